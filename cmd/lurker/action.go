@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// actionEvent is the part of a workflow's triggering event lurker reads
+// (the file at $GITHUB_EVENT_PATH).
+type actionEvent struct {
+	Issue struct {
+		Number int `json:"number"`
+	} `json:"issue"`
+	Label struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Inputs struct {
+		Issue string `json:"issue"` // workflow_dispatch
+	} `json:"inputs"`
+}
+
+// actionBotName and actionBotEmail are who commits when the workflow sets
+// none.
+const (
+	actionBotName  = "github-actions[bot]"
+	actionBotEmail = "41898282+github-actions[bot]@users.noreply.github.com"
+)
+
+// runAction works the issue that triggered a GitHub Actions workflow, as
+// `lurker run --wait --pr` would, then leaves the transcript and logs in
+// --artifacts for upload and reports through the step's outputs and job
+// summary. action.yml at the repo root wraps it.
+//
+//	lurker action [--label NAME] [--pr=false] [--draft] [--artifacts DIR] [--timeout D] [OWNER/REPO#N]
+func runAction(args []string) error {
+	fs := flag.NewFlagSet("action", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: $RUNNER_TEMP/lurker)")
+	label := fs.String("label", "", "Only act when the triggering event added this label")
+	openPR := fs.Bool("pr", true, "Push the branch and open a PR once the issue is ready")
+	draft := fs.Bool("draft", false, "Open the PR as a draft")
+	artifacts := fs.String("artifacts", "", "Directory to leave the transcript and logs in (default: $RUNNER_TEMP/lurker-artifacts)")
+	timeout := fs.Duration("timeout", 0, "Give up on the run after this long (0 waits forever)")
+	fs.Parse(args)
+
+	var repo string
+	var num int
+	var err error
+	switch fs.NArg() {
+	case 0:
+		if repo, num, err = actionIssue(*label); err != nil {
+			return err
+		}
+		if num == 0 {
+			fmt.Printf("The event didn't add the %q label; nothing to do.\n", *label)
+			return nil
+		}
+	case 1:
+		if repo, num, err = watcher.ParseIssueKey(fs.Arg(0)); err != nil {
+			return &exitError{exitUsage, err}
+		}
+	default:
+		return &exitError{exitUsage, errors.New("usage: lurker action [flags] [OWNER/REPO#N]")}
+	}
+
+	scratch := os.Getenv("RUNNER_TEMP")
+	if scratch == "" {
+		scratch = os.TempDir()
+	}
+	if *baseDir == "" {
+		*baseDir = filepath.Join(scratch, "lurker")
+	}
+	if *artifacts == "" {
+		*artifacts = filepath.Join(scratch, "lurker-artifacts")
+	}
+	for _, v := range []struct{ key, value string }{
+		{"GIT_AUTHOR_NAME", actionBotName}, {"GIT_AUTHOR_EMAIL", actionBotEmail},
+		{"GIT_COMMITTER_NAME", actionBotName}, {"GIT_COMMITTER_EMAIL", actionBotEmail},
+	} {
+		if os.Getenv(v.key) == "" {
+			os.Setenv(v.key, v.value)
+		}
+	}
+	// gh, which clones, reads GH_TOKEN; the workflow provides GITHUB_TOKEN
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && os.Getenv("GH_TOKEN") == "" {
+		os.Setenv("GH_TOKEN", token)
+	}
+
+	iss, runErr := runHeadless(headlessOptions{
+		baseDir: *baseDir,
+		repo:    repo,
+		num:     num,
+		openPR:  *openPR,
+		draft:   *draft,
+		timeout: *timeout,
+	})
+	if runErr != nil {
+		fmt.Printf("::error title=lurker::%s\n", actionEscape(runErr.Error()))
+	}
+
+	if err := collectArtifacts(*artifacts, *baseDir, iss); err != nil {
+		fmt.Printf("::warning title=lurker::artifacts: %s\n", actionEscape(err.Error()))
+	}
+	status := iss.Status.String()
+	if runErr != nil && iss.Status == watcher.StatusReady {
+		status = "pr-failed"
+	}
+	appendActionFile("GITHUB_OUTPUT", fmt.Sprintf("status=%s\npr-number=%d\npr-url=%s\nartifacts=%s\n",
+		status, iss.PRNumber, iss.PRURL, *artifacts))
+	appendActionFile("GITHUB_STEP_SUMMARY", actionSummary(iss, runErr))
+	return runErr
+}
+
+// actionIssue returns the issue the workflow was triggered for. num is 0
+// if label is set and the event didn't add it.
+func actionIssue(label string) (repo string, num int, err error) {
+	repo = os.Getenv("GITHUB_REPOSITORY")
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if repo == "" || path == "" {
+		return "", 0, &exitError{exitUsage, errors.New("not running in GitHub Actions; pass OWNER/REPO#N")}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	var ev actionEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return "", 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if label != "" && os.Getenv("GITHUB_EVENT_NAME") == "issues" && ev.Label.Name != label {
+		return repo, 0, nil
+	}
+	num = ev.Issue.Number
+	if num == 0 && ev.Inputs.Issue != "" {
+		fmt.Sscanf(strings.TrimPrefix(ev.Inputs.Issue, "#"), "%d", &num)
+	}
+	if num == 0 {
+		return "", 0, &exitError{exitUsage, fmt.Errorf("the %s event names no issue", os.Getenv("GITHUB_EVENT_NAME"))}
+	}
+	return repo, num, nil
+}
+
+// collectArtifacts copies what's worth keeping from iss's run — Claude's
+// transcript, lurker's log, issue.json and the verification — into dir.
+func collectArtifacts(dir, baseDir string, iss watcher.TrackedIssue) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	issueDir := watcher.IssueDir(baseDir, iss.Repo, iss.Number)
+	for _, name := range []string{watcher.LogFile, "issue.json", watcher.VerificationFile} {
+		data, err := os.ReadFile(filepath.Join(issueDir, name))
+		if err != nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+	if iss.Workdir == "" {
+		return nil
+	}
+	lines, err := watcher.ClaudeTranscript(iss.Workdir, watcher.StreamFormat{})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "transcript.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// actionSummary is the job summary: the outcome and, after a run, its
+// summary.
+func actionSummary(iss watcher.TrackedIssue, runErr error) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## lurker: %s\n\n", watcher.IssueKey(iss.Repo, iss.Number))
+	switch {
+	case iss.PRURL != "":
+		fmt.Fprintf(&b, "✅ Opened %s\n\n", iss.PRURL)
+	case iss.Status == watcher.StatusNeedsInput:
+		fmt.Fprintf(&b, "❓ Claude has questions:\n\n%s\n\n", iss.Questions)
+	case runErr != nil:
+		fmt.Fprintf(&b, "❌ %s\n\n", runErr)
+	default:
+		fmt.Fprintf(&b, "✅ Ready on `%s`\n\n", watcher.IssueBranch(iss.Number))
+	}
+	if iss.Workdir != "" {
+		if s, err := watcher.LoadRunSummary(iss.Workdir); err == nil {
+			b.WriteString(s.Markdown())
+			b.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&b, "_Finished %s_\n", time.Now().UTC().Format(time.RFC1123))
+	return b.String()
+}
+
+// appendActionFile appends text to the workflow command file named by env
+// (GITHUB_OUTPUT, GITHUB_STEP_SUMMARY), if the runner set one.
+func appendActionFile(env, text string) {
+	path := os.Getenv(env)
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(text)
+}
+
+// actionEscape escapes a workflow command's message.
+func actionEscape(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
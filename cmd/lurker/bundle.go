@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// runBundle packs an issue's state, logs, Claude transcripts and diff into
+// an anonymized tarball to attach to a lurker bug report or share a run.
+//
+//	lurker bundle [--dir DIR] [-o FILE] OWNER/REPO#N
+func runBundle(args []string) error {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	out := fs.String("o", "", "Output file, - for stdout (default: lurker-OWNER-REPO-N.tar.gz)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return &exitError{exitUsage, errors.New("usage: lurker bundle [flags] OWNER/REPO#N")}
+	}
+	repo, num, err := watcher.ParseIssueKey(fs.Arg(0))
+	if err != nil {
+		return &exitError{exitUsage, err}
+	}
+	dir := *baseDir
+	if dir == "" {
+		if dir, err = defaultBaseDir(); err != nil {
+			return err
+		}
+	}
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("lurker-%s-%d.tar.gz", strings.ReplaceAll(repo, "/", "-"), num)
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	man, err := watcher.WriteBundle(context.Background(), w, dir, repo, num)
+	if err != nil {
+		if path != "-" {
+			os.Remove(path)
+		}
+		return err
+	}
+	if path == "-" {
+		return nil
+	}
+	fmt.Printf("Wrote %s (%d files)\n", path, len(man.Files))
+	for name, why := range man.Skipped {
+		fmt.Printf("  skipped %s: %s\n", name, why)
+	}
+	fmt.Println("Tokens, email addresses and local paths are scrubbed; look it over before sharing.")
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/api"
+	"github.com/stefanpenner/lurker/pkg/tui"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// openManager opens the state under dir (or the default) without starting
+// any watchers, for subcommands that only read or edit configuration.
+func openManager(dir string) (*watcher.Manager, error) {
+	layout, err := openLayout(dir, watcher.Layout{}, "")
+	if err != nil {
+		return nil, err
+	}
+	return watcher.NewManagerIn(layout, 30*time.Second, nil)
+}
+
+// runExport writes the watched-repo configuration as JSON.
+//
+//	lurker export [--dir DIR] [-o FILE]
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	out := fs.String("o", "-", "Output file (- for stdout)")
+	fs.Parse(args)
+
+	mgr, err := openManager(*baseDir)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return watcher.WriteExport(w, mgr.Export())
+}
+
+// runImport merges (or with --replace, replaces) the watched repos from an
+// export. Run it while lurker is not running; a live instance would
+// overwrite the state on its next save.
+//
+//	lurker import [--dir DIR] [--replace] FILE|-
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	replace := fs.Bool("replace", false, "Stop watching repos not in the import")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: lurker import [--dir DIR] [--replace] FILE|-")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	ex, err := watcher.ReadExport(r)
+	if err != nil {
+		return err
+	}
+
+	mgr, err := openManager(*baseDir)
+	if err != nil {
+		return err
+	}
+	added, err := mgr.Import(ex, *replace)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d repos (%d new); now watching %d\n", len(ex.Repos), added, len(mgr.Repos()))
+	return nil
+}
+
+// runMigrate moves the base directory to a new location, e.g. a bigger
+// disk. Run it while lurker is not running.
+//
+//	lurker migrate [--dir DIR] --to NEWDIR
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Current base directory (default: ~/.local/share/lurker)")
+	to := fs.String("to", "", "New base directory (must not exist or be empty)")
+	fs.Parse(args)
+
+	if *to == "" {
+		return fmt.Errorf("usage: lurker migrate [--dir DIR] --to NEWDIR")
+	}
+	from := *baseDir
+	if from == "" {
+		d, err := defaultBaseDir()
+		if err != nil {
+			return err
+		}
+		from = d
+	}
+
+	if err := watcher.Migrate(from, *to, func(step string) { fmt.Println(step) }); err != nil {
+		return err
+	}
+	fmt.Printf("Start lurker with --dir %s from now on\n", *to)
+	return nil
+}
+
+// runFleet shows a read-only overview of one or more lurker instances:
+// their base directories, e.g. mounted from other machines, or their
+// control APIs.
+//
+//	lurker fleet [--refresh 10s] [--api ADDR]... [--api-token TOKEN] [DIR...]
+func runFleet(args []string) error {
+	fs := flag.NewFlagSet("fleet", flag.ExitOnError)
+	refresh := fs.Duration("refresh", 10*time.Second, "How often to re-read each instance")
+	var addrs []string
+	fs.Func("api", "Control API address of an instance to show; repeat for more", func(addr string) error {
+		addrs = append(addrs, addr)
+		return nil
+	})
+	token := fs.String("api-token", os.Getenv("LURKER_API_TOKEN"), "Token the control APIs require (default: $LURKER_API_TOKEN)")
+	fs.Parse(args)
+
+	var apis []tui.FleetAPI
+	for _, addr := range addrs {
+		c, conn, err := api.Dial(addr, *token)
+		if err != nil {
+			return fmt.Errorf("%s: %w", addr, err)
+		}
+		defer conn.Close()
+		apis = append(apis, tui.FleetAPI{Addr: addr, Client: c})
+	}
+
+	dirs := fs.Args()
+	if len(dirs) == 0 && len(apis) == 0 {
+		d, err := defaultBaseDir()
+		if err != nil {
+			return err
+		}
+		dirs = []string{d}
+	}
+
+	_, err := tea.NewProgram(tui.NewFleetModel(dirs, apis, *refresh), tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stefanpenner/lurker/pkg/api"
+	"github.com/stefanpenner/lurker/pkg/api/lurkerpb"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// The scripting subcommands (list, start, stop, logs, status) talk to a
+// running lurker over its control API (--api) and, where they only read,
+// fall back to the state on disk when none is running.
+
+// defaultSocket is where the subcommands look for a running lurker's API
+// unless --api says otherwise.
+const defaultSocket = "lurker.sock"
+
+// ctlFlags are the flags every scripting subcommand takes.
+type ctlFlags struct {
+	dir   *string
+	addr  *string
+	token *string
+}
+
+func newCtlFlags(fs *flag.FlagSet) ctlFlags {
+	return ctlFlags{
+		dir:   fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)"),
+		addr:  fs.String("api", "", "Control API address of the running lurker (default: DIR/lurker.sock)"),
+		token: fs.String("api-token", os.Getenv("LURKER_API_TOKEN"), "Token the control API requires (default: $LURKER_API_TOKEN)"),
+	}
+}
+
+// baseDir resolves --dir.
+func (f ctlFlags) baseDir() (string, error) {
+	if *f.dir != "" {
+		return *f.dir, nil
+	}
+	return defaultBaseDir()
+}
+
+// connect returns a client for the running lurker, or nil if there is
+// none and --api wasn't given. Call close when done.
+func (f ctlFlags) connect(ctx context.Context) (client lurkerpb.LurkerClient, close func(), err error) {
+	addr := *f.addr
+	if addr == "" {
+		dir, err := f.baseDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		addr = filepath.Join(dir, defaultSocket)
+		if _, err := os.Stat(addr); err != nil {
+			return nil, func() {}, nil
+		}
+	}
+	c, conn, err := api.Dial(addr, *f.token)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Fail now, not on the first real call, if nothing is listening
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if _, err := c.ListRepos(pingCtx, &lurkerpb.ListReposRequest{}); err != nil {
+		conn.Close()
+		if *f.addr == "" && status.Code(err) == codes.Unavailable {
+			return nil, func() {}, nil // a socket left behind by a lurker that exited
+		}
+		return nil, nil, fmt.Errorf("lurker at %s: %w", addr, err)
+	}
+	return c, func() { conn.Close() }, nil
+}
+
+// requireDaemon connects to the running lurker, failing if there is none.
+func (f ctlFlags) requireDaemon(ctx context.Context) (lurkerpb.LurkerClient, func(), error) {
+	c, closeConn, err := f.connect(ctx)
+	if err == nil && c == nil {
+		err = errors.New("no running lurker found; start it with --api DIR/lurker.sock or pass --api")
+	}
+	return c, closeConn, err
+}
+
+// lurkerState is what list and status report.
+type lurkerState struct {
+	Running bool                `json:"running"` // read from a running lurker, not disk
+	Repos   []string            `json:"repos"`
+	Issues  []watcher.IssueMeta `json:"issues"`
+}
+
+// loadState reads the running lurker's repos and issues, or the state on
+// disk if none is running.
+func (f ctlFlags) loadState(ctx context.Context, repo string) (lurkerState, error) {
+	c, closeConn, err := f.connect(ctx)
+	if err != nil {
+		return lurkerState{}, err
+	}
+	defer closeConn()
+	if c == nil {
+		dir, err := f.baseDir()
+		if err != nil {
+			return lurkerState{}, err
+		}
+		snap, err := watcher.LoadSnapshot(dir)
+		if err != nil {
+			return lurkerState{}, err
+		}
+		st := lurkerState{Repos: snap.Repos}
+		for _, iss := range snap.Issues {
+			if repo == "" || iss.Repo == repo {
+				st.Issues = append(st.Issues, iss)
+			}
+		}
+		return st, nil
+	}
+
+	repos, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{})
+	if err != nil {
+		return lurkerState{}, err
+	}
+	issues, err := c.ListIssues(ctx, &lurkerpb.ListIssuesRequest{Repo: repo})
+	if err != nil {
+		return lurkerState{}, err
+	}
+	st := lurkerState{Running: true, Repos: repos.GetRepos()}
+	for _, p := range issues.GetIssues() {
+		st.Issues = append(st.Issues, api.IssueFromProto(p))
+	}
+	return st, nil
+}
+
+// runList prints the tracked issues, one per line.
+//
+//	lurker list [--dir DIR] [--api ADDR] [--repo OWNER/NAME] [--status STATUS] [--json]
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	cf := newCtlFlags(fs)
+	repo := fs.String("repo", "", "Only list this repo's issues (owner/name)")
+	statusFlag := fs.String("status", "", "Only list issues with this status (e.g. ready, failed)")
+	asJSON := fs.Bool("json", false, "Print the issues as JSON, in the shape of issue.json")
+	fs.Parse(args)
+
+	if *statusFlag != "" {
+		if _, ok := watcher.ParseIssueStatus(*statusFlag); !ok {
+			return fmt.Errorf("unknown status %q", *statusFlag)
+		}
+	}
+	st, err := cf.loadState(context.Background(), *repo)
+	if err != nil {
+		return err
+	}
+	var issues []watcher.IssueMeta
+	for _, iss := range st.Issues {
+		if *statusFlag == "" || iss.Status == *statusFlag {
+			issues = append(issues, iss)
+		}
+	}
+
+	if *asJSON {
+		if issues == nil {
+			issues = []watcher.IssueMeta{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, iss := range issues {
+		pr := ""
+		if iss.PRNumber != 0 {
+			pr = fmt.Sprintf("PR #%d", iss.PRNumber)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", watcher.IssueKey(iss.Repo, iss.Number), iss.Status, pr, iss.Title)
+	}
+	return tw.Flush()
+}
+
+// runStatus summarizes the lurker: whether it is running, and its issues
+// per repo by status.
+//
+//	lurker status [--dir DIR] [--api ADDR] [--json]
+func runStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	cf := newCtlFlags(fs)
+	asJSON := fs.Bool("json", false, "Print the repos and issues as JSON")
+	fs.Parse(args)
+
+	st, err := cf.loadState(context.Background(), "")
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if st.Repos == nil {
+			st.Repos = []string{}
+		}
+		if st.Issues == nil {
+			st.Issues = []watcher.IssueMeta{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+
+	if st.Running {
+		fmt.Println("lurker is running")
+	} else {
+		fmt.Println("lurker is not running (or has no --api); showing the state on disk")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, repo := range st.Repos {
+		counts := make(map[string]int)
+		var order []string
+		for _, iss := range st.Issues {
+			if iss.Repo != repo {
+				continue
+			}
+			if counts[iss.Status] == 0 {
+				order = append(order, iss.Status)
+			}
+			counts[iss.Status]++
+		}
+		var parts []string
+		for _, s := range order {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+		if len(parts) == 0 {
+			parts = []string{"no issues"}
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", repo, strings.Join(parts, ", "))
+	}
+	return tw.Flush()
+}
+
+// runStart starts (or restarts) an issue in the running lurker, as space
+// does in the TUI.
+//
+//	lurker start [--dir DIR] [--api ADDR] OWNER/REPO#N
+func runStart(args []string) error {
+	return runIssueAction("start", args, func(ctx context.Context, c lurkerpb.LurkerClient, ref *lurkerpb.IssueRef) (*lurkerpb.Issue, error) {
+		return c.StartIssue(ctx, ref)
+	})
+}
+
+// runStop stops an issue's run in the running lurker.
+//
+//	lurker stop [--dir DIR] [--api ADDR] OWNER/REPO#N
+func runStop(args []string) error {
+	return runIssueAction("stop", args, func(ctx context.Context, c lurkerpb.LurkerClient, ref *lurkerpb.IssueRef) (*lurkerpb.Issue, error) {
+		return c.StopIssue(ctx, ref)
+	})
+}
+
+func runIssueAction(name string, args []string, action func(context.Context, lurkerpb.LurkerClient, *lurkerpb.IssueRef) (*lurkerpb.Issue, error)) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := newCtlFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return &exitError{exitUsage, fmt.Errorf("usage: lurker %s [flags] OWNER/REPO#N", name)}
+	}
+	repo, num, err := watcher.ParseIssueKey(fs.Arg(0))
+	if err != nil {
+		return &exitError{exitUsage, err}
+	}
+
+	ctx := context.Background()
+	c, closeConn, err := cf.requireDaemon(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	iss, err := action(ctx, c, &lurkerpb.IssueRef{Repo: repo, Number: int32(num)})
+	if err != nil {
+		return fmt.Errorf("%s %s: %s", name, watcher.IssueKey(repo, num), status.Convert(err).Message())
+	}
+	fmt.Printf("%s\t%s\n", watcher.IssueKey(iss.GetRepo(), int(iss.GetNumber())), iss.GetStatus())
+	return nil
+}
+
+// runLogs prints an issue's log from its lurker.log; with -f it then
+// follows new lines from the running lurker.
+//
+//	lurker logs [--dir DIR] [--api ADDR] [-n N] [-f] OWNER/REPO#N
+func runLogs(args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	cf := newCtlFlags(fs)
+	lines := fs.Int("n", 0, "Print only the last N lines (0 prints all)")
+	follow := fs.Bool("f", false, "Keep printing new lines as the running lurker logs them")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return &exitError{exitUsage, errors.New("usage: lurker logs [flags] OWNER/REPO#N")}
+	}
+	repo, num, err := watcher.ParseIssueKey(fs.Arg(0))
+	if err != nil {
+		return &exitError{exitUsage, err}
+	}
+	dir, err := cf.baseDir()
+	if err != nil {
+		return err
+	}
+
+	// Connect before reading the file so no line falls between the two
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var stream grpc.ServerStreamingClient[lurkerpb.LogLine]
+	if *follow {
+		c, closeConn, err := cf.requireDaemon(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeConn()
+		if stream, err = c.StreamLogs(ctx, &lurkerpb.StreamLogsRequest{Repo: repo, Number: int32(num)}); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(watcher.IssueDir(dir, repo, num), watcher.LogFile)
+	if err := printLogTail(path, *lines); err != nil && !(*follow && errors.Is(err, os.ErrNotExist)) {
+		return err
+	}
+	if stream == nil {
+		return nil
+	}
+	for {
+		line, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fmt.Printf("%s\t%s\n", time.UnixMilli(line.GetTime()).UTC().Format(time.RFC3339), line.GetText())
+	}
+}
+
+// printLogTail prints the last n lines (all if n is 0) of the log at path.
+func printLogTail(path string, n int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var tail []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		tail = append(tail, scanner.Text())
+		if n > 0 && len(tail) > n {
+			tail = tail[1:]
+		}
+	}
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+	return scanner.Err()
+}
@@ -1,53 +1,361 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/stefanpenner/lurker/pkg/api"
 	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/gitlab"
 	"github.com/stefanpenner/lurker/pkg/tui"
 	"github.com/stefanpenner/lurker/pkg/watcher"
+	"github.com/stefanpenner/lurker/pkg/webhook"
 )
 
+// subcommands are dispatched on the first argument; anything else starts
+// the TUI.
+var subcommands = map[string]func(args []string) error{
+	"action":      runAction,
+	"bundle":      runBundle,
+	"export":      runExport,
+	"fleet":       runFleet,
+	"import":      runImport,
+	"list":        runList,
+	"logs":        runLogs,
+	"migrate":     runMigrate,
+	"report":      runReport,
+	"run":         runRun,
+	"sandbox-net": runSandboxNet,
+	"search":      runSearch,
+	"selftest":    runSelftest,
+	"start":       runStart,
+	"status":      runStatus,
+	"stop":        runStop,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if mask, err := watcher.ParseUmask(defaultUmask()); err == nil {
+				watcher.SetUmask(mask)
+			}
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				var exit *exitError
+				if errors.As(err, &exit) {
+					os.Exit(exit.code)
+				}
+				os.Exit(exitFailed)
+			}
+			return
+		}
+	}
+
 	interval := flag.Duration("interval", 30*time.Second, "Poll interval")
-	baseDir := flag.String("dir", "", "Base directory for workdirs (default: ~/.local/share/lurker)")
+	baseDir := flag.String("dir", "", "Base directory for workdirs, and for state, bare clones and config unless given below (default: $XDG_DATA_HOME/lurker)")
+	stateDir := flag.String("state-dir", "", "Directory for state.json and the event journal (default: $XDG_STATE_HOME/lurker, or DIR with --dir)")
+	cacheDir := flag.String("cache-dir", "", "Directory for bare clones and the team config checkout (default: $XDG_CACHE_HOME/lurker, or DIR with --dir)")
+	configDir := flag.String("config-dir", "", "Directory for permissions.json, macros.json and shellrc (default: $XDG_CONFIG_HOME/lurker, or DIR with --dir)")
+	umask := flag.String("umask", defaultUmask(), "Umask for the files lurker and the commands it runs create; lurker's directories lose what it denies (default: $LURKER_UMASK, else 077)")
+	requireMount := flag.String("require-mount", "", "Refuse to start unless this directory, e.g. an encrypted volume holding DIR, is mounted and holds all of lurker's directories")
+	columns := flag.String("columns", strings.Join(tui.DefaultColumns, ","), "Issue columns to show (status,beads,number,title,pr,cost,elapsed,progress,logs)")
+	bell := flag.Bool("bell", false, "Ring the terminal bell when an issue is ready or fails")
+	osc9 := flag.Bool("osc9", false, "Send OSC 9 desktop notifications when an issue is ready or fails")
+	tmuxStatus := flag.Bool("tmux-status", false, "Publish ready/failed counts in the tmux @lurker option")
+	autoStart := flag.Bool("auto-start", false, "Start issues that appear while lurker is running without pressing space")
+	idlePause := flag.Duration("idle-pause", 0, "Suspend --auto-start after this long without keyboard input (e.g. 4h; 0 disables)")
+	claimTTL := flag.Duration("claim-ttl", 24*time.Hour, "Release the 👀 claim on issues failed this long (0 keeps claims)")
+	teamConfig := flag.String("team-config", "", "Git repo or gist URL holding a shared lurker.json (watched repos, prompt template, policy)")
+	teamRefresh := flag.Duration("team-config-refresh", 10*time.Minute, "How often to re-pull --team-config")
+	permsFile := flag.String("permissions", "", "Permissions file with allow_pr_create, allow_comments, allow_auto_start, allow_push (default: CONFIG_DIR/permissions.json)")
+	observer := flag.Bool("observer", false, "Deny every action that would change GitHub (overrides --permissions)")
+	macrosFile := flag.String("macros", "", "Macros file binding keys to chains of actions (default: CONFIG_DIR/macros.json)")
+	apiAddr := flag.String("api", "", "Serve the gRPC control API on a unix socket path or host:port (e.g. DIR/lurker.sock)")
+	jsonrpcAddr := flag.String("jsonrpc", "", "Serve the JSON-RPC editor endpoint on a unix socket path or host:port (e.g. DIR/editor.sock)")
+	apiToken := flag.String("api-token", os.Getenv("LURKER_API_TOKEN"), "Token --api and --jsonrpc require of callers; needed on a host:port (default: $LURKER_API_TOKEN)")
+	maxIssues := flag.Int("max-issues", 1000, "List at most this many open issues per repo each poll (0 for no limit)")
+	maxTurns := flag.Int("max-turns", 0, "Stop each Claude run after this many turns (0 for no limit)")
+	runTimeout := flag.Duration("run-timeout", 0, "Stop each Claude run after this long and fail the issue (e.g. 45m; 0 for no limit)")
+	maxClaude := flag.Int("max-claude", 4, "Run Claude on at most this many issues at once; the rest queue (0 for no limit)")
+	shellIdle := flag.Duration("shell-idle", 30*time.Minute, "Close an issue's shell after this long unused while it isn't being worked on (0 keeps shells)")
+	maxShells := flag.Int("max-shells", 16, "Keep at most this many issue shells open, closing the least recently used idle one (0 for no limit)")
+	shellBackend := flag.String("shell-backend", "pty", "What backs issue shells: "+strings.Join(tui.ShellBackends, ", ")+"; tmux and screen sessions survive restarts")
+	attachProfile := flag.Bool("attach-profile", false, "Give the shells you attach to (s, c, t) your own shell profile, apart from the one lurker runs commands in")
+	agent := flag.String("agent", watcher.DefaultAgent, "Coding agent to run issues with unless their repo config says otherwise: "+strings.Join(watcher.AgentNames(), ", "))
+	sandboxImage := flag.String("sandbox-image", "", "Run every repo's new issues in Docker containers of this image, whatever the repo's sandbox_image says")
+	bwrap := flag.Bool("bwrap", false, "Run Claude and issue shells under bubblewrap, seeing only their worktree, with network only to Anthropic and GitHub (Linux)")
+	stats := flag.Bool("stats", false, "Record each run's outcome and phase durations in stats.jsonl in the state directory, for the U view (local only; nothing is sent anywhere)")
+	logColors := flag.Bool("log-colors", false, "Show log lines in the colors tools such as test runners gave them (lurker.log stays plain)")
+	streamFormat := flag.String("stream-format", "short", "How Claude's transcripts are shown: short, or any of text (whole messages), inputs (whole tool inputs), full (both) and raw (JSON events), comma-separated")
+	forgeName := flag.String("forge", "github", "Where the watched repos live: github or gitlab")
+	gitlabURL := flag.String("gitlab-url", gitlab.DefaultURL, "GitLab instance for --forge gitlab (token from $GITLAB_TOKEN or glab)")
+	listenAddr := flag.String("listen", "", "Receive GitHub issues webhooks on host:port at /webhook; repos that deliver are then polled only hourly")
+	webhookSecret := flag.String("webhook-secret", os.Getenv("LURKER_WEBHOOK_SECRET"), "Secret the webhooks are signed with (default: $LURKER_WEBHOOK_SECRET)")
 	flag.Parse()
 
-	if *baseDir == "" {
-		home, err := os.UserHomeDir()
+	mask, err := watcher.ParseUmask(*umask)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --umask: %v\n", err)
+		os.Exit(1)
+	}
+	watcher.SetUmask(mask)
+	layout, err := openLayout(*baseDir, watcher.Layout{State: *stateDir, Cache: *cacheDir, Config: *configDir}, *requireMount)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := layout.Restrict(mask); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --umask: %v\n", err)
+		os.Exit(1)
+	}
+
+	var forge watcher.Forge
+	var glClient *gitlab.Client
+	ghClient, err := github.NewClient()
+	switch *forgeName {
+	case "github":
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		forge = ghClient
+	case "gitlab":
+		// GitHub still backs review requests (V) when it is set up.
+		if err != nil {
+			ghClient = github.NewOfflineClient()
+		}
+		glClient, err = gitlab.NewClient(*gitlabURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// glab, which clones in the issue shells, reads the instance
+		// from the environment.
+		if u, err := url.Parse(*gitlabURL); err == nil && os.Getenv("GITLAB_HOST") == "" {
+			os.Setenv("GITLAB_HOST", u.Host)
+		}
+		forge = glClient
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown forge %q (want github or gitlab)\n", *forgeName)
+		os.Exit(1)
+	}
+
+	perms := github.Permissions{}
+	if !*observer {
+		if *permsFile == "" {
+			*permsFile = layout.ConfigPath("permissions.json")
+		}
+		perms, err = github.LoadPermissions(*permsFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		*baseDir = filepath.Join(home, ".local", "share", "lurker")
+	}
+	ghClient.SetPermissions(perms)
+	ghClient.SetMaxIssues(*maxIssues)
+	if glClient != nil {
+		glClient.SetPermissions(perms)
+		glClient.SetMaxIssues(*maxIssues)
 	}
 
-	ghClient, err := github.NewClient()
+	if *macrosFile == "" {
+		*macrosFile = layout.ConfigPath("macros.json")
+	}
+	macros, err := tui.LoadMacros(*macrosFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	mgr, err := watcher.NewManager(*baseDir, *interval, ghClient)
+	format, err := watcher.ParseStreamFormat(*streamFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --stream-format: %v\n", err)
+		os.Exit(1)
+	}
+
+	mgr, err := watcher.NewManagerIn(layout, *interval, forge)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating manager: %v\n", err)
 		os.Exit(1)
 	}
 
+	mgr.SetMaxClaudeRuns(*maxClaude)
+	mgr.SetRunLimits(watcher.RunLimits{MaxTurns: *maxTurns, Timeout: *runTimeout})
+	mgr.SetStreamFormat(format)
+	mgr.SetStats(*stats)
+	if err := mgr.SetAgent(*agent); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --agent: %v\n", err)
+		os.Exit(1)
+	}
+	mgr.SetSandboxImage(*sandboxImage)
+	if *bwrap {
+		box, err := watcher.StartBwrap(layout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer box.Close()
+		mgr.SetBwrap(box)
+	}
 	mgr.Start()
 	defer mgr.Stop()
+	if *listenAddr != "" {
+		hook, err := webhook.NewHandler(*webhookSecret, mgr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		lis, err := api.Listen(*listenAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		srv := &http.Server{Handler: hook, ReadHeaderTimeout: 10 * time.Second}
+		go srv.Serve(lis)
+		defer srv.Close()
+	}
+	if *teamConfig != "" {
+		mgr.StartTeamSync(*teamConfig, *teamRefresh)
+	}
+
+	model, err := tui.NewModel(mgr, ghClient, tui.Options{
+		Columns:       strings.Split(*columns, ","),
+		Bell:          *bell,
+		OSC9:          *osc9,
+		TmuxStatus:    *tmuxStatus,
+		AutoStart:     *autoStart,
+		IdlePause:     *idlePause,
+		ClaimTTL:      *claimTTL,
+		Macros:        macros,
+		ShellIdle:     *shellIdle,
+		MaxShells:     *maxShells,
+		ShellBackend:  *shellBackend,
+		AttachProfile: *attachProfile,
+		LogColors:     *logColors,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(model.Output()))
 
-	model := tui.NewModel(mgr, ghClient)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	backend := model.APIBackend()
+	backend.Attach(p)
+	if *apiAddr != "" {
+		lis, err := listenAPI(*apiAddr, *apiToken)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		srv := api.NewServer(backend, *apiToken)
+		go srv.Serve(lis)
+		defer srv.Stop()
+	}
+	if *jsonrpcAddr != "" {
+		lis, err := listenAPI(*jsonrpcAddr, *apiToken)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		srv := api.NewJSONRPCServer(backend, *apiToken)
+		go srv.Serve(lis)
+		defer srv.Stop()
+	}
 
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// listenAPI opens addr for an API server, refusing a TCP address
+// without a token.
+func listenAPI(addr, token string) (net.Listener, error) {
+	if err := api.CheckToken(addr, token); err != nil {
+		return nil, err
+	}
+	return api.Listen(addr)
+}
+
+// defaultBaseDir is where workdirs live unless --dir is given: the XDG
+// data directory, which records where the rest is (see watcher.Layout).
+func defaultBaseDir() (string, error) {
+	l, err := watcher.XDGLayout()
+	return l.Data, err
+}
+
+// legacyBaseDir is where lurker kept everything before it followed the
+// XDG base directory spec.
+func legacyBaseDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "lurker"), nil
+}
+
+// defaultUmask is $LURKER_UMASK, or watcher.DefaultUmask.
+func defaultUmask() string {
+	if mask := os.Getenv("LURKER_UMASK"); mask != "" {
+		return mask
+	}
+	return fmt.Sprintf("%03o", watcher.DefaultUmask)
+}
+
+// openLayout resolves where lurker keeps its files, and moves them there
+// from where they were. With dir, that is where dir's layout.json says,
+// and everything in dir if it says nothing; without it, where the XDG data
+// directory's says, else the XDG base directories. What overrides sets
+// wins either way. If mount is set, it must be mounted and hold them all
+// before anything is written.
+func openLayout(dir string, overrides watcher.Layout, mount string) (watcher.Layout, error) {
+	legacy, layout := dir, watcher.LoadLayout(dir)
+	if dir == "" {
+		xdg, err := watcher.XDGLayout()
+		if err != nil {
+			return layout, err
+		}
+		if legacy, err = legacyBaseDir(); err != nil {
+			return layout, err
+		}
+		layout = xdg
+		if _, err := os.Stat(filepath.Join(xdg.Data, watcher.LayoutFile)); err == nil {
+			layout = watcher.LoadLayout(xdg.Data)
+		}
+	}
+	// layout.json outlives the working directory.
+	for _, dir := range []struct {
+		to   *string
+		from string
+	}{
+		{&layout.State, overrides.State},
+		{&layout.Cache, overrides.Cache},
+		{&layout.Config, overrides.Config},
+	} {
+		if dir.from == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir.from)
+		if err != nil {
+			return layout, err
+		}
+		*dir.to = abs
+	}
+	if mount != "" {
+		if err := layout.RequireMount(mount); err != nil {
+			return layout, fmt.Errorf("--require-mount: %w", err)
+		}
+	}
+	err := watcher.MigrateLayout(legacy, layout, func(step string) { fmt.Fprintln(os.Stderr, step) })
+	return layout, err
+}
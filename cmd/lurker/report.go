@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// runReport prints a digest of the issues processed, PRs opened and
+// merged, failures and spend per repo over a period. With --every it keeps
+// running and produces one report per period, e.g. a daily Slack post.
+//
+//	lurker report [--dir DIR] [--since 24h|7d] [--format markdown|slack] [-o FILE] [--slack-webhook URL] [--every 24h|7d]
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	sinceFlag := fs.String("since", "24h", "Period to report on, ending now (e.g. 24h, 7d)")
+	format := fs.String("format", "markdown", "Output format: markdown or slack")
+	out := fs.String("o", "-", "Output file (- for stdout)")
+	webhook := fs.String("slack-webhook", "", "Post the report (in slack format) to this Slack incoming webhook instead of printing it")
+	everyFlag := fs.String("every", "", "Keep running and report every period (e.g. 24h, 7d); --since defaults to the same period")
+	fs.Parse(args)
+
+	if *format != "markdown" && *format != "slack" {
+		return fmt.Errorf("unknown format %q (want markdown or slack)", *format)
+	}
+	if *webhook != "" {
+		*format = "slack"
+	}
+	since, err := parsePeriod(*sinceFlag)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	var every time.Duration
+	if *everyFlag != "" {
+		if every, err = parsePeriod(*everyFlag); err != nil {
+			return fmt.Errorf("--every: %w", err)
+		}
+		if !flagSet(fs, "since") {
+			since = every
+		}
+	}
+
+	dir := *baseDir
+	if dir == "" {
+		if dir, err = defaultBaseDir(); err != nil {
+			return err
+		}
+	}
+
+	// Merges are looked up on GitHub; without access the report says so.
+	var merged watcher.MergedFunc
+	if gh, err := github.NewClient(); err == nil {
+		merged = func(ctx context.Context, repo string, pr int) (time.Time, error) {
+			p, err := gh.GetPR(ctx, repo, pr)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return p.MergedAt, nil
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: not checking merged PRs: %v\n", err)
+	}
+
+	report := func() error {
+		snap, err := watcher.LoadSnapshot(dir)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		rep := watcher.BuildReport(context.Background(), snap, now.Add(-since), now, merged)
+		text := rep.Markdown()
+		if *format == "slack" {
+			text = rep.Slack()
+		}
+		if *webhook != "" {
+			return postSlack(*webhook, text)
+		}
+		return writeOutput(*out, text)
+	}
+
+	if every == 0 {
+		return report()
+	}
+	for {
+		if err := report(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s report failed: %v\n", time.Now().Format(time.DateTime), err)
+		}
+		time.Sleep(every)
+	}
+}
+
+// parsePeriod is time.ParseDuration plus whole days, e.g. "7d".
+func parsePeriod(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", s)
+	}
+	return d, nil
+}
+
+// flagSet reports whether name was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// writeOutput writes text to path, or stdout for "-". Files are replaced
+// so a scheduled report always holds the latest period.
+func writeOutput(path, text string) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err := fmt.Fprintln(w, text)
+	return err
+}
+
+// postSlack sends text to a Slack incoming webhook.
+func postSlack(webhook, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack: %s: %s", resp.Status, msg)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// Exit statuses of `lurker run`, for CI and bots to act on.
+const (
+	exitFailed     = 1 // lurker itself failed: bad issue, no credentials
+	exitUsage      = 2
+	exitNeedsInput = 3 // Claude asked questions instead of finishing
+	exitRunFailed  = 4 // cloning or Claude failed
+	exitPRFailed   = 5 // the branch is ready but couldn't be pushed or opened as a PR
+)
+
+// exitError makes a subcommand exit with code rather than 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// runRun processes one issue headlessly — fetch it, clone, run Claude and
+// optionally open the PR — for CI jobs and other bots. Without --wait it
+// starts the run in the background and returns at once.
+//
+//	lurker run [--dir DIR] [--wait] [--pr] [--draft] [--timeout D] OWNER/REPO#N
+func runRun(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	wait := fs.Bool("wait", false, "Stay in the foreground, streaming the log, and exit with the run's status")
+	openPR := fs.Bool("pr", false, "Push the branch and open a PR once the issue is ready")
+	draft := fs.Bool("draft", false, "Open the PR as a draft (with --pr; always, if the repo config sets draft_prs)")
+	timeout := fs.Duration("timeout", 0, "Give up on the run after this long (0 waits forever)")
+	permsFile := fs.String("permissions", "", "Permissions file (default: CONFIG_DIR/permissions.json)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return &exitError{exitUsage, errors.New("usage: lurker run [flags] OWNER/REPO#N")}
+	}
+	repo, num, err := watcher.ParseIssueKey(fs.Arg(0))
+	if err != nil {
+		return &exitError{exitUsage, err}
+	}
+	if *baseDir == "" {
+		if *baseDir, err = defaultBaseDir(); err != nil {
+			return err
+		}
+	}
+	if !*wait {
+		return startRunInBackground(*baseDir, repo, num, args)
+	}
+	_, err = runHeadless(headlessOptions{
+		baseDir:   *baseDir,
+		permsFile: *permsFile,
+		repo:      repo,
+		num:       num,
+		openPR:    *openPR,
+		draft:     *draft,
+		timeout:   *timeout,
+	})
+	return err
+}
+
+// headlessOptions configures runHeadless.
+type headlessOptions struct {
+	baseDir   string
+	permsFile string // "" is permissions.json in baseDir's config dir
+	repo      string
+	num       int
+	openPR    bool
+	draft     bool
+	timeout   time.Duration // 0 waits forever
+}
+
+// runHeadless works one issue in the foreground, printing its log, and
+// returns the issue as it ended up. Errors carry `lurker run`'s exit
+// statuses.
+func runHeadless(o headlessOptions) (watcher.TrackedIssue, error) {
+	repo, num := o.repo, o.num
+	iss := watcher.TrackedIssue{Repo: repo, Number: num, Status: watcher.StatusFailed}
+
+	ghClient, err := github.NewClient()
+	if err != nil {
+		return iss, err
+	}
+	if o.permsFile == "" {
+		o.permsFile = watcher.LoadLayout(o.baseDir).ConfigPath("permissions.json")
+	}
+	perms, err := github.LoadPermissions(o.permsFile)
+	if err != nil {
+		return iss, err
+	}
+	ghClient.SetPermissions(perms)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	gi, err := ghClient.GetIssue(ctx, repo, num)
+	if err != nil {
+		return iss, err
+	}
+	if gi.PullRequest != nil {
+		return iss, fmt.Errorf("%s#%d is a pull request", repo, num)
+	}
+	if gi.State == "closed" {
+		return iss, fmt.Errorf("%s#%d is closed", repo, num)
+	}
+
+	mgr, err := watcher.NewManager(o.baseDir, 30*time.Second, ghClient)
+	if err != nil {
+		return iss, err
+	}
+	issue := watcher.IssueFromGitHub(*gi)
+	iss = watcher.TrackedIssue{
+		Repo:      repo,
+		Number:    num,
+		Title:     issue.Title,
+		URL:       issue.URL,
+		Status:    watcher.StatusReacted,
+		StartedAt: time.Now(),
+		CreatedAt: issue.CreatedAt,
+		Attempts:  1,
+	}
+	if meta, err := watcher.ReadIssueMeta(o.baseDir, repo, num); err == nil {
+		iss.Attempts = meta.Attempts + 1
+		iss.PRNumber, iss.PRURL = meta.PRNumber, meta.PRURL
+	}
+
+	logPath := filepath.Join(watcher.IssueDir(o.baseDir, repo, num), watcher.LogFile)
+	logLine := func(text string) {
+		now := time.Now()
+		fmt.Printf("%s  %s\n", now.Format("15:04:05"), text)
+		os.MkdirAll(filepath.Dir(logPath), 0o755)
+		if f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+			f.WriteString(now.UTC().Format(time.RFC3339) + "\t" + watcher.StripANSI(text) + "\n")
+			f.Close()
+		}
+	}
+
+	// Run the pipeline, logging its events as the TUI would; the manager
+	// tracks the status they lead to
+	eventCh := make(chan watcher.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range eventCh {
+			switch ev.Kind {
+			case watcher.EventCloneDone:
+				logLine("Cloned into " + ev.Text)
+			case watcher.EventGateWaiting:
+				// Nobody is there to approve it
+				logLine("⏸ The repo gates " + ev.Text + "; going on, as runs from the command line can't wait for approval")
+				mgr.ContinueIssue(repo, num)
+			case watcher.EventReady:
+				logLine("✅ Ready for review")
+			case watcher.EventCost:
+				logLine("💰 $" + ev.Text)
+			case watcher.EventWarning:
+				logLine("⚠ " + ev.Text)
+			case watcher.EventNeedsInput:
+				logLine("❓ Claude has questions:")
+				for _, q := range strings.Split(strings.TrimSpace(ev.Text), "\n") {
+					logLine("   " + q)
+				}
+			case watcher.EventBuildFailed, watcher.EventTestsFailed:
+				iss.Error = ev.Text
+				logLine("✗ " + ev.Text)
+			case watcher.EventError:
+				if iss.Error == "" {
+					iss.Error = ev.Text
+				}
+				logLine("❌ " + ev.Text)
+			default:
+				logLine(ev.Text)
+			}
+		}
+	}()
+	logLine(fmt.Sprintf("▶ Running %s#%d: %s", repo, num, issue.Title))
+	mgr.RunIssue(ctx, repo, issue, eventCh)
+	close(eventCh)
+	<-done
+	if tracked, ok := mgr.Issue(repo, num); ok {
+		iss.Status, iss.Workdir, iss.Questions = tracked.Status, tracked.Workdir, tracked.Questions
+		iss.CostUSD = tracked.CostUSD
+		if iss.Error == "" {
+			iss.Error = tracked.Error
+		}
+	}
+
+	if iss.Status.Active() || iss.Status == watcher.StatusFailed {
+		iss.Status = watcher.StatusFailed
+		if iss.Error == "" {
+			iss.Error = "run stopped"
+			if ctx.Err() != nil {
+				iss.Error = fmt.Sprintf("run stopped: %v", context.Cause(ctx))
+			}
+		}
+	}
+	defer func() { watcher.WriteIssueMeta(o.baseDir, iss) }()
+
+	switch iss.Status {
+	case watcher.StatusNeedsInput:
+		return iss, &exitError{exitNeedsInput, errors.New("claude needs input; answer in the TUI")}
+	case watcher.StatusReady:
+	default:
+		return iss, &exitError{exitRunFailed, errors.New(iss.Error)}
+	}
+	if !o.openPR {
+		return iss, nil
+	}
+	if iss.PRURL != "" {
+		logLine("PR already open: " + iss.PRURL)
+		return iss, nil
+	}
+	if perms := ghClient.Permissions(); !perms.AllowPush || !perms.AllowPRCreate {
+		return iss, &exitError{exitPRFailed, errors.New("pushing and PR creation are disabled by permissions")}
+	}
+
+	base := mgr.BaseBranch(repo, iss.Workdir)
+	cfg := mgr.RepoConfig(iss.Workdir)
+	prDraft, err := watcher.LoadPRDraft(mgr.Bwrap(), iss.Workdir, num, issue.Title, base, cfg)
+	if err != nil {
+		return iss, &exitError{exitPRFailed, err}
+	}
+	for _, w := range prDraft.Warnings {
+		logLine("⚠️ " + w)
+	}
+	logLine("🚀 Pushing branch & creating PR into " + base + "...")
+	pr, warnings, err := watcher.OpenPR(ctx, ghClient, repo, num, iss.Workdir, watcher.PRSpec{
+		Head:      prDraft.Head,
+		Title:     prDraft.Title,
+		Body:      prDraft.Body,
+		Base:      base,
+		Draft:     o.draft || cfg.DraftPRs,
+		Reviewers: prDraft.Reviewers,
+		Labels:    prDraft.Labels,
+		Assignees: prDraft.Assignees,
+		Summary:   cfg.PRSummary && ghClient.Permissions().AllowComments,
+		CostUSD:   iss.CostUSD,
+		Templates: cfg.Templates,
+	})
+	if exists := (*github.PRExistsError)(nil); errors.As(err, &exists) {
+		iss.PRNumber, iss.PRURL = exists.PR.Number, exists.PR.HTMLURL
+		logLine(fmt.Sprintf("ℹ️ PR #%d already exists — pushed the branch to it: %s", exists.PR.Number, exists.PR.HTMLURL))
+		return iss, nil
+	}
+	if err != nil {
+		logLine("❌ " + err.Error())
+		return iss, &exitError{exitPRFailed, err}
+	}
+	for _, w := range warnings {
+		logLine("⚠️ " + w)
+	}
+	iss.PRNumber, iss.PRURL = pr.Number, pr.HTMLURL
+	logLine("✅ PR created: " + pr.HTMLURL)
+	return iss, nil
+}
+
+// startRunInBackground re-runs `lurker run` with --wait in its own
+// session, its output going to the issue's run.log.
+func startRunInBackground(baseDir, repo string, num int, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	dir := watcher.IssueDir(baseDir, repo, num)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	logPath := filepath.Join(dir, "run.log")
+	out, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	cmd := exec.Command(self, append([]string{"run", "--wait", "--dir", baseDir}, args...)...)
+	cmd.Stdout, cmd.Stderr = out, out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	fmt.Printf("Started %s (pid %d); output in %s\n", watcher.IssueKey(repo, num), cmd.Process.Pid, logPath)
+	return cmd.Process.Release()
+}
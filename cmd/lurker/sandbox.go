@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// runSandboxNet runs a command in a --bwrap sandbox, with the network
+// through lurker's proxy, and exits as it does. lurker runs it; it isn't
+// for people.
+//
+//	lurker sandbox-net SOCKET COMMAND [ARGS...]
+func runSandboxNet(args []string) error {
+	if len(args) < 2 {
+		return &exitError{exitUsage, errors.New("usage: lurker sandbox-net SOCKET COMMAND [ARGS...]")}
+	}
+	code, err := watcher.SandboxNet(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	os.Exit(code)
+	return nil
+}
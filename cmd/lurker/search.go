@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// runSearch greps every issue's lurker.log (and optionally its Claude
+// transcripts) under the base dir, newest match first, e.g. to find which
+// run touched config.yaml last month.
+//
+//	lurker search [--dir DIR] [--repo OWNER/NAME] [--issue N] [--since 30d|DATE] [--until DATE] [--transcripts] [-i] [--limit N] PATTERN
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	baseDir := fs.String("dir", "", "Base directory (default: ~/.local/share/lurker)")
+	repo := fs.String("repo", "", "Only search this repo (owner/name)")
+	issue := fs.Int("issue", 0, "Only search this issue number")
+	sinceFlag := fs.String("since", "", "Only lines from this date or age on (e.g. 2026-03-01, 30d, 36h)")
+	untilFlag := fs.String("until", "", "Only lines before this date or age (e.g. 2026-04-01, 7d)")
+	transcripts := fs.Bool("transcripts", false, "Also search Claude session transcripts")
+	ignoreCase := fs.Bool("i", false, "Case-insensitive match")
+	limit := fs.Int("limit", 0, "Print at most this many (newest) matches; 0 prints all")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: lurker search [flags] PATTERN")
+	}
+	pattern := fs.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	q := watcher.SearchQuery{Pattern: re, Repo: *repo, Number: *issue, Transcripts: *transcripts, Limit: *limit}
+	now := time.Now()
+	if *sinceFlag != "" {
+		if q.Since, err = watcher.ParseSearchTime(*sinceFlag, now); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+	}
+	if *untilFlag != "" {
+		if q.Until, err = watcher.ParseSearchTime(*untilFlag, now); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+	}
+
+	dir := *baseDir
+	if dir == "" {
+		if dir, err = defaultBaseDir(); err != nil {
+			return err
+		}
+	}
+	matches, err := watcher.Search(dir, q)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		stamp := "-"
+		if !m.At.IsZero() {
+			stamp = m.At.Local().Format(time.DateTime)
+		}
+		fmt.Printf("%s#%d\t%s\t%s\t%s\n", m.Repo, m.Number, stamp, m.Source, strings.ReplaceAll(m.Line, "\n", " "))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// runSelftest runs the issue pipeline against a scratch repo with a mock
+// agent and reports each step, as a smoke test after installing or
+// upgrading. Nothing reaches GitHub.
+//
+//	lurker selftest [--repo PATH] [--keep]
+func runSelftest(args []string) error {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	repo := fs.String("repo", "", "Local git repo to run against (default: a built-in fixture)")
+	keep := fs.Bool("keep", false, "Keep the scratch directory for inspection")
+	fs.Parse(args)
+
+	dir, err := os.MkdirTemp("", "lurker-selftest-")
+	if err != nil {
+		return err
+	}
+	if *keep {
+		fmt.Printf("Scratch directory: %s\n", dir)
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = watcher.SelfTest(ctx, watcher.SelfTestOptions{Dir: dir, Repo: *repo}, func(s watcher.SelfTestStep) {
+		if s.Err != nil {
+			fmt.Printf("✗ %-8s %v\n", s.Name, s.Err)
+		} else {
+			fmt.Printf("✓ %-8s %s\n", s.Name, s.Detail)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("selftest failed at %w", err)
+	}
+	fmt.Println("All steps passed.")
+	return nil
+}
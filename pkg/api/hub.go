@@ -0,0 +1,52 @@
+package api
+
+import "sync"
+
+// hubBuffer is how many lines a subscriber may fall behind before lines
+// are dropped for it.
+const hubBuffer = 256
+
+// Hub fans log lines out to subscribers. The zero value is ready to use,
+// and a nil *Hub discards everything.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[chan LogLine]struct{}
+}
+
+// Publish sends l to every subscriber without blocking; a subscriber that
+// isn't keeping up misses the line rather than stalling the caller.
+func (h *Hub) Publish(l LogLine) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- l:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of published lines and a func that
+// unsubscribes and closes it.
+func (h *Hub) Subscribe() (<-chan LogLine, func()) {
+	ch := make(chan LogLine, hubBuffer)
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan LogLine]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
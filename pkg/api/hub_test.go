@@ -0,0 +1,36 @@
+package api
+
+import "testing"
+
+func TestHub(t *testing.T) {
+	var h Hub
+	a, cancelA := h.Subscribe()
+	b, cancelB := h.Subscribe()
+
+	h.Publish(LogLine{Text: "one"})
+	if l := <-a; l.Text != "one" {
+		t.Errorf("a got %q", l.Text)
+	}
+	if l := <-b; l.Text != "one" {
+		t.Errorf("b got %q", l.Text)
+	}
+
+	cancelA()
+	cancelA() // idempotent
+	if _, ok := <-a; ok {
+		t.Error("a not closed after cancel")
+	}
+	h.Publish(LogLine{Text: "two"})
+	if l := <-b; l.Text != "two" {
+		t.Errorf("b got %q", l.Text)
+	}
+
+	// A subscriber that stops reading must not block Publish.
+	for range hubBuffer + 10 {
+		h.Publish(LogLine{Text: "flood"})
+	}
+	cancelB()
+
+	var nilHub *Hub
+	nilHub.Publish(LogLine{Text: "dropped"})
+}
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// JSONRPCServer is a small JSON-RPC 2.0 endpoint for editor extensions,
+// framed like LSP (a Content-Length header before each message) so
+// vscode-jsonrpc and similar libraries can talk to it without gRPC.
+//
+//	lurker/listIssues   {"repo"?, "status"?}  → [issue.json objects]
+//	lurker/openWorktree {"repo", "number"}    → {"path", "branch", "uri"}
+//	lurker/approve      {"repo", "number"}    → {"number", "url", "warnings"}
+//
+// Unless the server has no token, every request's params carry it as
+// "token".
+type JSONRPCServer struct {
+	backend Backend
+	token   string
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	stopped   bool
+}
+
+// maxHeaderSize and maxMessageSize bound a request's header and body;
+// requests are tiny.
+const (
+	maxHeaderSize  = 4 << 10
+	maxMessageSize = 64 << 10
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeNotFound       = -32001
+	codeDenied         = -32002
+	codeUnauthorized   = -32003
+)
+
+// NewJSONRPCServer creates a JSONRPCServer for b, requiring token unless
+// it is empty.
+func NewJSONRPCServer(b Backend, token string) *JSONRPCServer {
+	return &JSONRPCServer{
+		backend:   b,
+		token:     token,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts connections on lis until Stop is called.
+func (s *JSONRPCServer) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		lis.Close()
+		return net.ErrClosed
+	}
+	s.listeners[lis] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			stopped := s.stopped
+			s.mu.Unlock()
+			if stopped {
+				return nil
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Stop closes all listeners and connections.
+func (s *JSONRPCServer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for lis := range s.listeners {
+		lis.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// serveConn handles requests on conn until the peer hangs up: one at a
+// time until one carries the token, so a peer without it can't pile up
+// work, then concurrently (approving can take a while).
+func (s *JSONRPCServer) serveConn(conn net.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	var writeMu sync.Mutex
+	r := bufio.NewReader(conn)
+	authed := s.token == ""
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		if !authed {
+			var resp *rpcResponse
+			resp, authed = s.handle(ctx, body)
+			if resp != nil {
+				writeMessage(conn, resp)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, _ := s.handle(ctx, body); resp != nil {
+				writeMu.Lock()
+				defer writeMu.Unlock()
+				writeMessage(conn, resp)
+			}
+		}()
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"` // absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// handle runs one request and returns its response, or nil for a
+// notification, and whether the request carried the token.
+func (s *JSONRPCServer) handle(ctx context.Context, body []byte) (*rpcResponse, bool) {
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return &rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}}, false
+	}
+	authed := s.authorized(req.Params)
+	var result any
+	var err error
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		err = &rpcError{codeInvalidRequest, "not a JSON-RPC 2.0 request"}
+	} else {
+		result, err = s.call(ctx, req.Method, req.Params)
+	}
+	if len(req.ID) == 0 {
+		return nil, authed
+	}
+	resp := &rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		resp.Error = rpcErrorFor(err)
+	} else {
+		resp.Result = result
+	}
+	return resp, authed
+}
+
+// authorized reports whether params carry the server's token, or the
+// server needs none.
+func (s *JSONRPCServer) authorized(params json.RawMessage) bool {
+	if s.token == "" {
+		return true
+	}
+	var p struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(params, &p)
+	return validToken(p.Token, s.token)
+}
+
+// issueParams covers the params of every method.
+type issueParams struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	Status string `json:"status"`
+	Token  string `json:"token"`
+}
+
+type worktree struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch"`
+	URI    string `json:"uri"`
+}
+
+type pullRequest struct {
+	Number   int      `json:"number"`
+	URL      string   `json:"url"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (s *JSONRPCServer) call(ctx context.Context, method string, raw json.RawMessage) (any, error) {
+	var p issueParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+	}
+	if !s.authorized(raw) {
+		return nil, &rpcError{codeUnauthorized, "missing or wrong API token"}
+	}
+
+	switch method {
+	case "lurker/listIssues":
+		if p.Status != "" {
+			if _, ok := watcher.ParseIssueStatus(p.Status); !ok {
+				return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown status %q", p.Status)}
+			}
+		}
+		issues, err := s.backend.Issues(ctx, p.Repo)
+		if err != nil {
+			return nil, err
+		}
+		out := []watcher.IssueMeta{}
+		for _, iss := range issues {
+			if p.Status == "" || iss.Status.String() == p.Status {
+				out = append(out, iss.Meta())
+			}
+		}
+		return out, nil
+
+	case "lurker/openWorktree":
+		iss, err := s.issue(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if iss.Workdir == "" {
+			return nil, fmt.Errorf("%s#%d has no worktree yet", iss.Repo, iss.Number)
+		}
+		uri := url.URL{Scheme: "file", Path: iss.Workdir}
+		return worktree{Path: iss.Workdir, Branch: watcher.IssueBranch(iss.Number), URI: uri.String()}, nil
+
+	case "lurker/approve":
+		if err := checkParams(p); err != nil {
+			return nil, err
+		}
+		pr, err := s.backend.Approve(ctx, p.Repo, p.Number)
+		if err != nil {
+			return nil, err
+		}
+		return pullRequest{Number: pr.Number, URL: pr.URL, Warnings: pr.Warnings}, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "unknown method " + method}
+}
+
+func (s *JSONRPCServer) issue(ctx context.Context, p issueParams) (watcher.TrackedIssue, error) {
+	if err := checkParams(p); err != nil {
+		return watcher.TrackedIssue{}, err
+	}
+	issues, err := s.backend.Issues(ctx, p.Repo)
+	if err != nil {
+		return watcher.TrackedIssue{}, err
+	}
+	for _, iss := range issues {
+		if iss.Number == p.Number {
+			return iss, nil
+		}
+	}
+	return watcher.TrackedIssue{}, fmt.Errorf("%s#%d: %w", p.Repo, p.Number, ErrNotFound)
+}
+
+func checkParams(p issueParams) error {
+	if !validRepo(p.Repo) || p.Number <= 0 {
+		return &rpcError{codeInvalidParams, "need repo owner/name and an issue number"}
+	}
+	return nil
+}
+
+// rpcErrorFor maps backend errors onto JSON-RPC error codes.
+func rpcErrorFor(err error) *rpcError {
+	var rpcErr *rpcError
+	var permErr *github.PermissionError
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.As(err, &permErr):
+		return &rpcError{codeDenied, err.Error()}
+	case errors.Is(err, ErrNotFound):
+		return &rpcError{codeNotFound, err.Error()}
+	default:
+		return &rpcError{codeServerError, err.Error()}
+	}
+}
+
+// readMessage reads one Content-Length framed message, of at most
+// maxHeaderSize and maxMessageSize.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length, header := -1, 0
+	for {
+		raw, err := r.ReadSlice('\n')
+		if header += len(raw); header > maxHeaderSize || errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("header over %d bytes", maxHeaderSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+		line := strings.TrimRight(string(raw), "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("bad Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 || length > maxMessageSize {
+		return nil, fmt.Errorf("bad or missing Content-Length %d", length)
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// writeMessage writes v as one Content-Length framed message.
+func writeMessage(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// rpcClient sends framed requests over one connection.
+type rpcClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialJSONRPC(t *testing.T, b Backend, token string) *rpcClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewJSONRPCServer(b, token)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &rpcClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *rpcClient) call(id int, method string, params any) rpcResponse {
+	c.t.Helper()
+	req := map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+	if err := writeMessage(c.conn, req); err != nil {
+		c.t.Fatal(err)
+	}
+	body, err := readMessage(c.r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		c.t.Fatal(err)
+	}
+	if string(resp.ID) != strings.TrimSpace(string(mustJSON(id))) {
+		c.t.Fatalf("response id %s, want %d", resp.ID, id)
+	}
+	return resp
+}
+
+func mustJSON(v any) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func TestJSONRPC(t *testing.T) {
+	b := &fakeBackend{issues: []watcher.TrackedIssue{
+		{Repo: "owner/a", Number: 1, Title: "one", Status: watcher.StatusReady, Workdir: "/work/a/1/repo", PRNumber: 5, PRURL: "https://github.com/owner/a/pull/5"},
+		{Repo: "owner/a", Number: 2, Title: "two", Status: watcher.StatusPending},
+	}}
+	c := dialJSONRPC(t, b, "")
+
+	resp := c.call(1, "lurker/listIssues", map[string]any{"status": "ready"})
+	if resp.Error != nil {
+		t.Fatalf("listIssues: %v", resp.Error)
+	}
+	var issues []watcher.IssueMeta
+	json.Unmarshal(mustJSON(resp.Result), &issues)
+	if len(issues) != 1 || issues[0].Number != 1 || issues[0].Branch != "agent/issue-1" {
+		t.Errorf("listIssues = %+v", issues)
+	}
+
+	resp = c.call(2, "lurker/openWorktree", map[string]any{"repo": "owner/a", "number": 1})
+	var wt worktree
+	json.Unmarshal(mustJSON(resp.Result), &wt)
+	if resp.Error != nil || wt.Path != "/work/a/1/repo" || wt.URI != "file:///work/a/1/repo" {
+		t.Errorf("openWorktree = %+v, %v", wt, resp.Error)
+	}
+
+	resp = c.call(3, "lurker/openWorktree", map[string]any{"repo": "owner/a", "number": 2})
+	if resp.Error == nil || resp.Error.Code != codeServerError {
+		t.Errorf("openWorktree without worktree: %+v", resp.Error)
+	}
+
+	resp = c.call(4, "lurker/approve", map[string]any{"repo": "owner/a", "number": 1})
+	var pr pullRequest
+	json.Unmarshal(mustJSON(resp.Result), &pr)
+	if resp.Error != nil || pr.Number != 5 {
+		t.Errorf("approve = %+v, %v", pr, resp.Error)
+	}
+
+	for _, tc := range []struct {
+		method string
+		params any
+		code   int
+	}{
+		{"lurker/approve", map[string]any{"repo": "owner/a", "number": 9}, codeNotFound},
+		{"lurker/approve", map[string]any{"repo": "owner/a"}, codeInvalidParams},
+		{"lurker/approve", map[string]any{"repo": "owner/a", "number": 2}, codeServerError},
+		{"lurker/listIssues", map[string]any{"status": "bogus"}, codeInvalidParams},
+		{"lurker/nope", nil, codeMethodNotFound},
+	} {
+		resp := c.call(5, tc.method, tc.params)
+		if resp.Error == nil || resp.Error.Code != tc.code {
+			t.Errorf("%s %v: error %+v, want code %d", tc.method, tc.params, resp.Error, tc.code)
+		}
+	}
+}
+
+func TestJSONRPC_ParseErrorAndNotification(t *testing.T) {
+	c := dialJSONRPC(t, &fakeBackend{}, "")
+
+	// A notification gets no response, so the next thing read is the
+	// parse error for the garbage that follows it.
+	writeMessage(c.conn, map[string]any{"jsonrpc": "2.0", "method": "lurker/listIssues"})
+	c.conn.Write([]byte("Content-Length: 3\r\n\r\n{x}"))
+	body, err := readMessage(c.r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp rpcResponse
+	json.Unmarshal(body, &resp)
+	if resp.Error == nil || resp.Error.Code != codeParseError || string(resp.ID) != "null" {
+		t.Errorf("response = %s", body)
+	}
+}
+
+func TestJSONRPC_Token(t *testing.T) {
+	c := dialJSONRPC(t, &fakeBackend{}, "s3cret")
+	for i, params := range []any{nil, map[string]any{"token": "wrong"}} {
+		if resp := c.call(i, "lurker/listIssues", params); resp.Error == nil || resp.Error.Code != codeUnauthorized {
+			t.Errorf("listIssues with %v: error %+v, want code %d", params, resp.Error, codeUnauthorized)
+		}
+	}
+	if resp := c.call(2, "lurker/listIssues", map[string]any{"token": "s3cret"}); resp.Error != nil {
+		t.Errorf("listIssues with the token: %v", resp.Error)
+	}
+}
+
+func TestReadMessage_Limits(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		ok   bool
+	}{
+		{"framed", "Content-Length: 2\r\n\r\n{}", true},
+		{"long header line", "X-Pad: " + strings.Repeat("a", maxHeaderSize) + "\r\nContent-Length: 2\r\n\r\n{}", false},
+		{"many header lines", strings.Repeat("X-Pad: a\r\n", maxHeaderSize/10+1) + "Content-Length: 2\r\n\r\n{}", false},
+		{"body too big", fmt.Sprintf("Content-Length: %d\r\n\r\n", maxMessageSize+1), false},
+		{"no length", "\r\n{}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := readMessage(bufio.NewReader(strings.NewReader(tt.in)))
+			if (err == nil) != tt.ok {
+				t.Errorf("readMessage = %q, %v; want ok %v", body, err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: lurker/v1/lurker.proto
+
+package lurkerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Lurker_ListRepos_FullMethodName    = "/lurker.v1.Lurker/ListRepos"
+	Lurker_AddRepo_FullMethodName      = "/lurker.v1.Lurker/AddRepo"
+	Lurker_ListIssues_FullMethodName   = "/lurker.v1.Lurker/ListIssues"
+	Lurker_StartIssue_FullMethodName   = "/lurker.v1.Lurker/StartIssue"
+	Lurker_StopIssue_FullMethodName    = "/lurker.v1.Lurker/StopIssue"
+	Lurker_ApproveIssue_FullMethodName = "/lurker.v1.Lurker/ApproveIssue"
+	Lurker_StreamLogs_FullMethodName   = "/lurker.v1.Lurker/StreamLogs"
+)
+
+// LurkerClient is the client API for Lurker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Lurker is the control surface of a running lurker. Issues are addressed
+// by repo ("owner/name") and issue number.
+type LurkerClient interface {
+	// ListRepos returns the watched repos.
+	ListRepos(ctx context.Context, in *ListReposRequest, opts ...grpc.CallOption) (*ListReposResponse, error)
+	// AddRepo starts watching a repo.
+	AddRepo(ctx context.Context, in *AddRepoRequest, opts ...grpc.CallOption) (*AddRepoResponse, error)
+	// ListIssues returns tracked issues, optionally for a single repo.
+	ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error)
+	// StartIssue starts, resumes or retries processing of an issue.
+	StartIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*Issue, error)
+	// StopIssue pauses an issue that is being processed.
+	StopIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*Issue, error)
+	// ApproveIssue pushes a ready issue's branch and opens its PR, or
+	// returns the PR already opened for it.
+	ApproveIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*PullRequest, error)
+	// StreamLogs sends recent log lines and then follows new ones until the
+	// client goes away.
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error)
+}
+
+type lurkerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLurkerClient(cc grpc.ClientConnInterface) LurkerClient {
+	return &lurkerClient{cc}
+}
+
+func (c *lurkerClient) ListRepos(ctx context.Context, in *ListReposRequest, opts ...grpc.CallOption) (*ListReposResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReposResponse)
+	err := c.cc.Invoke(ctx, Lurker_ListRepos_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) AddRepo(ctx context.Context, in *AddRepoRequest, opts ...grpc.CallOption) (*AddRepoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddRepoResponse)
+	err := c.cc.Invoke(ctx, Lurker_AddRepo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIssuesResponse)
+	err := c.cc.Invoke(ctx, Lurker_ListIssues_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) StartIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*Issue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, Lurker_StartIssue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) StopIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*Issue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, Lurker_StopIssue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) ApproveIssue(ctx context.Context, in *IssueRef, opts ...grpc.CallOption) (*PullRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequest)
+	err := c.cc.Invoke(ctx, Lurker_ApproveIssue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lurkerClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Lurker_ServiceDesc.Streams[0], Lurker_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lurker_StreamLogsClient = grpc.ServerStreamingClient[LogLine]
+
+// LurkerServer is the server API for Lurker service.
+// All implementations must embed UnimplementedLurkerServer
+// for forward compatibility.
+//
+// Lurker is the control surface of a running lurker. Issues are addressed
+// by repo ("owner/name") and issue number.
+type LurkerServer interface {
+	// ListRepos returns the watched repos.
+	ListRepos(context.Context, *ListReposRequest) (*ListReposResponse, error)
+	// AddRepo starts watching a repo.
+	AddRepo(context.Context, *AddRepoRequest) (*AddRepoResponse, error)
+	// ListIssues returns tracked issues, optionally for a single repo.
+	ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error)
+	// StartIssue starts, resumes or retries processing of an issue.
+	StartIssue(context.Context, *IssueRef) (*Issue, error)
+	// StopIssue pauses an issue that is being processed.
+	StopIssue(context.Context, *IssueRef) (*Issue, error)
+	// ApproveIssue pushes a ready issue's branch and opens its PR, or
+	// returns the PR already opened for it.
+	ApproveIssue(context.Context, *IssueRef) (*PullRequest, error)
+	// StreamLogs sends recent log lines and then follows new ones until the
+	// client goes away.
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogLine]) error
+	mustEmbedUnimplementedLurkerServer()
+}
+
+// UnimplementedLurkerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLurkerServer struct{}
+
+func (UnimplementedLurkerServer) ListRepos(context.Context, *ListReposRequest) (*ListReposResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRepos not implemented")
+}
+func (UnimplementedLurkerServer) AddRepo(context.Context, *AddRepoRequest) (*AddRepoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddRepo not implemented")
+}
+func (UnimplementedLurkerServer) ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIssues not implemented")
+}
+func (UnimplementedLurkerServer) StartIssue(context.Context, *IssueRef) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartIssue not implemented")
+}
+func (UnimplementedLurkerServer) StopIssue(context.Context, *IssueRef) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopIssue not implemented")
+}
+func (UnimplementedLurkerServer) ApproveIssue(context.Context, *IssueRef) (*PullRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveIssue not implemented")
+}
+func (UnimplementedLurkerServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogLine]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedLurkerServer) mustEmbedUnimplementedLurkerServer() {}
+func (UnimplementedLurkerServer) testEmbeddedByValue()                {}
+
+// UnsafeLurkerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LurkerServer will
+// result in compilation errors.
+type UnsafeLurkerServer interface {
+	mustEmbedUnimplementedLurkerServer()
+}
+
+func RegisterLurkerServer(s grpc.ServiceRegistrar, srv LurkerServer) {
+	// If the following call pancis, it indicates UnimplementedLurkerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Lurker_ServiceDesc, srv)
+}
+
+func _Lurker_ListRepos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReposRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).ListRepos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_ListRepos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).ListRepos(ctx, req.(*ListReposRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_AddRepo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRepoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).AddRepo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_AddRepo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).AddRepo(ctx, req.(*AddRepoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_ListIssues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).ListIssues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_ListIssues_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).ListIssues(ctx, req.(*ListIssuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_StartIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).StartIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_StartIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).StartIssue(ctx, req.(*IssueRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_StopIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).StopIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_StopIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).StopIssue(ctx, req.(*IssueRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_ApproveIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LurkerServer).ApproveIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lurker_ApproveIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LurkerServer).ApproveIssue(ctx, req.(*IssueRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lurker_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LurkerServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lurker_StreamLogsServer = grpc.ServerStreamingServer[LogLine]
+
+// Lurker_ServiceDesc is the grpc.ServiceDesc for Lurker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Lurker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lurker.v1.Lurker",
+	HandlerType: (*LurkerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRepos",
+			Handler:    _Lurker_ListRepos_Handler,
+		},
+		{
+			MethodName: "AddRepo",
+			Handler:    _Lurker_AddRepo_Handler,
+		},
+		{
+			MethodName: "ListIssues",
+			Handler:    _Lurker_ListIssues_Handler,
+		},
+		{
+			MethodName: "StartIssue",
+			Handler:    _Lurker_StartIssue_Handler,
+		},
+		{
+			MethodName: "StopIssue",
+			Handler:    _Lurker_StopIssue_Handler,
+		},
+		{
+			MethodName: "ApproveIssue",
+			Handler:    _Lurker_ApproveIssue_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _Lurker_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lurker/v1/lurker.proto",
+}
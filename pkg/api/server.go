@@ -0,0 +1,377 @@
+// Package api serves lurker's control surface — list, add, start, stop and
+// log streaming — over gRPC so editor plugins and bots can drive a running
+// lurker. The service is defined in proto/lurker/v1/lurker.proto.
+package api
+
+//go:generate sh -c "cd ../../proto && protoc --go_out=.. --go_opt=module=github.com/stefanpenner/lurker --go-grpc_out=.. --go-grpc_opt=module=github.com/stefanpenner/lurker lurker/v1/lurker.proto"
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/stefanpenner/lurker/pkg/api/lurkerpb"
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// LogLine is one entry in an issue's log.
+type LogLine struct {
+	Repo   string
+	Number int
+	At     time.Time
+	Text   string
+}
+
+// Backend is what the server drives. The TUI implements it so API calls
+// act on the same state (and PTY sessions) as key presses.
+type Backend interface {
+	Repos() []string
+	AddRepo(ctx context.Context, repo string) error
+	// Issues returns tracked issues of repo, or of every repo if empty.
+	Issues(ctx context.Context, repo string) ([]watcher.TrackedIssue, error)
+	// StartIssue and StopIssue return the issue as it is afterwards.
+	StartIssue(ctx context.Context, repo string, num int) (watcher.TrackedIssue, error)
+	StopIssue(ctx context.Context, repo string, num int) (watcher.TrackedIssue, error)
+	// Approve pushes a ready issue's branch and opens its PR, or returns
+	// the PR already opened for it.
+	Approve(ctx context.Context, repo string, num int) (PullRequest, error)
+	// Logs returns up to backlog recent lines of each issue matching repo
+	// and num (empty and zero match everything) and subscribes to new
+	// lines of every issue until cancel is called.
+	Logs(ctx context.Context, repo string, num, backlog int) (recent []LogLine, lines <-chan LogLine, cancel func(), err error)
+}
+
+// PullRequest is the result of approving an issue.
+type PullRequest struct {
+	Number   int
+	URL      string
+	Warnings []string // follow-up steps (reviewers, labels) that failed
+}
+
+// ErrNotFound is returned by a Backend for an unknown repo or issue.
+var ErrNotFound = errors.New("not found")
+
+// Server implements lurkerpb.LurkerServer on top of a Backend.
+type Server struct {
+	lurkerpb.UnimplementedLurkerServer
+	backend Backend
+	token   string
+	grpc    *grpc.Server
+}
+
+// NewServer creates a Server for b. Unless token is empty, every call
+// must carry it as an "authorization: Bearer TOKEN" header.
+func NewServer(b Backend, token string) *Server {
+	s := &Server{backend: b, token: token}
+	s.grpc = grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	lurkerpb.RegisterLurkerServer(s.grpc, s)
+	return s
+}
+
+// authorize checks the bearer token of the call in ctx.
+func (s *Server) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if validToken(strings.TrimPrefix(v, "Bearer "), s.token) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or wrong API token")
+}
+
+// validToken compares got to want in constant time.
+func validToken(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// Serve accepts connections on lis until Stop is called.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Stop closes all connections and open log streams.
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// unixPath returns the socket path of addr, and false if addr is a TCP
+// host:port instead.
+func unixPath(addr string) (string, bool) {
+	path, isUnix := strings.CutPrefix(addr, "unix:")
+	if !isUnix && strings.ContainsRune(addr, '/') {
+		path, isUnix = addr, true
+	}
+	return path, isUnix
+}
+
+// CheckToken refuses to serve the API on a TCP address without a token:
+// anyone who can reach the port could otherwise drive lurker, approving
+// issues included. A unix socket is guarded by its permissions instead.
+func CheckToken(addr, token string) error {
+	if _, isUnix := unixPath(addr); !isUnix && token == "" {
+		return fmt.Errorf("api: %s is a TCP address; set $LURKER_API_TOKEN (or --api-token) to require a token on it, or serve on a unix socket", addr)
+	}
+	return nil
+}
+
+// Listen opens addr for Serve. "unix:PATH" (or a bare path) is a unix
+// socket readable only by the current user; anything else is a TCP
+// host:port.
+func Listen(addr string) (net.Listener, error) {
+	path, isUnix := unixPath(addr)
+	if !isUnix {
+		return net.Listen("tcp", addr)
+	}
+	// A socket left behind by a previous run would make Listen fail.
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("api: %s is in use by another lurker", path)
+	}
+	os.Remove(path)
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
+
+// Dial connects to a lurker serving the API on addr, which is read as by
+// Listen, sending token with every call unless it is empty. The
+// connection is made lazily, so an unreachable lurker shows up as
+// codes.Unavailable from the first call.
+func Dial(addr, token string) (lurkerpb.LurkerClient, *grpc.ClientConn, error) {
+	target := addr
+	path, isUnix := unixPath(addr)
+	if isUnix {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		target = "unix://" + abs
+	} else {
+		target = "passthrough:///" + addr
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lurkerpb.NewLurkerClient(conn), conn, nil
+}
+
+// bearerToken sends itself as the authorization header of each call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false: the API has no TLS to require.
+func (t bearerToken) RequireTransportSecurity() bool { return false }
+
+// The methods below implement lurkerpb.LurkerServer; see lurker.proto.
+
+func (s *Server) ListRepos(ctx context.Context, _ *lurkerpb.ListReposRequest) (*lurkerpb.ListReposResponse, error) {
+	return &lurkerpb.ListReposResponse{Repos: s.backend.Repos()}, nil
+}
+
+func (s *Server) AddRepo(ctx context.Context, req *lurkerpb.AddRepoRequest) (*lurkerpb.AddRepoResponse, error) {
+	repo := strings.TrimSpace(req.GetRepo())
+	if !validRepo(repo) {
+		return nil, status.Errorf(codes.InvalidArgument, "repo must be owner/name, got %q", req.GetRepo())
+	}
+	if err := s.backend.AddRepo(ctx, repo); err != nil {
+		return nil, grpcError(err)
+	}
+	return &lurkerpb.AddRepoResponse{Repo: repo}, nil
+}
+
+func (s *Server) ListIssues(ctx context.Context, req *lurkerpb.ListIssuesRequest) (*lurkerpb.ListIssuesResponse, error) {
+	issues, err := s.backend.Issues(ctx, req.GetRepo())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &lurkerpb.ListIssuesResponse{}
+	for _, iss := range issues {
+		resp.Issues = append(resp.Issues, issueProto(iss))
+	}
+	return resp, nil
+}
+
+func (s *Server) StartIssue(ctx context.Context, ref *lurkerpb.IssueRef) (*lurkerpb.Issue, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	iss, err := s.backend.StartIssue(ctx, ref.GetRepo(), int(ref.GetNumber()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return issueProto(iss), nil
+}
+
+func (s *Server) StopIssue(ctx context.Context, ref *lurkerpb.IssueRef) (*lurkerpb.Issue, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	iss, err := s.backend.StopIssue(ctx, ref.GetRepo(), int(ref.GetNumber()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return issueProto(iss), nil
+}
+
+func (s *Server) ApproveIssue(ctx context.Context, ref *lurkerpb.IssueRef) (*lurkerpb.PullRequest, error) {
+	if err := checkRef(ref); err != nil {
+		return nil, err
+	}
+	pr, err := s.backend.Approve(ctx, ref.GetRepo(), int(ref.GetNumber()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &lurkerpb.PullRequest{Number: int32(pr.Number), Url: pr.URL, Warnings: pr.Warnings}, nil
+}
+
+func (s *Server) StreamLogs(req *lurkerpb.StreamLogsRequest, stream grpc.ServerStreamingServer[lurkerpb.LogLine]) error {
+	ctx := stream.Context()
+	repo, num := req.GetRepo(), int(req.GetNumber())
+	recent, lines, cancel, err := s.backend.Logs(ctx, repo, num, int(req.GetBacklog()))
+	if err != nil {
+		return grpcError(err)
+	}
+	defer cancel()
+
+	for _, l := range recent {
+		if err := stream.Send(logProto(l)); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case l, ok := <-lines:
+			if !ok {
+				return nil
+			}
+			if repo != "" && l.Repo != repo || num != 0 && l.Number != num {
+				continue
+			}
+			if err := stream.Send(logProto(l)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func checkRef(ref *lurkerpb.IssueRef) error {
+	if !validRepo(ref.GetRepo()) || ref.GetNumber() <= 0 {
+		return status.Errorf(codes.InvalidArgument, "need repo owner/name and an issue number, got %q #%d", ref.GetRepo(), ref.GetNumber())
+	}
+	return nil
+}
+
+func validRepo(repo string) bool {
+	owner, name, ok := strings.Cut(repo, "/")
+	return ok && owner != "" && name != "" && !strings.Contains(name, "/")
+}
+
+// grpcError maps backend errors onto gRPC status codes.
+func grpcError(err error) error {
+	var permErr *github.PermissionError
+	switch {
+	case errors.As(err, &permErr):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+}
+
+func issueProto(iss watcher.TrackedIssue) *lurkerpb.Issue {
+	p := &lurkerpb.Issue{
+		Repo:     iss.Repo,
+		Number:   int32(iss.Number),
+		Title:    iss.Title,
+		Url:      iss.URL,
+		Status:   iss.Status.String(),
+		Workdir:  iss.Workdir,
+		PrNumber: int32(iss.PRNumber),
+		PrUrl:    iss.PRURL,
+		Error:    iss.Error,
+		Attempts: int32(iss.Attempts),
+	}
+	if !iss.StartedAt.IsZero() {
+		p.StartedAt = iss.StartedAt.Unix()
+	}
+	return p
+}
+
+// IssueFromProto converts an issue reported over the API to its
+// issue.json form, which readers of the state on disk use.
+func IssueFromProto(p *lurkerpb.Issue) watcher.IssueMeta {
+	m := watcher.IssueMeta{
+		Repo:     p.GetRepo(),
+		Number:   int(p.GetNumber()),
+		Title:    p.GetTitle(),
+		URL:      p.GetUrl(),
+		Status:   p.GetStatus(),
+		Branch:   watcher.IssueBranch(int(p.GetNumber())),
+		Workdir:  p.GetWorkdir(),
+		PRNumber: int(p.GetPrNumber()),
+		PRURL:    p.GetPrUrl(),
+		Error:    p.GetError(),
+		Attempts: int(p.GetAttempts()),
+	}
+	if p.GetStartedAt() != 0 {
+		m.StartedAt = time.Unix(p.GetStartedAt(), 0)
+	}
+	return m
+}
+
+func logProto(l LogLine) *lurkerpb.LogLine {
+	return &lurkerpb.LogLine{
+		Repo:   l.Repo,
+		Number: int32(l.Number),
+		Time:   l.At.UnixMilli(),
+		Text:   l.Text,
+	}
+}
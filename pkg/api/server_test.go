@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/stefanpenner/lurker/pkg/api/lurkerpb"
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+type fakeBackend struct {
+	mu     sync.Mutex
+	repos  []string
+	issues []watcher.TrackedIssue
+	logs   []LogLine
+	hub    Hub
+}
+
+func (f *fakeBackend) Repos() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.repos...)
+}
+
+func (f *fakeBackend) AddRepo(_ context.Context, repo string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.repos = append(f.repos, repo)
+	return nil
+}
+
+func (f *fakeBackend) Issues(_ context.Context, repo string) ([]watcher.TrackedIssue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []watcher.TrackedIssue
+	for _, iss := range f.issues {
+		if repo == "" || iss.Repo == repo {
+			out = append(out, iss)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBackend) setStatus(repo string, num int, from, to watcher.IssueStatus) (watcher.TrackedIssue, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.issues {
+		iss := &f.issues[i]
+		if iss.Repo != repo || iss.Number != num {
+			continue
+		}
+		if iss.Status != from {
+			return *iss, fmt.Errorf("%s#%d is %s", repo, num, iss.Status)
+		}
+		iss.Status = to
+		return *iss, nil
+	}
+	return watcher.TrackedIssue{}, ErrNotFound
+}
+
+func (f *fakeBackend) StartIssue(_ context.Context, repo string, num int) (watcher.TrackedIssue, error) {
+	return f.setStatus(repo, num, watcher.StatusPending, watcher.StatusReacted)
+}
+
+func (f *fakeBackend) StopIssue(_ context.Context, repo string, num int) (watcher.TrackedIssue, error) {
+	return f.setStatus(repo, num, watcher.StatusReacted, watcher.StatusPaused)
+}
+
+func (f *fakeBackend) Approve(_ context.Context, repo string, num int) (PullRequest, error) {
+	iss, err := f.setStatus(repo, num, watcher.StatusReady, watcher.StatusReady)
+	if err != nil {
+		return PullRequest{}, err
+	}
+	if iss.PRNumber == 0 {
+		return PullRequest{}, &github.PermissionError{Action: "allow_pr_create"}
+	}
+	return PullRequest{Number: iss.PRNumber, URL: iss.PRURL}, nil
+}
+
+func (f *fakeBackend) Logs(_ context.Context, repo string, num, backlog int) ([]LogLine, <-chan LogLine, func(), error) {
+	lines, cancel := f.hub.Subscribe()
+	var recent []LogLine
+	for _, l := range f.logs {
+		if l.Repo == repo && l.Number == num {
+			recent = append(recent, l)
+		}
+	}
+	if len(recent) > backlog {
+		recent = recent[len(recent)-backlog:]
+	}
+	return recent, lines, cancel, nil
+}
+
+// dial serves b over an in-memory connection and returns a client.
+func dial(t *testing.T, b Backend) lurkerpb.LurkerClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(b, "")
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return lurkerpb.NewLurkerClient(conn)
+}
+
+func TestServer_ReposAndIssues(t *testing.T) {
+	b := &fakeBackend{
+		repos: []string{"owner/a"},
+		issues: []watcher.TrackedIssue{
+			{Repo: "owner/a", Number: 1, Title: "one", Status: watcher.StatusReady, PRNumber: 7, Attempts: 2},
+			{Repo: "owner/b", Number: 2, Title: "two", Status: watcher.StatusPending},
+		},
+	}
+	c := dial(t, b)
+	ctx := context.Background()
+
+	if _, err := c.AddRepo(ctx, &lurkerpb.AddRepoRequest{Repo: "owner/b"}); err != nil {
+		t.Fatalf("AddRepo: %v", err)
+	}
+	_, err := c.AddRepo(ctx, &lurkerpb.AddRepoRequest{Repo: "nope"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("AddRepo(nope) = %v, want InvalidArgument", err)
+	}
+
+	repos, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if got := repos.GetRepos(); len(got) != 2 || got[1] != "owner/b" {
+		t.Errorf("repos = %v", got)
+	}
+
+	issues, err := c.ListIssues(ctx, &lurkerpb.ListIssuesRequest{Repo: "owner/a"})
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if len(issues.GetIssues()) != 1 {
+		t.Fatalf("issues = %v", issues.GetIssues())
+	}
+	iss := issues.GetIssues()[0]
+	if iss.GetStatus() != "ready" || iss.GetPrNumber() != 7 || iss.GetAttempts() != 2 || iss.GetStartedAt() != 0 {
+		t.Errorf("issue = %v", iss)
+	}
+}
+
+func TestServer_StartStop(t *testing.T) {
+	b := &fakeBackend{issues: []watcher.TrackedIssue{{Repo: "owner/a", Number: 1, Status: watcher.StatusPending}}}
+	c := dial(t, b)
+	ctx := context.Background()
+	ref := &lurkerpb.IssueRef{Repo: "owner/a", Number: 1}
+
+	iss, err := c.StartIssue(ctx, ref)
+	if err != nil || iss.GetStatus() != "reacted" {
+		t.Fatalf("StartIssue = %v, %v", iss, err)
+	}
+	if _, err := c.StartIssue(ctx, ref); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second StartIssue = %v, want FailedPrecondition", err)
+	}
+	iss, err = c.StopIssue(ctx, ref)
+	if err != nil || iss.GetStatus() != "paused" {
+		t.Fatalf("StopIssue = %v, %v", iss, err)
+	}
+	if _, err := c.StopIssue(ctx, &lurkerpb.IssueRef{Repo: "owner/a", Number: 9}); status.Code(err) != codes.NotFound {
+		t.Errorf("StopIssue(#9) = %v, want NotFound", err)
+	}
+	if _, err := c.StartIssue(ctx, &lurkerpb.IssueRef{Repo: "owner/a"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StartIssue without number = %v, want InvalidArgument", err)
+	}
+}
+
+func TestServer_ApproveIssue(t *testing.T) {
+	b := &fakeBackend{issues: []watcher.TrackedIssue{
+		{Repo: "owner/a", Number: 1, Status: watcher.StatusReady, PRNumber: 5, PRURL: "https://github.com/owner/a/pull/5"},
+		{Repo: "owner/a", Number: 2, Status: watcher.StatusReady},
+	}}
+	c := dial(t, b)
+	ctx := context.Background()
+
+	pr, err := c.ApproveIssue(ctx, &lurkerpb.IssueRef{Repo: "owner/a", Number: 1})
+	if err != nil || pr.GetNumber() != 5 {
+		t.Fatalf("ApproveIssue = %v, %v", pr, err)
+	}
+	if _, err := c.ApproveIssue(ctx, &lurkerpb.IssueRef{Repo: "owner/a", Number: 2}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ApproveIssue(#2) = %v, want PermissionDenied", err)
+	}
+}
+
+func TestServer_StreamLogs(t *testing.T) {
+	at := time.UnixMilli(1700000000000)
+	b := &fakeBackend{logs: []LogLine{
+		{Repo: "owner/a", Number: 1, At: at, Text: "old"},
+		{Repo: "owner/a", Number: 1, At: at, Text: "recent"},
+	}}
+	c := dial(t, b)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.StreamLogs(ctx, &lurkerpb.StreamLogsRequest{Repo: "owner/a", Number: 1, Backlog: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.GetText() != "recent" || l.GetTime() != at.UnixMilli() {
+		t.Errorf("backlog line = %v", l)
+	}
+
+	// Keep publishing until the stream has subscribed; lines for other
+	// issues must be filtered out.
+	go func() {
+		for ctx.Err() == nil {
+			b.hub.Publish(LogLine{Repo: "owner/a", Number: 2, Text: "other"})
+			b.hub.Publish(LogLine{Repo: "owner/a", Number: 1, Text: "live"})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	l, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.GetText() != "live" || l.GetNumber() != 1 {
+		t.Errorf("live line = %v", l)
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lurker.sock")
+	lis, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer lis.Close()
+	if _, err := Listen("unix:" + path); err == nil {
+		t.Error("expected error for a socket in use")
+	}
+}
+
+func TestDial_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lurker.sock")
+	c, conn, err := Dial(path, "")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("ListRepos without a server = %v, want Unavailable", err)
+	}
+
+	lis, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv := NewServer(&fakeBackend{repos: []string{"owner/a"}}, "")
+	go srv.Serve(lis)
+	defer srv.Stop()
+	c, conn2, err := Dial("unix:"+path, "")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn2.Close()
+	resp, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{})
+	if err != nil || len(resp.GetRepos()) != 1 {
+		t.Errorf("ListRepos = %v, %v", resp, err)
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lurker.sock")
+	lis, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv := NewServer(&fakeBackend{repos: []string{"owner/a"}}, "s3cret")
+	go srv.Serve(lis)
+	defer srv.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, token := range []string{"", "wrong"} {
+		c, conn, err := Dial(path, token)
+		if err != nil {
+			t.Fatalf("Dial: %v", err)
+		}
+		if _, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("ListRepos with token %q = %v, want Unauthenticated", token, err)
+		}
+		stream, err := c.StreamLogs(ctx, &lurkerpb.StreamLogsRequest{})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("StreamLogs with token %q = %v, want Unauthenticated", token, err)
+		}
+		conn.Close()
+	}
+
+	c, conn, err := Dial(path, "s3cret")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	if resp, err := c.ListRepos(ctx, &lurkerpb.ListReposRequest{}); err != nil || len(resp.GetRepos()) != 1 {
+		t.Errorf("ListRepos with the token = %v, %v", resp, err)
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	for _, tc := range []struct {
+		addr, token string
+		ok          bool
+	}{
+		{"/tmp/lurker.sock", "", true},
+		{"unix:lurker.sock", "", true},
+		{"127.0.0.1:7777", "", false},
+		{":7777", "", false},
+		{":7777", "s3cret", true},
+	} {
+		if err := CheckToken(tc.addr, tc.token); (err == nil) != tc.ok {
+			t.Errorf("CheckToken(%q, %q) = %v, want ok %v", tc.addr, tc.token, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// appAuth authenticates as a GitHub App installation, so reactions,
+// comments and PRs show up under the app's bot account. A JWT signed with
+// the app's private key buys an installation token good for an hour; it is
+// renewed a few minutes before it runs out.
+type appAuth struct {
+	appID string
+	key   *rsa.PrivateKey
+
+	mu             sync.Mutex
+	installationID string // discovered on first use if not configured
+	token          string
+	expires        time.Time
+	slug           string // the app's name in URLs; its bot is "<slug>[bot]"
+}
+
+// tokenSlack is how long before expiry an installation token is renewed.
+const tokenSlack = 5 * time.Minute
+
+// appFromEnv returns the GitHub App configured by GITHUB_APP_ID and
+// GITHUB_APP_PRIVATE_KEY (the PEM itself) or GITHUB_APP_PRIVATE_KEY_FILE,
+// with GITHUB_APP_INSTALLATION_ID picking the installation when the app
+// has several. It returns nil if GITHUB_APP_ID is unset.
+func appFromEnv() (*appAuth, error) {
+	appID := os.Getenv("GITHUB_APP_ID")
+	if appID == "" {
+		return nil, nil
+	}
+	pemData := []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	if path := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"); len(pemData) == 0 && path != "" {
+		var err error
+		if pemData, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("github: reading app key: %w", err)
+		}
+	}
+	if len(pemData) == 0 {
+		return nil, errors.New("github: GITHUB_APP_ID is set but neither GITHUB_APP_PRIVATE_KEY nor GITHUB_APP_PRIVATE_KEY_FILE is")
+	}
+	return newAppAuth(appID, pemData, os.Getenv("GITHUB_APP_INSTALLATION_ID"))
+}
+
+// newAppAuth parses the app's PEM private key, as GitHub hands it out
+// (PKCS #1) or converted to PKCS #8.
+func newAppAuth(appID string, pemData []byte, installationID string) (*appAuth, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("github: app private key is not PEM")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err8 := x509.ParsePKCS8PrivateKey(block.Bytes)
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if err8 != nil || !ok {
+			return nil, fmt.Errorf("github: parsing app private key: %w", err)
+		}
+		key = rsaKey
+	}
+	return &appAuth{appID: appID, key: key, installationID: installationID}, nil
+}
+
+// jwt returns a token identifying the app itself, valid for ten minutes
+// (backdated a minute for clock drift).
+func (a *appAuth) jwt(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("github: signing app JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// installationToken returns a current installation token, minting one if
+// needed.
+func (a *appAuth) installationToken(ctx context.Context, hc *http.Client) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && time.Until(a.expires) > tokenSlack {
+		return a.token, nil
+	}
+	if a.installationID == "" {
+		var installs []struct {
+			ID int64 `json:"id"`
+		}
+		if err := a.call(ctx, hc, http.MethodGet, "/app/installations", &installs); err != nil {
+			return "", err
+		}
+		if len(installs) != 1 {
+			return "", fmt.Errorf("github: app has %d installations; set GITHUB_APP_INSTALLATION_ID", len(installs))
+		}
+		a.installationID = strconv.FormatInt(installs[0].ID, 10)
+	}
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := "/app/installations/" + a.installationID + "/access_tokens"
+	if err := a.call(ctx, hc, http.MethodPost, path, &result); err != nil {
+		return "", err
+	}
+	a.token, a.expires = result.Token, result.ExpiresAt
+	return a.token, nil
+}
+
+// botLogin returns the login the app acts as, e.g. "lurker-bot[bot]".
+func (a *appAuth) botLogin(ctx context.Context, hc *http.Client) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.slug == "" {
+		var app struct {
+			Slug string `json:"slug"`
+		}
+		if err := a.call(ctx, hc, http.MethodGet, "/app", &app); err != nil {
+			return "", err
+		}
+		a.slug = app.Slug
+	}
+	return a.slug + "[bot]", nil
+}
+
+// call makes a request as the app itself and decodes the response into
+// out.
+func (a *appAuth) call(ctx context.Context, hc *http.Client, method, path string, out any) error {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiBase+path, nil)
+	if err != nil {
+		return fmt.Errorf("github: creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("github: app auth: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github: app auth %s: %s: %s", path, resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("github: decoding %s: %w", path, err)
+	}
+	return nil
+}
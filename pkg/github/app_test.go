@@ -0,0 +1,175 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAppKey returns a fresh RSA key and its PKCS #1 PEM.
+func testAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// verifyJWT checks jwt's signature against key and returns its claims.
+func verifyJWT(t *testing.T, jwt string, key *rsa.PublicKey) map[string]any {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts", len(parts))
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		t.Fatalf("JWT signature: %v", err)
+	}
+	data, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]any
+	json.Unmarshal(data, &claims)
+	return claims
+}
+
+func TestAppAuth_MintsAndReusesInstallationToken(t *testing.T) {
+	key, pemData := testAppKey(t)
+	var mints int
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/installations":
+			verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+			w.Write([]byte(`[{"id": 99}]`))
+		case "/app/installations/99/access_tokens":
+			claims := verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+			if claims["iss"] != "123" {
+				t.Errorf("iss = %v, want 123", claims["iss"])
+			}
+			mints++
+			json.NewEncoder(w).Encode(map[string]any{"token": "ghs_install", "expires_at": time.Now().Add(time.Hour)})
+		case "/repos/o/r/pulls/1":
+			gotAuth = r.Header.Get("Authorization")
+			w.Write([]byte(`{"number": 1}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	app, err := newAppAuth("123", pemData, "")
+	if err != nil {
+		t.Fatalf("newAppAuth: %v", err)
+	}
+	c := newClientForTest(srv.Client(), "")
+	c.app = app
+	for range 2 {
+		if _, err := c.GetPR(context.Background(), "o/r", 1); err != nil {
+			t.Fatalf("GetPR: %v", err)
+		}
+	}
+	if gotAuth != "Bearer ghs_install" {
+		t.Errorf("Authorization = %q, want the installation token", gotAuth)
+	}
+	if mints != 1 {
+		t.Errorf("minted %d tokens, want 1", mints)
+	}
+}
+
+func TestAppAuth_RenewsExpiringToken(t *testing.T) {
+	_, pemData := testAppKey(t)
+	var mints int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mints++
+		json.NewEncoder(w).Encode(map[string]any{"token": "ghs_new", "expires_at": time.Now().Add(time.Hour)})
+	}))
+	defer srv.Close()
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	app, err := newAppAuth("123", pemData, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.token, app.expires = "ghs_old", time.Now().Add(time.Minute)
+	token, err := app.installationToken(context.Background(), srv.Client())
+	if err != nil {
+		t.Fatalf("installationToken: %v", err)
+	}
+	if token != "ghs_new" || mints != 1 {
+		t.Errorf("token = %q after %d mints, want a fresh one", token, mints)
+	}
+}
+
+func TestAppAuth_BotLogin(t *testing.T) {
+	_, pemData := testAppKey(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"slug": "lurker-bot"}`))
+	}))
+	defer srv.Close()
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	app, err := newAppAuth("123", pemData, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClientForTest(srv.Client(), "")
+	c.app = app
+	login, err := c.viewerLogin(context.Background())
+	if err != nil {
+		t.Fatalf("viewerLogin: %v", err)
+	}
+	if login != "lurker-bot[bot]" {
+		t.Errorf("login = %q, want lurker-bot[bot]", login)
+	}
+}
+
+func TestNewAppAuth_PKCS8(t *testing.T) {
+	key, _ := testAppKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if _, err := newAppAuth("1", pemData, ""); err != nil {
+		t.Errorf("PKCS #8 key: %v", err)
+	}
+	if _, err := newAppAuth("1", []byte("not a key"), ""); err == nil {
+		t.Error("accepted a key that isn't PEM")
+	}
+}
+
+func TestAppFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_APP_ID", "")
+	if app, err := appFromEnv(); app != nil || err != nil {
+		t.Errorf("appFromEnv() = %v, %v without GITHUB_APP_ID", app, err)
+	}
+	t.Setenv("GITHUB_APP_ID", "123")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY", "")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY_FILE", "")
+	if _, err := appFromEnv(); err == nil {
+		t.Error("appFromEnv accepted an app without a key")
+	}
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckRun is a CI check on a commit, as GitHub shows it on a pull request.
+type CheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`     // "queued", "in_progress" or "completed"
+	Conclusion string `json:"conclusion"` // once completed: "success", "failure", "neutral", "cancelled", "skipped", "timed_out" or "action_required"
+	HTMLURL    string `json:"html_url"`
+}
+
+// PRChecks returns the checks on pull request number's head commit: its
+// check runs, and its commit statuses as check runs, since many CI systems
+// still report through statuses.
+func (c *Client) PRChecks(ctx context.Context, repo string, number int) ([]CheckRun, error) {
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls/%d", apiBase, repo, number), &pr, "get PR"); err != nil {
+		return nil, err
+	}
+
+	var runs struct {
+		CheckRuns []CheckRun `json:"check_runs"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?per_page=100", apiBase, repo, pr.Head.SHA)
+	if err := c.getJSON(ctx, url, &runs, "list check runs"); err != nil {
+		return nil, err
+	}
+	var combined struct {
+		Statuses []CommitStatus `json:"statuses"`
+	}
+	url = fmt.Sprintf("%s/repos/%s/commits/%s/status", apiBase, repo, pr.Head.SHA)
+	if err := c.getJSON(ctx, url, &combined, "get combined status"); err != nil {
+		return nil, err
+	}
+
+	checks := runs.CheckRuns
+	for _, s := range combined.Statuses {
+		check := CheckRun{Name: s.Context, Status: "completed", Conclusion: s.State, HTMLURL: s.TargetURL}
+		switch s.State {
+		case "pending":
+			check.Status, check.Conclusion = "in_progress", ""
+		case "error":
+			check.Conclusion = "failure"
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
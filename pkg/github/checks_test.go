@@ -0,0 +1,48 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPRChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/7":
+			w.Write([]byte(`{"number": 7, "head": {"sha": "abc123"}}`))
+		case "/repos/owner/repo/commits/abc123/check-runs":
+			w.Write([]byte(`{"check_runs": [{"name": "build", "status": "completed", "conclusion": "success"}]}`))
+		case "/repos/owner/repo/commits/abc123/status":
+			w.Write([]byte(`{"statuses": [{"context": "ci/lint", "state": "pending"}, {"context": "ci/test", "state": "error"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	checks, err := c.PRChecks(context.Background(), "owner/repo", 7)
+	if err != nil {
+		t.Fatalf("PRChecks: %v", err)
+	}
+	want := []CheckRun{
+		{Name: "build", Status: "completed", Conclusion: "success"},
+		{Name: "ci/lint", Status: "in_progress"},
+		{Name: "ci/test", Status: "completed", Conclusion: "failure"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("checks[%d] = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
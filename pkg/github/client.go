@@ -1,11 +1,15 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,12 +18,33 @@ import (
 type Client struct {
 	httpClient *http.Client
 	token      string
+	app        *appAuth // set when acting as a GitHub App; token is unused
 	limiter    *rateLimiter
+	perms      Permissions
+	offline    bool // every request fails; see NewOfflineClient
+	maxIssues  int  // cap on ListOpenIssues; 0 for none
+
+	loginMu sync.Mutex
+	login   string // authenticated user, fetched on first use
 }
 
-// NewClient creates a Client, resolving the API token from GITHUB_TOKEN
-// or falling back to `gh auth token`.
+// NewClient creates a Client. It acts as a GitHub App when GITHUB_APP_ID
+// is set (see appFromEnv); otherwise it resolves the API token from
+// GITHUB_TOKEN or falls back to `gh auth token`.
 func NewClient() (*Client, error) {
+	app, err := appFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		return &Client{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+			app:        app,
+			limiter:    newRateLimiter(),
+			perms:      AllowAll(),
+		}, nil
+	}
+
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
 		out, err := exec.Command("gh", "auth", "token").Output()
@@ -36,6 +61,7 @@ func NewClient() (*Client, error) {
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		token:      token,
 		limiter:    newRateLimiter(),
+		perms:      AllowAll(),
 	}, nil
 }
 
@@ -45,18 +71,66 @@ func newClientForTest(httpClient *http.Client, token string) *Client {
 		httpClient: httpClient,
 		token:      token,
 		limiter:    newRateLimiter(),
+		perms:      AllowAll(),
 	}
 }
 
+// NewOfflineClient creates a Client that never reaches GitHub: it has no
+// token and no permissions, and every request fails. It stands in for a
+// real client where lurker runs without GitHub, as in `lurker selftest`.
+func NewOfflineClient() *Client {
+	return &Client{limiter: newRateLimiter(), offline: true}
+}
+
 var apiBase = "https://api.github.com"
 
+// SetMaxIssues caps how many open issues ListOpenIssues returns per repo,
+// so a repo with thousands can't eat the rate limit; n <= 0 lifts the cap.
+// Call it before the client is shared with watchers.
+func (c *Client) SetMaxIssues(n int) { c.maxIssues = max(n, 0) }
+
+// nextPage returns the URL of the next page of a paginated response, from
+// its Link header, or "" on the last page.
+func nextPage(h http.Header) string {
+	for _, link := range strings.Split(h.Get("Link"), ",") {
+		url, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(url), "<>")
+			}
+		}
+	}
+	return ""
+}
+
 // setAPIBase overrides the API base URL (for testing).
 func setAPIBase(url string) { apiBase = url }
 
 // do executes an HTTP request with auth, rate limiting, and retry.
 func (c *Client) do(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.offline {
+		return nil, fmt.Errorf("github: offline: %s %s", req.Method, req.URL.Path)
+	}
+	// Backstop for observer deployments: nothing but reads leaves the
+	// process, even from call sites that forgot their own check.
+	if c.perms.ReadOnly() && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, &PermissionError{Action: "write"}
+	}
+
+	token := c.token
+	if c.app != nil {
+		var err error
+		if token, err = c.app.installationToken(req.Context(), c.httpClient); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if req.Header.Get("Accept") == "" { // some callers ask for another media type
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
 	var resp *http.Response
@@ -99,6 +173,40 @@ func (c *Client) do(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
+// viewerLogin returns the login of the token's user, or of the app's bot.
+func (c *Client) viewerLogin(ctx context.Context) (string, error) {
+	if c.app != nil {
+		return c.app.botLogin(ctx, c.httpClient)
+	}
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	if c.login != "" {
+		return c.login, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBase+"/user", nil)
+	if err != nil {
+		return "", fmt.Errorf("github: creating request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("github: get user: %s: %s", resp.Status, string(body))
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("github: decoding user: %w", err)
+	}
+	c.login = user.Login
+	return c.login, nil
+}
+
 func isRateLimitError(resp *http.Response) bool {
 	return resp.Header.Get("X-RateLimit-Remaining") == "0"
 }
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,6 +17,16 @@ func TestNewClientForTest(t *testing.T) {
 	}
 }
 
+func TestNewOfflineClient(t *testing.T) {
+	c := NewOfflineClient()
+	if !c.Permissions().ReadOnly() {
+		t.Errorf("offline client permissions = %+v, want read-only", c.Permissions())
+	}
+	if _, err := c.GetPR(context.Background(), "o/r", 1); err == nil {
+		t.Error("offline client reached the API")
+	}
+}
+
 func TestDo_SetsAuthHeaders(t *testing.T) {
 	var gotAuth, gotAccept, gotVersion string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -96,3 +107,23 @@ func TestDo_Returns4xxImmediately(t *testing.T) {
 		t.Errorf("should not retry 4xx, but got %d attempts", attempts)
 	}
 }
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"`, "https://api.github.com/repositories/1/issues?page=2"},
+		{`<https://api.github.com/repositories/1/issues?page=1>; rel="prev", <https://api.github.com/repositories/1/issues?page=1>; rel="first"`, ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.link != "" {
+			h.Set("Link", tt.link)
+		}
+		if got := nextPage(h); got != tt.want {
+			t.Errorf("nextPage(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Comment is a comment on an issue or pull request.
+type Comment struct {
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+	IssueURL string `json:"issue_url"` // API URL of the issue or PR
+	User     struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	// AuthorAssociation is the author's standing in the repo: "OWNER",
+	// "MEMBER", "COLLABORATOR", "CONTRIBUTOR", "NONE" and so on.
+	AuthorAssociation string    `json:"author_association"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// IssueNumber returns the number of the issue or PR commented on, or 0.
+func (c Comment) IssueNumber() int {
+	n, _ := strconv.Atoi(c.IssueURL[strings.LastIndex(c.IssueURL, "/")+1:])
+	return n
+}
+
+// ListCommentsSince returns the comments on all of repo's issues and PRs
+// created or edited at or after since, oldest first, following pagination.
+func (c *Client) ListCommentsSince(ctx context.Context, repo string, since time.Time) ([]Comment, error) {
+	u := fmt.Sprintf("%s/repos/%s/issues/comments?sort=created&direction=asc&per_page=100&since=%s",
+		apiBase, repo, url.QueryEscape(since.UTC().Format(time.RFC3339)))
+
+	result := []Comment{}
+	for u != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("github: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("github: list comments: %s: %s", resp.Status, string(body))
+		}
+		var page []Comment
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("github: decoding comments: %w", err)
+		}
+		result = append(result, page...)
+		u = nextPage(resp.Header)
+	}
+	return result, nil
+}
+
+// AddCommentReaction adds a reaction to an issue or PR comment.
+func (c *Client) AddCommentReaction(ctx context.Context, repo string, id int64, reaction string) error {
+	if !c.perms.AllowComments {
+		return &PermissionError{Action: "allow_comments"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/comments/%d/reactions", apiBase, repo, id)
+	return c.postJSON(ctx, url, map[string]string{"content": reaction}, "add comment reaction")
+}
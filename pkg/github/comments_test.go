@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListCommentsSince(t *testing.T) {
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/issues/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("since"); got != "2026-01-02T03:04:05Z" {
+			t.Errorf("since = %q", got)
+		}
+		w.Write([]byte(`[{"id": 7, "body": "@lurker start", "issue_url": "https://api.github.com/repos/owner/repo/issues/42",
+			"user": {"login": "alice"}, "author_association": "MEMBER"}]`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	comments, err := c.ListCommentsSince(context.Background(), "owner/repo", since)
+	if err != nil {
+		t.Fatalf("ListCommentsSince: %v", err)
+	}
+	if len(comments) != 1 || comments[0].IssueNumber() != 42 || comments[0].User.Login != "alice" || comments[0].AuthorAssociation != "MEMBER" {
+		t.Errorf("comments = %+v", comments)
+	}
+}
+
+func TestAddCommentReaction(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/issues/comments/7/reactions" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	if err := c.AddCommentReaction(context.Background(), "owner/repo", 7, "+1"); err != nil {
+		t.Fatalf("AddCommentReaction: %v", err)
+	}
+	if got["content"] != "+1" {
+		t.Errorf("payload = %v", got)
+	}
+	c.SetPermissions(Permissions{})
+	if err := c.AddCommentReaction(context.Background(), "owner/repo", 7, "+1"); err == nil {
+		t.Error("reacted without allow_comments")
+	}
+}
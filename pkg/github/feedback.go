@@ -0,0 +1,100 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Feedback is a review, a comment on a line of the diff, or a comment on
+// the conversation of a pull request.
+type Feedback struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	State     string    `json:"state,omitempty"` // a review's: "CHANGES_REQUESTED" or "COMMENTED"
+	Path      string    `json:"path,omitempty"`  // the file a line comment is on
+	Line      int       `json:"line,omitempty"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type feedbackUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"` // "User" or "Bot"
+}
+
+// PRFeedback returns the feedback people left on pull request number,
+// oldest first: reviews requesting changes or commenting, line comments
+// and conversation comments. Approvals, empty reviews and bots are left
+// out.
+func (c *Client) PRFeedback(ctx context.Context, repo string, number int) ([]Feedback, error) {
+	var reviews []struct {
+		ID          int64        `json:"id"`
+		User        feedbackUser `json:"user"`
+		State       string       `json:"state"`
+		Body        string       `json:"body"`
+		HTMLURL     string       `json:"html_url"`
+		SubmittedAt time.Time    `json:"submitted_at"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews?per_page=100", apiBase, repo, number)
+	if err := c.getJSON(ctx, url, &reviews, "list reviews"); err != nil {
+		return nil, err
+	}
+	var lines []struct {
+		ID           int64        `json:"id"`
+		User         feedbackUser `json:"user"`
+		Path         string       `json:"path"`
+		Line         int          `json:"line"`
+		OriginalLine int          `json:"original_line"`
+		Body         string       `json:"body"`
+		HTMLURL      string       `json:"html_url"`
+		CreatedAt    time.Time    `json:"created_at"`
+	}
+	url = fmt.Sprintf("%s/repos/%s/pulls/%d/comments?per_page=100", apiBase, repo, number)
+	if err := c.getJSON(ctx, url, &lines, "list review comments"); err != nil {
+		return nil, err
+	}
+	var comments []struct {
+		ID        int64        `json:"id"`
+		User      feedbackUser `json:"user"`
+		Body      string       `json:"body"`
+		HTMLURL   string       `json:"html_url"`
+		CreatedAt time.Time    `json:"created_at"`
+	}
+	url = fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=100", apiBase, repo, number)
+	if err := c.getJSON(ctx, url, &comments, "list comments"); err != nil {
+		return nil, err
+	}
+
+	var feedback []Feedback
+	for _, r := range reviews {
+		body := strings.TrimSpace(r.Body)
+		switch {
+		case r.User.Type == "Bot":
+		case r.State == "CHANGES_REQUESTED", r.State == "COMMENTED" && body != "":
+			feedback = append(feedback, Feedback{ID: r.ID, Author: r.User.Login, State: r.State, Body: body, URL: r.HTMLURL, CreatedAt: r.SubmittedAt})
+		}
+	}
+	for _, l := range lines {
+		if l.User.Type == "Bot" {
+			continue
+		}
+		line := l.Line
+		if line == 0 {
+			// Outdated: the line is gone from the latest diff.
+			line = l.OriginalLine
+		}
+		feedback = append(feedback, Feedback{ID: l.ID, Author: l.User.Login, Path: l.Path, Line: line, Body: strings.TrimSpace(l.Body), URL: l.HTMLURL, CreatedAt: l.CreatedAt})
+	}
+	for _, cm := range comments {
+		if cm.User.Type == "Bot" {
+			continue
+		}
+		feedback = append(feedback, Feedback{ID: cm.ID, Author: cm.User.Login, Body: strings.TrimSpace(cm.Body), URL: cm.HTMLURL, CreatedAt: cm.CreatedAt})
+	}
+	slices.SortStableFunc(feedback, func(a, b Feedback) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return feedback, nil
+}
@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPRFeedback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/7/reviews":
+			w.Write([]byte(`[
+				{"id": 1, "user": {"login": "alice"}, "state": "APPROVED", "body": "LGTM", "submitted_at": "2024-01-01T10:00:00Z"},
+				{"id": 2, "user": {"login": "bob"}, "state": "CHANGES_REQUESTED", "body": "Needs tests.", "submitted_at": "2024-01-01T12:00:00Z"},
+				{"id": 3, "user": {"login": "bob"}, "state": "COMMENTED", "body": "", "submitted_at": "2024-01-01T12:00:00Z"}
+			]`))
+		case "/repos/owner/repo/pulls/7/comments":
+			w.Write([]byte(`[
+				{"id": 10, "user": {"login": "bob"}, "path": "main.go", "line": 12, "body": "Check this error.", "created_at": "2024-01-01T11:00:00Z"},
+				{"id": 11, "user": {"login": "bob"}, "path": "old.go", "line": 0, "original_line": 3, "body": " Typo. ", "created_at": "2024-01-01T11:30:00Z"}
+			]`))
+		case "/repos/owner/repo/issues/7/comments":
+			w.Write([]byte(`[
+				{"id": 20, "user": {"login": "ci-bot", "type": "Bot"}, "body": "Coverage: 80%", "created_at": "2024-01-01T09:00:00Z"},
+				{"id": 21, "user": {"login": "carol", "type": "User"}, "body": "Also update the docs?", "created_at": "2024-01-01T13:00:00Z"}
+			]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	feedback, err := c.PRFeedback(context.Background(), "owner/repo", 7)
+	if err != nil {
+		t.Fatalf("PRFeedback: %v", err)
+	}
+	var ids []int64
+	for _, f := range feedback {
+		ids = append(ids, f.ID)
+	}
+	want := []int64{10, 11, 2, 21}
+	if len(ids) != len(want) {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", ids, want)
+		}
+	}
+	if f := feedback[1]; f.Path != "old.go" || f.Line != 3 || f.Body != "Typo." {
+		t.Errorf("outdated line comment = %+v", f)
+	}
+	if f := feedback[2]; f.State != "CHANGES_REQUESTED" || f.Author != "bob" {
+		t.Errorf("review = %+v", f)
+	}
+}
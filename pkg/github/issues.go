@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +18,7 @@ type Issue struct {
 	Body        string    `json:"body"`
 	Labels      []Label   `json:"labels"`
 	URL         string    `json:"html_url"`
+	State       string    `json:"state"`
 	CreatedAt   time.Time `json:"created_at"`
 	PullRequest *struct{} `json:"pull_request,omitempty"`
 }
@@ -25,10 +28,88 @@ type Label struct {
 	Name string `json:"name"`
 }
 
-// ListOpenIssues returns open issues for the given "owner/repo", excluding PRs.
+// ListOpenIssues returns open issues for the given "owner/repo", excluding
+// PRs, following pagination up to the cap set by SetMaxIssues.
 func (c *Client) ListOpenIssues(ctx context.Context, repo string) ([]Issue, error) {
 	url := fmt.Sprintf("%s/repos/%s/issues?state=open&per_page=100", apiBase, repo)
 
+	filtered := []Issue{}
+	for first := true; url != ""; first = false {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("github: creating request: %w", err)
+		}
+		issues, next, err := c.listIssuesPage(ctx, req, repo, first)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out pull requests
+		for _, iss := range issues {
+			if iss.PullRequest == nil {
+				filtered = append(filtered, iss)
+			}
+		}
+		if c.maxIssues > 0 && len(filtered) >= c.maxIssues {
+			return filtered[:c.maxIssues], nil
+		}
+		url = next
+	}
+	return filtered, nil
+}
+
+// listIssuesPage fetches one page of ListOpenIssues and the URL of the
+// next. Only the first page is checked for a moved repo.
+func (c *Client) listIssuesPage(ctx context.Context, req *http.Request, repo string, first bool) ([]Issue, string, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("github: list issues: %s: %s", resp.Status, string(body))
+	}
+
+	// GitHub answers requests for a renamed or transferred repo with a 301 to
+	// /repositories/<id>/...; the HTTP client follows it transparently, so
+	// compare the final path against the one we asked for.
+	if first && wasRedirected(req, resp) {
+		newName, err := c.RepoFullName(ctx, repo)
+		if err != nil {
+			return nil, "", err
+		}
+		if !strings.EqualFold(newName, repo) {
+			return nil, "", &RepoMovedError{Repo: repo, NewRepo: newName}
+		}
+	}
+
+	var issues []Issue
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, "", fmt.Errorf("github: decoding issues: %w", err)
+	}
+	return issues, nextPage(resp.Header), nil
+}
+
+// IssueMovedError is returned when an issue has been transferred to
+// another repo. NewRepo and NewNumber locate it at its destination.
+type IssueMovedError struct {
+	Repo      string
+	Number    int
+	NewRepo   string
+	NewNumber int
+}
+
+func (e *IssueMovedError) Error() string {
+	return fmt.Sprintf("github: %s#%d moved to %s#%d", e.Repo, e.Number, e.NewRepo, e.NewNumber)
+}
+
+// GetIssue fetches a single issue. If the issue was transferred to another
+// repo, it returns an *IssueMovedError describing the new location.
+func (c *Client) GetIssue(ctx context.Context, repo string, number int) (*Issue, error) {
+	url := fmt.Sprintf("%s/repos/%s/issues/%d", apiBase, repo, number)
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("github: creating request: %w", err)
@@ -42,27 +123,42 @@ func (c *Client) ListOpenIssues(ctx context.Context, repo string) ([]Issue, erro
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("github: list issues: %s: %s", resp.Status, string(body))
+		return nil, fmt.Errorf("github: get issue: %s: %s", resp.Status, string(body))
 	}
 
-	var issues []Issue
-	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
-		return nil, fmt.Errorf("github: decoding issues: %w", err)
+	var issue Issue
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return nil, fmt.Errorf("github: decoding issue: %w", err)
 	}
 
-	// Filter out pull requests
-	filtered := make([]Issue, 0, len(issues))
-	for _, iss := range issues {
-		if iss.PullRequest == nil {
-			filtered = append(filtered, iss)
+	if newRepo, newNum, ok := parseIssueURL(issue.URL); ok {
+		if !strings.EqualFold(newRepo, repo) || newNum != number {
+			return &issue, &IssueMovedError{Repo: repo, Number: number, NewRepo: newRepo, NewNumber: newNum}
 		}
 	}
 
-	return filtered, nil
+	return &issue, nil
+}
+
+// parseIssueURL extracts "owner/repo" and the number from an issue's
+// html_url, e.g. https://github.com/owner/repo/issues/42.
+func parseIssueURL(u string) (string, int, bool) {
+	parts := strings.Split(strings.TrimSuffix(u, "/"), "/")
+	if len(parts) < 4 || parts[len(parts)-2] != "issues" {
+		return "", 0, false
+	}
+	num, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[len(parts)-4] + "/" + parts[len(parts)-3], num, true
 }
 
 // AddReaction adds a reaction to an issue.
 func (c *Client) AddReaction(ctx context.Context, repo string, number int, reaction string) error {
+	if !c.perms.AllowComments {
+		return &PermissionError{Action: "allow_comments"}
+	}
 	url := fmt.Sprintf("%s/repos/%s/issues/%d/reactions", apiBase, repo, number)
 
 	body := fmt.Sprintf(`{"content":%q}`, reaction)
@@ -86,3 +182,72 @@ func (c *Client) AddReaction(ctx context.Context, repo string, number int, react
 
 	return nil
 }
+
+// CreateComment comments on an issue or pull request.
+func (c *Client) CreateComment(ctx context.Context, repo string, number int, body string) error {
+	if !c.perms.AllowComments {
+		return &PermissionError{Action: "allow_comments"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", apiBase, repo, number)
+	return c.postJSON(ctx, url, map[string]string{"body": body}, "create comment")
+}
+
+// reaction is an entry from the issue reactions list.
+type reaction struct {
+	ID      int64  `json:"id"`
+	Content string `json:"content"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// RemoveReaction deletes the authenticated user's reaction of the given
+// kind from an issue. It is not an error if there is none.
+func (c *Client) RemoveReaction(ctx context.Context, repo string, number int, content string) error {
+	if !c.perms.AllowComments {
+		return &PermissionError{Action: "allow_comments"}
+	}
+	login, err := c.viewerLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/reactions?content=%s&per_page=100", apiBase, repo, number, content)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("github: creating request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github: list reactions: %s: %s", resp.Status, string(body))
+	}
+	var reactions []reaction
+	if err := json.NewDecoder(resp.Body).Decode(&reactions); err != nil {
+		return fmt.Errorf("github: decoding reactions: %w", err)
+	}
+
+	for _, r := range reactions {
+		if r.Content != content || !strings.EqualFold(r.User.Login, login) {
+			continue
+		}
+		url := fmt.Sprintf("%s/repos/%s/issues/%d/reactions/%d", apiBase, repo, number, r.ID)
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			return fmt.Errorf("github: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("github: delete reaction: %s", resp.Status)
+		}
+	}
+	return nil
+}
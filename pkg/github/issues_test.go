@@ -3,6 +3,8 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -49,6 +51,54 @@ func TestListOpenIssues(t *testing.T) {
 	}
 }
 
+func TestListOpenIssues_Paginates(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		var issues []Issue
+		switch page {
+		case "":
+			issues = []Issue{{Number: 1}, {Number: 2, PullRequest: &struct{}{}}}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repositories/1/issues?state=open&page=2>; rel="next", <%s/repositories/1/issues?state=open&page=3>; rel="last"`, srv.URL, srv.URL))
+		case "2":
+			issues = []Issue{{Number: 3}, {Number: 4}}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repositories/1/issues?state=open&page=3>; rel="next"`, srv.URL))
+		case "3":
+			issues = []Issue{{Number: 5}}
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+		json.NewEncoder(w).Encode(issues)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	result, err := c.ListOpenIssues(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	var nums []int
+	for _, iss := range result {
+		nums = append(nums, iss.Number)
+	}
+	if fmt.Sprint(nums) != "[1 3 4 5]" {
+		t.Errorf("issues = %v, want [1 3 4 5] across all pages", nums)
+	}
+
+	c.SetMaxIssues(2)
+	result, err = c.ListOpenIssues(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	if len(result) != 2 || result[1].Number != 3 {
+		t.Errorf("capped issues = %+v, want #1 and #3", result)
+	}
+}
+
 func TestListOpenIssues_Error(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -100,3 +150,121 @@ func TestAddReaction(t *testing.T) {
 		t.Errorf("body content = %q, want 'eyes'", gotBody["content"])
 	}
 }
+
+func TestCreateComment(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	if err := c.CreateComment(context.Background(), "owner/repo", 7, "hello"); err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if gotPath != "/repos/owner/repo/issues/7/comments" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotBody["body"] != "hello" {
+		t.Errorf("body = %q", gotBody["body"])
+	}
+
+	c.SetPermissions(Permissions{AllowPRCreate: true})
+	var permErr *PermissionError
+	if err := c.CreateComment(context.Background(), "owner/repo", 7, "hello"); !errors.As(err, &permErr) {
+		t.Errorf("err = %v, want PermissionError", err)
+	}
+}
+
+func TestRemoveReaction(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			w.Write([]byte(`{"login": "me"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/issues/42/reactions":
+			if got := r.URL.Query().Get("content"); got != "eyes" {
+				t.Errorf("content = %q", got)
+			}
+			w.Write([]byte(`[
+				{"id": 1, "content": "eyes", "user": {"login": "someone"}},
+				{"id": 2, "content": "eyes", "user": {"login": "Me"}}
+			]`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	if err := c.RemoveReaction(context.Background(), "owner/repo", 42, "eyes"); err != nil {
+		t.Fatalf("RemoveReaction: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/repos/owner/repo/issues/42/reactions/2" {
+		t.Errorf("deleted = %v, want only our own reaction", deleted)
+	}
+}
+
+func TestGetIssue_Transferred(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/5":
+			http.Redirect(w, r, "/repositories/2/issues/9", http.StatusMovedPermanently)
+		case "/repositories/2/issues/9":
+			json.NewEncoder(w).Encode(Issue{Number: 9, URL: "https://github.com/other/place/issues/9"})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	_, err := c.GetIssue(context.Background(), "owner/repo", 5)
+	var moved *IssueMovedError
+	if !errors.As(err, &moved) {
+		t.Fatalf("expected IssueMovedError, got %v", err)
+	}
+	if moved.NewRepo != "other/place" || moved.NewNumber != 9 {
+		t.Errorf("moved = %+v", moved)
+	}
+}
+
+func TestGetIssue_NotMoved(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Issue{Number: 5, State: "closed", URL: "https://github.com/owner/repo/issues/5"})
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	issue, err := c.GetIssue(context.Background(), "owner/repo", 5)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if issue.State != "closed" {
+		t.Errorf("state = %q, want closed", issue.State)
+	}
+}
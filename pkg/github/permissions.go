@@ -0,0 +1,60 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Permissions gates every action lurker can take that is visible to other
+// people. The zero value allows nothing; use AllowAll for the default.
+type Permissions struct {
+	AllowPRCreate  bool `json:"allow_pr_create"`
+	AllowComments  bool `json:"allow_comments"` // comments and reactions on issues
+	AllowAutoStart bool `json:"allow_auto_start"`
+	AllowPush      bool `json:"allow_push"`
+}
+
+// AllowAll is the default role: every action is permitted.
+func AllowAll() Permissions {
+	return Permissions{AllowPRCreate: true, AllowComments: true, AllowAutoStart: true, AllowPush: true}
+}
+
+// ReadOnly reports whether no mutating action is permitted (an "observer").
+func (p Permissions) ReadOnly() bool {
+	return !p.AllowPRCreate && !p.AllowComments && !p.AllowAutoStart && !p.AllowPush
+}
+
+// PermissionError is returned when an action is disabled by Permissions.
+type PermissionError struct {
+	Action string // the config switch that denied it, e.g. "allow_pr_create"
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("github: %s is disabled", e.Action)
+}
+
+// LoadPermissions reads a permissions file. Switches missing from the file
+// keep their AllowAll value; a missing file allows everything.
+func LoadPermissions(path string) (Permissions, error) {
+	perms := AllowAll()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return perms, nil
+	}
+	if err != nil {
+		return perms, err
+	}
+	if err := json.Unmarshal(data, &perms); err != nil {
+		return perms, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return perms, nil
+}
+
+// SetPermissions restricts what the client may do. Call it before the
+// client is shared with watchers.
+func (c *Client) SetPermissions(p Permissions) { c.perms = p }
+
+// Permissions returns the client's current permissions.
+func (c *Client) Permissions() Permissions { return c.perms }
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPermissions_Missing(t *testing.T) {
+	p, err := LoadPermissions(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Fatalf("LoadPermissions: %v", err)
+	}
+	if p != AllowAll() {
+		t.Errorf("missing file = %+v, want AllowAll", p)
+	}
+}
+
+func TestLoadPermissions_Partial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "permissions.json")
+	os.WriteFile(path, []byte(`{"allow_push": false, "allow_pr_create": false}`), 0o644)
+
+	p, err := LoadPermissions(path)
+	if err != nil {
+		t.Fatalf("LoadPermissions: %v", err)
+	}
+	want := Permissions{AllowComments: true, AllowAutoStart: true}
+	if p != want {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+	if p.ReadOnly() {
+		t.Error("partial permissions should not be read-only")
+	}
+}
+
+func TestPermissions_BlockWrites(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 1}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	c.SetPermissions(Permissions{AllowComments: true})
+	_, err := c.CreatePR(context.Background(), CreatePRRequest{Repo: "owner/repo"})
+	var perr *PermissionError
+	if !errors.As(err, &perr) || perr.Action != "allow_pr_create" {
+		t.Errorf("CreatePR err = %v, want allow_pr_create PermissionError", err)
+	}
+	if err := c.AddReaction(context.Background(), "owner/repo", 1, "eyes"); err != nil {
+		t.Errorf("AddReaction: %v", err)
+	}
+
+	// Observer: even a raw write through do() is refused.
+	c.SetPermissions(Permissions{})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/anything", nil)
+	if _, err := c.do(req); !errors.As(err, &perr) {
+		t.Errorf("do(POST) err = %v, want PermissionError", err)
+	}
+
+	if hits != 1 {
+		t.Errorf("server saw %d requests, want 1", hits)
+	}
+}
@@ -3,10 +3,13 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
 // CreatePRRequest contains the fields needed to create a pull request.
@@ -16,28 +19,65 @@ type CreatePRRequest struct {
 	Body  string
 	Head  string // branch name
 	Base  string // target branch (e.g. "main")
+	Draft bool
 }
 
-// PullRequest is the response from creating a PR.
+// PullRequest is the subset of a pull request lurker uses.
 type PullRequest struct {
-	Number  int    `json:"number"`
-	HTMLURL string `json:"html_url"`
+	Number   int       `json:"number"`
+	HTMLURL  string    `json:"html_url"`
+	State    string    `json:"state,omitempty"`    // "open" or "closed"
+	MergedAt time.Time `json:"merged_at,omitzero"` // zero unless merged
 }
 
-// CreatePR creates a pull request on the given repo.
-func (c *Client) CreatePR(ctx context.Context, pr CreatePRRequest) (*PullRequest, error) {
-	url := fmt.Sprintf("%s/repos/%s/pulls", apiBase, pr.Repo)
-
-	payload := map[string]string{
-		"title": pr.Title,
-		"body":  pr.Body,
-		"head":  pr.Head,
-		"base":  pr.Base,
+// Payload returns the JSON body CreatePR sends for pr, rejecting requests
+// GitHub would refuse for lack of a title, head or base.
+func (pr CreatePRRequest) Payload() ([]byte, error) {
+	switch {
+	case pr.Title == "":
+		return nil, fmt.Errorf("github: PR request has no title")
+	case pr.Head == "" || pr.Base == "":
+		return nil, fmt.Errorf("github: PR request needs a head and a base")
+	case pr.Head == pr.Base:
+		return nil, fmt.Errorf("github: PR head and base are both %s", pr.Head)
 	}
+	payload := struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Draft bool   `json:"draft,omitempty"`
+	}{pr.Title, pr.Body, pr.Head, pr.Base, pr.Draft}
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("github: marshaling PR request: %w", err)
 	}
+	return data, nil
+}
+
+// PRExistsError is returned by CreatePR when the head branch already
+// has an open pull request, which PR is.
+type PRExistsError struct {
+	Repo string
+	PR   PullRequest
+}
+
+func (e *PRExistsError) Error() string {
+	return fmt.Sprintf("PR #%d already exists: %s", e.PR.Number, e.PR.HTMLURL)
+}
+
+// CreatePR creates a pull request on the given repo. If the head branch
+// has one open already, it returns a *PRExistsError describing it.
+func (c *Client) CreatePR(ctx context.Context, pr CreatePRRequest) (*PullRequest, error) {
+	if !c.perms.AllowPRCreate {
+		return nil, &PermissionError{Action: "allow_pr_create"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls", apiBase, pr.Repo)
+
+	data, err := pr.Payload()
+	if err != nil {
+		return nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(data)))
 	if err != nil {
@@ -53,6 +93,11 @@ func (c *Client) CreatePR(ctx context.Context, pr CreatePRRequest) (*PullRequest
 
 	if resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnprocessableEntity && strings.Contains(string(body), "already exists") {
+			if open, err := c.FindPR(ctx, pr.Repo, pr.Head); err == nil && open != nil {
+				return nil, &PRExistsError{Repo: pr.Repo, PR: *open}
+			}
+		}
 		return nil, fmt.Errorf("github: create PR: %s: %s", resp.Status, string(body))
 	}
 
@@ -64,6 +109,172 @@ func (c *Client) CreatePR(ctx context.Context, pr CreatePRRequest) (*PullRequest
 	return &result, nil
 }
 
+// FindPR returns the open pull request whose head is head, a branch of
+// repo or "owner:branch" for a fork's, or nil if there is none.
+func (c *Client) FindPR(ctx context.Context, repo, head string) (*PullRequest, error) {
+	if !strings.Contains(head, ":") {
+		owner, _, _ := strings.Cut(repo, "/")
+		head = owner + ":" + head
+	}
+	u := fmt.Sprintf("%s/repos/%s/pulls?state=open&head=%s", apiBase, repo, url.QueryEscape(head))
+	var prs []PullRequest
+	if err := c.getJSON(ctx, u, &prs, "find PR"); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return &prs[0], nil
+}
+
+// EditPR replaces the title and body of pull request number.
+func (c *Client) EditPR(ctx context.Context, repo string, number int, title, body string) error {
+	if !c.perms.AllowPRCreate {
+		return &PermissionError{Action: "allow_pr_create"}
+	}
+	u := fmt.Sprintf("%s/repos/%s/pulls/%d", apiBase, repo, number)
+	return c.sendJSON(ctx, http.MethodPatch, u, map[string]string{"title": title, "body": body}, "edit PR")
+}
+
+// GetPR fetches a pull request.
+func (c *Client) GetPR(ctx context.Context, repo string, number int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d", apiBase, repo, number)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("github: creating request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("github: get PR: %s: %s", resp.Status, string(body))
+	}
+
+	var result PullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("github: decoding PR: %w", err)
+	}
+	return &result, nil
+}
+
+// RequestReviewers asks the given users to review a pull request.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, number int, reviewers []string) error {
+	if !c.perms.AllowPRCreate {
+		return &PermissionError{Action: "allow_pr_create"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", apiBase, repo, number)
+	return c.postJSON(ctx, url, map[string][]string{"reviewers": reviewers}, "request reviewers")
+}
+
+// AddLabels adds labels to an issue or pull request.
+func (c *Client) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	if !c.perms.AllowPRCreate {
+		return &PermissionError{Action: "allow_pr_create"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/labels", apiBase, repo, number)
+	return c.postJSON(ctx, url, map[string][]string{"labels": labels}, "add labels")
+}
+
+// AddAssignees assigns users to an issue or pull request.
+func (c *Client) AddAssignees(ctx context.Context, repo string, number int, assignees []string) error {
+	if !c.perms.AllowPRCreate {
+		return &PermissionError{Action: "allow_pr_create"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/assignees", apiBase, repo, number)
+	return c.postJSON(ctx, url, map[string][]string{"assignees": assignees}, "add assignees")
+}
+
+// ErrUpdateConflict is returned by UpdateBranch when the base branch
+// can't be merged into the PR's branch without conflicts.
+var ErrUpdateConflict = errors.New("github: update branch: merge conflict")
+
+// UpdateBranch has GitHub merge the base branch into pull request
+// number's branch. GitHub does so in the background after accepting.
+func (c *Client) UpdateBranch(ctx context.Context, repo string, number int) error {
+	if !c.perms.AllowPush {
+		return &PermissionError{Action: "allow_push"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/update-branch", apiBase, repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, nil)
+	if err != nil {
+		return fmt.Errorf("github: creating request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnprocessableEntity && strings.Contains(strings.ToLower(string(body)), "conflict") {
+			return ErrUpdateConflict
+		}
+		return fmt.Errorf("github: update branch: %s: %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// postJSON POSTs payload to url and expects a 2xx response. what names the
+// operation in errors.
+func (c *Client) postJSON(ctx context.Context, url string, payload any, what string) error {
+	return c.sendJSON(ctx, http.MethodPost, url, payload, what)
+}
+
+// sendJSON sends payload to url with method and expects a 2xx response.
+func (c *Client) sendJSON(ctx context.Context, method, url string, payload any, what string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("github: marshaling %s request: %w", what, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(string(data)))
+	if err != nil {
+		return fmt.Errorf("github: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github: %s: %s: %s", what, resp.Status, string(body))
+	}
+	return nil
+}
+
+// getJSON GETs url and decodes the JSON response into out. what names the
+// operation in errors.
+func (c *Client) getJSON(ctx context.Context, url string, out any, what string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("github: creating request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github: %s: %s: %s", what, resp.Status, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("github: decoding %s response: %w", what, err)
+	}
+	return nil
+}
+
 // stringReader is a helper to create an io.Reader from a string.
 func stringReader(s string) io.Reader {
 	return strings.NewReader(s)
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -82,3 +83,178 @@ func TestCreatePR_Error(t *testing.T) {
 		t.Fatal("expected error for 422 response")
 	}
 }
+
+func TestCreatePR_Exists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message":"Validation Failed","errors":[{"resource":"PullRequest","code":"custom","message":"A pull request already exists for owner:agent/issue-1."}]}`))
+		case r.URL.Query().Get("head") == "owner:agent/issue-1" && r.URL.Query().Get("state") == "open":
+			w.Write([]byte(`[{"number": 42, "html_url": "https://github.com/owner/repo/pull/42", "state": "open"}]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	_, err := c.CreatePR(context.Background(), CreatePRRequest{Repo: "owner/repo", Title: "Fix #1", Head: "agent/issue-1", Base: "main"})
+	var exists *PRExistsError
+	if !errors.As(err, &exists) || exists.PR.Number != 42 || exists.Error() != "PR #42 already exists: https://github.com/owner/repo/pull/42" {
+		t.Errorf("err = %v, want a PRExistsError for #42", err)
+	}
+}
+
+func TestEditPR(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/repos/owner/repo/pulls/42" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"number": 42}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	if err := c.EditPR(context.Background(), "owner/repo", 42, "New title", "New body"); err != nil {
+		t.Fatalf("EditPR: %v", err)
+	}
+	if got["title"] != "New title" || got["body"] != "New body" {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestCreatePR_DraftReviewersLabels(t *testing.T) {
+	got := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		got[r.URL.Path] = body
+		if r.URL.Path == "/repos/owner/repo/pulls" {
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(PullRequest{Number: 7})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	ctx := context.Background()
+	pr, err := c.CreatePR(ctx, CreatePRRequest{Repo: "owner/repo", Title: "t", Head: "h", Base: "dev", Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if err := c.RequestReviewers(ctx, "owner/repo", pr.Number, []string{"alice", "bob"}); err != nil {
+		t.Fatalf("RequestReviewers: %v", err)
+	}
+	if err := c.AddLabels(ctx, "owner/repo", pr.Number, []string{"bug"}); err != nil {
+		t.Fatalf("AddLabels: %v", err)
+	}
+	if err := c.AddAssignees(ctx, "owner/repo", pr.Number, []string{"carol"}); err != nil {
+		t.Fatalf("AddAssignees: %v", err)
+	}
+
+	if got["/repos/owner/repo/pulls"]["draft"] != true {
+		t.Errorf("draft = %v, want true", got["/repos/owner/repo/pulls"]["draft"])
+	}
+	if r, _ := got["/repos/owner/repo/pulls/7/requested_reviewers"]["reviewers"].([]any); len(r) != 2 {
+		t.Errorf("reviewers = %v", r)
+	}
+	if l, _ := got["/repos/owner/repo/issues/7/labels"]["labels"].([]any); len(l) != 1 || l[0] != "bug" {
+		t.Errorf("labels = %v", l)
+	}
+	if a, _ := got["/repos/owner/repo/issues/7/assignees"]["assignees"].([]any); len(a) != 1 || a[0] != "carol" {
+		t.Errorf("assignees = %v", a)
+	}
+}
+
+func TestGetPR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/pulls/7" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.com/owner/repo/pull/7", "state": "closed", "merged_at": "2026-03-02T10:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	pr, err := c.GetPR(context.Background(), "owner/repo", 7)
+	if err != nil {
+		t.Fatalf("GetPR: %v", err)
+	}
+	if pr.State != "closed" || pr.MergedAt.IsZero() {
+		t.Errorf("PR = %+v, want closed and merged", pr)
+	}
+}
+
+func TestUpdateBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+		switch r.URL.Path {
+		case "/repos/owner/repo/pulls/7/update-branch":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"message": "Updating pull request branch."}`))
+		case "/repos/owner/repo/pulls/8/update-branch":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "merge conflict between base and head"}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	if err := c.UpdateBranch(context.Background(), "owner/repo", 7); err != nil {
+		t.Errorf("UpdateBranch: %v", err)
+	}
+	if err := c.UpdateBranch(context.Background(), "owner/repo", 8); !errors.Is(err, ErrUpdateConflict) {
+		t.Errorf("UpdateBranch with conflicts = %v, want ErrUpdateConflict", err)
+	}
+}
+
+func TestCreatePRRequest_Payload(t *testing.T) {
+	data, err := CreatePRRequest{Repo: "o/r", Title: "Fix #1", Head: "agent/issue-1", Base: "main", Draft: true}.Payload()
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	want := `{"title":"Fix #1","body":"","head":"agent/issue-1","base":"main","draft":true}`
+	if string(data) != want {
+		t.Errorf("payload = %s, want %s", data, want)
+	}
+
+	for _, bad := range []CreatePRRequest{
+		{Head: "agent/issue-1", Base: "main"},
+		{Title: "Fix #1", Base: "main"},
+		{Title: "Fix #1", Head: "main", Base: "main"},
+	} {
+		if _, err := bad.Payload(); err == nil {
+			t.Errorf("Payload(%+v) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RepoMovedError is returned when a repo has been renamed or transferred.
+// NewRepo holds the canonical "owner/repo" GitHub now reports.
+type RepoMovedError struct {
+	Repo    string
+	NewRepo string
+}
+
+func (e *RepoMovedError) Error() string {
+	return fmt.Sprintf("github: %s moved to %s", e.Repo, e.NewRepo)
+}
+
+// Repo is the subset of a GitHub repository lurker uses.
+type Repo struct {
+	FullName      string   `json:"full_name"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+	// Permissions are the authenticated user's on the repo; nil when
+	// GitHub doesn't say, as for tokens that aren't a user's.
+	Permissions *RepoPermissions `json:"permissions,omitempty"`
+}
+
+// RepoPermissions is what the authenticated user may do in a repo.
+type RepoPermissions struct {
+	Push bool `json:"push"`
+}
+
+// GetRepo fetches repo, following any rename or transfer redirects.
+func (c *Client) GetRepo(ctx context.Context, repo string) (Repo, error) {
+	url := fmt.Sprintf("%s/repos/%s", apiBase, repo)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Repo{}, fmt.Errorf("github: creating request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return Repo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Repo{}, fmt.Errorf("github: get repo: %s: %s", resp.Status, string(body))
+	}
+
+	var result Repo
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Repo{}, fmt.Errorf("github: decoding repo: %w", err)
+	}
+	if result.FullName == "" {
+		return Repo{}, fmt.Errorf("github: get repo: empty full_name")
+	}
+
+	return result, nil
+}
+
+// RepoFullName returns the canonical "owner/repo" for repo, following any
+// rename or transfer redirects.
+func (c *Client) RepoFullName(ctx context.Context, repo string) (string, error) {
+	r, err := c.GetRepo(ctx, repo)
+	return r.FullName, err
+}
+
+// CreateFork forks repo into the authenticated user's account, or returns
+// the fork they already have. GitHub makes the fork in the background, so
+// it may take a few seconds before it can be pushed to.
+func (c *Client) CreateFork(ctx context.Context, repo string) (Repo, error) {
+	if !c.perms.AllowPush {
+		return Repo{}, &PermissionError{Action: "allow_push"}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/forks", apiBase, repo), strings.NewReader("{}"))
+	if err != nil {
+		return Repo{}, fmt.Errorf("github: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return Repo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Repo{}, fmt.Errorf("github: fork %s: %s: %s", repo, resp.Status, string(body))
+	}
+	var fork Repo
+	if err := json.NewDecoder(resp.Body).Decode(&fork); err != nil {
+		return Repo{}, fmt.Errorf("github: decoding fork: %w", err)
+	}
+	if fork.FullName == "" {
+		return Repo{}, fmt.Errorf("github: fork %s: empty full_name", repo)
+	}
+	return fork, nil
+}
+
+// wasRedirected reports whether the client followed a redirect to a
+// different path while serving req.
+func wasRedirected(req *http.Request, resp *http.Response) bool {
+	return resp.Request != nil && resp.Request.URL.Path != req.URL.Path
+}
+
+// WebURL returns the web page of repo.
+func (c *Client) WebURL(repo string) string {
+	return "https://github.com/" + repo
+}
+
+// ListOwnerRepos returns the repos of an organization, or of a user if
+// owner isn't one, following pagination.
+func (c *Client) ListOwnerRepos(ctx context.Context, owner string) ([]Repo, error) {
+	repos, err := c.listRepos(ctx, fmt.Sprintf("%s/orgs/%s/repos?per_page=100", apiBase, url.PathEscape(owner)))
+	if errors.Is(err, errNotFound) {
+		repos, err = c.listRepos(ctx, fmt.Sprintf("%s/users/%s/repos?per_page=100", apiBase, url.PathEscape(owner)))
+	}
+	return repos, err
+}
+
+// errNotFound is returned by listRepos for a 404.
+var errNotFound = errors.New("github: not found")
+
+// listRepos fetches a list of repos starting at url, following pagination.
+func (c *Client) listRepos(ctx context.Context, url string) ([]Repo, error) {
+	result := []Repo{}
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("github: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, errNotFound
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("github: list repos: %s: %s", resp.Status, string(body))
+		}
+		var page []Repo
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("github: decoding repos: %w", err)
+		}
+		result = append(result, page...)
+		url = nextPage(resp.Header)
+	}
+	return result, nil
+}
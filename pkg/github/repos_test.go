@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newMovedRepoServer simulates GitHub's behaviour for a renamed repo:
+// old paths 301 to /repositories/1/..., which serve the new full_name.
+func newMovedRepoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/old/repo", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/repositories/1", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/repos/old/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/repositories/1/issues?"+r.URL.RawQuery, http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/repositories/1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"full_name": "new/repo"})
+	})
+	mux.HandleFunc("/repositories/1/issues", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Issue{{Number: 1}})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRepoFullName_FollowsRename(t *testing.T) {
+	srv := newMovedRepoServer(t)
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	name, err := c.RepoFullName(context.Background(), "old/repo")
+	if err != nil {
+		t.Fatalf("RepoFullName: %v", err)
+	}
+	if name != "new/repo" {
+		t.Errorf("full name = %q, want new/repo", name)
+	}
+}
+
+func TestGetRepo_DefaultBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"full_name": "owner/repo", "default_branch": "develop"})
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	repo, err := c.GetRepo(context.Background(), "owner/repo")
+	if err != nil {
+		t.Fatalf("GetRepo: %v", err)
+	}
+	if repo.DefaultBranch != "develop" {
+		t.Errorf("default branch = %q, want develop", repo.DefaultBranch)
+	}
+}
+
+func TestListOpenIssues_RepoMoved(t *testing.T) {
+	srv := newMovedRepoServer(t)
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	_, err := c.ListOpenIssues(context.Background(), "old/repo")
+	var moved *RepoMovedError
+	if !errors.As(err, &moved) {
+		t.Fatalf("expected RepoMovedError, got %v", err)
+	}
+	if moved.Repo != "old/repo" || moved.NewRepo != "new/repo" {
+		t.Errorf("moved = %+v", moved)
+	}
+}
+
+func TestListOwnerRepos_FallsBackToUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/alice/repos":
+			http.NotFound(w, r)
+		case "/users/alice/repos":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", `<`+"http://"+r.Host+`/users/alice/repos?page=2>; rel="next"`)
+				w.Write([]byte(`[{"full_name": "alice/a", "topics": ["ml"]}]`))
+				return
+			}
+			w.Write([]byte(`[{"full_name": "alice/b", "archived": true}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	repos, err := c.ListOwnerRepos(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("ListOwnerRepos: %v", err)
+	}
+	if len(repos) != 2 || repos[0].FullName != "alice/a" || repos[0].Topics[0] != "ml" || !repos[1].Archived {
+		t.Errorf("repos = %+v", repos)
+	}
+}
+
+func TestCreateFork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/up/repo/forks" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"full_name": "me/repo", "permissions": {"push": true}}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	fork, err := c.CreateFork(context.Background(), "up/repo")
+	if err != nil {
+		t.Fatalf("CreateFork: %v", err)
+	}
+	if fork.FullName != "me/repo" || fork.Permissions == nil || !fork.Permissions.Push {
+		t.Errorf("fork = %+v", fork)
+	}
+
+	c.perms.AllowPush = false
+	var perr *PermissionError
+	if _, err := c.CreateFork(context.Background(), "up/repo"); !errors.As(err, &perr) {
+		t.Errorf("err = %v, want a PermissionError", err)
+	}
+}
@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ReviewRequest is an open pull request awaiting the user's review.
+type ReviewRequest struct {
+	Repo      string // "owner/repo"
+	Number    int
+	Title     string
+	Body      string
+	URL       string
+	Author    string
+	UpdatedAt time.Time
+}
+
+// ListReviewRequests returns the open pull requests, in any repo, whose
+// review is requested from the authenticated user, most recently updated
+// first.
+func (c *Client) ListReviewRequests(ctx context.Context) ([]ReviewRequest, error) {
+	url := fmt.Sprintf("%s/search/issues?q=is:open+is:pr+archived:false+review-requested:@me&sort=updated&per_page=100", apiBase)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("github: creating request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("github: list review requests: %s: %s", resp.Status, string(body))
+	}
+
+	var result struct {
+		Items []struct {
+			Number        int       `json:"number"`
+			Title         string    `json:"title"`
+			Body          string    `json:"body"`
+			HTMLURL       string    `json:"html_url"`
+			RepositoryURL string    `json:"repository_url"`
+			UpdatedAt     time.Time `json:"updated_at"`
+			User          struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("github: decoding review requests: %w", err)
+	}
+
+	requests := make([]ReviewRequest, 0, len(result.Items))
+	for _, it := range result.Items {
+		_, repo, ok := strings.Cut(it.RepositoryURL, "/repos/")
+		if !ok {
+			continue
+		}
+		requests = append(requests, ReviewRequest{
+			Repo:      repo,
+			Number:    it.Number,
+			Title:     it.Title,
+			Body:      it.Body,
+			URL:       it.HTMLURL,
+			Author:    it.User.Login,
+			UpdatedAt: it.UpdatedAt,
+		})
+	}
+	return requests, nil
+}
+
+// PRDiff fetches a pull request's diff against its base.
+func (c *Client) PRDiff(ctx context.Context, repo string, number int) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d", apiBase, repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("github: creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.diff")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("github: reading PR diff: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github: get PR diff: %s: %s", resp.Status, string(body))
+	}
+	return string(body), nil
+}
+
+// Review events.
+const (
+	ReviewEventComment        = "COMMENT"
+	ReviewEventApprove        = "APPROVE"
+	ReviewEventRequestChanges = "REQUEST_CHANGES"
+)
+
+// Review is a pull request review to submit.
+type Review struct {
+	Event    string          `json:"event"` // one of the ReviewEvent constants
+	Body     string          `json:"body,omitempty"`
+	Comments []ReviewComment `json:"comments,omitempty"`
+}
+
+// ReviewComment comments on a line of a file as changed by the PR.
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// SubmitReview submits a review of a pull request. Reviews are comments,
+// so they fall under allow_comments.
+func (c *Client) SubmitReview(ctx context.Context, repo string, number int, review Review) error {
+	if !c.perms.AllowComments {
+		return &PermissionError{Action: "allow_comments"}
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/reviews", apiBase, repo, number)
+	return c.postJSON(ctx, url, review, "submit review")
+}
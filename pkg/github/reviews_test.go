@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListReviewRequests(t *testing.T) {
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		gotQuery = r.URL.Query().Get("q")
+		w.Write([]byte(`{"items":[
+			{"number":12,"title":"Add cache","html_url":"https://github.com/o/r/pull/12",
+			 "repository_url":"https://api.github.com/repos/o/r","user":{"login":"alice"},
+			 "updated_at":"2026-01-02T10:00:00Z"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	reqs, err := c.ListReviewRequests(context.Background())
+	if err != nil {
+		t.Fatalf("ListReviewRequests: %v", err)
+	}
+	if gotQuery != "is:open is:pr archived:false review-requested:@me" {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests", len(reqs))
+	}
+	if r := reqs[0]; r.Repo != "o/r" || r.Number != 12 || r.Author != "alice" || r.UpdatedAt.IsZero() {
+		t.Errorf("request = %+v", r)
+	}
+}
+
+func TestPRDiff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/vnd.github.diff" {
+			t.Errorf("Accept = %q", accept)
+		}
+		w.Write([]byte("diff --git a/x b/x\n"))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	diff, err := c.PRDiff(context.Background(), "o/r", 12)
+	if err != nil || diff != "diff --git a/x b/x\n" {
+		t.Errorf("PRDiff = %q, %v", diff, err)
+	}
+}
+
+func TestSubmitReview(t *testing.T) {
+	var gotPath string
+	var got Review
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	review := Review{Event: ReviewEventRequestChanges, Body: "Needs a test.", Comments: []ReviewComment{{Path: "a.go", Line: 3, Body: "nil?"}}}
+	if err := c.SubmitReview(context.Background(), "o/r", 12, review); err != nil {
+		t.Fatalf("SubmitReview: %v", err)
+	}
+	if gotPath != "/repos/o/r/pulls/12/reviews" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if got.Event != ReviewEventRequestChanges || len(got.Comments) != 1 || got.Comments[0].Line != 3 {
+		t.Errorf("review = %+v", got)
+	}
+
+	c.SetPermissions(Permissions{AllowPRCreate: true, AllowPush: true})
+	var permErr *PermissionError
+	if err := c.SubmitReview(context.Background(), "o/r", 12, review); !errors.As(err, &permErr) {
+		t.Errorf("err = %v, want PermissionError", err)
+	}
+}
@@ -0,0 +1,32 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// CommitStatus is a status posted on a commit; pull requests show their
+// head commit's statuses as checks. Check runs would need a GitHub App, so
+// lurker uses statuses, which any token with push access can post.
+type CommitStatus struct {
+	State       string `json:"state"`   // "success", "failure", "error" or "pending"
+	Context     string `json:"context"` // e.g. "lurker/test"; one status per context is kept
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+// maxStatusDescription is GitHub's limit on a status description.
+const maxStatusDescription = 140
+
+// CreateStatus posts status on commit sha. Statuses describe work lurker
+// pushed, so they fall under allow_push.
+func (c *Client) CreateStatus(ctx context.Context, repo, sha string, status CommitStatus) error {
+	if !c.perms.AllowPush {
+		return &PermissionError{Action: "allow_push"}
+	}
+	if r := []rune(status.Description); len(r) > maxStatusDescription {
+		status.Description = string(r[:maxStatusDescription-1]) + "…"
+	}
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", apiBase, repo, sha)
+	return c.postJSON(ctx, url, status, "create status")
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateStatus(t *testing.T) {
+	var got CommitStatus
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/statuses/abc123" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), "tok")
+	origBase := apiBase
+	defer func() { setAPIBase(origBase) }()
+	setAPIBase(srv.URL)
+
+	long := strings.Repeat("x", 200)
+	if err := c.CreateStatus(context.Background(), "owner/repo", "abc123", CommitStatus{State: "success", Context: "lurker/test", Description: long}); err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if got.State != "success" || got.Context != "lurker/test" || len([]rune(got.Description)) != maxStatusDescription {
+		t.Errorf("posted %+v", got)
+	}
+
+	c.SetPermissions(Permissions{AllowPRCreate: true, AllowComments: true})
+	var perr *PermissionError
+	if err := c.CreateStatus(context.Background(), "owner/repo", "abc123", CommitStatus{}); !errors.As(err, &perr) || perr.Action != "allow_push" {
+		t.Errorf("err = %v, want allow_push PermissionError", err)
+	}
+}
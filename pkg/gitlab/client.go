@@ -0,0 +1,214 @@
+// Package gitlab is a GitLab API client that speaks lurker's GitHub
+// vocabulary: issues come back as github.Issue, merge requests as
+// github.PullRequest, and award emoji stand in for reactions, so the
+// watcher can use either forge.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// DefaultURL is GitLab's SaaS instance.
+const DefaultURL = "https://gitlab.com"
+
+// Client is a GitLab API client with retry logic. It is safe for
+// concurrent use across multiple watchers.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string // e.g. https://gitlab.com, without /api/v4
+	token      string
+	perms      github.Permissions
+	maxIssues  int // cap on ListOpenIssues; 0 for none
+
+	userMu sync.Mutex
+	user   string // authenticated username, fetched on first use
+}
+
+// NewClient creates a Client for the GitLab instance at baseURL, resolving
+// the API token from GITLAB_TOKEN or falling back to glab's stored token
+// for that host.
+func NewClient(baseURL string) (*Client, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("gitlab: bad instance URL %q", baseURL)
+	}
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		out, err := exec.Command("glab", "config", "get", "token", "--host", u.Host).Output()
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: no GITLAB_TOKEN and `glab config get token` failed: %w", err)
+		}
+		token = strings.TrimSpace(string(out))
+	}
+	if token == "" {
+		return nil, fmt.Errorf("gitlab: empty token")
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    baseURL,
+		token:      token,
+		perms:      github.AllowAll(),
+	}, nil
+}
+
+// newClientForTest creates a Client pointing at a test server.
+func newClientForTest(httpClient *http.Client, baseURL, token string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		token:      token,
+		perms:      github.AllowAll(),
+	}
+}
+
+// SetPermissions sets which mutating actions the client may take.
+func (c *Client) SetPermissions(p github.Permissions) { c.perms = p }
+
+// Permissions returns the client's permissions.
+func (c *Client) Permissions() github.Permissions { return c.perms }
+
+// SetMaxIssues caps how many open issues ListOpenIssues returns per
+// project; n <= 0 lifts the cap. Call it before the client is shared with
+// watchers.
+func (c *Client) SetMaxIssues(n int) { c.maxIssues = max(n, 0) }
+
+// WebURL returns the web page of project repo ("group/project").
+func (c *Client) WebURL(repo string) string {
+	return c.baseURL + "/" + repo
+}
+
+// CloneTool is the CLI that clones GitLab projects; `glab repo clone`
+// takes the same arguments as `gh repo clone`.
+func (c *Client) CloneTool() string { return "glab" }
+
+// projectURL returns the API URL of repo's project, plus path.
+func (c *Client) projectURL(repo, path string) string {
+	return c.baseURL + "/api/v4/projects/" + url.PathEscape(repo) + path
+}
+
+// do executes an HTTP request with auth and retry.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	// Backstop for observer deployments, as in the GitHub client.
+	if c.perms.ReadOnly() && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return nil, &github.PermissionError{Action: "write"}
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= 3; attempt++ {
+		if attempt > 0 {
+			wait := time.Duration(1<<uint(attempt-1)) * time.Second
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+					wait = time.Duration(s) * time.Second
+				}
+			}
+			time.Sleep(wait)
+		}
+		if req.GetBody != nil && attempt > 0 {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: request failed after retries: %w", err)
+	}
+	return resp, nil
+}
+
+// send issues a request with an optional JSON payload and decodes a 2xx
+// response into out, if non-nil. what names the operation in errors.
+func (c *Client) send(ctx context.Context, method, url string, payload, out any, what string) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("gitlab: marshaling %s request: %w", what, err)
+		}
+		body = strings.NewReader(string(data))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("gitlab: creating request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("gitlab: %s: %s: %s", what, resp.Status, string(data))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("gitlab: decoding %s: %w", what, err)
+		}
+	}
+	return nil
+}
+
+// currentUser returns the username of the token's user.
+func (c *Client) currentUser(ctx context.Context) (string, error) {
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+	if c.user != "" {
+		return c.user, nil
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := c.send(ctx, http.MethodGet, c.baseURL+"/api/v4/user", nil, &user, "get user"); err != nil {
+		return "", err
+	}
+	c.user = user.Username
+	return c.user, nil
+}
+
+// nextPage returns the URL of the next page from a Link header, or "".
+// GitLab sends the same rel="next" links GitHub does.
+func nextPage(h http.Header) string {
+	for _, link := range strings.Split(h.Get("Link"), ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestDo_SetsToken(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("PRIVATE-TOKEN")
+		w.Write([]byte(`{"username":"me"}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	user, err := c.currentUser(context.Background())
+	if err != nil {
+		t.Fatalf("currentUser: %v", err)
+	}
+	if got != "tok" {
+		t.Errorf("PRIVATE-TOKEN = %q, want tok", got)
+	}
+	if user != "me" {
+		t.Errorf("user = %q, want me", user)
+	}
+}
+
+func TestDo_ReadOnlyBlocksWrites(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached the server: %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	c.SetPermissions(github.Permissions{})
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/v4/projects", nil)
+	_, err := c.do(req)
+	var permErr *github.PermissionError
+	if !errors.As(err, &permErr) {
+		t.Errorf("err = %v, want a PermissionError", err)
+	}
+}
+
+func TestProjectURL_EscapesPath(t *testing.T) {
+	c := newClientForTest(http.DefaultClient, "https://gitlab.example", "tok")
+	got := c.projectURL("group/project", "/issues")
+	want := "https://gitlab.example/api/v4/projects/group%2Fproject/issues"
+	if got != want {
+		t.Errorf("projectURL = %q, want %q", got, want)
+	}
+}
+
+func TestNewClient_RejectsBadURL(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "tok")
+	if _, err := NewClient("not a url"); err == nil {
+		t.Error("NewClient accepted a URL without a host")
+	}
+	c, err := NewClient("https://gitlab.example/")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if got := c.WebURL("group/project"); got != "https://gitlab.example/group/project" {
+		t.Errorf("WebURL = %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// issue is the subset of a GitLab issue lurker uses.
+type issue struct {
+	IID         int       `json:"iid"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Labels      []string  `json:"labels"`
+	WebURL      string    `json:"web_url"`
+	State       string    `json:"state"` // "opened" or "closed"
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// toGitHub converts iss to the shape the watcher consumes. Issue numbers
+// are project-scoped iids, like GitHub's.
+func (iss issue) toGitHub() github.Issue {
+	gi := github.Issue{
+		Number:    iss.IID,
+		Title:     iss.Title,
+		Body:      iss.Description,
+		URL:       iss.WebURL,
+		State:     "closed",
+		CreatedAt: iss.CreatedAt,
+	}
+	if iss.State == "opened" {
+		gi.State = "open"
+	}
+	for _, name := range iss.Labels {
+		gi.Labels = append(gi.Labels, github.Label{Name: name})
+	}
+	return gi
+}
+
+// ListOpenIssues returns the open issues of project repo ("group/project"),
+// following pagination up to the cap set by SetMaxIssues. GitLab lists
+// merge requests separately, so there are none to filter out.
+func (c *Client) ListOpenIssues(ctx context.Context, repo string) ([]github.Issue, error) {
+	url := c.projectURL(repo, "/issues?state=opened&per_page=100")
+
+	result := []github.Issue{}
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab: list issues: %s: %s", resp.Status, string(body))
+		}
+		var page []issue
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: decoding issues: %w", err)
+		}
+
+		for _, iss := range page {
+			result = append(result, iss.toGitHub())
+		}
+		if c.maxIssues > 0 && len(result) >= c.maxIssues {
+			return result[:c.maxIssues], nil
+		}
+		url = nextPage(resp.Header)
+	}
+	return result, nil
+}
+
+// GetIssue fetches a single issue. GitLab's moved issues are closed copies
+// pointing at an id lurker can't resolve to a project, so unlike GitHub
+// this never reports a move.
+func (c *Client) GetIssue(ctx context.Context, repo string, number int) (*github.Issue, error) {
+	var iss issue
+	url := c.projectURL(repo, fmt.Sprintf("/issues/%d", number))
+	if err := c.send(ctx, http.MethodGet, url, nil, &iss, "get issue"); err != nil {
+		return nil, err
+	}
+	gi := iss.toGitHub()
+	return &gi, nil
+}
+
+// AddReaction awards an emoji, such as "eyes", to an issue. Awarding one
+// the user already gave is not an error.
+func (c *Client) AddReaction(ctx context.Context, repo string, number int, reaction string) error {
+	if !c.perms.AllowComments {
+		return &github.PermissionError{Action: "allow_comments"}
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/issues/%d/award_emoji", number))
+	err := c.send(ctx, http.MethodPost, url, map[string]string{"name": reaction}, nil, "add reaction")
+	if err != nil && c.hasAward(ctx, repo, number, reaction) {
+		return nil
+	}
+	return err
+}
+
+// award is an entry from an issue's award emoji list.
+type award struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+}
+
+// awards lists the authenticated user's awards of name on an issue.
+func (c *Client) awards(ctx context.Context, repo string, number int, name string) ([]award, error) {
+	user, err := c.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var all []award
+	url := c.projectURL(repo, fmt.Sprintf("/issues/%d/award_emoji?per_page=100", number))
+	if err := c.send(ctx, http.MethodGet, url, nil, &all, "list reactions"); err != nil {
+		return nil, err
+	}
+	var mine []award
+	for _, a := range all {
+		if a.Name == name && a.User.Username == user {
+			mine = append(mine, a)
+		}
+	}
+	return mine, nil
+}
+
+// hasAward reports whether the user has already awarded name on an issue.
+func (c *Client) hasAward(ctx context.Context, repo string, number int, name string) bool {
+	mine, err := c.awards(ctx, repo, number, name)
+	return err == nil && len(mine) > 0
+}
+
+// RemoveReaction takes back the authenticated user's award of the given
+// emoji from an issue. It is not an error if there is none.
+func (c *Client) RemoveReaction(ctx context.Context, repo string, number int, content string) error {
+	if !c.perms.AllowComments {
+		return &github.PermissionError{Action: "allow_comments"}
+	}
+	mine, err := c.awards(ctx, repo, number, content)
+	if err != nil {
+		return err
+	}
+	for _, a := range mine {
+		url := c.projectURL(repo, fmt.Sprintf("/issues/%d/award_emoji/%d", number, a.ID))
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+		if err != nil {
+			return fmt.Errorf("gitlab: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("gitlab: delete reaction: %s", resp.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListOpenIssues(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/issues" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("state") != "opened" {
+			t.Error("expected state=opened")
+		}
+		var page []issue
+		switch r.URL.Query().Get("page") {
+		case "":
+			page = []issue{{IID: 1, Title: "Bug", Labels: []string{"bug"}, State: "opened", WebURL: "https://gitlab.example/group/project/-/issues/1"}}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/projects/group%%2Fproject/issues?state=opened&page=2>; rel="next"`, srv.URL))
+		case "2":
+			page = []issue{{IID: 3, Title: "Feature", State: "opened"}}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	issues, err := c.ListOpenIssues(context.Background(), "group/project")
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Number != 1 || issues[1].Number != 3 {
+		t.Fatalf("issues = %+v, want #1 and #3", issues)
+	}
+	if issues[0].State != "open" || len(issues[0].Labels) != 1 || issues[0].Labels[0].Name != "bug" {
+		t.Errorf("issue #1 = %+v", issues[0])
+	}
+
+	c.SetMaxIssues(1)
+	issues, err = c.ListOpenIssues(context.Background(), "group/project")
+	if err != nil {
+		t.Fatalf("ListOpenIssues: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Errorf("capped list has %d issues, want 1", len(issues))
+	}
+}
+
+func TestRemoveReaction_DeletesOnlyOwnAward(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/user":
+			w.Write([]byte(`{"username":"me"}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`[
+				{"id": 1, "name": "eyes", "user": {"username": "someone"}},
+				{"id": 2, "name": "eyes", "user": {"username": "me"}},
+				{"id": 3, "name": "thumbsup", "user": {"username": "me"}}
+			]`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	if err := c.RemoveReaction(context.Background(), "group/project", 7, "eyes"); err != nil {
+		t.Fatalf("RemoveReaction: %v", err)
+	}
+	want := "/api/v4/projects/group%2Fproject/issues/7/award_emoji/2"
+	if len(deleted) != 1 || deleted[0] != want {
+		t.Errorf("deleted %v, want [%s]", deleted, want)
+	}
+}
+
+func TestAddReaction_AlreadyAwarded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/user":
+			w.Write([]byte(`{"username":"me"}`))
+		case r.Method == http.MethodPost:
+			http.Error(w, `{"message":"already awarded"}`, http.StatusNotFound)
+		default:
+			w.Write([]byte(`[{"id": 2, "name": "eyes", "user": {"username": "me"}}]`))
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	if err := c.AddReaction(context.Background(), "group/project", 7, "eyes"); err != nil {
+		t.Errorf("AddReaction = %v, want nil for an existing award", err)
+	}
+}
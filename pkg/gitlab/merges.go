@@ -0,0 +1,295 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// mergeRequest is the subset of a GitLab merge request lurker uses.
+type mergeRequest struct {
+	IID      int        `json:"iid"`
+	WebURL   string     `json:"web_url"`
+	State    string     `json:"state"` // "opened", "closed", "merged" or "locked"
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+func (mr mergeRequest) toGitHub() *github.PullRequest {
+	pr := &github.PullRequest{Number: mr.IID, HTMLURL: mr.WebURL, State: "closed"}
+	if mr.State == "opened" || mr.State == "locked" {
+		pr.State = "open"
+	}
+	if mr.MergedAt != nil {
+		pr.MergedAt = *mr.MergedAt
+	}
+	return pr
+}
+
+// CreatePR opens a merge request; pr.Repo is the project. If the source
+// branch has one open already, it returns a *github.PRExistsError
+// describing it.
+func (c *Client) CreatePR(ctx context.Context, pr github.CreatePRRequest) (*github.PullRequest, error) {
+	if !c.perms.AllowPRCreate {
+		return nil, &github.PermissionError{Action: "allow_pr_create"}
+	}
+	// Validate the way the GitHub client does.
+	if _, err := pr.Payload(); err != nil {
+		return nil, err
+	}
+	title := pr.Title
+	if pr.Draft {
+		title = "Draft: " + title
+	}
+	payload := map[string]any{
+		"title":                title,
+		"description":          pr.Body,
+		"source_branch":        pr.Head,
+		"target_branch":        pr.Base,
+		"remove_source_branch": true,
+	}
+	var mr mergeRequest
+	if err := c.send(ctx, http.MethodPost, c.projectURL(pr.Repo, "/merge_requests"), payload, &mr, "create merge request"); err != nil {
+		// GitLab answers 409 with only a message; look for the MR itself.
+		var open []mergeRequest
+		u := c.projectURL(pr.Repo, "/merge_requests?state=opened&source_branch="+url.QueryEscape(pr.Head))
+		if c.send(ctx, http.MethodGet, u, nil, &open, "find merge request") == nil && len(open) > 0 {
+			return nil, &github.PRExistsError{Repo: pr.Repo, PR: *open[0].toGitHub()}
+		}
+		return nil, err
+	}
+	return mr.toGitHub(), nil
+}
+
+// EditPR replaces the title and description of a merge request.
+func (c *Client) EditPR(ctx context.Context, repo string, number int, title, body string) error {
+	if !c.perms.AllowPRCreate {
+		return &github.PermissionError{Action: "allow_pr_create"}
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/merge_requests/%d", number))
+	return c.send(ctx, http.MethodPut, url, map[string]string{"title": title, "description": body}, nil, "edit merge request")
+}
+
+// RequestReviewers makes the given users reviewers of a merge request.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, number int, reviewers []string) error {
+	if !c.perms.AllowPRCreate {
+		return &github.PermissionError{Action: "allow_pr_create"}
+	}
+	var ids []int
+	for _, name := range reviewers {
+		id, err := c.userID(ctx, name)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/merge_requests/%d", number))
+	return c.send(ctx, http.MethodPut, url, map[string][]int{"reviewer_ids": ids}, nil, "request reviewers")
+}
+
+// AddAssignees assigns users to a merge request.
+func (c *Client) AddAssignees(ctx context.Context, repo string, number int, assignees []string) error {
+	if !c.perms.AllowPRCreate {
+		return &github.PermissionError{Action: "allow_pr_create"}
+	}
+	var ids []int
+	for _, name := range assignees {
+		id, err := c.userID(ctx, name)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/merge_requests/%d", number))
+	return c.send(ctx, http.MethodPut, url, map[string][]int{"assignee_ids": ids}, nil, "add assignees")
+}
+
+// userID looks up the id of the user called username.
+func (c *Client) userID(ctx context.Context, username string) (int, error) {
+	var users []struct {
+		ID int `json:"id"`
+	}
+	u := c.baseURL + "/api/v4/users?username=" + url.QueryEscape(username)
+	if err := c.send(ctx, http.MethodGet, u, nil, &users, "find user"); err != nil {
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("gitlab: no user %q", username)
+	}
+	return users[0].ID, nil
+}
+
+// AddLabels adds labels to a merge request.
+func (c *Client) AddLabels(ctx context.Context, repo string, number int, labels []string) error {
+	if !c.perms.AllowPRCreate {
+		return &github.PermissionError{Action: "allow_pr_create"}
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/merge_requests/%d", number))
+	return c.send(ctx, http.MethodPut, url, map[string]string{"add_labels": strings.Join(labels, ",")}, nil, "add labels")
+}
+
+// CreateComment comments on a merge request. GitLab keeps issue and merge
+// request notes apart; lurker only comments on the ones it opened.
+func (c *Client) CreateComment(ctx context.Context, repo string, number int, body string) error {
+	if !c.perms.AllowComments {
+		return &github.PermissionError{Action: "allow_comments"}
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/merge_requests/%d/notes", number))
+	return c.send(ctx, http.MethodPost, url, map[string]string{"body": body}, nil, "create comment")
+}
+
+// statusStates maps GitHub commit status states onto GitLab's.
+var statusStates = map[string]string{
+	"success": "success",
+	"failure": "failed",
+	"error":   "failed",
+	"pending": "pending",
+}
+
+// CreateStatus posts status on commit sha as an external pipeline job
+// named after its context.
+func (c *Client) CreateStatus(ctx context.Context, repo, sha string, status github.CommitStatus) error {
+	if !c.perms.AllowPush {
+		return &github.PermissionError{Action: "allow_push"}
+	}
+	state, ok := statusStates[status.State]
+	if !ok {
+		return fmt.Errorf("gitlab: unknown status state %q", status.State)
+	}
+	payload := map[string]string{
+		"state":       state,
+		"name":        status.Context,
+		"description": status.Description,
+	}
+	if status.TargetURL != "" {
+		payload["target_url"] = status.TargetURL
+	}
+	url := c.projectURL(repo, "/statuses/"+sha)
+	return c.send(ctx, http.MethodPost, url, payload, nil, "create status")
+}
+
+// jobChecks maps GitLab job statuses that are final onto check run
+// conclusions; jobs in any other status are still to run.
+var jobChecks = map[string]string{
+	"success":  "success",
+	"failed":   "failure",
+	"canceled": "cancelled",
+	"skipped":  "skipped",
+	"manual":   "neutral",
+}
+
+// PRChecks returns the jobs of merge request number's latest pipeline as
+// check runs. Failed jobs that are allowed to fail are neutral.
+func (c *Client) PRChecks(ctx context.Context, repo string, number int) ([]github.CheckRun, error) {
+	var mr struct {
+		HeadPipeline *struct {
+			ID int `json:"id"`
+		} `json:"head_pipeline"`
+	}
+	if err := c.send(ctx, http.MethodGet, c.projectURL(repo, fmt.Sprintf("/merge_requests/%d", number)), nil, &mr, "get merge request"); err != nil {
+		return nil, err
+	}
+	if mr.HeadPipeline == nil {
+		return nil, nil
+	}
+	var jobs []struct {
+		Name         string `json:"name"`
+		Status       string `json:"status"`
+		WebURL       string `json:"web_url"`
+		AllowFailure bool   `json:"allow_failure"`
+	}
+	url := c.projectURL(repo, fmt.Sprintf("/pipelines/%d/jobs?per_page=100", mr.HeadPipeline.ID))
+	if err := c.send(ctx, http.MethodGet, url, nil, &jobs, "list pipeline jobs"); err != nil {
+		return nil, err
+	}
+	var checks []github.CheckRun
+	for _, j := range jobs {
+		check := github.CheckRun{Name: j.Name, Status: "in_progress", HTMLURL: j.WebURL}
+		if conclusion, ok := jobChecks[j.Status]; ok {
+			check.Status, check.Conclusion = "completed", conclusion
+			if conclusion == "failure" && j.AllowFailure {
+				check.Conclusion = "neutral"
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// project is the subset of a GitLab project lurker uses.
+type project struct {
+	Path          string   `json:"path_with_namespace"`
+	DefaultBranch string   `json:"default_branch"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+}
+
+func (p project) toGitHub() github.Repo {
+	return github.Repo{FullName: p.Path, DefaultBranch: p.DefaultBranch, Archived: p.Archived, Topics: p.Topics}
+}
+
+// GetRepo fetches project repo. FullName is its path with namespace.
+func (c *Client) GetRepo(ctx context.Context, repo string) (github.Repo, error) {
+	var p project
+	if err := c.send(ctx, http.MethodGet, c.projectURL(repo, ""), nil, &p, "get project"); err != nil {
+		return github.Repo{}, err
+	}
+	return p.toGitHub(), nil
+}
+
+// ListOwnerRepos returns the projects directly in group owner, or of user
+// owner if there is no such group. Subgroups' projects are left out, as
+// lurker only handles "group/project" paths.
+func (c *Client) ListOwnerRepos(ctx context.Context, owner string) ([]github.Repo, error) {
+	repos, err := c.listProjects(ctx, c.baseURL+"/api/v4/groups/"+url.PathEscape(owner)+"/projects?per_page=100")
+	if err == errNotFound {
+		repos, err = c.listProjects(ctx, c.baseURL+"/api/v4/users/"+url.PathEscape(owner)+"/projects?per_page=100")
+	}
+	return repos, err
+}
+
+// errNotFound is returned by listProjects for a 404.
+var errNotFound = errors.New("gitlab: not found")
+
+// listProjects fetches a list of projects starting at u, following
+// pagination.
+func (c *Client) listProjects(ctx context.Context, u string) ([]github.Repo, error) {
+	result := []github.Repo{}
+	for u != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: creating request: %w", err)
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, errNotFound
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab: list projects: %s: %s", resp.Status, string(body))
+		}
+		var page []project
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: decoding projects: %w", err)
+		}
+		for _, p := range page {
+			result = append(result, p.toGitHub())
+		}
+		u = nextPage(resp.Header)
+	}
+	return result, nil
+}
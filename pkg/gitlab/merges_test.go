@@ -0,0 +1,188 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestCreatePR(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/merge_requests" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid": 12, "web_url": "https://gitlab.example/group/project/-/merge_requests/12", "state": "opened"}`))
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	pr, err := c.CreatePR(context.Background(), github.CreatePRRequest{
+		Repo: "group/project", Title: "Fix #3", Body: "Fixes #3", Head: "agent/issue-3", Base: "main", Draft: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if pr.Number != 12 || pr.State != "open" || pr.HTMLURL == "" {
+		t.Errorf("pr = %+v", pr)
+	}
+	if got["title"] != "Draft: Fix #3" || got["source_branch"] != "agent/issue-3" || got["target_branch"] != "main" || got["description"] != "Fixes #3" {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestCreatePR_Exists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": ["Another open merge request already exists for this source branch: !12"]}`))
+		case r.URL.Query().Get("source_branch") == "agent/issue-3" && r.URL.Query().Get("state") == "opened":
+			w.Write([]byte(`[{"iid": 12, "web_url": "https://gitlab.example/group/project/-/merge_requests/12", "state": "opened"}]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	_, err := c.CreatePR(context.Background(), github.CreatePRRequest{Repo: "group/project", Title: "Fix #3", Head: "agent/issue-3", Base: "main"})
+	var exists *github.PRExistsError
+	if !errors.As(err, &exists) || exists.PR.Number != 12 {
+		t.Errorf("err = %v, want a PRExistsError for !12", err)
+	}
+}
+
+func TestCreatePR_Permissions(t *testing.T) {
+	c := newClientForTest(http.DefaultClient, "http://unused", "tok")
+	c.SetPermissions(github.Permissions{AllowPush: true})
+	_, err := c.CreatePR(context.Background(), github.CreatePRRequest{Repo: "g/p", Title: "t", Head: "h", Base: "b"})
+	var permErr *github.PermissionError
+	if !errors.As(err, &permErr) || permErr.Action != "allow_pr_create" {
+		t.Errorf("err = %v, want allow_pr_create PermissionError", err)
+	}
+}
+
+func TestCreateStatus_MapsState(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/statuses/abc123" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	err := c.CreateStatus(context.Background(), "group/project", "abc123", github.CommitStatus{State: "failure", Context: "lurker/test"})
+	if err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if got["state"] != "failed" || got["name"] != "lurker/test" {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestRequestReviewers_ResolvesUsernames(t *testing.T) {
+	var got map[string][]int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/users":
+			if r.URL.Query().Get("username") == "alice" {
+				w.Write([]byte(`[{"id": 42}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		case r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&got)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	if err := c.RequestReviewers(context.Background(), "group/project", 12, []string{"alice"}); err != nil {
+		t.Fatalf("RequestReviewers: %v", err)
+	}
+	if len(got["reviewer_ids"]) != 1 || got["reviewer_ids"][0] != 42 {
+		t.Errorf("payload = %v", got)
+	}
+	if err := c.RequestReviewers(context.Background(), "group/project", 12, []string{"nobody"}); err == nil {
+		t.Error("RequestReviewers accepted an unknown user")
+	}
+	if err := c.AddAssignees(context.Background(), "group/project", 12, []string{"alice"}); err != nil {
+		t.Fatalf("AddAssignees: %v", err)
+	}
+	if len(got["assignee_ids"]) != 1 || got["assignee_ids"][0] != 42 {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestListOwnerRepos_FallsBackToUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/groups/alice/projects":
+			http.NotFound(w, r)
+		case "/api/v4/users/alice/projects":
+			w.Write([]byte(`[{"path_with_namespace": "alice/tool", "default_branch": "main", "topics": ["cli"]}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	repos, err := c.ListOwnerRepos(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("ListOwnerRepos: %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "alice/tool" || len(repos[0].Topics) != 1 {
+		t.Errorf("repos = %+v", repos)
+	}
+}
+
+func TestPRChecks_PipelineJobs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/merge_requests/12":
+			w.Write([]byte(`{"iid": 12, "head_pipeline": {"id": 99}}`))
+		case "/api/v4/projects/group%2Fproject/pipelines/99/jobs":
+			w.Write([]byte(`[
+				{"name": "build", "status": "success"},
+				{"name": "lint", "status": "failed", "allow_failure": true},
+				{"name": "test", "status": "running"}
+			]`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := newClientForTest(srv.Client(), srv.URL, "tok")
+	checks, err := c.PRChecks(context.Background(), "group/project", 12)
+	if err != nil {
+		t.Fatalf("PRChecks: %v", err)
+	}
+	want := []github.CheckRun{
+		{Name: "build", Status: "completed", Conclusion: "success"},
+		{Name: "lint", Status: "completed", Conclusion: "neutral"},
+		{Name: "test", Status: "in_progress"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Errorf("checks[%d] = %+v, want %+v", i, checks[i], want[i])
+		}
+	}
+}
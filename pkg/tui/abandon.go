@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// abandonedMsg reports an issue handed back by Manager.Abandon.
+type abandonedMsg struct {
+	repo     string
+	num      int
+	warnings []string
+	err      error
+}
+
+// abandonFor asks whether to abandon iss, with what focusAbandon's
+// toggles say to clean up besides.
+func (m *Model) abandonFor(iss *watcher.TrackedIssue) {
+	if iss == nil {
+		return
+	}
+	comments := m.ghClient.Permissions().AllowComments
+	m.abandoning = iss
+	m.abandonOpts = watcher.AbandonOptions{ReleaseClaim: comments, Comment: comments, RemoveWorktree: true}
+	m.abandonFrom = m.focus
+	m.focus = focusAbandon
+}
+
+func (m *Model) handleAbandonKey(key string) tea.Cmd {
+	iss := m.abandoning
+	comments := m.ghClient.Permissions().AllowComments
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "r":
+		m.abandonOpts.ReleaseClaim = !m.abandonOpts.ReleaseClaim && comments
+	case "c":
+		m.abandonOpts.Comment = !m.abandonOpts.Comment && comments
+	case "w":
+		m.abandonOpts.RemoveWorktree = !m.abandonOpts.RemoveWorktree
+	case "y":
+		m.closeAbandon()
+		m.closePtySession(issueKey(iss.Repo, iss.Number))
+		mgr, repo, num, opts := m.manager, iss.Repo, iss.Number, m.abandonOpts
+		return func() tea.Msg {
+			warnings, err := mgr.Abandon(repo, num, opts)
+			return abandonedMsg{repo: repo, num: num, warnings: warnings, err: err}
+		}
+	case "n", "esc":
+		m.closeAbandon()
+	}
+	return nil
+}
+
+func (m *Model) closeAbandon() {
+	m.abandoning = nil
+	m.focus = m.abandonFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+// handleAbandoned reports how abandoning went in the header, as the issue
+// and its log leave the list with EventAbandoned.
+func (m *Model) handleAbandoned(msg abandonedMsg) {
+	what := fmt.Sprintf("%s#%d", msg.repo, msg.num)
+	switch {
+	case msg.err != nil:
+		m.appendLog(issueKey(msg.repo, msg.num), fmt.Sprintf("❌ Abandoning: %v", msg.err))
+	case len(msg.warnings) > 0:
+		m.cleanupStatus = "abandoned " + what + " — " + strings.Join(msg.warnings, "; ")
+	default:
+		m.cleanupStatus = "abandoned " + what
+	}
+}
+
+// abandonPrompt is focusAbandon's footer: what abandoning will do, with
+// the keys that toggle each optional step.
+func (m *Model) abandonPrompt() string {
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+	o := m.abandonOpts
+	return footerStyle.Render(fmt.Sprintf(" Hand #%d back? lurker stops and ignores it from now on. ", m.abandoning.Number)) +
+		fmtHelp("r", "remove 👀: "+onOff(o.ReleaseClaim)) + "  " +
+		fmtHelp("c", "comment: "+onOff(o.Comment)) + "  " +
+		fmtHelp("w", "remove worktree: "+onOff(o.RemoveWorktree)) + "  " +
+		fmtHelp("y", "abandon") + "  " + fmtHelp("esc", "cancel")
+}
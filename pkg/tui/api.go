@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/api"
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// APIBackend implements api.Backend for a running TUI. Calls are forwarded
+// into the program so they act on the model from its own goroutine, exactly
+// like key presses (starting an issue needs the model's PTY sessions).
+type APIBackend struct {
+	manager *watcher.Manager
+	hub     *api.Hub
+	send    func(tea.Msg)
+}
+
+// apiCallMsg runs fn against the model and closes done, unless the caller
+// gave up first.
+type apiCallMsg struct {
+	fn    func(m *Model)
+	state *atomic.Int32 // apiCallPending, apiCallRunning or apiCallAbandoned
+	done  chan struct{}
+}
+
+const (
+	apiCallPending int32 = iota
+	apiCallRunning
+	apiCallAbandoned
+)
+
+// run is called from Update.
+func (c apiCallMsg) run(m *Model) {
+	if c.state.CompareAndSwap(apiCallPending, apiCallRunning) {
+		c.fn(m)
+		close(c.done)
+	}
+}
+
+// APIBackend returns the backend for serving this model over the API.
+// Attach must be called with the program running the model before use.
+func (m Model) APIBackend() *APIBackend {
+	return &APIBackend{manager: m.manager, hub: m.logHub}
+}
+
+// Attach routes calls to p.
+func (b *APIBackend) Attach(p *tea.Program) {
+	b.send = p.Send
+}
+
+// call runs fn on the model's goroutine and waits for it. If ctx ends
+// before fn starts, fn never runs.
+func (b *APIBackend) call(ctx context.Context, fn func(m *Model)) error {
+	if b.send == nil {
+		return errors.New("lurker is not running")
+	}
+	msg := apiCallMsg{fn: fn, state: new(atomic.Int32), done: make(chan struct{})}
+	go b.send(msg)
+	select {
+	case <-msg.done:
+		return nil
+	case <-ctx.Done():
+		if msg.state.CompareAndSwap(apiCallPending, apiCallAbandoned) {
+			return ctx.Err()
+		}
+		<-msg.done
+		return nil
+	}
+}
+
+// The methods below implement api.Backend.
+
+func (b *APIBackend) Repos() []string {
+	return b.manager.Repos()
+}
+
+func (b *APIBackend) AddRepo(ctx context.Context, repo string) error {
+	var err error
+	if callErr := b.call(ctx, func(m *Model) {
+		if err = m.manager.AddRepo(repo); err == nil {
+			m.repoExpanded[repo] = true
+		}
+	}); callErr != nil {
+		return callErr
+	}
+	return err
+}
+
+func (b *APIBackend) Issues(ctx context.Context, repo string) ([]watcher.TrackedIssue, error) {
+	var issues []watcher.TrackedIssue
+	if err := b.call(ctx, func(m *Model) {
+		for _, iss := range m.issues.all() {
+			if repo == "" || iss.Repo == repo {
+				issues = append(issues, *iss)
+			}
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+func (b *APIBackend) StartIssue(ctx context.Context, repo string, num int) (watcher.TrackedIssue, error) {
+	return b.issueCall(ctx, repo, num, func(m *Model, iss *watcher.TrackedIssue) error {
+		switch iss.Status {
+		case watcher.StatusPending:
+			m.startIssue(iss, "▶ Started (api)")
+		case watcher.StatusPaused:
+			m.startIssue(iss, "▶ Resumed (api)")
+		case watcher.StatusFailed, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+			m.startIssue(iss, "▶ Retrying (api)")
+		default:
+			return fmt.Errorf("%s is %s", issueKey(repo, num), iss.Status)
+		}
+		return nil
+	})
+}
+
+func (b *APIBackend) StopIssue(ctx context.Context, repo string, num int) (watcher.TrackedIssue, error) {
+	return b.issueCall(ctx, repo, num, func(m *Model, iss *watcher.TrackedIssue) error {
+		switch iss.Status {
+		case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusQueued, watcher.StatusClaudeRunning:
+			m.manager.StopIssue(iss.Repo, iss.Number)
+			m.syncStatus(iss)
+			m.appendLog(issueKey(repo, num), "⏸ Paused (api)")
+			m.saveIssueMeta(iss)
+			return nil
+		default:
+			return fmt.Errorf("%s is %s", issueKey(repo, num), iss.Status)
+		}
+	})
+}
+
+func (b *APIBackend) Approve(ctx context.Context, repo string, num int) (api.PullRequest, error) {
+	var cmd tea.Cmd
+	var existing api.PullRequest
+	var err error
+	if callErr := b.call(ctx, func(m *Model) {
+		iss := m.findIssue(repo, num)
+		switch {
+		case iss == nil:
+			err = fmt.Errorf("%s: %w", issueKey(repo, num), api.ErrNotFound)
+		case iss.PRNumber != 0:
+			existing = api.PullRequest{Number: iss.PRNumber, URL: iss.PRURL}
+		case iss.Status != watcher.StatusReady:
+			err = fmt.Errorf("%s is %s, not ready", issueKey(repo, num), iss.Status)
+		case !m.ghClient.Permissions().AllowPush:
+			err = &github.PermissionError{Action: "allow_push"}
+		case !m.ghClient.Permissions().AllowPRCreate:
+			err = &github.PermissionError{Action: "allow_pr_create"}
+		default:
+			if cmd = m.approvePRFor(iss, false); cmd == nil {
+				err = fmt.Errorf("%s has no worktree", issueKey(repo, num))
+			}
+		}
+	}); callErr != nil {
+		return api.PullRequest{}, callErr
+	}
+	if err != nil || cmd == nil {
+		return existing, err
+	}
+
+	// Push and create the PR here rather than on the program's goroutine,
+	// then hand the result to the model as if the A key had been pressed.
+	res, _ := cmd().(prResultMsg)
+	go b.send(res)
+	if res.err != nil {
+		return api.PullRequest{}, res.err
+	}
+	return api.PullRequest{Number: res.prNum, URL: res.url, Warnings: res.warnings}, nil
+}
+
+// issueCall runs fn on repo#num and returns the issue as fn left it.
+func (b *APIBackend) issueCall(ctx context.Context, repo string, num int, fn func(m *Model, iss *watcher.TrackedIssue) error) (watcher.TrackedIssue, error) {
+	var out watcher.TrackedIssue
+	var err error
+	if callErr := b.call(ctx, func(m *Model) {
+		iss := m.findIssue(repo, num)
+		if iss == nil {
+			err = fmt.Errorf("%s: %w", issueKey(repo, num), api.ErrNotFound)
+			return
+		}
+		err = fn(m, iss)
+		out = *iss
+	}); callErr != nil {
+		return watcher.TrackedIssue{}, callErr
+	}
+	return out, err
+}
+
+func (b *APIBackend) Logs(ctx context.Context, repo string, num, backlog int) ([]api.LogLine, <-chan api.LogLine, func(), error) {
+	var recent []api.LogLine
+	var lines <-chan api.LogLine
+	var cancel func()
+	err := b.call(ctx, func(m *Model) {
+		// Lines are only appended on this goroutine, so subscribing here
+		// neither loses nor repeats any around the backlog.
+		lines, cancel = b.hub.Subscribe()
+		if backlog <= 0 {
+			return
+		}
+		keys := make([]string, 0, len(m.logs))
+		for key := range m.logs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			r, n := parseIssueKey(key)
+			if repo != "" && r != repo || num != 0 && n != num {
+				continue
+			}
+			logs := m.logs[key]
+			if len(logs) > backlog {
+				logs = logs[len(logs)-backlog:]
+			}
+			for _, l := range logs {
+				recent = append(recent, api.LogLine{Repo: r, Number: n, At: l.at, Text: l.text})
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return recent, lines, cancel, nil
+}
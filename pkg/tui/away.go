@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// awayState tracks keyboard idleness. While away, auto-start is suspended
+// (polling continues) and whatever accumulates is tallied for a summary
+// shown on the next keypress.
+type awayState struct {
+	after     time.Duration // idle time before going away; 0 disables
+	lastInput time.Time
+	away      bool
+	idleSince time.Time   // lastInput when the user went away
+	pending   *tea.KeyMsg // the key that woke the summary, run once it closes
+
+	newIssues int // discovered while away, left pending
+	ready     int
+	failed    int
+	asked     int // stopped with questions for you
+}
+
+// checkIdle flips into away mode once no key has been seen for a.after.
+func (m *Model) checkIdle() {
+	a := &m.away
+	if a.after <= 0 || a.away || m.now.Sub(a.lastInput) < a.after {
+		return
+	}
+	a.away = true
+	a.idleSince = a.lastInput
+	a.newIssues, a.ready, a.failed, a.asked = 0, 0, 0, 0
+}
+
+// awayReplayKeys are the keys that, having woken the away summary, are
+// run once it closes. They only move the cursor; any other key, which
+// could act on whatever issue the list has since moved under it, only
+// wakes the summary.
+var awayReplayKeys = map[string]bool{
+	"j": true, "k": true, "up": true, "down": true,
+	"pgup": true, "pgdown": true, "home": true, "end": true,
+}
+
+// noteInput records a keypress. If the user just came back from being
+// away it shows the summary dialog and returns true: the key is held
+// until the summary closes, and then run only if it is one of
+// awayReplayKeys, so it can't trigger an action unseen. Only ctrl+c goes
+// through at once.
+func (m *Model) noteInput(msg tea.KeyMsg) bool {
+	m.away.lastInput = time.Now()
+	if !m.away.away {
+		return false
+	}
+	m.away.away = false
+	m.away.pending = nil
+	m.focus = focusAway
+	switch key := msg.String(); {
+	case key == "ctrl+c":
+		return false
+	case awayReplayKeys[key]:
+		m.away.pending = &msg
+	}
+	return true
+}
+
+// closeAway closes the summary dialog and runs the key that woke it, if
+// any.
+func (m *Model) closeAway() tea.Cmd {
+	m.focus = focusList
+	pending := m.away.pending
+	m.away.pending = nil
+	if pending == nil {
+		return nil
+	}
+	return m.handleKey(*pending)
+}
+
+// autoStartAllowed reports whether a newly discovered issue may be started
+// without the user pressing space.
+func (m *Model) autoStartAllowed(repo string) bool {
+	return m.autoStart && m.ghClient.Permissions().AllowAutoStart && !m.away.away && m.seededRepos[repo]
+}
+
+// tallyAway counts an event toward the away summary.
+func (m *Model) tallyAway(ev watcher.Event) {
+	if !m.away.away {
+		return
+	}
+	switch ev.Kind {
+	case watcher.EventIssueFound:
+		if m.seededRepos[ev.Repo] {
+			m.away.newIssues++
+		}
+	case watcher.EventReady:
+		m.away.ready++
+	case watcher.EventNeedsInput:
+		m.away.asked++
+	case watcher.EventError:
+		if ev.IssueNum > 0 {
+			m.away.failed++
+		}
+	}
+}
+
+func (m Model) renderAwayDialog() string {
+	a := m.away
+	var d strings.Builder
+	d.WriteString(dialogTitleStyle.Render("Welcome back"))
+	d.WriteString("\n\n")
+	d.WriteString(fmt.Sprintf("Idle since %s. Auto-start was paused; polling continued.\n\n",
+		formatStamp(a.idleSince, m.now, m.relativeTimes)))
+
+	row := func(n int, what string, style lipgloss.Style) {
+		text := fmt.Sprintf("  %3d  %s", n, what)
+		if n == 0 {
+			d.WriteString(headerDimStyle.Render(text))
+		} else {
+			d.WriteString(style.Render(text))
+		}
+		d.WriteString("\n")
+	}
+	row(a.newIssues, "new issues waiting (not started)", lipgloss.NewStyle().Foreground(colorBlue))
+	row(a.ready, "ready for review", statusReadyBoldStyle)
+	row(a.failed, "failed", statusFailedStyle)
+	row(a.asked, "waiting for your answer", statusNeedsInputStyle)
+
+	d.WriteString("\n")
+	closeHelp := "close"
+	if a.pending != nil {
+		closeHelp = "close, then " + a.pending.String()
+	}
+	d.WriteString(fmtHelp("esc", closeHelp) + "  " + fmtHelp("S", "start all pending"))
+
+	dialog := dialogStyle.Render(d.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNoteInput(t *testing.T) {
+	tests := []struct {
+		key         tea.KeyMsg
+		wantHeld    bool // the key only wakes the summary
+		wantPending bool // ...and is run once it closes
+	}{
+		{tea.KeyMsg{Type: tea.KeyDown}, true, true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, true, true},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X")}, true, false},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")}, true, false},
+		{tea.KeyMsg{Type: tea.KeyEnter}, true, false},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key.String(), func(t *testing.T) {
+			m := &Model{away: awayState{away: true}}
+			if held := m.noteInput(tt.key); held != tt.wantHeld {
+				t.Errorf("held = %v, want %v", held, tt.wantHeld)
+			}
+			if (m.away.pending != nil) != tt.wantPending {
+				t.Errorf("pending = %v, want %v", m.away.pending, tt.wantPending)
+			}
+			if m.focus != focusAway || m.away.away {
+				t.Errorf("focus = %v, away = %v: the summary isn't showing", m.focus, m.away.away)
+			}
+			// Back, the next key goes through.
+			if m.noteInput(tt.key) {
+				t.Error("held a key once back")
+			}
+		})
+	}
+}
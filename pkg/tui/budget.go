@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// budgetRefresh is how often the focused run's session is re-read for its
+// turns and tokens.
+const budgetRefresh = 2 * time.Second
+
+// runBudget is what the focused issue's Claude run has used so far.
+type runBudget struct {
+	key   string
+	usage watcher.SessionUsage
+	at    time.Time // when it was last read; zero while a read is pending
+}
+
+type budgetMsg struct {
+	key   string
+	usage watcher.SessionUsage
+	err   error
+}
+
+// refreshBudget re-reads the focused issue's session usage if Claude is
+// running and the last read is stale.
+func (m *Model) refreshBudget() tea.Cmd {
+	iss := m.focusIssue
+	if iss == nil || iss.Status != watcher.StatusClaudeRunning || iss.Workdir == "" {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	if m.budget.key == key && (m.budget.at.IsZero() || m.now.Sub(m.budget.at) < budgetRefresh) {
+		return nil
+	}
+	if m.budget.key != key {
+		m.budget = runBudget{key: key}
+	}
+	m.budget.at = time.Time{}
+	workdir, since := iss.Workdir, m.claudeSince[key]
+	return func() tea.Msg {
+		u, err := watcher.ReadSessionUsage(workdir, since)
+		return budgetMsg{key: key, usage: u, err: err}
+	}
+}
+
+func (m *Model) handleBudget(msg budgetMsg) {
+	if m.budget.key != msg.key {
+		return
+	}
+	m.budget.at = m.now
+	if msg.err == nil {
+		m.budget.usage = msg.usage
+	}
+}
+
+// budgetLine shows a running issue's turns, time and tokens against the
+// run limits, e.g. "turns 12/50 · 8m/45m · 45k ctx · 3.2k out", or ""
+// if Claude isn't running.
+func (m Model) budgetLine(iss watcher.TrackedIssue) string {
+	if iss.Status != watcher.StatusClaudeRunning {
+		return ""
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	limits := m.manager.RunLimits()
+	var parts []string
+	if m.budget.key == key {
+		turns := fmt.Sprintf("turns %d", m.budget.usage.Turns)
+		if limits.MaxTurns > 0 {
+			turns += fmt.Sprintf("/%d", limits.MaxTurns)
+		}
+		parts = append(parts, turns)
+	}
+	if since, ok := m.claudeSince[key]; ok {
+		spent := elapsed(since, m.now)
+		if limits.Timeout > 0 {
+			spent += "/" + shortDuration(limits.Timeout)
+		}
+		parts = append(parts, spent)
+	}
+	if u := m.budget.usage; m.budget.key == key && u.Turns > 0 {
+		parts = append(parts, tokenCount(u.ContextTokens)+" ctx", tokenCount(u.OutputTokens)+" out")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// tokenCount renders n tokens compactly, e.g. "950", "3.2k" or "1.1M".
+func tokenCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1_000_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	}
+}
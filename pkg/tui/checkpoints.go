@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// checkpointMsg reports a checkpoint taken (restored false) or restored.
+type checkpointMsg struct {
+	repo       string
+	num        int
+	checkpoint watcher.Checkpoint
+	restored   bool
+	err        error
+}
+
+// checkpointFor snapshots iss's worktree in the background.
+func (m *Model) checkpointFor(iss *watcher.TrackedIssue, label string) tea.Cmd {
+	if iss == nil || iss.Workdir == "" {
+		return nil
+	}
+	repo, num, mgr := iss.Repo, iss.Number, m.manager
+	return func() tea.Msg {
+		c, err := mgr.Checkpoint(repo, num, label)
+		return checkpointMsg{repo: repo, num: num, checkpoint: c, err: err}
+	}
+}
+
+// openCheckpoints opens the list of iss's checkpoints to restore from.
+func (m *Model) openCheckpoints(iss *watcher.TrackedIssue) {
+	if iss == nil || iss.Workdir == "" {
+		return
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	if isActive(iss.Status) {
+		m.appendLog(key, "⏸ Pause the issue before restoring a checkpoint")
+		return
+	}
+	list, err := watcher.ListCheckpoints(watcher.IssueDir(m.manager.BaseDir(), iss.Repo, iss.Number))
+	if err != nil {
+		m.appendLog(key, "❌ Checkpoints: "+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		m.appendLog(key, "No checkpoints yet — press z to take one")
+		return
+	}
+	m.checkpointIssue = key
+	m.checkpoints = list
+	m.checkpointCursor = 0
+	m.checkpointFrom = m.focus
+	m.focus = focusRestore
+}
+
+func (m *Model) handleCheckpointsKey(key string) tea.Cmd {
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q":
+		m.focus = m.checkpointFrom
+	case "j", "down":
+		if m.checkpointCursor < len(m.checkpoints)-1 {
+			m.checkpointCursor++
+		}
+	case "k", "up":
+		if m.checkpointCursor > 0 {
+			m.checkpointCursor--
+		}
+	case "enter", "y":
+		m.focus = m.checkpointFrom
+		repo, num := parseIssueKey(m.checkpointIssue)
+		id := m.checkpoints[m.checkpointCursor].ID
+		mgr := m.manager
+		m.appendLog(m.checkpointIssue, "⏪ Restoring checkpoint "+id+"...")
+		return func() tea.Msg {
+			c, err := mgr.RestoreCheckpoint(repo, num, id)
+			return checkpointMsg{repo: repo, num: num, checkpoint: c, restored: true, err: err}
+		}
+	}
+	return nil
+}
+
+func (m *Model) handleCheckpoint(msg checkpointMsg) tea.Cmd {
+	key := issueKey(msg.repo, msg.num)
+	c := msg.checkpoint
+	switch {
+	case msg.err != nil && msg.restored:
+		m.appendLog(key, "❌ Restore failed: "+msg.err.Error())
+	case msg.err != nil:
+		m.appendLog(key, "❌ Checkpoint failed: "+msg.err.Error())
+	case msg.restored:
+		m.appendLog(key, fmt.Sprintf("⏪ Restored checkpoint %s (%s)", c.ID, c.Label))
+		if m.focus == focusFocus && m.focusIssue != nil && issueKey(m.focusIssue.Repo, m.focusIssue.Number) == key {
+			return m.loadFocusTab()
+		}
+	default:
+		m.appendLog(key, fmt.Sprintf("📸 Checkpoint %s (%s)", c.ID, c.Label))
+	}
+	return nil
+}
+
+func (m Model) renderCheckpointsDialog() string {
+	var d strings.Builder
+	d.WriteString(dialogTitleStyle.Render("Restore " + m.checkpointIssue))
+	d.WriteString("\n\n")
+	for i, c := range m.checkpoints {
+		line := fmt.Sprintf("%s  %-20s  %s", formatStamp(c.CreatedAt, m.now, m.relativeTimes), c.Label, c.Head[:min(len(c.Head), 8)])
+		if i == m.checkpointCursor {
+			d.WriteString(selectedRowStyle.Render("▸ " + line))
+		} else {
+			d.WriteString("  " + line)
+		}
+		d.WriteString("\n")
+	}
+	d.WriteString("\n")
+	d.WriteString(headerDimStyle.Render("The current state is checkpointed first, so a restore can be undone."))
+	d.WriteString("\n\n")
+	d.WriteString(fmtHelp("enter", "restore") + "  " + fmtHelp("j/k", "select") + "  " + fmtHelp("esc", "cancel"))
+
+	dialog := dialogStyle.Render(d.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
+}
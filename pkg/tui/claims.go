@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// claimState tracks how long a claimed issue has been stuck in a failed
+// state. It is kept in the issue's watcher.ClaimFile too, so a restart
+// doesn't start the wait over.
+type claimState struct {
+	status   watcher.IssueStatus
+	since    time.Time
+	released bool
+}
+
+type claimReleasedMsg struct {
+	repo string
+	num  int
+	err  error
+}
+
+// stuck reports whether an issue's claim should expire if left alone. A
+// paused issue's shouldn't: someone paused it, and means to come back.
+func stuck(status watcher.IssueStatus) bool {
+	return status == watcher.StatusFailed
+}
+
+// checkClaims hands back issues that have been failed for longer than
+// claimTTL by removing lurker's 👀 claim, so work doesn't sit behind an
+// instance nobody is looking at.
+func (m *Model) checkClaims() tea.Cmd {
+	if m.claimTTL <= 0 {
+		return nil
+	}
+	baseDir := m.manager.BaseDir()
+	var cmds []tea.Cmd
+	for _, iss := range m.issues.all() {
+		key := issueKey(iss.Repo, iss.Number)
+		if !stuck(iss.Status) {
+			if _, ok := m.claims[key]; ok {
+				delete(m.claims, key)
+				watcher.ClearClaim(baseDir, iss.Repo, iss.Number)
+			}
+			continue
+		}
+		c, ok := m.claims[key]
+		if !ok {
+			// What an earlier lurker saw, unless the issue has run since.
+			if saved, found := watcher.ReadClaim(baseDir, iss.Repo, iss.Number); found && saved.Status == iss.Status.String() && !saved.Since.Before(iss.StartedAt) {
+				c, ok = claimState{status: iss.Status, since: saved.Since, released: saved.Released}, true
+				m.claims[key] = c
+			}
+		}
+		if !ok || c.status != iss.Status {
+			m.claims[key] = claimState{status: iss.Status, since: m.now}
+			watcher.WriteClaim(baseDir, iss.Repo, iss.Number, watcher.Claim{Status: iss.Status.String(), Since: m.now})
+			continue
+		}
+		if c.released || m.now.Sub(c.since) < m.claimTTL {
+			continue
+		}
+		c.released = true
+		m.claims[key] = c
+
+		repo, num := iss.Repo, iss.Number
+		mgr := m.manager
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			return claimReleasedMsg{repo: repo, num: num, err: mgr.ReleaseClaim(ctx, repo, num)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) handleClaimReleased(msg claimReleasedMsg) {
+	key := issueKey(msg.repo, msg.num)
+	if msg.err != nil {
+		var perm *github.PermissionError
+		if errors.As(msg.err, &perm) {
+			m.appendLog(key, fmt.Sprintf("🔒 Claim not released: %s is off", perm.Action))
+		} else {
+			m.appendLog(key, "🔒 Claim not released: "+msg.err.Error())
+		}
+		return
+	}
+	if c, ok := m.claims[key]; ok && c.released {
+		watcher.WriteClaim(m.manager.BaseDir(), msg.repo, msg.num, watcher.Claim{Status: c.status.String(), Since: c.since, Released: true})
+	}
+	what := fmt.Sprintf("claim released (%s for over %s)", m.findIssueStatus(msg.repo, msg.num), shortDuration(m.claimTTL))
+	m.appendLog(key, "🔓 "+what)
+	m.notifier.notify(key, what)
+}
+
+// shortDuration formats d without trailing zero units: 24h, 1h30m, 90s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/api"
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// claimModel returns a Model, over a manager of baseDir, with just what
+// checkClaims needs, holding iss.
+func claimModel(t *testing.T, baseDir string, iss watcher.TrackedIssue, now time.Time) *Model {
+	t.Helper()
+	mgr, err := watcher.NewManager(baseDir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mgr.Stop)
+	m := &Model{
+		manager:  mgr,
+		issues:   newIssueStore(),
+		claims:   make(map[string]claimState),
+		logs:     make(map[string][]logLine),
+		logHub:   &api.Hub{},
+		claimTTL: time.Hour,
+		now:      now,
+	}
+	m.issues.add(iss)
+	return m
+}
+
+func TestCheckClaims(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	failed := watcher.TrackedIssue{Repo: "o/r", Number: 1, Status: watcher.StatusFailed, StartedAt: start.Add(-time.Minute)}
+
+	t.Run("released once the TTL is up, across a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(watcher.IssueDir(dir, "o/r", 1), 0o755)
+		if cmd := claimModel(t, dir, failed, start).checkClaims(); cmd != nil {
+			t.Error("released at once")
+		}
+		// A later lurker picks up the wait where this one left it.
+		m := claimModel(t, dir, failed, start.Add(90*time.Minute))
+		if cmd := m.checkClaims(); cmd == nil {
+			t.Fatal("not released after the TTL")
+		}
+		m.handleClaimReleased(claimReleasedMsg{repo: "o/r", num: 1})
+		if c, _ := watcher.ReadClaim(dir, "o/r", 1); !c.Released {
+			t.Errorf("claim = %+v, want released", c)
+		}
+		if cmd := claimModel(t, dir, failed, start.Add(3*time.Hour)).checkClaims(); cmd != nil {
+			t.Error("released again after another restart")
+		}
+	})
+
+	t.Run("a run since starts the wait over", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(watcher.IssueDir(dir, "o/r", 1), 0o755)
+		claimModel(t, dir, failed, start).checkClaims()
+		rerun := failed
+		rerun.StartedAt = start.Add(30 * time.Minute)
+		if cmd := claimModel(t, dir, rerun, start.Add(90*time.Minute)).checkClaims(); cmd != nil {
+			t.Error("released on the wait of the run before")
+		}
+	})
+
+	t.Run("paused issues keep their claim", func(t *testing.T) {
+		paused := failed
+		paused.Status = watcher.StatusPaused
+		m := claimModel(t, t.TempDir(), paused, start)
+		m.checkClaims()
+		m.now = start.Add(48 * time.Hour)
+		if cmd := m.checkClaims(); cmd != nil {
+			t.Error("released a paused issue's claim")
+		}
+	})
+
+	t.Run("not released is reported", func(t *testing.T) {
+		m := claimModel(t, t.TempDir(), failed, start)
+		m.handleClaimReleased(claimReleasedMsg{repo: "o/r", num: 1, err: &github.PermissionError{Action: "allow_comments"}})
+		logs := m.logs["o/r#1"]
+		if len(logs) != 1 || !strings.Contains(logs[0].text, "Claim not released: allow_comments is off") {
+			t.Errorf("logs = %+v", logs)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// column identifies one cell of an issue row in the tree.
+type column int
+
+const (
+	colStatus column = iota
+	colBeads
+	colNumber
+	colTitle
+	colPR
+	colCost
+	colElapsed
+	colProgress
+	colLogs
+)
+
+// columnSpec describes how a column is sized and when it gives way.
+// The title column is flexible and absorbs whatever width remains; the
+// others are fixed. When the row doesn't fit, columns with the lowest
+// priority are hidden first.
+type columnSpec struct {
+	id       column
+	name     string // used in --columns
+	width    int    // fixed width; 0 for the flexible title column
+	priority int    // higher survives longer on narrow terminals
+}
+
+var columnSpecs = []columnSpec{
+	{colStatus, "status", 7, 2},
+	{colBeads, "beads", 13, 5},
+	{colNumber, "number", 6, 6},
+	{colTitle, "title", 0, 7},
+	{colPR, "pr", 6, 3},
+	{colCost, "cost", 7, 1},
+	{colElapsed, "elapsed", 8, 4},
+	{colProgress, "progress", 18, 2},
+	{colLogs, "logs", 6, 0},
+}
+
+const (
+	issueIndent    = 6  // leading spaces before the first column
+	columnGap      = 2  // spaces between columns
+	minTitleWidth  = 12 // title never shrinks below this before others drop
+	maxTitleLength = 60 // titles are capped even on very wide terminals
+)
+
+// DefaultColumns is the column set shown when none is configured.
+var DefaultColumns = []string{"beads", "number", "title", "pr", "cost", "elapsed", "progress", "logs"}
+
+// parseColumns turns column names into a visibility set.
+func parseColumns(names []string) (map[column]bool, error) {
+	visible := make(map[column]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, spec := range columnSpecs {
+			if spec.name == name {
+				visible[spec.id] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+	// A row without a title is useless; always keep it.
+	visible[colTitle] = true
+	return visible, nil
+}
+
+// layoutColumns picks which enabled columns fit in width and how wide the
+// title may be. Columns are returned in display order.
+func layoutColumns(enabled map[column]bool, width int) ([]columnSpec, int) {
+	var cols []columnSpec
+	for _, spec := range columnSpecs {
+		if enabled[spec.id] {
+			cols = append(cols, spec)
+		}
+	}
+
+	for {
+		used := issueIndent
+		for i, c := range cols {
+			if i > 0 {
+				used += columnGap
+			}
+			used += c.width
+		}
+		titleWidth := width - used
+		if titleWidth >= minTitleWidth || len(cols) <= 1 {
+			if titleWidth > maxTitleLength {
+				titleWidth = maxTitleLength
+			}
+			if titleWidth < 1 {
+				titleWidth = 1
+			}
+			return cols, titleWidth
+		}
+
+		// Drop the lowest-priority fixed column and try again.
+		drop := -1
+		for i, c := range cols {
+			if c.id == colTitle {
+				continue
+			}
+			if drop < 0 || c.priority < cols[drop].priority {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			return cols, 1
+		}
+		cols = append(cols[:drop], cols[drop+1:]...)
+	}
+}
+
+// truncate shortens plain (unstyled) s to width columns with an ellipsis.
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	if width == 1 {
+		return "…"
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// cell pads plain text to width and applies style. Styling happens after
+// truncation so escape sequences are never cut in half.
+func cell(text string, width int, style lipgloss.Style) string {
+	text = truncate(text, width)
+	pad := width - lipgloss.Width(text)
+	if pad < 0 {
+		pad = 0
+	}
+	return style.Render(text) + strings.Repeat(" ", pad)
+}
+
+// renderIssueCell renders one column of an issue row.
+func (m Model) renderIssueCell(iss watcher.TrackedIssue, c columnSpec, titleWidth int) string {
+	switch c.id {
+	case colStatus:
+		text := iss.StatusText()
+		if iss.Status == watcher.StatusReady {
+			text = "REVIEW"
+		}
+		return cell(text, c.width, statusStyle(iss.Status))
+	case colBeads:
+		// Already styled; fixed visual width of 13.
+		return m.renderBeadsCompact(iss)
+	case colNumber:
+		return cell(fmt.Sprintf("#%d", iss.Number), c.width, repoCountStyle)
+	case colTitle:
+		title := truncate(iss.Title, titleWidth)
+		pad := titleWidth - lipgloss.Width(title)
+		return hyperlink(iss.URL, title) + strings.Repeat(" ", pad)
+	case colPR:
+		text := ""
+		if iss.PRNumber > 0 {
+			text = fmt.Sprintf("PR#%d", iss.PRNumber)
+		}
+		return hyperlink(iss.PRURL, cell(text, c.width, statusReadyStyle))
+	case colCost:
+		text := ""
+		if iss.CostUSD > 0 {
+			text = fmt.Sprintf("$%.2f", iss.CostUSD)
+		}
+		return cell(text, c.width, headerDimStyle)
+	case colElapsed:
+		text := ""
+		if isActive(iss.Status) {
+			text = m.spinner.View() + " " + elapsed(iss.StartedAt, m.now)
+		}
+		return cell(text, c.width, headerDimStyle)
+	case colProgress:
+		return cell(m.progressEstimate(iss), c.width, headerDimStyle)
+	case colLogs:
+		text := ""
+		if n := len(m.logs[issueKey(iss.Repo, iss.Number)]); n > 0 {
+			text = fmt.Sprintf("[%d]", n)
+		}
+		return cell(text, c.width, headerDimStyle)
+	}
+	return ""
+}
+
+// statusStyle picks the badge style for a status.
+func statusStyle(status watcher.IssueStatus) lipgloss.Style {
+	switch status {
+	case watcher.StatusReady:
+		return statusReadyBoldStyle
+	case watcher.StatusFailed, watcher.StatusConflict, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+		return statusFailedStyle
+	case watcher.StatusPaused:
+		return statusPausedStyle
+	case watcher.StatusNeedsInput, watcher.StatusWaiting:
+		return statusNeedsInputStyle
+	case watcher.StatusQueued:
+		return statusQueuedStyle
+	case watcher.StatusReacted:
+		return statusReactedStyle
+	case watcher.StatusPending:
+		return beadPending
+	default:
+		return statusRunningStyle
+	}
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// commentCommand carries out a teammate's "@lurker <command>" comment on
+// an issue the way the matching key would, logging who asked.
+func (m *Model) commentCommand(ev watcher.Event) tea.Cmd {
+	iss := m.findIssue(ev.Repo, ev.IssueNum)
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(ev.Repo, ev.IssueNum)
+	who := "@" + ev.By
+	refuse := func(why string) {
+		m.appendLog(key, fmt.Sprintf("💬 %s asked to %s, but %s", who, ev.Text, why))
+	}
+
+	switch ev.Text {
+	case "start", "retry":
+		switch iss.Status {
+		case watcher.StatusPending:
+			m.startIssue(iss, "▶ Started by "+who)
+		case watcher.StatusPaused:
+			m.startIssue(iss, "▶ Resumed by "+who)
+		case watcher.StatusFailed, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+			m.startIssue(iss, "▶ Retrying for "+who)
+		default:
+			refuse("it is " + iss.Status.String())
+		}
+	case "pr":
+		perms := m.ghClient.Permissions()
+		switch {
+		case iss.PRNumber != 0:
+			refuse("it already has " + iss.PRURL)
+		case iss.Status != watcher.StatusReady:
+			refuse("it is " + iss.Status.String() + ", not ready")
+		case !perms.AllowPush || !perms.AllowPRCreate:
+			refuse("lurker may not push and open PRs here")
+		default:
+			m.appendLog(key, "💬 Opening a PR for "+who)
+			return m.approvePRFor(iss, false)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// lineTracker follows the terminal line the cursor is on through a
+// session's output, just well enough to read back each command line the
+// shell echoed when the user pressed Enter. Full-screen programs (in the
+// alternate screen) are ignored: what is typed there isn't a command.
+type lineTracker struct {
+	mu        sync.Mutex
+	line      []rune
+	col       int
+	esc       []byte // unfinished escape sequence
+	partial   []byte // unfinished UTF-8 sequence
+	altScreen bool
+	enters    int // Enters sent whose line the shell hasn't ended yet
+	commands  []string
+}
+
+// promptMarks end common prompts; the first one on a line is taken to be
+// where the command starts. Issue shells' own prompt is "lurker$ ".
+var promptMarks = []string{"$ ", "% ", "# ", "> ", "❯ "}
+
+// splitPrompt returns what follows the prompt on line, and whether line
+// has one.
+func splitPrompt(line string) (string, bool) {
+	at := -1
+	var mark string
+	for _, m := range promptMarks {
+		if i := strings.Index(line, m); i >= 0 && (at < 0 || i < at) {
+			at, mark = i, m
+		}
+	}
+	if at < 0 {
+		return "", false
+	}
+	// Input boxes, such as Claude's, draw a border after the text.
+	return strings.TrimRight(strings.TrimSpace(line[at+len(mark):]), " │"), true
+}
+
+// expectEnter notes that the user pressed Enter n times; the lines the
+// shell ends next are their commands.
+func (t *lineTracker) expectEnter(n int) {
+	t.mu.Lock()
+	t.enters += n
+	t.mu.Unlock()
+}
+
+func (t *lineTracker) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := append(t.partial, p...)
+	t.partial = nil
+	for len(data) > 0 {
+		if len(t.esc) > 0 || data[0] == 0x1b {
+			t.esc = append(t.esc, data[0])
+			data = data[1:]
+			t.escape()
+			continue
+		}
+		if !utf8.FullRune(data) {
+			t.partial = append([]byte(nil), data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		t.put(r)
+	}
+	return len(p), nil
+}
+
+// put applies one character of output to the line.
+func (t *lineTracker) put(r rune) {
+	switch {
+	case r == '\r':
+		t.col = 0
+	case r == '\n':
+		if t.enters > 0 {
+			t.enters--
+			if cmd, ok := splitPrompt(string(t.line)); ok && cmd != "" && !t.altScreen {
+				t.commands = append(t.commands, cmd)
+			}
+		}
+		t.line, t.col = t.line[:0], 0
+	case r == '\b':
+		t.col = max(t.col-1, 0)
+	case r == '\t':
+		t.col = (t.col/8 + 1) * 8
+	case r < 0x20 || r == 0x7f:
+	default:
+		for len(t.line) < t.col {
+			t.line = append(t.line, ' ')
+		}
+		if t.col < len(t.line) {
+			t.line[t.col] = r
+		} else {
+			t.line = append(t.line, r)
+		}
+		t.col++
+	}
+}
+
+// maxEscape bounds how long an escape sequence is followed before it is
+// given up on.
+const maxEscape = 256
+
+// escape acts on t.esc once it holds a whole sequence: CSI cursor moves,
+// erases and the alternate screen are followed, the rest skipped.
+func (t *lineTracker) escape() {
+	seq := t.esc
+	if len(seq) < 2 {
+		return
+	}
+	if len(seq) > maxEscape {
+		t.esc = nil
+		return
+	}
+	switch seq[1] {
+	case '[':
+		final := seq[len(seq)-1]
+		if len(seq) == 2 || final < 0x40 || final > 0x7e {
+			return
+		}
+		t.csi(string(seq[2:len(seq)-1]), final)
+	case '(', ')':
+		// Character set selection takes one more byte.
+		if len(seq) < 3 {
+			return
+		}
+	case ']':
+		// OSC, such as a window title: ends with BEL or ESC \.
+		if last := seq[len(seq)-1]; last != 0x07 && !(last == '\\' && seq[len(seq)-2] == 0x1b) {
+			return
+		}
+	}
+	t.esc = nil
+}
+
+func (t *lineTracker) csi(params string, final byte) {
+	n, err := strconv.Atoi(params)
+	if err != nil || n < 1 {
+		n = 1
+	}
+	switch final {
+	case 'C':
+		t.col += n
+	case 'D':
+		t.col = max(t.col-n, 0)
+	case 'G':
+		t.col = n - 1
+	case 'K':
+		switch params {
+		case "", "0":
+			t.line = t.line[:min(t.col, len(t.line))]
+		case "2":
+			t.line = t.line[:0]
+		}
+	case 'P':
+		if t.col < len(t.line) {
+			t.line = append(t.line[:t.col], t.line[min(t.col+n, len(t.line)):]...)
+		}
+	case 'h', 'l':
+		switch params {
+		case "?1049", "?1047", "?47":
+			t.altScreen = final == 'h'
+		}
+	}
+}
+
+// summary returns the commands seen, whether the shell shows its prompt
+// (rather than a command's output) and whether something is typed after
+// it.
+func (t *lineTracker) summary() (commands []string, atPrompt, typing bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rest, ok := splitPrompt(string(t.line))
+	return append([]string(nil), t.commands...), ok && !t.altScreen, rest != ""
+}
+
+// detachedMsg reports that the user detached from an issue's shell.
+type detachedMsg struct {
+	key        string
+	workdir    string
+	session    *ptySession
+	duration   time.Duration
+	commands   []string
+	atPrompt   bool     // the shell showed its prompt
+	typing     bool     // with an unfinished command after it
+	recordings []string // recordings made while attached
+	then       tea.Msg  // sent once the summary is logged, if not nil
+}
+
+// detachSummaryMsg carries what detachedMsg's follow-up checks found.
+type detachSummaryMsg struct {
+	detachedMsg
+	code  int // last command's exit status; -1 if unknown
+	dirty []string
+}
+
+// maxDetachCommands bounds how many commands a detach summary lists.
+const maxDetachCommands = 10
+
+// attachTo hands the terminal to session, iss's shell labelled key. On
+// detach, what happened is summarized in key's log, then done, if not nil,
+// is sent.
+func (m *Model) attachTo(session *ptySession, key string, iss *watcher.TrackedIssue, done tea.Msg) tea.Cmd {
+	a := &ptyAttacher{
+		session:  session,
+		label:    key,
+		issueDir: watcher.IssueDir(m.manager.BaseDir(), iss.Repo, iss.Number),
+	}
+	start := time.Now()
+	return tea.Exec(a, func(err error) tea.Msg {
+		commands, atPrompt, typing := a.tracker.summary()
+		return detachedMsg{
+			key: key, workdir: iss.Workdir, session: session,
+			duration: time.Since(start), commands: commands,
+			atPrompt: atPrompt, typing: typing,
+			recordings: a.recordings,
+			then:       done,
+		}
+	})
+}
+
+// checkDetached looks up the last command's exit status, if the shell is
+// back at its prompt, and the worktree's uncommitted files.
+func checkDetached(msg detachedMsg) tea.Cmd {
+	return func() tea.Msg {
+		sum := detachSummaryMsg{detachedMsg: msg, code: -1}
+		if msg.atPrompt && !msg.typing && len(msg.commands) > 0 && !msg.session.busy() {
+			// $? is still the last command's status when the probe starts.
+			if res, err := msg.session.run(context.Background(), "(exit $?)", 5*time.Second); err == nil {
+				sum.code = res.Code
+			}
+		}
+		if msg.workdir != "" {
+			cmd := exec.Command("git", "status", "--porcelain")
+			cmd.Dir = msg.workdir
+			if out, err := cmd.Output(); err == nil {
+				for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+					if len(line) > 3 {
+						sum.dirty = append(sum.dirty, line[3:])
+					}
+				}
+			}
+		}
+		return sum
+	}
+}
+
+// logDetach writes a detach summary to the issue's log.
+func (m *Model) logDetach(sum detachSummaryMsg) {
+	key := sum.key
+	n := len(sum.commands)
+	switch n {
+	case 0:
+		m.appendLog(key, fmt.Sprintf("🔌 Detached after %s, no commands run", sum.duration.Round(time.Second)))
+	case 1:
+		m.appendLog(key, fmt.Sprintf("🔌 Detached after %s, ran 1 command:", sum.duration.Round(time.Second)))
+	default:
+		m.appendLog(key, fmt.Sprintf("🔌 Detached after %s, ran %d commands:", sum.duration.Round(time.Second), n))
+	}
+	shown := sum.commands[max(n-maxDetachCommands, 0):]
+	if len(shown) < n {
+		m.appendLog(key, fmt.Sprintf("  … %d earlier", n-len(shown)))
+	}
+	for _, c := range shown {
+		m.appendLog(key, "  $ "+c)
+	}
+	switch {
+	case n > 0 && !sum.atPrompt:
+		m.appendLog(key, "  still running: "+sum.commands[n-1])
+	case sum.code >= 0:
+		m.appendLog(key, fmt.Sprintf("  last command exited %d", sum.code))
+	}
+	switch len(sum.dirty) {
+	case 0:
+		if sum.workdir != "" {
+			m.appendLog(key, "  worktree clean")
+		}
+	default:
+		files := sum.dirty
+		if len(files) > 5 {
+			files = append(files[:5:5], fmt.Sprintf("… %d more", len(sum.dirty)-5))
+		}
+		m.appendLog(key, fmt.Sprintf("  %d uncommitted: %s", len(sum.dirty), strings.Join(files, ", ")))
+	}
+	for _, path := range sum.recordings {
+		m.appendLog(key, "  🎬 Recorded "+path)
+	}
+}
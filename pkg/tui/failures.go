@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// failureSummary renders an error as its category and suggested fix,
+// falling back to the error itself when lurker doesn't recognize it.
+func failureSummary(text string) string {
+	f := watcher.ClassifyFailure(text)
+	summary := text
+	if f.Suggestion != "" {
+		summary = f.Suggestion
+	}
+	if f.Kind != watcher.FailureOther {
+		summary = "[" + string(f.Kind) + "] " + summary
+	}
+	return summary
+}
+
+// failureLine returns the line shown beneath a failed or conflicting
+// issue, or one whose build or tests failed, in the tree, or "".
+func failureLine(iss watcher.TrackedIssue) string {
+	switch {
+	case iss.Error == "":
+		return ""
+	case iss.Status == watcher.StatusFailed:
+		return "✗ " + failureSummary(iss.Error)
+	case iss.Status == watcher.StatusConflict:
+		return "✗ " + iss.Error + " — resolve in the shell, then press B"
+	case iss.Status == watcher.StatusBuildFailed, iss.Status == watcher.StatusTestsFailed:
+		return "✗ " + iss.Error + " — fix it in the shell, or press Space to retry"
+	}
+	return ""
+}
+
+// appendFailure logs an error followed by its suggested fix, if any.
+func (m *Model) appendFailure(key, text string) {
+	m.appendLog(key, "❌ "+text)
+	if f := watcher.ClassifyFailure(text); f.Suggestion != "" {
+		m.appendLog(key, "💡 "+f.Suggestion)
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// handleFeedback logs new review feedback on an issue's PR and offers to
+// have Claude address it, unless the repo has that happen by itself or
+// lurker may not push.
+func (m *Model) handleFeedback(ev watcher.Event) {
+	key := issueKey(ev.Repo, ev.IssueNum)
+	iss := m.findIssue(ev.Repo, ev.IssueNum)
+	if iss == nil {
+		return
+	}
+	m.appendLogAt(key, ev.Timestamp, fmt.Sprintf("💬 Review feedback on PR #%d:", iss.PRNumber))
+	for _, line := range strings.Split(ev.Text, "\n") {
+		m.appendLogAs(key, ev.Timestamp, logAgent, "  "+line)
+	}
+	if m.manager.Forge().Permissions().AllowPush && !m.manager.RepoConfig(iss.Workdir).AddressReviews {
+		m.appendLogAt(key, ev.Timestamp, "Press 'F' to have Claude address it")
+	}
+	m.expanded[key] = true
+	m.notifyTransition(ev, "got review feedback")
+}
+
+// handleFeedbackDone marks an issue ready again once Claude's fixes for
+// its review are pushed, and watches the PR's checks on them.
+func (m *Model) handleFeedbackDone(ev watcher.Event) {
+	key := issueKey(ev.Repo, ev.IssueNum)
+	m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusReady)
+	m.appendLogAt(key, ev.Timestamp, "✅ "+ev.Text)
+	if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil && iss.PRNumber > 0 {
+		iss.Checks = watcher.ChecksPending
+		m.saveIssueMeta(iss)
+		m.manager.WatchChecks(ev.Repo, ev.IssueNum, iss.PRNumber)
+		m.appendLogAt(key, ev.Timestamp, "🔄 Watching CI checks...")
+	}
+	m.notifyTransition(ev, "addressed its review")
+}
+
+// addressFeedbackFor has Claude address the pending review feedback on
+// iss's PR.
+func (m *Model) addressFeedbackFor(iss *watcher.TrackedIssue) tea.Cmd {
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	switch {
+	case iss.PRNumber == 0:
+		m.appendLog(key, "⚠ No PR to address feedback on")
+	case isActive(iss.Status):
+		m.appendLog(key, "⚠ Claude is busy; address the review once it's done")
+	case !m.manager.Forge().Permissions().AllowPush:
+		m.appendLog(key, "⚠ lurker may not push here (allow_push)")
+	case len(watcher.PendingFeedback(m.manager.BaseDir(), iss.Repo, iss.Number)) == 0:
+		m.appendLog(key, fmt.Sprintf("No new review feedback on PR #%d", iss.PRNumber))
+	default:
+		m.manager.AddressFeedback(iss.Repo, iss.Number)
+		m.expanded[key] = true
+	}
+	return nil
+}
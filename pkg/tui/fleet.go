@@ -0,0 +1,279 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/api"
+	"github.com/stefanpenner/lurker/pkg/api/lurkerpb"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// FleetModel is a read-only overview of several lurker instances, each
+// identified by its BaseDir (local, or mounted from another machine) or
+// the address of its control API. It only reads state.json and issue.json
+// files, or lists repos and issues over the API, and never changes
+// anything.
+type FleetModel struct {
+	dirs    []string
+	apis    []FleetAPI
+	refresh time.Duration
+
+	entries []fleetEntry
+	cursor  int // index into rows()
+	scroll  int
+	width   int
+	height  int
+	now     time.Time
+}
+
+// FleetAPI is an instance the overview reads over its control API.
+type FleetAPI struct {
+	Addr   string
+	Client lurkerpb.LurkerClient
+}
+
+// fleetAPITimeout bounds reading an instance over its API, so one that is
+// down doesn't hold up the others.
+const fleetAPITimeout = 5 * time.Second
+
+// fleetEntry is the latest snapshot of one instance, named by its
+// directory or API address.
+type fleetEntry struct {
+	name string
+	snap watcher.Snapshot
+	err  error
+}
+
+// fleetRow is one line of the overview: an instance header or an issue.
+type fleetRow struct {
+	entry int
+	issue *watcher.IssueMeta // nil for the instance header
+}
+
+type fleetLoadedMsg []fleetEntry
+type fleetTickMsg struct{}
+
+// NewFleetModel creates an overview of the lurker instances rooted at
+// dirs and serving apis, re-read every refresh.
+func NewFleetModel(dirs []string, apis []FleetAPI, refresh time.Duration) FleetModel {
+	return FleetModel{dirs: dirs, apis: apis, refresh: refresh, now: time.Now()}
+}
+
+func (m FleetModel) Init() tea.Cmd {
+	return m.load()
+}
+
+func (m FleetModel) load() tea.Cmd {
+	dirs, apis := m.dirs, m.apis
+	return func() tea.Msg {
+		entries := make([]fleetEntry, 0, len(dirs)+len(apis))
+		for _, dir := range dirs {
+			snap, err := watcher.LoadSnapshot(dir)
+			entries = append(entries, fleetEntry{name: dir, snap: snap, err: err})
+		}
+		for _, a := range apis {
+			snap, err := loadAPISnapshot(a)
+			entries = append(entries, fleetEntry{name: a.Addr, snap: snap, err: err})
+		}
+		return fleetLoadedMsg(entries)
+	}
+}
+
+// loadAPISnapshot reads the repos and issues of the instance serving f, as
+// LoadSnapshot reads them from disk.
+func loadAPISnapshot(f FleetAPI) (watcher.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetAPITimeout)
+	defer cancel()
+	repos, err := f.Client.ListRepos(ctx, &lurkerpb.ListReposRequest{})
+	if err != nil {
+		return watcher.Snapshot{}, err
+	}
+	issues, err := f.Client.ListIssues(ctx, &lurkerpb.ListIssuesRequest{})
+	if err != nil {
+		return watcher.Snapshot{}, err
+	}
+	snap := watcher.Snapshot{Repos: repos.GetRepos()}
+	for _, p := range issues.GetIssues() {
+		snap.Issues = append(snap.Issues, api.IssueFromProto(p))
+	}
+	sort.Slice(snap.Issues, func(i, j int) bool {
+		a, b := snap.Issues[i], snap.Issues[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+	return snap, nil
+}
+
+func (m FleetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case fleetLoadedMsg:
+		m.entries = msg
+		m.now = time.Now()
+		if n := len(m.rows()); m.cursor >= n {
+			m.cursor = max(n-1, 0)
+		}
+		return m, tea.Tick(m.refresh, func(time.Time) tea.Msg { return fleetTickMsg{} })
+
+	case fleetTickMsg:
+		return m, m.load()
+
+	case tea.KeyMsg:
+		rows := m.rows()
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "j", "down":
+			if m.cursor < len(rows)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "g":
+			m.cursor = 0
+		case "G":
+			m.cursor = max(len(rows)-1, 0)
+		case "o":
+			if m.cursor < len(rows) {
+				if iss := rows[m.cursor].issue; iss != nil {
+					url := iss.PRURL
+					if url == "" {
+						url = iss.URL
+					}
+					if url != "" {
+						openURL(url)
+					}
+				}
+			}
+		case "r":
+			return m, m.load()
+		}
+		m.ensureCursorVisible()
+	}
+	return m, nil
+}
+
+func (m FleetModel) rows() []fleetRow {
+	var rows []fleetRow
+	for i, e := range m.entries {
+		rows = append(rows, fleetRow{entry: i})
+		for j := range e.snap.Issues {
+			rows = append(rows, fleetRow{entry: i, issue: &e.snap.Issues[j]})
+		}
+	}
+	return rows
+}
+
+func (m FleetModel) listHeight() int {
+	return max(m.height-3, 1) // header + separator + footer
+}
+
+func (m *FleetModel) ensureCursorVisible() {
+	h := m.listHeight()
+	if m.cursor < m.scroll {
+		m.scroll = m.cursor
+	}
+	if m.cursor >= m.scroll+h {
+		m.scroll = m.cursor - h + 1
+	}
+}
+
+func (m FleetModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+	var b strings.Builder
+
+	title := headerStyle.Render("lurker fleet")
+	count := headerDimStyle.Render(fmt.Sprintf("%d instances", len(m.dirs)+len(m.apis)))
+	tag := lipgloss.NewStyle().Foreground(colorOrange).Bold(true).Render(" READ-ONLY ")
+	left := fmt.Sprintf(" %s  %s", title, count)
+	gap := max(m.width-lipgloss.Width(left)-lipgloss.Width(tag)-1, 1)
+	b.WriteString(left + strings.Repeat(" ", gap) + tag + "\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)) + "\n")
+
+	rows := m.rows()
+	h := m.listHeight()
+	for i := m.scroll; i < len(rows) && i < m.scroll+h; i++ {
+		line := m.renderRow(rows[i])
+		line = clipLine(line, m.width)
+		line += strings.Repeat(" ", max(m.width-lipgloss.Width(line), 0))
+		if i == m.cursor {
+			line = selectedRowStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	for i := len(rows) - m.scroll; i < h; i++ {
+		b.WriteString("\n")
+	}
+
+	sep := footerSepStyle.Render("  |  ")
+	b.WriteString(" " + fmtHelp("j/k", "navigate") + sep + fmtHelp("o", "open PR/issue") + sep +
+		fmtHelp("r", "refresh") + sep + fmtHelp("q", "quit"))
+	return b.String()
+}
+
+func (m FleetModel) renderRow(r fleetRow) string {
+	e := m.entries[r.entry]
+	if r.issue == nil {
+		line := " " + repoNameStyle.Render(e.name)
+		if e.err != nil {
+			return line + "  " + statusFailedStyle.Render(e.err.Error())
+		}
+		s := e.snap
+		active := len(s.Issues) - s.Count(watcher.StatusReady) - s.Count(watcher.StatusFailed) -
+			s.Count(watcher.StatusPaused) - s.Count(watcher.StatusPending) - s.Count(watcher.StatusNeedsInput) -
+			s.Count(watcher.StatusConflict) - s.Count(watcher.StatusBuildFailed) - s.Count(watcher.StatusTestsFailed)
+		parts := []string{
+			fmt.Sprintf("%d repos", len(s.Repos)),
+			statusRunningStyle.Render(fmt.Sprintf("%d active", active)),
+			statusReadyBoldStyle.Render(fmt.Sprintf("%d ready", s.Count(watcher.StatusReady))),
+			statusFailedStyle.Render(fmt.Sprintf("%d failed", s.Count(watcher.StatusFailed))),
+		}
+		if n := s.Count(watcher.StatusNeedsInput); n > 0 {
+			parts = append(parts, statusNeedsInputStyle.Render(fmt.Sprintf("%d need input", n)))
+		}
+		if n := s.Count(watcher.StatusConflict); n > 0 {
+			parts = append(parts, statusFailedStyle.Render(fmt.Sprintf("%d conflicting", n)))
+		}
+		if n := s.Count(watcher.StatusBuildFailed); n > 0 {
+			parts = append(parts, statusFailedStyle.Render(fmt.Sprintf("%d not building", n)))
+		}
+		if n := s.Count(watcher.StatusTestsFailed); n > 0 {
+			parts = append(parts, statusFailedStyle.Render(fmt.Sprintf("%d failing tests", n)))
+		}
+		if !s.Updated.IsZero() {
+			parts = append(parts, headerDimStyle.Render("updated "+ago(s.Updated, m.now)))
+		}
+		return line + "  " + strings.Join(parts, footerSepStyle.Render(" · "))
+	}
+
+	iss := r.issue
+	status, _ := watcher.ParseIssueStatus(iss.Status)
+	line := "   " + cell(iss.Status, 8, statusStyle(status)) +
+		" " + cell(fmt.Sprintf("%s#%d", iss.Repo, iss.Number), 28, normalRowStyle) +
+		" " + cell(iss.Title, max(m.width-70, 10), normalRowStyle)
+	if iss.PRNumber > 0 {
+		line += " " + statusReadyStyle.Render(fmt.Sprintf("PR #%d", iss.PRNumber))
+	}
+	if iss.Attempts > 1 {
+		line += " " + headerDimStyle.Render(fmt.Sprintf("×%d", iss.Attempts))
+	}
+	if iss.Error != "" {
+		line += " " + statusFailedStyle.Render(truncate(failureSummary(iss.Error), 50))
+	}
+	return line
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// issueStore holds the tracked issues keyed by issueKey, in the order they
+// were found. It hands out pointers that stay put while other issues come
+// and go, so a selection or the focus view can't slide onto a different
+// issue.
+type issueStore struct {
+	byKey map[string]*watcher.TrackedIssue
+	order []string // keys, oldest first
+}
+
+func newIssueStore() *issueStore {
+	return &issueStore{byKey: make(map[string]*watcher.TrackedIssue)}
+}
+
+// get returns repo#num, or nil.
+func (s *issueStore) get(repo string, num int) *watcher.TrackedIssue {
+	return s.byKey[issueKey(repo, num)]
+}
+
+// add tracks iss, replacing an issue with the same key in place, and
+// returns the stored issue.
+func (s *issueStore) add(iss watcher.TrackedIssue) *watcher.TrackedIssue {
+	key := issueKey(iss.Repo, iss.Number)
+	if old, ok := s.byKey[key]; ok {
+		*old = iss
+		return old
+	}
+	p := &iss
+	s.byKey[key] = p
+	s.order = append(s.order, key)
+	return p
+}
+
+// remove stops tracking repo#num, returning the issue it held or nil.
+func (s *issueStore) remove(repo string, num int) *watcher.TrackedIssue {
+	key := issueKey(repo, num)
+	iss, ok := s.byKey[key]
+	if !ok {
+		return nil
+	}
+	delete(s.byKey, key)
+	s.order = slices.DeleteFunc(s.order, func(k string) bool { return k == key })
+	return iss
+}
+
+// rekey refiles iss, stored under oldKey, after its Repo or Number
+// changed. It keeps its place in the order; an issue already under the
+// new key is dropped.
+func (s *issueStore) rekey(oldKey string, iss *watcher.TrackedIssue) {
+	newKey := issueKey(iss.Repo, iss.Number)
+	if newKey == oldKey || s.byKey[oldKey] != iss {
+		return
+	}
+	if s.byKey[newKey] != nil {
+		s.remove(iss.Repo, iss.Number)
+	}
+	delete(s.byKey, oldKey)
+	s.byKey[newKey] = iss
+	if i := slices.Index(s.order, oldKey); i >= 0 {
+		s.order[i] = newKey
+	}
+}
+
+// all returns the issues in the order they were found.
+func (s *issueStore) all() []*watcher.TrackedIssue {
+	issues := make([]*watcher.TrackedIssue, len(s.order))
+	for i, key := range s.order {
+		issues[i] = s.byKey[key]
+	}
+	return issues
+}
+
+// inRepo returns repo's issues in the order they were found.
+func (s *issueStore) inRepo(repo string) []*watcher.TrackedIssue {
+	var issues []*watcher.TrackedIssue
+	for _, key := range s.order {
+		if iss := s.byKey[key]; iss.Repo == repo {
+			issues = append(issues, iss)
+		}
+	}
+	return issues
+}
+
+// len returns how many issues are tracked.
+func (s *issueStore) len() int {
+	return len(s.order)
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+func trackedIssue(repo string, num int, title string) watcher.TrackedIssue {
+	return watcher.TrackedIssue{Repo: repo, Number: num, Title: title}
+}
+
+// storeKeys lists s's issues as keys, in its order.
+func storeKeys(s *issueStore) []string {
+	var keys []string
+	for _, iss := range s.all() {
+		keys = append(keys, issueKey(iss.Repo, iss.Number))
+	}
+	return keys
+}
+
+func TestIssueStore_Add(t *testing.T) {
+	tests := []struct {
+		name string
+		add  []watcher.TrackedIssue
+		want []string
+	}{
+		{"in the order found", []watcher.TrackedIssue{trackedIssue("o/r", 2, ""), trackedIssue("o/r", 1, ""), trackedIssue("o/s", 1, "")}, []string{"o/r#2", "o/r#1", "o/s#1"}},
+		{"a known key keeps its place", []watcher.TrackedIssue{trackedIssue("o/r", 1, ""), trackedIssue("o/r", 2, ""), trackedIssue("o/r", 1, "again")}, []string{"o/r#1", "o/r#2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIssueStore()
+			for _, iss := range tt.add {
+				s.add(iss)
+			}
+			if got := storeKeys(s); !slices.Equal(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+			if s.len() != len(tt.want) {
+				t.Errorf("len = %d, want %d", s.len(), len(tt.want))
+			}
+		})
+	}
+
+	// Replacing an issue updates it in place, so pointers to it see the
+	// new fields rather than going stale.
+	s := newIssueStore()
+	p := s.add(trackedIssue("o/r", 1, "old"))
+	if q := s.add(trackedIssue("o/r", 1, "new")); q != p {
+		t.Errorf("replacing returned %p, want the stored %p", q, p)
+	}
+	if p.Title != "new" || s.get("o/r", 1) != p {
+		t.Errorf("after replace: %+v, get = %p, want %p", *p, s.get("o/r", 1), p)
+	}
+}
+
+func TestIssueStore_Remove(t *testing.T) {
+	tests := []struct {
+		name        string
+		repo        string
+		num         int
+		wantRemoved bool
+		want        []string
+	}{
+		{"first", "o/r", 1, true, []string{"o/r#2", "o/s#1"}},
+		{"middle", "o/r", 2, true, []string{"o/r#1", "o/s#1"}},
+		{"last", "o/s", 1, true, []string{"o/r#1", "o/r#2"}},
+		{"unknown", "o/r", 9, false, []string{"o/r#1", "o/r#2", "o/s#1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIssueStore()
+			for _, iss := range []watcher.TrackedIssue{trackedIssue("o/r", 1, ""), trackedIssue("o/r", 2, ""), trackedIssue("o/s", 1, "")} {
+				s.add(iss)
+			}
+			held := s.get(tt.repo, tt.num)
+			got := s.remove(tt.repo, tt.num)
+			if (got != nil) != tt.wantRemoved || got != held {
+				t.Errorf("remove = %p, want %p", got, held)
+			}
+			if s.get(tt.repo, tt.num) != nil {
+				t.Errorf("%s#%d still stored", tt.repo, tt.num)
+			}
+			if keys := storeKeys(s); !slices.Equal(keys, tt.want) {
+				t.Errorf("keys = %v, want %v", keys, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueStore_Rekey(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     string // moved o/r#1 to
+		num      int
+		want     []string
+		dropped  string // key of an issue the move replaced
+		unmoved  bool   // the store is left as it was
+		notFiled bool   // the issue passed isn't the one stored under the old key
+	}{
+		{name: "transferred", repo: "o/t", num: 5, want: []string{"o/t#5", "o/r#2", "o/s#1"}},
+		{name: "renumbered", repo: "o/r", num: 3, want: []string{"o/r#3", "o/r#2", "o/s#1"}},
+		{name: "same key", repo: "o/r", num: 1, want: []string{"o/r#1", "o/r#2", "o/s#1"}, unmoved: true},
+		{name: "onto a known issue", repo: "o/s", num: 1, want: []string{"o/s#1", "o/r#2"}, dropped: "o/s#1"},
+		{name: "not the stored issue", repo: "o/t", num: 5, want: []string{"o/r#1", "o/r#2", "o/s#1"}, unmoved: true, notFiled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIssueStore()
+			for _, iss := range []watcher.TrackedIssue{trackedIssue("o/r", 1, "moved"), trackedIssue("o/r", 2, ""), trackedIssue("o/s", 1, "existing")} {
+				s.add(iss)
+			}
+			existing := s.get("o/s", 1)
+			iss := s.get("o/r", 1)
+			if tt.notFiled {
+				copied := *iss
+				iss = &copied
+			}
+			iss.Repo, iss.Number = tt.repo, tt.num
+			s.rekey("o/r#1", iss)
+
+			if keys := storeKeys(s); !slices.Equal(keys, tt.want) {
+				t.Errorf("keys = %v, want %v", keys, tt.want)
+			}
+			if tt.unmoved {
+				return
+			}
+			if got := s.get(tt.repo, tt.num); got != iss {
+				t.Errorf("get(%s#%d) = %p, want the moved %p", tt.repo, tt.num, got, iss)
+			}
+			if s.get("o/r", 1) != nil {
+				t.Error("still stored under the old key")
+			}
+			if tt.dropped != "" {
+				for _, kept := range s.all() {
+					if kept == existing {
+						t.Errorf("%s the move replaced is still stored", tt.dropped)
+					}
+				}
+			}
+		})
+	}
+}
@@ -11,7 +11,17 @@ func helpLineNormal() string {
 		fmtHelp("enter", "focus") + sep +
 		fmtHelp("space", "start/pause") + sep +
 		fmtHelp("r", "add repo") + sep +
-		fmtHelp("a", "approve") + sep +
+		fmtHelp("a", "PR") + sep +
+		fmtHelp("/", "search") + sep +
+		fmtHelp("?", "help") + sep +
+		fmtHelp("q", "quit")
+}
+
+// helpLineCompact is the normal-mode footer for narrow terminals.
+func helpLineCompact() string {
+	sep := footerSepStyle.Render(" | ")
+	return fmtHelp("j/k", "nav") + sep +
+		fmtHelp("space", "run") + sep +
 		fmtHelp("?", "help") + sep +
 		fmtHelp("q", "quit")
 }
@@ -19,11 +29,14 @@ func helpLineNormal() string {
 func helpLineFocus() string {
 	sep := footerSepStyle.Render("  |  ")
 	return fmtHelp("j/k", "scroll") + sep +
+		fmtHelp("[/]", "tabs") + sep +
 		fmtHelp("G", "bottom") + sep +
+		fmtHelp("v", "log view") + sep +
+		fmtHelp("T", "times") + sep +
 		fmtHelp("space", "start/pause") + sep +
 		fmtHelp("t", "takeover") + sep +
 		fmtHelp("s", "shell") + sep +
-		fmtHelp("a", "approve") + sep +
+		fmtHelp("a", "PR") + sep +
 		fmtHelp("c", "claude") + sep +
 		fmtHelp("esc", "back")
 }
@@ -31,3 +44,31 @@ func helpLineFocus() string {
 func helpLineDialog() string {
 	return fmtHelp("esc", "close")
 }
+
+func helpLineReviews() string {
+	sep := footerSepStyle.Render("  |  ")
+	return fmtHelp("j/k", "navigate") + sep +
+		fmtHelp("d", "draft with claude") + sep +
+		fmtHelp("e", "edit") + sep +
+		fmtHelp("a", "submit") + sep +
+		fmtHelp("A", "approve") + sep +
+		fmtHelp("o", "open") + sep +
+		fmtHelp("r", "refresh") + sep +
+		fmtHelp("esc", "back")
+}
+
+func helpLineStats() string {
+	sep := footerSepStyle.Render("  |  ")
+	return fmtHelp("j/k", "scroll") + sep +
+		fmtHelp("[/]", "7/30/90 days") + sep +
+		fmtHelp("r", "reload") + sep +
+		fmtHelp("esc", "back")
+}
+
+func helpLineSearch() string {
+	sep := footerSepStyle.Render("  |  ")
+	return fmtHelp("j/k", "navigate") + sep +
+		fmtHelp("enter", "jump to issue") + sep +
+		fmtHelp("/", "new search") + sep +
+		fmtHelp("esc", "back")
+}
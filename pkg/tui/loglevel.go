@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+)
+
+// logKind is where a log line came from, for filtering the focus view.
+type logKind int
+
+const (
+	logStage  logKind = iota // lurker's own progress: cloning, checkpoints, PRs
+	logAgent                 // what Claude said
+	logTool                  // Claude's tool calls and commands run
+	logOutput                // output of commands, stderr included
+	logError                 // failures and warnings
+)
+
+// toolMarks start the lines formatStreamEvent and the shell write for tool
+// calls and commands.
+var toolMarks = []string{"$ ", "📖 ", "📝 ", "✏️", "🔍 ", "🔧 ", "🤖 Spawning"}
+
+// errorMarks start the lines that report something going wrong.
+var errorMarks = []string{"❌", "✗", "⚠", "Error", "PTY: "}
+
+// classifyLog guesses the kind of a log line from its text, for lines
+// appended without one and those read back from lurker.log. Indented
+// lines that aren't tool calls are taken to be output.
+func classifyLog(text string) logKind {
+	trimmed := strings.TrimLeft(text, " ")
+	for _, mark := range errorMarks {
+		if strings.HasPrefix(trimmed, mark) {
+			return logError
+		}
+	}
+	for _, mark := range toolMarks {
+		if strings.HasPrefix(trimmed, mark) {
+			return logTool
+		}
+	}
+	if trimmed != text {
+		return logOutput
+	}
+	return logStage
+}
+
+// logView selects which kinds of line the focus view's logs tab shows.
+type logView int
+
+const (
+	logViewAll    logView = iota
+	logViewQuiet          // without the tool-call ticker and command output
+	logViewErrors         // errors only
+	numLogViews
+)
+
+var logViewNames = [numLogViews]string{"all", "quiet", "errors"}
+
+// shows reports whether lines of kind k are shown.
+func (v logView) shows(k logKind) bool {
+	switch v {
+	case logViewQuiet:
+		return k != logTool && k != logOutput
+	case logViewErrors:
+		return k == logError
+	}
+	return true
+}
+
+// focusLogs returns the focused issue's log lines that its view shows.
+func (m *Model) focusLogs() []logLine {
+	lines := m.logs[issueKey(m.focusIssue.Repo, m.focusIssue.Number)]
+	if m.logView == logViewAll {
+		return lines
+	}
+	var shown []logLine
+	for _, line := range lines {
+		if m.logView.shows(line.kind) {
+			shown = append(shown, line)
+		}
+	}
+	return shown
+}
+
+// cycleLogView switches the logs tab to the next view, staying at the
+// bottom if it was tailing.
+func (m *Model) cycleLogView() {
+	tailing := m.atFocusBottom()
+	m.logView = (m.logView + 1) % numLogViews
+	if tailing {
+		m.focusScroll = 999999
+	}
+	m.clampFocusScroll()
+}
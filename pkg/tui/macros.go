@@ -0,0 +1,318 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// Macro is a named chain of actions bound to one key, e.g. "review": show
+// the diff, run the tests, then open the PR dialog.
+//
+// Steps are run in order on the selected (or focused) issue:
+//
+//	logs, body, diff, transcript, shell   open the focus view on that tab
+//	start                                 start, resume or retry the issue
+//	checkpoint                            checkpoint the issue's worktree
+//	rebase                                rebase the branch onto its base; stops the macro on conflicts
+//	squash                                squash the branch into one commit
+//	build, test                           run the repo's build/test command in the issue shell
+//	run:CMD                               run CMD in the issue shell
+//	open                                  open the issue in the browser
+//	pr                                    open the PR dialog (must be last)
+//	approve                               push and open the PR (must be last)
+//
+// Shell steps wait for the command and stop the macro if it fails.
+type Macro struct {
+	Name  string   `json:"name"`
+	Key   string   `json:"key"`
+	Steps []string `json:"steps"`
+}
+
+// reservedKeys are bound by lurker itself in the list or focus view.
+var reservedKeys = map[string]bool{
+	"j": true, "k": true, "l": true, "up": true, "down": true, "enter": true,
+	" ": true, "f": true, "s": true, "g": true, "c": true, "o": true,
+	"i": true, "a": true, "A": true, "r": true, "R": true, "d": true,
+	"u": true, "S": true, "T": true, "G": true, "t": true, "[": true,
+	"]": true, "?": true, "/": true, "z": true, "Z": true, "P": true, "D": true, "F": true, "B": true, "Q": true, "X": true, "H": true, "U": true, "V": true, "v": true, "q": true, "esc": true, "ctrl+c": true,
+}
+
+// finalSteps hand control to something else, so nothing may follow them.
+var finalSteps = map[string]bool{"pr": true, "approve": true}
+
+// validStep reports whether step is a known macro step.
+func validStep(step string) bool {
+	if cmd, ok := strings.CutPrefix(step, "run:"); ok {
+		return strings.TrimSpace(cmd) != ""
+	}
+	if _, ok := tabByName(step); ok {
+		return true
+	}
+	switch step {
+	case "start", "checkpoint", "rebase", "squash", "build", "test", "open", "pr", "approve":
+		return true
+	}
+	return false
+}
+
+func tabByName(name string) (focusTab, bool) {
+	for i, n := range tabNames {
+		if n == name {
+			return focusTab(i), true
+		}
+	}
+	return 0, false
+}
+
+// LoadMacros reads a macros file:
+//
+//	{"macros": [{"name": "review", "key": "W", "steps": ["diff", "test", "pr"]}]}
+//
+// A missing file means no macros.
+func LoadMacros(path string) ([]Macro, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Macros []Macro `json:"macros"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]string)
+	for _, mac := range file.Macros {
+		switch {
+		case mac.Name == "" || mac.Key == "":
+			return nil, fmt.Errorf("%s: every macro needs a name and a key", path)
+		case reservedKeys[mac.Key]:
+			return nil, fmt.Errorf("%s: macro %q: key %q is already bound", path, mac.Name, mac.Key)
+		case seen[mac.Key] != "":
+			return nil, fmt.Errorf("%s: macros %q and %q both use key %q", path, seen[mac.Key], mac.Name, mac.Key)
+		case len(mac.Steps) == 0:
+			return nil, fmt.Errorf("%s: macro %q has no steps", path, mac.Name)
+		}
+		seen[mac.Key] = mac.Name
+		for i, step := range mac.Steps {
+			if !validStep(step) {
+				return nil, fmt.Errorf("%s: macro %q: unknown step %q", path, mac.Name, step)
+			}
+			if finalSteps[step] && i != len(mac.Steps)-1 {
+				return nil, fmt.Errorf("%s: macro %q: %q must be the last step", path, mac.Name, step)
+			}
+		}
+	}
+	return file.Macros, nil
+}
+
+// macroRun tracks a macro in progress.
+type macroRun struct {
+	macro Macro
+	repo  string
+	num   int
+	next  int // index of the next step
+}
+
+// macroStepMsg reports a finished shell step.
+type macroStepMsg struct {
+	step   string
+	code   int
+	output string // what the step printed, if it failed
+	err    error
+}
+
+// macroStepTimeout is how long a macro's shell step may run.
+const macroStepTimeout = 30 * time.Minute
+
+// macroOutputLines is how much of a failed step's output is logged.
+const macroOutputLines = 5
+
+// macroFor returns the macro bound to key, if any.
+func (m *Model) macroFor(key string) (Macro, bool) {
+	for _, mac := range m.macros {
+		if mac.Key == key {
+			return mac, true
+		}
+	}
+	return Macro{}, false
+}
+
+// runMacro starts mac on iss.
+func (m *Model) runMacro(mac Macro, iss *watcher.TrackedIssue) tea.Cmd {
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	if m.macroRun != nil {
+		m.appendLog(key, fmt.Sprintf("⏳ Macro %s is still running", m.macroRun.macro.Name))
+		return nil
+	}
+	m.macroRun = &macroRun{macro: mac, repo: iss.Repo, num: iss.Number}
+	m.appendLog(key, fmt.Sprintf("▶ Macro %s: %s", mac.Name, strings.Join(mac.Steps, " → ")))
+	return m.advanceMacro()
+}
+
+// advanceMacro runs steps until one has to be waited for or the macro ends.
+func (m *Model) advanceMacro() tea.Cmd {
+	run := m.macroRun
+	if run == nil {
+		return nil
+	}
+	key := issueKey(run.repo, run.num)
+	iss := m.findIssue(run.repo, run.num)
+	if iss == nil {
+		m.macroRun = nil
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for run.next < len(run.macro.Steps) {
+		step := run.macro.Steps[run.next]
+		run.next++
+		cmd, wait, err := m.macroStep(step, iss)
+		if err != nil {
+			m.appendLog(key, fmt.Sprintf("✗ Macro %s stopped at %s: %v", run.macro.Name, step, err))
+			m.macroRun = nil
+			return tea.Batch(cmds...)
+		}
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if wait {
+			return tea.Batch(cmds...)
+		}
+	}
+	m.appendLog(key, fmt.Sprintf("✓ Macro %s done", run.macro.Name))
+	m.macroRun = nil
+	return tea.Batch(cmds...)
+}
+
+// macroStep performs one step. wait is true when cmd delivers a
+// macroStepMsg that continues the macro.
+func (m *Model) macroStep(step string, iss *watcher.TrackedIssue) (cmd tea.Cmd, wait bool, err error) {
+	if tab, ok := tabByName(step); ok {
+		if m.focus != focusFocus || m.focusIssue != iss {
+			m.enterFocus(iss)
+		}
+		m.focusTab = tab
+		m.focusScroll = 0
+		if tab == tabLogs || tab == tabShell {
+			m.focusScroll = 999999
+		}
+		return m.loadFocusTab(), false, nil
+	}
+
+	switch step {
+	case "start":
+		switch iss.Status {
+		case watcher.StatusPending:
+			m.startIssue(iss, "▶ Started")
+		case watcher.StatusPaused:
+			m.startIssue(iss, "▶ Resumed")
+		case watcher.StatusFailed, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+			m.startIssue(iss, "▶ Retrying")
+		}
+		return nil, false, nil
+	case "checkpoint":
+		if iss.Workdir == "" {
+			return nil, false, errors.New("no worktree yet")
+		}
+		c, err := m.manager.Checkpoint(iss.Repo, iss.Number, "macro "+m.macroRun.macro.Name)
+		if err != nil {
+			return nil, false, err
+		}
+		m.appendLog(issueKey(iss.Repo, iss.Number), fmt.Sprintf("📸 Checkpoint %s (%s)", c.ID, c.Label))
+		return nil, false, nil
+	case "rebase":
+		if iss.Workdir == "" {
+			return nil, false, errors.New("no worktree yet")
+		}
+		if isActive(iss.Status) {
+			return nil, false, errors.New("the issue is running")
+		}
+		return m.updateBranchFor(iss, true), true, nil
+	case "squash":
+		if iss.Workdir == "" {
+			return nil, false, errors.New("no worktree yet")
+		}
+		if isActive(iss.Status) {
+			return nil, false, errors.New("the issue is running")
+		}
+		return m.squashFor(iss, true), true, nil
+	case "open":
+		if iss.URL != "" {
+			openURL(iss.URL)
+		}
+		return nil, false, nil
+	case "pr":
+		return m.openPRDialog(iss), false, nil
+	case "approve":
+		return m.approvePRFor(iss, false), false, nil
+	}
+
+	// Everything else runs in the issue's shell.
+	if iss.Workdir == "" {
+		return nil, false, errors.New("no worktree yet")
+	}
+	switch iss.Status {
+	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusQueued, watcher.StatusClaudeRunning:
+		return nil, false, errors.New("the issue's shell is busy")
+	}
+	command, _ := strings.CutPrefix(step, "run:")
+	switch step {
+	case "build":
+		command = m.manager.RepoConfig(iss.Workdir).Build()
+	case "test":
+		command = m.manager.RepoConfig(iss.Workdir).Test()
+	}
+
+	key := issueKey(iss.Repo, iss.Number)
+	session := m.ensurePtySession(key, iss.Workdir)
+	if session == nil {
+		return nil, false, errors.New("no shell session")
+	}
+	m.appendLog(key, "$ "+command)
+	full := fmt.Sprintf("cd %s && %s", watcher.ShellQuote(iss.Workdir), command)
+	return func() tea.Msg {
+		res, err := session.run(context.Background(), full, macroStepTimeout)
+		return macroStepMsg{step: step, code: res.Code, output: res.Output, err: err}
+	}, true, nil
+}
+
+// handleMacroStep continues or stops the running macro after a shell step.
+func (m *Model) handleMacroStep(msg macroStepMsg) tea.Cmd {
+	run := m.macroRun
+	if run == nil {
+		return nil
+	}
+	key := issueKey(run.repo, run.num)
+	switch {
+	case msg.err != nil:
+		m.appendLog(key, fmt.Sprintf("✗ Macro %s stopped at %s: %v", run.macro.Name, msg.step, msg.err))
+	case msg.code != 0:
+		m.appendLog(key, fmt.Sprintf("✗ Macro %s stopped: %s exited with code %d", run.macro.Name, msg.step, msg.code))
+	default:
+		return m.advanceMacro()
+	}
+	lines := strings.Split(strings.TrimRight(msg.output, "\n"), "\n")
+	for _, line := range lines[max(0, len(lines)-macroOutputLines):] {
+		if line != "" {
+			m.appendLog(key, "  "+line)
+		}
+	}
+	m.macroRun = nil
+	return nil
+}
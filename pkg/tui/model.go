@@ -7,14 +7,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/stefanpenner/lurker/pkg/api"
 	"github.com/stefanpenner/lurker/pkg/github"
 	"github.com/stefanpenner/lurker/pkg/watcher"
 )
@@ -35,6 +37,18 @@ const (
 	focusFocus         // full-screen focus view of a single issue
 	focusHelp          // help screen overlay
 	focusConfirm       // confirmation dialog (e.g. remove repo)
+	focusAway          // "welcome back" summary after idle auto-pause
+	focusPR            // PR creation dialog
+	focusSearch        // global log/transcript search results
+	focusRestore       // picking a checkpoint to restore
+	focusReviews       // PRs awaiting the user's review
+	focusApprove       // allowing or denying the tools Claude was refused
+	focusChecks        // preflight checklist before A/D push
+	focusPRExist       // offering to update a PR that already existed
+	focusForce         // confirming a force-push over a rejected push
+	focusDelete        // confirming deletion of an issue's remote branch
+	focusAbandon       // confirming handing an issue back
+	focusStats         // local usage stats
 )
 
 // itemKind distinguishes tree items.
@@ -47,9 +61,9 @@ const (
 
 // listItem is one selectable row in the tree.
 type listItem struct {
-	kind     itemKind
-	repo     string
-	issueIdx int // index into Model.issues; -1 for repo items
+	kind  itemKind
+	repo  string
+	issue *watcher.TrackedIssue // nil for repo items
 }
 
 func issueKey(repo string, num int) string {
@@ -58,11 +72,12 @@ func issueKey(repo string, num int) string {
 
 // Model is the Bubbletea model for the TUI dashboard.
 type Model struct {
-	issues       []watcher.TrackedIssue
-	logs         map[string][]string // per-issue log lines, keyed by "owner/repo#42"
-	expanded     map[string]bool     // which issues have logs toggled open
-	repoExpanded map[string]bool     // which repo folders are open
-	repoErrors   map[string]string   // latest poll error per repo
+	issues       *issueStore
+	logs         map[string][]logLine // per-issue log lines, keyed by "owner/repo#42"
+	expanded     map[string]bool      // which issues have logs toggled open
+	repoExpanded map[string]bool      // which repo folders are open
+	repoErrors   map[string]string    // latest poll error per repo
+	repoMoves    map[string]string    // repos GitHub reports as renamed: old → new
 
 	cursor    int   // index into visibleItems()
 	focus     focus // current focus mode
@@ -78,77 +93,326 @@ type Model struct {
 	manager   *watcher.Manager
 	eventCh   <-chan watcher.Event
 
+	// addRepo is the repo whose base branch is being asked for.
+	addRepo string
+	// replyTo is the issue whose questions are being answered, and
+	// replyFrom the view to return to afterwards.
+	replyTo   string
+	replyFrom focus
+	// approving is the issue whose tool requests are being answered,
+	// with its requests; it also returns to replyFrom.
+	approving      string
+	approvingRules []string
+	// search is the global log/transcript search (/).
+	search searchState
+
+	// reviews lists the PRs awaiting the user's review (V).
+	reviews reviewsState
+	stats   statsState
+
+	// checkpoints of checkpointIssue, listed for restoring.
+	checkpointIssue  string
+	checkpoints      []watcher.Checkpoint
+	checkpointCursor int
+	checkpointFrom   focus
+
 	// Dialog state
-	dialogIssue *watcher.TrackedIssue
-	confirmRepo string // repo pending removal confirmation
+	dialogIssue      *watcher.TrackedIssue
+	dialogRecordings []watcher.Recording
+	dialogTools      watcher.ToolStats
+	dialogResult     watcher.RunResult
+	confirmRepo      string // repo pending removal (or rename) confirmation
+	confirmRename    string // new name when confirmRepo is pending a rename
 
 	// Focus view state
 	focusIssue  *watcher.TrackedIssue
 	focusScroll int
+	focusTab    focusTab
+	tabLines    []string // content of the current non-log tab
+	tabSeek     string   // line to scroll to once the tab loads
+	logView     logView  // which log lines the logs tab shows
 
 	// GitHub API client
 	ghClient *github.Client
 
 	// Persistent shell sessions (PTY per issue)
-	ptySessions map[string]*ptySession
+	ptys          *ptyPool
+	attachProfile bool // attach to a second shell with the user's profile
+	logColors     bool // show log lines in the colors their tools gave them
 
 	// Counters
 	lastPoll  time.Time
 	pollCount int
 	now       time.Time
+
+	// columns is the set of issue-row columns the user enabled.
+	columns map[column]bool
+
+	notifier notifier
+
+	// Auto-start and idle tracking
+	autoStart   bool
+	seededRepos map[string]bool // repos whose first poll has completed
+	replayed    map[string]bool // issues shown from the event journal, not yet re-polled
+	away        awayState
+
+	// relativeTimes renders timestamps as "3m ago" instead of local clock time.
+	relativeTimes bool
+
+	// teamStatus is the result of the last team config sync, if any.
+	teamStatus string
+	// discoverStatus is the latest news from org repo discovery, if any.
+	discoverStatus string
+
+	// Claims on failed/paused issues are released after claimTTL (0 = never).
+	claimTTL time.Duration
+	claims   map[string]claimState
+
+	// claudeSince is when each issue's current Claude run started, and
+	// budget what the focused one has used so far.
+	claudeSince map[string]time.Time
+	budget      runBudget
+
+	prDialog  *prDialog
+	preflight *preflightDialog
+
+	// prExists is the PR that focusPRExist offers to update.
+	prExists     *prResultMsg
+	prExistsFrom focus
+
+	// forcePush is the rejected push focusForce offers to force.
+	forcePush     *prResultMsg
+	forcePushFrom focus
+
+	// delBranch is the issue whose remote branch focusDelete offers to
+	// delete.
+	delBranch     *watcher.TrackedIssue
+	delBranchFrom focus
+
+	// abandoning is the issue focusAbandon offers to hand back, with
+	// abandonOpts as its toggles stand.
+	abandoning  *watcher.TrackedIssue
+	abandonOpts watcher.AbandonOptions
+	abandonFrom focus
+
+	// logHub streams appended log lines to API clients.
+	logHub *api.Hub
+
+	macros   []Macro
+	macroRun *macroRun // macro waiting on a shell step, if any
+
+	// cleanupStatus reports background deletion of a removed repo's files.
+	cleanupStatus string
+}
+
+// logLine is one timestamped entry in an issue's log.
+type logLine struct {
+	at    time.Time // zero for lines persisted before timestamps existed
+	text  string    // plain, without escape sequences
+	color string    // text in the colors it came with, if any and LogColors
+	kind  logKind
 }
 
 // Messages
-type eventMsg watcher.Event
+// eventsMsg is a batch of watcher events, oldest first.
+type eventsMsg []watcher.Event
 type tickMsg struct{}
 
 type prResultMsg struct {
 	repo     string
 	issueNum int
+	prNum    int
 	url      string
+	warnings []string // follow-up steps (reviewers, labels) that failed
 	err      error
+
+	// existed is set when the branch already had PR prNum open; the
+	// branch was pushed to it. From the PR dialog, title and body are
+	// offered as its new ones.
+	existed     bool
+	title, body string
+
+	// rejected is set when the push was rejected as non-fast-forward;
+	// spec, pushed from workdir, is submitted again after a force-push.
+	rejected *watcher.PushRejectedError
+	workdir  string
+	spec     watcher.PRSpec
+}
+
+// Options configures optional TUI behaviour.
+type Options struct {
+	// Columns lists the issue-row columns to show, by name
+	// (status, beads, number, title, pr, cost, elapsed, progress, logs).
+	// Empty means DefaultColumns.
+	Columns []string
+
+	// Bell rings the terminal bell when an issue becomes ready or fails.
+	Bell bool
+	// OSC9 sends an OSC 9 desktop notification for the same transitions.
+	OSC9 bool
+	// TmuxStatus publishes ready/failed counts in the tmux @lurker option.
+	TmuxStatus bool
+
+	// AutoStart starts issues that appear while lurker is running without
+	// waiting for space. Issues already open at startup are never
+	// auto-started.
+	AutoStart bool
+	// IdlePause suspends AutoStart after this long without keyboard input
+	// and summarizes what accumulated on return. Zero disables it.
+	IdlePause time.Duration
+
+	// ClaimTTL releases lurker's 👀 claim on issues that have been failed
+	// or paused this long. Zero keeps claims forever.
+	ClaimTTL time.Duration
+
+	// Macros are extra key bindings that chain actions; see LoadMacros.
+	Macros []Macro
+
+	// ShellIdle closes an issue's shell once it has gone unused this long
+	// while the issue isn't being worked on; it is started again when
+	// next needed. Zero keeps shells open.
+	ShellIdle time.Duration
+	// MaxShells caps how many issue shells are open at once; starting
+	// another closes the least recently used idle one. Zero is no limit.
+	MaxShells int
+	// ShellBackend is what backs issue shells; one of ShellBackends.
+	// With "tmux" or "screen" they survive lurker restarts. Empty means
+	// "pty".
+	ShellBackend string
+	// AttachProfile gives the shells the user attaches to (s, c, t) their
+	// own shell profile. They are then separate from the shell lurker runs
+	// commands in, which always starts from lurker's rc so that prompt
+	// themes and aliases can't break them.
+	AttachProfile bool
+	// LogColors shows log lines from tools that color their output, such
+	// as test runners, in those colors. Either way lurker.log and the API
+	// get plain text.
+	LogColors bool
 }
 
 // NewModel creates a new TUI Model.
-func NewModel(manager *watcher.Manager, ghClient *github.Client) Model {
+func NewModel(manager *watcher.Manager, ghClient *github.Client, opts Options) (Model, error) {
 	s := spinner.New()
 	s.Spinner = spinner.MiniDot
 
 	ti := textinput.New()
-	ti.Placeholder = "owner/repo"
+	ti.Placeholder = "owner/repo or owner/*"
 	ti.CharLimit = 100
 	ti.Width = 40
 
-	return Model{
-		logs:         make(map[string][]string),
-		expanded:     make(map[string]bool),
-		repoExpanded: make(map[string]bool),
-		repoErrors:   make(map[string]string),
-		spinner:      s,
-		textInput:    ti,
-		manager:      manager,
-		ghClient:     ghClient,
-		eventCh:      manager.EventCh(),
-		ptySessions:  make(map[string]*ptySession),
-		now:          time.Now(),
+	if len(opts.Columns) == 0 {
+		opts.Columns = DefaultColumns
+	}
+	columns, err := parseColumns(opts.Columns)
+	if err != nil {
+		return Model{}, err
+	}
+	backend, err := parseShellBackend(opts.ShellBackend)
+	if err != nil {
+		return Model{}, err
 	}
+	rcDir, err := writeShellRC(manager.BaseDir(), manager.Layout().Config)
+	if err != nil {
+		return Model{}, fmt.Errorf("writing shell rc: %w", err)
+	}
+
+	m := Model{
+		issues:        newIssueStore(),
+		logs:          make(map[string][]logLine),
+		expanded:      make(map[string]bool),
+		repoExpanded:  make(map[string]bool),
+		repoErrors:    make(map[string]string),
+		repoMoves:     make(map[string]string),
+		spinner:       s,
+		textInput:     ti,
+		manager:       manager,
+		ghClient:      ghClient,
+		eventCh:       manager.EventCh(),
+		ptys:          newPtyPool(backend, opts.ShellIdle, opts.MaxShells, rcDir, manager.Bwrap(), filepath.Join(manager.Layout().Config, shellRCName)),
+		attachProfile: opts.AttachProfile,
+		logColors:     opts.LogColors,
+		columns:       columns,
+		notifier: notifier{
+			bell: opts.Bell,
+			osc9: opts.OSC9,
+			out:  &terminal{File: os.Stdout},
+		},
+		autoStart:   opts.AutoStart,
+		seededRepos: make(map[string]bool),
+		replayed:    make(map[string]bool),
+		claimTTL:    opts.ClaimTTL,
+		claims:      make(map[string]claimState),
+		claudeSince: make(map[string]time.Time),
+		logHub:      &api.Hub{},
+		macros:      opts.Macros,
+		away:        awayState{after: opts.IdlePause, lastInput: time.Now()},
+		now:         time.Now(),
+	}
+	if opts.TmuxStatus {
+		m.notifier.tmux = newTmuxStatus()
+	}
+	// Show the issues the last run knew about until the first polls land
+	for _, ev := range manager.Replay() {
+		m.handleEvent(ev)
+		m.replayed[issueKey(ev.Repo, ev.IssueNum)] = true
+	}
+	return m, nil
 }
 
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.spinner.Tick, m.pollEvents())
 }
 
+// Claude's output is coalesced for up to eventBatchWindow, so a chatty
+// run costs one render per window rather than one per line.
+const (
+	eventBatchWindow = 100 * time.Millisecond
+	maxEventBatch    = 1000
+)
+
 func (m Model) pollEvents() tea.Cmd {
 	return func() tea.Msg {
+		var batch eventsMsg
 		select {
 		case ev, ok := <-m.eventCh:
 			if !ok {
 				return nil
 			}
-			return eventMsg(ev)
+			batch = append(batch, ev)
 		case <-time.After(100 * time.Millisecond):
 			return tickMsg{}
 		}
+
+		// Take whatever else is queued; wait out the window for more
+		// output if this is Claude talking.
+		var window <-chan time.Time
+		if batch[0].Kind == watcher.EventClaudeLog {
+			window = time.After(eventBatchWindow)
+		}
+		for len(batch) < maxEventBatch {
+			select {
+			case ev, ok := <-m.eventCh:
+				if !ok {
+					return batch
+				}
+				batch = append(batch, ev)
+				continue
+			default:
+			}
+			if window == nil {
+				break
+			}
+			select {
+			case ev, ok := <-m.eventCh:
+				if !ok {
+					return batch
+				}
+				batch = append(batch, ev)
+			case <-window:
+				return batch
+			}
+		}
+		return batch
 	}
 }
 
@@ -176,21 +440,113 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
-	case eventMsg:
+	case eventsMsg:
 		m.now = time.Now()
-		m.handleEvent(watcher.Event(msg))
-		m.refreshFocusIssue()
+		m.checkIdle()
+		m.reapPtySessions()
+		for _, ev := range msg {
+			m.handleEvent(ev)
+			if ev.Kind == watcher.EventCommand {
+				cmds = append(cmds, m.commentCommand(ev))
+			}
+		}
 		cmds = append(cmds, m.pollEvents())
 
 	case tickMsg:
 		m.now = time.Now()
-		cmds = append(cmds, m.pollEvents())
+		m.checkIdle()
+		m.reapPtySessions()
+		cmds = append(cmds, m.pollEvents(), m.checkClaims(), m.refreshBudget())
+
+	case claimReleasedMsg:
+		m.handleClaimReleased(msg)
+
+	case budgetMsg:
+		m.handleBudget(msg)
+
+	case prEditedMsg:
+		m.handlePREdited(msg)
 
 	case prResultMsg:
 		m.handlePRResult(msg)
 
 	case interactiveClaudeDoneMsg:
 		m.handleInteractiveReturn(msg)
+
+	case detachedMsg:
+		cmds = append(cmds, checkDetached(msg))
+
+	case detachSummaryMsg:
+		m.logDetach(msg)
+		if msg.then != nil {
+			then := msg.then
+			cmds = append(cmds, func() tea.Msg { return then })
+		}
+
+	case tabContentMsg:
+		m.handleTabContent(msg)
+
+	case prDraftMsg:
+		m.handlePRDraft(msg)
+
+	case defaultBranchMsg:
+		m.handleDefaultBranch(msg)
+
+	case apiCallMsg:
+		msg.run(&m)
+
+	case macroStepMsg:
+		if cmd := m.handleMacroStep(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case searchResultMsg:
+		m.handleSearchResult(msg)
+
+	case preflightMsg:
+		m.handlePreflight(msg)
+
+	case branchDeletedMsg:
+		m.handleBranchDeleted(msg)
+
+	case abandonedMsg:
+		m.handleAbandoned(msg)
+
+	case checkpointMsg:
+		if cmd := m.handleCheckpoint(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case branchUpdateMsg:
+		if cmd := m.handleBranchUpdate(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case squashMsg:
+		if cmd := m.handleSquash(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case reviewRequestsMsg:
+		m.handleReviewRequests(msg)
+
+	case reviewDraftedMsg:
+		m.handleReviewDrafted(msg)
+
+	case reviewEditedMsg:
+		m.handleReviewEdited(msg)
+
+	case reviewSubmittedMsg:
+		m.handleReviewSubmitted(msg)
+	}
+
+	// Keep the PR dialog's inputs blinking; keys are routed by handleKey.
+	if m.focus == focusPR && prevFocus == focusPR {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			if cmd := m.prDialog.update(msg); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
 	}
 
 	// Forward messages to textinput when focused, but skip the keypress
@@ -217,12 +573,10 @@ func (m *Model) visibleItems() []listItem {
 	var items []listItem
 	repos := m.manager.Repos()
 	for _, repo := range repos {
-		items = append(items, listItem{kind: itemRepo, repo: repo, issueIdx: -1})
+		items = append(items, listItem{kind: itemRepo, repo: repo})
 		if m.repoExpanded[repo] {
-			for i, iss := range m.issues {
-				if iss.Repo == repo {
-					items = append(items, listItem{kind: itemIssue, repo: repo, issueIdx: i})
-				}
+			for _, iss := range m.issues.inRepo(repo) {
+				items = append(items, listItem{kind: itemIssue, repo: repo, issue: iss})
 			}
 		}
 	}
@@ -243,10 +597,7 @@ func (m *Model) selectedIssue() *watcher.TrackedIssue {
 	if item == nil || item.kind != itemIssue {
 		return nil
 	}
-	if item.issueIdx >= 0 && item.issueIdx < len(m.issues) {
-		return &m.issues[item.issueIdx]
-	}
-	return nil
+	return item.issue
 }
 
 func (m *Model) selectedRepo() string {
@@ -262,24 +613,101 @@ func (m *Model) selectedRepo() string {
 func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	key := msg.String()
 
+	if m.noteInput(msg) {
+		return nil
+	}
+
+	// Away summary
+	if m.focus == focusAway {
+		switch key {
+		case "ctrl+c":
+			return tea.Quit
+		case "S":
+			m.startAllStopped()
+			m.away.pending = nil
+			m.focus = focusList
+		case "esc", "enter", "q":
+			return m.closeAway()
+		}
+		return nil
+	}
+
+	if m.focus == focusPR {
+		return m.handlePRDialogKey(msg)
+	}
+
+	if m.focus == focusChecks {
+		return m.handlePreflightKey(key)
+	}
+
+	if m.focus == focusPRExist {
+		return m.handlePRExistsKey(key)
+	}
+
+	if m.focus == focusForce {
+		return m.handleForcePushKey(key)
+	}
+
+	if m.focus == focusDelete {
+		return m.handleDeleteBranchKey(key)
+	}
+
+	if m.focus == focusAbandon {
+		return m.handleAbandonKey(key)
+	}
+
+	if m.focus == focusApprove {
+		switch key {
+		case "ctrl+c":
+			return tea.Quit
+		case "y":
+			m.submitApproval(watcher.ApprovalOnce)
+		case "a":
+			m.submitApproval(watcher.ApprovalAlways)
+		case "n":
+			m.submitApproval(watcher.ApprovalDeny)
+		case "esc":
+			m.closeApproval()
+		}
+		return nil
+	}
+
 	// Text input mode
 	if m.focus == focusInput {
 		switch key {
 		case "ctrl+c":
 			return tea.Quit
 		case "enter":
-			repo := strings.TrimSpace(m.textInput.Value())
-			if repo != "" {
-				m.manager.AddRepo(repo)
-				m.repoExpanded[repo] = true
+			value := strings.TrimSpace(m.textInput.Value())
+			if m.replyTo != "" {
+				m.submitReply(value)
+				return nil
 			}
-			m.textInput.Reset()
-			m.textInput.Blur()
-			m.focus = focusList
+			if m.search.typing {
+				return m.submitSearch(value)
+			}
+			if m.addRepo == "" {
+				if value == "" {
+					m.closeRepoInput()
+					return nil
+				}
+				if owner, topic, remove, ok := parseOrgPattern(value); ok {
+					m.addOrg(owner, topic, remove)
+					m.closeRepoInput()
+					return nil
+				}
+				return m.askBaseBranch(value)
+			}
+			m.addRepoWithBase(m.addRepo, value)
+			m.closeRepoInput()
 		case "esc":
-			m.textInput.Reset()
-			m.textInput.Blur()
-			m.focus = focusList
+			if m.replyTo != "" {
+				m.closeReply()
+			} else if m.search.typing {
+				m.cancelSearch()
+			} else {
+				m.closeRepoInput()
+			}
 		}
 		return nil
 	}
@@ -288,15 +716,41 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	if m.focus == focusConfirm {
 		switch key {
 		case "y", "enter":
-			m.removeSelectedRepoConfirmed()
+			if m.confirmRename != "" {
+				m.renameRepoConfirmed()
+			} else {
+				m.removeSelectedRepoConfirmed(false)
+			}
 			m.focus = focusList
+		case "D":
+			if m.confirmRename == "" {
+				m.removeSelectedRepoConfirmed(true)
+				m.focus = focusList
+			}
 		case "n", "esc":
 			m.confirmRepo = ""
+			m.confirmRename = ""
 			m.focus = focusList
 		}
 		return nil
 	}
 
+	if m.focus == focusSearch {
+		return m.handleSearchKey(key)
+	}
+
+	if m.focus == focusRestore {
+		return m.handleCheckpointsKey(key)
+	}
+
+	if m.focus == focusReviews {
+		return m.handleReviewsKey(key)
+	}
+
+	if m.focus == focusStats {
+		return m.handleStatsKey(key)
+	}
+
 	// Dialog mode (info or help)
 	if m.focus == focusDialog || m.focus == focusHelp {
 		if key == "esc" || key == "?" {
@@ -313,6 +767,10 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			return tea.Quit
 		case "esc":
 			m.focus = focusList
+			if m.search.jumped {
+				m.search.jumped = false
+				m.focus = focusSearch
+			}
 			m.focusIssue = nil
 		case "j", "down":
 			m.focusScroll++
@@ -324,14 +782,32 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 		case "G":
 			m.focusScroll = 999999
 			m.clampFocusScroll()
+		case "]":
+			return m.switchFocusTab(1)
+		case "[":
+			return m.switchFocusTab(-1)
+		case "r":
+			return m.loadFocusTab()
+		case "T":
+			m.relativeTimes = !m.relativeTimes
 		case " ":
-			m.toggleFocusIssueProcessing()
+			return m.toggleFocusIssueProcessing()
 		case "o":
 			if m.focusIssue != nil && m.focusIssue.URL != "" {
-				exec.Command("open", m.focusIssue.URL).Start()
+				openURL(m.focusIssue.URL)
 			}
 		case "a":
-			return m.approvePRFor(m.focusIssue)
+			return m.openPRDialog(m.focusIssue)
+		case "A":
+			return m.preflightFor(m.focusIssue, false)
+		case "D":
+			return m.preflightFor(m.focusIssue, true)
+		case "F":
+			return m.addressFeedbackFor(m.focusIssue)
+		case "B":
+			return m.updateBranchFor(m.focusIssue, false)
+		case "Q":
+			return m.squashFor(m.focusIssue, false)
 		case "g":
 			return m.launchLazygitFor(m.focusIssue)
 		case "c":
@@ -340,6 +816,18 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			return m.takeoverClaudeFor(m.focusIssue)
 		case "s":
 			return m.launchShellFor(m.focusIssue)
+		case "z":
+			return m.checkpointFor(m.focusIssue, "manual")
+		case "Z":
+			m.openCheckpoints(m.focusIssue)
+		case "v":
+			if m.focusTab == tabLogs {
+				m.cycleLogView()
+			}
+		default:
+			if mac, ok := m.macroFor(key); ok {
+				return m.runMacro(mac, m.focusIssue)
+			}
 		}
 		return nil
 	}
@@ -370,10 +858,7 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 		if item.kind == itemRepo {
 			m.repoExpanded[item.repo] = !m.repoExpanded[item.repo]
 		} else if iss := m.selectedIssue(); iss != nil {
-			m.focusIssue = iss
-			m.focusScroll = 999999
-			m.clampFocusScroll()
-			m.focus = focusFocus
+			m.enterFocus(iss)
 		}
 	case " ":
 		item := m.cursorItem()
@@ -383,14 +868,11 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 		if item.kind == itemRepo {
 			m.repoExpanded[item.repo] = !m.repoExpanded[item.repo]
 		} else {
-			m.toggleIssueProcessing()
+			return m.toggleIssueProcessing()
 		}
 	case "f":
 		if iss := m.selectedIssue(); iss != nil {
-			m.focusIssue = iss
-			m.focusScroll = 999999
-			m.clampFocusScroll()
-			m.focus = focusFocus
+			m.enterFocus(iss)
 		}
 	case "s":
 		return m.launchShellFor(m.selectedIssue())
@@ -403,7 +885,21 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	case "i":
 		m.showDialog()
 	case "a":
-		return m.approvePRFor(m.selectedIssue())
+		return m.openPRDialog(m.selectedIssue())
+	case "A":
+		return m.preflightFor(m.selectedIssue(), false)
+	case "D":
+		return m.preflightFor(m.selectedIssue(), true)
+	case "F":
+		return m.addressFeedbackFor(m.selectedIssue())
+	case "B":
+		return m.updateBranchFor(m.selectedIssue(), false)
+	case "Q":
+		return m.squashFor(m.selectedIssue(), false)
+	case "X":
+		m.deleteBranchFor(m.selectedIssue())
+	case "H":
+		m.abandonFor(m.selectedIssue())
 	case "r":
 		m.focus = focusInput
 		return m.textInput.Focus()
@@ -412,27 +908,150 @@ func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			m.confirmRepo = repo
 			m.focus = focusConfirm
 		}
+	case "P":
+		if item := m.cursorItem(); item != nil && item.kind == itemRepo {
+			m.manager.SetPatchMode(item.repo, !m.manager.PatchMode(item.repo))
+		}
+	case "u":
+		if repo := m.selectedRepo(); repo != "" && m.repoMoves[repo] != "" {
+			m.confirmRepo = repo
+			m.confirmRename = m.repoMoves[repo]
+			m.focus = focusConfirm
+		}
 	case "S":
 		m.startAllStopped()
+	case "z":
+		return m.checkpointFor(m.selectedIssue(), "manual")
+	case "Z":
+		m.openCheckpoints(m.selectedIssue())
+	case "T":
+		m.relativeTimes = !m.relativeTimes
+	case "/":
+		return m.openSearch()
+	case "V":
+		return m.openReviews()
+	case "U":
+		m.openStats()
 	case "?":
 		m.focus = focusHelp
+	default:
+		if mac, ok := m.macroFor(key); ok {
+			return m.runMacro(mac, m.selectedIssue())
+		}
 	}
 
 	return nil
 }
 
-func (m *Model) ensurePtySession(key string, workdir string) {
-	if s := m.ptySessions[key]; s != nil && !s.isDone() {
+// defaultBranchMsg carries a repo's default branch for the add-repo flow.
+type defaultBranchMsg struct {
+	repo   string
+	branch string
+	err    error
+}
+
+// askBaseBranch moves the add-repo input on to the base branch, filled in
+// with the repo's default branch once GitHub reports it.
+func (m *Model) askBaseBranch(repo string) tea.Cmd {
+	m.addRepo = repo
+	m.textInput.Reset()
+	m.textInput.Placeholder = "detecting default branch…"
+	forge := m.manager.Forge()
+	return func() tea.Msg {
+		r, err := forge.GetRepo(context.Background(), repo)
+		return defaultBranchMsg{repo: repo, branch: r.DefaultBranch, err: err}
+	}
+}
+
+func (m *Model) handleDefaultBranch(msg defaultBranchMsg) {
+	if m.focus != focusInput || m.addRepo != msg.repo {
 		return
 	}
-	session, err := newPtySession(workdir)
+	m.textInput.Placeholder = "base branch"
+	if msg.err != nil {
+		msg.branch = "main"
+	}
+	if m.textInput.Value() == "" && msg.branch != "" {
+		m.textInput.SetValue(msg.branch)
+		m.textInput.CursorEnd()
+	}
+}
+
+// addRepoWithBase starts watching repo with base as its base branch.
+func (m *Model) addRepoWithBase(repo, base string) {
+	if err := m.manager.AddRepo(repo); err != nil {
+		m.repoErrors[repo] = err.Error()
+		return
+	}
+	if err := m.manager.SetBaseBranch(repo, base); err != nil {
+		m.repoErrors[repo] = err.Error()
+	}
+	m.repoExpanded[repo] = true
+}
+
+func (m *Model) closeRepoInput() {
+	m.addRepo = ""
+	m.textInput.Reset()
+	m.textInput.Placeholder = "owner/repo or owner/*"
+	m.textInput.Blur()
+	m.focus = focusList
+}
+
+// ensurePtySession returns key's shell, starting one in workdir if it has
+// none (never had, reaped, or exited), or nil if it can't.
+func (m *Model) ensurePtySession(key string, workdir string) *ptySession {
+	return m.openPtySession(key, workdir, false)
+}
+
+// attachPtySession returns the shell the user attaches to for key: the
+// issue's own, or with AttachProfile a second one started with the user's
+// profile, which lurker never runs commands in.
+func (m *Model) attachPtySession(key string, workdir string) *ptySession {
+	if !m.attachProfile {
+		return m.ensurePtySession(key, workdir)
+	}
+	return m.openPtySession(key+profileSuffix, workdir, true)
+}
+
+func (m *Model) openPtySession(key string, workdir string, profile bool) *ptySession {
+	session, created, evicted, err := m.ptys.open(key, workdir, profile, m.shellInUse)
+	if evicted != "" {
+		m.manager.DropIssuePTY(evicted)
+		m.appendLog(evicted, fmt.Sprintf("PTY closed to make room for %s", key))
+	}
 	if err != nil {
 		m.appendLog(key, "PTY: "+err.Error())
-		return
+		return nil
+	}
+	if created && !profile {
+		m.manager.SetIssuePTY(key, session)
+	}
+	if created {
+		m.appendLog(key, fmt.Sprintf("PTY created [%s] in %s", key, workdir))
+	}
+	return session
+}
+
+// closePtySession ends key's shell, if it has one.
+func (m *Model) closePtySession(key string) {
+	m.ptys.close(key)
+	m.ptys.close(key + profileSuffix)
+	m.manager.DropIssuePTY(key)
+}
+
+// shellInUse reports whether key's issue is being worked on, so its shell
+// must stay open.
+func (m *Model) shellInUse(key string) bool {
+	iss := m.issues.get(parseIssueKey(key))
+	return iss != nil && isActive(iss.Status)
+}
+
+// reapPtySessions closes the shells that have sat idle too long.
+func (m *Model) reapPtySessions() {
+	for _, key := range m.ptys.reap(m.now, m.shellInUse) {
+		m.manager.DropIssuePTY(key)
+		m.appendLog(key, "PTY closed after sitting idle")
 	}
-	m.ptySessions[key] = session
-	m.manager.SetIssuePTY(key, session)
-	m.appendLog(key, fmt.Sprintf("PTY created [%s] in %s", key, workdir))
 }
 
 func (m *Model) ptyWorkdir(iss *watcher.TrackedIssue) string {
@@ -446,91 +1065,135 @@ func (m *Model) ptyWorkdir(iss *watcher.TrackedIssue) string {
 	return dir
 }
 
-func (m *Model) toggleIssueProcessing() {
+func (m *Model) toggleIssueProcessing() tea.Cmd {
 	iss := m.selectedIssue()
 	if iss == nil {
-		return
+		return nil
 	}
 	key := issueKey(iss.Repo, iss.Number)
 
 	switch iss.Status {
 	case watcher.StatusPending:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		m.appendLog(key, "▶ Started")
-		m.expanded[key] = true
-	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusClaudeRunning:
+		m.startIssue(iss, "▶ Started")
+	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusQueued, watcher.StatusClaudeRunning:
 		m.manager.StopIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusPaused
+		m.syncStatus(iss)
 		m.appendLog(key, "⏸ Paused")
+		m.saveIssueMeta(iss)
 	case watcher.StatusPaused:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		m.appendLog(key, "▶ Resumed")
-		m.expanded[key] = true
-	case watcher.StatusFailed:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		iss.Error = ""
-		m.appendLog(key, "▶ Retrying")
-		m.expanded[key] = true
+		m.startIssue(iss, "▶ Resumed")
+	case watcher.StatusFailed, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+		m.startIssue(iss, "▶ Retrying")
+	case watcher.StatusNeedsInput:
+		return m.openReply(iss)
+	case watcher.StatusConflict:
+		return m.updateBranchFor(iss, false)
+	case watcher.StatusWaiting:
+		m.continueIssue(iss)
+	}
+	return nil
+}
+
+// startIssue kicks off (or resumes/retries) processing for iss and resets
+// its run clock so elapsed time and progress estimates start from now.
+func (m *Model) startIssue(iss *watcher.TrackedIssue, note string) {
+	key := issueKey(iss.Repo, iss.Number)
+	delete(m.replayed, key)
+	m.ensurePtySession(key, m.ptyWorkdir(iss))
+	m.manager.StartIssue(iss.Repo, iss.Number)
+	m.syncStatus(iss)
+	iss.Error = ""
+	iss.StartedAt = time.Now()
+	iss.Attempts++
+	m.appendLog(key, note)
+	m.expanded[key] = true
+	m.saveIssueMeta(iss)
+}
+
+// continueIssue lets iss's run go on past the gated stage it waits at. A
+// run that stopped waiting, as lurker restarted, is started again.
+func (m *Model) continueIssue(iss *watcher.TrackedIssue) {
+	if !m.manager.ContinueIssue(iss.Repo, iss.Number) {
+		m.startIssue(iss, "▶ Restarted: its run was no longer waiting")
+	}
+}
+
+// saveIssueMeta mirrors iss into its issue.json.
+func (m *Model) saveIssueMeta(iss *watcher.TrackedIssue) {
+	if iss != nil {
+		watcher.WriteIssueMeta(m.manager.BaseDir(), *iss)
+	}
+}
+
+// findIssue returns the tracked issue repo#num, or nil.
+func (m *Model) findIssue(repo string, num int) *watcher.TrackedIssue {
+	return m.issues.get(repo, num)
+}
+
+// forgetIssue stops tracking repo#num and drops its logs, leaving the
+// focus view or dialog if they were showing it.
+func (m *Model) forgetIssue(repo string, num int) {
+	iss := m.issues.remove(repo, num)
+	if iss == nil {
+		return
+	}
+	key := issueKey(repo, num)
+	delete(m.logs, key)
+	delete(m.expanded, key)
+	if m.focusIssue == iss {
+		m.focusIssue = nil
+		if m.focus == focusFocus {
+			m.focus = focusList
+		}
+	}
+	if m.dialogIssue == iss {
+		m.dialogIssue = nil
+	}
+}
+
+// clampCursor keeps the cursor on the list after items were removed.
+func (m *Model) clampCursor() {
+	items := m.visibleItems()
+	if m.cursor >= len(items) {
+		m.cursor = len(items) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
 	}
 }
 
 func (m *Model) startAllStopped() {
-	for i := range m.issues {
-		iss := &m.issues[i]
-		key := issueKey(iss.Repo, iss.Number)
+	for _, iss := range m.issues.all() {
 		switch iss.Status {
 		case watcher.StatusPending:
-			m.ensurePtySession(key, m.ptyWorkdir(iss))
-			m.manager.StartIssue(iss.Repo, iss.Number)
-			iss.Status = watcher.StatusReacted
-			m.appendLog(key, "▶ Started")
-			m.expanded[key] = true
+			m.startIssue(iss, "▶ Started")
 		case watcher.StatusPaused:
-			m.ensurePtySession(key, m.ptyWorkdir(iss))
-			m.manager.StartIssue(iss.Repo, iss.Number)
-			iss.Status = watcher.StatusReacted
-			m.appendLog(key, "▶ Resumed")
-			m.expanded[key] = true
+			m.startIssue(iss, "▶ Resumed")
 		case watcher.StatusFailed:
-			m.ensurePtySession(key, m.ptyWorkdir(iss))
-			m.manager.StartIssue(iss.Repo, iss.Number)
-			iss.Status = watcher.StatusReacted
-			iss.Error = ""
-			m.appendLog(key, "▶ Retrying")
-			m.expanded[key] = true
+			m.startIssue(iss, "▶ Retrying")
 		}
 	}
 }
 
-func (m *Model) refreshFocusIssue() {
-	if m.focusIssue == nil {
-		return
-	}
-	for i := range m.issues {
-		if m.issues[i].Repo == m.focusIssue.Repo && m.issues[i].Number == m.focusIssue.Number {
-			m.focusIssue = &m.issues[i]
-			return
-		}
+// atFocusBottom reports whether the focus view is scrolled to its end,
+// and so should follow new lines.
+func (m *Model) atFocusBottom() bool {
+	visibleLines := m.height - 5
+	if visibleLines < 1 {
+		visibleLines = 1
 	}
+	return m.focusScroll >= max(m.focusLineCount()-visibleLines, 0)
 }
 
 func (m *Model) clampFocusScroll() {
 	if m.focusIssue == nil {
 		return
 	}
-	key := issueKey(m.focusIssue.Repo, m.focusIssue.Number)
-	lines := m.logs[key]
-	visibleLines := m.height - 5 // header(1) + title(1) + sep(1) + sep(1) + footer(1)
+	visibleLines := m.height - 5 // header(1) + title(1) + tabs(1) + sep(1) + footer(1)
 	if visibleLines < 1 {
 		visibleLines = 1
 	}
-	maxScroll := len(lines) - visibleLines
+	maxScroll := m.focusLineCount() - visibleLines
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
@@ -539,35 +1202,33 @@ func (m *Model) clampFocusScroll() {
 	}
 }
 
-func (m *Model) toggleFocusIssueProcessing() {
+func (m *Model) toggleFocusIssueProcessing() tea.Cmd {
 	iss := m.focusIssue
 	if iss == nil {
-		return
+		return nil
 	}
 	key := issueKey(iss.Repo, iss.Number)
 
 	switch iss.Status {
 	case watcher.StatusPending:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		m.appendLog(key, "▶ Started")
-	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusClaudeRunning:
+		m.startIssue(iss, "▶ Started")
+	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusQueued, watcher.StatusClaudeRunning:
 		m.manager.StopIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusPaused
+		m.syncStatus(iss)
 		m.appendLog(key, "⏸ Paused")
+		m.saveIssueMeta(iss)
 	case watcher.StatusPaused:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		m.appendLog(key, "▶ Resumed")
-	case watcher.StatusFailed:
-		m.ensurePtySession(key, m.ptyWorkdir(iss))
-		m.manager.StartIssue(iss.Repo, iss.Number)
-		iss.Status = watcher.StatusReacted
-		iss.Error = ""
-		m.appendLog(key, "▶ Retrying")
+		m.startIssue(iss, "▶ Resumed")
+	case watcher.StatusFailed, watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+		m.startIssue(iss, "▶ Retrying")
+	case watcher.StatusNeedsInput:
+		return m.openReply(iss)
+	case watcher.StatusConflict:
+		return m.updateBranchFor(iss, false)
+	case watcher.StatusWaiting:
+		m.continueIssue(iss)
 	}
+	return nil
 }
 
 func (m *Model) ensureCursorVisible() {
@@ -576,36 +1237,72 @@ func (m *Model) ensureCursorVisible() {
 		return
 	}
 
+	rows := func(item listItem) int {
+		n := 1 // the item line itself
+		if item.kind == itemRepo && m.repoErrors[item.repo] != "" {
+			n++ // error line beneath repo
+		}
+		if item.kind == itemIssue && m.narrow() {
+			n++ // title stacked beneath the status line
+		}
+		if item.kind == itemIssue {
+			n += len(questionLines(*item.issue)) // questions beneath it
+			if failureLine(*item.issue) != "" {
+				n++ // error beneath it
+			}
+		}
+		return n
+	}
+
 	lineOffset := 0
 	for i := 0; i < m.cursor; i++ {
-		lineOffset++ // the item line itself
-		if items[i].kind == itemRepo && m.repoErrors[items[i].repo] != "" {
-			lineOffset++ // error line beneath repo
-		}
+		lineOffset += rows(items[i])
 	}
+	lastLine := lineOffset + rows(items[m.cursor]) - 1
 
 	if lineOffset < m.listScroll {
 		m.listScroll = lineOffset
 	}
-	if lineOffset >= m.listScroll+m.listHeight {
-		m.listScroll = lineOffset - m.listHeight + 1
+	if lastLine >= m.listScroll+m.listHeight {
+		m.listScroll = lastLine - m.listHeight + 1
 	}
 }
 
+// openURL opens url in the browser: with open on macOS, and xdg-open on
+// Linux and the other Unixes.
+func openURL(url string) {
+	name := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		name = "open"
+	}
+	exec.Command(name, url).Start()
+}
+
 func (m *Model) openGithubIssue() {
 	if iss := m.selectedIssue(); iss != nil && iss.URL != "" {
-		exec.Command("open", iss.URL).Start()
+		openURL(iss.URL)
 	}
 }
 
 func (m *Model) showDialog() {
 	if iss := m.selectedIssue(); iss != nil {
 		m.dialogIssue = iss
+		m.dialogRecordings, _ = watcher.ListRecordings(watcher.IssueDir(m.manager.BaseDir(), iss.Repo, iss.Number))
+		m.dialogTools = watcher.ToolStats{}
+		if iss.Workdir != "" {
+			if s, err := watcher.LoadRunSummary(iss.Workdir); err == nil {
+				m.dialogTools = s.Tools
+			}
+		}
+		m.dialogResult, _ = watcher.ReadRunResult(watcher.IssueDir(m.manager.BaseDir(), iss.Repo, iss.Number))
 		m.focus = focusDialog
 	}
 }
 
-func (m *Model) removeSelectedRepoConfirmed() {
+// removeSelectedRepoConfirmed stops watching the confirmed repo. With
+// deleteFiles, its bare clone, worktrees and logs are deleted in the
+// background; otherwise they stay under BaseDir.
+func (m *Model) removeSelectedRepoConfirmed(deleteFiles bool) {
 	repo := m.confirmRepo
 	m.confirmRepo = ""
 	if repo == "" {
@@ -613,31 +1310,64 @@ func (m *Model) removeSelectedRepoConfirmed() {
 	}
 	m.manager.RemoveRepo(repo)
 
-	var remaining []watcher.TrackedIssue
-	for _, issue := range m.issues {
-		if issue.Repo != repo {
-			remaining = append(remaining, issue)
-		} else {
-			key := issueKey(issue.Repo, issue.Number)
-			delete(m.logs, key)
-			delete(m.expanded, key)
-			if s := m.ptySessions[key]; s != nil {
-				s.cmd.Process.Signal(syscall.SIGHUP)
-				delete(m.ptySessions, key)
-			}
-		}
+	for _, issue := range m.issues.inRepo(repo) {
+		key := issueKey(issue.Repo, issue.Number)
+		m.closePtySession(key)
+		m.forgetIssue(issue.Repo, issue.Number)
 	}
-	m.issues = remaining
 	delete(m.repoExpanded, repo)
 	delete(m.repoErrors, repo)
 
-	items := m.visibleItems()
-	if m.cursor >= len(items) {
-		m.cursor = len(items) - 1
+	if deleteFiles {
+		if err := m.manager.DeleteRepoFiles(repo); err != nil {
+			m.cleanupStatus = err.Error()
+		} else {
+			m.cleanupStatus = "deleting " + repo
+		}
 	}
-	if m.cursor < 0 {
-		m.cursor = 0
+	m.clampCursor()
+}
+
+// renameRepoConfirmed follows a GitHub rename/transfer: the manager moves
+// state and workdirs, and the model re-keys its issues, logs and sessions.
+func (m *Model) renameRepoConfirmed() {
+	from, to := m.confirmRepo, m.confirmRename
+	m.confirmRepo = ""
+	m.confirmRename = ""
+	if from == "" || to == "" {
+		return
+	}
+	// Shells were started inside the old path; close them before it moves
+	// and let the next action create a fresh one.
+	for _, iss := range m.issues.inRepo(from) {
+		m.closePtySession(issueKey(from, iss.Number))
 	}
+	if err := m.manager.RenameRepo(from, to); err != nil {
+		m.repoErrors[from] = err.Error()
+		return
+	}
+
+	for _, iss := range m.issues.inRepo(from) {
+		oldKey := issueKey(from, iss.Number)
+		newKey := issueKey(to, iss.Number)
+		m.logs[newKey] = m.logs[oldKey]
+		delete(m.logs, oldKey)
+		m.expanded[newKey] = m.expanded[oldKey]
+		delete(m.expanded, oldKey)
+
+		iss.Repo = to
+		m.issues.rekey(oldKey, iss)
+		iss.URL = strings.Replace(iss.URL, "/"+from+"/", "/"+to+"/", 1)
+		if iss.Workdir != "" {
+			iss.Workdir = filepath.Join(m.manager.BaseDir(), to, fmt.Sprintf("%d", iss.Number), filepath.Base(to))
+		}
+		m.appendLog(newKey, fmt.Sprintf("Repo moved: %s → %s", from, to))
+	}
+
+	m.repoExpanded[to] = m.repoExpanded[from]
+	delete(m.repoExpanded, from)
+	delete(m.repoErrors, from)
+	delete(m.repoMoves, from)
 }
 
 func (m *Model) launchLazygitFor(iss *watcher.TrackedIssue) tea.Cmd {
@@ -656,22 +1386,12 @@ func (m *Model) launchShellFor(iss *watcher.TrackedIssue) tea.Cmd {
 
 	key := issueKey(iss.Repo, iss.Number)
 
-	session := m.ptySessions[key]
-	if session == nil || session.isDone() {
-		// No PTY exists yet — create one (starts shell automatically)
-		var err error
-		session, err = newPtySession(iss.Workdir)
-		if err != nil {
-			m.appendLog(key, "PTY: "+err.Error())
-			return nil
-		}
-		m.ptySessions[key] = session
-		m.manager.SetIssuePTY(key, session)
+	session := m.attachPtySession(key, iss.Workdir)
+	if session == nil {
+		return nil
 	}
 
-	return tea.Exec(&ptyAttacher{session: session, label: key}, func(err error) tea.Msg {
-		return nil
-	})
+	return m.attachTo(session, key, iss, nil)
 }
 
 func (m *Model) launchClaudeFor(iss *watcher.TrackedIssue) tea.Cmd {
@@ -680,24 +1400,15 @@ func (m *Model) launchClaudeFor(iss *watcher.TrackedIssue) tea.Cmd {
 	}
 
 	key := issueKey(iss.Repo, iss.Number)
-	session := m.ptySessions[key]
-	if session == nil || session.isDone() {
-		var err error
-		session, err = newPtySession(iss.Workdir)
-		if err != nil {
-			m.appendLog(key, "PTY: "+err.Error())
-			return nil
-		}
-		m.ptySessions[key] = session
-		m.manager.SetIssuePTY(key, session)
+	session := m.attachPtySession(key, iss.Workdir)
+	if session == nil {
+		return nil
 	}
 
 	// Send claude command to the PTY shell, then attach
 	session.ptmx.Write([]byte("claude\n"))
 
-	return tea.Exec(&ptyAttacher{session: session, label: key}, func(err error) tea.Msg {
-		return nil
-	})
+	return m.attachTo(session, key, iss, nil)
 }
 
 // interactiveClaudeDoneMsg is sent when the user exits an interactive Claude session.
@@ -720,27 +1431,35 @@ func (m *Model) takeoverClaudeFor(iss *watcher.TrackedIssue) tea.Cmd {
 		m.appendLog(key, "⏸ Pausing automation — launching interactive session...")
 	}
 
-	session := m.ptySessions[key]
-	if session == nil || session.isDone() {
-		var err error
-		session, err = newPtySession(iss.Workdir)
-		if err != nil {
-			m.appendLog(key, "PTY: "+err.Error())
-			return nil
-		}
-		m.ptySessions[key] = session
-		m.manager.SetIssuePTY(key, session)
+	session := m.attachPtySession(key, iss.Workdir)
+	if session == nil {
+		return nil
+	}
+
+	// The session may rewrite anything, so keep the current state restorable
+	if c, err := m.manager.Checkpoint(iss.Repo, iss.Number, "before takeover"); err != nil {
+		m.appendLog(key, "❌ Checkpoint failed: "+err.Error())
+	} else {
+		m.appendLog(key, fmt.Sprintf("📸 Checkpoint %s (before takeover)", c.ID))
 	}
 
 	// Send claude --continue to the PTY shell, then attach
 	session.ptmx.Write([]byte("claude --continue\n"))
 
-	return tea.Exec(&ptyAttacher{session: session, label: key}, func(err error) tea.Msg {
-		return interactiveClaudeDoneMsg{repo: iss.Repo, num: iss.Number, workdir: iss.Workdir}
-	})
+	return m.attachTo(session, key, iss, interactiveClaudeDoneMsg{repo: iss.Repo, num: iss.Number, workdir: iss.Workdir})
+}
+
+// approvePRFor pushes the issue branch and opens a PR with the default
+// title, body and base, without the PR dialog or checklist, once the
+// preflight checks pass. The PR is a draft if draft is set or the repo's
+// config says so.
+func (m *Model) approvePRFor(iss *watcher.TrackedIssue, draft bool) tea.Cmd {
+	return m.pushPRFor(iss, draft, true)
 }
 
-func (m *Model) approvePRFor(iss *watcher.TrackedIssue) tea.Cmd {
+// pushPRFor is approvePRFor, running the preflight checks first only if
+// preflight is set.
+func (m *Model) pushPRFor(iss *watcher.TrackedIssue, draft, preflight bool) tea.Cmd {
 	if iss == nil || iss.Workdir == "" {
 		return nil
 	}
@@ -749,75 +1468,104 @@ func (m *Model) approvePRFor(iss *watcher.TrackedIssue) tea.Cmd {
 	title := iss.Title
 	workdir := iss.Workdir
 	repo := iss.Repo
-	ghClient := m.ghClient
+	forge := m.manager.Forge()
+	base := m.manager.BaseBranch(repo, workdir)
+	cfg := m.manager.RepoConfig(workdir)
+	summary := cfg.PRSummary && forge.Permissions().AllowComments
+	draft = draft || cfg.DraftPRs
+	cost := iss.CostUSD
+	box := m.manager.Bwrap()
 
 	key := issueKey(repo, num)
+	if perms := forge.Permissions(); !perms.AllowPush || !perms.AllowPRCreate {
+		m.appendLog(key, "🔒 Pushing and PR creation are disabled by permissions")
+		return nil
+	}
 	m.appendLog(key, "")
-	m.appendLog(key, "🚀 Pushing branch & creating PR...")
+	if preflight {
+		m.appendLog(key, "🛫 Running preflight checks...")
+	}
+	if draft {
+		m.appendLog(key, "🚀 Pushing branch & creating draft PR...")
+	} else {
+		m.appendLog(key, "🚀 Pushing branch & creating PR...")
+	}
 
 	return func() tea.Msg {
-		cmd := exec.Command("git", "push", "-u", "origin", "HEAD")
-		cmd.Dir = workdir
-		if out, err := cmd.CombinedOutput(); err != nil {
-			return prResultMsg{repo: repo, issueNum: num, err: fmt.Errorf("push: %s: %w", strings.TrimSpace(string(out)), err)}
+		if preflight {
+			if err := watcher.RunPreflight(context.Background(), workdir, base, cfg).Err(); err != nil {
+				return prResultMsg{repo: repo, issueNum: num, err: err}
+			}
 		}
-
-		cmd = exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-		cmd.Dir = workdir
-		branchOut, err := cmd.Output()
+		pd, err := watcher.LoadPRDraft(box, workdir, num, title, base, cfg)
 		if err != nil {
-			return prResultMsg{repo: repo, issueNum: num, err: fmt.Errorf("branch: %w", err)}
+			return prResultMsg{repo: repo, issueNum: num, err: err}
 		}
-		branch := strings.TrimSpace(string(branchOut))
-
-		cmd = exec.Command("git", "log", "--oneline", "main.."+branch)
-		cmd.Dir = workdir
-		logOut, _ := cmd.Output()
-
-		body := fmt.Sprintf("Fixes #%d\n\n## Commits\n```\n%s```\n\n🤖 Generated by lurker", num, string(logOut))
-
-		prTitle := fmt.Sprintf("Fix #%d: %s", num, title)
-		pr, err := ghClient.CreatePR(context.Background(), github.CreatePRRequest{
-			Repo:  repo,
-			Title: prTitle,
-			Body:  body,
-			Head:  branch,
-			Base:  "main",
+		res := submitPR(forge, repo, num, workdir, watcher.PRSpec{
+			Head:      pd.Head,
+			Title:     pd.Title,
+			Body:      pd.Body,
+			Base:      pd.Bases[0],
+			Draft:     draft,
+			Reviewers: pd.Reviewers,
+			Labels:    pd.Labels,
+			Assignees: pd.Assignees,
+			Summary:   summary,
+			CostUSD:   cost,
+			Templates: cfg.Templates,
 		})
-		if err != nil {
-			return prResultMsg{repo: repo, issueNum: num, err: fmt.Errorf("pr: %w", err)}
-		}
-
-		return prResultMsg{repo: repo, issueNum: num, url: pr.HTMLURL}
+		res.warnings = append(pd.Warnings, res.warnings...)
+		return res
 	}
 }
 
 func (m *Model) handleInteractiveReturn(msg interactiveClaudeDoneMsg) {
 	key := issueKey(msg.repo, msg.num)
 
-	// Check if the branch has commits beyond origin/main
-	branch := fmt.Sprintf("agent/issue-%d", msg.num)
-	cmd := exec.Command("git", "log", "--oneline", "origin/main.."+branch)
+	// Check if the branch has commits beyond the base
+	branch := watcher.IssueBranch(msg.num)
+	base := m.manager.BaseBranch(msg.repo, msg.workdir)
+	cmd := exec.Command("git", "log", "--oneline", "origin/"+base+".."+branch)
 	cmd.Dir = msg.workdir
 	out, err := cmd.Output()
 	if err == nil && len(strings.TrimSpace(string(out))) > 0 {
-		m.updateIssueStatus(msg.repo, msg.num, watcher.StatusReady)
+		m.changeIssueStatus(msg.repo, msg.num, watcher.StatusReady)
 		m.appendLog(key, "✅ Interactive session done — ready for review")
 	} else {
 		// No new commits — mark as clone-ready so user can restart
-		m.updateIssueStatus(msg.repo, msg.num, watcher.StatusCloneReady)
+		m.changeIssueStatus(msg.repo, msg.num, watcher.StatusCloneReady)
 		m.appendLog(key, "Interactive session ended")
 	}
-	m.refreshFocusIssue()
+	m.saveIssueMeta(m.findIssue(msg.repo, msg.num))
 }
 
 func (m *Model) handlePRResult(msg prResultMsg) {
 	key := issueKey(msg.repo, msg.issueNum)
 	if msg.err != nil {
-		m.appendLog(key, "❌ "+msg.err.Error())
+		m.appendFailure(key, msg.err.Error())
+		if msg.rejected != nil {
+			m.offerForcePush(msg)
+		}
 	} else {
-		m.appendLog(key, "✅ PR: "+msg.url)
+		if msg.existed {
+			m.appendLog(key, fmt.Sprintf("ℹ PR #%d already exists — pushed the branch to it: %s", msg.prNum, msg.url))
+			m.offerPRUpdate(msg)
+		} else {
+			m.appendLog(key, "✅ PR: "+msg.url)
+		}
+		for _, w := range msg.warnings {
+			m.appendLog(key, "⚠ "+w)
+		}
 		m.expanded[key] = true
+		if iss := m.findIssue(msg.repo, msg.issueNum); iss != nil {
+			iss.PRNumber = msg.prNum
+			iss.PRURL = msg.url
+			iss.Checks = watcher.ChecksPending
+			m.saveIssueMeta(iss)
+			m.manager.WatchChecks(msg.repo, msg.issueNum, msg.prNum)
+			m.manager.WatchFeedback(msg.repo, msg.issueNum, msg.prNum)
+			m.appendLog(key, "🔄 Watching CI checks...")
+		}
 	}
 }
 
@@ -827,7 +1575,8 @@ func (m *Model) handleEvent(ev watcher.Event) {
 	key := issueKey(ev.Repo, ev.IssueNum)
 
 	// Ignore processing events for paused issues (stale from cancelled ctx)
-	if ev.IssueNum > 0 && ev.Kind != watcher.EventIssueFound {
+	if ev.IssueNum > 0 && ev.Kind != watcher.EventIssueFound && ev.Kind != watcher.EventIssueMoved && ev.Kind != watcher.EventIssueGone &&
+		ev.Kind != watcher.EventStatus {
 		if m.findIssueStatus(ev.Repo, ev.IssueNum) == watcher.StatusPaused {
 			return
 		}
@@ -839,131 +1588,413 @@ func (m *Model) handleEvent(ev watcher.Event) {
 		m.lastPoll = ev.Timestamp
 
 	case watcher.EventIssueFound:
+		if m.replayed[key] {
+			// Re-polled: refresh what the journal had
+			delete(m.replayed, key)
+			if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+				iss.Title, iss.Body, iss.Labels, iss.URL = ev.Text, ev.IssueBody, ev.IssueLabels, ev.IssueURL
+				break
+			}
+		}
 		// Auto-expand repo folder for first issue
 		if _, ok := m.repoExpanded[ev.Repo]; !ok {
 			m.repoExpanded[ev.Repo] = true
 		}
-		status, workdir := watcher.DeriveIssueStatus(m.manager.BaseDir(), ev.Repo, ev.IssueNum)
-		m.issues = append(m.issues, watcher.TrackedIssue{
-			Repo:      ev.Repo,
-			Number:    ev.IssueNum,
-			Title:     ev.Text,
-			Body:      ev.IssueBody,
-			Labels:    ev.IssueLabels,
-			URL:       ev.IssueURL,
-			Status:    status,
-			Workdir:   workdir,
-			StartedAt: ev.Timestamp,
-		})
+		// The manager tracked it, status and all, on the way here.
+		tracked, ok := m.manager.Issue(ev.Repo, ev.IssueNum)
+		if !ok {
+			break
+		}
+		iss := m.issues.add(tracked)
+		status := iss.Status
+		if iss.Checks == watcher.ChecksPending && iss.PRNumber > 0 {
+			m.manager.WatchChecks(ev.Repo, ev.IssueNum, iss.PRNumber)
+		}
+		if iss.PRNumber > 0 {
+			m.manager.WatchFeedback(ev.Repo, ev.IssueNum, iss.PRNumber)
+		}
 		if logs := m.loadPersistedLogs(ev.Repo, ev.IssueNum); logs != nil {
 			m.logs[key] = logs
 		} else {
-			m.logs[key] = []string{}
+			m.logs[key] = []logLine{}
+		}
+		m.tallyAway(ev)
+		if status == watcher.StatusPending && m.autoStartAllowed(ev.Repo) {
+			m.startIssue(iss, "▶ Auto-started")
 		}
 
 	case watcher.EventReacted:
 		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusReacted)
-		m.appendLog(key, "👀 Reacted")
+		m.appendLogAt(key, ev.Timestamp, "👀 Reacted")
 
 	case watcher.EventCloneStart:
 		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusCloning)
-		m.appendLog(key, "📦 Cloning...")
+		m.appendLogAt(key, ev.Timestamp, "📦 Cloning...")
 
 	case watcher.EventCloneDone:
 		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusCloneReady)
 		m.setWorkdir(ev.Repo, ev.IssueNum, ev.Text)
-		m.appendLog(key, "📂 "+ev.Text)
+		m.appendLogAt(key, ev.Timestamp, "📂 "+ev.Text)
 
 	case watcher.EventClaudeStart:
 		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusClaudeRunning)
-		m.appendLog(key, "🤖 Claude working...")
+		m.claudeSince[key] = ev.Timestamp
+		m.appendLogAt(key, ev.Timestamp, "🤖 Claude working...")
 		m.expanded[key] = true
 
 	case watcher.EventClaudeLog:
-		m.appendLog(key, "  "+ev.Text)
+		kind := classifyLog(ev.Text)
+		if kind == logStage {
+			kind = logAgent
+		}
+		m.appendLogAs(key, ev.Timestamp, kind, "  "+ev.Text)
 
 	case watcher.EventClaudeDone:
-		m.appendLog(key, ev.Text)
+		m.appendLogAt(key, ev.Timestamp, ev.Text)
+
+	case watcher.EventCheckpoint:
+		m.appendLogAt(key, ev.Timestamp, "📸 "+ev.Text)
+
+	case watcher.EventVerify:
+		m.appendLogAt(key, ev.Timestamp, "🧪 "+ev.Text)
+
+	case watcher.EventProgress:
+		m.appendLogAt(key, ev.Timestamp, "💬 "+ev.Text)
+
+	case watcher.EventToolStats:
+		m.appendLogAt(key, ev.Timestamp, "📊 "+ev.Text)
+
+	case watcher.EventWarning:
+		m.appendLogAt(key, ev.Timestamp, "⚠ "+ev.Text)
+
+	case watcher.EventCost:
+		// The cost is the manager's, like the status.
+		if tracked, ok := m.manager.Issue(ev.Repo, ev.IssueNum); ok {
+			if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+				iss.CostUSD = tracked.CostUSD
+				m.saveIssueMeta(iss)
+			}
+			m.appendLogAt(key, ev.Timestamp, fmt.Sprintf("💰 $%.2f", tracked.CostUSD))
+		}
+
+	case watcher.EventResult:
+		m.appendLogAt(key, ev.Timestamp, "📋 Claude's result:")
+		for _, line := range strings.Split(ev.Text, "\n") {
+			m.appendLogAs(key, ev.Timestamp, logAgent, "  "+line)
+		}
+
+	case watcher.EventFeedback:
+		m.handleFeedback(ev)
+
+	case watcher.EventFeedbackDone:
+		m.handleFeedbackDone(ev)
+
+	case watcher.EventRebased:
+		m.appendLogAt(key, ev.Timestamp, "🔀 "+ev.Text)
+
+	case watcher.EventConflict:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusConflict)
+		m.setError(ev.Repo, ev.IssueNum, ev.Text)
+		m.appendLogAt(key, ev.Timestamp, "✗ "+ev.Text)
+		m.notifyTransition(ev, "conflicts with its base")
+
+	case watcher.EventBranchGone:
+		m.appendLogAt(key, ev.Timestamp, "🧹 "+ev.Text)
+
+	case watcher.EventAbandoned:
+		m.closePtySession(key)
+		m.forgetIssue(ev.Repo, ev.IssueNum)
+		m.clampCursor()
+
+	case watcher.EventStatus:
+		// Changed by the manager, or another frontend: take its word.
+		iss := m.findIssue(ev.Repo, ev.IssueNum)
+		if tracked, ok := m.manager.Issue(ev.Repo, ev.IssueNum); ok && iss != nil && iss.Status != tracked.Status {
+			iss.Status = tracked.Status
+			iss.Stages = tracked.Stages
+			m.saveIssueMeta(iss)
+		}
+
+	case watcher.EventGateOutput:
+		m.appendLogAt(key, ev.Timestamp, "  │ "+ev.Text)
+
+	case watcher.EventBuilding, watcher.EventBuildPassed:
+		m.appendLogAt(key, ev.Timestamp, "🔨 "+ev.Text)
+
+	case watcher.EventPipeline, watcher.EventStageStarted, watcher.EventStagePassed, watcher.EventStageFailed:
+		// The stages' beads are the manager's, like the status.
+		iss := m.findIssue(ev.Repo, ev.IssueNum)
+		if tracked, ok := m.manager.Issue(ev.Repo, ev.IssueNum); ok && iss != nil {
+			iss.Stages = tracked.Stages
+			m.saveIssueMeta(iss)
+		}
+
+	case watcher.EventBuildFailed:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusBuildFailed)
+		m.setError(ev.Repo, ev.IssueNum, ev.Text)
+		m.appendLogAt(key, ev.Timestamp, "✗ "+ev.Text)
+		m.notifyTransition(ev, "failed to build")
+
+	case watcher.EventTestsFailed:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusTestsFailed)
+		m.setError(ev.Repo, ev.IssueNum, ev.Text)
+		m.appendLogAt(key, ev.Timestamp, "✗ "+ev.Text)
+		m.notifyTransition(ev, "failed its tests")
+
+	case watcher.EventQueued:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusQueued)
+		m.appendLogAt(key, ev.Timestamp, "⏳ "+ev.Text)
+
+	case watcher.EventToolRequest:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusNeedsInput)
+		if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+			iss.Questions = ev.Text
+		}
+		m.appendLogAt(key, ev.Timestamp, "🔐 Claude needs tools allowed — press space to allow or deny:")
+		for _, line := range strings.Split(ev.Text, "\n") {
+			m.appendLogAs(key, ev.Timestamp, logAgent, "  "+line)
+		}
+		m.notifyTransition(ev, "wants tools allowed")
+		m.tallyAway(ev)
+
+	case watcher.EventNeedsInput:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusNeedsInput)
+		if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+			iss.Questions = ev.Text
+		}
+		m.appendLogAt(key, ev.Timestamp, "❓ Claude needs your input — press space to answer:")
+		for _, line := range strings.Split(ev.Text, "\n") {
+			m.appendLogAs(key, ev.Timestamp, logAgent, "  "+line)
+		}
+		m.notifyTransition(ev, "needs your input")
+		m.tallyAway(ev)
+
+	case watcher.EventGateWaiting:
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusWaiting)
+		m.appendLogAt(key, ev.Timestamp, "⏸ "+ev.Text+" is done; waiting for your approval — press space to go on")
+		m.notifyTransition(ev, "waits for approval after "+ev.Text)
+
+	case watcher.EventGateApproved:
+		status := watcher.StatusClaudeRunning
+		if ev.Text == watcher.StageClone {
+			status = watcher.StatusCloneReady
+		}
+		m.updateIssueStatus(ev.Repo, ev.IssueNum, status)
+		m.appendLogAt(key, ev.Timestamp, "▶ Approved; going on after "+ev.Text)
 
 	case watcher.EventReady:
 		m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusReady)
-		m.appendLog(key, "✅ Ready — press 'a' to approve & open PR")
+		if ev.Text == watcher.PatchPath(m.manager.BaseDir(), ev.Repo, ev.IssueNum) {
+			// Patch mode: the checkout is gone, the patch is the result
+			m.setWorkdir(ev.Repo, ev.IssueNum, "")
+			m.appendLogAt(key, ev.Timestamp, "✅ Patch ready — "+ev.Text)
+		} else {
+			m.appendLogAt(key, ev.Timestamp, "✅ Ready — press 'a' to approve & open PR")
+		}
+		m.notifyTransition(ev, "is ready for review")
+		m.tallyAway(ev)
+
+	case watcher.EventChecksPassed, watcher.EventChecksFailed, watcher.EventChecksNone:
+		state, mark := watcher.ChecksNone, "⚪ "
+		switch ev.Kind {
+		case watcher.EventChecksPassed:
+			state, mark = watcher.ChecksPassed, "✅ "
+			m.notifyTransition(ev, "passed CI")
+		case watcher.EventChecksFailed:
+			state, mark = watcher.ChecksFailed, "❌ "
+			m.notifyTransition(ev, "failed CI")
+		}
+		if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+			iss.Checks = state
+			m.saveIssueMeta(iss)
+		}
+		m.appendLogAt(key, ev.Timestamp, mark+ev.Text)
 
 	case watcher.EventError:
 		if ev.IssueNum == 0 {
 			// Repo-level error (e.g. poll failure, bad repo name)
-			m.repoErrors[ev.Repo] = ev.Text
+			m.repoErrors[ev.Repo] = ev.Text + ev.RepeatsText()
 		} else {
 			m.updateIssueStatus(ev.Repo, ev.IssueNum, watcher.StatusFailed)
 			m.setError(ev.Repo, ev.IssueNum, ev.Text)
-			m.appendLog(key, "❌ "+ev.Text)
+			m.appendFailure(key, ev.Text)
+			m.notifyTransition(ev, "failed: "+ev.Text)
+			m.tallyAway(ev)
 		}
 
+	case watcher.EventIssueMoved:
+		m.moveIssue(ev)
+
+	case watcher.EventIssueGone:
+		// Gone from the list with the manager's; a running one stays until
+		// its run is over and lurker restarts.
+		if _, ok := m.manager.Issue(ev.Repo, ev.IssueNum); ok {
+			m.appendLogAt(key, ev.Timestamp, "🚪 "+ev.Text+"; kept while it runs")
+			break
+		}
+		m.closePtySession(key)
+		m.forgetIssue(ev.Repo, ev.IssueNum)
+		m.clampCursor()
+
+	case watcher.EventRepoMoved:
+		m.repoMoves[ev.Repo] = ev.Text
+		m.repoErrors[ev.Repo] = fmt.Sprintf("moved to %s — press u to update", ev.Text)
+
+	case watcher.EventTeamConfig:
+		m.teamStatus = ev.Text
+
+	case watcher.EventDiscovery:
+		m.discoverStatus = ev.Text
+
+	case watcher.EventCleanup, watcher.EventCleanupDone:
+		m.cleanupStatus = ev.Text
+
 	case watcher.EventPollDone:
 		// Successful poll clears any repo-level error
 		delete(m.repoErrors, ev.Repo)
+		m.seededRepos[ev.Repo] = true
+		m.dropUnconfirmed(ev.Repo)
+	}
+
+	if ev.IssueNum > 0 && ev.Kind != watcher.EventIssueFound && ev.Kind != watcher.EventClaudeLog {
+		m.saveIssueMeta(m.findIssue(ev.Repo, ev.IssueNum))
 	}
 }
 
-func (m *Model) findIssueStatus(repo string, num int) watcher.IssueStatus {
-	for _, iss := range m.issues {
-		if iss.Repo == repo && iss.Number == num {
-			return iss.Status
+// dropUnconfirmed removes the replayed issues of repo that its poll didn't
+// find open; they were closed while lurker wasn't running.
+func (m *Model) dropUnconfirmed(repo string) {
+	dropped := false
+	for _, iss := range m.issues.inRepo(repo) {
+		key := issueKey(iss.Repo, iss.Number)
+		if m.replayed[key] {
+			delete(m.replayed, key)
+			m.forgetIssue(iss.Repo, iss.Number)
+			dropped = true
 		}
 	}
+	if dropped {
+		m.clampCursor()
+	}
+}
+
+// moveIssue re-keys a tracked issue that was transferred on GitHub so its
+// logs and workdir carry over. If the destination repo isn't watched, the
+// issue stays where it is with a note in its log.
+func (m *Model) moveIssue(ev watcher.Event) {
+	oldKey := issueKey(ev.Repo, ev.IssueNum)
+	newKey := issueKey(ev.MovedRepo, ev.MovedNum)
+
+	// Its shell is inside the directory about to move.
+	if slices.Contains(m.manager.Repos(), ev.MovedRepo) {
+		m.closePtySession(oldKey)
+	}
+	if err := m.manager.MoveIssue(ev.Repo, ev.IssueNum, ev.MovedRepo, ev.MovedNum); err != nil {
+		m.appendLogAt(oldKey, ev.Timestamp, fmt.Sprintf("↪ %s — %v", ev.Text, err))
+		return
+	}
+
+	if iss := m.findIssue(ev.Repo, ev.IssueNum); iss != nil {
+		// The destination poll may already have picked it up as a fresh issue.
+		m.forgetIssue(ev.MovedRepo, ev.MovedNum)
+		m.logs[newKey] = m.logs[oldKey]
+		delete(m.logs, oldKey)
+		m.expanded[newKey] = m.expanded[oldKey]
+		delete(m.expanded, oldKey)
+
+		iss.Repo = ev.MovedRepo
+		iss.Number = ev.MovedNum
+		m.issues.rekey(oldKey, iss)
+		iss.URL = fmt.Sprintf("https://github.com/%s/issues/%d", ev.MovedRepo, ev.MovedNum)
+		if iss.Workdir != "" {
+			iss.Workdir = filepath.Join(m.manager.BaseDir(), ev.MovedRepo, fmt.Sprintf("%d", ev.MovedNum), filepath.Base(ev.MovedRepo))
+		}
+		m.syncStatus(iss)
+		m.appendLogAt(newKey, ev.Timestamp, fmt.Sprintf("↪ Transferred from %s", oldKey))
+		m.saveIssueMeta(iss)
+	}
+	m.clampCursor()
+}
+
+func (m *Model) findIssueStatus(repo string, num int) watcher.IssueStatus {
+	if iss := m.findIssue(repo, num); iss != nil {
+		return iss.Status
+	}
 	return watcher.StatusPending
 }
 
 func (m *Model) updateIssueStatus(repo string, num int, status watcher.IssueStatus) {
-	for i := range m.issues {
-		if m.issues[i].Repo == repo && m.issues[i].Number == num {
-			m.issues[i].Status = status
-			return
-		}
+	if iss := m.findIssue(repo, num); iss != nil {
+		iss.Status = status
+	}
+}
+
+// changeIssueStatus is updateIssueStatus for a change the TUI makes
+// itself, rather than one an event reported: the manager is told too, so
+// every frontend sees it.
+func (m *Model) changeIssueStatus(repo string, num int, status watcher.IssueStatus) {
+	m.updateIssueStatus(repo, num, status)
+	m.manager.SetStatus(repo, num, status)
+}
+
+// syncStatus takes iss's status, and its stages, from the manager, which
+// records them, after the TUI had it change them.
+func (m *Model) syncStatus(iss *watcher.TrackedIssue) {
+	if tracked, ok := m.manager.Issue(iss.Repo, iss.Number); ok {
+		iss.Status = tracked.Status
+		iss.Stages = tracked.Stages
 	}
 }
 
 func (m *Model) setWorkdir(repo string, num int, dir string) {
-	for i := range m.issues {
-		if m.issues[i].Repo == repo && m.issues[i].Number == num {
-			m.issues[i].Workdir = dir
-			return
-		}
+	if iss := m.findIssue(repo, num); iss != nil {
+		iss.Workdir = dir
 	}
 }
 
 func (m *Model) setError(repo string, num int, errText string) {
-	for i := range m.issues {
-		if m.issues[i].Repo == repo && m.issues[i].Number == num {
-			m.issues[i].Error = errText
-			return
-		}
+	if iss := m.findIssue(repo, num); iss != nil {
+		iss.Error = errText
 	}
 }
 
 func (m *Model) appendLog(key string, line string) {
+	m.appendLogAt(key, time.Now(), line)
+}
+
+// appendLogAt is appendLog for a line stamped at, when the event it tells
+// of happened rather than when the TUI got to it.
+func (m *Model) appendLogAt(key string, at time.Time, line string) {
+	m.appendLogAs(key, at, classifyLog(line), line)
+}
+
+// appendLogAs is appendLogAt for a line whose kind is known.
+func (m *Model) appendLogAs(key string, at time.Time, kind logKind, line string) {
 	if m.logs[key] == nil {
-		m.logs[key] = []string{}
+		m.logs[key] = []logLine{}
 	}
 
 	// Check if focus view should auto-scroll (tail-follow)
 	autoScroll := false
-	if m.focus == focusFocus && m.focusIssue != nil {
+	if m.focus == focusFocus && m.focusIssue != nil && m.focusTab == tabLogs {
 		fKey := issueKey(m.focusIssue.Repo, m.focusIssue.Number)
 		if fKey == key {
-			visibleLines := m.height - 5
-			if visibleLines < 1 {
-				visibleLines = 1
-			}
-			maxScroll := len(m.logs[key]) - visibleLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-			autoScroll = m.focusScroll >= maxScroll
+			autoScroll = m.atFocusBottom()
 		}
 	}
 
-	m.logs[key] = append(m.logs[key], line)
+	// Tools' stderr and PTY output bring escape sequences along; the log
+	// keeps plain text, and their colors only for display.
+	if at.IsZero() {
+		at = time.Now()
+	}
+	entry := logLine{at: at, text: watcher.StripANSI(line), kind: kind}
+	if m.logColors {
+		if c := watcher.KeepColors(line); c != entry.text {
+			entry.color = c
+		}
+	}
+	m.logs[key] = append(m.logs[key], entry)
 	if len(m.logs[key]) > maxLogLines {
 		m.logs[key] = m.logs[key][len(m.logs[key])-maxLogLines:]
 	}
@@ -975,7 +2006,8 @@ func (m *Model) appendLog(key string, line string) {
 
 	repo, num := parseIssueKey(key)
 	if repo != "" {
-		m.persistLogLine(repo, num, line)
+		m.persistLogLine(repo, num, entry)
+		m.logHub.Publish(api.LogLine{Repo: repo, Number: num, At: entry.at, Text: entry.text})
 	}
 }
 
@@ -991,31 +2023,33 @@ func parseIssueKey(key string) (string, int) {
 }
 
 func (m *Model) logFilePath(repo string, num int) string {
-	return filepath.Join(m.manager.BaseDir(), repo, fmt.Sprintf("%d", num), "lurker.log")
+	return filepath.Join(watcher.IssueDir(m.manager.BaseDir(), repo, num), watcher.LogFile)
 }
 
-func (m *Model) persistLogLine(repo string, num int, line string) {
+// persistLogLine appends an entry to the issue's lurker.log as
+// "<RFC3339 timestamp>\t<text>".
+func (m *Model) persistLogLine(repo string, num int, line logLine) {
 	p := m.logFilePath(repo, num)
 	os.MkdirAll(filepath.Dir(p), 0o755)
 	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
 		return
 	}
-	f.WriteString(line + "\n")
+	f.WriteString(line.at.UTC().Format(time.RFC3339) + "\t" + line.text + "\n")
 	f.Close()
 }
 
-func (m *Model) loadPersistedLogs(repo string, num int) []string {
+func (m *Model) loadPersistedLogs(repo string, num int) []logLine {
 	p := m.logFilePath(repo, num)
 	f, err := os.Open(p)
 	if err != nil {
 		return nil
 	}
 	defer f.Close()
-	var lines []string
+	var lines []logLine
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+		lines = append(lines, parseLogLine(scanner.Text()))
 	}
 	if len(lines) > maxLogLines {
 		lines = lines[len(lines)-maxLogLines:]
@@ -1023,13 +2057,20 @@ func (m *Model) loadPersistedLogs(repo string, num int) []string {
 	return lines
 }
 
-// --- Counts (computed from issues slice) ---
+// parseLogLine splits a persisted log line into its timestamp and text.
+// Lines without a timestamp prefix (older logs) keep a zero time.
+func parseLogLine(raw string) logLine {
+	at, text := watcher.ParseLogLine(raw)
+	return logLine{at: at, text: text, kind: classifyLog(text)}
+}
+
+// --- Counts (computed from the issue store) ---
 
 func (m Model) countActive() int {
 	n := 0
-	for _, iss := range m.issues {
+	for _, iss := range m.issues.all() {
 		switch iss.Status {
-		case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusClaudeRunning:
+		case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusQueued, watcher.StatusClaudeRunning:
 			n++
 		}
 	}
@@ -1038,7 +2079,7 @@ func (m Model) countActive() int {
 
 func (m Model) countByStatus(s watcher.IssueStatus) int {
 	n := 0
-	for _, iss := range m.issues {
+	for _, iss := range m.issues.all() {
 		if iss.Status == s {
 			n++
 		}
@@ -1047,13 +2088,62 @@ func (m Model) countByStatus(s watcher.IssueStatus) int {
 }
 
 func (m Model) countIssuesForRepo(repo string) int {
-	n := 0
-	for _, iss := range m.issues {
-		if iss.Repo == repo {
-			n++
-		}
+	return len(m.issues.inRepo(repo))
+}
+
+// formatStamp renders t in local time ("15:04:05", with the date when it
+// isn't today) or relative to now ("3m ago").
+func formatStamp(t, now time.Time, relative bool) string {
+	if t.IsZero() {
+		return ""
 	}
-	return n
+	if relative {
+		return ago(t, now)
+	}
+	t = t.Local()
+	if y, mo, d := t.Date(); y != now.Year() || mo != now.Month() || d != now.Day() {
+		return t.Format("Jan 2 15:04")
+	}
+	return t.Format("15:04:05")
+}
+
+// ago renders the time since t in its largest sensible unit.
+func ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// progressEstimate renders a soft progress hint for a running issue from
+// the repo's median run time, e.g. "~60% · est 4m left". It returns ""
+// when the issue isn't running or there is no history yet.
+func (m Model) progressEstimate(iss watcher.TrackedIssue) string {
+	if !isActive(iss.Status) || iss.StartedAt.IsZero() {
+		return ""
+	}
+	median, ok := m.manager.MedianRunDuration(iss.Repo)
+	if !ok || median <= 0 {
+		return ""
+	}
+	spent := m.now.Sub(iss.StartedAt)
+	pct := int(100 * spent / median)
+	if pct > 95 {
+		// Never claim to be done; the run ends when it ends.
+		return "~95% · overdue"
+	}
+	left := median - spent
+	if left < time.Minute {
+		return fmt.Sprintf("~%d%% · est <1m left", pct)
+	}
+	return fmt.Sprintf("~%d%% · est %dm left", pct, int(left.Round(time.Minute).Minutes()))
 }
 
 func elapsed(start time.Time, now time.Time) string {
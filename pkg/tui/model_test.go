@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// pollBatch runs one pollEvents on ch, failing the test if it takes far
+// longer than a batch window.
+func pollBatch(t *testing.T, ch <-chan watcher.Event) any {
+	t.Helper()
+	got := make(chan any, 1)
+	go func() { got <- Model{eventCh: ch}.pollEvents()() }()
+	select {
+	case msg := <-got:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("pollEvents didn't return")
+		return nil
+	}
+}
+
+// nextBatch polls ch until it yields a batch, past the ticks of idle
+// polls, and lists the Text of each of its events.
+func nextBatch(t *testing.T, ch <-chan watcher.Event) []string {
+	t.Helper()
+	msg := pollBatch(t, ch)
+	for msg == (tickMsg{}) {
+		msg = pollBatch(t, ch)
+	}
+	return batchTexts(t, msg)
+}
+
+// batchTexts lists the Text of each event in msg, which must be an
+// eventsMsg.
+func batchTexts(t *testing.T, msg any) []string {
+	t.Helper()
+	batch, ok := msg.(eventsMsg)
+	if !ok {
+		t.Fatalf("msg = %#v, want an eventsMsg", msg)
+	}
+	var texts []string
+	for _, ev := range batch {
+		texts = append(texts, ev.Text)
+	}
+	return texts
+}
+
+func claudeLog(text string) watcher.Event {
+	return watcher.Event{Kind: watcher.EventClaudeLog, Repo: "o/r", IssueNum: 1, Text: text}
+}
+
+func statusEvent(s watcher.IssueStatus) watcher.Event {
+	return watcher.Event{Kind: watcher.EventStatus, Repo: "o/r", IssueNum: 1, Text: s.String()}
+}
+
+func TestPollEvents(t *testing.T) {
+	type send struct {
+		after time.Duration // since the previous send
+		ev    watcher.Event
+	}
+	tests := []struct {
+		name  string
+		sends []send
+		want  [][]string // texts of each batch
+	}{
+		{
+			name: "queued events come in one batch, in order",
+			sends: []send{
+				{0, statusEvent(watcher.StatusClaudeRunning)},
+				{0, claudeLog("a")},
+				{0, statusEvent(watcher.StatusReady)},
+			},
+			want: [][]string{{"claude", "a", "ready"}},
+		},
+		{
+			name: "Claude's output is coalesced over the window, status events in their place",
+			sends: []send{
+				{0, claudeLog("a")},
+				{20 * time.Millisecond, claudeLog("b")},
+				{20 * time.Millisecond, statusEvent(watcher.StatusWaiting)},
+				{20 * time.Millisecond, claudeLog("c")},
+			},
+			want: [][]string{{"a", "b", "waiting", "c"}},
+		},
+		{
+			name: "output after the window starts the next batch",
+			sends: []send{
+				{0, claudeLog("a")},
+				{3 * eventBatchWindow, claudeLog("b")},
+				{0, statusEvent(watcher.StatusReady)},
+			},
+			want: [][]string{{"a"}, {"b", "ready"}},
+		},
+		{
+			name: "other events aren't held for more",
+			sends: []send{
+				{0, statusEvent(watcher.StatusClaudeRunning)},
+				{3 * eventBatchWindow, claudeLog("a")},
+			},
+			want: [][]string{{"claude"}, {"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan watcher.Event, len(tt.sends))
+			// The first event, and those right after it, are queued before
+			// polling, so the batches don't hang on when the sender runs.
+			i := 0
+			for ; i < len(tt.sends) && tt.sends[i].after == 0; i++ {
+				ch <- tt.sends[i].ev
+			}
+			go func(rest []send) {
+				for _, s := range rest {
+					time.Sleep(s.after)
+					ch <- s.ev
+				}
+			}(tt.sends[i:])
+
+			for n, want := range tt.want {
+				if got := nextBatch(t, ch); !slices.Equal(got, want) {
+					t.Errorf("batch %d = %q, want %q", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPollEvents_Limits(t *testing.T) {
+	// Nothing to read is a tick, so the clock moves on.
+	if msg := pollBatch(t, make(chan watcher.Event)); msg != (tickMsg{}) {
+		t.Errorf("idle poll = %#v, want tickMsg", msg)
+	}
+
+	// A closed channel ends polling.
+	closed := make(chan watcher.Event)
+	close(closed)
+	if msg := pollBatch(t, closed); msg != nil {
+		t.Errorf("closed poll = %#v, want nil", msg)
+	}
+
+	// A flood is cut into batches of at most maxEventBatch.
+	flood := make(chan watcher.Event, maxEventBatch+1)
+	for range maxEventBatch + 1 {
+		flood <- claudeLog("x")
+	}
+	if n := len(batchTexts(t, pollBatch(t, flood))); n != maxEventBatch {
+		t.Errorf("first batch has %d events, want %d", n, maxEventBatch)
+	}
+	if n := len(batchTexts(t, pollBatch(t, flood))); n != 1 {
+		t.Errorf("second batch has %d events, want 1", n)
+	}
+}
+
+func TestHandleEvent_LogsAtEventTime(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	m := claimModel(t, t.TempDir(), watcher.TrackedIssue{Repo: "o/r", Number: 1, Status: watcher.StatusClaudeRunning}, at)
+	for _, ev := range []watcher.Event{claudeLog("a"), {Kind: watcher.EventCheckpoint, Repo: "o/r", IssueNum: 1, Text: "b"}} {
+		ev.Timestamp = at
+		m.handleEvent(ev)
+	}
+	logs := m.logs["o/r#1"]
+	if len(logs) != 2 {
+		t.Fatalf("logged %d lines, want 2", len(logs))
+	}
+	for _, l := range logs {
+		if !l.at.Equal(at) {
+			t.Errorf("%q logged at %v, want the event's %v", l.text, l.at, at)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// notifier tells the user about issues becoming ready or failing when
+// lurker lives in a background pane.
+type notifier struct {
+	bell bool        // ring the terminal bell
+	osc9 bool        // send an OSC 9 desktop notification (iTerm2, kitty, WezTerm…)
+	tmux *tmuxStatus // publishes counts in the tmux @lurker user option; nil for none
+
+	out io.Writer // the program's terminal; see terminal
+}
+
+// terminal is the terminal the program draws on, shared with the
+// notifications so neither writes in the middle of the other. It is still
+// an *os.File, so the program sees a TTY.
+type terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// Output returns the writer m's program must draw with
+// (tea.WithOutput), so that notifications don't land mid-frame.
+func (m Model) Output() io.Writer {
+	return m.notifier.out
+}
+
+// notify emits the configured notifications for one issue transition.
+func (n notifier) notify(key, what string) {
+	if n.out == nil {
+		return
+	}
+	var seq string
+	if n.bell {
+		seq += "\a"
+	}
+	if n.osc9 {
+		seq += "\x1b]9;" + oscText(fmt.Sprintf("lurker: %s %s", key, what)) + "\x07"
+	}
+	if seq != "" {
+		io.WriteString(n.out, seq)
+	}
+}
+
+// oscText makes s safe to send in an OSC payload, which a BEL or ST
+// would end: control characters, C0, DEL and C1 alike, become spaces.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r >= 0x7f && r <= 0x9f {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// updateTmux sets the global tmux user option @lurker to a short summary,
+// e.g. "2 ready · 1 failed". Reference it from status-right with
+// #{@lurker}. Does nothing outside tmux.
+func (n notifier) updateTmux(ready, failed int) {
+	if n.tmux == nil || os.Getenv("TMUX") == "" {
+		return
+	}
+	var parts []string
+	if ready > 0 {
+		parts = append(parts, fmt.Sprintf("%d ready", ready))
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	n.tmux.publish(strings.Join(parts, " · "))
+}
+
+// tmuxStatus publishes summaries to tmux one at a time, off the UI
+// goroutine. Summaries published while one is being set are coalesced,
+// so the last one is always what tmux ends up showing.
+type tmuxStatus struct {
+	set func(summary string) // runs the tmux commands
+
+	mu      sync.Mutex
+	next    *string // the summary to set next, if any
+	running bool    // a goroutine is setting summaries
+}
+
+func newTmuxStatus() *tmuxStatus {
+	return &tmuxStatus{set: func(summary string) {
+		exec.Command("tmux", "set-option", "-gq", "@lurker", summary).Run()
+		exec.Command("tmux", "refresh-client", "-S").Run()
+	}}
+}
+
+// publish has summary set once the summaries before it are.
+func (t *tmuxStatus) publish(summary string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next = &summary
+	if t.running {
+		return
+	}
+	t.running = true
+	go func() {
+		for {
+			t.mu.Lock()
+			next := t.next
+			t.next = nil
+			if next == nil {
+				t.running = false
+			}
+			t.mu.Unlock()
+			if next == nil {
+				return
+			}
+			t.set(*next)
+		}
+	}()
+}
+
+// notifyTransition is called when an issue becomes ready, fails or stops
+// with questions, and when its PR's CI passes or fails.
+func (m *Model) notifyTransition(ev watcher.Event, what string) {
+	m.notifier.notify(issueKey(ev.Repo, ev.IssueNum), what)
+	m.notifier.updateTmux(m.countByStatus(watcher.StatusReady), m.countByStatus(watcher.StatusFailed))
+}
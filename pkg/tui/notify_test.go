@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	var out strings.Builder
+	n := notifier{bell: true, osc9: true, out: &out}
+	n.notify("o/r#1", "failed: \x07bell\x1b]esc\x7fdel\u009cst\u0085nel")
+	want := "\a\x1b]9;lurker: o/r#1 failed:  bell ]esc del st nel\x07"
+	if out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	notifier{out: &out}.notify("o/r#1", "ready")
+	if out.Len() != 0 {
+		t.Errorf("wrote %q with nothing turned on", out.String())
+	}
+}
+
+func TestTmuxStatus(t *testing.T) {
+	var mu sync.Mutex
+	var set []string
+	running := 0
+	release := make(chan struct{})
+	s := &tmuxStatus{set: func(summary string) {
+		mu.Lock()
+		if running++; running > 1 {
+			t.Error("tmux commands ran at once")
+		}
+		set = append(set, summary)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}}
+
+	// While the first is being set, the rest coalesce into the last.
+	s.publish("1 ready")
+	for _, summary := range []string{"2 ready", "2 ready · 1 failed", "1 failed"} {
+		s.publish(summary)
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		idle := !s.running
+		s.mu.Unlock()
+		if idle || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(set) == 0 || set[len(set)-1] != "1 failed" {
+		t.Errorf("set %q, want the last to be %q", set, "1 failed")
+	}
+	if len(set) > 2 {
+		t.Errorf("set %q: the summaries published meanwhile weren't coalesced", set)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseOrgPattern recognizes the add-repo prompt's "owner/*" form, which
+// watches every repo of an organization or user, optionally narrowed with
+// "topic:NAME". A leading "-" stops discovering.
+func parseOrgPattern(value string) (owner, topic string, remove, ok bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", "", false, false
+	}
+	pattern, remove := strings.CutPrefix(fields[0], "-")
+	owner, found := strings.CutSuffix(pattern, "/*")
+	if !found || owner == "" {
+		return "", "", false, false
+	}
+	if len(fields) == 2 {
+		if topic, found = strings.CutPrefix(fields[1], "topic:"); !found || topic == "" || remove {
+			return "", "", false, false
+		}
+	}
+	return owner, topic, remove, true
+}
+
+// addOrg starts or stops discovering owner's repos.
+func (m *Model) addOrg(owner, topic string, remove bool) {
+	var err error
+	switch {
+	case remove:
+		err = m.manager.RemoveOrg(owner)
+		m.discoverStatus = fmt.Sprintf("%s/*: no longer discovering", owner)
+	case topic != "":
+		err = m.manager.AddOrg(owner, topic)
+		m.discoverStatus = fmt.Sprintf("%s/*: discovering repos tagged %s…", owner, topic)
+	default:
+		err = m.manager.AddOrg(owner, topic)
+		m.discoverStatus = fmt.Sprintf("%s/*: discovering repos…", owner)
+	}
+	if err != nil {
+		m.discoverStatus = err.Error()
+	}
+}
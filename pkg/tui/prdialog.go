@@ -0,0 +1,491 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// prField is a field of the PR dialog, in tab order.
+type prField int
+
+const (
+	prFieldTitle prField = iota
+	prFieldBody
+	prFieldBase
+	prFieldDraft
+	prFieldSummary
+	prFieldReviewers
+	prFieldLabels
+	prFieldAssignees
+	prFieldCount
+)
+
+// prDialog is the PR creation dialog opened with `a`.
+type prDialog struct {
+	repo    string
+	num     int
+	workdir string
+	head    string
+
+	title     textinput.Model
+	body      textarea.Model
+	reviewers textinput.Model
+	labels    textinput.Model
+	assignees textinput.Model
+	bases     []string
+	base      int
+	draft     bool
+	summary   bool
+	cost      float64
+	templates watcher.Templates // for the summary comment
+
+	field    prField
+	loading  bool
+	byClaude bool // Claude is writing the title and body (claude_prs)
+}
+
+type prDraftMsg struct {
+	repo  string
+	num   int
+	draft watcher.PRDraft
+	err   error
+}
+
+// submitPR pushes the branch and opens the PR, reporting the outcome. A
+// PR the branch already had is reported as existed, a push rejected as
+// non-fast-forward as rejected.
+func submitPR(forge watcher.Forge, repo string, num int, workdir string, spec watcher.PRSpec) prResultMsg {
+	pr, warnings, err := watcher.OpenPR(context.Background(), forge, repo, num, workdir, spec)
+	if exists := (*github.PRExistsError)(nil); errors.As(err, &exists) {
+		return prResultMsg{repo: repo, issueNum: num, prNum: exists.PR.Number, url: exists.PR.HTMLURL, existed: true}
+	}
+	if rejected := (*watcher.PushRejectedError)(nil); errors.As(err, &rejected) {
+		return prResultMsg{repo: repo, issueNum: num, err: err, rejected: rejected, workdir: workdir, spec: spec}
+	}
+	if err != nil {
+		return prResultMsg{repo: repo, issueNum: num, err: err}
+	}
+	return prResultMsg{repo: repo, issueNum: num, prNum: pr.Number, url: pr.HTMLURL, warnings: warnings}
+}
+
+// openPRDialog shows the PR dialog for iss and loads its defaults.
+func (m *Model) openPRDialog(iss *watcher.TrackedIssue) tea.Cmd {
+	if iss == nil || iss.Workdir == "" {
+		return nil
+	}
+	if perms := m.ghClient.Permissions(); !perms.AllowPush || !perms.AllowPRCreate {
+		m.appendLog(issueKey(iss.Repo, iss.Number), "🔒 Pushing and PR creation are disabled by permissions")
+		return nil
+	}
+	base := m.manager.BaseBranch(iss.Repo, iss.Workdir)
+	cfg := m.manager.RepoConfig(iss.Workdir)
+
+	newInput := func(placeholder string) textinput.Model {
+		ti := textinput.New()
+		ti.Placeholder = placeholder
+		ti.Width = 56
+		ti.Prompt = ""
+		return ti
+	}
+	d := &prDialog{
+		repo:      iss.Repo,
+		num:       iss.Number,
+		workdir:   iss.Workdir,
+		title:     newInput("title"),
+		body:      textarea.New(),
+		reviewers: newInput("alice, bob"),
+		labels:    newInput("bug, needs-review"),
+		assignees: newInput("carol"),
+		bases:     []string{base},
+		summary:   cfg.PRSummary && m.ghClient.Permissions().AllowComments,
+		draft:     cfg.DraftPRs,
+		cost:      iss.CostUSD,
+		templates: cfg.Templates,
+		loading:   true,
+		byClaude:  cfg.ClaudePRs,
+	}
+	d.title.CharLimit = 256
+	d.body.SetWidth(64)
+	d.body.SetHeight(8)
+	d.body.ShowLineNumbers = false
+	d.body.CharLimit = 0
+
+	m.prDialog = d
+	m.focus = focusPR
+
+	repo, num, workdir, title, box := iss.Repo, iss.Number, iss.Workdir, iss.Title, m.manager.Bwrap()
+	return tea.Batch(d.focusField(prFieldTitle), func() tea.Msg {
+		draft, err := watcher.LoadPRDraft(box, workdir, num, title, base, cfg)
+		return prDraftMsg{repo: repo, num: num, draft: draft, err: err}
+	})
+}
+
+func (m *Model) handlePRDraft(msg prDraftMsg) {
+	d := m.prDialog
+	if d == nil || d.repo != msg.repo || d.num != msg.num {
+		return
+	}
+	if msg.err != nil {
+		m.appendLog(issueKey(msg.repo, msg.num), "❌ "+msg.err.Error())
+		m.closePRDialog()
+		return
+	}
+	for _, w := range msg.draft.Warnings {
+		m.appendLog(issueKey(msg.repo, msg.num), "⚠ "+w)
+	}
+	d.loading = false
+	d.head = msg.draft.Head
+	d.bases = msg.draft.Bases
+	d.title.SetValue(msg.draft.Title)
+	d.title.CursorEnd()
+	d.body.SetValue(msg.draft.Body)
+	d.reviewers.SetValue(strings.Join(msg.draft.Reviewers, ", "))
+	d.labels.SetValue(strings.Join(msg.draft.Labels, ", "))
+	d.assignees.SetValue(strings.Join(msg.draft.Assignees, ", "))
+}
+
+func (m *Model) closePRDialog() {
+	m.prDialog = nil
+	if m.focusIssue != nil {
+		m.focus = focusFocus
+	} else {
+		m.focus = focusList
+	}
+}
+
+// focusField moves keyboard focus to field f.
+func (d *prDialog) focusField(f prField) tea.Cmd {
+	d.field = f
+	d.title.Blur()
+	d.body.Blur()
+	d.reviewers.Blur()
+	d.labels.Blur()
+	d.assignees.Blur()
+	switch f {
+	case prFieldTitle:
+		return d.title.Focus()
+	case prFieldBody:
+		return d.body.Focus()
+	case prFieldReviewers:
+		return d.reviewers.Focus()
+	case prFieldLabels:
+		return d.labels.Focus()
+	case prFieldAssignees:
+		return d.assignees.Focus()
+	}
+	return nil
+}
+
+// update forwards a message to the focused text field.
+func (d *prDialog) update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch d.field {
+	case prFieldTitle:
+		d.title, cmd = d.title.Update(msg)
+	case prFieldBody:
+		d.body, cmd = d.body.Update(msg)
+	case prFieldReviewers:
+		d.reviewers, cmd = d.reviewers.Update(msg)
+	case prFieldLabels:
+		d.labels, cmd = d.labels.Update(msg)
+	case prFieldAssignees:
+		d.assignees, cmd = d.assignees.Update(msg)
+	}
+	return cmd
+}
+
+func (m *Model) handlePRDialogKey(msg tea.KeyMsg) tea.Cmd {
+	d := m.prDialog
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		m.closePRDialog()
+		return nil
+	case "ctrl+s":
+		return m.submitPRDialog()
+	case "tab":
+		return d.focusField((d.field + 1) % prFieldCount)
+	case "shift+tab":
+		return d.focusField((d.field + prFieldCount - 1) % prFieldCount)
+	case "enter":
+		if d.field != prFieldBody {
+			return d.focusField((d.field + 1) % prFieldCount)
+		}
+	}
+
+	switch d.field {
+	case prFieldBase:
+		switch msg.String() {
+		case "left", "h":
+			d.base = (d.base + len(d.bases) - 1) % len(d.bases)
+		case "right", "l", " ":
+			d.base = (d.base + 1) % len(d.bases)
+		}
+		return nil
+	case prFieldDraft:
+		switch msg.String() {
+		case " ", "left", "right", "h", "l", "x":
+			d.draft = !d.draft
+		}
+		return nil
+	case prFieldSummary:
+		switch msg.String() {
+		case " ", "left", "right", "h", "l", "x":
+			d.summary = !d.summary
+		}
+		return nil
+	}
+	return d.update(msg)
+}
+
+func (m *Model) submitPRDialog() tea.Cmd {
+	d := m.prDialog
+	if d.loading {
+		return nil
+	}
+	spec := watcher.PRSpec{
+		Head:      d.head,
+		Title:     strings.TrimSpace(d.title.Value()),
+		Body:      d.body.Value(),
+		Base:      d.bases[d.base],
+		Draft:     d.draft,
+		Reviewers: splitList(d.reviewers.Value()),
+		Labels:    splitList(d.labels.Value()),
+		Assignees: splitList(d.assignees.Value()),
+		Summary:   d.summary,
+		CostUSD:   d.cost,
+		Templates: d.templates,
+	}
+	if spec.Title == "" {
+		return nil
+	}
+	m.closePRDialog()
+
+	repo, num, workdir, forge := d.repo, d.num, d.workdir, m.manager.Forge()
+	key := issueKey(repo, num)
+	m.appendLog(key, "")
+	if spec.Draft {
+		m.appendLog(key, "🚀 Pushing branch & creating draft PR into "+spec.Base+"...")
+	} else {
+		m.appendLog(key, "🚀 Pushing branch & creating PR into "+spec.Base+"...")
+	}
+	return func() tea.Msg {
+		res := submitPR(forge, repo, num, workdir, spec)
+		if res.existed || res.rejected != nil {
+			res.title, res.body = spec.Title, spec.Body
+		}
+		return res
+	}
+}
+
+// prEditedMsg reports a PR's title and body updated, or not.
+type prEditedMsg struct {
+	repo  string
+	num   int
+	prNum int
+	err   error
+}
+
+// offerPRUpdate asks whether the PR that res found already open should
+// take the title and body from the PR dialog, if it came from there and
+// nothing else has the keyboard.
+func (m *Model) offerPRUpdate(res prResultMsg) {
+	if res.title == "" || (m.focus != focusList && m.focus != focusFocus) {
+		return
+	}
+	m.prExists = &res
+	m.prExistsFrom = m.focus
+	m.focus = focusPRExist
+}
+
+func (m *Model) handlePRExistsKey(key string) tea.Cmd {
+	res := m.prExists
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "u", "y":
+		m.closePRExists()
+		m.appendLog(issueKey(res.repo, res.issueNum), fmt.Sprintf("✏️ Updating PR #%d's title and body...", res.prNum))
+		forge := m.manager.Forge()
+		return func() tea.Msg {
+			err := watcher.UpdatePR(context.Background(), forge, res.repo, res.prNum, res.title, res.body)
+			return prEditedMsg{repo: res.repo, num: res.issueNum, prNum: res.prNum, err: err}
+		}
+	case "n", "esc":
+		m.closePRExists()
+	}
+	return nil
+}
+
+func (m *Model) closePRExists() {
+	m.prExists = nil
+	m.focus = m.prExistsFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+// offerForcePush asks whether to force-push over the push res had
+// rejected, if the repo's config allows it and nothing else has the
+// keyboard.
+func (m *Model) offerForcePush(res prResultMsg) {
+	key := issueKey(res.repo, res.issueNum)
+	if !m.manager.RepoConfig(res.workdir).ForcePush {
+		m.appendLog(key, `ℹ Set "force_push": true in .lurker/config.json to be offered a force-push`)
+		return
+	}
+	if m.focus != focusList && m.focus != focusFocus {
+		return
+	}
+	m.forcePush = &res
+	m.forcePushFrom = m.focus
+	m.focus = focusForce
+}
+
+func (m *Model) handleForcePushKey(key string) tea.Cmd {
+	res := m.forcePush
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "y":
+		m.closeForcePush()
+		r := res.rejected
+		m.appendLog(issueKey(res.repo, res.issueNum), fmt.Sprintf("⚠ Force-pushing %s over %s on %s...", r.Branch, shortSHA(r.SHA), r.Remote))
+		mgr, forge := m.manager, m.manager.Forge()
+		return func() tea.Msg {
+			if err := mgr.ForcePush(res.repo, res.issueNum, r); err != nil {
+				return prResultMsg{repo: res.repo, issueNum: res.issueNum, err: err}
+			}
+			next := submitPR(forge, res.repo, res.issueNum, res.workdir, res.spec)
+			if next.existed {
+				next.title, next.body = res.title, res.body
+			}
+			return next
+		}
+	case "n", "esc":
+		m.closeForcePush()
+	}
+	return nil
+}
+
+func (m *Model) closeForcePush() {
+	m.forcePush = nil
+	m.focus = m.forcePushFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+func (m *Model) handlePREdited(msg prEditedMsg) {
+	key := issueKey(msg.repo, msg.num)
+	if msg.err != nil {
+		m.appendLog(key, "❌ Updating the PR: "+msg.err.Error())
+		return
+	}
+	m.appendLog(key, fmt.Sprintf("✏️ Updated PR #%d's title and body", msg.prNum))
+}
+
+// splitList parses a comma- or space-separated list, dropping blanks and
+// leading @s.
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if f = strings.TrimPrefix(f, "@"); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (m Model) renderPRDialog() string {
+	d := m.prDialog
+	label := func(f prField, text string) string {
+		if d.field == f {
+			return dialogTitleStyle.Render(text)
+		}
+		return dialogLabelStyle.Render(text)
+	}
+
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render(fmt.Sprintf("Create PR — %s #%d", d.repo, d.num)))
+	if d.head != "" {
+		b.WriteString(headerDimStyle.Render("  from " + d.head))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(label(prFieldTitle, "Title      "))
+	b.WriteString(d.title.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(label(prFieldBody, "Body"))
+	b.WriteString("\n")
+	if d.loading && d.byClaude {
+		b.WriteString(headerDimStyle.Render("Claude is writing the description…"))
+	} else if d.loading {
+		b.WriteString(headerDimStyle.Render("loading…"))
+	} else {
+		b.WriteString(d.body.View())
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(label(prFieldBase, "Base       "))
+	base := d.bases[d.base]
+	if d.field == prFieldBase && len(d.bases) > 1 {
+		base = "◂ " + base + " ▸"
+	}
+	b.WriteString(base)
+	b.WriteString("\n")
+
+	b.WriteString(label(prFieldDraft, "Draft      "))
+	if d.draft {
+		b.WriteString("[x]")
+	} else {
+		b.WriteString("[ ]")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(label(prFieldSummary, "Summary    "))
+	if d.summary {
+		b.WriteString("[x]")
+	} else {
+		b.WriteString("[ ]")
+	}
+	b.WriteString(headerDimStyle.Render("  comment with the run summary"))
+	b.WriteString("\n")
+
+	b.WriteString(label(prFieldReviewers, "Reviewers  "))
+	b.WriteString(d.reviewers.View())
+	b.WriteString("\n")
+
+	b.WriteString(label(prFieldLabels, "Labels     "))
+	b.WriteString(d.labels.View())
+	b.WriteString("\n")
+
+	b.WriteString(label(prFieldAssignees, "Assignees  "))
+	b.WriteString(d.assignees.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(fmtHelp("tab", "next") + "  " + fmtHelp("ctrl+s", "create") + "  " + fmtHelp("esc", "cancel"))
+
+	dialog := dialogStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
+}
+
+// shortSHA abbreviates a commit hash for display.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
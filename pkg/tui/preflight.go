@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// preflightDialog is the checklist shown by A and D before the branch is
+// pushed.
+type preflightDialog struct {
+	repo   string
+	num    int
+	draft  bool
+	base   string
+	checks watcher.Preflight // nil while they run
+	from   focus
+}
+
+type preflightMsg struct {
+	repo   string
+	num    int
+	checks watcher.Preflight
+}
+
+// preflightFor runs the preflight checks for iss in the background and
+// shows them; once they pass, y pushes and opens the PR.
+func (m *Model) preflightFor(iss *watcher.TrackedIssue, draft bool) tea.Cmd {
+	if iss == nil || iss.Workdir == "" {
+		return nil
+	}
+	if perms := m.ghClient.Permissions(); !perms.AllowPush || !perms.AllowPRCreate {
+		m.appendLog(issueKey(iss.Repo, iss.Number), "🔒 Pushing and PR creation are disabled by permissions")
+		return nil
+	}
+	repo, num, workdir := iss.Repo, iss.Number, iss.Workdir
+	base := m.manager.BaseBranch(repo, workdir)
+	cfg := m.manager.RepoConfig(workdir)
+	m.preflight = &preflightDialog{repo: repo, num: num, draft: draft || cfg.DraftPRs, base: base, from: m.focus}
+	m.focus = focusChecks
+	return func() tea.Msg {
+		return preflightMsg{repo: repo, num: num, checks: watcher.RunPreflight(context.Background(), workdir, base, cfg)}
+	}
+}
+
+func (m *Model) handlePreflight(msg preflightMsg) {
+	d := m.preflight
+	if d == nil || d.repo != msg.repo || d.num != msg.num {
+		return
+	}
+	d.checks = msg.checks
+	if err := msg.checks.Err(); err != nil {
+		m.appendLog(issueKey(msg.repo, msg.num), "🛑 "+err.Error())
+	}
+}
+
+func (m *Model) handlePreflightKey(key string) tea.Cmd {
+	d := m.preflight
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "y", "enter":
+		if d.checks == nil || !d.checks.OK() {
+			return nil
+		}
+		m.closePreflight()
+		return m.pushPRFor(m.findIssue(d.repo, d.num), d.draft, false)
+	case "n", "esc", "q":
+		m.closePreflight()
+	}
+	return nil
+}
+
+func (m *Model) closePreflight() {
+	m.focus = m.preflight.from
+	m.preflight = nil
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+func (m Model) renderPreflightDialog() string {
+	d := m.preflight
+	what := "PR"
+	if d.draft {
+		what = "draft PR"
+	}
+	var b strings.Builder
+	b.WriteString(dialogTitleStyle.Render(fmt.Sprintf("Open a %s for %s #%d into %s?", what, d.repo, d.num, d.base)))
+	b.WriteString("\n\n")
+	if d.checks == nil {
+		b.WriteString(headerDimStyle.Render("Checking the branch… (the tests may take a while)"))
+		b.WriteString("\n\n")
+		b.WriteString(fmtHelp("esc", "cancel"))
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialogStyle.Render(b.String()))
+	}
+	for _, c := range d.checks {
+		switch {
+		case c.Skipped:
+			b.WriteString(headerDimStyle.Render("– " + c.Name + "  skipped"))
+		case c.OK:
+			b.WriteString(statusReadyStyle.Render("✓ ") + c.Name)
+		default:
+			b.WriteString(statusFailedStyle.Render("✗ " + c.Name))
+		}
+		if c.Detail != "" && !c.Skipped {
+			b.WriteString(headerDimStyle.Render("  " + truncate(c.Detail, 60)))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if d.checks.OK() {
+		b.WriteString(fmtHelp("y", "push & open PR") + "  " + fmtHelp("n/esc", "cancel"))
+	} else {
+		b.WriteString(headerDimStyle.Render("Nothing was pushed. Fix the failures, then try again."))
+		b.WriteString("\n\n")
+		b.WriteString(fmtHelp("esc", "close"))
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialogStyle.Render(b.String()))
+}
@@ -1,12 +1,16 @@
 package tui
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -14,6 +18,8 @@ import (
 
 	"github.com/creack/pty"
 	"golang.org/x/term"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
 )
 
 // ptySession holds a PTY with a shell that backs an issue's entire lifecycle.
@@ -27,29 +33,44 @@ type ptySession struct {
 	sink  io.Writer // stdout when attached, io.Discard when detached
 	done  bool
 
-	// Marker-based command completion detection
-	markerMu  sync.Mutex
-	pendingID string   // current marker ID we're watching for
-	pendingCh chan int // receives exit code when marker is found
-	scanBuf   []byte  // accumulates output for marker scanning
+	key     string       // issue key
+	backend shellBackend // what runs in the PTY
+
+	attached bool
+	lastUsed time.Time // last command, attach or detach; see ptyPool
+
+	// runMu lets one command at a time into the shell; running is set
+	// (under mu) while it runs
+	runMu   sync.Mutex
+	running bool
+
+	// Recent raw output, kept for the focus view's shell tab
+	scrollback []byte
 }
 
-// newPtySession creates a PTY with a shell running in workdir.
-func newPtySession(workdir string) (*ptySession, error) {
+// maxScrollback bounds how much PTY output is retained per session.
+const maxScrollback = 256 * 1024
+
+// newPtySession creates a PTY for key's shell, started by argv in workdir
+// or re-attached through backend.
+func newPtySession(key, workdir string, backend shellBackend, argv []string) (*ptySession, error) {
 	ptmx, slave, err := pty.Open()
 	if err != nil {
 		return nil, err
 	}
 
 	s := &ptySession{
-		ptmx:  ptmx,
-		slave: slave,
-		sink:  io.Discard,
+		key:      key,
+		backend:  backend,
+		ptmx:     ptmx,
+		slave:    slave,
+		sink:     io.Discard,
+		lastUsed: time.Now(),
 	}
 
 	go s.drain()
 
-	if err := s.startShell(workdir); err != nil {
+	if err := s.startShell(workdir, argv); err != nil {
 		ptmx.Close()
 		slave.Close()
 		return nil, err
@@ -58,7 +79,7 @@ func newPtySession(workdir string) (*ptySession, error) {
 	return s, nil
 }
 
-func (s *ptySession) startShell(workdir string) error {
+func (s *ptySession) startShell(workdir string, argv []string) error {
 	s.mu.Lock()
 	if s.cmd != nil {
 		s.mu.Unlock()
@@ -66,17 +87,10 @@ func (s *ptySession) startShell(workdir string) error {
 	}
 	s.mu.Unlock()
 
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = "zsh"
-	}
-
-	cmd := exec.Command(shell)
-	cmd.Dir = workdir
+	cmd := s.backend.command(s.key, workdir, argv)
 	cmd.Stdin = s.slave
 	cmd.Stdout = s.slave
 	cmd.Stderr = s.slave
-	cmd.Env = os.Environ()
 
 	if err := cmd.Start(); err != nil {
 		return err
@@ -99,87 +113,107 @@ func (s *ptySession) startShell(workdir string) error {
 	return nil
 }
 
+// commandResult is what a command run in the shell produced.
+type commandResult struct {
+	Code   int    // exit code; -1 if the command didn't finish
+	Output string // stdout and stderr, interleaved
+}
+
 // RunCommand writes a command to the shell and waits for it to complete.
-// Uses a marker pattern to detect completion and extract the exit code.
 // Implements watcher.IssuePTY.
 func (s *ptySession) RunCommand(ctx context.Context, cmd string) (int, error) {
-	id := fmt.Sprintf("%d", time.Now().UnixNano())
-	marker := fmt.Sprintf("__LURKER_DONE_%s_", id)
-	resultCh := make(chan int, 1)
-
-	s.markerMu.Lock()
-	s.pendingID = id
-	s.pendingCh = resultCh
-	s.scanBuf = nil
-	s.markerMu.Unlock()
+	res, err := s.run(ctx, cmd, 0)
+	return res.Code, err
+}
 
-	defer func() {
-		s.markerMu.Lock()
-		s.pendingID = ""
-		s.pendingCh = nil
-		s.scanBuf = nil
-		s.markerMu.Unlock()
+// run writes cmd to the shell and waits up to timeout (0 for no limit) for
+// it to finish. The command still shows in the shell, and its output is
+// also teed to a file and returned — what there is of it if the command
+// timed out or ctx was cancelled, in which case it is interrupted.
+//
+// Completion isn't read off the terminal, where prompts, right prompts,
+// PROMPT_COMMAND hooks and a tmux or screen client's redraws all land:
+// the shell writes the exit code to a FIFO made for this command alone.
+// The command runs in a subshell, so a cd in it doesn't stick.
+func (s *ptySession) run(ctx context.Context, cmd string, timeout time.Duration) (commandResult, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.setRunning(true)
+	defer s.setRunning(false)
+
+	dir, err := os.MkdirTemp("", "lurker-run-")
+	if err != nil {
+		return commandResult{Code: -1}, err
+	}
+	defer os.RemoveAll(dir)
+	fifo := filepath.Join(dir, "done")
+	out := filepath.Join(dir, "out")
+	status := filepath.Join(dir, "status")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		return commandResult{Code: -1}, fmt.Errorf("mkfifo: %w", err)
+	}
+	// Opened read-write, the FIFO neither blocks here waiting for the
+	// shell nor reads EOF between writers; closing it ends the read.
+	done, err := os.OpenFile(fifo, os.O_RDWR, 0)
+	if err != nil {
+		return commandResult{Code: -1}, err
+	}
+	defer done.Close()
+	codeCh := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(done).ReadString('\n')
+		codeCh <- strings.TrimSpace(line)
 	}()
 
-	// Write command + marker to the shell
-	fullCmd := fmt.Sprintf("%s; echo \"%s$?\"\n", cmd, marker)
-	if _, err := s.ptmx.Write([]byte(fullCmd)); err != nil {
-		return -1, fmt.Errorf("write to pty: %w", err)
+	// The newline after cmd keeps a trailing comment from swallowing the
+	// rest. If cmd exits the subshell early, no status is written and -1
+	// stands in. The writes go quiet once an abandoned command's files are
+	// gone.
+	q := watcher.ShellQuote
+	full := fmt.Sprintf("{ %s\necho $? 2>/dev/null > %s; } 2>&1 | tee %s; { cat %s > %s || echo -1 > %s; } 2>/dev/null\n",
+		cmd, q(status), q(out), q(status), q(fifo), q(fifo))
+	if _, err := s.ptmx.Write([]byte(full)); err != nil {
+		return commandResult{Code: -1}, fmt.Errorf("write to pty: %w", err)
 	}
 
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+	partial := func() commandResult {
+		data, _ := os.ReadFile(out)
+		return commandResult{Code: -1, Output: string(data)}
+	}
 	select {
 	case <-ctx.Done():
 		// Send Ctrl+C to interrupt the running command
 		s.ptmx.Write([]byte{0x03})
-		return -1, ctx.Err()
-	case code := <-resultCh:
-		return code, nil
+		return partial(), ctx.Err()
+	case <-expired:
+		s.ptmx.Write([]byte{0x03})
+		return partial(), fmt.Errorf("timed out after %s", timeout)
+	case line := <-codeCh:
+		res := partial()
+		code, err := strconv.Atoi(line)
+		if err != nil {
+			return res, fmt.Errorf("bad exit status %q", line)
+		}
+		res.Code = code
+		return res, nil
 	}
 }
 
-// checkMarker scans accumulated output for the completion marker.
-// Must be called with markerMu held.
-func (s *ptySession) checkMarker() {
-	if s.pendingID == "" {
-		return
-	}
-
-	marker := fmt.Sprintf("__LURKER_DONE_%s_", s.pendingID)
-	data := string(s.scanBuf)
-
-	// Scan all occurrences — the echo shows "$?" (not digits),
-	// the real output shows the actual exit code (digits).
-	search := data
-	for {
-		idx := strings.Index(search, marker)
-		if idx < 0 {
-			return
-		}
-
-		rest := search[idx+len(marker):]
-
-		// Read digits after marker
-		end := 0
-		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
-			end++
-		}
-
-		if end > 0 {
-			// Found digits — this is the real output, not the echo
-			var code int
-			fmt.Sscanf(rest[:end], "%d", &code)
-			if s.pendingCh != nil {
-				s.pendingCh <- code
-			}
-			return
-		}
-
-		// No digits (this is the echo) — keep scanning
-		search = rest
-	}
+// setRunning records whether a command is running.
+func (s *ptySession) setRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
 }
 
-// drain continuously reads PTY output, forwards to sink, and scans for markers.
+// drain continuously reads PTY output and forwards it to sink.
 func (s *ptySession) drain() {
 	buf := make([]byte, 4096)
 	for {
@@ -190,16 +224,12 @@ func (s *ptySession) drain() {
 
 			s.mu.Lock()
 			w := s.sink
+			s.scrollback = append(s.scrollback, chunk...)
+			if over := len(s.scrollback) - maxScrollback; over > 0 {
+				s.scrollback = s.scrollback[over:]
+			}
 			s.mu.Unlock()
 			w.Write(chunk)
-
-			// Scan for completion markers
-			s.markerMu.Lock()
-			if s.pendingID != "" {
-				s.scanBuf = append(s.scanBuf, chunk...)
-				s.checkMarker()
-			}
-			s.markerMu.Unlock()
 		}
 		if err != nil {
 			return
@@ -207,6 +237,22 @@ func (s *ptySession) drain() {
 	}
 }
 
+// note adds text to the retained output without sending it to the shell.
+func (s *ptySession) note(text string) {
+	s.mu.Lock()
+	s.scrollback = append(s.scrollback, text...)
+	s.mu.Unlock()
+}
+
+// recentOutput returns a copy of the retained PTY output.
+func (s *ptySession) recentOutput() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]byte, len(s.scrollback))
+	copy(out, s.scrollback)
+	return out
+}
+
 func (s *ptySession) isDone() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -216,23 +262,125 @@ func (s *ptySession) isDone() bool {
 func (s *ptySession) attach(w io.Writer) {
 	s.mu.Lock()
 	s.sink = w
+	s.attached = true
+	s.lastUsed = time.Now()
 	s.mu.Unlock()
 }
 
 func (s *ptySession) detach() {
 	s.mu.Lock()
 	s.sink = io.Discard
+	s.attached = false
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
+}
+
+// touch marks the session as just used.
+func (s *ptySession) touch() {
+	s.mu.Lock()
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
+}
+
+// lastUse is when the session was last used.
+func (s *ptySession) lastUse() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastUsed
+}
+
+// busy reports whether the user is attached or a command is running.
+func (s *ptySession) busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attached || s.running
+}
+
+// kill ends the shell for good, along with the tmux or screen session
+// backing it.
+func (s *ptySession) kill() {
+	s.backend.kill(s.key)
+	s.close()
+}
+
+// close hangs up the shell and releases the PTY. A tmux or screen session
+// behind it lives on.
+func (s *ptySession) close() {
+	s.mu.Lock()
+	cmd := s.cmd
 	s.mu.Unlock()
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Signal(syscall.SIGHUP)
+	}
+	s.ptmx.Close()
+	s.slave.Close()
 }
 
 // ptyAttacher implements tea.ExecCommand to attach to a live PTY session.
 // Ctrl+] detaches and returns to the TUI. The session keeps running.
+// Ctrl+^ starts and stops recording what the terminal shows.
 type ptyAttacher struct {
-	session *ptySession
-	label   string // e.g. "owner/repo#42" — shown on attach
-	stdin   io.Reader
-	stdout  io.Writer
-	stderr  io.Writer
+	session    *ptySession
+	label      string // e.g. "owner/repo#42" — shown on attach
+	issueDir   string // where recordings go
+	tracker    lineTracker
+	recorder   recordSink
+	recordings []string // recordings finished while attached
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// recordSink passes output on to a recording while one is running.
+type recordSink struct {
+	mu  sync.Mutex
+	rec *watcher.Recorder
+}
+
+func (r *recordSink) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rec != nil {
+		r.rec.Write(p)
+	}
+	return len(p), nil
+}
+
+// swap replaces the running recording with rec and returns the old one.
+func (r *recordSink) swap(rec *watcher.Recorder) *watcher.Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.rec
+	r.rec = rec
+	return old
+}
+
+// toggleRecording starts recording to the issue's recordings, or stops
+// the recording running, telling the user which.
+func (a *ptyAttacher) toggleRecording(fd int) {
+	if rec := a.recorder.swap(nil); rec != nil {
+		if err := rec.Close(); err != nil {
+			fmt.Fprintf(a.stdout, "\r\n── recording failed: %v ──\r\n", err)
+			return
+		}
+		a.recordings = append(a.recordings, rec.Path())
+		fmt.Fprintf(a.stdout, "\r\n── recording saved: %s ──\r\n", rec.Path())
+		return
+	}
+	cols, rows, err := term.GetSize(fd)
+	if err != nil {
+		cols, rows = 80, 24
+	}
+	rec, err := watcher.StartRecording(a.issueDir, cols, rows, a.label)
+	if err != nil {
+		fmt.Fprintf(a.stdout, "\r\n── can't record: %v ──\r\n", err)
+		return
+	}
+	fmt.Fprintf(a.stdout, "\r\n── recording (Ctrl+^ to stop) ──\r\n")
+	// Start from the line the shell is on, likely its prompt.
+	out := a.session.recentOutput()
+	rec.Write(out[bytes.LastIndexByte(out, '\n')+1:])
+	a.recorder.swap(rec)
 }
 
 func (a *ptyAttacher) SetStdin(r io.Reader)  { a.stdin = r }
@@ -281,15 +429,34 @@ func (a *ptyAttacher) Run() error {
 		}
 	}()
 
-	s.attach(a.stdout)
+	// Start the tracker on the line the shell is on, which likely holds
+	// its prompt.
+	out := s.recentOutput()
+	a.tracker.Write(out[bytes.LastIndexByte(out, '\n')+1:])
+	s.attach(io.MultiWriter(a.stdout, &a.tracker, &a.recorder))
 	defer s.detach()
+	defer func() {
+		if a.recorder.rec != nil {
+			a.toggleRecording(fd)
+		}
+	}()
 
-	// Print banner so the user knows which issue's PTY they connected to
+	// Print banner so the user knows which issue's PTY they connected to.
+	// It goes into the session's scrollback too, which marks where each
+	// visit starts and ends in the shell tab.
 	if a.label != "" {
-		a.stdout.Write([]byte(fmt.Sprintf("\r\n── attached: %s (Ctrl+] to detach) ──\r\n", a.label)))
+		banner := fmt.Sprintf("\r\n── attached: %s (Ctrl+] to detach, Ctrl+^ to record) ──\r\n", a.label)
+		a.stdout.Write([]byte(banner))
+		s.note(banner)
+		defer func() {
+			banner := fmt.Sprintf("\r\n── detached: %s ──\r\n", a.label)
+			a.stdout.Write([]byte(banner))
+			s.note(banner)
+		}()
 	}
 
-	// Forward stdin → PTY, scanning for Ctrl+] (0x1d) to detach
+	// Forward stdin → PTY, scanning for Ctrl+] (0x1d) to detach and
+	// Ctrl+^ (0x1e) to toggle recording
 	buf := make([]byte, 4096)
 	for {
 		n, err := a.stdin.Read(buf)
@@ -297,21 +464,30 @@ func (a *ptyAttacher) Run() error {
 			return nil
 		}
 
-		for i := 0; i < n; i++ {
-			if buf[i] == 0x1d {
-				if i > 0 {
-					s.ptmx.Write(buf[:i])
+		in := buf[:n]
+		for len(in) > 0 {
+			i := bytes.IndexAny(in, "\x1d\x1e")
+			chunk := in
+			if i >= 0 {
+				chunk = in[:i]
+			}
+			if len(chunk) > 0 {
+				a.tracker.expectEnter(bytes.Count(chunk, []byte{'\r'}))
+				if s.isDone() {
+					return nil
 				}
+				if _, err := s.ptmx.Write(chunk); err != nil {
+					return nil
+				}
+			}
+			if i < 0 {
+				break
+			}
+			if in[i] == 0x1d {
 				return nil // detach
 			}
-		}
-
-		if s.isDone() {
-			return nil
-		}
-
-		if _, err := s.ptmx.Write(buf[:n]); err != nil {
-			return nil
+			a.toggleRecording(fd)
+			in = in[i+1:]
 		}
 	}
 }
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// reapEvery is how often idle shells are looked for.
+const reapEvery = time.Minute
+
+// ptyPool owns the issues' shell sessions. Shells left unused for idle are
+// closed, at most max are open at once, and a closed shell is started
+// again the next time its issue needs one.
+type ptyPool struct {
+	sessions map[string]*ptySession
+	backend  shellBackend
+	idle     time.Duration  // close shells unused this long; 0 keeps them
+	max      int            // most shells open at once; 0 for no limit
+	rcDir    string         // where shells' rc files are; "" for the user's own
+	box      *watcher.Bwrap // sandbox the shells run in; nil for none
+	shellrc  string         // the user's shellrc, which a sandbox must let them read
+	lastReap time.Time
+}
+
+func newPtyPool(backend shellBackend, idle time.Duration, max int, rcDir string, box *watcher.Bwrap, shellrc string) *ptyPool {
+	return &ptyPool{
+		sessions: make(map[string]*ptySession),
+		backend:  backend,
+		idle:     idle,
+		max:      max,
+		rcDir:    rcDir,
+		box:      box,
+		shellrc:  shellrc,
+	}
+}
+
+// get returns key's session, or nil. It doesn't count as a use.
+func (p *ptyPool) get(key string) *ptySession {
+	return p.sessions[key]
+}
+
+// open returns key's shell, starting one in workdir if it has none or its
+// shell exited. A new shell reads the rc files in rcDir unless profile asks
+// for the user's own, and runs in p.box's sandbox if set. At the cap, the
+// least recently used shell that isn't busy and whose issue isn't inUse is
+// closed to make room; evicted names it.
+func (p *ptyPool) open(key, workdir string, profile bool, inUse func(key string) bool) (s *ptySession, created bool, evicted string, err error) {
+	if s := p.sessions[key]; s != nil && !s.isDone() {
+		s.touch()
+		return s, false, "", nil
+	}
+	if s := p.sessions[key]; s != nil {
+		// The client exited; a session the user detached from with the
+		// backend's own keys is re-attached below.
+		s.close()
+		delete(p.sessions, key)
+	}
+	if p.max > 0 && len(p.sessions) >= p.max {
+		evicted = p.leastRecentlyUsed(inUse)
+		if evicted == "" {
+			return nil, false, "", fmt.Errorf("all %d shells are in use", p.max)
+		}
+		p.close(evicted)
+	}
+	rcDir := p.rcDir
+	if profile {
+		rcDir = ""
+	}
+	argv := shellArgv(userShell(), rcDir)
+	if p.box != nil {
+		argv = p.box.Argv(workdir, argv, rcDir, p.shellrc)
+	}
+	s, err = newPtySession(key, workdir, p.backend, argv)
+	if err != nil {
+		return nil, false, evicted, err
+	}
+	p.sessions[key] = s
+	return s, true, evicted, nil
+}
+
+// leastRecentlyUsed returns the key of the longest-unused shell that can
+// be closed, or "".
+func (p *ptyPool) leastRecentlyUsed(inUse func(key string) bool) string {
+	var oldest string
+	var oldestAt time.Time
+	for key, s := range p.sessions {
+		if s.busy() || inUse(key) {
+			continue
+		}
+		if at := s.lastUse(); oldest == "" || at.Before(oldestAt) {
+			oldest, oldestAt = key, at
+		}
+	}
+	return oldest
+}
+
+// close ends key's shell, if it has one.
+func (p *ptyPool) close(key string) {
+	if s := p.sessions[key]; s != nil {
+		s.kill()
+		delete(p.sessions, key)
+	}
+}
+
+// reap closes the shells that exited or have been unused for p.idle,
+// sparing busy ones and those whose issue is inUse, and returns their
+// keys, sorted. It looks at most once per reapEvery.
+func (p *ptyPool) reap(now time.Time, inUse func(key string) bool) []string {
+	if p.idle <= 0 || now.Sub(p.lastReap) < reapEvery {
+		return nil
+	}
+	p.lastReap = now
+	var reaped []string
+	for key, s := range p.sessions {
+		if !s.isDone() && (s.busy() || inUse(key) || now.Sub(s.lastUse()) < p.idle) {
+			continue
+		}
+		p.close(key)
+		reaped = append(reaped, key)
+	}
+	slices.Sort(reaped)
+	return reaped
+}
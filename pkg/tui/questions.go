@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// maxQuestionLines is how much of an issue's questions the tree shows
+// beneath it; the logs tab has all of them.
+const maxQuestionLines = 3
+
+// questionLines returns the lines of iss's questions shown in the tree.
+func questionLines(iss watcher.TrackedIssue) []string {
+	if iss.Status != watcher.StatusNeedsInput || iss.Questions == "" {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(iss.Questions, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if len(lines) == maxQuestionLines {
+			lines[len(lines)-1] += " …"
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// openReply opens the footer input for answering iss's questions, or the
+// prompt for allowing the tools it was refused.
+func (m *Model) openReply(iss *watcher.TrackedIssue) tea.Cmd {
+	m.replyFrom = m.focus
+	if reqs := watcher.ReadToolRequests(watcher.IssueDir(m.manager.BaseDir(), iss.Repo, iss.Number)); len(reqs) > 0 {
+		m.approving = issueKey(iss.Repo, iss.Number)
+		m.approvingRules = watcher.ToolRules(reqs)
+		m.focus = focusApprove
+		return nil
+	}
+	m.replyTo = issueKey(iss.Repo, iss.Number)
+	m.textInput.Reset()
+	m.textInput.Placeholder = "your answer"
+	m.textInput.CharLimit = 0
+	m.focus = focusInput
+	return m.textInput.Focus()
+}
+
+// submitReply resumes the issue being answered with answer.
+func (m *Model) submitReply(answer string) {
+	repo, num := parseIssueKey(m.replyTo)
+	m.closeReply()
+	iss := m.findIssue(repo, num)
+	if answer == "" || iss == nil || iss.Status != watcher.StatusNeedsInput {
+		return
+	}
+	key := issueKey(repo, num)
+	m.ensurePtySession(key, m.ptyWorkdir(iss))
+	m.manager.AnswerIssue(repo, num, answer)
+	m.syncStatus(iss)
+	iss.Questions = ""
+	iss.Error = ""
+	iss.StartedAt = time.Now()
+	m.appendLog(key, "💬 Answered: "+answer)
+	m.saveIssueMeta(iss)
+}
+
+func (m *Model) closeReply() {
+	m.replyTo = ""
+	m.textInput.Reset()
+	m.textInput.Placeholder = "owner/repo or owner/*"
+	m.textInput.CharLimit = 100
+	m.textInput.Blur()
+	m.focus = m.replyFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+// submitApproval answers the tool requests of the issue being approved
+// and resumes it.
+func (m *Model) submitApproval(approval watcher.Approval) {
+	repo, num := parseIssueKey(m.approving)
+	rules := strings.Join(m.approvingRules, ", ")
+	m.closeApproval()
+	iss := m.findIssue(repo, num)
+	if iss == nil || iss.Status != watcher.StatusNeedsInput {
+		return
+	}
+	key := issueKey(repo, num)
+	m.ensurePtySession(key, m.ptyWorkdir(iss))
+	m.manager.ResolveToolRequests(repo, num, approval)
+	m.syncStatus(iss)
+	iss.Questions = ""
+	iss.Error = ""
+	iss.StartedAt = time.Now()
+	switch approval {
+	case watcher.ApprovalOnce:
+		m.appendLog(key, "🔐 Allowed for this run: "+rules)
+	case watcher.ApprovalAlways:
+		m.appendLog(key, "🔐 Allowed for every run in "+repo+": "+rules)
+	default:
+		m.appendLog(key, "🔐 Denied: "+rules)
+	}
+	m.saveIssueMeta(iss)
+}
+
+func (m *Model) closeApproval() {
+	m.approving = ""
+	m.approvingRules = nil
+	m.focus = m.replyFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
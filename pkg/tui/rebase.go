@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// branchUpdateMsg reports an issue's branch brought up to date with its
+// base, or not.
+type branchUpdateMsg struct {
+	repo   string
+	num    int
+	update watcher.BranchUpdate
+	err    error
+	macro  bool // a macro's rebase step, which continues the macro
+}
+
+// updateBranchFor rebases iss's branch onto the latest base branch (or
+// has the forge merge it in, per update_method) in the background.
+func (m *Model) updateBranchFor(iss *watcher.TrackedIssue, macro bool) tea.Cmd {
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	switch {
+	case iss.Workdir == "":
+		m.appendLog(key, "⚠ No worktree to update yet")
+		return nil
+	case isActive(iss.Status):
+		m.appendLog(key, "⏸ Pause the issue before updating its branch")
+		return nil
+	}
+	m.appendLog(key, "🔀 Updating the branch from its base...")
+	mgr, repo, num := m.manager, iss.Repo, iss.Number
+	return func() tea.Msg {
+		u, err := mgr.UpdateBranch(repo, num)
+		return branchUpdateMsg{repo: repo, num: num, update: u, err: err, macro: macro}
+	}
+}
+
+// handleBranchUpdate logs a branch update and flags the issue if it
+// conflicted. A conflicting issue whose branch now updates cleanly is
+// ready again, and a PR whose branch was pushed has its checks watched.
+func (m *Model) handleBranchUpdate(msg branchUpdateMsg) tea.Cmd {
+	key := issueKey(msg.repo, msg.num)
+	iss := m.findIssue(msg.repo, msg.num)
+	err := msg.err
+	switch {
+	case iss == nil:
+	case err != nil:
+		m.appendLog(key, "❌ Updating the branch: "+err.Error())
+	case len(msg.update.Conflicts) > 0:
+		err = errors.New(msg.update.String())
+		m.changeIssueStatus(iss.Repo, iss.Number, watcher.StatusConflict)
+		iss.Error = msg.update.String()
+		m.appendLog(key, "✗ "+msg.update.String())
+		m.saveIssueMeta(iss)
+	default:
+		m.appendLog(key, "🔀 "+msg.update.String())
+		if iss.Status == watcher.StatusConflict {
+			m.changeIssueStatus(iss.Repo, iss.Number, watcher.StatusReady)
+			iss.Error = ""
+		}
+		if msg.update.Pushed && iss.PRNumber > 0 {
+			iss.Checks = watcher.ChecksPending
+			m.manager.WatchChecks(iss.Repo, iss.Number, iss.PRNumber)
+			m.appendLog(key, "🔄 Watching CI checks...")
+		}
+		m.saveIssueMeta(iss)
+	}
+	if !msg.macro {
+		return nil
+	}
+	return m.handleMacroStep(macroStepMsg{step: "rebase", err: err})
+}
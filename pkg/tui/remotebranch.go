@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// branchDeletedMsg reports an issue's branch deleted from its remote, or
+// not.
+type branchDeletedMsg struct {
+	repo    string
+	num     int
+	deleted bool // false if the remote didn't have it
+	err     error
+}
+
+// deleteBranchFor asks whether to delete iss's branch from its remote,
+// abandoning its PR if it has one open.
+func (m *Model) deleteBranchFor(iss *watcher.TrackedIssue) {
+	if iss == nil {
+		return
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	switch {
+	case !m.ghClient.Permissions().AllowPush:
+		m.appendLog(key, "🔒 Pushing is disabled by permissions")
+		return
+	case isActive(iss.Status):
+		m.appendLog(key, "⏸ Pause the issue before deleting its branch")
+		return
+	}
+	m.delBranch = iss
+	m.delBranchFrom = m.focus
+	m.focus = focusDelete
+}
+
+func (m *Model) handleDeleteBranchKey(key string) tea.Cmd {
+	iss := m.delBranch
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "y":
+		m.closeDeleteBranch()
+		m.appendLog(issueKey(iss.Repo, iss.Number), "🧹 Deleting "+watcher.IssueBranch(iss.Number)+" from its remote...")
+		mgr, repo, num := m.manager, iss.Repo, iss.Number
+		return func() tea.Msg {
+			deleted, err := mgr.DeleteRemoteBranch(repo, num)
+			return branchDeletedMsg{repo: repo, num: num, deleted: deleted, err: err}
+		}
+	case "n", "esc":
+		m.closeDeleteBranch()
+	}
+	return nil
+}
+
+func (m *Model) closeDeleteBranch() {
+	m.delBranch = nil
+	m.focus = m.delBranchFrom
+	if m.focus == focusFocus && m.focusIssue == nil {
+		m.focus = focusList
+	}
+}
+
+func (m *Model) handleBranchDeleted(msg branchDeletedMsg) {
+	key := issueKey(msg.repo, msg.num)
+	branch := watcher.IssueBranch(msg.num)
+	switch {
+	case msg.err != nil:
+		m.appendLog(key, fmt.Sprintf("❌ Deleting %s: %v", branch, msg.err))
+	case msg.deleted:
+		m.appendLog(key, "🧹 Deleted "+branch+" from its remote")
+	default:
+		m.appendLog(key, "ℹ "+branch+" wasn't on its remote")
+	}
+}
@@ -0,0 +1,344 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// reviewsState is the review-requests view (V): open PRs awaiting the
+// user's review, with the drafts Claude writes for them.
+type reviewsState struct {
+	loading  bool
+	requests []github.ReviewRequest
+	err      error
+	notice   string // outcome of the last draft or submission
+	cursor   int
+	scroll   int
+	drafting map[string]bool // issueKey → Claude is drafting its review
+	preview  []string        // draft of the selected PR, if any
+}
+
+// reviewRequestsMsg delivers the PRs awaiting review.
+type reviewRequestsMsg struct {
+	requests []github.ReviewRequest
+	err      error
+}
+
+// reviewDraftedMsg reports a finished draft.
+type reviewDraftedMsg struct {
+	repo string
+	num  int
+	err  error
+}
+
+// reviewEditedMsg is sent when the editor on a draft exits.
+type reviewEditedMsg struct{ err error }
+
+// reviewSubmittedMsg reports a submitted review.
+type reviewSubmittedMsg struct {
+	repo  string
+	num   int
+	event string
+	err   error
+}
+
+// openReviews shows the review requests, fetching them afresh.
+func (m *Model) openReviews() tea.Cmd {
+	m.focus = focusReviews
+	if m.reviews.drafting == nil {
+		m.reviews.drafting = make(map[string]bool)
+	}
+	return m.fetchReviewRequests()
+}
+
+func (m *Model) fetchReviewRequests() tea.Cmd {
+	m.reviews.loading = true
+	m.reviews.err = nil
+	ghClient := m.ghClient
+	return func() tea.Msg {
+		requests, err := ghClient.ListReviewRequests(context.Background())
+		return reviewRequestsMsg{requests: requests, err: err}
+	}
+}
+
+func (m *Model) handleReviewRequests(msg reviewRequestsMsg) {
+	m.reviews.loading = false
+	m.reviews.err = msg.err
+	if msg.err == nil {
+		m.reviews.requests = msg.requests
+	}
+	m.reviews.cursor = min(m.reviews.cursor, max(len(m.reviews.requests)-1, 0))
+	m.ensureReviewCursorVisible()
+	m.loadReviewPreview()
+}
+
+// selectedReview returns the PR under the cursor.
+func (m *Model) selectedReview() *github.ReviewRequest {
+	if m.reviews.cursor < len(m.reviews.requests) {
+		return &m.reviews.requests[m.reviews.cursor]
+	}
+	return nil
+}
+
+// loadReviewPreview reads the selected PR's draft for the preview pane.
+func (m *Model) loadReviewPreview() {
+	m.reviews.preview = nil
+	pr := m.selectedReview()
+	if pr == nil {
+		return
+	}
+	if path := watcher.ReviewDraftPath(m.manager.BaseDir(), pr.Repo, pr.Number); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			m.reviews.preview = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		}
+	}
+}
+
+// draftReview has Claude draft a review of pr in the background.
+func (m *Model) draftReview(pr *github.ReviewRequest) tea.Cmd {
+	if pr == nil {
+		return nil
+	}
+	key := issueKey(pr.Repo, pr.Number)
+	if m.reviews.drafting[key] {
+		return nil
+	}
+	m.reviews.drafting[key] = true
+	m.reviews.notice = "Drafting a review of " + key + "…"
+	baseDir, ghClient, req, format, box := m.manager.BaseDir(), m.ghClient, *pr, m.manager.StreamFormat(), m.manager.Bwrap()
+	return func() tea.Msg {
+		_, err := watcher.DraftReview(context.Background(), box, baseDir, ghClient, req, format)
+		return reviewDraftedMsg{repo: req.Repo, num: req.Number, err: err}
+	}
+}
+
+func (m *Model) handleReviewDrafted(msg reviewDraftedMsg) {
+	key := issueKey(msg.repo, msg.num)
+	delete(m.reviews.drafting, key)
+	if msg.err != nil {
+		m.reviews.notice = fmt.Sprintf("❌ Draft of %s failed: %v", key, msg.err)
+	} else {
+		m.reviews.notice = "✅ Drafted a review of " + key + " — e to edit, a to submit"
+	}
+	m.loadReviewPreview()
+}
+
+// editReview opens pr's draft in $EDITOR, starting one from scratch if
+// Claude hasn't drafted it.
+func (m *Model) editReview(pr *github.ReviewRequest) tea.Cmd {
+	if pr == nil {
+		return nil
+	}
+	path := watcher.ReviewDraftPath(m.manager.BaseDir(), pr.Repo, pr.Number)
+	if path == "" {
+		dir := watcher.ReviewDir(m.manager.BaseDir(), pr.Repo, pr.Number)
+		path = filepath.Join(dir, watcher.ReviewFile)
+		err := os.MkdirAll(dir, 0o755)
+		if err == nil {
+			err = os.WriteFile(path, []byte("verdict: comment\n\n"), 0o644)
+		}
+		if err != nil {
+			m.reviews.notice = "❌ " + err.Error()
+			return nil
+		}
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	c := exec.Command("sh", "-c", editor+" "+watcher.ShellQuote(path))
+	return tea.ExecProcess(c, func(err error) tea.Msg { return reviewEditedMsg{err: err} })
+}
+
+func (m *Model) handleReviewEdited(msg reviewEditedMsg) {
+	if msg.err != nil {
+		m.reviews.notice = "❌ Editor: " + msg.err.Error()
+	}
+	m.loadReviewPreview()
+}
+
+// submitReview submits pr's draft, as an approval if approve is set.
+func (m *Model) submitReview(pr *github.ReviewRequest, approve bool) tea.Cmd {
+	if pr == nil {
+		return nil
+	}
+	if !m.ghClient.Permissions().AllowComments {
+		m.reviews.notice = "🔒 Reviews are disabled by permissions"
+		return nil
+	}
+	event := ""
+	if approve {
+		event = github.ReviewEventApprove
+	}
+	key := issueKey(pr.Repo, pr.Number)
+	if approve && watcher.ReviewDraftPath(m.manager.BaseDir(), pr.Repo, pr.Number) == "" {
+		// Approving needs no words; give it an empty draft to submit.
+		dir := watcher.ReviewDir(m.manager.BaseDir(), pr.Repo, pr.Number)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, watcher.ReviewFile), []byte("verdict: approve\n"), 0o644)
+	}
+	m.reviews.notice = "🚀 Submitting the review of " + key + "…"
+	baseDir, ghClient, repo, num := m.manager.BaseDir(), m.ghClient, pr.Repo, pr.Number
+	return func() tea.Msg {
+		err := watcher.SubmitReview(context.Background(), ghClient, baseDir, repo, num, event)
+		return reviewSubmittedMsg{repo: repo, num: num, event: event, err: err}
+	}
+}
+
+func (m *Model) handleReviewSubmitted(msg reviewSubmittedMsg) {
+	key := issueKey(msg.repo, msg.num)
+	if msg.err != nil {
+		m.reviews.notice = fmt.Sprintf("❌ Review of %s: %v", key, msg.err)
+		return
+	}
+	if msg.event == github.ReviewEventApprove {
+		m.reviews.notice = "✅ Approved " + key
+	} else {
+		m.reviews.notice = "✅ Submitted the review of " + key
+	}
+	for i, pr := range m.reviews.requests {
+		if pr.Repo == msg.repo && pr.Number == msg.num {
+			m.reviews.requests = append(m.reviews.requests[:i], m.reviews.requests[i+1:]...)
+			break
+		}
+	}
+	m.reviews.cursor = min(m.reviews.cursor, max(len(m.reviews.requests)-1, 0))
+	m.ensureReviewCursorVisible()
+	m.loadReviewPreview()
+}
+
+// handleReviewsKey drives the review-requests view.
+func (m *Model) handleReviewsKey(key string) tea.Cmd {
+	pr := m.selectedReview()
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q":
+		m.focus = focusList
+		return nil
+	case "j", "down":
+		if m.reviews.cursor < len(m.reviews.requests)-1 {
+			m.reviews.cursor++
+		}
+	case "k", "up":
+		if m.reviews.cursor > 0 {
+			m.reviews.cursor--
+		}
+	case "G":
+		m.reviews.cursor = max(len(m.reviews.requests)-1, 0)
+	case "r":
+		return m.fetchReviewRequests()
+	case "d":
+		return m.draftReview(pr)
+	case "e", "enter":
+		return m.editReview(pr)
+	case "a":
+		return m.submitReview(pr, false)
+	case "A":
+		return m.submitReview(pr, true)
+	case "o":
+		if pr != nil {
+			openURL(pr.URL)
+		}
+		return nil
+	default:
+		return nil
+	}
+	m.ensureReviewCursorVisible()
+	m.loadReviewPreview()
+	return nil
+}
+
+// reviewListLines is how many PRs the list shows; the draft preview gets
+// the rest of the screen.
+func (m *Model) reviewListLines() int {
+	return max((m.height-5)/3, 1) // header, status, 2 separators, footer
+}
+
+func (m *Model) ensureReviewCursorVisible() {
+	visible := m.reviewListLines()
+	if m.reviews.cursor < m.reviews.scroll {
+		m.reviews.scroll = m.reviews.cursor
+	}
+	if m.reviews.cursor >= m.reviews.scroll+visible {
+		m.reviews.scroll = m.reviews.cursor - visible + 1
+	}
+}
+
+func (m Model) renderReviewsView() string {
+	var b strings.Builder
+	b.WriteString(m.renderHeader())
+	b.WriteString("\n")
+
+	var status string
+	switch {
+	case m.reviews.err != nil:
+		status = statusFailedStyle.Render(m.reviews.err.Error())
+	case m.reviews.loading:
+		status = headerDimStyle.Render("loading…")
+	case m.reviews.notice != "":
+		status = m.reviews.notice
+	default:
+		status = headerDimStyle.Render(fmt.Sprintf("%d pull requests", len(m.reviews.requests)))
+	}
+	b.WriteString(clipLine(" "+dialogLabelStyle.Render("Review requests")+"  "+status, m.width))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+	b.WriteString("\n")
+
+	visible := m.reviewListLines()
+	end := min(m.reviews.scroll+visible, len(m.reviews.requests))
+	for i := m.reviews.scroll; i < end; i++ {
+		pr := m.reviews.requests[i]
+		key := issueKey(pr.Repo, pr.Number)
+		var state string
+		switch {
+		case m.reviews.drafting[key]:
+			state = statusRunningStyle.Render("drafting…")
+		case watcher.ReviewDraftPath(m.manager.BaseDir(), pr.Repo, pr.Number) != "":
+			state = statusReadyStyle.Render("drafted")
+		}
+		line := fmt.Sprintf(" %s  %s  %s  %s  %s",
+			headerDimStyle.Render(fmt.Sprintf("%-12s", formatStamp(pr.UpdatedAt, m.now, m.relativeTimes))),
+			repoNameStyle.Render(key),
+			pr.Title,
+			headerDimStyle.Render("@"+pr.Author),
+			state)
+		if i == m.reviews.cursor {
+			line = selectedRowStyle.Render(padOrTruncate(line, m.width))
+		} else {
+			line = clipLine(line, m.width)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for i := end - m.reviews.scroll; i < visible; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+	b.WriteString("\n")
+	previewLines := max(m.height-5-visible, 0)
+	preview := m.reviews.preview
+	if preview == nil && m.selectedReview() != nil {
+		preview = []string{headerDimStyle.Render("No draft yet — d to have Claude draft one, e to write your own, A to approve")}
+	}
+	for i := 0; i < previewLines; i++ {
+		if i < len(preview) {
+			b.WriteString(clipLine(" "+preview[i], m.width))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(" " + clipLine(helpLineReviews(), m.width-1))
+	return b.String()
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// maxSearchResults caps how many matches the results view keeps.
+const maxSearchResults = 500
+
+// searchState is the global log/transcript search: the query being
+// typed in the footer, then its results in a full-screen list.
+type searchState struct {
+	typing  bool // the footer input holds a query
+	jumped  bool // esc in the focus view returns to the results
+	query   string
+	running bool
+	results []watcher.SearchMatch
+	err     error
+	cursor  int
+	scroll  int
+}
+
+// searchResultMsg delivers the matches of a search run in the background.
+type searchResultMsg struct {
+	query   string
+	results []watcher.SearchMatch
+	err     error
+}
+
+// openSearch opens the footer input for a search query, prefilled with
+// the previous one.
+func (m *Model) openSearch() tea.Cmd {
+	m.search.typing = true
+	m.textInput.Reset()
+	m.textInput.Placeholder = "config.yaml repo:owner/name #42 since:30d until:2026-03-01"
+	m.textInput.CharLimit = 0
+	m.textInput.SetValue(m.search.query)
+	m.textInput.CursorEnd()
+	m.focus = focusInput
+	return m.textInput.Focus()
+}
+
+// submitSearch runs query across every issue's logs and transcripts.
+func (m *Model) submitSearch(query string) tea.Cmd {
+	m.search.typing = false
+	m.closeRepoInput()
+	if query == "" {
+		return nil
+	}
+	m.search = searchState{query: query, running: true}
+	m.focus = focusSearch
+	q, err := watcher.ParseSearchQuery(query, time.Now())
+	if err != nil {
+		m.search.running = false
+		m.search.err = err
+		return nil
+	}
+	q.Limit = maxSearchResults
+	baseDir := m.manager.BaseDir()
+	return func() tea.Msg {
+		results, err := watcher.Search(baseDir, q)
+		return searchResultMsg{query: query, results: results, err: err}
+	}
+}
+
+func (m *Model) cancelSearch() {
+	m.search.typing = false
+	m.closeRepoInput()
+}
+
+func (m *Model) handleSearchResult(msg searchResultMsg) {
+	if msg.query != m.search.query {
+		return
+	}
+	m.search.running = false
+	m.search.results = msg.results
+	m.search.err = msg.err
+}
+
+// handleSearchKey drives the results view.
+func (m *Model) handleSearchKey(key string) tea.Cmd {
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q":
+		m.focus = focusList
+	case "/":
+		return m.openSearch()
+	case "j", "down":
+		if m.search.cursor < len(m.search.results)-1 {
+			m.search.cursor++
+		}
+	case "k", "up":
+		if m.search.cursor > 0 {
+			m.search.cursor--
+		}
+	case "G":
+		m.search.cursor = max(len(m.search.results)-1, 0)
+	case "enter", "l", "f":
+		if m.search.cursor < len(m.search.results) {
+			return m.jumpToMatch(m.search.results[m.search.cursor])
+		}
+	}
+	m.ensureSearchCursorVisible()
+	return nil
+}
+
+// jumpToMatch opens the focus view of the match's issue on the tab the
+// line came from, scrolled to it.
+func (m *Model) jumpToMatch(match watcher.SearchMatch) tea.Cmd {
+	iss := m.findIssue(match.Repo, match.Number)
+	if iss == nil {
+		m.search.err = fmt.Errorf("%s#%d is no longer tracked", match.Repo, match.Number)
+		return nil
+	}
+	m.search.err = nil
+	m.search.jumped = true
+	m.enterFocus(iss)
+	if match.Source == "transcript" {
+		m.focusTab = tabTranscript
+		m.focusScroll = 0
+		m.tabSeek = match.Line
+		return m.loadFocusTab()
+	}
+	m.logView = logViewAll
+	for i, line := range m.logs[issueKey(iss.Repo, iss.Number)] {
+		if line.at.Equal(match.At) && strings.TrimSpace(line.text) == match.Line {
+			m.focusScroll = i
+			m.clampFocusScroll()
+			break
+		}
+	}
+	return nil
+}
+
+// seekTabLine scrolls a freshly loaded tab to the line a search jumped to.
+func (m *Model) seekTabLine() {
+	if m.tabSeek == "" {
+		return
+	}
+	for i, line := range m.tabLines {
+		if line == m.tabSeek {
+			m.focusScroll = i
+			break
+		}
+	}
+	m.tabSeek = ""
+}
+
+// searchVisibleLines is how many results fit between header and footer.
+func (m *Model) searchVisibleLines() int {
+	return max(m.height-4, 1) // header(1) + status(1) + sep(1) + footer(1)
+}
+
+func (m *Model) ensureSearchCursorVisible() {
+	visible := m.searchVisibleLines()
+	if m.search.cursor < m.search.scroll {
+		m.search.scroll = m.search.cursor
+	}
+	if m.search.cursor >= m.search.scroll+visible {
+		m.search.scroll = m.search.cursor - visible + 1
+	}
+}
+
+func (m Model) renderSearchView() string {
+	var b strings.Builder
+	b.WriteString(m.renderHeader())
+	b.WriteString("\n")
+
+	var status string
+	switch {
+	case m.search.err != nil:
+		status = statusFailedStyle.Render(m.search.err.Error())
+	case m.search.running:
+		status = headerDimStyle.Render("searching…")
+	case len(m.search.results) == maxSearchResults:
+		status = headerDimStyle.Render(fmt.Sprintf("newest %d matches", maxSearchResults))
+	default:
+		status = headerDimStyle.Render(fmt.Sprintf("%d matches", len(m.search.results)))
+	}
+	b.WriteString(clipLine(" "+dialogLabelStyle.Render("/"+m.search.query)+"  "+status, m.width))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+	b.WriteString("\n")
+
+	visible := m.searchVisibleLines()
+	end := min(m.search.scroll+visible, len(m.search.results))
+	for i := m.search.scroll; i < end; i++ {
+		r := m.search.results[i]
+		stamp := fmt.Sprintf("%-12s", formatStamp(r.At, m.now, m.relativeTimes))
+		source := "log"
+		if r.Source == "transcript" {
+			source = "tx "
+		}
+		line := fmt.Sprintf(" %s  %s  %s  %s",
+			headerDimStyle.Render(stamp),
+			repoNameStyle.Render(fmt.Sprintf("%s#%d", r.Repo, r.Number)),
+			headerDimStyle.Render(source),
+			r.Line)
+		if i == m.search.cursor {
+			line = selectedRowStyle.Render(padOrTruncate(line, m.width))
+		} else {
+			line = clipLine(line, m.width)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for i := end - m.search.scroll; i < visible; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(" " + clipLine(helpLineSearch(), m.width-1))
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// ShellBackends lists the values Options.ShellBackend accepts.
+var ShellBackends = []string{"pty", "tmux", "screen"}
+
+// shellBackend decides what runs in an issue's PTY. "pty" runs the shell
+// itself, which dies with lurker. "tmux" and "screen" run a client of a
+// session named after the issue, created on first use and re-attached
+// after that, so the shell — and whatever it is running — outlives lurker
+// and the next run picks it up again.
+type shellBackend string
+
+func parseShellBackend(name string) (shellBackend, error) {
+	if name == "" {
+		return "pty", nil
+	}
+	if !slices.Contains(ShellBackends, name) {
+		return "", fmt.Errorf("unknown shell backend %q (want %s)", name, strings.Join(ShellBackends, ", "))
+	}
+	return shellBackend(name), nil
+}
+
+// sessionName is the tmux/screen session that backs key's shell, e.g.
+// "lurker_owner_repo_42": both tools reserve some punctuation in names.
+func sessionName(key string) string {
+	return "lurker_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// command returns the command that gives key a shell, started by argv, in
+// workdir. A session that already exists is re-attached and keeps its own
+// directory and shell.
+func (b shellBackend) command(key, workdir string, argv []string) *exec.Cmd {
+	var cmd *exec.Cmd
+	switch b {
+	case "tmux":
+		cmd = exec.Command("tmux", append([]string{"new-session", "-A", "-s", sessionName(key)}, argv...)...)
+	case "screen":
+		cmd = exec.Command("screen", append([]string{"-D", "-R", "-S", sessionName(key)}, argv...)...)
+	default:
+		cmd = exec.Command(argv[0], argv[1:]...)
+	}
+	cmd.Dir = workdir
+	// lurker may itself run inside tmux or screen; its clients refuse to
+	// nest unless told they aren't.
+	cmd.Env = slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return b != "pty" && (strings.HasPrefix(kv, "TMUX=") || strings.HasPrefix(kv, "STY="))
+	})
+	return cmd
+}
+
+// kill ends key's session for good. Hanging up a tmux or screen client
+// only detaches it.
+func (b shellBackend) kill(key string) {
+	switch b {
+	case "tmux":
+		exec.Command("tmux", "kill-session", "-t", "="+sessionName(key)).Run()
+	case "screen":
+		exec.Command("screen", "-S", sessionName(key), "-X", "quit").Run()
+	}
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shellRCDir is where lurker keeps the rc files its issue shells start
+// from, under BaseDir.
+const shellRCDir = ".shell"
+
+// shellRCName is the file in the config directory (see watcher.Layout)
+// that issue shells source after lurker's own settings, for the aliases
+// and PATH its commands do need.
+const shellRCName = "shellrc"
+
+// profileSuffix marks the pool key of an issue's second shell, the one
+// started with the user's own profile when Options.AttachProfile is set.
+const profileSuffix = " (profile)"
+
+// userShell is the user's login shell, or zsh.
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "zsh"
+}
+
+// writeShellRC writes the rc files issue shells start from into
+// baseDir/.shell and returns that directory. One POSIX file serves bash,
+// zsh and sh alike: a fixed prompt, no prompt hooks, then the user's
+// shellrc in configDir if there is one.
+func writeShellRC(baseDir, configDir string) (string, error) {
+	dir := filepath.Join(baseDir, shellRCDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	rc := fmt.Sprintf(`# Written by lurker on every start; edit %[1]s instead.
+# Issue shells read this rather than your own rc files, so prompt themes,
+# aliases and autocorrection can't get in the way of lurker's commands.
+PS1='lurker$ '
+PS2='> '
+unset PROMPT_COMMAND RPROMPT RPS1 ENV ZDOTDIR
+[ -r '%[1]s' ] && . '%[1]s'
+`, strings.ReplaceAll(filepath.Join(configDir, shellRCName), "'", `'\''`))
+	for _, name := range []string{"bashrc", ".zshrc", "shrc"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(rc), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// shellArgv returns the command line that starts shell from the rc files
+// in rcDir instead of the user's. An empty rcDir means the user's own
+// profile. Shells lurker doesn't know get ENV, which any POSIX sh reads.
+func shellArgv(shell, rcDir string) []string {
+	if rcDir == "" {
+		return []string{shell}
+	}
+	switch filepath.Base(shell) {
+	case "bash":
+		return []string{shell, "--noprofile", "--rcfile", filepath.Join(rcDir, "bashrc")}
+	case "zsh":
+		// -d skips the global rc files except /etc/zshenv; ZDOTDIR points
+		// the per-user ones at lurker's.
+		return []string{"env", "ZDOTDIR=" + rcDir, shell, "-d"}
+	case "fish":
+		return []string{shell, "--no-config", "--init-command", "function fish_prompt; echo 'lurker> '; end"}
+	default:
+		return []string{"env", "ENV=" + filepath.Join(rcDir, "shrc"), shell}
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// squashMsg reports an issue's branch squashed into one commit, or not.
+type squashMsg struct {
+	repo   string
+	num    int
+	squash watcher.Squash
+	err    error
+	macro  bool // a macro's squash step, which continues the macro
+}
+
+// squashFor squashes iss's branch into a single commit, with the repo's
+// squash_message, in the background.
+func (m *Model) squashFor(iss *watcher.TrackedIssue, macro bool) tea.Cmd {
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+	switch {
+	case iss.Workdir == "":
+		m.appendLog(key, "⚠ No worktree to squash yet")
+		return nil
+	case isActive(iss.Status):
+		m.appendLog(key, "⏸ Pause the issue before squashing its branch")
+		return nil
+	}
+	m.appendLog(key, "🗜 Squashing the branch...")
+	mgr, repo, num, title, labels := m.manager, iss.Repo, iss.Number, iss.Title, iss.Labels
+	return func() tea.Msg {
+		s, err := mgr.SquashBranch(repo, num, title, labels)
+		return squashMsg{repo: repo, num: num, squash: s, err: err, macro: macro}
+	}
+}
+
+// handleSquash logs a squash; a PR whose branch was force-pushed has its
+// checks watched again.
+func (m *Model) handleSquash(msg squashMsg) tea.Cmd {
+	key := issueKey(msg.repo, msg.num)
+	if msg.err != nil {
+		m.appendLog(key, "❌ Squashing the branch: "+msg.err.Error())
+	} else {
+		m.appendLog(key, "🗜 "+msg.squash.String())
+		if iss := m.findIssue(msg.repo, msg.num); iss != nil && msg.squash.Pushed && iss.PRNumber > 0 {
+			iss.Checks = watcher.ChecksPending
+			m.manager.WatchChecks(iss.Repo, iss.Number, iss.PRNumber)
+			m.appendLog(key, "🔄 Watching CI checks...")
+			m.saveIssueMeta(iss)
+		}
+	}
+	if !msg.macro {
+		return nil
+	}
+	return m.handleMacroStep(macroStepMsg{step: "squash", err: msg.err})
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// statsSpans are the spans of days the stats view cycles through.
+var statsSpans = []int{7, 30, 90}
+
+// statsChartHeight is how many rows the runs-per-day chart is tall.
+const statsChartHeight = 6
+
+// statsState is the usage stats view (U): what the runs recorded in
+// watcher.StatsFile add up to, charted. It reads only that file.
+type statsState struct {
+	span   int // index into statsSpans
+	runs   []watcher.RunStat
+	err    error
+	scroll int
+}
+
+// openStats shows the usage stats, reading the runs afresh.
+func (m *Model) openStats() {
+	m.focus = focusStats
+	m.stats.scroll = 0
+	m.loadStats()
+}
+
+func (m *Model) loadStats() {
+	m.stats.runs, m.stats.err = watcher.LoadStats(m.manager.Layout())
+}
+
+// handleStatsKey drives the usage stats view.
+func (m *Model) handleStatsKey(key string) tea.Cmd {
+	switch key {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q", "U":
+		m.focus = focusList
+	case "j", "down":
+		m.stats.scroll++
+	case "k", "up":
+		m.stats.scroll = max(m.stats.scroll-1, 0)
+	case "]":
+		m.stats.span = (m.stats.span + 1) % len(statsSpans)
+	case "[":
+		m.stats.span = (m.stats.span + len(statsSpans) - 1) % len(statsSpans)
+	case "r":
+		m.loadStats()
+	}
+	return nil
+}
+
+// statsBodyLines is how many lines the stats view has for its charts.
+func (m Model) statsBodyLines() int {
+	return max(m.height-4, 1) // header, title, separator, footer
+}
+
+func (m Model) renderStatsView() string {
+	days := statsSpans[m.stats.span]
+	s := watcher.SummarizeStats(m.stats.runs, m.now, days)
+
+	var b strings.Builder
+	b.WriteString(m.renderHeader())
+	b.WriteString("\n")
+
+	status := headerDimStyle.Render(fmt.Sprintf("last %d days", days))
+	switch {
+	case m.stats.err != nil:
+		status += "  " + statusFailedStyle.Render(m.stats.err.Error())
+	case s.Runs > 0:
+		status += headerDimStyle.Render(fmt.Sprintf(" · %d runs · %.0f%% ready · %s running · median %s",
+			s.Runs, 100*s.SuccessRate(), statsDuration(s.Total), statsDuration(s.Median)))
+	}
+	if !m.manager.Stats() {
+		status += "  " + statusPausedStyle.Render("not recording — start lurker with --stats")
+	}
+	b.WriteString(clipLine(" "+dialogLabelStyle.Render("Usage stats")+"  "+status, m.width))
+	b.WriteString("\n")
+	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+	b.WriteString("\n")
+
+	lines := m.statsLines(s)
+	visible := m.statsBodyLines()
+	scroll := min(m.stats.scroll, max(len(lines)-visible, 0))
+	for i := 0; i < visible; i++ {
+		if scroll+i < len(lines) {
+			b.WriteString(clipLine(lines[scroll+i], m.width))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(" " + clipLine(helpLineStats(), m.width-1))
+	return b.String()
+}
+
+// statsLines charts s: runs per day, how runs ended, how long each phase
+// takes, and the busiest repos.
+func (m Model) statsLines(s watcher.StatsSummary) []string {
+	if s.Runs == 0 {
+		return []string{"", " " + headerDimStyle.Render("No runs recorded in this span.")}
+	}
+	var lines []string
+	section := func(title string) {
+		lines = append(lines, "", " "+dialogLabelStyle.Render(title))
+	}
+	barWidth := max(m.width/3, 10)
+
+	section("Runs per day")
+	lines = append(lines, dayChart(s.Days, m.width-4)...)
+
+	section("Outcomes")
+	type outcome struct {
+		name string
+		n    int
+	}
+	var outcomes []outcome
+	for name, n := range s.Outcomes {
+		outcomes = append(outcomes, outcome{name, n})
+	}
+	sort.Slice(outcomes, func(i, j int) bool {
+		if outcomes[i].n != outcomes[j].n {
+			return outcomes[i].n > outcomes[j].n
+		}
+		return outcomes[i].name < outcomes[j].name
+	})
+	labels := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		labels[i] = o.name
+	}
+	width := labelWidth(labels)
+	for _, o := range outcomes {
+		style := statusRunningStyle
+		switch o.name {
+		case watcher.StatusReady.String():
+			style = statusReadyStyle
+		case watcher.StatusFailed.String(), watcher.StatusConflict.String(),
+			watcher.StatusBuildFailed.String(), watcher.StatusTestsFailed.String():
+			style = statusFailedStyle
+		}
+		lines = append(lines, fmt.Sprintf("   %-*s  %s %s", width, o.name,
+			style.Render(statsBar(float64(o.n), float64(s.Runs), barWidth)),
+			headerDimStyle.Render(fmt.Sprintf("%d (%.0f%%)", o.n, 100*float64(o.n)/float64(s.Runs)))))
+	}
+
+	if len(s.Phases) > 0 {
+		section("Time per phase (median)")
+		labels = labels[:0]
+		longest := time.Duration(0)
+		for _, p := range s.Phases {
+			labels = append(labels, p.Name)
+			longest = max(longest, p.Median)
+		}
+		width = labelWidth(labels)
+		for _, p := range s.Phases {
+			lines = append(lines, fmt.Sprintf("   %-*s  %s %s", width, p.Name,
+				statusReactedStyle.Render(statsBar(float64(p.Median), float64(longest), barWidth)),
+				headerDimStyle.Render(fmt.Sprintf("%s · mean %s · %d runs", statsDuration(p.Median), statsDuration(p.Mean), p.Runs))))
+		}
+	}
+
+	section("Repos")
+	labels = labels[:0]
+	for _, r := range s.Repos {
+		labels = append(labels, r.Repo)
+	}
+	width = labelWidth(labels)
+	most := float64(s.Repos[0].Runs)
+	for _, r := range s.Repos {
+		lines = append(lines, fmt.Sprintf("   %s  %s %s", repoNameStyle.Render(padOrTruncate(r.Repo, width)),
+			statusQueuedStyle.Render(statsBar(float64(r.Runs), most, barWidth)),
+			headerDimStyle.Render(fmt.Sprintf("%d runs · %.0f%% ready", r.Runs, 100*float64(r.Succeeded)/float64(r.Runs)))))
+	}
+	return lines
+}
+
+// dayChart draws days' runs as columns statsChartHeight rows tall, as
+// wide as fits in width: the runs that ended ready in green, under the
+// rest in red.
+func dayChart(days []watcher.DayStats, width int) []string {
+	// A day's column is a cell narrower than its share, if it has room,
+	// to keep it apart from the next.
+	col := min(max((width-6)/max(len(days), 1), 1), 4)
+	bar := max(col-1, 1)
+	gap := strings.Repeat(" ", col-bar)
+	most := 0
+	for _, d := range days {
+		most = max(most, d.Runs)
+	}
+	most = max(most, 1)
+	cells := statsChartHeight * 8
+	lines := make([]string, 0, statsChartHeight+1)
+	for row := statsChartHeight; row >= 1; row-- {
+		label := "    "
+		if row == statsChartHeight {
+			label = fmt.Sprintf("%3d ", most)
+		}
+		var b strings.Builder
+		b.WriteString(headerDimStyle.Render(label))
+		for _, d := range days {
+			total := int(math.Round(float64(d.Runs) / float64(most) * float64(cells)))
+			ready := int(math.Round(float64(d.Succeeded) / float64(most) * float64(cells)))
+			style := statusFailedStyle
+			if ready > (row-1)*8 {
+				style = statusReadyStyle
+			}
+			b.WriteString(style.Render(strings.Repeat(columnCell(total, row), bar)) + gap)
+		}
+		lines = append(lines, b.String())
+	}
+	if len(days) > 0 {
+		first, last := days[0].Day.Format("Jan 2"), days[len(days)-1].Day.Format("Jan 2")
+		pad := max(len(days)*col-len(first)-len(last)-len(gap), 1)
+		lines = append(lines, headerDimStyle.Render("    "+first+strings.Repeat(" ", pad)+last))
+	}
+	return lines
+}
+
+// columnCell is the cell at row, counted from 1 at the bottom, of a
+// column eighths of a cell tall.
+func columnCell(eighths, row int) string {
+	filled := eighths - (row-1)*8
+	switch {
+	case filled >= 8:
+		return "█"
+	case filled > 0:
+		return string([]rune("▁▂▃▄▅▆▇")[filled-1])
+	}
+	return " "
+}
+
+// statsBar draws n of most as a bar up to width cells long, to an eighth
+// of a cell.
+func statsBar(n, most float64, width int) string {
+	if most <= 0 || n <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(n / most * float64(width*8)))
+	bar := strings.Repeat("█", eighths/8)
+	if r := eighths % 8; r > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[r-1])
+	}
+	return bar
+}
+
+// labelWidth is the width to pad labels to, up to 30.
+func labelWidth(labels []string) int {
+	width := 0
+	for _, l := range labels {
+		width = max(width, len(l))
+	}
+	return min(width, 30)
+}
+
+// statsDuration renders d to the second, or the tenth of one if shorter.
+func statsDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return shortDuration(d.Round(time.Second))
+}
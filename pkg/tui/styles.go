@@ -84,12 +84,14 @@ var (
 // -- Issue status badges -----------------------------------------------------
 
 var (
-	statusReadyStyle     = lipgloss.NewStyle().Foreground(colorGreen)
-	statusReadyBoldStyle = lipgloss.NewStyle().Foreground(colorGreen).Bold(true)
-	statusRunningStyle   = lipgloss.NewStyle().Foreground(colorYellow)
-	statusFailedStyle    = lipgloss.NewStyle().Foreground(colorRed)
-	statusReactedStyle   = lipgloss.NewStyle().Foreground(colorBlue)
-	statusPausedStyle    = lipgloss.NewStyle().Foreground(colorOrange)
+	statusReadyStyle      = lipgloss.NewStyle().Foreground(colorGreen)
+	statusReadyBoldStyle  = lipgloss.NewStyle().Foreground(colorGreen).Bold(true)
+	statusRunningStyle    = lipgloss.NewStyle().Foreground(colorYellow)
+	statusFailedStyle     = lipgloss.NewStyle().Foreground(colorRed)
+	statusReactedStyle    = lipgloss.NewStyle().Foreground(colorBlue)
+	statusPausedStyle     = lipgloss.NewStyle().Foreground(colorOrange)
+	statusNeedsInputStyle = lipgloss.NewStyle().Foreground(colorMagenta).Bold(true)
+	statusQueuedStyle     = lipgloss.NewStyle().Foreground(colorCyan)
 )
 
 // -- Log lines ---------------------------------------------------------------
@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/stefanpenner/lurker/pkg/watcher"
+)
+
+// focusTab selects what the focus view's scrollable area shows.
+type focusTab int
+
+const (
+	tabLogs       focusTab = iota // lurker's own log for the issue
+	tabBody                       // the GitHub issue body
+	tabDiff                       // the worktree's diff against its base branch
+	tabTranscript                 // Claude's full session transcript
+	tabShell                      // recent PTY output
+	numTabs
+)
+
+var tabNames = [numTabs]string{"logs", "body", "diff", "transcript", "shell"}
+
+// tabContentMsg delivers lines for a tab that is loaded in the background.
+type tabContentMsg struct {
+	key   string
+	tab   focusTab
+	lines []string
+}
+
+// enterFocus opens the focus view for iss on the logs tab, tailing.
+func (m *Model) enterFocus(iss *watcher.TrackedIssue) {
+	m.focusIssue = iss
+	m.focusTab = tabLogs
+	m.tabLines = nil
+	m.focusScroll = 999999
+	m.clampFocusScroll()
+	m.focus = focusFocus
+}
+
+// switchFocusTab moves delta tabs (wrapping) and loads the new tab.
+func (m *Model) switchFocusTab(delta int) tea.Cmd {
+	m.focusTab = focusTab((int(m.focusTab) + delta + int(numTabs)) % int(numTabs))
+	m.focusScroll = 0
+	if m.focusTab == tabLogs || m.focusTab == tabShell {
+		m.focusScroll = 999999
+	}
+	return m.loadFocusTab()
+}
+
+// loadFocusTab fills m.tabLines for the current tab. Cheap tabs load
+// synchronously; diff and transcript shell out or read files, so they load
+// in the background and arrive as a tabContentMsg.
+func (m *Model) loadFocusTab() tea.Cmd {
+	iss := m.focusIssue
+	m.tabLines = nil
+	if iss == nil {
+		return nil
+	}
+	key := issueKey(iss.Repo, iss.Number)
+
+	switch m.focusTab {
+	case tabBody:
+		body := strings.TrimSpace(iss.Body)
+		if body == "" {
+			body = "(no description)"
+		}
+		m.tabLines = wrapLines(body, m.width-2)
+	case tabShell:
+		s := m.ptys.get(key)
+		if s == nil {
+			m.tabLines = []string{"(no shell session — press s to start one)"}
+			break
+		}
+		m.tabLines = terminalLines(s.recentOutput())
+		m.clampFocusScroll()
+	case tabDiff:
+		workdir := iss.Workdir
+		if workdir == "" {
+			if path := watcher.PatchPath(m.manager.BaseDir(), iss.Repo, iss.Number); path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					m.tabLines = []string{"(" + err.Error() + ")"}
+					break
+				}
+				m.tabLines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+				break
+			}
+			m.tabLines = []string{"(not cloned yet)"}
+			break
+		}
+		base := m.manager.BaseBranch(iss.Repo, workdir)
+		return func() tea.Msg {
+			cmd := exec.Command("git", "diff", "origin/"+base)
+			cmd.Dir = workdir
+			out, err := cmd.CombinedOutput()
+			lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+			if err != nil {
+				lines = append([]string{"git diff failed: " + err.Error()}, lines...)
+			} else if len(out) == 0 {
+				lines = []string{"(no changes)"}
+			}
+			return tabContentMsg{key: key, tab: tabDiff, lines: lines}
+		}
+	case tabTranscript:
+		workdir := iss.Workdir
+		if workdir == "" && watcher.PatchPath(m.manager.BaseDir(), iss.Repo, iss.Number) != "" {
+			// Patch mode: the checkout is gone but Claude kept the session
+			workdir = watcher.IssueWorkdir(m.manager.BaseDir(), iss.Repo, iss.Number)
+		}
+		if workdir == "" {
+			m.tabLines = []string{"(not cloned yet)"}
+			break
+		}
+		format := m.manager.StreamFormat()
+		return func() tea.Msg {
+			lines, err := watcher.ClaudeTranscript(workdir, format)
+			if err != nil {
+				lines = []string{"(" + err.Error() + ")"}
+			}
+			return tabContentMsg{key: key, tab: tabTranscript, lines: lines}
+		}
+	}
+	return nil
+}
+
+// handleTabContent installs background-loaded tab lines if the user is
+// still looking at that tab.
+func (m *Model) handleTabContent(msg tabContentMsg) {
+	if m.focusIssue == nil || issueKey(m.focusIssue.Repo, m.focusIssue.Number) != msg.key || m.focusTab != msg.tab {
+		return
+	}
+	m.tabLines = msg.lines
+	m.seekTabLine()
+	m.clampFocusScroll()
+}
+
+// focusLineCount is the number of scrollable lines on the current tab.
+func (m *Model) focusLineCount() int {
+	if m.focusTab == tabLogs {
+		return len(m.focusLogs())
+	}
+	return len(m.tabLines)
+}
+
+// renderTabBar draws the tab strip that sits between title and content.
+func (m Model) renderTabBar() string {
+	active := lipgloss.NewStyle().Foreground(colorMagenta).Bold(true)
+	var parts []string
+	for i, name := range tabNames {
+		if focusTab(i) == tabLogs && m.logView != logViewAll {
+			name += ": " + logViewNames[m.logView]
+		}
+		if focusTab(i) == m.focusTab {
+			parts = append(parts, active.Render("["+name+"]"))
+		} else {
+			parts = append(parts, headerDimStyle.Render(" "+name+" "))
+		}
+	}
+	bar := " " + strings.Join(parts, " ")
+	if w := lipgloss.Width(bar); w < m.width {
+		bar += " " + separatorStyle.Render(strings.Repeat("─", m.width-w-1))
+	}
+	return clipLine(bar, m.width)
+}
+
+// renderTabLine styles one line of a non-log tab.
+func (m Model) renderTabLine(line string) string {
+	if m.focusTab == tabDiff {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			return dialogLabelStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			return statusReadyStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			return statusFailedStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			return lipgloss.NewStyle().Foreground(colorCyan).Render(line)
+		}
+	}
+	return logLineActiveStyle.Render(line)
+}
+
+// terminalLines turns raw PTY output into plain lines: escape sequences
+// are stripped and carriage-return overwrites keep only the final text.
+func terminalLines(raw []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		lines = append(lines, watcher.StripANSI(line))
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// wrapLines hard-wraps text to width columns, preserving blank lines.
+func wrapLines(text string, width int) []string {
+	if width < 10 {
+		width = 10
+	}
+	var out []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		wrapped := lipgloss.NewStyle().Width(width).Render(para)
+		out = append(out, strings.Split(wrapped, "\n")...)
+	}
+	return out
+}
@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
@@ -17,6 +19,24 @@ func hyperlink(url, text string) string {
 	return fmt.Sprintf("\x1b]8;;%s\x07%s\x1b]8;;\x07", url, text)
 }
 
+// narrowWidth is the terminal width below which the compact layout kicks in.
+const narrowWidth = 80
+
+// narrow reports whether the terminal is too narrow for the full layout,
+// e.g. a tmux split.
+func (m Model) narrow() bool {
+	return m.width < narrowWidth
+}
+
+// clipLine cuts a styled line to width columns without breaking escape
+// sequences, so long lines never wrap and break the frame.
+func clipLine(s string, width int) string {
+	if width < 1 || lipgloss.Width(s) <= width {
+		return s
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(s)
+}
+
 // padOrTruncate pads s (right) or truncates it to exactly width visual columns.
 func padOrTruncate(s string, width int) string {
 	w := lipgloss.Width(s)
@@ -48,6 +68,18 @@ func (m Model) View() string {
 		return m.renderFocusView()
 	}
 
+	if m.focus == focusSearch {
+		return m.renderSearchView()
+	}
+
+	if m.focus == focusReviews {
+		return m.renderReviewsView()
+	}
+
+	if m.focus == focusStats {
+		return m.renderStatsView()
+	}
+
 	var b strings.Builder
 
 	// Header bar
@@ -87,6 +119,26 @@ func (m Model) View() string {
 		return m.renderHelpScreen()
 	}
 
+	// PR dialog overlay
+	if m.focus == focusPR && m.prDialog != nil {
+		return m.renderPRDialog()
+	}
+
+	// Preflight checklist overlay
+	if m.focus == focusChecks && m.preflight != nil {
+		return m.renderPreflightDialog()
+	}
+
+	// Checkpoint picker overlay
+	if m.focus == focusRestore {
+		return m.renderCheckpointsDialog()
+	}
+
+	// Away summary overlay
+	if m.focus == focusAway {
+		return m.renderAwayDialog()
+	}
+
 	return b.String()
 }
 
@@ -101,18 +153,30 @@ func (m Model) renderHeader() string {
 	} else {
 		repoStr = headerDimStyle.Render(fmt.Sprintf("%d repos", len(repos)))
 	}
+	if orgs := len(m.manager.Orgs()); orgs > 0 {
+		repoStr += headerDimStyle.Render(fmt.Sprintf(" · %d orgs", orgs))
+	}
 
 	left := fmt.Sprintf(" %s  %s", title, repoStr)
+	if m.narrow() {
+		left = " " + title
+	}
 
 	// Right side: mode indicator (vim-style)
 	var modeTag string
 	switch m.focus {
 	case focusLogs:
 		modeTag = lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render(" LOGS ")
-	case focusInput:
+	case focusInput, focusPR:
 		modeTag = lipgloss.NewStyle().Foreground(colorGreen).Bold(true).Render(" INSERT ")
 	case focusFocus:
 		modeTag = lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render(" FOCUS ")
+	case focusSearch:
+		modeTag = lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render(" SEARCH ")
+	case focusReviews:
+		modeTag = lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render(" REVIEW ")
+	case focusStats:
+		modeTag = lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render(" STATS ")
 	default:
 		modeTag = lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render(" NORMAL ")
 	}
@@ -133,6 +197,7 @@ func (m Model) renderStatusBar() string {
 	pending := m.countByStatus(watcher.StatusPending)
 	ready := m.countByStatus(watcher.StatusReady)
 	failed := m.countByStatus(watcher.StatusFailed)
+	asking := m.countByStatus(watcher.StatusNeedsInput)
 
 	pendingStr := fmt.Sprintf("%d pending", pending)
 	activeStr := fmt.Sprintf("%d active", active)
@@ -140,6 +205,13 @@ func (m Model) renderStatusBar() string {
 	failedStr := fmt.Sprintf("%d failed", failed)
 
 	sep := footerSepStyle.Render(" | ")
+	if m.narrow() {
+		pendingStr = fmt.Sprintf("%dp", pending)
+		activeStr = fmt.Sprintf("%da", active)
+		readyStr = fmt.Sprintf("%dr", ready)
+		failedStr = fmt.Sprintf("%df", failed)
+		sep = " "
+	}
 
 	if pending > 0 {
 		parts = append(parts, lipgloss.NewStyle().Foreground(colorBlue).Render(pendingStr))
@@ -161,6 +233,57 @@ func (m Model) renderStatusBar() string {
 	} else {
 		parts = append(parts, headerDimStyle.Render(failedStr))
 	}
+	if asking > 0 {
+		askingStr := fmt.Sprintf("%d need input", asking)
+		if m.narrow() {
+			askingStr = fmt.Sprintf("%d?", asking)
+		}
+		parts = append(parts, statusNeedsInputStyle.Render(askingStr))
+	}
+	if queued := m.countByStatus(watcher.StatusQueued); queued > 0 {
+		queuedStr := fmt.Sprintf("%d queued", queued)
+		if m.narrow() {
+			queuedStr = fmt.Sprintf("%dq", queued)
+		}
+		parts = append(parts, statusQueuedStyle.Render(queuedStr))
+	}
+	if conflicts := m.countByStatus(watcher.StatusConflict); conflicts > 0 {
+		conflictStr := fmt.Sprintf("%d conflicting", conflicts)
+		if m.narrow() {
+			conflictStr = fmt.Sprintf("%d!", conflicts)
+		}
+		parts = append(parts, statusFailedStyle.Render(conflictStr))
+	}
+	if broken := m.countByStatus(watcher.StatusBuildFailed); broken > 0 {
+		brokenStr := fmt.Sprintf("%d not building", broken)
+		if m.narrow() {
+			brokenStr = fmt.Sprintf("%db", broken)
+		}
+		parts = append(parts, statusFailedStyle.Render(brokenStr))
+	}
+	if red := m.countByStatus(watcher.StatusTestsFailed); red > 0 {
+		redStr := fmt.Sprintf("%d failing tests", red)
+		if m.narrow() {
+			redStr = fmt.Sprintf("%dt", red)
+		}
+		parts = append(parts, statusFailedStyle.Render(redStr))
+	}
+
+	if !m.lastPoll.IsZero() && !m.narrow() {
+		parts = append(parts, headerDimStyle.Render("polled "+formatStamp(m.lastPoll, m.now, m.relativeTimes)))
+	}
+	if m.ghClient.Permissions().ReadOnly() {
+		parts = append(parts, statusPausedStyle.Render("observer"))
+	}
+	if m.cleanupStatus != "" && !m.narrow() {
+		parts = append(parts, headerDimStyle.Render("🗑 "+m.cleanupStatus))
+	}
+	if m.teamStatus != "" && !m.narrow() {
+		parts = append(parts, headerDimStyle.Render(m.teamStatus))
+	}
+	if m.discoverStatus != "" && !m.narrow() {
+		parts = append(parts, headerDimStyle.Render(m.discoverStatus))
+	}
 
 	return "  " + strings.Join(parts, sep)
 }
@@ -179,13 +302,23 @@ func (m Model) renderTree() string {
 			repoErr := m.repoErrors[item.repo]
 			allLines = append(allLines, m.renderRepoLine(item.repo, isSelected, count, repoErr))
 			if repoErr != "" {
-				errLine := "      " + statusFailedStyle.Render(repoErr)
+				errLine := clipLine("      "+statusFailedStyle.Render(failureSummary(repoErr)), m.width)
 				allLines = append(allLines, errLine)
 			}
 
 		case itemIssue:
-			iss := m.issues[item.issueIdx]
-			allLines = append(allLines, m.renderIssueLine(iss, isSelected))
+			iss := *item.issue
+			if m.narrow() {
+				allLines = append(allLines, m.renderIssueLinesCompact(iss, isSelected)...)
+			} else {
+				allLines = append(allLines, m.renderIssueLine(iss, isSelected))
+			}
+			for _, q := range questionLines(iss) {
+				allLines = append(allLines, clipLine(strings.Repeat(" ", issueIndent)+statusNeedsInputStyle.Render("? "+q), m.width))
+			}
+			if line := failureLine(iss); line != "" {
+				allLines = append(allLines, clipLine(strings.Repeat(" ", issueIndent)+statusFailedStyle.Render(line), m.width))
+			}
 		}
 	}
 
@@ -226,8 +359,7 @@ func (m Model) renderRepoLine(repo string, selected bool, issueCount int, repoEr
 		expandIcon = headerDimStyle.Render("v")
 	}
 
-	repoURL := fmt.Sprintf("https://github.com/%s", repo)
-	repoDisplay := hyperlink(repoURL, repo)
+	repoDisplay := hyperlink(m.manager.Forge().WebURL(repo), repo)
 
 	if repoErr != "" {
 		repoStyled := repoNameErrStyle.Render(repoDisplay)
@@ -246,8 +378,14 @@ func (m Model) renderRepoLine(repo string, selected bool, issueCount int, repoEr
 
 	repoStyled := repoNameStyle.Render(repoDisplay)
 	countStyled := repoCountStyle.Render(countStr)
+	if m.manager.PatchMode(repo) {
+		countStyled += headerDimStyle.Render("  patch mode")
+	}
 
 	line := fmt.Sprintf("  %s %s  %s", expandIcon, repoStyled, countStyled)
+	if m.narrow() {
+		line = clipLine(line, m.width)
+	}
 
 	if selected {
 		return selectedRowStyle.Render(padOrTruncate(line, m.width))
@@ -257,9 +395,6 @@ func (m Model) renderRepoLine(repo string, selected bool, issueCount int, repoEr
 
 // --- Bead pipeline rendering ------------------------------------------------
 
-// beadStages are the 5 pipeline stages in order.
-var beadStages = [5]string{"react", "clone", "claude", "review", "pr"}
-
 // beadState describes what a single bead looks like.
 type beadState int
 
@@ -271,35 +406,103 @@ const (
 	beadStatePausedAt                  // paused marker
 )
 
-// issueBeads returns the 5 bead states for a given issue status.
-func issueBeads(status watcher.IssueStatus) [5]beadState {
-	switch status {
-	case watcher.StatusPending:
-		return [5]beadState{beadStatePending, beadStatePending, beadStatePending, beadStatePending, beadStatePending}
+// issueBeads returns the labels and states of an issue's beads: its
+// status gives react, clone, claude and review, the stages of its latest
+// run's pipeline (see RepoConfig.Pipeline) come between claude and
+// review, and its PR and the PR's CI checks come last.
+func issueBeads(iss watcher.TrackedIssue) ([]string, []beadState) {
+	var head [3]beadState // react, clone, claude
+	var review beadState
+	switch iss.Status {
 	case watcher.StatusReacted:
-		return [5]beadState{beadStateDone, beadStatePending, beadStatePending, beadStatePending, beadStatePending}
+		head = [3]beadState{beadStateDone}
 	case watcher.StatusCloning:
-		return [5]beadState{beadStateDone, beadStateActive, beadStatePending, beadStatePending, beadStatePending}
-	case watcher.StatusCloneReady:
-		return [5]beadState{beadStateDone, beadStateDone, beadStatePending, beadStatePending, beadStatePending}
+		head = [3]beadState{beadStateDone, beadStateActive}
+	case watcher.StatusCloneReady, watcher.StatusQueued:
+		head = [3]beadState{beadStateDone, beadStateDone}
 	case watcher.StatusClaudeRunning:
-		return [5]beadState{beadStateDone, beadStateDone, beadStateActive, beadStatePending, beadStatePending}
+		head = [3]beadState{beadStateDone, beadStateDone, beadStateActive}
 	case watcher.StatusReady:
-		return [5]beadState{beadStateDone, beadStateDone, beadStateDone, beadStateDone, beadStatePending}
+		head = [3]beadState{beadStateDone, beadStateDone, beadStateDone}
+		review = beadStateDone
 	case watcher.StatusFailed:
-		return [5]beadState{beadStateDone, beadStateDone, beadStateFail, beadStatePending, beadStatePending}
-	case watcher.StatusPaused:
-		return [5]beadState{beadStateDone, beadStateDone, beadStatePausedAt, beadStatePending, beadStatePending}
-	default:
-		return [5]beadState{beadStatePending, beadStatePending, beadStatePending, beadStatePending, beadStatePending}
+		head = [3]beadState{beadStateDone, beadStateDone, beadStateFail}
+	case watcher.StatusPaused, watcher.StatusNeedsInput, watcher.StatusWaiting:
+		head = [3]beadState{beadStateDone, beadStateDone, beadStatePausedAt}
+	case watcher.StatusConflict, watcher.StatusTestsFailed:
+		head = [3]beadState{beadStateDone, beadStateDone, beadStateDone}
+		review = beadStateFail
+	case watcher.StatusBuildFailed:
+		head = [3]beadState{beadStateDone, beadStateDone, beadStateDone}
+	}
+
+	labels := []string{"react", "clone", "claude"}
+	beads := head[:]
+	// A run waiting for approval past claude waits before its next stage.
+	waiting := iss.Status == watcher.StatusWaiting && len(iss.Stages) > 0
+	for _, s := range iss.Stages {
+		bs := beadStatePending
+		switch s.State {
+		case watcher.ChecksNone:
+			if waiting {
+				bs, beads[2], waiting = beadStatePausedAt, beadStateDone, false
+			}
+		case watcher.ChecksPending:
+			// Claude is done once its pipeline runs; what happened to
+			// the run shows on the stage it was in.
+			bs, beads[2] = head[2], beadStateDone
+		case watcher.ChecksPassed:
+			bs = beadStateDone
+		case watcher.ChecksFailed:
+			bs, beads[2] = beadStateFail, beadStateDone
+			review = beadStatePending // the stage says why
+		}
+		labels = append(labels, s.Name)
+		beads = append(beads, bs)
+	}
+	if waiting {
+		review = beadStatePausedAt
+	}
+
+	labels = append(labels, "review", "pr", "ci")
+	beads = append(beads, review, beadStatePending, beadStatePending)
+	if iss.PRNumber > 0 {
+		beads[len(beads)-2] = beadStateDone
 	}
+	switch iss.Checks {
+	case watcher.ChecksPending:
+		beads[len(beads)-1] = beadStateActive
+	case watcher.ChecksPassed:
+		beads[len(beads)-1] = beadStateDone
+	case watcher.ChecksFailed:
+		beads[len(beads)-1] = beadStateFail
+	}
+	return labels, beads
+}
+
+// compactBeads returns the 7 beads of an issue's row: issueBeads, with its
+// pipeline's stages as one bead that shows the worst of them.
+func compactBeads(iss watcher.TrackedIssue) [7]beadState {
+	_, beads := issueBeads(iss)
+	stages := beads[3 : len(beads)-3]
+	pipeline := beadStateDone
+	if len(stages) == 0 {
+		pipeline = beadStatePending
+	}
+	rank := map[beadState]int{beadStateDone: 0, beadStatePending: 1, beadStateActive: 2, beadStatePausedAt: 3, beadStateFail: 4}
+	for _, bs := range stages {
+		if rank[bs] > rank[pipeline] {
+			pipeline = bs
+		}
+	}
+	return [7]beadState{beads[0], beads[1], beads[2], pipeline, beads[len(beads)-3], beads[len(beads)-2], beads[len(beads)-1]}
 }
 
 // renderBeads produces the bead pipeline string for an issue.
-// Line 1: dots connected by lines   e.g.  "  ● ── ● ── ● ── ○ ── ○"
-// Line 2: labels beneath the dots   e.g.  "  react clone claude review pr"
-func (m Model) renderBeads(status watcher.IssueStatus) (string, string) {
-	beads := issueBeads(status)
+// Line 1: dots connected by lines   e.g.  "  ● ── ● ── ● ── ○ ── ○ ── ○ ── ○"
+// Line 2: labels beneath the dots   e.g.  "  react clone claude build review pr ci"
+func (m Model) renderBeads(iss watcher.TrackedIssue) (string, string) {
+	labels, beads := issueBeads(iss)
 	connector := beadLine.Render("--")
 
 	var dotParts []string
@@ -321,11 +524,11 @@ func (m Model) renderBeads(status watcher.IssueStatus) (string, string) {
 		}
 		dotParts = append(dotParts, dot)
 
-		lbl := beadStages[i]
+		lbl := labels[i]
 		// Pad label to 6 chars to match dot + connector width
 		lblParts = append(lblParts, beadLabel.Render(fmt.Sprintf("%-6s", lbl)))
 
-		if i < 4 {
+		if i < len(beads)-1 {
 			dotParts = append(dotParts, connector)
 		}
 	}
@@ -335,9 +538,9 @@ func (m Model) renderBeads(status watcher.IssueStatus) (string, string) {
 	return dotsLine, lblLine
 }
 
-// renderBeadsCompact produces a single-line bead string: "*--*--*--o--o"
-func (m Model) renderBeadsCompact(status watcher.IssueStatus) string {
-	beads := issueBeads(status)
+// renderBeadsCompact produces a single-line bead string: "*-*-*-o-o-o-o"
+func (m Model) renderBeadsCompact(iss watcher.TrackedIssue) string {
+	beads := compactBeads(iss)
 	connector := beadLine.Render("-")
 
 	var parts []string
@@ -356,7 +559,7 @@ func (m Model) renderBeadsCompact(status watcher.IssueStatus) string {
 			dot = beadPending.Render("o")
 		}
 		parts = append(parts, dot)
-		if i < 4 {
+		if i < len(beads)-1 {
 			parts = append(parts, connector)
 		}
 	}
@@ -364,50 +567,18 @@ func (m Model) renderBeadsCompact(status watcher.IssueStatus) string {
 }
 
 func (m Model) renderIssueLine(iss watcher.TrackedIssue, selected bool) string {
-	key := issueKey(iss.Repo, iss.Number)
-	logCount := len(m.logs[key])
-
-	// Compact bead pipeline (9 chars visual: "x-x-x-o-o")
-	beadStr := m.renderBeadsCompact(iss.Status)
-
-	// Elapsed time — only shown for actively running statuses
-	var elapsedStr string
-	if isActive(iss.Status) {
-		el := elapsed(iss.StartedAt, m.now)
-		elapsedStr = m.spinner.View() + " " + el
-	}
-
-	// Issue reference
-	title := iss.Title
-	if len(title) > 40 {
-		title = title[:40] + "..."
-	}
-	issueRef := fmt.Sprintf("#%d %s", iss.Number, title)
-	issueRef = hyperlink(iss.URL, issueRef)
-
-	// Build the line:
-	//   col 1: indent (4 chars)
-	//   col 2: beads (compact, ~9 chars)
-	//   col 3: issue ref (variable)
-	//   col 4: elapsed + spinner (right side)
-	//   col 5: log count
+	cols, titleWidth := layoutColumns(m.columns, m.width)
 
 	var line strings.Builder
-	line.WriteString("      ")
-	line.WriteString(beadStr)
-	line.WriteString("  ")
-	line.WriteString(issueRef)
-
-	if elapsedStr != "" {
-		line.WriteString("  ")
-		line.WriteString(headerDimStyle.Render(elapsedStr))
-	}
-	if logCount > 0 {
-		line.WriteString("  ")
-		line.WriteString(headerDimStyle.Render(fmt.Sprintf("[%d]", logCount)))
+	line.WriteString(strings.Repeat(" ", issueIndent))
+	for i, c := range cols {
+		if i > 0 {
+			line.WriteString(strings.Repeat(" ", columnGap))
+		}
+		line.WriteString(m.renderIssueCell(iss, c, titleWidth))
 	}
 
-	result := line.String()
+	result := strings.TrimRight(line.String(), " ")
 
 	if selected {
 		return selectedRowStyle.Render(padOrTruncate(result, m.width))
@@ -418,12 +589,43 @@ func (m Model) renderIssueLine(iss watcher.TrackedIssue, selected bool) string {
 	return normalRowStyle.Render(result)
 }
 
-func isActive(status watcher.IssueStatus) bool {
-	switch status {
-	case watcher.StatusReacted, watcher.StatusCloning, watcher.StatusCloneReady, watcher.StatusClaudeRunning:
-		return true
+// renderIssueLinesCompact renders an issue as two stacked rows for narrow
+// terminals: a status icon, number and elapsed time, then the title.
+func (m Model) renderIssueLinesCompact(iss watcher.TrackedIssue, selected bool) []string {
+	top := fmt.Sprintf("    %s %s", m.statusIcon(iss.Status), repoCountStyle.Render(fmt.Sprintf("#%d", iss.Number)))
+	if isActive(iss.Status) {
+		top += "  " + headerDimStyle.Render(elapsed(iss.StartedAt, m.now))
+		if p := m.progressEstimate(iss); p != "" {
+			top += "  " + headerDimStyle.Render(p)
+		}
+	}
+	if iss.PRNumber > 0 {
+		top += "  " + statusReadyStyle.Render(fmt.Sprintf("PR#%d", iss.PRNumber))
 	}
-	return false
+
+	titleWidth := m.width - issueIndent
+	if titleWidth < 1 {
+		titleWidth = 1
+	}
+	title := truncate(iss.Title, titleWidth)
+	bottom := strings.Repeat(" ", issueIndent) + hyperlink(iss.URL, title)
+
+	lines := []string{top, bottom}
+	for i, l := range lines {
+		switch {
+		case selected:
+			lines[i] = selectedRowStyle.Render(padOrTruncate(l, m.width))
+		case iss.Status == watcher.StatusReady:
+			lines[i] = statusReadyBoldStyle.Render(l)
+		default:
+			lines[i] = normalRowStyle.Render(l)
+		}
+	}
+	return lines
+}
+
+func isActive(status watcher.IssueStatus) bool {
+	return status.Active()
 }
 
 func (m Model) statusIcon(status watcher.IssueStatus) string {
@@ -444,6 +646,14 @@ func (m Model) statusIcon(status watcher.IssueStatus) string {
 		return statusFailedStyle.Render("x")
 	case watcher.StatusPaused:
 		return statusPausedStyle.Render("~")
+	case watcher.StatusNeedsInput, watcher.StatusWaiting:
+		return statusNeedsInputStyle.Render("?")
+	case watcher.StatusQueued:
+		return statusQueuedStyle.Render("…")
+	case watcher.StatusConflict:
+		return statusFailedStyle.Render("!")
+	case watcher.StatusBuildFailed, watcher.StatusTestsFailed:
+		return statusFailedStyle.Render("x")
 	default:
 		return " "
 	}
@@ -467,6 +677,18 @@ func (m Model) statusLabel(status watcher.IssueStatus) string {
 		return statusFailedStyle.Render("failed")
 	case watcher.StatusPaused:
 		return statusPausedStyle.Render("paused")
+	case watcher.StatusNeedsInput:
+		return statusNeedsInputStyle.Render("ASKS")
+	case watcher.StatusWaiting:
+		return statusNeedsInputStyle.Render("WAITS")
+	case watcher.StatusQueued:
+		return statusQueuedStyle.Render("queued")
+	case watcher.StatusConflict:
+		return statusFailedStyle.Render("CONFLICT")
+	case watcher.StatusTestsFailed:
+		return statusFailedStyle.Render("TESTS")
+	case watcher.StatusBuildFailed:
+		return statusFailedStyle.Render("BUILD")
 	default:
 		return ""
 	}
@@ -475,18 +697,54 @@ func (m Model) statusLabel(status watcher.IssueStatus) string {
 func (m Model) renderFooter() string {
 	switch m.focus {
 	case focusInput:
+		if m.replyTo != "" {
+			return footerStyle.Render(" Answer " + m.replyTo + ": " + m.textInput.View())
+		}
+		if m.search.typing {
+			return footerStyle.Render(" Search: " + m.textInput.View())
+		}
+		if m.addRepo != "" {
+			return footerStyle.Render(" Base branch for " + m.addRepo + ": " + m.textInput.View())
+		}
 		return footerStyle.Render(" Add repo: " + m.textInput.View())
-	case focusDialog, focusHelp:
+	case focusDialog, focusHelp, focusAway, focusRestore:
 		return " " + helpLineDialog()
+	case focusPRExist:
+		return footerStyle.Render(fmt.Sprintf(" PR #%d already exists and has the new commits. Update its title and body too? ", m.prExists.prNum)) +
+			fmtHelp("u", "update") + "  " + fmtHelp("esc", "keep them")
+	case focusForce:
+		r := m.forcePush.rejected
+		return footerStyle.Render(fmt.Sprintf(" %s on %s has commits this branch doesn't (%s). Force-push over them? ", r.Branch, r.Remote, shortSHA(r.SHA))) +
+			fmtHelp("y", "force-push") + "  " + fmtHelp("esc", "cancel")
+	case focusDelete:
+		what := " Delete " + watcher.IssueBranch(m.delBranch.Number) + " from its remote"
+		if m.delBranch.PRNumber > 0 {
+			what += fmt.Sprintf(", closing PR #%d if it's open", m.delBranch.PRNumber)
+		}
+		return footerStyle.Render(what+"? ") + fmtHelp("y", "delete") + "  " + fmtHelp("esc", "cancel")
+	case focusAbandon:
+		return m.abandonPrompt()
+	case focusApprove:
+		return footerStyle.Render(" Allow "+strings.Join(m.approvingRules, ", ")+" for "+m.approving+"? ") +
+			fmtHelp("y", "this run") + "  " + fmtHelp("a", "always") + "  " + fmtHelp("n", "deny") + "  " + fmtHelp("esc", "later")
 	case focusConfirm:
+		if m.confirmRename == "" {
+			return " " + fmtHelp("y", "keep files") + "  " + fmtHelp("D", "delete files") + "  " + fmtHelp("n/esc", "cancel")
+		}
 		return " " + fmtHelp("y", "confirm") + "  " + fmtHelp("n/esc", "cancel")
 	case focusFocus:
-		return " " + helpLineFocus()
+		return " " + clipLine(helpLineFocus(), m.width-1)
 	default:
+		if m.narrow() {
+			return " " + helpLineCompact()
+		}
 		return " " + helpLineNormal()
 	}
 }
 
+// maxDialogRecordings bounds how many recordings the issue dialog lists.
+const maxDialogRecordings = 5
+
 func (m Model) renderWithDialog(_ string) string {
 	iss := m.dialogIssue
 	if iss == nil {
@@ -500,16 +758,21 @@ func (m Model) renderWithDialog(_ string) string {
 	d.WriteString(iss.Title)
 	d.WriteString("\n")
 	d.WriteString(dialogLabelStyle.Render("Status:  "))
-	d.WriteString(iss.Status.String())
+	d.WriteString(iss.StatusText())
 
 	// Bead pipeline in the dialog
 	d.WriteString("\n\n")
-	dotsLine, lblLine := m.renderBeads(iss.Status)
+	dotsLine, lblLine := m.renderBeads(*iss)
 	d.WriteString("  " + dotsLine)
 	d.WriteString("\n")
 	d.WriteString("  " + lblLine)
 	d.WriteString("\n")
 
+	if !iss.CreatedAt.IsZero() {
+		d.WriteString("\n")
+		d.WriteString(dialogLabelStyle.Render("Opened:  "))
+		d.WriteString(formatStamp(iss.CreatedAt, m.now, m.relativeTimes))
+	}
 	if iss.Labels != "" {
 		d.WriteString("\n")
 		d.WriteString(dialogLabelStyle.Render("Labels:  "))
@@ -528,6 +791,17 @@ func (m Model) renderWithDialog(_ string) string {
 	if iss.Error != "" {
 		d.WriteString("\n\n")
 		d.WriteString(statusFailedStyle.Render("Error: " + iss.Error))
+		if f := watcher.ClassifyFailure(iss.Error); f.Kind != watcher.FailureOther || f.Suggestion != "" {
+			d.WriteString("\n")
+			if f.Kind != watcher.FailureOther {
+				d.WriteString(dialogLabelStyle.Render("Kind:    "))
+				d.WriteString(string(f.Kind) + "\n")
+			}
+			if f.Suggestion != "" {
+				d.WriteString(dialogLabelStyle.Render("Fix:     "))
+				d.WriteString(f.Suggestion)
+			}
+		}
 	}
 	if iss.Body != "" {
 		d.WriteString("\n\n")
@@ -539,6 +813,31 @@ func (m Model) renderWithDialog(_ string) string {
 		}
 		d.WriteString(body)
 	}
+	if lines := m.dialogResult.Lines(); len(lines) > 0 {
+		d.WriteString("\n\n")
+		d.WriteString(dialogLabelStyle.Render("Result:"))
+		for _, line := range lines {
+			d.WriteString("\n  " + line)
+		}
+	}
+	if lines := m.dialogTools.Lines(); len(lines) > 0 {
+		d.WriteString("\n\n")
+		d.WriteString(dialogLabelStyle.Render("Last run:"))
+		for _, line := range lines {
+			d.WriteString("\n  " + line)
+		}
+	}
+	if len(m.dialogRecordings) > 0 {
+		d.WriteString("\n\n")
+		d.WriteString(dialogLabelStyle.Render(fmt.Sprintf("Recordings (%d):", len(m.dialogRecordings))))
+		for i, r := range m.dialogRecordings {
+			if i == maxDialogRecordings {
+				d.WriteString(fmt.Sprintf("\n  … %d older", len(m.dialogRecordings)-i))
+				break
+			}
+			d.WriteString(fmt.Sprintf("\n  %s  %s", formatStamp(r.StartedAt, m.now, m.relativeTimes), r.Path))
+		}
+	}
 	d.WriteString("\n\n")
 	d.WriteString(fmtHelp("esc", "close") + "  " + fmtHelp("o", "open in browser"))
 
@@ -549,13 +848,27 @@ func (m Model) renderWithDialog(_ string) string {
 
 func (m Model) renderConfirmDialog() string {
 	var d strings.Builder
+	if m.confirmRename != "" {
+		d.WriteString(dialogTitleStyle.Render("Update renamed repo"))
+		d.WriteString("\n\n")
+		d.WriteString(repoNameStyle.Render(m.confirmRepo))
+		d.WriteString(" now lives at ")
+		d.WriteString(repoNameStyle.Render(m.confirmRename))
+		d.WriteString(".\nUpdate the watched name and move its workdirs?")
+		d.WriteString("\n\n")
+		d.WriteString(fmtHelp("y", "confirm") + "  " + fmtHelp("n/esc", "cancel"))
+
+		dialog := dialogStyle.Render(d.String())
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
+	}
 	d.WriteString(dialogTitleStyle.Render("Remove repo"))
 	d.WriteString("\n\n")
-	d.WriteString("Remove ")
+	d.WriteString("Stop watching ")
 	d.WriteString(repoNameStyle.Render(m.confirmRepo))
-	d.WriteString(" and all its issues?")
+	d.WriteString(" and all its issues?\n")
+	d.WriteString(headerDimStyle.Render("Its bare clone, worktrees and logs live in " + filepath.Join(m.manager.BaseDir(), m.confirmRepo)))
 	d.WriteString("\n\n")
-	d.WriteString(fmtHelp("y", "confirm") + "  " + fmtHelp("n/esc", "cancel"))
+	d.WriteString(fmtHelp("y", "remove, keep files") + "  " + fmtHelp("D", "remove & delete files") + "  " + fmtHelp("n/esc", "cancel"))
 
 	dialog := dialogStyle.Render(d.String())
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialog)
@@ -572,46 +885,82 @@ func (m Model) renderFocusView() string {
 	// Line 1: repo  #num  beads  url
 	repoStyled := repoNameStyle.Render(iss.Repo)
 	numStr := headerDimStyle.Render(fmt.Sprintf("#%d", iss.Number))
-	beadStr := m.renderBeadsCompact(iss.Status)
+	beadStr := m.renderBeadsCompact(*iss)
 	label := m.statusLabel(iss.Status)
 	urlStr := headerDimStyle.Render(hyperlink(iss.URL, iss.URL))
-	b.WriteString(fmt.Sprintf(" %s  %s  %s %s  %s", repoStyled, numStr, beadStr, label, urlStr))
+	var header string
+	if m.narrow() {
+		header = fmt.Sprintf(" %s %s %s", numStr, m.statusIcon(iss.Status), label)
+	} else {
+		header = fmt.Sprintf(" %s  %s  %s %s  %s", repoStyled, numStr, beadStr, label, urlStr)
+		if !iss.CreatedAt.IsZero() {
+			header += headerDimStyle.Render("  opened " + formatStamp(iss.CreatedAt, m.now, m.relativeTimes))
+		}
+		if p := m.progressEstimate(*iss); p != "" {
+			header += headerDimStyle.Render("  " + p)
+		}
+		if u := m.budgetLine(*iss); u != "" {
+			header += headerDimStyle.Render("  " + u)
+		}
+	}
+	b.WriteString(clipLine(header, m.width))
 	b.WriteString("\n")
 
 	// Line 2: title
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(colorFg)
-	b.WriteString(" " + titleStyle.Render(iss.Title))
+	b.WriteString(" " + titleStyle.Render(truncate(iss.Title, m.width-1)))
 	b.WriteString("\n")
 
-	// Separator
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+	// Tab bar doubles as the separator
+	b.WriteString(m.renderTabBar())
 	b.WriteString("\n")
 
-	// Scrollable log area
-	key := issueKey(iss.Repo, iss.Number)
-	logLines := m.logs[key]
-	visibleLines := m.height - 5 // header(1) + title(1) + sep(1) + sep(1) + footer(1)
+	// Scrollable content area
+	visibleLines := m.height - 5 // header(1) + title(1) + tabs(1) + sep(1) + footer(1)
 	if visibleLines < 1 {
 		visibleLines = 1
 	}
 
 	start := m.focusScroll
 	end := start + visibleLines
-	if start > len(logLines) {
-		start = len(logLines)
+	total := m.focusLineCount()
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
 	}
-	if end > len(logLines) {
-		end = len(logLines)
+
+	if m.focusTab != tabLogs {
+		visible := m.tabLines[start:end]
+		for _, line := range visible {
+			b.WriteString(clipLine(" "+m.renderTabLine(line), m.width))
+			b.WriteString("\n")
+		}
+		for i := len(visible); i < visibleLines; i++ {
+			b.WriteString("\n")
+		}
+		b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width)))
+		b.WriteString("\n")
+		b.WriteString(" " + clipLine(helpLineFocus(), m.width-1))
+		return b.String()
 	}
 
-	visible := logLines[start:end]
+	visible := m.focusLogs()[start:end]
 	isActive := iss.Status == watcher.StatusClaudeRunning
 	for _, line := range visible {
-		if isActive {
-			b.WriteString(logLineActiveStyle.Render(" " + line))
+		var row string
+		if !m.narrow() {
+			row = m.renderStamp(line.at)
+		}
+		if line.color != "" {
+			row += " " + line.color
+		} else if isActive {
+			row += logLineActiveStyle.Render(" " + line.text)
 		} else {
-			b.WriteString(logLineStyle.Render(" " + line))
+			row += logLineStyle.Render(" " + line.text)
 		}
+		b.WriteString(clipLine(row, m.width))
 		b.WriteString("\n")
 	}
 	// Pad remaining lines
@@ -624,11 +973,19 @@ func (m Model) renderFocusView() string {
 	b.WriteString("\n")
 
 	// Footer
-	b.WriteString(" " + helpLineFocus())
+	b.WriteString(" " + clipLine(helpLineFocus(), m.width-1))
 
 	return b.String()
 }
 
+// renderStamp renders a log line's timestamp as a fixed-width dim column.
+// Lines without a timestamp get blank padding so text stays aligned.
+func (m Model) renderStamp(t time.Time) string {
+	const width = 12
+	stamp := formatStamp(t, m.now, m.relativeTimes)
+	return headerDimStyle.Render(" " + padOrTruncate(stamp, width))
+}
+
 func (m Model) renderHelpScreen() string {
 	var d strings.Builder
 	d.WriteString(dialogTitleStyle.Render("Keybindings"))
@@ -649,25 +1006,51 @@ func (m Model) renderHelpScreen() string {
 		{"j / k", "Move down / up"},
 		{"enter/l", "Expand repo / focus issue"},
 		{"f", "Focus view (full-screen logs)"},
+		{"[ / ]", "Focus view: previous / next tab"},
+		{"r", "Focus view: reload tab"},
 		{"i", "Info dialog"},
 		{"o", "Open in browser"},
+		{"T", "Toggle absolute / relative times"},
+		{"v", "Focus view: cycle logs between all, quiet (no tool calls or output) and errors"},
+		{"/", "Search all logs and transcripts (repo:, #N, since:, until:)"},
+		{"V", "PRs awaiting your review — draft, edit, submit"},
+		{"U", "Usage stats — runs, outcomes, time per phase (--stats)"},
 	})
 
 	section("Actions", [][2]string{
-		{"space", "Start / pause processing"},
+		{"space", "Start / pause processing, answer questions, allow tools or pass a gate"},
 		{"S", "Start all pending/paused/failed issues"},
-		{"a", "Approve & create PR"},
+		{"a", "Create PR (edit title, body, base, reviewers…)"},
+		{"A", "Create PR with defaults, after preflight checks"},
+		{"D", "Create draft PR with defaults, after preflight checks"},
+		{"F", "Have Claude address review feedback on the PR"},
+		{"B", "Rebase the branch onto the latest base branch"},
+		{"Q", "Squash the branch into one commit (squash_message)"},
+		{"X", "Delete the branch from its remote (abandons an open PR)"},
+		{"H", "Hand the issue back: stop, clean up and ignore it"},
 		{"t", "Takeover — interactive Claude (--continue)"},
-		{"s", "Shell — persistent PTY (Ctrl+] to detach)"},
+		{"s", "Shell — persistent PTY (Ctrl+] to detach, Ctrl+^ to record)"},
 		{"g", "Launch lazygit"},
 		{"c", "Launch Claude Code"},
+		{"z", "Checkpoint the worktree"},
+		{"Z", "Restore a checkpoint"},
 	})
 
 	section("Repos", [][2]string{
-		{"r", "Add repo"},
-		{"R / d", "Remove repo"},
+		{"r", "Add repo (owner/* for a whole org)"},
+		{"R / d", "Remove repo (keep or delete its files)"},
+		{"u", "Update a renamed/transferred repo"},
+		{"P", "Toggle patch mode (shallow checkout, patch output)"},
 	})
 
+	if len(m.macros) > 0 {
+		var bindings [][2]string
+		for _, mac := range m.macros {
+			bindings = append(bindings, [2]string{mac.Key, mac.Name + ": " + strings.Join(mac.Steps, " → ")})
+		}
+		section("Macros", bindings)
+	}
+
 	section("General", [][2]string{
 		{"?", "Toggle this help"},
 		{"esc", "Back / close"},
@@ -0,0 +1,116 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AbandonOptions say what abandoning an issue does besides stopping work
+// on it and ignoring it from then on.
+type AbandonOptions struct {
+	ReleaseClaim   bool // remove lurker's 👀 reaction
+	Comment        bool // post Templates.AbandonComment on the issue
+	RemoveWorktree bool // delete the worktree and its local branch
+}
+
+// abandonComment is the comment posted on an abandoned issue without an
+// abandon_comment template.
+func abandonComment(CommentData) string {
+	return "🤖 lurker is handing this issue back and won't work on it any further."
+}
+
+// Abandon hands repo#num back: it stops work on the issue, does what opts
+// asks, and ignores the issue from then on, so polls, webhooks and
+// replayed journals no longer bring it back. The issue is ignored even if
+// a step fails; failed steps are returned as warnings.
+func (m *Manager) Abandon(repo string, num int, opts AbandonOptions) (warnings []string, err error) {
+	m.StopIssue(repo, num)
+
+	m.mu.Lock()
+	key := IssueKey(repo, num)
+	delete(m.knownIssues, key)
+	delete(m.replayed, key)
+	delete(m.tracked, key)
+	if !m.ignored(repo, num) {
+		if m.state.Ignored == nil {
+			m.state.Ignored = make(map[string][]int)
+		}
+		m.state.Ignored[repo] = append(m.state.Ignored[repo], num)
+	}
+	err = m.saveState()
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	workdir := IssueWorkdir(m.baseDir, repo, num)
+	cfg := m.RepoConfig(workdir)
+	done := []string{"stopped"}
+	if opts.ReleaseClaim && m.ghClient != nil && m.ghClient.Permissions().AllowComments {
+		if err := m.ReleaseClaim(ctx, repo, num); err != nil {
+			warnings = append(warnings, fmt.Sprintf("removing 👀: %v", err))
+		} else {
+			done = append(done, "claim released")
+		}
+	}
+	if opts.Comment && m.ghClient != nil && m.ghClient.Permissions().AllowComments {
+		d := CommentData{Repo: repo, Number: num, Branch: IssueBranch(num)}
+		body, err := render("abandon_comment", cfg.Templates.AbandonComment, d, func() string { return abandonComment(d) })
+		if err == nil {
+			err = m.ghClient.CreateComment(ctx, repo, num, body)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("commenting: %v", err))
+		} else {
+			done = append(done, "commented")
+		}
+	}
+	if opts.RemoveWorktree && existingDir(workdir) != "" {
+		if err := m.removeWorktree(ctx, repo, num); err != nil {
+			warnings = append(warnings, fmt.Sprintf("removing the worktree: %v", err))
+		} else {
+			done = append(done, "worktree removed")
+		}
+	}
+
+	text := "Abandoned: " + strings.Join(done, ", ")
+	m.eventCh <- Event{Kind: EventAbandoned, Repo: repo, IssueNum: num, Text: text, Timestamp: time.Now()}
+	return warnings, nil
+}
+
+// removeWorktree deletes repo#num's checkout and, if it was a worktree of
+// the repo's bare clone, its local branch. The issue's logs are kept.
+func (m *Manager) removeWorktree(ctx context.Context, repo string, num int) error {
+	if err := removeWorktreeDir(IssueWorkdir(m.baseDir, repo, num)); err != nil {
+		return err
+	}
+	bare := existingDir(m.layout.BareDir(repo))
+	if bare == "" {
+		return nil
+	}
+	if _, err := gitCmd(ctx, bare, nil, "worktree", "prune"); err != nil {
+		return err
+	}
+	branch := IssueBranch(num)
+	if _, err := gitCmd(ctx, bare, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		return nil
+	}
+	_, err := gitCmd(ctx, bare, nil, "branch", "-D", branch)
+	return err
+}
+
+// IsIgnored reports whether repo#num was abandoned.
+func (m *Manager) IsIgnored(repo string, num int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ignored(repo, num)
+}
+
+// ignored is IsIgnored for callers holding m.mu.
+func (m *Manager) ignored(repo string, num int) bool {
+	return slices.Contains(m.state.Ignored[repo], num)
+}
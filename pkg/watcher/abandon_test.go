@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestManager_Abandon(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.state.Repos = []string{"owner/repo"} // watched, without starting a poller
+
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	gitIn(t, src, "commit", "-q", "--allow-empty", "-m", "init")
+	bare := filepath.Join(dir, "owner/repo/bare.git")
+	gitIn(t, dir, "clone", "-q", "--bare", src, bare)
+	wt := IssueWorkdir(dir, "owner/repo", 7)
+	gitIn(t, bare, "worktree", "add", "-q", "-b", IssueBranch(7), wt)
+	os.WriteFile(filepath.Join(IssueDir(dir, "owner/repo", 7), "lurker.log"), []byte("hello\n"), 0o644)
+	mgr.StoreIssue("owner/repo", Issue{Number: 7})
+
+	warnings, err := mgr.Abandon("owner/repo", 7, AbandonOptions{ReleaseClaim: true, Comment: true, RemoveWorktree: true})
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("Abandon = %v, %v", warnings, err)
+	}
+	if ev := <-mgr.EventCh(); ev.Kind != EventAbandoned || !strings.Contains(ev.Text, "worktree removed") {
+		t.Errorf("event = %+v", ev)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Errorf("worktree still there: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(IssueDir(dir, "owner/repo", 7), "lurker.log")); err != nil {
+		t.Errorf("log removed with the worktree: %v", err)
+	}
+	if out := gitIn(t, bare, "branch", "--list", IssueBranch(7)); out != "" {
+		t.Errorf("branch kept: %q", out)
+	}
+	if !mgr.IsIgnored("owner/repo", 7) || mgr.IsKnown(IssueKey("owner/repo", 7)) {
+		t.Error("issue not ignored")
+	}
+
+	// Polls and webhooks no longer pick it up, even after a restart.
+	if mgr.DeliverIssue("owner/repo", github.Issue{Number: 7, State: "open"}) {
+		t.Error("abandoned issue delivered")
+	}
+	again, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer again.Stop()
+	if !again.IsIgnored("owner/repo", 7) {
+		t.Error("ignored issue not persisted")
+	}
+}
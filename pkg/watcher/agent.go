@@ -0,0 +1,110 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultAgent is the agent issues are run with unless a repo config
+// names another.
+const DefaultAgent = "claude"
+
+// An Agent is a coding agent lurker runs on issues: Claude Code, or
+// another tried on the same issues. The issues' runs start it from a
+// shell in their PTY, with Command; PR descriptions and other short
+// background jobs call Run.
+type Agent interface {
+	// Name is what RepoConfig.Agent calls the agent.
+	Name() string
+	// Command returns the command line that runs the agent, from workdir,
+	// on the prompt it reads from stdin.
+	Command(workdir string, opts AgentOptions) []string
+	// Unsetenv lists the variables to unset for the agent when it runs on
+	// the host rather than in a sandbox.
+	Unsetenv() []string
+	// Run runs the agent in workdir on prompt, streaming its output to
+	// opts.Log, and returns all of it once the agent exits.
+	Run(ctx context.Context, workdir, prompt string, opts AgentOptions) (string, error)
+}
+
+// AgentOptions are what an Agent is run with. Agents ignore those they
+// have no use for.
+type AgentOptions struct {
+	Tools    string       // tools allowed without asking, as claude's --allowedTools takes them
+	Resume   bool         // continue the agent's last session in the workdir
+	MaxTurns int          // turns the agent may take; 0 for no limit
+	Format   StreamFormat // how Run logs the agent's output
+	Log      LogFunc      // gets Run's log lines; may be nil
+	PTY      *os.File     // if non-nil, Run mirrors its log lines there
+	Bwrap    *Bwrap       // if non-nil, Run runs the agent in its sandbox
+}
+
+// agents are the Agents RepoConfig.Agent can name.
+var agents = map[string]Agent{
+	"aider":  aiderAgent{},
+	"claude": claudeAgent{},
+	"codex":  codexAgent{},
+}
+
+// AgentNames lists the agents there are, sorted.
+func AgentNames() []string {
+	var names []string
+	for name := range agents {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupAgent returns the agent called name, or DefaultAgent's if name is
+// "".
+func LookupAgent(name string) (Agent, error) {
+	if name == "" {
+		name = DefaultAgent
+	}
+	a, ok := agents[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown agent %q (want %s)", name, strings.Join(AgentNames(), ", "))
+	}
+	return a, nil
+}
+
+// SetAgent has issues whose repo config names no agent run with the one
+// called name, DefaultAgent's if name is "".
+func (m *Manager) SetAgent(name string) error {
+	if _, err := LookupAgent(name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.agent = name
+	return nil
+}
+
+// agent returns the Agent issues in workdir are run with.
+func (w *Watcher) agent(workdir string) (Agent, error) {
+	if w.manager == nil {
+		return LookupAgent(LoadRepoConfig(workdir).Agent)
+	}
+	return LookupAgent(w.manager.RepoConfig(workdir).Agent)
+}
+
+// agentCommand returns the command that runs a with opts in workdir on
+// the prompt it reads from stdin, in box's sandbox if box is non-nil.
+func agentCommand(a Agent, workdir string, opts AgentOptions, box *Bwrap) []string {
+	argv := a.Command(workdir, opts)
+	if box != nil {
+		argv = box.Argv(workdir, argv)
+	}
+	if unset := a.Unsetenv(); len(unset) > 0 {
+		env := []string{"env"}
+		for _, name := range unset {
+			env = append(env, "-u", name)
+		}
+		argv = append(env, argv...)
+	}
+	return argv
+}
@@ -0,0 +1,97 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupAgent(t *testing.T) {
+	if a, err := LookupAgent(""); err != nil || a.Name() != DefaultAgent {
+		t.Errorf("LookupAgent(\"\") = %v, %v", a, err)
+	}
+	if a, err := LookupAgent("aider"); err != nil || a.Name() != "aider" {
+		t.Errorf("LookupAgent(aider) = %v, %v", a, err)
+	}
+	if _, err := LookupAgent("devin"); err == nil || !strings.Contains(err.Error(), "aider, claude, codex") {
+		t.Errorf("LookupAgent(devin) = %v", err)
+	}
+}
+
+func TestAgentCommand(t *testing.T) {
+	opts := AgentOptions{Tools: "Read,Bash(go test:*)", Resume: true, MaxTurns: 30}
+	got := shellJoin(agentCommand(claudeAgent{}, "/w", opts, nil))
+	want := "env -u ANTHROPIC_API_KEY -u CLAUDECODE claude -p --verbose --continue --max-turns 30 --allowedTools 'Read,Bash(go test:*)'"
+	if got != want {
+		t.Errorf("claude command:\n got %s\nwant %s", got, want)
+	}
+	got = shellJoin(agentCommand(aiderAgent{}, "/w", opts, nil))
+	want = "aider --yes-always --no-check-update --no-show-release-notes --message-file /dev/stdin --restore-chat-history"
+	if got != want {
+		t.Errorf("aider command:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAiderRun(t *testing.T) {
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" > "$AIDER_LOG.args"
+cat > "$AIDER_LOG.stdin"
+echo "Applied edit to notes.md"
+echo "Commit 1234567 docs: notes"
+`
+	if err := os.WriteFile(filepath.Join(bin, "aider"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	log := filepath.Join(t.TempDir(), "aider")
+	t.Setenv("AIDER_LOG", log)
+
+	// Outside a repo, it is handed the files there are.
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "diff.txt"), []byte("+x\n"), 0o644)
+	var lines []string
+	out, err := aiderAgent{}.Run(context.Background(), dir, "Describe the diff.", AgentOptions{Log: func(line string) { lines = append(lines, line) }})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{"Applied edit to notes.md", "Commit 1234567 docs: notes"}
+	if !reflect.DeepEqual(lines, want) || out != strings.Join(want, "\n")+"\n" {
+		t.Errorf("Run logged %q, returned %q", lines, out)
+	}
+	if stdin, _ := os.ReadFile(log + ".stdin"); string(stdin) != "Describe the diff." {
+		t.Errorf("prompt = %q", stdin)
+	}
+	args, _ := os.ReadFile(log + ".args")
+	if !strings.Contains(string(args), "--no-git --file "+filepath.Join(dir, "diff.txt")) {
+		t.Errorf("args = %s", args)
+	}
+}
+
+func TestSetAgent(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	if err := mgr.SetAgent("devin"); err == nil {
+		t.Error("SetAgent took an unknown agent")
+	}
+	if err := mgr.SetAgent("aider"); err != nil {
+		t.Fatalf("SetAgent: %v", err)
+	}
+
+	workdir := t.TempDir()
+	if got := mgr.RepoConfig(workdir).Agent; got != "aider" {
+		t.Errorf("agent without a repo config = %q", got)
+	}
+	os.MkdirAll(filepath.Join(workdir, ".lurker"), 0o755)
+	os.WriteFile(filepath.Join(workdir, ".lurker", "config.json"), []byte(`{"agent": "claude"}`), 0o644)
+	if got := mgr.RepoConfig(workdir).Agent; got != "claude" {
+		t.Errorf("agent the repo config names = %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// aiderAgent runs aider (https://aider.chat), which picks its model from
+// the API keys in its environment and its own config (.aider.conf.yml,
+// AIDER_MODEL and the like). It edits files and commits what it changed
+// itself; it doesn't run the build or tests, and lurker's tool approvals,
+// turn limits and transcripts don't apply to it.
+type aiderAgent struct{}
+
+func (aiderAgent) Name() string { return "aider" }
+
+// Command runs aider once, accepting whatever it asks, on the prompt
+// read from stdin. Resume has it reload the chat of its last run in the
+// directory.
+func (aiderAgent) Command(workdir string, opts AgentOptions) []string {
+	argv := []string{"aider", "--yes-always", "--no-check-update", "--no-show-release-notes", "--message-file", "/dev/stdin"}
+	if opts.Resume {
+		argv = append(argv, "--restore-chat-history")
+	}
+	return argv
+}
+
+// Unsetenv is empty: aider needs the API keys it is given.
+func (aiderAgent) Unsetenv() []string { return nil }
+
+// Run logs aider's output as it is. Outside a git repo, aider sees only
+// the files it is given, so all of workdir's are.
+func (a aiderAgent) Run(ctx context.Context, workdir, prompt string, opts AgentOptions) (string, error) {
+	argv := append(a.Command(workdir, opts), "--no-pretty")
+	if _, common := gitDirs(workdir); common == "" {
+		argv = append(argv, "--no-git")
+		entries, _ := os.ReadDir(workdir)
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				argv = append(argv, "--file", filepath.Join(workdir, e.Name()))
+			}
+		}
+	}
+	return runAgent(ctx, a, workdir, prompt, argv, opts, func(raw string) []string { return []string{raw} })
+}
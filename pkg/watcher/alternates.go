@@ -0,0 +1,162 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Bare clones share objects through git alternates: a new bare clone
+// borrows from those of the watched repos with the same name, which are
+// usually forks of one another, so it fetches and stores only what they
+// lack. Issues' worktrees already share their repo's bare clone, and
+// patch mode's checkouts borrow from it too. Alternates are written
+// relative to the borrowing objects directory, so moving the cache
+// directory whole keeps them working; renaming or deleting a repo fixes
+// up those that borrow from it.
+
+// referenceClones returns the bare clones in l a new one of repo can
+// borrow objects from.
+func (l Layout) referenceClones(repo string) []string {
+	var refs []string
+	for _, bare := range l.bareClones() {
+		if filepath.Base(filepath.Dir(bare)) == filepath.Base(repo) && bare != l.BareDir(repo) {
+			refs = append(refs, bare)
+		}
+	}
+	return refs
+}
+
+// referenceArgs returns the git clone flags that borrow from dirs'
+// objects, where they are usable.
+func referenceArgs(dirs []string) string {
+	var args string
+	for _, dir := range dirs {
+		args += " --reference-if-able " + ShellQuote(dir)
+	}
+	return args
+}
+
+// objectsDir returns the directory of bareDir's objects.
+func objectsDir(bareDir string) string {
+	return filepath.Join(bareDir, "objects")
+}
+
+// readAlternates returns the objects directories objects borrows from,
+// resolving relative ones against from, the directory they were written
+// relative to.
+func readAlternates(objects, from string) []string {
+	data, err := os.ReadFile(filepath.Join(objects, "info", "alternates"))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case filepath.IsAbs(line):
+			dirs = append(dirs, filepath.Clean(line))
+		default:
+			dirs = append(dirs, filepath.Join(from, line))
+		}
+	}
+	return dirs
+}
+
+// writeAlternates has objects borrow from dirs, relative to it, or from
+// none if dirs is empty.
+func writeAlternates(objects string, dirs []string) error {
+	path := filepath.Join(objects, "info", "alternates")
+	if len(dirs) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var lines []string
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(objects, dir)
+		if err != nil {
+			rel = dir
+		}
+		lines = append(lines, rel)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// bareClones returns the bare clones in l's cache, but for those being
+// deleted.
+func (l Layout) bareClones() []string {
+	var dirs []string
+	for _, pattern := range []string{"*/*/bare.git", "*/*/*/bare.git"} {
+		matches, _ := filepath.Glob(filepath.Join(l.Cache, pattern))
+		for _, m := range matches {
+			if !strings.HasPrefix(m, filepath.Join(l.Cache, trashDir)+string(filepath.Separator)) {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs
+}
+
+// relinkAlternates fixes up alternates after repo's bare clone moved from
+// the objects directory was to where l keeps it now: its own, which are
+// relative to where it was, and those of the bare clones borrowing from
+// it.
+func (l Layout) relinkAlternates(repo, was string) error {
+	now := objectsDir(l.BareDir(repo))
+	if err := writeAlternates(now, readAlternates(now, was)); err != nil {
+		return err
+	}
+	return l.repointBorrowers(was, now)
+}
+
+// repointBorrowers has the bare clones borrowing from the objects
+// directory was borrow from now instead.
+func (l Layout) repointBorrowers(was, now string) error {
+	for _, bare := range l.borrowers(was) {
+		objects := objectsDir(bare)
+		dirs := readAlternates(objects, objects)
+		dirs[slices.Index(dirs, was)] = now
+		if err := writeAlternates(objects, dirs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// borrowers returns the bare clones in l borrowing from the objects
+// directory objects.
+func (l Layout) borrowers(objects string) []string {
+	var bares []string
+	for _, bare := range l.bareClones() {
+		own := objectsDir(bare)
+		if slices.Contains(readAlternates(own, own), objects) {
+			bares = append(bares, bare)
+		}
+	}
+	return bares
+}
+
+// dissociate copies into bares the objects they borrow from the objects
+// directory objects, and has them stop, so it can be deleted. Repacking
+// takes a while in a big repo.
+func dissociate(ctx context.Context, bares []string, objects string) error {
+	for _, bare := range bares {
+		own := objectsDir(bare)
+		dirs := readAlternates(own, own)
+		i := slices.Index(dirs, objects)
+		if i < 0 {
+			continue
+		}
+		if _, err := gitCmd(ctx, bare, nil, "repack", "-a", "-d", "-q"); err != nil {
+			return err
+		}
+		if err := writeAlternates(own, slices.Delete(dirs, i, i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAlternates(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+
+	l := Layout{Data: filepath.Join(root, "base")}.resolved()
+	gitIn(t, root, "clone", "-q", "--bare", src, l.BareDir("a/proj"))
+	if got, want := l.referenceClones("b/proj"), []string{l.BareDir("a/proj")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("referenceClones = %v, want %v", got, want)
+	}
+	if got := l.referenceClones("b/other"); len(got) != 0 {
+		t.Errorf("referenceClones of another name = %v", got)
+	}
+
+	// A fork's clone borrows the objects it shares, relative to itself.
+	gitIn(t, root, "clone", "-q", "--bare", "--reference-if-able", l.BareDir("a/proj"), "file://"+src, l.BareDir("b/proj"))
+	fork := objectsDir(l.BareDir("b/proj"))
+	writeAlternates(fork, readAlternates(fork, fork))
+	data, _ := os.ReadFile(filepath.Join(fork, "info", "alternates"))
+	if strings.TrimSpace(string(data)) != "../../../../a/proj/bare.git/objects" {
+		t.Errorf("alternates = %q", data)
+	}
+	readable := func() {
+		t.Helper()
+		gitIn(t, l.BareDir("b/proj"), "cat-file", "-e", "HEAD:a.txt")
+	}
+	readable()
+	if out := gitIn(t, l.BareDir("b/proj"), "count-objects", "-v"); !strings.HasPrefix(out, "count: 0\n") || !strings.Contains(out, "\nin-pack: 0\n") {
+		t.Errorf("fork's clone has objects of its own:\n%s", out)
+	}
+
+	// Renaming the repo it borrows from keeps it readable.
+	if err := moveRepoDir(l, "a/proj", "c/proj"); err != nil {
+		t.Fatalf("moveRepoDir: %v", err)
+	}
+	readable()
+
+	// So does deleting it, once dissociated.
+	own := objectsDir(l.BareDir("c/proj"))
+	if got := l.borrowers(own); !reflect.DeepEqual(got, []string{l.BareDir("b/proj")}) {
+		t.Errorf("borrowers = %v", got)
+	}
+	if err := dissociate(context.Background(), l.borrowers(own), own); err != nil {
+		t.Fatalf("dissociate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(fork, "info", "alternates")); !os.IsNotExist(err) {
+		t.Errorf("alternates kept: %v", err)
+	}
+	os.RemoveAll(filepath.Join(l.Data, "c"))
+	readable()
+}
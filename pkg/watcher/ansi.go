@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiEscape matches CSI, OSC and two-character escape sequences, as
+// found in the output of tools that color or redraw their terminal.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// sgrReset ends any colors KeepColors left on.
+const sgrReset = "\x1b[0m"
+
+// StripANSI returns s as plain text: escape sequences are removed, a
+// carriage return keeps only the text written after it, as a terminal
+// would show it, newlines become spaces and other control characters
+// except tab are dropped.
+func StripANSI(s string) string {
+	return strings.ReplaceAll(cleanLine(ansiEscape.ReplaceAllString(s, "")), "\x1b", "")
+}
+
+// KeepColors is StripANSI that keeps the color and style (SGR)
+// sequences, so s can be shown as its tool colored it. The result ends
+// with a reset if it has any.
+func KeepColors(s string) string {
+	colored := false
+	s = ansiEscape.ReplaceAllStringFunc(s, func(seq string) string {
+		if strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") {
+			colored = true
+			return seq
+		}
+		return ""
+	})
+	s = cleanLine(s)
+	if colored && strings.Contains(s, "\x1b[") {
+		s += sgrReset
+	}
+	return s
+}
+
+// cleanLine applies carriage returns and drops control characters, but
+// leaves ESC for KeepColors' sequences.
+func cleanLine(s string) string {
+	s = strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\r\n")
+	if i := strings.LastIndexByte(s, '\r'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n':
+			return ' '
+		case r == '\t' || r == 0x1b:
+			return r
+		case r < 0x20 || r == 0x7f:
+			return -1
+		}
+		return r
+	}, s)
+}
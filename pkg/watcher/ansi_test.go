@@ -0,0 +1,34 @@
+package watcher
+
+import "testing"
+
+func TestStripANSI(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"plain text", "plain text"},
+		{"\x1b[31mFAIL\x1b[0m TestFoo", "FAIL TestFoo"},
+		{"\x1b]0;title\x07prompt", "prompt"},
+		{"\x1b[2K\x1b[1Gdone", "done"},
+		{"10%\r50%\r100%", "100%"},
+		{"line\r\n", "line"},
+		{"two\nlines", "two lines"},
+		{"tab\there\x07\x00", "tab\there"},
+		{"stray \x1bx", "stray x"},
+	} {
+		if got := StripANSI(tc.in); got != tc.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestKeepColors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"plain", "plain"},
+		{"\x1b[31mFAIL\x1b[0m ok", "\x1b[31mFAIL\x1b[0m ok\x1b[0m"},
+		{"\x1b[1;32mPASS", "\x1b[1;32mPASS\x1b[0m"},
+		{"\x1b[2K\x1b]0;t\x07\x1b[33mwarn", "\x1b[33mwarn\x1b[0m"},
+	} {
+		if got := KeepColors(tc.in); got != tc.want {
+			t.Errorf("KeepColors(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
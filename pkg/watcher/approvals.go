@@ -0,0 +1,380 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ToolRequestsFile is where an issue's refused tool calls wait in its
+// IssueDir for the user to allow or deny them. Its presence puts the issue
+// in StatusNeedsInput.
+const ToolRequestsFile = "tool-requests.json"
+
+// ApprovedToolsFile lists, in a repo's directory under the base dir, the
+// tool rules the user allowed for all of the repo's runs.
+const ApprovedToolsFile = "approved-tools.json"
+
+// ToolRequest is a tool call Claude was refused because the tool wasn't
+// in its allowed tools.
+type ToolRequest struct {
+	Tool  string `json:"tool"`
+	Input string `json:"input,omitempty"` // the command, path or URL, for display
+}
+
+// Rule is the --allowedTools rule that would allow r: Bash commands by
+// their program and subcommand ("Bash(npm install:*)"), other tools
+// whole.
+func (r ToolRequest) Rule() string {
+	if r.Tool != "Bash" {
+		return r.Tool
+	}
+	words := strings.Fields(r.Input)
+	switch {
+	case len(words) == 0:
+		return "Bash"
+	case len(words) == 1 || strings.HasPrefix(words[1], "-") || strings.ContainsAny(words[1], "&|;<>()$`'\"/="):
+		return "Bash(" + words[0] + ":*)"
+	}
+	return "Bash(" + words[0] + " " + words[1] + ":*)"
+}
+
+// Approval is the user's answer to an issue's tool requests.
+type Approval int
+
+const (
+	ApprovalDeny   Approval = iota
+	ApprovalOnce            // allow for the resumed run
+	ApprovalAlways          // allow for every run in the repo
+)
+
+// refusalMark is in the error Claude Code returns for a tool call it
+// hasn't been allowed to make.
+const refusalMark = "requested permissions to"
+
+// SessionToolRequests returns the tool calls refused in the latest run of
+// the latest Claude session in workdir, that is since its last prompt.
+func SessionToolRequests(workdir string) ([]ToolRequest, error) {
+	path, err := latestSession(workdir)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	calls := map[string]contentBlock{}
+	var reqs []ToolRequest
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line sessionLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		var msg struct {
+			Content json.RawMessage `json:"content"`
+		}
+		if json.Unmarshal(line.Message, &msg) != nil {
+			continue
+		}
+		var blocks []struct {
+			contentBlock
+			ToolUseID string          `json:"tool_use_id"`
+			Content   json.RawMessage `json:"content"`
+			IsError   bool            `json:"is_error"`
+		}
+		if json.Unmarshal(msg.Content, &blocks) != nil {
+			if line.Type == "user" {
+				// A prompt: a new run starts.
+				reqs, seen = nil, map[string]bool{}
+			}
+			continue
+		}
+		for _, b := range blocks {
+			switch {
+			case line.Type == "assistant" && b.Type == "tool_use":
+				calls[b.ID] = b.contentBlock
+			case line.Type == "user" && b.Type == "tool_result" && b.IsError && strings.Contains(resultText(b.Content), refusalMark):
+				call, ok := calls[b.ToolUseID]
+				if !ok {
+					continue
+				}
+				req := ToolRequest{Tool: call.Name, Input: toolInput(call)}
+				if key := req.Rule() + "\x00" + req.Input; !seen[key] {
+					seen[key] = true
+					reqs = append(reqs, req)
+				}
+			}
+		}
+	}
+	return reqs, scanner.Err()
+}
+
+// resultText is the text of a tool result, which is a string or a list
+// of text blocks.
+func resultText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []contentBlock
+	json.Unmarshal(raw, &blocks)
+	var b strings.Builder
+	for _, block := range blocks {
+		b.WriteString(block.Text)
+	}
+	return b.String()
+}
+
+// toolInput summarizes a tool call's input in a line: its command, path,
+// URL or pattern.
+func toolInput(call contentBlock) string {
+	var input struct {
+		Command  string `json:"command"`
+		FilePath string `json:"file_path"`
+		URL      string `json:"url"`
+		Pattern  string `json:"pattern"`
+	}
+	json.Unmarshal(call.Input, &input)
+	for _, s := range []string{input.Command, input.FilePath, input.URL, input.Pattern} {
+		if s = strings.TrimSpace(s); s != "" {
+			first, _, _ := strings.Cut(s, "\n")
+			return truncateRunes(first, 200)
+		}
+	}
+	return ""
+}
+
+// ReadToolRequests returns the tool requests waiting in issueDir.
+func ReadToolRequests(issueDir string) []ToolRequest {
+	data, err := os.ReadFile(filepath.Join(issueDir, ToolRequestsFile))
+	if err != nil {
+		return nil
+	}
+	var reqs []ToolRequest
+	json.Unmarshal(data, &reqs)
+	return reqs
+}
+
+// writeToolRequests saves reqs in issueDir, or removes the file if there
+// are none.
+func writeToolRequests(issueDir string, reqs []ToolRequest) error {
+	path := filepath.Join(issueDir, ToolRequestsFile)
+	if len(reqs) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(reqs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ToolRequestsText describes reqs for the user, a line per request.
+func ToolRequestsText(reqs []ToolRequest) string {
+	var b strings.Builder
+	b.WriteString("Claude was refused tools it isn't allowed:")
+	for _, r := range reqs {
+		fmt.Fprintf(&b, "\n- %s", r.Tool)
+		if r.Input != "" {
+			fmt.Fprintf(&b, ": %s", r.Input)
+		}
+		if rule := r.Rule(); rule != r.Tool {
+			fmt.Fprintf(&b, " (allow as %s)", rule)
+		}
+	}
+	return b.String()
+}
+
+// ToolRules returns the distinct rules that allow reqs.
+func ToolRules(reqs []ToolRequest) []string {
+	var rules []string
+	for _, r := range reqs {
+		if rule := r.Rule(); !slices.Contains(rules, rule) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ReadApprovedTools returns the tool rules allowed for all of repo's runs.
+func ReadApprovedTools(baseDir, repo string) []string {
+	data, err := os.ReadFile(filepath.Join(baseDir, repo, ApprovedToolsFile))
+	if err != nil {
+		return nil
+	}
+	var rules []string
+	json.Unmarshal(data, &rules)
+	return rules
+}
+
+// approveTools adds rules to repo's approved tools.
+func approveTools(baseDir, repo string, rules []string) error {
+	approved := ReadApprovedTools(baseDir, repo)
+	for _, rule := range rules {
+		if !slices.Contains(approved, rule) {
+			approved = append(approved, rule)
+		}
+	}
+	data, err := json.MarshalIndent(approved, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(baseDir, repo)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ApprovedToolsFile), append(data, '\n'), 0o644)
+}
+
+// withTools adds the rules tools lacks to the comma-separated tools.
+func withTools(tools string, rules []string) string {
+	have := strings.Split(tools, ",")
+	for i := range have {
+		have[i] = strings.TrimSpace(have[i])
+	}
+	for _, rule := range rules {
+		if !slices.Contains(have, rule) {
+			tools += "," + rule
+			have = append(have, rule)
+		}
+	}
+	return tools
+}
+
+// toolRequestPrompt resumes a run that was refused reqs.
+func toolRequestPrompt(reqs []ToolRequest, allowed bool) string {
+	var b strings.Builder
+	b.WriteString("You were refused permission to use these tools:\n")
+	for _, r := range reqs {
+		fmt.Fprintf(&b, "\n- %s", r.Tool)
+		if r.Input != "" {
+			fmt.Fprintf(&b, ": %s", r.Input)
+		}
+	}
+	if allowed {
+		b.WriteString("\n\nThe maintainer has now allowed them. Retry what you were doing, then continue with the issue.")
+	} else {
+		fmt.Fprintf(&b, "\n\nThe maintainer denied them. Continue with the issue without them; if you cannot, write your questions to %s and stop.", QuestionsFile)
+	}
+	return b.String()
+}
+
+// checkToolRequests looks for tool calls refused in the run just ended in
+// workdir. If there were any it saves them and reports them, and returns
+// true: the run waits for the user to allow or deny them.
+func (w *Watcher) checkToolRequests(eventCh chan<- Event, num int, workdir string) bool {
+	reqs, err := SessionToolRequests(workdir)
+	if err != nil || len(reqs) == 0 {
+		return false
+	}
+	if err := writeToolRequests(IssueDir(w.cfg.BaseDir, w.cfg.Repo, num), reqs); err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Save tool requests: %v", err))
+		return true
+	}
+	w.emit(eventCh, EventClaudeDone, num, "Claude stopped on tools it isn't allowed")
+	w.emit(eventCh, EventToolRequest, num, ToolRequestsText(reqs))
+	return true
+}
+
+// ResolveToolRequests answers the tool requests of an issue waiting in
+// StatusNeedsInput and resumes its Claude session.
+func (m *Manager) ResolveToolRequests(repo string, num int, approval Approval) {
+	reqs := ReadToolRequests(IssueDir(m.baseDir, repo, num))
+	if len(reqs) == 0 {
+		m.announce(Event{Kind: EventWarning, Repo: repo, IssueNum: num, Text: "No tool requests to answer", Timestamp: time.Now()})
+		return
+	}
+	m.mu.Lock()
+	key := IssueKey(repo, num)
+	issue, ok := m.knownIssues[key]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	if cancel, ok := m.issueCtxs[key]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.issueCtxs[key] = cancel
+	done := m.runStarted(key)
+	w := m.repoWatchers[repo]
+	var resumed Event
+	var changed bool
+	if w != nil {
+		resumed, changed = m.setStatus(repo, num, StatusReacted)
+	}
+	m.mu.Unlock()
+
+	if w == nil {
+		cancel()
+		done()
+		return
+	}
+	if changed {
+		m.announce(resumed)
+	}
+	go func() {
+		defer done()
+		w.resolveToolRequests(ctx, m.eventCh, issue, reqs, approval)
+	}()
+}
+
+// resolveToolRequests continues the most recent Claude session in the
+// issue's worktree, with the tools of reqs it was refused if approval
+// allows them.
+func (w *Watcher) resolveToolRequests(ctx context.Context, eventCh chan<- Event, issue Issue, reqs []ToolRequest, approval Approval) {
+	num := issue.Number
+	run := w.runner(ctx, IssueKey(w.cfg.Repo, num))
+	started := time.Now()
+
+	issueDir := IssueDir(w.cfg.BaseDir, w.cfg.Repo, num)
+	workdir := IssueWorkdir(w.cfg.BaseDir, w.cfg.Repo, num)
+
+	repoCfg := w.manager.RepoConfig(workdir)
+	tools := scopedTools(repoCfg.ClaudeTools(), repoCfg.Scope(issue))
+	rules := ToolRules(reqs)
+	if approval != ApprovalDeny {
+		tools = withTools(tools, rules)
+	}
+	if approval == ApprovalAlways {
+		if err := approveTools(w.cfg.BaseDir, w.cfg.Repo, rules); err != nil {
+			w.emit(eventCh, EventError, num, fmt.Sprintf("Save approved tools: %v", err))
+			return
+		}
+	}
+
+	promptFile := filepath.Join(issueDir, ".lurker-tools.txt")
+	if err := os.WriteFile(promptFile, []byte(toolRequestPrompt(reqs, approval != ApprovalDeny)), 0o644); err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Write tool answer: %v", err))
+		return
+	}
+	if err := writeToolRequests(issueDir, nil); err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Clear tool requests: %v", err))
+		return
+	}
+
+	w.backupBeforeRun(ctx, eventCh, num, workdir, w.manager.BaseBranch(w.cfg.Repo, workdir))
+
+	if approval == ApprovalDeny {
+		w.emit(eventCh, EventClaudeStart, num, "Resuming Claude Code without the tools...")
+	} else {
+		w.emit(eventCh, EventClaudeStart, num, "Resuming Claude Code with "+strings.Join(rules, ", ")+"...")
+	}
+	if w.runClaude(ctx, eventCh, run, num, workdir, promptFile, tools, true) && w.runStages(ctx, eventCh, num, workdir, repoCfg) {
+		w.finishRun(eventCh, num, workdir, started)
+	}
+}
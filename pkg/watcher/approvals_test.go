@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionToolRequests(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workdir := filepath.Join(t.TempDir(), "repo")
+	dir, _ := claudeProjectDir(workdir)
+	os.MkdirAll(dir, 0o755)
+	refused := func(id string) string {
+		return `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"` + id + `","is_error":true,"content":"Claude requested permissions to use Bash, but you haven't granted it yet."}]}}`
+	}
+	session := strings.Join([]string{
+		`{"type":"user","message":{"role":"user","content":"fix it"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"curl example.com"}}]}}`,
+		refused("t1"),
+		// Resumed: only what was refused since counts.
+		`{"type":"user","message":{"role":"user","content":"go on"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"npm install left-pad\nnpm test"}}]}}`,
+		refused("t2"),
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t3","name":"WebFetch","input":{"url":"https://example.com"}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","is_error":true,"content":[{"type":"text","text":"Claude requested permissions to use WebFetch, but you haven't granted it yet."}]}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t4","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t4","is_error":true,"content":"FAIL"}]}}`,
+	}, "\n") + "\n"
+	os.WriteFile(filepath.Join(dir, "s.jsonl"), []byte(session), 0o644)
+
+	reqs, err := SessionToolRequests(workdir)
+	if err != nil {
+		t.Fatalf("SessionToolRequests: %v", err)
+	}
+	want := []ToolRequest{{Tool: "Bash", Input: "npm install left-pad"}, {Tool: "WebFetch", Input: "https://example.com"}}
+	if len(reqs) != len(want) || reqs[0] != want[0] || reqs[1] != want[1] {
+		t.Fatalf("requests = %+v, want %+v", reqs, want)
+	}
+	if rules := ToolRules(reqs); strings.Join(rules, ",") != "Bash(npm install:*),WebFetch" {
+		t.Errorf("rules = %v", rules)
+	}
+}
+
+func TestToolRequestRule(t *testing.T) {
+	for cmd, want := range map[string]string{
+		"npm install left-pad": "Bash(npm install:*)",
+		"ls -la":               "Bash(ls:*)",
+		"make":                 "Bash(make:*)",
+		"git -C dir status":    "Bash(git:*)",
+		"":                     "Bash",
+	} {
+		if got := (ToolRequest{Tool: "Bash", Input: cmd}).Rule(); got != want {
+			t.Errorf("Rule(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestApprovedTools(t *testing.T) {
+	base := t.TempDir()
+	if rules := ReadApprovedTools(base, "o/r"); rules != nil {
+		t.Fatalf("approved = %v with none", rules)
+	}
+	approveTools(base, "o/r", []string{"WebFetch"})
+	approveTools(base, "o/r", []string{"WebFetch", "Bash(npm install:*)"})
+	rules := ReadApprovedTools(base, "o/r")
+	if strings.Join(rules, ",") != "WebFetch,Bash(npm install:*)" {
+		t.Errorf("approved = %v", rules)
+	}
+	if got := withTools("Read, WebFetch", rules); got != "Read, WebFetch,Bash(npm install:*)" {
+		t.Errorf("withTools = %q", got)
+	}
+
+	issueDir := t.TempDir()
+	reqs := []ToolRequest{{Tool: "WebFetch", Input: "https://example.com"}}
+	writeToolRequests(issueDir, reqs)
+	if got := ReadToolRequests(issueDir); len(got) != 1 || got[0] != reqs[0] {
+		t.Errorf("requests = %+v", got)
+	}
+	writeToolRequests(issueDir, nil)
+	if got := ReadToolRequests(issueDir); got != nil {
+		t.Errorf("requests = %+v after clearing", got)
+	}
+}
@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BackupBranchPrefix names the branches that keep an issue branch's
+// earlier heads: agent/issue-42-backup-1, -2, …
+func BackupBranchPrefix(num int) string {
+	return IssueBranch(num) + "-backup-"
+}
+
+// BackupBranch points a new backup branch at workdir's HEAD before the
+// agent runs on it again, so a worse attempt can't lose a better one. It
+// returns the backup's name, or "" if HEAD has no commits of its own on
+// top of base. A HEAD that is already backed up reuses that backup.
+func BackupBranch(ctx context.Context, workdir string, num int, base string) (string, error) {
+	head, err := gitCmd(ctx, workdir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if _, err := gitCmd(ctx, workdir, nil, "merge-base", "--is-ancestor", head, "origin/"+base); err == nil {
+		return "", nil
+	}
+
+	prefix := BackupBranchPrefix(num)
+	refs, err := gitCmd(ctx, workdir, nil, "for-each-ref", "--format=%(objectname) %(refname:short)", "refs/heads/"+prefix+"*")
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	for _, line := range strings.Split(refs, "\n") {
+		sha, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if sha == head {
+			return name, nil
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, prefix)); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	name := fmt.Sprintf("%s%d", prefix, next)
+	if _, err := gitCmd(ctx, workdir, nil, "branch", name, head); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// backupBeforeRun backs up the issue branch and reports it in the log.
+func (w *Watcher) backupBeforeRun(ctx context.Context, eventCh chan<- Event, num int, workdir, base string) {
+	name, err := BackupBranch(ctx, workdir, num, base)
+	switch {
+	case err != nil:
+		w.emit(eventCh, EventCheckpoint, num, fmt.Sprintf("Branch backup failed: %v", err))
+	case name != "":
+		w.emit(eventCh, EventCheckpoint, num, fmt.Sprintf("Backed up %s as %s", IssueBranch(num), name))
+	}
+}
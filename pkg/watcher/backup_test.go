@@ -0,0 +1,51 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupBranch(t *testing.T) {
+	ctx := context.Background()
+	origin := t.TempDir()
+	gitIn(t, origin, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(origin, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, origin, "add", ".")
+	gitIn(t, origin, "commit", "-q", "-m", "init")
+
+	workdir := filepath.Join(t.TempDir(), "repo")
+	gitIn(t, origin, "clone", "-q", origin, workdir)
+	gitIn(t, workdir, "checkout", "-q", "-b", IssueBranch(7))
+
+	if name, err := BackupBranch(ctx, workdir, 7, "main"); err != nil || name != "" {
+		t.Errorf("no commits yet: got %q, %v; want no backup", name, err)
+	}
+
+	commit := func(content string) string {
+		os.WriteFile(filepath.Join(workdir, "a.txt"), []byte(content), 0o644)
+		gitIn(t, workdir, "commit", "-q", "-am", content)
+		return strings.TrimSpace(gitIn(t, workdir, "rev-parse", "HEAD"))
+	}
+	first := commit("two\n")
+
+	name, err := BackupBranch(ctx, workdir, 7, "main")
+	if err != nil || name != "agent/issue-7-backup-1" {
+		t.Fatalf("BackupBranch = %q, %v", name, err)
+	}
+	if again, _ := BackupBranch(ctx, workdir, 7, "main"); again != name {
+		t.Errorf("same head backed up again as %q", again)
+	}
+
+	// The second attempt rewrites the branch; the first survives.
+	gitIn(t, workdir, "reset", "-q", "--hard", "origin/main")
+	commit("three\n")
+	if name, _ := BackupBranch(ctx, workdir, 7, "main"); name != "agent/issue-7-backup-2" {
+		t.Errorf("second backup = %q", name)
+	}
+	if sha := strings.TrimSpace(gitIn(t, workdir, "rev-parse", "agent/issue-7-backup-1")); sha != first {
+		t.Errorf("backup-1 = %s, want %s", sha, first)
+	}
+}
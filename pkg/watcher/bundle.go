@@ -0,0 +1,222 @@
+package watcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// bundleVersion is bumped when the bundle layout changes incompatibly.
+const bundleVersion = 1
+
+// bundleMaxFile is the largest file a bundle takes; bigger ones are
+// listed as skipped.
+const bundleMaxFile = 8 << 20
+
+// BundleManifest describes a bundle, as its MANIFEST.json.
+type BundleManifest struct {
+	Version int               `json:"version"`
+	Repo    string            `json:"repo"`
+	Number  int               `json:"number"`
+	Base    string            `json:"base,omitempty"`
+	Created time.Time         `json:"created"`
+	Files   []string          `json:"files"`
+	Skipped map[string]string `json:"skipped,omitempty"` // what was left out, and why
+}
+
+// secretPatterns match tokens that must never leave the machine.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bxox[abpr]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`(?i)\b(bearer|token)(\s+|=|:\s*)[A-Za-z0-9._~+/-]{20,}`),
+}
+
+// emailPattern matches email addresses, e.g. in commit trailers.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// anonymizer scrubs what a bundle's text says about the machine and
+// people it came from.
+type anonymizer struct {
+	paths *strings.Replacer
+}
+
+// newAnonymizer replaces baseDir with $LURKER_DIR and the home directory
+// with ~.
+func newAnonymizer(baseDir string) anonymizer {
+	pairs := []string{baseDir, "$LURKER_DIR"}
+	if home, err := os.UserHomeDir(); err == nil && home != "" && home != "/" {
+		pairs = append(pairs, home, "~")
+	}
+	return anonymizer{paths: strings.NewReplacer(pairs...)}
+}
+
+// redact blanks out tokens only, for text that must stay as it is
+// otherwise, such as a diff.
+func (a anonymizer) redact(s string) string {
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, "[redacted]")
+	}
+	return s
+}
+
+// scrub redacts tokens and replaces email addresses and local paths.
+func (a anonymizer) scrub(s string) string {
+	s = a.paths.Replace(a.redact(s))
+	return emailPattern.ReplaceAllString(s, "[email]")
+}
+
+// WriteBundle writes repo#num as a gzipped tarball to w, for attaching to
+// a bug report: the files in its IssueDir (issue.json, lurker.log,
+// result.json and the like), the Claude sessions run in its worktree and
+// the diff of its worktree against the base branch. Text is anonymized:
+// tokens are redacted, and email addresses and local paths replaced.
+// Parts that can't be read are listed in the manifest rather than failing
+// the bundle.
+func WriteBundle(ctx context.Context, w io.Writer, baseDir, repo string, num int) (BundleManifest, error) {
+	issueDir := IssueDir(baseDir, repo, num)
+	if _, err := os.Stat(issueDir); err != nil {
+		return BundleManifest{}, fmt.Errorf("no such issue: %w", err)
+	}
+	man := BundleManifest{Version: bundleVersion, Repo: repo, Number: num, Created: time.Now().UTC(), Skipped: map[string]string{}}
+	anon := newAnonymizer(baseDir)
+	prefix := fmt.Sprintf("lurker-%s-%d/", strings.ReplaceAll(repo, "/", "-"), num)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name, content string) error {
+		hdr := &tar.Header{Name: prefix + name, Mode: 0o644, Size: int64(len(content)), ModTime: man.Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(tw, content); err != nil {
+			return err
+		}
+		man.Files = append(man.Files, name)
+		return nil
+	}
+
+	entries, err := os.ReadDir(issueDir)
+	if err != nil {
+		return man, err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, reason := readBundleFile(filepath.Join(issueDir, e.Name()))
+		if reason != "" {
+			man.Skipped[e.Name()] = reason
+			continue
+		}
+		if err := add(e.Name(), anon.scrub(data)); err != nil {
+			return man, err
+		}
+	}
+
+	workdir := existingDir(IssueWorkdir(baseDir, repo, num))
+	if workdir == "" {
+		man.Skipped["diff.patch"] = "no worktree"
+	} else {
+		man.Base = savedBaseBranch(baseDir, repo, workdir)
+		if diff, err := worktreeDiff(ctx, issueDir, workdir, man.Base); err != nil {
+			man.Skipped["diff.patch"] = err.Error()
+		} else if err := add("diff.patch", anon.redact(diff)); err != nil {
+			return man, err
+		}
+	}
+
+	sessions, err := sessionFiles(IssueWorkdir(baseDir, repo, num))
+	if err != nil {
+		man.Skipped["transcripts/"] = err.Error()
+	}
+	for _, path := range sessions {
+		name := "transcripts/" + filepath.Base(path)
+		data, reason := readBundleFile(path)
+		if reason != "" {
+			man.Skipped[name] = reason
+			continue
+		}
+		if err := add(name, anon.scrub(data)); err != nil {
+			return man, err
+		}
+	}
+
+	data, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return man, err
+	}
+	if err := add("MANIFEST.json", string(data)+"\n"); err != nil {
+		return man, err
+	}
+	if err := tw.Close(); err != nil {
+		return man, err
+	}
+	return man, gz.Close()
+}
+
+// readBundleFile reads a text file for a bundle, or says why it won't go
+// in.
+func readBundleFile(path string) (string, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err.Error()
+	}
+	if info.Size() > bundleMaxFile {
+		return "", fmt.Sprintf("%d bytes, over the %d limit", info.Size(), bundleMaxFile)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err.Error()
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return "", "binary"
+	}
+	return string(data), ""
+}
+
+// worktreeDiff is every change in workdir since origin/base, committed or
+// not.
+func worktreeDiff(ctx context.Context, scratch, workdir, base string) (string, error) {
+	_, commit, err := worktreeCommit(ctx, workdir, scratch, "lurker bundle")
+	if err != nil {
+		return "", err
+	}
+	return gitRaw(ctx, workdir, nil, "diff", "origin/"+base, commit)
+}
+
+// sessionFiles returns the Claude Code sessions run in workdir.
+func sessionFiles(workdir string) ([]string, error) {
+	dir, err := claudeProjectDir(workdir)
+	if err != nil {
+		return nil, err
+	}
+	return filepath.Glob(filepath.Join(dir, "*.jsonl"))
+}
+
+// savedBaseBranch is BaseBranch for when lurker isn't running: the base
+// chosen for repo, else its config's, else its bare clone's HEAD.
+func savedBaseBranch(baseDir, repo, workdir string) string {
+	layout := LoadLayout(baseDir)
+	if b := loadState(layout.StatePath()).BaseBranches[repo]; b != "" {
+		return b
+	}
+	if b := LoadRepoConfig(workdir).BaseBranch; b != "" {
+		return b
+	}
+	bare := layout.BareDir(repo)
+	if b, err := gitCmd(context.Background(), bare, nil, "symbolic-ref", "--short", "HEAD"); err == nil && validBranchName(b) {
+		return b
+	}
+	return defaultBaseBranch
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteBundle(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	base := t.TempDir()
+	dir := IssueDir(base, "o/r", 5)
+	workdir := IssueWorkdir(base, "o/r", 5)
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	gitIn(t, base, "init", "-q", "--bare", "-b", "main", origin)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "clone", "-q", origin, workdir)
+	gitIn(t, workdir, "checkout", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", "a.txt")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+	gitIn(t, workdir, "push", "-q", "origin", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+
+	token := "ghp_" + strings.Repeat("x", 36)
+	os.WriteFile(filepath.Join(dir, LogFile), []byte("▶ Started in "+workdir+"\n❌ push: Bearer "+token+"\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "issue.json"), []byte(`{"repo": "o/r", "number": 5}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "agent.bundle"), []byte("PACK\x00\x01"), 0o644)
+	sessions, _ := claudeProjectDir(workdir)
+	os.MkdirAll(sessions, 0o755)
+	os.WriteFile(filepath.Join(sessions, "s1.jsonl"), []byte(`{"text": "mail alice@example.com from `+home+`/src"}`+"\n"), 0o644)
+
+	var buf bytes.Buffer
+	man, err := WriteBundle(context.Background(), &buf, base, "o/r", 5)
+	if err != nil {
+		t.Fatalf("WriteBundle: %v", err)
+	}
+	if man.Base != "main" || man.Skipped["agent.bundle"] != "binary" {
+		t.Errorf("manifest = %+v", man)
+	}
+
+	files := map[string]string{}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		name, ok := strings.CutPrefix(hdr.Name, "lurker-o-r-5/")
+		if !ok {
+			t.Errorf("entry %q outside the bundle's directory", hdr.Name)
+		}
+		files[name] = string(data)
+	}
+
+	log := files[LogFile]
+	if strings.Contains(log, token) || !strings.Contains(log, "push: Bearer [redacted]") {
+		t.Errorf("token not redacted: %q", log)
+	}
+	if want := "$LURKER_DIR/o/r/5/r"; !strings.Contains(log, want) {
+		t.Errorf("log = %q, want %s in it", log, want)
+	}
+	if tr := files["transcripts/s1.jsonl"]; tr != `{"text": "mail [email] from ~/src"}`+"\n" {
+		t.Errorf("transcript = %q", tr)
+	}
+	if d := files["diff.patch"]; !strings.Contains(d, "-one\n+two\n") {
+		t.Errorf("diff = %q", d)
+	}
+	if _, ok := files["agent.bundle"]; ok {
+		t.Error("binary file bundled")
+	}
+	var got BundleManifest
+	if err := json.Unmarshal([]byte(files["MANIFEST.json"]), &got); err != nil || got.Repo != "o/r" || len(got.Files) != len(files)-1 {
+		t.Errorf("MANIFEST.json = %s (%v)", files["MANIFEST.json"], err)
+	}
+}
@@ -0,0 +1,346 @@
+package watcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// BwrapSocket, in the state directory, is where the proxy that bwrap
+// sandboxes reach the network through listens.
+const BwrapSocket = "net.sock"
+
+// bwrapSocket is where BwrapSocket is mounted in a sandbox.
+const bwrapSocket = "/tmp/lurker-net.sock"
+
+// sandboxNetCommand is the lurker subcommand a command in a bwrap sandbox
+// is run under (see SandboxNet).
+const sandboxNetCommand = "sandbox-net"
+
+// sandboxHosts are the domains, with their subdomains, that commands in a
+// bwrap sandbox may connect to: Claude's, Codex's and GitHub's.
+var sandboxHosts = []string{"anthropic.com", "claude.ai", "claude.com", "openai.com", "chatgpt.com", "github.com", "githubusercontent.com"}
+
+// bwrapHomeRW and bwrapHomeRO are what a bwrap sandbox sees of the home
+// directory, read-write and read-only: Codex's login and sessions, and
+// Claude's, of which it writes only what claudeState gives it, and git's
+// and gh's config.
+var (
+	bwrapHomeRW = []string{".codex"}
+	bwrapHomeRO = []string{".claude", ".claude.json", ".gitconfig", ".config/git", ".config/gh"}
+)
+
+// bwrapClaudeCopies are the files, in the home directory, of Claude's a
+// bwrap sandbox has its own writable copy of, taken as it starts: its
+// login, which it refreshes, and the settings and state it keeps per
+// project.
+var bwrapClaudeCopies = []string{".claude.json", ".claude/.credentials.json"}
+
+// bwrapClaudeScratch are the directories in ~/.claude that Claude writes
+// as it runs, which a bwrap sandbox has its own of.
+var bwrapClaudeScratch = []string{"todos", "shell-snapshots", "statsig"}
+
+// A Bwrap confines commands with bubblewrap, for those who would rather
+// not run Docker (see RepoConfig.SandboxImage): a command sees the system
+// read-only, of the home directory only what bwrapHomeRW and bwrapHomeRO
+// list, and of lurker's files only its worktree, which it may write. Of
+// Claude's state it may write only its own, kept in the IssueDir's
+// SandboxHome, and its sessions. It has no network but lurker's proxy,
+// which connects only to sandboxHosts.
+type Bwrap struct {
+	exe    string // lurker, run in the sandbox as sandboxNetCommand
+	socket string // the proxy's
+	ln     net.Listener
+}
+
+// StartBwrap starts the proxy of l's bwrap sandboxes. Close stops it.
+func StartBwrap(l Layout) (*Bwrap, error) {
+	if _, err := exec.LookPath("bwrap"); err != nil {
+		return nil, fmt.Errorf("bwrap sandbox: %w", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("bwrap sandbox: %w", err)
+	}
+	socket := filepath.Join(l.resolved().State, BwrapSocket)
+	// One left by a lurker that didn't exit cleanly.
+	os.Remove(socket)
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("bwrap sandbox: %w", err)
+	}
+	go serveProxy(ln, sandboxHostAllowed)
+	return &Bwrap{exe: exe, socket: socket, ln: ln}, nil
+}
+
+// Close stops b's proxy; commands still in its sandboxes lose the network.
+func (b *Bwrap) Close() error {
+	return b.ln.Close()
+}
+
+// SetBwrap has Claude and the issues' shells run in b's sandboxes from
+// now on, or not if b is nil.
+func (m *Manager) SetBwrap(b *Bwrap) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bwrap = b
+}
+
+// Bwrap returns the sandbox set with SetBwrap, nil if there is none.
+func (m *Manager) Bwrap() *Bwrap {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bwrap
+}
+
+// bwrap returns the sandbox Claude runs in, nil if there is none.
+func (w *Watcher) bwrap() *Bwrap {
+	if w.manager == nil {
+		return nil
+	}
+	return w.manager.Bwrap()
+}
+
+// Argv returns the command line that runs argv in workdir in a sandbox of
+// b's, which can also read the paths in readable. A worktree can
+// commit to its bare clone, whose config and hooks, which lurker's own git
+// commands run, stay read-only; the clones it borrows objects from are
+// readable.
+func (b *Bwrap) Argv(workdir string, argv []string, readable ...string) []string {
+	args := []string{"bwrap", "--die-with-parent", "--unshare-all",
+		"--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+	ro := func(path string) {
+		if path != "" {
+			args = append(args, "--ro-bind-try", path, path)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--tmpfs", home)
+		for _, name := range bwrapHomeRW {
+			args = append(args, "--bind-try", filepath.Join(home, name), filepath.Join(home, name))
+		}
+		for _, name := range bwrapHomeRO {
+			ro(filepath.Join(home, name))
+		}
+		args = append(args, claudeState(home, filepath.Join(filepath.Dir(workdir), SandboxHome), workdir)...)
+		// Tools installed in the home directory, and whatever the
+		// command's own links lead to there.
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if strings.HasPrefix(dir, home+string(filepath.Separator)) {
+				ro(dir)
+			}
+		}
+		if path, err := exec.LookPath(argv[0]); err == nil {
+			if path, err := filepath.EvalSymlinks(path); err == nil && strings.HasPrefix(path, home+string(filepath.Separator)) {
+				ro(filepath.Dir(path))
+			}
+		}
+	}
+	ro(b.exe)
+	for _, dir := range readable {
+		ro(dir)
+	}
+	args = append(args, "--bind", workdir, workdir)
+	if gitDir, common := gitDirs(workdir); common != "" {
+		if !strings.HasPrefix(common, workdir+string(filepath.Separator)) {
+			ro(common)
+			for _, dir := range []string{"objects", "refs", "logs"} {
+				args = append(args, "--bind-try", filepath.Join(common, dir), filepath.Join(common, dir))
+			}
+			args = append(args, "--bind", gitDir, gitDir)
+		}
+		for _, dir := range readAlternates(objectsDir(common), objectsDir(common)) {
+			ro(dir)
+		}
+	}
+	args = append(args, "--bind", b.socket, bwrapSocket, "--chdir", workdir, "--", b.exe, sandboxNetCommand, bwrapSocket)
+	return append(args, argv...)
+}
+
+// claudeState returns the bwrap flags that let the sandbox of workdir,
+// with home read-only, write the Claude state it needs: the directory of
+// its sessions, which lurker reads and resumes, and its own copies, in
+// dir, of bwrapClaudeCopies and bwrapClaudeScratch. What can't be set up
+// is left read-only.
+func claudeState(home, dir, workdir string) []string {
+	var args []string
+	bind := func(src, dst string) {
+		args = append(args, "--bind", src, dst)
+	}
+	for _, name := range bwrapClaudeCopies {
+		src, dst := filepath.Join(home, name), filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			continue
+		}
+		// A fresh copy; sandboxes still running keep theirs.
+		os.Remove(dst)
+		if copyFile(src, dst, 0o600) == nil {
+			bind(dst, src)
+		}
+	}
+	for _, name := range bwrapClaudeScratch {
+		src, dst := filepath.Join(home, ".claude", name), filepath.Join(dir, ".claude", name)
+		if os.MkdirAll(src, 0o700) == nil && os.MkdirAll(dst, 0o700) == nil {
+			bind(dst, src)
+		}
+	}
+	if sessions, err := claudeProjectDir(workdir); err == nil && os.MkdirAll(sessions, 0o700) == nil {
+		bind(sessions, sessions)
+	}
+	return args
+}
+
+// gitDirs returns workdir's git directory and the one its objects and
+// refs are in: a worktree's, in its bare clone, and the bare clone, or a
+// clone's .git twice. They are "" if workdir is neither.
+func gitDirs(workdir string) (gitDir, common string) {
+	gitDir = filepath.Join(workdir, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", ""
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return "", ""
+		}
+		gitDir = strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(workdir, gitDir)
+		}
+	}
+	gitDir = filepath.Clean(gitDir)
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir, gitDir
+	}
+	common = strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	return gitDir, filepath.Clean(common)
+}
+
+// sandboxHostAllowed reports whether a bwrap sandbox may connect to
+// hostport: HTTPS to one of sandboxHosts.
+func sandboxHostAllowed(hostport string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil || port != "443" {
+		return false
+	}
+	host = strings.ToLower(host)
+	for _, domain := range sandboxHosts {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveProxy serves HTTP CONNECT requests on ln until it is closed,
+// tunnelling to the addresses allowed accepts and refusing the rest.
+func serveProxy(ln net.Listener, allowed func(hostport string) bool) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go proxyConn(conn, allowed)
+	}
+}
+
+func proxyConn(conn net.Conn, allowed func(hostport string) bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	req, err := http.ReadRequest(r)
+	if err != nil {
+		return
+	}
+	if req.Method != http.MethodConnect || !allowed(req.Host) {
+		msg := fmt.Sprintf("lurker's sandbox doesn't connect to %s\n", req.Host)
+		fmt.Fprintf(conn, "HTTP/1.1 403 Forbidden\r\nContent-Length: %d\r\n\r\n%s", len(msg), msg)
+		return
+	}
+	upstream, err := net.DialTimeout("tcp", req.Host, 30*time.Second)
+	if err != nil {
+		msg := err.Error() + "\n"
+		fmt.Fprintf(conn, "HTTP/1.1 502 Bad Gateway\r\nContent-Length: %d\r\n\r\n%s", len(msg), msg)
+		return
+	}
+	defer upstream.Close()
+	fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	splice(struct {
+		io.Reader
+		io.Writer
+	}{r, conn}, upstream)
+}
+
+// splice copies between a and b both ways until either side is done.
+func splice(a, b io.ReadWriter) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// SandboxNet runs argv, in a bwrap sandbox, with a proxy on the sandbox's
+// loopback that forwards to socket, lurker's proxy outside it, and returns
+// its exit code.
+func SandboxNet(socket string, argv []string) (int, error) {
+	if len(argv) == 0 {
+		return 0, fmt.Errorf("no command")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("unix", socket)
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				splice(conn, upstream)
+			}()
+		}
+	}()
+
+	// ^C and ^\ are the command's; this only has to outlive it.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGINT, syscall.SIGQUIT)
+	proxy := "http://" + ln.Addr().String()
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		"HTTPS_PROXY="+proxy, "https_proxy="+proxy, "HTTP_PROXY="+proxy, "http_proxy="+proxy,
+		"NO_PROXY=localhost,127.0.0.1", "no_proxy=localhost,127.0.0.1")
+	if err := cmd.Run(); err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			return exit.ExitCode(), nil
+		}
+		return 0, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSandboxHostAllowed(t *testing.T) {
+	for hostport, want := range map[string]bool{
+		"api.anthropic.com:443":             true,
+		"github.com:443":                    true,
+		"objects.githubusercontent.com:443": true,
+		"GitHub.com:443":                    true,
+		"github.com:80":                     false,
+		"github.com":                        false,
+		"evilgithub.com:443":                false,
+		"github.com.example.org:443":        false,
+		"example.com:443":                   false,
+	} {
+		if got := sandboxHostAllowed(hostport); got != want {
+			t.Errorf("sandboxHostAllowed(%q) = %v, want %v", hostport, got, want)
+		}
+	}
+}
+
+func TestSandboxProxy(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	socket := filepath.Join(t.TempDir(), BwrapSocket)
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveProxy(ln, func(hostport string) bool { return hostport == echo.Addr().String() })
+
+	connect := func(hostport string) (net.Conn, *bufio.Reader, int) {
+		t.Helper()
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", hostport, hostport)
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, nil)
+		if err != nil {
+			t.Fatalf("CONNECT %s: %v", hostport, err)
+		}
+		return conn, r, resp.StatusCode
+	}
+
+	conn, r, code := connect(echo.Addr().String())
+	defer conn.Close()
+	if code != http.StatusOK {
+		t.Fatalf("CONNECT to an allowed address = %d", code)
+	}
+	fmt.Fprint(conn, "hello\n")
+	if line, err := r.ReadString('\n'); err != nil || line != "hello\n" {
+		t.Errorf("through the tunnel: %q, %v", line, err)
+	}
+
+	refused, _, code := connect("example.com:443")
+	refused.Close()
+	if code != http.StatusForbidden {
+		t.Errorf("CONNECT to another address = %d", code)
+	}
+}
+
+func TestBwrapArgv(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+	bare := filepath.Join(root, "bare.git")
+	gitIn(t, root, "clone", "-q", "--bare", src, bare)
+	workdir := filepath.Join(root, "7", "repo")
+	gitIn(t, bare, "worktree", "add", "-q", "-b", "agent/issue-7", workdir)
+
+	home := filepath.Join(root, "home")
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".claude"), 0o700)
+	os.WriteFile(filepath.Join(home, ".claude.json"), []byte("{}"), 0o600)
+	os.WriteFile(filepath.Join(home, ".claude", ".credentials.json"), []byte("token"), 0o600)
+
+	b := &Bwrap{exe: "/usr/bin/lurker", socket: filepath.Join(root, BwrapSocket)}
+	args := b.Argv(workdir, []string{"sh", "-l"}, "/etc/lurker")
+	mounts := func(flag, src, dst string) bool {
+		for i := 0; i+2 < len(args); i++ {
+			if args[i] == flag && args[i+1] == src && args[i+2] == dst {
+				return true
+			}
+		}
+		return false
+	}
+	has := func(flag, path string) bool { return mounts(flag, path, path) }
+	for _, bind := range []struct{ flag, path string }{
+		{"--bind", workdir},
+		{"--ro-bind-try", bare},
+		{"--bind-try", filepath.Join(bare, "objects")},
+		{"--bind", filepath.Join(bare, "worktrees", "repo")},
+		{"--ro-bind-try", "/etc/lurker"},
+	} {
+		if !has(bind.flag, bind.path) {
+			t.Errorf("no %s %s in %q", bind.flag, bind.path, args)
+		}
+	}
+	// Claude's state is read-only but for the sandbox's own copies of its
+	// login and settings, and its sessions.
+	sessions, _ := claudeProjectDir(workdir)
+	state := filepath.Join(root, "7", SandboxHome)
+	if !has("--ro-bind-try", filepath.Join(home, ".claude")) || !has("--ro-bind-try", filepath.Join(home, ".claude.json")) || has("--bind-try", filepath.Join(home, ".claude")) {
+		t.Errorf("~/.claude not read-only: %q", args)
+	}
+	for _, name := range []string{".claude.json", ".claude/.credentials.json"} {
+		if !mounts("--bind", filepath.Join(state, name), filepath.Join(home, name)) {
+			t.Errorf("no writable copy of %s in %q", name, args)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, ".claude", ".credentials.json")); string(data) != "token" {
+		t.Errorf("copied login = %q", data)
+	}
+	if !has("--bind", sessions) {
+		t.Errorf("sessions not writable: %q", args)
+	}
+
+	// The bare clone is read-only but for what committing writes.
+	if has("--bind", bare) || has("--bind-try", filepath.Join(bare, "hooks")) {
+		t.Errorf("bare clone writable: %q", args)
+	}
+	want := []string{"--", "/usr/bin/lurker", sandboxNetCommand, bwrapSocket, "sh", "-l"}
+	if i := slices.Index(args, "--"); i < 0 || !slices.Equal(args[i:], want) {
+		t.Errorf("command = %q, want it to end %q", args, want)
+	}
+	if args[0] != "bwrap" || !slices.Contains(args, "--unshare-all") {
+		t.Errorf("not confined: %q", args)
+	}
+}
+
+func TestSandboxNet(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "proxy")
+	code, err := SandboxNet("/nonexistent.sock", []string{"sh", "-c", `printf %s "$HTTPS_PROXY" > "$0"; exit 3`, out})
+	if err != nil || code != 3 {
+		t.Fatalf("SandboxNet = %d, %v", code, err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(data), "http://127.0.0.1:") {
+		t.Errorf("HTTPS_PROXY = %q", data)
+	}
+}
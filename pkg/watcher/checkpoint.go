@@ -0,0 +1,244 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CheckpointsDir is where an issue's checkpoints live inside its IssueDir.
+// Each checkpoint is three files named by its ID: a .json description, a
+// git .bundle holding the snapshot commit (so it survives the bare clone
+// being pruned or re-cloned) and a .patch of the uncommitted changes for
+// reading.
+const CheckpointsDir = "checkpoints"
+
+// Checkpoint is a snapshot of an issue's worktree: the branch's commit
+// plus every uncommitted change, untracked files included (ignored files
+// are not).
+type Checkpoint struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+	Branch    string    `json:"branch,omitempty"` // "" if HEAD was detached
+	Head      string    `json:"head"`             // commit HEAD pointed at
+	Commit    string    `json:"commit"`           // Head plus the working tree
+}
+
+// ref is where the snapshot commit is kept in the repo, so gc leaves it.
+func (c Checkpoint) ref(issueDir string) string {
+	return "refs/lurker/checkpoints/" + filepath.Base(issueDir) + "/" + c.ID
+}
+
+// CreateCheckpoint snapshots workdir into issueDir's checkpoints. base is
+// the branch the issue branches from; the bundle leaves out its history.
+func CreateCheckpoint(ctx context.Context, issueDir, workdir, base, label string) (Checkpoint, error) {
+	dir := filepath.Join(issueDir, CheckpointsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Checkpoint{}, err
+	}
+	now := time.Now()
+	id := now.UTC().Format("20060102-150405")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); err != nil {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", now.UTC().Format("20060102-150405"), n)
+	}
+
+	head, commit, err := worktreeCommit(ctx, workdir, dir, "lurker checkpoint: "+label)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	branch, _ := gitCmd(ctx, workdir, nil, "symbolic-ref", "--short", "-q", "HEAD")
+	c := Checkpoint{ID: id, Label: label, CreatedAt: now, Branch: branch, Head: head, Commit: commit}
+
+	if _, err := gitCmd(ctx, workdir, nil, "update-ref", c.ref(issueDir), c.Commit); err != nil {
+		return Checkpoint{}, err
+	}
+
+	bundle := []string{"bundle", "create", "-q", filepath.Join(dir, id+".bundle"), c.ref(issueDir)}
+	if _, err := gitCmd(ctx, workdir, nil, "rev-parse", "--verify", "-q", "origin/"+base); err == nil {
+		bundle = append(bundle, "^origin/"+base)
+	}
+	if _, err := gitCmd(ctx, workdir, nil, bundle...); err != nil {
+		return Checkpoint{}, err
+	}
+	patch, err := gitRaw(ctx, workdir, nil, "diff", "--binary", head, c.Commit)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".patch"), []byte(patch), 0o644); err != nil {
+		return Checkpoint{}, err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return c, os.WriteFile(filepath.Join(dir, id+".json"), append(data, '\n'), 0o644)
+}
+
+// ListCheckpoints returns issueDir's checkpoints, newest first.
+func ListCheckpoints(issueDir string) ([]Checkpoint, error) {
+	files, err := filepath.Glob(filepath.Join(issueDir, CheckpointsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var list []Checkpoint
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c Checkpoint
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+// RestoreCheckpoint puts workdir back the way checkpoint id found it: the
+// branch is reset to its commit then, and the working tree to its files,
+// with changes that were uncommitted left uncommitted. Untracked files
+// made since are deleted; ignored files are kept.
+func RestoreCheckpoint(ctx context.Context, issueDir, workdir, id string) (Checkpoint, error) {
+	dir := filepath.Join(issueDir, CheckpointsDir)
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, fmt.Errorf("no checkpoint %q", id)
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Checkpoint{}, err
+	}
+
+	// The ref goes if the bare clone is re-made; the bundle brings it back.
+	if _, err := gitCmd(ctx, workdir, nil, "cat-file", "-e", c.Commit+"^{commit}"); err != nil {
+		ref := c.ref(issueDir)
+		if _, err := gitCmd(ctx, workdir, nil, "fetch", "-q", filepath.Join(dir, id+".bundle"), ref+":"+ref); err != nil {
+			return Checkpoint{}, fmt.Errorf("checkpoint %s is gone from the repo and its bundle: %w", id, err)
+		}
+	}
+
+	if c.Branch != "" {
+		if current, _ := gitCmd(ctx, workdir, nil, "symbolic-ref", "--short", "-q", "HEAD"); current != c.Branch {
+			if _, err := gitCmd(ctx, workdir, nil, "checkout", "-q", "-f", c.Branch); err != nil {
+				return Checkpoint{}, err
+			}
+		}
+	}
+	for _, args := range [][]string{
+		{"reset", "-q", "--hard", c.Commit},
+		{"clean", "-q", "-f", "-d"},
+		{"reset", "-q", c.Head},
+	} {
+		if _, err := gitCmd(ctx, workdir, nil, args...); err != nil {
+			return Checkpoint{}, err
+		}
+	}
+	return c, nil
+}
+
+// worktreeCommit records workdir as it is — HEAD plus every uncommitted
+// change, untracked files included — in a commit on top of HEAD, without
+// touching the branch or the index. scratch holds a temporary index.
+func worktreeCommit(ctx context.Context, workdir, scratch, message string) (head, commit string, err error) {
+	if head, err = gitCmd(ctx, workdir, nil, "rev-parse", "HEAD"); err != nil {
+		return "", "", err
+	}
+
+	// Stage everything into a copy of the index, so the worktree's own
+	// index (and any sparse-checkout bits in it) is left alone.
+	index, err := gitCmd(ctx, workdir, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", "", err
+	}
+	f, err := os.CreateTemp(scratch, ".index-*")
+	if err != nil {
+		return "", "", err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if data, err := os.ReadFile(index); err == nil {
+		if err := os.WriteFile(f.Name(), data, 0o644); err != nil {
+			return "", "", err
+		}
+	} else {
+		os.Remove(f.Name()) // git treats an empty file as a corrupt index
+	}
+	env := []string{
+		"GIT_INDEX_FILE=" + f.Name(),
+		"GIT_AUTHOR_NAME=lurker", "GIT_AUTHOR_EMAIL=lurker@localhost",
+		"GIT_COMMITTER_NAME=lurker", "GIT_COMMITTER_EMAIL=lurker@localhost",
+	}
+	if _, err := gitCmd(ctx, workdir, env, "add", "-A"); err != nil {
+		return "", "", err
+	}
+	tree, err := gitCmd(ctx, workdir, env, "write-tree")
+	if err != nil {
+		return "", "", err
+	}
+	commit, err = gitCmd(ctx, workdir, env, "commit-tree", tree, "-p", head, "-m", message)
+	return head, commit, err
+}
+
+// gitCmd runs git in dir with extra environment and returns its trimmed
+// output.
+func gitCmd(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	out, err := gitRaw(ctx, dir, env, args...)
+	return strings.TrimSpace(out), err
+}
+
+// gitRaw is gitCmd without trimming, for output such as patches.
+func gitRaw(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return string(out), nil
+}
+
+// Checkpoint snapshots the worktree of repo#num with label.
+func (m *Manager) Checkpoint(repo string, num int, label string) (Checkpoint, error) {
+	workdir := existingDir(IssueWorkdir(m.baseDir, repo, num))
+	if workdir == "" {
+		return Checkpoint{}, errors.New("no worktree yet")
+	}
+	return CreateCheckpoint(context.Background(), IssueDir(m.baseDir, repo, num), workdir, m.BaseBranch(repo, workdir), label)
+}
+
+// RestoreCheckpoint rolls the worktree of repo#num back to checkpoint id,
+// checkpointing its current state first so the restore can be undone.
+// The issue must not be running.
+func (m *Manager) RestoreCheckpoint(repo string, num int, id string) (Checkpoint, error) {
+	workdir := existingDir(IssueWorkdir(m.baseDir, repo, num))
+	if workdir == "" {
+		return Checkpoint{}, errors.New("no worktree yet")
+	}
+	if _, err := m.Checkpoint(repo, num, "before restoring "+id); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint before restore: %w", err)
+	}
+	return RestoreCheckpoint(context.Background(), IssueDir(m.baseDir, repo, num), workdir, id)
+}
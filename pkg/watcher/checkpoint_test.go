@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoint_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	issueDir := t.TempDir()
+	workdir := filepath.Join(issueDir, "repo")
+	os.MkdirAll(workdir, 0o755)
+	write := func(name, content string) {
+		os.WriteFile(filepath.Join(workdir, name), []byte(content), 0o644)
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(workdir, name))
+		return string(data)
+	}
+
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	write("a.txt", "one\n")
+	write(".gitignore", "build/\n")
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+	gitIn(t, workdir, "checkout", "-q", "-b", "lurker/issue-1")
+	write("a.txt", "two\n")
+	gitIn(t, workdir, "commit", "-q", "-am", "two")
+	write("a.txt", "three\n")
+	write("new.txt", "untracked\n")
+
+	c, err := CreateCheckpoint(ctx, issueDir, workdir, "main", "before takeover")
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	if c.Branch != "lurker/issue-1" || c.Head == "" || c.Commit == c.Head {
+		t.Errorf("checkpoint = %+v", c)
+	}
+	for _, ext := range []string{".json", ".bundle", ".patch"} {
+		if _, err := os.Stat(filepath.Join(issueDir, CheckpointsDir, c.ID+ext)); err != nil {
+			t.Errorf("missing %s: %v", ext, err)
+		}
+	}
+	if patch, _ := os.ReadFile(filepath.Join(issueDir, CheckpointsDir, c.ID+".patch")); !strings.Contains(string(patch), "+three") || !strings.Contains(string(patch), "new.txt") {
+		t.Errorf("patch = %s", patch)
+	}
+	if status := gitIn(t, workdir, "status", "--porcelain"); status != " M a.txt\n?? new.txt\n" {
+		t.Errorf("checkpoint touched the index: %q", status)
+	}
+
+	// A destructive retry: new commit, deleted file, stray files.
+	write("a.txt", "four\n")
+	gitIn(t, workdir, "commit", "-q", "-am", "four")
+	os.Remove(filepath.Join(workdir, "new.txt"))
+	write("junk.txt", "junk\n")
+	os.MkdirAll(filepath.Join(workdir, "build"), 0o755)
+	write("build/out", "ignored\n")
+
+	restore := func() {
+		t.Helper()
+		if _, err := RestoreCheckpoint(ctx, issueDir, workdir, c.ID); err != nil {
+			t.Fatalf("RestoreCheckpoint: %v", err)
+		}
+		if head := strings.TrimSpace(gitIn(t, workdir, "rev-parse", "HEAD")); head != c.Head {
+			t.Errorf("HEAD = %s, want %s", head, c.Head)
+		}
+		if got := read("a.txt"); got != "three\n" {
+			t.Errorf("a.txt = %q", got)
+		}
+		if status := gitIn(t, workdir, "status", "--porcelain"); status != " M a.txt\n?? new.txt\n" {
+			t.Errorf("status after restore = %q", status)
+		}
+		if read("build/out") != "ignored\n" {
+			t.Error("restore removed an ignored file")
+		}
+	}
+	restore()
+
+	// Without the ref and its objects, the bundle brings it back.
+	gitIn(t, workdir, "update-ref", "-d", c.ref(issueDir))
+	gitIn(t, workdir, "reflog", "expire", "--expire=now", "--all")
+	gitIn(t, workdir, "gc", "-q", "--prune=now")
+	write("a.txt", "five\n")
+	restore()
+
+	list, err := ListCheckpoints(issueDir)
+	if err != nil || len(list) != 1 || list[0].ID != c.ID || list[0].Label != "before takeover" {
+		t.Errorf("ListCheckpoints = %+v, %v", list, err)
+	}
+	if _, err := RestoreCheckpoint(ctx, issueDir, workdir, "nope"); err == nil {
+		t.Error("expected error restoring a missing checkpoint")
+	}
+}
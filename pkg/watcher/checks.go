@@ -0,0 +1,161 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// ChecksState is how the CI checks on an issue's PR stand.
+type ChecksState int
+
+const (
+	ChecksNone    ChecksState = iota // not watched, or no checks reported
+	ChecksPending                    // being watched, some still running
+	ChecksPassed
+	ChecksFailed
+)
+
+func (s ChecksState) String() string {
+	switch s {
+	case ChecksPending:
+		return "pending"
+	case ChecksPassed:
+		return "passed"
+	case ChecksFailed:
+		return "failed"
+	default:
+		return ""
+	}
+}
+
+// ParseChecksState is the inverse of ChecksState.String; anything else is
+// ChecksNone.
+func ParseChecksState(s string) ChecksState {
+	for st := ChecksPending; st <= ChecksFailed; st++ {
+		if st.String() == s {
+			return st
+		}
+	}
+	return ChecksNone
+}
+
+var (
+	// checksPollInterval is how often a PR's checks are polled.
+	checksPollInterval = 30 * time.Second
+	// checksStartWait is how long to wait for a PR's first check before
+	// deciding it has no CI.
+	checksStartWait = 10 * time.Minute
+	// checksTimeout bounds how long checks are watched in all.
+	checksTimeout = 3 * time.Hour
+	// checksMaxErrors is how many polls in a row may fail before watching
+	// gives up.
+	checksMaxErrors = 5
+)
+
+// SummarizeChecks returns how runs stand, and the names of the ones that
+// failed. One failure fails them all, even with others still running.
+// Neutral and skipped checks count as passing.
+func SummarizeChecks(runs []github.CheckRun) (ChecksState, []string) {
+	if len(runs) == 0 {
+		return ChecksNone, nil
+	}
+	pending := false
+	var failed []string
+	for _, r := range runs {
+		if r.Status != "completed" {
+			pending = true
+			continue
+		}
+		switch r.Conclusion {
+		case "success", "neutral", "skipped":
+		default:
+			failed = append(failed, r.Name)
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		return ChecksFailed, failed
+	case pending:
+		return ChecksPending, nil
+	}
+	return ChecksPassed, nil
+}
+
+// WatchChecks polls the CI checks on PR prNum, opened for repo#num, until
+// they pass, one fails or watching gives up, then emits EventChecksPassed,
+// EventChecksFailed or EventChecksNone. It does nothing if the issue's
+// checks are already being watched, and stops without a word if the repo
+// is removed meanwhile.
+func (m *Manager) WatchChecks(repo string, num, prNum int) {
+	key := IssueKey(repo, num)
+	m.mu.Lock()
+	if m.checkWatches[key] != nil {
+		m.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	m.checkWatches[key] = stop
+	m.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), checksTimeout)
+		defer cancel()
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		kind, text := m.watchChecks(ctx, repo, prNum)
+		m.mu.Lock()
+		if m.checkWatches[key] == stop {
+			delete(m.checkWatches, key)
+		}
+		m.mu.Unlock()
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		m.eventCh <- Event{Kind: kind, Repo: repo, IssueNum: num, Text: text, Timestamp: time.Now()}
+	}()
+}
+
+// watchChecks polls until the checks on repo's PR prNum settle, and
+// returns the event to report it with.
+func (m *Manager) watchChecks(ctx context.Context, repo string, prNum int) (EventKind, string) {
+	started := time.Now()
+	failures := 0
+	for {
+		runs, err := m.ghClient.PRChecks(ctx, repo, prNum)
+		if err != nil {
+			if failures++; failures >= checksMaxErrors {
+				return EventChecksNone, fmt.Sprintf("Stopped watching CI on PR #%d: %v", prNum, err)
+			}
+		} else {
+			failures = 0
+			state, failed := SummarizeChecks(runs)
+			switch state {
+			case ChecksPassed:
+				return EventChecksPassed, fmt.Sprintf("CI passed on PR #%d: %s", prNum, plural(len(runs), "check"))
+			case ChecksFailed:
+				return EventChecksFailed, fmt.Sprintf("CI failed on PR #%d: %s", prNum, strings.Join(failed, ", "))
+			case ChecksNone:
+				if time.Since(started) >= checksStartWait {
+					return EventChecksNone, fmt.Sprintf("No CI checks reported on PR #%d", prNum)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return EventChecksNone, fmt.Sprintf("Stopped watching CI on PR #%d: still running after %s", prNum, checksTimeout)
+		case <-time.After(checksPollInterval):
+		}
+	}
+}
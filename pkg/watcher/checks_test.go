@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestSummarizeChecks(t *testing.T) {
+	run := func(status, conclusion string) github.CheckRun {
+		return github.CheckRun{Name: status + "/" + conclusion, Status: status, Conclusion: conclusion}
+	}
+	tests := []struct {
+		runs   []github.CheckRun
+		want   ChecksState
+		failed string
+	}{
+		{nil, ChecksNone, ""},
+		{[]github.CheckRun{run("completed", "success"), run("in_progress", "")}, ChecksPending, ""},
+		{[]github.CheckRun{run("completed", "success"), run("completed", "skipped"), run("completed", "neutral")}, ChecksPassed, ""},
+		{[]github.CheckRun{run("queued", ""), run("completed", "timed_out"), run("completed", "failure")}, ChecksFailed, "completed/timed_out completed/failure"},
+	}
+	for _, tt := range tests {
+		got, failed := SummarizeChecks(tt.runs)
+		if got != tt.want || strings.Join(failed, " ") != tt.failed {
+			t.Errorf("SummarizeChecks(%v) = %v, %v; want %v, %s", tt.runs, got, failed, tt.want, tt.failed)
+		}
+	}
+	for st := ChecksNone; st <= ChecksFailed; st++ {
+		if got := ParseChecksState(st.String()); got != st {
+			t.Errorf("ParseChecksState(%q) = %v", st.String(), got)
+		}
+	}
+}
+
+// checksForge reports each poll's checks in turn, repeating the last.
+type checksForge struct {
+	Forge
+	mu    sync.Mutex
+	polls [][]github.CheckRun
+}
+
+func (f *checksForge) PRChecks(ctx context.Context, repo string, number int) ([]github.CheckRun, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	runs := f.polls[0]
+	if len(f.polls) > 1 {
+		f.polls = f.polls[1:]
+	}
+	return runs, nil
+}
+
+func TestWatchChecks(t *testing.T) {
+	defer func(d time.Duration) { checksPollInterval = d }(checksPollInterval)
+	checksPollInterval = time.Millisecond
+
+	forge := &checksForge{polls: [][]github.CheckRun{
+		nil,
+		{{Name: "build", Status: "queued"}},
+		{{Name: "build", Status: "completed", Conclusion: "success"}, {Name: "test", Status: "in_progress"}},
+		{{Name: "build", Status: "completed", Conclusion: "success"}, {Name: "test", Status: "completed", Conclusion: "failure"}},
+	}}
+	m, err := NewManager(t.TempDir(), 30*time.Second, forge)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+
+	m.WatchChecks("o/r", 3, 7)
+	m.WatchChecks("o/r", 3, 7) // already watched
+	ev := <-m.EventCh()
+	if ev.Kind != EventChecksFailed || ev.IssueNum != 3 || ev.Text != "CI failed on PR #7: test" {
+		t.Fatalf("event = %+v", ev)
+	}
+	select {
+	case ev := <-m.EventCh():
+		t.Errorf("second event %+v; checks were watched twice", ev)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
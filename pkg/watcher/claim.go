@@ -0,0 +1,51 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ClaimFile, in an IssueDir, records how long the issue's 👀 claim has
+// been held by a failed run, so the claim's expiry survives a restart.
+const ClaimFile = "claim.json"
+
+// Claim is what ClaimFile holds.
+type Claim struct {
+	Status   string    `json:"status"`   // the issue's, when it got stuck
+	Since    time.Time `json:"since"`    // when it got stuck
+	Released bool      `json:"released"` // the claim has been released since
+}
+
+// ReadClaim loads repo#num's ClaimFile; ok is false if there is none.
+func ReadClaim(baseDir, repo string, num int) (c Claim, ok bool) {
+	data, err := os.ReadFile(filepath.Join(IssueDir(baseDir, repo, num), ClaimFile))
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return Claim{}, false
+	}
+	return c, true
+}
+
+// WriteClaim records c as repo#num's ClaimFile. Like WriteIssueMeta it
+// does nothing for an issue without an issue directory.
+func WriteClaim(baseDir, repo string, num int, c Claim) error {
+	dir := IssueDir(baseDir, repo, num)
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ClaimFile), data, 0o644)
+}
+
+// ClearClaim removes repo#num's ClaimFile, once its issue moves on.
+func ClearClaim(baseDir, repo string, num int) error {
+	err := os.Remove(filepath.Join(IssueDir(baseDir, repo, num), ClaimFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestClaimFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := ReadClaim(dir, "o/r", 1); ok {
+		t.Error("read a claim that was never written")
+	}
+	// Nothing is written for an issue lurker hasn't touched.
+	c := Claim{Status: StatusFailed.String(), Since: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := WriteClaim(dir, "o/r", 1, c); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ReadClaim(dir, "o/r", 1); ok {
+		t.Error("wrote a claim without an issue directory")
+	}
+
+	os.MkdirAll(IssueDir(dir, "o/r", 1), 0o755)
+	if err := WriteClaim(dir, "o/r", 1, c); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := ReadClaim(dir, "o/r", 1); !ok || got != c {
+		t.Errorf("ReadClaim = %+v, %v, want %+v", got, ok, c)
+	}
+	if err := ClearClaim(dir, "o/r", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ReadClaim(dir, "o/r", 1); ok {
+		t.Error("claim still there once cleared")
+	}
+	if err := ClearClaim(dir, "o/r", 1); err != nil {
+		t.Errorf("clearing again: %v", err)
+	}
+}
+
+func TestReleaseClaim_Permissions(t *testing.T) {
+	noComments := github.AllowAll()
+	noComments.AllowComments = false
+	for name, forge := range map[string]Forge{"no forge": nil, "allow_comments off": permsForge{perms: noComments}} {
+		mgr, err := NewManager(t.TempDir(), 30*time.Second, forge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var perm *github.PermissionError
+		if err := mgr.ReleaseClaim(context.Background(), "o/r", 1); !errors.As(err, &perm) || perm.Action != "allow_comments" {
+			t.Errorf("%s: ReleaseClaim = %v, want a PermissionError", name, err)
+		}
+		mgr.Stop()
+	}
+}
@@ -8,24 +8,31 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
-// claudeTools defines the scoped tools Claude is allowed to use.
-var claudeTools = strings.Join([]string{
-	"Read",
-	"Glob",
-	"Grep",
-	"Edit",
-	"Write",
-	`Bash(bazel test:*)`,
-	`Bash(bazel build:*)`,
-	`Bash(git add:*)`,
-	`Bash(git commit:*)`,
-	`Bash(git diff:*)`,
-	`Bash(git status:*)`,
-	`Bash(git log:*)`,
-}, ",")
+// claudeTools defines the scoped tools Claude is allowed to use when the
+// project's toolchain is unknown.
+var claudeTools = toolsFor(defaultToolchain)
+
+// toolsFor returns the tools Claude may use in a tc project: file access,
+// tc's build and test commands, and committing.
+func toolsFor(tc Toolchain) string {
+	tools := []string{"Read", "Glob", "Grep", "Edit", "Write"}
+	tools = append(tools, tc.Tools...)
+	tools = append(tools,
+		`Bash(git add:*)`,
+		`Bash(git commit:*)`,
+		`Bash(git diff:*)`,
+		`Bash(git status:*)`,
+		`Bash(git log:*)`,
+	)
+	return strings.Join(tools, ",")
+}
 
 // BuildClaudePrompt creates the prompt for Claude Code given a repo and issue.
 func BuildClaudePrompt(repo string, issue Issue) string {
@@ -79,6 +86,7 @@ type assistantMessage struct {
 }
 
 type contentBlock struct {
+	ID    string          `json:"id,omitempty"`
 	Type  string          `json:"type"`
 	Text  string          `json:"text,omitempty"`
 	Name  string          `json:"name,omitempty"`
@@ -88,9 +96,67 @@ type contentBlock struct {
 // LogFunc is called with each line of Claude's output.
 type LogFunc func(line string)
 
-// formatStreamEvent turns a stream-json event into human-readable log lines.
-// Returns nil if the event should be suppressed.
+// StreamFormat is how Claude's stream-json events are shown in logs and
+// transcripts. The zero value shortens long messages and tool inputs to
+// keep logs scannable.
+type StreamFormat struct {
+	FullText   bool // show Claude's messages and errors whole
+	FullInputs bool // show tool inputs whole, such as multi-line commands
+	Raw        bool // pass events through as their JSON
+}
+
+// ParseStreamFormat parses a comma-separated list of "text" (FullText),
+// "inputs" (FullInputs), "full" (both) and "raw". "" and "short" are the
+// default format.
+func ParseStreamFormat(s string) (StreamFormat, error) {
+	var f StreamFormat
+	for _, opt := range strings.Split(s, ",") {
+		switch strings.TrimSpace(opt) {
+		case "", "short":
+		case "text":
+			f.FullText = true
+		case "inputs":
+			f.FullInputs = true
+		case "full":
+			f.FullText, f.FullInputs = true, true
+		case "raw":
+			f.Raw = true
+		default:
+			return StreamFormat{}, fmt.Errorf("unknown stream format %q (want short, text, inputs, full or raw)", opt)
+		}
+	}
+	return f, nil
+}
+
+// SetStreamFormat sets how transcripts and Claude's output are shown.
+func (m *Manager) SetStreamFormat(f StreamFormat) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streamFormat = f
+}
+
+// StreamFormat returns the format set with SetStreamFormat.
+func (m *Manager) StreamFormat() StreamFormat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.streamFormat
+}
+
+// formatStreamEvent turns a stream-json event into human-readable log lines
+// in the default format. Returns nil if the event should be suppressed.
 func formatStreamEvent(raw string) []string {
+	return StreamFormat{}.Lines(raw)
+}
+
+// Lines turns a stream-json event into log lines as f says. Returns nil
+// if the event should be suppressed.
+func (f StreamFormat) Lines(raw string) []string {
+	if f.Raw {
+		if strings.TrimSpace(raw) == "" {
+			return nil
+		}
+		return []string{raw}
+	}
 	var ev streamEvent
 	if err := json.Unmarshal([]byte(raw), &ev); err != nil {
 		return nil
@@ -114,25 +180,9 @@ func formatStreamEvent(raw string) []string {
 		for _, block := range ev.Message.Content {
 			switch block.Type {
 			case "text":
-				text := strings.TrimSpace(block.Text)
-				if text == "" {
-					continue
-				}
-				// Show first ~200 chars of text output
-				if len(text) > 200 {
-					text = text[:200] + "…"
-				}
-				// Split multi-line text into separate log lines
-				for _, line := range strings.Split(text, "\n") {
-					line = strings.TrimSpace(line)
-					if line != "" {
-						lines = append(lines, line)
-					}
-				}
+				lines = append(lines, f.text(block.Text)...)
 			case "tool_use":
-				if line := formatToolUse(block); line != "" {
-					lines = append(lines, line)
-				}
+				lines = append(lines, f.toolUse(block)...)
 			}
 		}
 		return lines
@@ -157,7 +207,7 @@ func formatStreamEvent(raw string) []string {
 		}
 		if ev.IsError {
 			msg := ev.Result
-			if len(msg) > 100 {
+			if !f.FullText && len(msg) > 100 {
 				msg = msg[:100] + "…"
 			}
 			return []string{fmt.Sprintf("✗ Failed:%s%s — %s", dur, cost, msg)}
@@ -168,10 +218,39 @@ func formatStreamEvent(raw string) []string {
 	return nil
 }
 
+// text renders a message of the agent's as log lines, one per line of it.
+func (f StreamFormat) text(text string) []string {
+	text = strings.TrimSpace(text)
+	// Show first ~200 chars of text output
+	if !f.FullText && len(text) > 200 {
+		text = text[:200] + "…"
+	}
+	var lines []string
+	// Split multi-line text into separate log lines
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// formatToolUse renders a tool call as a log line in the default format.
 func formatToolUse(block contentBlock) string {
+	lines := StreamFormat{}.toolUse(block)
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[0]
+}
+
+// toolUse renders a tool call as log lines: one, unless f.FullInputs
+// shows a multi-line command whole.
+func (f StreamFormat) toolUse(block contentBlock) []string {
 	tool := block.Name
 	if tool == "" {
-		return ""
+		return nil
 	}
 
 	var input struct {
@@ -186,54 +265,99 @@ func formatToolUse(block contentBlock) string {
 
 	switch tool {
 	case "Read":
-		return fmt.Sprintf("📖 Read %s", input.FilePath)
+		return []string{fmt.Sprintf("📖 Read %s", input.FilePath)}
 	case "Write":
-		return fmt.Sprintf("📝 Write %s", input.FilePath)
+		return []string{fmt.Sprintf("📝 Write %s", input.FilePath)}
 	case "Edit":
-		return fmt.Sprintf("✏️  Edit %s", input.FilePath)
+		return []string{fmt.Sprintf("✏️  Edit %s", input.FilePath)}
 	case "Glob":
-		return fmt.Sprintf("🔍 Glob %s", input.Pattern)
+		return []string{fmt.Sprintf("🔍 Glob %s", input.Pattern)}
 	case "Grep":
 		p := input.Pattern
-		if len(p) > 50 {
+		if !f.FullInputs && len(p) > 50 {
 			p = p[:50] + "…"
 		}
-		return fmt.Sprintf("🔍 Grep %q", p)
+		return []string{fmt.Sprintf("🔍 Grep %q", p)}
 	case "Bash":
+		if f.FullInputs {
+			// Continuation lines are indented, so they read as output
+			// of the command rather than commands of their own.
+			cmd := strings.Split(strings.TrimSpace(input.Command), "\n")
+			lines := []string{"$ " + cmd[0]}
+			for _, line := range cmd[1:] {
+				lines = append(lines, "  "+line)
+			}
+			return lines
+		}
 		cmd := input.Command
 		if len(cmd) > 80 {
 			cmd = cmd[:80] + "…"
 		}
-		return fmt.Sprintf("$ %s", cmd)
+		return []string{fmt.Sprintf("$ %s", cmd)}
 	case "Task":
-		return "🤖 Spawning sub-agent"
+		return []string{"🤖 Spawning sub-agent"}
 	default:
-		return fmt.Sprintf("🔧 %s", tool)
+		if f.FullInputs && len(block.Input) > 0 && string(block.Input) != "{}" {
+			return []string{fmt.Sprintf("🔧 %s %s", tool, block.Input)}
+		}
+		return []string{fmt.Sprintf("🔧 %s", tool)}
 	}
 }
 
-// RunClaude invokes Claude Code in the given workdir with the given prompt.
-// It streams output line-by-line via logFn. The tools parameter specifies
-// the allowed tools string; pass claudeTools for the default set.
-// If ptySlave is non-nil, formatted output is also written there so the
-// user can see Claude's activity when attached to the issue's PTY.
+// claudeAgent runs Claude Code.
+type claudeAgent struct{}
+
+func (claudeAgent) Name() string { return "claude" }
+
+// Command runs claude -p, which reads the prompt from stdin, with the
+// tools it may use.
+func (claudeAgent) Command(workdir string, opts AgentOptions) []string {
+	argv := []string{"claude", "-p", "--verbose"}
+	if opts.Resume {
+		argv = append(argv, "--continue")
+	}
+	if opts.MaxTurns > 0 {
+		argv = append(argv, "--max-turns", strconv.Itoa(opts.MaxTurns))
+	}
+	return append(argv, "--allowedTools", opts.Tools)
+}
+
+// Unsetenv strips ANTHROPIC_API_KEY so claude -p uses OAuth/Max
+// subscription instead of API credits, and CLAUDECODE, which claude sets
+// for the commands it runs, lurker among them.
+func (claudeAgent) Unsetenv() []string {
+	return []string{"ANTHROPIC_API_KEY", "CLAUDECODE"}
+}
+
+// Run has claude stream JSON events and logs them formatted as
+// opts.Format says. opts.Tools defaults to claudeTools.
+func (a claudeAgent) Run(ctx context.Context, workdir, prompt string, opts AgentOptions) (string, error) {
+	if opts.Tools == "" {
+		opts.Tools = claudeTools
+	}
+	argv := append(a.Command(workdir, opts), "--output-format", "stream-json")
+	return runAgent(ctx, a, workdir, prompt, argv, opts, opts.Format.Lines)
+}
+
+// runAgent runs argv, a's command, in workdir (in opts.Bwrap's sandbox if
+// set) with prompt on its stdin. It streams its output line-by-line via
+// opts.Log, as lines turns each line into log lines. If opts.PTY is
+// non-nil, the log lines are also written there so the user can see the
+// agent's activity when attached to the issue's PTY.
 // Returns the full output on completion.
-func RunClaude(ctx context.Context, workdir string, prompt string, tools string, logFn LogFunc, ptySlave *os.File) (string, error) {
-	cmd := exec.CommandContext(ctx, "claude",
-		"-p",
-		"--output-format", "stream-json",
-		"--verbose",
-		"--allowedTools", tools,
-	)
+func runAgent(ctx context.Context, a Agent, workdir, prompt string, argv []string, opts AgentOptions, lines func(raw string) []string) (string, error) {
+	logFn, ptySlave := opts.Log, opts.PTY
+	if opts.Bwrap != nil {
+		argv = opts.Bwrap.Argv(workdir, argv)
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
 	cmd.Dir = workdir
 
-	// Strip ANTHROPIC_API_KEY so claude -p uses OAuth/Max subscription
-	// instead of API credits
 	env := os.Environ()
 	filtered := make([]string, 0, len(env))
 	for _, e := range env {
-		if !strings.HasPrefix(e, "ANTHROPIC_API_KEY=") &&
-			!strings.HasPrefix(e, "CLAUDECODE=") {
+		name, _, _ := strings.Cut(e, "=")
+		if !slices.Contains(a.Unsetenv(), name) {
 			filtered = append(filtered, e)
 		}
 	}
@@ -264,7 +388,7 @@ func RunClaude(ctx context.Context, workdir string, prompt string, tools string,
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("starting claude: %w", err)
+		return "", fmt.Errorf("starting %s: %w", a.Name(), err)
 	}
 
 	// Write prompt and close stdin
@@ -292,7 +416,7 @@ func RunClaude(ctx context.Context, workdir string, prompt string, tools string,
 		}
 	}()
 
-	// Parse stream-json events from stdout
+	// Turn stdout into log lines
 	scanner := bufio.NewScanner(stdout)
 	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 	for scanner.Scan() {
@@ -300,8 +424,7 @@ func RunClaude(ctx context.Context, workdir string, prompt string, tools string,
 		output.WriteString(raw)
 		output.WriteString("\n")
 
-		lines := formatStreamEvent(raw)
-		for _, line := range lines {
+		for _, line := range lines(raw) {
 			if logFn != nil {
 				logFn(line)
 			}
@@ -315,8 +438,75 @@ func RunClaude(ctx context.Context, workdir string, prompt string, tools string,
 	<-doneCh
 
 	if err := cmd.Wait(); err != nil {
-		return output.String(), fmt.Errorf("claude exited: %w", err)
+		return output.String(), fmt.Errorf("%s exited: %w", a.Name(), err)
 	}
 
 	return output.String(), nil
 }
+
+// nonAlnum matches the characters Claude Code replaces with "-" when it
+// names a project's session directory after its working directory.
+var nonAlnum = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// claudeProjectDir is where Claude Code keeps sessions run in workdir.
+func claudeProjectDir(workdir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "projects", nonAlnum.ReplaceAllString(workdir, "-")), nil
+}
+
+// ClaudeTranscript returns the formatted transcript of the most recent
+// Claude Code session run in workdir. Claude keeps one JSONL file per
+// session under ~/.claude/projects/<mangled workdir>/; each line uses the
+// same shape as stream-json events, so format renders it.
+func ClaudeTranscript(workdir string, format StreamFormat) ([]string, error) {
+	latest, err := latestSession(workdir)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(latest)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, format.Lines(scanner.Text())...)
+	}
+	return lines, scanner.Err()
+}
+
+// latestSession returns the file of the most recent Claude Code session
+// run in workdir.
+func latestSession(workdir string) (string, error) {
+	dir, err := claudeProjectDir(workdir)
+	if err != nil {
+		return "", err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return "", err
+	}
+	var latest string
+	var latestMod int64
+	for _, p := range matches {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); mod > latestMod {
+			latest, latestMod = p, mod
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no Claude session found for %s", workdir)
+	}
+	return latest, nil
+}
@@ -1,6 +1,8 @@
 package watcher
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -130,3 +132,73 @@ func TestFormatToolUse(t *testing.T) {
 		})
 	}
 }
+
+func TestClaudeTranscript(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	workdir := "/tmp/lurker/owner/repo/1/repo"
+	dir := filepath.Join(home, ".claude", "projects", "-tmp-lurker-owner-repo-1-repo")
+	os.MkdirAll(dir, 0o755)
+	session := `{"type":"user","message":{"role":"user","content":"fix it"}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Looking around"},{"type":"tool_use","name":"Read","input":{"file_path":"main.go"}}]}}
+`
+	os.WriteFile(filepath.Join(dir, "abc.jsonl"), []byte(session), 0o644)
+
+	lines, err := ClaudeTranscript(workdir, StreamFormat{})
+	if err != nil {
+		t.Fatalf("ClaudeTranscript: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "Looking around" || !strings.Contains(lines[1], "main.go") {
+		t.Errorf("unexpected transcript: %v", lines)
+	}
+
+	if _, err := ClaudeTranscript("/nowhere", StreamFormat{}); err == nil {
+		t.Error("expected error when no session exists")
+	}
+}
+
+func TestStreamFormat(t *testing.T) {
+	long := strings.Repeat("word ", 60)
+	text := `{"type":"assistant","message":{"content":[{"type":"text","text":"` + long + `"}]}}`
+	bash := `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"cd pkg &&\n  go test ./... -run TestSomethingWithAVeryLongNameIndeed -count=1 -v -timeout 10m"}}]}}`
+	custom := `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"WebFetch","input":{"url":"https://example.com"}}]}}`
+
+	short := StreamFormat{}
+	if lines := short.Lines(text); len(lines) != 1 || !strings.HasSuffix(lines[0], "…") {
+		t.Errorf("short text = %q", lines)
+	}
+	if lines := short.Lines(bash); len(lines) != 1 || !strings.HasSuffix(lines[0], "…") {
+		t.Errorf("short bash = %q", lines)
+	}
+	if lines := short.Lines(custom); len(lines) != 1 || lines[0] != "🔧 WebFetch" {
+		t.Errorf("short custom = %q", lines)
+	}
+
+	full, err := ParseStreamFormat("full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := full.Lines(text); len(lines) != 1 || lines[0] != strings.TrimSpace(long) {
+		t.Errorf("full text = %q", lines)
+	}
+	want := []string{"$ cd pkg &&", "    go test ./... -run TestSomethingWithAVeryLongNameIndeed -count=1 -v -timeout 10m"}
+	if lines := full.Lines(bash); strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("full bash = %q", lines)
+	}
+	if lines := full.Lines(custom); len(lines) != 1 || lines[0] != `🔧 WebFetch {"url":"https://example.com"}` {
+		t.Errorf("full custom = %q", lines)
+	}
+
+	raw, _ := ParseStreamFormat("raw")
+	if lines := raw.Lines(custom); len(lines) != 1 || lines[0] != custom {
+		t.Errorf("raw = %q", lines)
+	}
+
+	if f, err := ParseStreamFormat("text, inputs"); err != nil || f != (StreamFormat{FullText: true, FullInputs: true}) {
+		t.Errorf("ParseStreamFormat(text, inputs) = %+v, %v", f, err)
+	}
+	if _, err := ParseStreamFormat("verbose"); err == nil {
+		t.Error("ParseStreamFormat(verbose) should fail")
+	}
+}
@@ -0,0 +1,272 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// Values of RepoConfig.DeleteBranches.
+const (
+	DeleteMerged = "merged" // once the issue's PR merges
+	DeleteClosed = "closed" // once it merges or is closed without merging
+)
+
+// trashDir holds repo directories that are being deleted in the background.
+const trashDir = ".trash"
+
+// DeleteRepoFiles deletes everything lurker keeps for repo under BaseDir:
+// the bare clone, every issue's worktree, and logs. The repo must already
+// be removed with RemoveRepo, which waits for its runs to stop.
+//
+// The directory is first renamed into BaseDir/.trash so the name is free
+// immediately (re-adding the repo starts clean), then deleted in the
+// background with EventCleanup progress and a final EventCleanupDone. A
+// bare clone the Layout keeps apart goes the same way. Clones of other
+// repos borrowing its objects are pointed at the trash, then repacked
+// in the background before it is deleted.
+func (m *Manager) DeleteRepoFiles(repo string) error {
+	if !validRepoName(repo) {
+		return fmt.Errorf("delete: invalid repo name %q", repo)
+	}
+
+	m.mu.Lock()
+	_, watched := m.watchers[repo]
+	m.mu.Unlock()
+	if watched {
+		return fmt.Errorf("delete: %s is still watched", repo)
+	}
+
+	objects := objectsDir(m.layout.BareDir(repo))
+	cached, err := trashRepoDir(m.layout.Cache, repo)
+	if err != nil {
+		return err
+	}
+	var trashed []string
+	if cached != "" {
+		trashed = append(trashed, cached)
+		if err := m.layout.repointBorrowers(objects, objectsDir(filepath.Join(cached, "bare.git"))); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+	}
+	if m.layout.Cache != m.baseDir {
+		dst, err := trashRepoDir(m.baseDir, repo)
+		if err != nil {
+			return err
+		}
+		if dst != "" {
+			trashed = append(trashed, dst)
+		}
+	}
+	if len(trashed) > 0 {
+		go m.purge(m.bgCtx, repo, trashed)
+	}
+	return nil
+}
+
+// trashRepoDir renames repo's directory under root into root/.trash, and
+// returns where it went: "" if there was none.
+func trashRepoDir(root, repo string) (string, error) {
+	src := filepath.Join(root, repo)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return "", nil
+	}
+	trash := filepath.Join(root, trashDir)
+	if err := os.MkdirAll(trash, 0o755); err != nil {
+		return "", fmt.Errorf("delete: %w", err)
+	}
+	dst := filepath.Join(trash, fmt.Sprintf("%s-%s-%d", filepath.Dir(repo), filepath.Base(repo), time.Now().UnixNano()))
+	if err := os.Rename(src, dst); err != nil {
+		return "", fmt.Errorf("delete: %w", err)
+	}
+	// Drop the owner directory if this was its last repo.
+	os.Remove(filepath.Dir(src))
+	return dst, nil
+}
+
+// purge removes repo's trashed dirs one top-level entry at a time,
+// after repacking the clones borrowing from them, reporting progress,
+// until ctx is done; what is left then stays in the trash for
+// purgeTrash. Progress is dropped rather than waited on if the events
+// back up.
+func (m *Manager) purge(ctx context.Context, repo string, dirs []string) {
+	report := func(kind EventKind, text string) {
+		ev := Event{Kind: kind, Repo: repo, Text: text, Timestamp: time.Now()}
+		if kind == EventCleanup {
+			select {
+			case m.eventCh <- ev:
+			default:
+			}
+			return
+		}
+		select {
+		case m.eventCh <- ev:
+		case <-ctx.Done():
+		}
+	}
+
+	fail := func(err error, dir string) {
+		if ctx.Err() == nil {
+			report(EventCleanupDone, fmt.Sprintf("deleting %s failed: %v (left in %s)", repo, err, dir))
+		}
+	}
+
+	var size int64
+	var paths []string
+	for _, dir := range dirs {
+		if bares := m.layout.borrowers(trashedObjects(dir)); len(bares) > 0 {
+			report(EventCleanup, fmt.Sprintf("repacking %d clones that borrow from %s", len(bares), repo))
+			if err := dissociate(ctx, bares, trashedObjects(dir)); err != nil {
+				fail(err, dir)
+				return
+			}
+		}
+		size += dirSize(dir)
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	for i, path := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+		report(EventCleanup, fmt.Sprintf("deleting %s %d/%d", repo, i, len(paths)))
+		if err := os.RemoveAll(path); err != nil {
+			fail(err, filepath.Dir(path))
+			return
+		}
+	}
+	for _, dir := range dirs {
+		// purgeTrash may have beaten it to dir.
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			fail(err, dir)
+			return
+		}
+	}
+	report(EventCleanupDone, fmt.Sprintf("deleted %s, freed %s", repo, formatBytes(size)))
+}
+
+// trashedObjects returns the objects directory of the bare clone in a
+// repo directory moved to the trash.
+func trashedObjects(dir string) string {
+	return objectsDir(filepath.Join(dir, "bare.git"))
+}
+
+// purgeTrash quietly deletes what purges left in the trash when lurker
+// last stopped, until ctx is done. A directory clones still borrow
+// from is kept unless they can be repacked.
+func (m *Manager) purgeTrash(ctx context.Context) {
+	for _, root := range []string{m.baseDir, m.layout.Cache} {
+		trash := filepath.Join(root, trashDir)
+		entries, _ := os.ReadDir(trash)
+		for _, e := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			dir := filepath.Join(trash, e.Name())
+			if bares := m.layout.borrowers(trashedObjects(dir)); len(bares) > 0 {
+				if dissociate(ctx, bares, trashedObjects(dir)) != nil {
+					continue
+				}
+			}
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+// dirSize returns the total size of regular files under dir.
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// formatBytes renders n as a short human-readable size, e.g. "1.4 GB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// DeleteRemoteBranch deletes repo#num's branch from where it was pushed,
+// origin or lurker's fork, and reports whether it was there to delete.
+// Deleting the branch of an open PR closes the PR.
+func (m *Manager) DeleteRemoteBranch(repo string, num int) (bool, error) {
+	ctx := context.Background()
+	branch := IssueBranch(num)
+	dir, remote, ref := m.layout.BareDir(repo), "origin", "refs/heads/"+branch
+	if workdir := existingDir(IssueWorkdir(m.baseDir, repo, num)); workdir != "" {
+		dir = workdir
+		if r, _ := gitCmd(ctx, workdir, nil, "config", "branch."+branch+".remote"); r != "" {
+			remote = r
+		}
+		if r, _ := gitCmd(ctx, workdir, nil, "config", "branch."+branch+".merge"); r != "" {
+			ref = r
+		}
+	}
+	out, err := gitCmd(ctx, dir, nil, "ls-remote", remote, ref)
+	if err != nil || out == "" {
+		return false, err
+	}
+	if _, err := gitCmd(ctx, dir, nil, "push", remote, "--delete", ref); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// prClosed deletes the branch of repo#num's PR, which is no longer open,
+// from its remote if the repo's delete_branches asks for it, and reports
+// the outcome as EventBranchGone.
+func (m *Manager) prClosed(repo string, num int, pr *github.PullRequest) {
+	merged := !pr.MergedAt.IsZero()
+	switch m.RepoConfig(IssueWorkdir(m.baseDir, repo, num)).DeleteBranches {
+	case DeleteClosed:
+	case DeleteMerged:
+		if !merged {
+			return
+		}
+	default:
+		return
+	}
+	if !m.ghClient.Permissions().AllowPush {
+		return
+	}
+	what := "closed"
+	if merged {
+		what = "merged"
+	}
+	var text string
+	switch deleted, err := m.DeleteRemoteBranch(repo, num); {
+	case err != nil:
+		text = fmt.Sprintf("Deleting %s after PR #%d %s failed: %v", IssueBranch(num), pr.Number, what, err)
+	case deleted:
+		text = fmt.Sprintf("Deleted %s from its remote: PR #%d %s", IssueBranch(num), pr.Number, what)
+	default:
+		return
+	}
+	m.eventCh <- Event{Kind: EventBranchGone, Repo: repo, IssueNum: num, Text: text, Timestamp: time.Now()}
+}
@@ -0,0 +1,175 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_DeleteRepoFiles(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.AddRepo("owner/repo")
+	os.MkdirAll(filepath.Join(dir, "owner/repo/bare.git"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "owner/repo/7/repo"), 0o755)
+	os.WriteFile(filepath.Join(dir, "owner/repo/7/lurker.log"), []byte("hello\n"), 0o644)
+
+	if err := mgr.DeleteRepoFiles("owner/repo"); err == nil {
+		t.Fatal("expected error deleting files of a watched repo")
+	}
+
+	mgr.RemoveRepo("owner/repo")
+	if err := mgr.DeleteRepoFiles("owner/repo"); err != nil {
+		t.Fatalf("DeleteRepoFiles: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "owner")); !os.IsNotExist(err) {
+		t.Errorf("owner dir should be gone immediately, got %v", err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-mgr.EventCh():
+			if ev.Kind != EventCleanupDone {
+				continue
+			}
+			if !strings.Contains(ev.Text, "freed 6 B") {
+				t.Errorf("done text = %q", ev.Text)
+			}
+			if entries, _ := os.ReadDir(filepath.Join(dir, trashDir)); len(entries) != 0 {
+				t.Errorf("trash not emptied: %v", entries)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for EventCleanupDone")
+		}
+	}
+}
+
+func TestManager_DeleteRepoFiles_Borrowed(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+	l := mgr.layout
+	gitIn(t, dir, "clone", "-q", "--bare", src, l.BareDir("a/proj"))
+	gitIn(t, dir, "clone", "-q", "--bare", "--reference-if-able", l.BareDir("a/proj"), "file://"+src, l.BareDir("b/proj"))
+
+	if err := mgr.DeleteRepoFiles("a/proj"); err != nil {
+		t.Fatalf("DeleteRepoFiles: %v", err)
+	}
+	// Until the purge repacks it, the fork's clone borrows from the trash.
+	gitIn(t, l.BareDir("b/proj"), "cat-file", "-e", "HEAD:a.txt")
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev := <-mgr.EventCh():
+			if ev.Kind != EventCleanupDone {
+				continue
+			}
+			if !strings.HasPrefix(ev.Text, "deleted a/proj") {
+				t.Errorf("done text = %q", ev.Text)
+			}
+			gitIn(t, l.BareDir("b/proj"), "cat-file", "-e", "HEAD:a.txt")
+			if _, err := os.Stat(filepath.Join(objectsDir(l.BareDir("b/proj")), "info", "alternates")); !os.IsNotExist(err) {
+				t.Errorf("alternates kept: %v", err)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for EventCleanupDone")
+		}
+	}
+}
+
+func TestManager_PurgeTrash(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	stale := filepath.Join(dir, trashDir, "owner-repo-1")
+	os.MkdirAll(filepath.Join(stale, "7/repo"), 0o755)
+
+	// Once stopped, a purge gives up without waiting on the events.
+	mgr.Stop()
+	done := make(chan struct{})
+	go func() {
+		mgr.purge(mgr.bgCtx, "owner/repo", []string{stale})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("purge after Stop blocked")
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("purge after Stop deleted %s: %v", stale, err)
+	}
+
+	// The next lurker finishes it.
+	mgr, err = NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.purgeTrash(mgr.bgCtx)
+	if entries, _ := os.ReadDir(filepath.Join(dir, trashDir)); len(entries) != 0 {
+		t.Errorf("trash not emptied: %v", entries)
+	}
+}
+
+func TestManager_DeleteRemoteBranch(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	origin := filepath.Join(t.TempDir(), "origin.git")
+	gitIn(t, dir, "init", "-q", "--bare", "-b", "main", origin)
+	workdir := IssueWorkdir(dir, "owner/repo", 7)
+	gitIn(t, dir, "clone", "-q", origin, workdir)
+	gitIn(t, workdir, "checkout", "-q", "-b", IssueBranch(7))
+	writeAndCommit(t, workdir, "a.txt", "fix\n")
+	gitIn(t, workdir, "push", "-q", "-u", "origin", IssueBranch(7))
+
+	deleted, err := mgr.DeleteRemoteBranch("owner/repo", 7)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteRemoteBranch = %v, %v", deleted, err)
+	}
+	if out := gitIn(t, workdir, "ls-remote", "origin", IssueBranch(7)); out != "" {
+		t.Errorf("origin still has %s", out)
+	}
+	if deleted, err := mgr.DeleteRemoteBranch("owner/repo", 7); err != nil || deleted {
+		t.Errorf("deleting again = %v, %v", deleted, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		512:                    "512 B",
+		1536:                   "1.5 KB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+	for n, want := range cases {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package watcher
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// codeownersPaths are where GitHub looks for a repo's CODEOWNERS, in
+// order.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule is a line of CODEOWNERS.
+type codeownersRule struct {
+	pattern string
+	owners  []string
+}
+
+// CodeOwners returns the users who own files in workdir's CODEOWNERS, in
+// the order first found. As in GitHub, the last rule matching a file
+// decides its owners. Teams and email owners are left out, since they
+// can't be requested by name.
+func CodeOwners(workdir string, files []string) []string {
+	rules := readCodeowners(workdir)
+	if len(rules) == 0 {
+		return nil
+	}
+	var owners []string
+	seen := map[string]bool{}
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !codeownersMatch(rules[i].pattern, file) {
+				continue
+			}
+			for _, owner := range rules[i].owners {
+				user, ok := strings.CutPrefix(owner, "@")
+				if !ok || strings.Contains(user, "/") || seen[user] {
+					continue
+				}
+				seen[user] = true
+				owners = append(owners, user)
+			}
+			break
+		}
+	}
+	return owners
+}
+
+// readCodeowners parses the first CODEOWNERS found in workdir.
+func readCodeowners(workdir string) []codeownersRule {
+	for _, p := range codeownersPaths {
+		f, err := os.Open(filepath.Join(workdir, p))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		var rules []codeownersRule
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			rules = append(rules, codeownersRule{pattern: fields[0], owners: fields[1:]})
+		}
+		return rules
+	}
+	return nil
+}
+
+// codeownersMatch reports whether the gitignore-style pattern matches
+// file, a slash-separated path from the repo root. A pattern matching a
+// directory matches everything in it.
+func codeownersMatch(pattern, file string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	segs := strings.Split(file, "/")
+	if trimmed == "" {
+		return false
+	}
+	if !strings.HasPrefix(pattern, "/") && !strings.Contains(trimmed, "/") {
+		// A bare name matches at any depth.
+		for i, seg := range segs {
+			if ok, _ := path.Match(trimmed, seg); ok && (!dirOnly || i < len(segs)-1) {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(trimmed, "/"), segs, dirOnly)
+}
+
+// matchSegments matches pattern segments, where "**" spans any number of
+// directories, against path segments.
+func matchSegments(pat, segs []string, dirOnly bool) bool {
+	if len(pat) == 0 {
+		// Matched the file itself, or a directory it is in.
+		return len(segs) > 0 || !dirOnly
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pat[1:], segs[i:], dirOnly) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pat[0], segs[0]); !ok {
+		return false
+	}
+	return matchSegments(pat[1:], segs[1:], dirOnly)
+}
@@ -0,0 +1,52 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodeownersMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*", "a/b.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.go", false},
+		{"docs/", "docs/guide.md", true},
+		{"docs/", "docs", false},
+		{"/build/", "src/build/x", false},
+		{"build/", "src/build/x", true},
+		{"/pkg/api", "pkg/api/server.go", true},
+		{"/pkg/api", "cmd/pkg/api/server.go", false},
+		{"pkg/*.go", "pkg/a.go", true},
+		{"pkg/*.go", "pkg/sub/a.go", false},
+		{"**/testdata", "pkg/x/testdata/f", true},
+		{"pkg/**/BUILD", "pkg/a/b/BUILD", true},
+	} {
+		if got := codeownersMatch(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("codeownersMatch(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestCodeOwners(t *testing.T) {
+	workdir := t.TempDir()
+	os.MkdirAll(filepath.Join(workdir, ".github"), 0o755)
+	os.WriteFile(filepath.Join(workdir, ".github", "CODEOWNERS"), []byte(`# default owners
+*            @alice
+/pkg/api/    @bob @org/api-team
+*.md         docs@example.com   # email owners can't be requested
+/pkg/api/*.proto @carol
+`), 0o644)
+
+	got := CodeOwners(workdir, []string{"main.go", "pkg/api/server.go", "pkg/api/v1.proto", "README.md"})
+	if strings.Join(got, ",") != "alice,bob,carol" {
+		t.Errorf("owners = %v", got)
+	}
+	if got := CodeOwners(t.TempDir(), []string{"main.go"}); got != nil {
+		t.Errorf("owners without CODEOWNERS = %v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// codexAgent runs OpenAI's Codex CLI non-interactively (codex exec),
+// logged in with `codex login` or $OPENAI_API_KEY. Codex can't allow
+// commands one by one as Claude's tools do; its sandbox stands in for
+// them (see codexSandbox).
+type codexAgent struct{}
+
+func (codexAgent) Name() string { return "codex" }
+
+// Command runs codex exec on the prompt on stdin ("-"), sandboxed as
+// opts.Tools allow. Resume continues its last session.
+func (a codexAgent) Command(workdir string, opts AgentOptions) []string {
+	return a.argv(workdir, opts, false)
+}
+
+// argv is Command's, streaming JSON events if json is set.
+func (codexAgent) argv(workdir string, opts AgentOptions, json bool) []string {
+	argv := []string{"codex", "exec", "--skip-git-repo-check", "--sandbox", codexSandbox(opts.Tools)}
+	if json {
+		argv = append(argv, "--json")
+	}
+	// A worktree commits to its bare clone, outside the workspace.
+	if _, common := gitDirs(workdir); common != "" && codexSandbox(opts.Tools) == "workspace-write" &&
+		!strings.HasPrefix(common, workdir+string(filepath.Separator)) {
+		argv = append(argv, "--add-dir", common)
+	}
+	if opts.Resume {
+		argv = append(argv, "resume", "--last")
+	}
+	return append(argv, "-")
+}
+
+// Unsetenv is empty: Codex doesn't read Claude's variables.
+func (codexAgent) Unsetenv() []string { return nil }
+
+// Run has codex stream JSON events and logs them as Claude's are, as
+// opts.Format says.
+func (a codexAgent) Run(ctx context.Context, workdir, prompt string, opts AgentOptions) (string, error) {
+	return runAgent(ctx, a, workdir, prompt, a.argv(workdir, opts, true), opts, opts.Format.codexLines)
+}
+
+// codexSandbox returns the Codex sandbox mode closest to allowing tools,
+// Claude's --allowedTools: the workspace is writable if Edit or Write
+// is allowed, and then Codex may run any command in it, but without
+// network; otherwise it may only read.
+func codexSandbox(tools string) string {
+	allowed := strings.Split(tools, ",")
+	if slices.Contains(allowed, "Edit") || slices.Contains(allowed, "Write") {
+		return "workspace-write"
+	}
+	return "read-only"
+}
+
+// codexEvent is an event of codex exec --json:
+//
+//	{"type":"thread.started","thread_id":"..."}
+//	{"type":"item.started","item":{"type":"command_execution","command":"bash -lc 'go test ./...'"}}
+//	{"type":"item.completed","item":{"type":"agent_message","text":"..."}}
+//	{"type":"turn.completed","usage":{"input_tokens":1200,"output_tokens":300}}
+type codexEvent struct {
+	Type    string     `json:"type"`
+	Message string     `json:"message,omitempty"` // of "error"
+	Item    *codexItem `json:"item,omitempty"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"` // of "turn.failed"
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"` // of "turn.completed"
+}
+
+type codexItem struct {
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`    // agent_message, reasoning
+	Command string `json:"command,omitempty"` // command_execution
+	Changes []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"` // add, update or delete
+	} `json:"changes,omitempty"` // file_change
+	Server string `json:"server,omitempty"` // mcp_tool_call
+	Tool   string `json:"tool,omitempty"`
+	Query  string `json:"query,omitempty"` // web_search
+}
+
+// codexLines turns a codex exec --json event into log lines as f says,
+// matching Claude's: its messages, and its commands and file changes as
+// the tool calls Claude would have made. Returns nil if the event should
+// be suppressed.
+func (f StreamFormat) codexLines(raw string) []string {
+	if f.Raw {
+		if strings.TrimSpace(raw) == "" {
+			return nil
+		}
+		return []string{raw}
+	}
+	var ev codexEvent
+	if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+		return nil
+	}
+	tool := func(name string, input any) []string {
+		data, _ := json.Marshal(input)
+		return f.toolUse(contentBlock{Type: "tool_use", Name: name, Input: data})
+	}
+
+	switch ev.Type {
+	case "thread.started":
+		return []string{"Codex session initialized"}
+
+	case "item.started":
+		// Commands are shown as they start; the rest once done.
+		if ev.Item != nil && ev.Item.Type == "command_execution" {
+			return tool("Bash", map[string]string{"command": ev.Item.Command})
+		}
+		return nil
+
+	case "item.completed":
+		if ev.Item == nil {
+			return nil
+		}
+		switch ev.Item.Type {
+		case "agent_message":
+			return f.text(ev.Item.Text)
+		case "file_change":
+			var lines []string
+			for _, c := range ev.Item.Changes {
+				switch c.Kind {
+				case "add":
+					lines = append(lines, tool("Write", map[string]string{"file_path": c.Path})...)
+				case "delete":
+					lines = append(lines, fmt.Sprintf("🗑  Delete %s", c.Path))
+				default:
+					lines = append(lines, tool("Edit", map[string]string{"file_path": c.Path})...)
+				}
+			}
+			return lines
+		case "mcp_tool_call":
+			return tool(ev.Item.Server+"."+ev.Item.Tool, map[string]string{})
+		case "web_search":
+			return tool("WebSearch", map[string]string{"query": ev.Item.Query})
+		}
+		return nil
+
+	case "turn.completed":
+		if ev.Usage == nil {
+			return []string{"✓ Done"}
+		}
+		return []string{fmt.Sprintf("✓ Done (%d tokens in, %d out)", ev.Usage.InputTokens, ev.Usage.OutputTokens)}
+
+	case "turn.failed":
+		msg := ""
+		if ev.Error != nil {
+			msg = ev.Error.Message
+		}
+		if !f.FullText && len(msg) > 100 {
+			msg = msg[:100] + "…"
+		}
+		return []string{fmt.Sprintf("✗ Failed — %s", msg)}
+
+	case "error":
+		return []string{fmt.Sprintf("⚠ Error: %s", ev.Message)}
+	}
+	return nil
+}
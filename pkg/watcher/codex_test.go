@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCodexCommand(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+	bare := filepath.Join(root, "bare.git")
+	gitIn(t, root, "clone", "-q", "--bare", src, bare)
+	workdir := filepath.Join(root, "7", "repo")
+	gitIn(t, bare, "worktree", "add", "-q", "-b", "agent/issue-7", workdir)
+
+	got := codexAgent{}.Command(workdir, AgentOptions{Tools: claudeTools, Resume: true})
+	want := []string{"codex", "exec", "--skip-git-repo-check", "--sandbox", "workspace-write", "--add-dir", bare, "resume", "--last", "-"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Command = %q, want %q", got, want)
+	}
+	// Read-only tools keep it from writing anything.
+	got = codexAgent{}.Command(workdir, AgentOptions{Tools: "Read,Glob,Grep"})
+	if !slices.Contains(got, "read-only") || slices.Contains(got, "--add-dir") {
+		t.Errorf("Command with read-only tools = %q", got)
+	}
+}
+
+func TestCodexLines(t *testing.T) {
+	f := StreamFormat{}
+	for raw, want := range map[string][]string{
+		`{"type":"thread.started","thread_id":"t1"}`: {"Codex session initialized"},
+		`{"type":"turn.started"}`:                    nil,
+		`{"type":"item.started","item":{"id":"i1","type":"command_execution","command":"go test ./...","status":"in_progress"}}`:                                           {"$ go test ./..."},
+		`{"type":"item.completed","item":{"id":"i1","type":"command_execution","command":"go test ./...","exit_code":0}}`:                                                  nil,
+		`{"type":"item.completed","item":{"type":"reasoning","text":"**Looking around**"}}`:                                                                                nil,
+		`{"type":"item.completed","item":{"type":"agent_message","text":"Fixed it.\n\nTests pass."}}`:                                                                      {"Fixed it.", "Tests pass."},
+		`{"type":"item.completed","item":{"type":"file_change","changes":[{"path":"a.go","kind":"update"},{"path":"b.go","kind":"add"},{"path":"c.go","kind":"delete"}]}}`: {"✏️  Edit a.go", "📝 Write b.go", "🗑  Delete c.go"},
+		`{"type":"turn.completed","usage":{"input_tokens":1200,"cached_input_tokens":800,"output_tokens":300}}`:                                                            {"✓ Done (1200 tokens in, 300 out)"},
+		`{"type":"turn.failed","error":{"message":"stream disconnected"}}`:                                                                                                 {"✗ Failed — stream disconnected"},
+		`{"type":"error","message":"rate limited"}`:                                                                                                                        {"⚠ Error: rate limited"},
+		`not json`: nil,
+	} {
+		if got := f.codexLines(raw); !reflect.DeepEqual(got, want) {
+			t.Errorf("codexLines(%s) = %q, want %q", raw, got, want)
+		}
+	}
+	if got := (StreamFormat{Raw: true}).codexLines(`{"type":"turn.started"}`); len(got) != 1 {
+		t.Errorf("raw codexLines = %q", got)
+	}
+}
+
+func TestCodexRun(t *testing.T) {
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" > "$CODEX_LOG.args"
+cat > "$CODEX_LOG.stdin"
+echo '{"type":"thread.started","thread_id":"t1"}'
+echo '{"type":"item.completed","item":{"type":"agent_message","text":"Wrote it."}}'
+echo '{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":2}}'
+`
+	if err := os.WriteFile(filepath.Join(bin, "codex"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	log := filepath.Join(t.TempDir(), "codex")
+	t.Setenv("CODEX_LOG", log)
+
+	var lines []string
+	_, err := codexAgent{}.Run(context.Background(), t.TempDir(), "Describe the diff.", AgentOptions{
+		Tools: "Read,Write",
+		Log:   func(line string) { lines = append(lines, line) },
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{"Codex session initialized", "Wrote it.", "✓ Done (10 tokens in, 2 out)"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("logged %q, want %q", lines, want)
+	}
+	if stdin, _ := os.ReadFile(log + ".stdin"); string(stdin) != "Describe the diff." {
+		t.Errorf("prompt = %q", stdin)
+	}
+	if args, _ := os.ReadFile(log + ".args"); !strings.Contains(string(args), "--sandbox workspace-write --json -") {
+		t.Errorf("args = %s", args)
+	}
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// CommentCommands are the commands teammates can give lurker by commenting
+// "@lurker <command>" on an issue: start (or resume) work, retry a failed
+// run, or open the PR for a finished one.
+var CommentCommands = []string{"start", "retry", "pr"}
+
+// commandAssociations are the author associations allowed to command
+// lurker; anyone else's comments are ignored.
+var commandAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// commenter is implemented by forges that list a repo's comments, which
+// lets teammates drive lurker from the issue tracker.
+type commenter interface {
+	ListCommentsSince(ctx context.Context, repo string, since time.Time) ([]github.Comment, error)
+	AddCommentReaction(ctx context.Context, repo string, id int64, reaction string) error
+}
+
+// commentSkew is how far back each look for comments reaches before the
+// previous one, for comments that took a moment to show up.
+const commentSkew = time.Minute
+
+// parseCommentCommand returns the command in a comment: the first line
+// that starts with "@lurker" followed by one of CommentCommands.
+func parseCommentCommand(body string) (string, bool) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(strings.ToLower(line))
+		if len(fields) >= 2 && fields[0] == "@lurker" && slices.Contains(CommentCommands, fields[1]) {
+			return fields[1], true
+		}
+	}
+	return "", false
+}
+
+// pollCommands emits EventCommand for each command commented by a
+// teammate since the last poll, and acknowledges it with a 👍. Comments
+// left before lurker started aren't acted on.
+func (w *Watcher) pollCommands(ctx context.Context, eventCh chan<- Event) {
+	forge, ok := w.ghClient.(commenter)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	w.commentsMu.Lock()
+	since, retired := w.commentsSince, w.retired
+	if since.IsZero() && !retired {
+		w.commentsSince, w.commandsFrom = now, now
+	}
+	w.commentsMu.Unlock()
+	if since.IsZero() || retired {
+		return
+	}
+	comments, err := forge.ListCommentsSince(ctx, w.cfg.Repo, since.Add(-commentSkew))
+	if err != nil {
+		// Try again next poll from the same point.
+		return
+	}
+	w.commentsMu.Lock()
+	defer w.commentsMu.Unlock()
+	if w.retired {
+		return
+	}
+	w.commentsSince = now
+
+	for _, c := range comments {
+		// Edits bring old comments back; only new ones count, once.
+		if c.ID <= w.lastCommentID || c.CreatedAt.Before(w.commandsFrom) {
+			continue
+		}
+		w.lastCommentID = c.ID
+		cmd, ok := parseCommentCommand(c.Body)
+		if !ok || !slices.Contains(commandAssociations, c.AuthorAssociation) || c.IssueNumber() == 0 {
+			continue
+		}
+		eventCh <- Event{
+			Kind:      EventCommand,
+			Repo:      w.cfg.Repo,
+			IssueNum:  c.IssueNumber(),
+			Text:      cmd,
+			Timestamp: now,
+			By:        c.User.Login,
+		}
+		forge.AddCommentReaction(ctx, w.cfg.Repo, c.ID, "+1")
+	}
+}
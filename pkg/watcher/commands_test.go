@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// commentForge serves a fixed list of comments and records reactions.
+type commentForge struct {
+	Forge
+	comments []github.Comment
+	reacted  []int64
+}
+
+func (f *commentForge) ListCommentsSince(ctx context.Context, repo string, since time.Time) ([]github.Comment, error) {
+	return f.comments, nil
+}
+
+func (f *commentForge) AddCommentReaction(ctx context.Context, repo string, id int64, reaction string) error {
+	f.reacted = append(f.reacted, id)
+	return nil
+}
+
+func comment(id int64, issue int, assoc, body string, at time.Time) github.Comment {
+	c := github.Comment{ID: id, Body: body, AuthorAssociation: assoc, CreatedAt: at}
+	c.IssueURL = fmt.Sprintf("https://api.github.com/repos/o/r/issues/%d", issue)
+	c.User.Login = "alice"
+	return c
+}
+
+func TestParseCommentCommand(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"@lurker start", "start"},
+		{"Looks good.\n@Lurker PR please", "pr"},
+		{"  @lurker retry\n@lurker start", "retry"},
+		{"@lurker dance", ""},
+		{"ping @lurker start", ""},
+		{"@lurker", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := parseCommentCommand(tt.body); got != tt.want {
+			t.Errorf("parseCommentCommand(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestPollCommands(t *testing.T) {
+	forge := &commentForge{}
+	w := &Watcher{cfg: Config{Repo: "o/r"}, ghClient: forge}
+	ch := make(chan Event, 10)
+
+	// The first poll only marks where commands start counting.
+	w.pollCommands(context.Background(), ch)
+	after := w.commandsFrom.Add(time.Second)
+	forge.comments = []github.Comment{
+		comment(1, 3, "MEMBER", "@lurker start", w.commandsFrom.Add(-time.Hour)), // edited old comment
+		comment(2, 4, "NONE", "@lurker start", after),                            // outsider
+		comment(3, 5, "COLLABORATOR", "thanks!", after),                          // no command
+		comment(4, 6, "OWNER", "@lurker retry", after),
+	}
+	w.pollCommands(context.Background(), ch)
+	w.pollCommands(context.Background(), ch) // the same comments again
+
+	if len(ch) != 1 {
+		t.Fatalf("got %d events, want 1", len(ch))
+	}
+	ev := <-ch
+	if ev.Kind != EventCommand || ev.IssueNum != 6 || ev.Text != "retry" || ev.By != "alice" {
+		t.Errorf("event = %+v", ev)
+	}
+	if len(forge.reacted) != 1 || forge.reacted[0] != 4 {
+		t.Errorf("reacted to %v, want [4]", forge.reacted)
+	}
+}
@@ -1,7 +1,9 @@
 package watcher
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,30 +18,300 @@ type RepoConfig struct {
 	// AllowedTools overrides the default Claude tool permissions
 	AllowedTools []string `json:"allowed_tools,omitempty"`
 
-	// BuildCommand is the command to run for building (default: "bazel build //...")
+	// BuildCommand is the command to run for building (default: the
+	// toolchain's, e.g. "go build ./...")
 	BuildCommand string `json:"build_command,omitempty"`
 
-	// TestCommand is the command to run for testing (default: "bazel test //...")
+	// TestCommand is the command to run for testing (default: the
+	// toolchain's, e.g. "go test ./...")
 	TestCommand string `json:"test_command,omitempty"`
+
+	// Toolchain names the project's toolchain (bazel, go, rust, pnpm, yarn,
+	// node or python) when detecting it from the repo's files guesses wrong.
+	Toolchain string `json:"toolchain,omitempty"`
+
+	// BaseBranch is the branch issue branches start from and PRs target
+	// (default: the repo's default branch). A base chosen when adding the
+	// repo to lurker wins.
+	BaseBranch string `json:"base_branch,omitempty"`
+
+	// Areas maps area names to directories for scoping monorepo issues
+	// (see Scope).
+	Areas map[string]string `json:"areas,omitempty"`
+
+	// LintCommand is the linter lurker runs after each run, and when
+	// verifying (no default). Its failures are logged, not held against
+	// the branch.
+	LintCommand string `json:"lint_command,omitempty"`
+
+	// FormatCommand is run after each run, before linting, and what it
+	// changes is committed, e.g. "gofmt -w ." (no default).
+	FormatCommand string `json:"format_command,omitempty"`
+
+	// Verify has lurker run the build, test and lint commands itself
+	// after each run and post the results as commit statuses on push.
+	Verify bool `json:"verify,omitempty"`
+
+	// RequireTests has a finished run's branch marked ready, or pushed
+	// to its PR, only once the test command passes in the worktree;
+	// otherwise the issue is marked tests-failed.
+	RequireTests bool `json:"require_tests,omitempty"`
+
+	// RequireBuild does the same with the build command, run before the
+	// tests; a failure marks the issue build-failed.
+	RequireBuild bool `json:"require_build,omitempty"`
+
+	// Stages replaces the pipeline a finished run's branch goes through
+	// before it is marked ready — otherwise rebase, format, lint, verify,
+	// build and test, as the settings above turn them on — with a list of
+	// built-in stages and commands of the repo's own.
+	Stages []Stage `json:"stages,omitempty"`
+
+	// PRSummary has new PRs get a comment summarizing the run behind
+	// them; it sets the default of the PR dialog's "Summary" toggle.
+	PRSummary bool `json:"pr_summary,omitempty"`
+
+	// DraftPRs has the PRs lurker opens start as drafts; it sets the
+	// default of the PR dialog's "Draft" toggle.
+	DraftPRs bool `json:"draft_prs,omitempty"`
+
+	// PRReviewers, PRLabels and PRAssignees are requested as reviewers,
+	// added as labels and assigned on the PRs lurker opens, and prefill
+	// the PR dialog — e.g. an "ai-generated" label to route bot PRs in CI.
+	PRReviewers []string `json:"pr_reviewers,omitempty"`
+	PRLabels    []string `json:"pr_labels,omitempty"`
+	PRAssignees []string `json:"pr_assignees,omitempty"`
+
+	// ClaudePRs has a short Claude run write the title and description
+	// of the PRs lurker opens from their diff, instead of listing the
+	// commits.
+	ClaudePRs bool `json:"claude_prs,omitempty"`
+
+	// PRTemplate is the outline Claude's PR descriptions follow (default:
+	// the repo's pull_request_template.md, else Summary, Changes and
+	// Testing sections).
+	PRTemplate string `json:"pr_template,omitempty"`
+
+	// CodeownersReviewers also requests review from the CODEOWNERS of
+	// the files a PR changes.
+	CodeownersReviewers bool `json:"codeowners_reviewers,omitempty"`
+
+	// ProgressComments has lurker comment on the issue when it starts
+	// work, and again with a summary when the branch is ready or with the
+	// error when the run fails.
+	ProgressComments bool `json:"progress_comments,omitempty"`
+
+	// UpdateMethod is how B brings an issue's branch up to date with
+	// the base branch: "rebase" (the default) rebases it locally, "merge"
+	// has GitHub merge the base into the branch of its open PR.
+	UpdateMethod string `json:"update_method,omitempty"`
+
+	// DeleteBranches has lurker delete an issue's branch from its remote
+	// once its PR is "merged", or "closed" with or without merging (see
+	// DeleteMerged and DeleteClosed), so stale agent branches don't pile
+	// up upstream.
+	DeleteBranches string `json:"delete_branches,omitempty"`
+
+	// AutoRebase has lurker rebase a finished run's branch onto the
+	// latest base branch before marking it ready.
+	AutoRebase bool `json:"auto_rebase,omitempty"`
+
+	// AddressReviews has Claude address new review feedback on lurker's
+	// PRs as soon as it arrives, instead of waiting for F.
+	AddressReviews bool `json:"address_reviews,omitempty"`
+
+	// ProtectedPaths are files the PRs lurker opens may not touch:
+	// patterns matched against the whole path (see path.Match), or
+	// directories ending in "/", e.g. ".github/workflows/".
+	ProtectedPaths []string `json:"protected_paths,omitempty"`
+
+	// SquashMessage is the text/template, executed with SquashData, for
+	// the message of a branch squashed with Q (default:
+	// DefaultSquashMessage, a conventional commit).
+	SquashMessage string `json:"squash_message,omitempty"`
+
+	// ForcePush offers to force-push (with a lease) an issue's branch when
+	// pushing it is rejected because it was rewritten since it was pushed,
+	// e.g. by a continued run that amended its commits.
+	ForcePush bool `json:"force_push,omitempty"`
+
+	// Templates replace the PR bodies and comments lurker posts.
+	Templates Templates `json:"templates,omitempty"`
+
+	// SandboxImage has each new issue's clone, Claude and commands (build,
+	// test, lint, format, stages) run in a Docker container of the image,
+	// one per issue, that sees only the issue's worktree. The image needs
+	// sh, git and claude; Claude logs in with $CLAUDE_CODE_OAUTH_TOKEN or
+	// $ANTHROPIC_API_KEY, which are passed in. lurker's --sandbox-image
+	// and the team policy's, if set, take its place.
+	SandboxImage string `json:"sandbox_image,omitempty"`
+
+	// Agent is the coding agent issues are run with, one of AgentNames;
+	// "" for lurker's --agent, Claude Code by default. It also writes the
+	// PR descriptions of ClaudePRs.
+	Agent string `json:"agent,omitempty"`
+
+	// detected is the toolchain found in the worktree, if any.
+	detected *Toolchain
 }
 
-// LoadRepoConfig reads .lurker/config.json from the given workdir.
-// Returns a zero-value RepoConfig if the file doesn't exist.
+// LoadRepoConfig reads .lurker/config.json from the given workdir and
+// detects the project's toolchain. Without the file, only the toolchain
+// is set.
 func LoadRepoConfig(workdir string) RepoConfig {
+	var cfg RepoConfig
 	p := filepath.Join(workdir, ".lurker", "config.json")
-	data, err := os.ReadFile(p)
-	if err != nil {
-		return RepoConfig{}
+	if data, err := os.ReadFile(p); err == nil {
+		cfg = parseRepoConfig(data)
+	}
+	if tc, ok := DetectToolchain(workdir); ok {
+		cfg.detected = &tc
 	}
+	return cfg
+}
+
+func parseRepoConfig(data []byte) RepoConfig {
 	var cfg RepoConfig
 	json.Unmarshal(data, &cfg)
 	return cfg
 }
 
-// ClaudeTools returns the tool permissions string, using overrides if configured.
+// defaultBaseBranch is used when a repo doesn't configure its own and
+// its default branch can't be found.
+const defaultBaseBranch = "main"
+
+// toolchain returns the configured or detected toolchain.
+func (c RepoConfig) toolchain() (Toolchain, bool) {
+	if tc, ok := toolchainByName(c.Toolchain); ok {
+		return tc, true
+	}
+	if c.detected != nil {
+		return *c.detected, true
+	}
+	return Toolchain{}, false
+}
+
+// Build returns the build command: the override if configured, else the
+// toolchain's (bazel if none was detected).
+func (c RepoConfig) Build() string {
+	if c.BuildCommand != "" {
+		return c.BuildCommand
+	}
+	if tc, ok := c.toolchain(); ok {
+		return tc.Build
+	}
+	return defaultToolchain.Build
+}
+
+// Test returns the test command: the override if configured, else the
+// toolchain's (bazel if none was detected).
+func (c RepoConfig) Test() string {
+	if c.TestCommand != "" {
+		return c.TestCommand
+	}
+	if tc, ok := c.toolchain(); ok {
+		return tc.Test
+	}
+	return defaultToolchain.Test
+}
+
+// ClaudeTools returns the tool permissions string, using overrides if
+// configured, else the default tools with the toolchain's commands.
 func (c RepoConfig) ClaudeTools() string {
 	if len(c.AllowedTools) > 0 {
 		return strings.Join(c.AllowedTools, ",")
 	}
+	if tc, ok := c.toolchain(); ok {
+		return toolsFor(tc)
+	}
 	return claudeTools
 }
+
+// SetBaseBranch records the branch repo's issues branch from and PR into.
+// An empty branch clears it, falling back to the repo's config.
+func (m *Manager) SetBaseBranch(repo, branch string) error {
+	branch = strings.TrimSpace(branch)
+	if branch != "" && !validBranchName(branch) {
+		return fmt.Errorf("invalid branch name %q", branch)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if branch == "" {
+		delete(m.state.BaseBranches, repo)
+	} else {
+		if m.state.BaseBranches == nil {
+			m.state.BaseBranches = make(map[string]string)
+		}
+		m.state.BaseBranches[repo] = branch
+	}
+	return m.saveState()
+}
+
+// BaseBranch returns the branch repo's issues branch from and PR into: the
+// one chosen when the repo was added, else base_branch from the config in
+// workdir or the team policy, else the repo's default branch once
+// detectDefaultBranch has found it, else "main". Without a workdir, the
+// config is the one committed on the default branch of lurker's bare
+// clone, if it has one yet.
+func (m *Manager) BaseBranch(repo, workdir string) string {
+	m.mu.Lock()
+	branch := m.state.BaseBranches[repo]
+	detected := m.defaultBranches[repo]
+	m.mu.Unlock()
+	if branch != "" {
+		return branch
+	}
+	var cfg RepoConfig
+	if workdir != "" {
+		cfg = m.RepoConfig(workdir)
+	} else {
+		bare := m.layout.BareDir(repo)
+		cfg = m.teamConfig().Policy.apply(loadRepoConfigAt(context.Background(), bare, "HEAD"))
+	}
+	if cfg.BaseBranch != "" {
+		return cfg.BaseBranch
+	}
+	if detected != "" {
+		return detected
+	}
+	return defaultBaseBranch
+}
+
+// detectDefaultBranch looks up repo's default branch for BaseBranch, once
+// per run: from the forge, else from the HEAD of lurker's bare clone of
+// it, which points at the default branch as of the clone.
+func (m *Manager) detectDefaultBranch(ctx context.Context, repo string) {
+	m.mu.Lock()
+	_, known := m.defaultBranches[repo]
+	m.mu.Unlock()
+	if known {
+		return
+	}
+	var branch string
+	if m.ghClient != nil {
+		if r, err := m.ghClient.GetRepo(ctx, repo); err == nil {
+			branch = r.DefaultBranch
+		}
+	}
+	if branch == "" {
+		bare := m.layout.BareDir(repo)
+		if out, err := gitCmd(ctx, bare, nil, "symbolic-ref", "--short", "HEAD"); err == nil {
+			branch = out
+		}
+	}
+	if branch == "" || !validBranchName(branch) {
+		// Try again next time rather than settle on "main".
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.defaultBranches == nil {
+		m.defaultBranches = make(map[string]string)
+	}
+	m.defaultBranches[repo] = branch
+}
+
+// validBranchName rejects names that would be misread by git or the shell.
+func validBranchName(s string) bool {
+	return s != "" && !strings.HasPrefix(s, "-") && !strings.ContainsAny(s, " \t\n~^:?*[\\") && !strings.Contains(s, "..")
+}
@@ -0,0 +1,144 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	if cfg := LoadRepoConfig(dir); cfg.BaseBranch != "" || cfg.Test() != "bazel test //..." {
+		t.Errorf("missing config = %+v", cfg)
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".lurker"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".lurker", "config.json"),
+		[]byte(`{"test_command": "go test ./...", "base_branch": "develop"}`), 0o644)
+	cfg := LoadRepoConfig(dir)
+	if cfg.Test() != "go test ./..." || cfg.BaseBranch != "develop" {
+		t.Errorf("config = %+v", cfg)
+	}
+}
+
+func TestManager_BaseBranch(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("owner/repo")
+
+	if got := mgr.BaseBranch("owner/repo", ""); got != "main" {
+		t.Errorf("default base = %q, want main", got)
+	}
+
+	workdir := t.TempDir()
+	os.MkdirAll(filepath.Join(workdir, ".lurker"), 0o755)
+	os.WriteFile(filepath.Join(workdir, ".lurker", "config.json"), []byte(`{"base_branch": "release"}`), 0o644)
+	if got := mgr.BaseBranch("owner/repo", workdir); got != "release" {
+		t.Errorf("base from repo config = %q, want release", got)
+	}
+
+	if err := mgr.SetBaseBranch("owner/repo", "develop"); err != nil {
+		t.Fatalf("SetBaseBranch: %v", err)
+	}
+	if got := mgr.BaseBranch("owner/repo", workdir); got != "develop" {
+		t.Errorf("chosen base = %q, want develop", got)
+	}
+	if err := mgr.SetBaseBranch("owner/repo", "--force"); err == nil {
+		t.Error("expected error for an option-like branch name")
+	}
+
+	// The choice survives a restart and goes away with the repo.
+	mgr.Stop()
+	mgr, err = NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if got := mgr.BaseBranch("owner/repo", ""); got != "develop" {
+		t.Errorf("base after reload = %q, want develop", got)
+	}
+	mgr.RemoveRepo("owner/repo")
+	if got := mgr.BaseBranch("owner/repo", ""); got != "main" {
+		t.Errorf("base after removal = %q, want main", got)
+	}
+}
+
+func TestManager_BaseBranchFromBareClone(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("owner/repo")
+
+	// Before a worktree exists, the config committed on the default
+	// branch picks the base new worktrees start from.
+	src := t.TempDir()
+	gitIn(t, src, "init", "-q", "-b", "main")
+	os.MkdirAll(filepath.Join(src, ".lurker"), 0o755)
+	writeAndCommit(t, src, ".lurker/config.json", `{"base_branch": "develop"}`)
+	gitIn(t, dir, "clone", "-q", "--bare", src, filepath.Join(dir, "owner", "repo", "bare.git"))
+
+	if got := mgr.BaseBranch("owner/repo", ""); got != "develop" {
+		t.Errorf("base from bare clone = %q, want develop", got)
+	}
+}
+
+// repoForge reports a repo's default branch, or fails.
+type repoForge struct {
+	Forge
+	branch string
+}
+
+func (f *repoForge) GetRepo(ctx context.Context, repo string) (github.Repo, error) {
+	if f.branch == "" {
+		return github.Repo{}, errors.New("offline")
+	}
+	return github.Repo{FullName: repo, DefaultBranch: f.branch}, nil
+}
+
+func TestManager_DetectDefaultBranch(t *testing.T) {
+	ctx := context.Background()
+	forge := &repoForge{branch: "trunk"}
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, forge)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.detectDefaultBranch(ctx, "owner/api")
+	if got := mgr.BaseBranch("owner/api", ""); got != "trunk" {
+		t.Errorf("base = %q, want the default branch trunk", got)
+	}
+	// Found once per run.
+	forge.branch = "other"
+	mgr.detectDefaultBranch(ctx, "owner/api")
+	if got := mgr.BaseBranch("owner/api", ""); got != "trunk" {
+		t.Errorf("base = %q after a second lookup, want trunk", got)
+	}
+
+	// Offline, the bare clone's HEAD tells.
+	forge.branch = ""
+	bare := filepath.Join(dir, "owner", "web", "bare.git")
+	os.MkdirAll(bare, 0o755)
+	gitIn(t, bare, "init", "-q", "--bare", "-b", "develop")
+	mgr.detectDefaultBranch(ctx, "owner/web")
+	if got := mgr.BaseBranch("owner/web", ""); got != "develop" {
+		t.Errorf("base = %q, want develop from the bare clone", got)
+	}
+
+	mgr.detectDefaultBranch(ctx, "owner/unknown")
+	if got := mgr.BaseBranch("owner/unknown", ""); got != "main" {
+		t.Errorf("base = %q with nothing found, want main", got)
+	}
+}
@@ -0,0 +1,56 @@
+package watcher
+
+import (
+	"fmt"
+	"time"
+)
+
+// errorWindow is how long a repo-level error keeps an identical one from
+// being passed on, so a flapping network doesn't flood the frontends, the
+// journal and notifications with the same poll failure.
+const errorWindow = 5 * time.Minute
+
+// seenError is a repo-level error passed on lately, and how many identical
+// ones were held back since.
+type seenError struct {
+	at      time.Time
+	repeats int
+}
+
+// dedupError reports whether ev is to be passed on. A repo-level
+// EventError identical to one passed on in the last errorWindow is held
+// back; the next one passed on carries in Repeats how many were. Issue
+// errors each end a run, and always go through. Only forwardEvents calls
+// it.
+func (m *Manager) dedupError(ev *Event) bool {
+	if ev.Kind != EventError || ev.IssueNum != 0 {
+		return true
+	}
+	key := ev.Repo + "\x00" + ev.Text
+	now := ev.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if seen, ok := m.errorsSeen[key]; ok && now.Sub(seen.at) < errorWindow {
+		seen.repeats++
+		m.errorsSeen[key] = seen
+		return false
+	}
+	for k, seen := range m.errorsSeen {
+		if now.Sub(seen.at) >= errorWindow && k != key {
+			delete(m.errorsSeen, k)
+		}
+	}
+	ev.Repeats = m.errorsSeen[key].repeats
+	m.errorsSeen[key] = seenError{at: now}
+	return true
+}
+
+// RepeatsText is what a frontend appends to ev's Text for the identical
+// errors held back before it, if any.
+func (ev Event) RepeatsText() string {
+	if ev.Repeats == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (repeated %s)", plural(ev.Repeats, "time"))
+}
@@ -0,0 +1,40 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManager_DedupError(t *testing.T) {
+	m := &Manager{errorsSeen: make(map[string]seenError)}
+	start := time.Now()
+	pollFailed := func(after time.Duration) (Event, bool) {
+		ev := Event{Kind: EventError, Repo: "o/r", Text: "Poll failed: EOF", Timestamp: start.Add(after)}
+		return ev, m.dedupError(&ev)
+	}
+
+	if _, ok := pollFailed(0); !ok {
+		t.Fatal("first error held back")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, ok := pollFailed(time.Duration(i) * time.Minute); ok {
+			t.Errorf("repeat %d passed on", i)
+		}
+	}
+	other := Event{Kind: EventError, Repo: "o/other", Text: "Poll failed: EOF", Timestamp: start}
+	if !m.dedupError(&other) {
+		t.Error("another repo's error held back")
+	}
+	issue := Event{Kind: EventError, Repo: "o/r", IssueNum: 4, Text: "boom", Timestamp: start}
+	if !m.dedupError(&issue) || !m.dedupError(&issue) {
+		t.Error("issue error held back")
+	}
+
+	ev, ok := pollFailed(errorWindow)
+	if !ok || ev.Repeats != 3 || ev.RepeatsText() != " (repeated 3 times)" {
+		t.Errorf("after the window: %v, %+v", ok, ev)
+	}
+	if ev, _ := pollFailed(3 * errorWindow); ev.Repeats != 0 {
+		t.Errorf("repeats counted twice: %+v", ev)
+	}
+}
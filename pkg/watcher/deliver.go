@@ -0,0 +1,48 @@
+package watcher
+
+import (
+	"slices"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// WebhookPollInterval is how often repos that receive webhooks are still
+// polled, to catch missed deliveries and issues closed in the meantime.
+const WebhookPollInterval = time.Hour
+
+// DeliverIssue takes an open issue pushed to lurker, by a webhook, rather
+// than found by polling. Issues already known from either source, pull
+// requests and repos lurker isn't watching are ignored; it reports whether
+// the issue was new.
+func (m *Manager) DeliverIssue(repo string, gi github.Issue) bool {
+	if gi.PullRequest != nil || gi.State == "closed" || !m.MarkWebhook(repo) {
+		return false
+	}
+	iss := IssueFromGitHub(gi)
+	if !m.claimIssue(repo, iss) {
+		return false
+	}
+	m.eventCh <- issueFoundEvent(repo, iss)
+	return true
+}
+
+// MarkWebhook records a webhook delivery for repo, after which its watcher
+// polls only every WebhookPollInterval. It reports whether repo is
+// watched; deliveries for other repos are ignored.
+func (m *Manager) MarkWebhook(repo string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Contains(m.state.Repos, repo) {
+		return false
+	}
+	m.hooked[repo] = time.Now()
+	return true
+}
+
+// webhookFed reports whether repo has received a webhook delivery.
+func (m *Manager) webhookFed(repo string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.hooked[repo].IsZero()
+}
@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestManager_DeliverIssue(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.state.Repos = []string{"o/r"} // watched, without starting a poller
+
+	if mgr.DeliverIssue("other/repo", github.Issue{Number: 1, Title: "x"}) {
+		t.Error("delivered an issue of an unwatched repo")
+	}
+	if mgr.webhookFed("o/r") {
+		t.Error("repo fed by webhooks before any delivery")
+	}
+	if !mgr.DeliverIssue("o/r", github.Issue{Number: 1, Title: "Crash", State: "open"}) {
+		t.Fatal("new issue not delivered")
+	}
+	if ev := <-mgr.EventCh(); ev.Kind != EventIssueFound || ev.IssueNum != 1 || ev.Text != "Crash" {
+		t.Errorf("event = %+v", ev)
+	}
+	if !mgr.IsKnown(IssueKey("o/r", 1)) || !mgr.webhookFed("o/r") {
+		t.Error("delivery not recorded")
+	}
+
+	// Already found, by polling or an earlier delivery
+	if mgr.DeliverIssue("o/r", github.Issue{Number: 1, Title: "Crash"}) {
+		t.Error("known issue delivered twice")
+	}
+	mgr.StoreIssue("o/r", Issue{Number: 2})
+	if mgr.DeliverIssue("o/r", github.Issue{Number: 2}) {
+		t.Error("polled issue delivered again")
+	}
+	if mgr.DeliverIssue("o/r", github.Issue{Number: 3, PullRequest: &struct{}{}}) {
+		t.Error("pull request delivered as an issue")
+	}
+	select {
+	case ev := <-mgr.EventCh():
+		t.Errorf("unexpected event %+v", ev)
+	default:
+	}
+}
@@ -0,0 +1,163 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// OrgWatch is an owner, organization or user, whose repos are watched as
+// they turn up, optionally only those tagged with Topic.
+type OrgWatch struct {
+	Owner string `json:"owner"`
+	Topic string `json:"topic,omitempty"`
+	// Skipped holds repos removed by hand, which discovery leaves alone.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// discoverEvery is how often watched owners are checked for new repos.
+const discoverEvery = 15 * time.Minute
+
+// wants reports whether discovery should watch r.
+func (o OrgWatch) wants(r github.Repo) bool {
+	if r.Archived || !validRepoName(r.FullName) || slices.Contains(o.Skipped, r.FullName) {
+		return false
+	}
+	owner, _, _ := strings.Cut(r.FullName, "/")
+	return strings.EqualFold(owner, o.Owner) && (o.Topic == "" || slices.Contains(r.Topics, o.Topic))
+}
+
+// validOwner reports whether s looks like an organization or user name.
+func validOwner(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, "/ \t\n")
+}
+
+// AddOrg watches owner's repos, only those tagged with topic if it isn't
+// empty, adding new ones as they are created. Adding an owner again
+// changes its topic. Discovery runs right away if the manager is running.
+func (m *Manager) AddOrg(owner, topic string) error {
+	if !validOwner(owner) {
+		return fmt.Errorf("invalid owner %q", owner)
+	}
+	m.mu.Lock()
+	if i := m.orgIndex(owner); i >= 0 {
+		m.state.Orgs[i].Topic = topic
+	} else {
+		m.state.Orgs = append(m.state.Orgs, OrgWatch{Owner: owner, Topic: topic})
+	}
+	err := m.saveState()
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	select {
+	case m.discoverNow <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// RemoveOrg stops discovering owner's repos. Those already found stay
+// watched.
+func (m *Manager) RemoveOrg(owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.orgIndex(owner)
+	if i < 0 {
+		return fmt.Errorf("%s is not watched", owner)
+	}
+	m.state.Orgs = slices.Delete(m.state.Orgs, i, i+1)
+	return m.saveState()
+}
+
+// Orgs returns the owners whose repos are discovered.
+func (m *Manager) Orgs() []OrgWatch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orgs := make([]OrgWatch, len(m.state.Orgs))
+	for i, o := range m.state.Orgs {
+		o.Skipped = slices.Clone(o.Skipped)
+		orgs[i] = o
+	}
+	return orgs
+}
+
+// orgIndex returns the index of owner in state.Orgs, or -1. The caller
+// holds m.mu.
+func (m *Manager) orgIndex(owner string) int {
+	return slices.IndexFunc(m.state.Orgs, func(o OrgWatch) bool {
+		return strings.EqualFold(o.Owner, owner)
+	})
+}
+
+// skip keeps discovery from adding repo back after it was removed. The
+// caller holds m.mu.
+func (m *Manager) skip(repo string) {
+	owner, _, _ := strings.Cut(repo, "/")
+	if i := m.orgIndex(owner); i >= 0 && !slices.Contains(m.state.Orgs[i].Skipped, repo) {
+		m.state.Orgs[i].Skipped = append(m.state.Orgs[i].Skipped, repo)
+	}
+}
+
+// unskip undoes skip for a repo added again by hand. The caller holds
+// m.mu.
+func (m *Manager) unskip(repo string) {
+	owner, _, _ := strings.Cut(repo, "/")
+	if i := m.orgIndex(owner); i >= 0 {
+		m.state.Orgs[i].Skipped = slices.DeleteFunc(m.state.Orgs[i].Skipped, func(r string) bool { return r == repo })
+	}
+}
+
+// discoverLoop looks for new repos every discoverEvery, and whenever an
+// owner is added, until ctx is done.
+func (m *Manager) discoverLoop(ctx context.Context) {
+	ticker := time.NewTicker(discoverEvery)
+	defer ticker.Stop()
+	for {
+		m.discover(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.discoverNow:
+		}
+	}
+}
+
+// discover starts watching the repos of watched owners that aren't yet,
+// reporting what it added or what went wrong.
+func (m *Manager) discover(ctx context.Context) {
+	report := func(text string) {
+		m.eventCh <- Event{Kind: EventDiscovery, Text: text, Timestamp: time.Now()}
+	}
+	for _, org := range m.Orgs() {
+		watched := make(map[string]bool)
+		for _, repo := range m.Repos() {
+			watched[strings.ToLower(repo)] = true
+		}
+		repos, err := m.ghClient.ListOwnerRepos(ctx, org.Owner)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			report(fmt.Sprintf("%s/*: %v", org.Owner, err))
+			continue
+		}
+		ex := Export{Version: exportVersion}
+		for _, r := range repos {
+			if org.wants(r) && !watched[strings.ToLower(r.FullName)] {
+				ex.Repos = append(ex.Repos, ExportedRepo{Name: r.FullName})
+			}
+		}
+		added, err := m.Import(ex, false)
+		if err != nil {
+			report(fmt.Sprintf("%s/*: %v", org.Owner, err))
+		} else if added > 0 {
+			report(fmt.Sprintf("%s/*: watching %d new repos", org.Owner, added))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package watcher
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// ownerForge lists a fixed set of repos for any owner.
+type ownerForge struct {
+	Forge
+	repos []github.Repo
+}
+
+func (f ownerForge) ListOwnerRepos(ctx context.Context, owner string) ([]github.Repo, error) {
+	return f.repos, nil
+}
+
+func TestDiscover_AddsMatchingRepos(t *testing.T) {
+	forge := ownerForge{repos: []github.Repo{
+		{FullName: "acme/api", Topics: []string{"lurker"}},
+		{FullName: "acme/web"},
+		{FullName: "acme/old", Topics: []string{"lurker"}, Archived: true},
+	}}
+	m, err := NewManager(t.TempDir(), 30*time.Second, forge)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+
+	if err := m.AddOrg("acme", "lurker"); err != nil {
+		t.Fatalf("AddOrg: %v", err)
+	}
+	m.discover(context.Background())
+	if ev := <-m.EventCh(); ev.Kind != EventDiscovery || !strings.Contains(ev.Text, "1 new") {
+		t.Fatalf("event = %+v", ev)
+	}
+	if repos := m.Repos(); !slices.Equal(repos, []string{"acme/api"}) {
+		t.Fatalf("Repos = %v, want only the tagged, live repo", repos)
+	}
+
+	// Without a topic every live repo is picked up.
+	m.AddOrg("acme", "")
+	m.discover(context.Background())
+	<-m.EventCh()
+	if repos := m.Repos(); !slices.Equal(repos, []string{"acme/api", "acme/web"}) {
+		t.Fatalf("Repos = %v", repos)
+	}
+}
+
+func TestDiscover_RespectsRemovedRepos(t *testing.T) {
+	forge := ownerForge{repos: []github.Repo{{FullName: "acme/api"}}}
+	m, err := NewManager(t.TempDir(), 30*time.Second, forge)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+
+	m.AddOrg("acme", "")
+	m.discover(context.Background())
+	<-m.EventCh()
+	if err := m.RemoveRepo("acme/api"); err != nil {
+		t.Fatalf("RemoveRepo: %v", err)
+	}
+	m.discover(context.Background())
+	if repos := m.Repos(); len(repos) != 0 {
+		t.Fatalf("Repos = %v; a removed repo came back", repos)
+	}
+
+	// Adding it by hand lifts the skip.
+	m.AddRepo("acme/api")
+	if orgs := m.Orgs(); len(orgs[0].Skipped) != 0 {
+		t.Errorf("Skipped = %v after re-adding", orgs[0].Skipped)
+	}
+}
+
+func TestAddOrg_Validates(t *testing.T) {
+	m, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+	for _, owner := range []string{"", "a/b", ".."} {
+		if err := m.AddOrg(owner, ""); err == nil {
+			t.Errorf("AddOrg(%q) succeeded", owner)
+		}
+	}
+	if err := m.RemoveOrg("nobody"); err == nil {
+		t.Error("RemoveOrg of an unwatched owner succeeded")
+	}
+}
@@ -0,0 +1,196 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// exportVersion is bumped when the export format changes incompatibly.
+const exportVersion = 1
+
+// Export is the portable form of a lurker setup: which repos are watched
+// and how, and which owners' repos are discovered. It deliberately
+// excludes logs, worktrees and processing history so it can be shared
+// between machines or across a team.
+type Export struct {
+	Version int            `json:"version"`
+	Repos   []ExportedRepo `json:"repos"`
+	Orgs    []OrgWatch     `json:"orgs,omitempty"` // with their topics and skipped repos
+}
+
+// ExportedRepo is one watched repo in an Export.
+type ExportedRepo struct {
+	Name       string `json:"name"`                  // "owner/repo"
+	BaseBranch string `json:"base_branch,omitempty"` // chosen base, if any
+	Patch      bool   `json:"patch,omitempty"`       // in patch mode
+	Ignored    []int  `json:"ignored,omitempty"`     // issues abandoned, which stay unpicked
+}
+
+// Export returns the current watched-repo configuration.
+func (m *Manager) Export() Export {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ex := Export{Version: exportVersion, Repos: []ExportedRepo{}}
+	for _, repo := range m.state.Repos {
+		ex.Repos = append(ex.Repos, ExportedRepo{
+			Name:       repo,
+			BaseBranch: m.state.BaseBranches[repo],
+			Patch:      m.state.PatchMode[repo],
+			Ignored:    slices.Clone(m.state.Ignored[repo]),
+		})
+	}
+	for _, o := range m.state.Orgs {
+		o.Skipped = slices.Clone(o.Skipped)
+		ex.Orgs = append(ex.Orgs, o)
+	}
+	return ex
+}
+
+// WriteExport encodes ex as indented JSON.
+func WriteExport(w io.Writer, ex Export) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ex)
+}
+
+// ReadExport decodes and validates an export produced by WriteExport.
+func ReadExport(r io.Reader) (Export, error) {
+	var ex Export
+	if err := json.NewDecoder(r).Decode(&ex); err != nil {
+		return Export{}, fmt.Errorf("decoding export: %w", err)
+	}
+	if ex.Version != exportVersion {
+		return Export{}, fmt.Errorf("unsupported export version %d (want %d)", ex.Version, exportVersion)
+	}
+	for _, r := range ex.Repos {
+		if !validRepoName(r.Name) {
+			return Export{}, fmt.Errorf("invalid repo name %q", r.Name)
+		}
+		if r.BaseBranch != "" && !validBranchName(r.BaseBranch) {
+			return Export{}, fmt.Errorf("%s: invalid base branch %q", r.Name, r.BaseBranch)
+		}
+	}
+	for _, o := range ex.Orgs {
+		if !validOwner(o.Owner) {
+			return Export{}, fmt.Errorf("invalid owner %q", o.Owner)
+		}
+		for _, repo := range o.Skipped {
+			if !validRepoName(repo) {
+				return Export{}, fmt.Errorf("%s: invalid skipped repo %q", o.Owner, repo)
+			}
+		}
+	}
+	return ex, nil
+}
+
+// Import merges ex into the watched repos and owners, returning how many
+// repos were added. With replace, repos missing from ex stop being
+// watched, as if removed (their workdirs are left on disk), and the repos
+// kept take ex's settings. Watchers are started only if the manager is
+// running.
+func (m *Manager) Import(ex Export, replace bool) (int, error) {
+	m.mu.Lock()
+	var waits []func()
+	defer func() {
+		m.mu.Unlock()
+		for _, wait := range waits {
+			wait()
+		}
+	}()
+
+	want := make(map[string]bool, len(ex.Repos))
+	for _, r := range ex.Repos {
+		want[r.Name] = true
+	}
+
+	if replace {
+		var dropped []string
+		for _, repo := range m.state.Repos {
+			if want[repo] {
+				delete(m.state.BaseBranches, repo)
+				delete(m.state.PatchMode, repo)
+				delete(m.state.Ignored, repo)
+			} else {
+				dropped = append(dropped, repo)
+			}
+		}
+		for _, repo := range dropped {
+			waits = append(waits, m.forgetRepo(repo))
+		}
+	}
+
+	have := make(map[string]bool, len(m.state.Repos))
+	for _, repo := range m.state.Repos {
+		have[repo] = true
+	}
+
+	added := 0
+	for _, r := range ex.Repos {
+		if r.BaseBranch != "" {
+			if m.state.BaseBranches == nil {
+				m.state.BaseBranches = make(map[string]string)
+			}
+			m.state.BaseBranches[r.Name] = r.BaseBranch
+		}
+		if r.Patch {
+			if m.state.PatchMode == nil {
+				m.state.PatchMode = make(map[string]bool)
+			}
+			m.state.PatchMode[r.Name] = true
+		}
+		for _, num := range r.Ignored {
+			if m.state.Ignored == nil {
+				m.state.Ignored = make(map[string][]int)
+			}
+			if !m.ignored(r.Name, num) {
+				m.state.Ignored[r.Name] = append(m.state.Ignored[r.Name], num)
+			}
+		}
+		if have[r.Name] {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(m.baseDir, r.Name), 0o755); err != nil {
+			return added, fmt.Errorf("creating workdir: %w", err)
+		}
+		m.state.Repos = append(m.state.Repos, r.Name)
+		m.unskip(r.Name)
+		have[r.Name] = true
+		added++
+		if m.started {
+			m.startWatcher(r.Name, staggerDelay(added-1, len(ex.Repos), m.pollInterval))
+		}
+	}
+	for _, o := range ex.Orgs {
+		i := m.orgIndex(o.Owner)
+		if i < 0 {
+			m.state.Orgs = append(m.state.Orgs, OrgWatch{Owner: o.Owner})
+			i = len(m.state.Orgs) - 1
+		}
+		m.state.Orgs[i].Topic = o.Topic
+		for _, repo := range o.Skipped {
+			if !slices.Contains(m.state.Orgs[i].Skipped, repo) && !have[repo] {
+				m.state.Orgs[i].Skipped = append(m.state.Orgs[i].Skipped, repo)
+			}
+		}
+	}
+	if len(ex.Orgs) > 0 {
+		select {
+		case m.discoverNow <- struct{}{}:
+		default:
+		}
+	}
+
+	return added, m.saveState()
+}
+
+// validRepoName reports whether s looks like "owner/repo".
+func validRepoName(s string) bool {
+	owner, name, ok := strings.Cut(s, "/")
+	return ok && owner != "" && name != "" && owner != "." && owner != ".." &&
+		name != "." && name != ".." && !strings.Contains(name, "/")
+}
@@ -0,0 +1,134 @@
+package watcher
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer src.Stop()
+	src.AddRepo("a/one")
+	src.AddRepo("b/two")
+	src.SetBaseBranch("a/one", "develop")
+	if _, err := src.Abandon("a/one", 5, AbandonOptions{}); err != nil {
+		t.Fatalf("Abandon: %v", err)
+	}
+	src.AddOrg("acme", "lurker")
+	src.AddRepo("acme/api")
+	src.RemoveRepo("acme/api")
+
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, src.Export()); err != nil {
+		t.Fatalf("WriteExport: %v", err)
+	}
+
+	ex, err := ReadExport(&buf)
+	if err != nil {
+		t.Fatalf("ReadExport: %v", err)
+	}
+
+	dst, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer dst.Stop()
+	dst.AddRepo("b/two")
+	dst.AddRepo("c/three")
+
+	added, err := dst.Import(ex, false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+	if got := strings.Join(dst.Repos(), ","); got != "b/two,c/three,a/one" {
+		t.Errorf("merged repos = %s", got)
+	}
+	if got := dst.BaseBranch("a/one", ""); got != "develop" {
+		t.Errorf("imported base branch = %q, want develop", got)
+	}
+	if !dst.IsIgnored("a/one", 5) {
+		t.Error("abandoned issue not ignored once imported")
+	}
+	if orgs := dst.Orgs(); len(orgs) != 1 || orgs[0].Owner != "acme" || orgs[0].Topic != "lurker" || strings.Join(orgs[0].Skipped, ",") != "acme/api" {
+		t.Errorf("imported orgs = %+v", orgs)
+	}
+
+	if _, err := dst.Import(ex, true); err != nil {
+		t.Fatalf("Import replace: %v", err)
+	}
+	if got := strings.Join(dst.Repos(), ","); got != "b/two,a/one" {
+		t.Errorf("replaced repos = %s", got)
+	}
+}
+
+func TestImport_Replace(t *testing.T) {
+	src, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer src.Stop()
+	src.AddRepo("b/two")
+	src.AddRepo("a/one")
+	src.SetPatchMode("a/one", true)
+	ex := src.Export()
+
+	dst, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer dst.Stop()
+	dst.AddOrg("c", "")
+	dst.AddRepo("b/two")
+	dst.SetBaseBranch("b/two", "develop")
+	dst.AddRepo("c/three")
+	dst.SetBaseBranch("c/three", "develop")
+	dst.SetPatchMode("c/three", true)
+
+	if _, err := dst.Import(ex, true); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	// The dropped repo goes as if removed, and is skipped by discovery.
+	if dst.BaseBranch("c/three", "") != "main" || dst.PatchMode("c/three") {
+		t.Error("dropped repo kept its base branch or patch mode")
+	}
+	if orgs := dst.Orgs(); len(orgs) != 1 || !slices.Contains(orgs[0].Skipped, "c/three") {
+		t.Errorf("orgs = %+v, want c/three skipped", orgs)
+	}
+
+	// The rest match the export, which comes back the same.
+	if dst.BaseBranch("b/two", "") != "main" {
+		t.Error("kept repo kept a base branch the export doesn't have")
+	}
+	got := dst.Export()
+	if !reflect.DeepEqual(got.Repos, ex.Repos) {
+		t.Errorf("re-exported repos = %+v, want %+v", got.Repos, ex.Repos)
+	}
+}
+
+func TestReadExport_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"bad json":    `{`,
+		"version":     `{"version":99,"repos":[]}`,
+		"repo name":   `{"version":1,"repos":[{"name":"../../etc"}]}`,
+		"no owner":    `{"version":1,"repos":[{"name":"/repo"}]}`,
+		"extra slash": `{"version":1,"repos":[{"name":"a/b/c"}]}`,
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadExport(strings.NewReader(in)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package watcher
+
+import "strings"
+
+// FailureKind is the category of a failure, by what went wrong.
+type FailureKind string
+
+const (
+	FailureOther       FailureKind = ""
+	FailureAuth        FailureKind = "auth"        // GitHub credentials or token scopes
+	FailurePermissions FailureKind = "permissions" // lurker's own permission switches
+	FailureGitHub      FailureKind = "github"      // the API itself: rate limits, outages
+	FailureClone       FailureKind = "clone"
+	FailureAgent       FailureKind = "agent" // Claude failed or couldn't run
+	FailureTests       FailureKind = "tests"
+	FailurePush        FailureKind = "push"
+	FailurePR          FailureKind = "pr"
+)
+
+// Failure is an error classified into a category, with a suggested fix
+// when lurker recognizes the cause.
+type Failure struct {
+	Kind       FailureKind
+	Suggestion string
+}
+
+// failureRule matches errors containing any of its (lowercase) patterns,
+// in the stage it names ("" for any).
+type failureRule struct {
+	stage      FailureKind
+	patterns   []string
+	kind       FailureKind // "" keeps the stage's kind
+	suggestion string
+}
+
+// failureRules are tried in order; the first match wins.
+var failureRules = []failureRule{
+	{"", []string{"bad credentials", "401 unauthorized", "requires authentication", "authentication failed",
+		"could not read username", "gh auth login", "not logged into"},
+		FailureAuth, "GitHub credentials are missing or expired — run gh auth login"},
+	{"", []string{"saml"},
+		FailureAuth, "the token isn't authorized for the org's SSO — authorize it on github.com/settings/tokens"},
+	{"", []string{"resource not accessible", "scope"},
+		FailureAuth, "the token is missing the repo scope — run gh auth refresh -s repo"},
+	{"", []string{"is disabled"},
+		FailurePermissions, "lurker's permissions forbid this — see permissions.json and --observer"},
+	{"", []string{"rate limit"},
+		FailureGitHub, "GitHub's rate limit is exhausted — retry after it resets"},
+	{FailureAgent, []string{"claude timed out"},
+		"", "the run hit --run-timeout — raise it, or narrow the issue and retry"},
+	{"", []string{"could not resolve host", "network is unreachable", "connection refused", "i/o timeout", "timed out"},
+		"", "network trouble — check your connection and retry"},
+	{"", []string{"no space left on device"},
+		"", "the disk is full — free space under lurker's base directory"},
+
+	{FailureClone, []string{"couldn't find remote ref", "invalid reference", "not a valid object name"},
+		"", "the base branch doesn't exist — pick another when adding the repo or set base_branch"},
+	{FailureClone, []string{"repository not found", "not found"},
+		"", "the repo wasn't found — check its name and that your token can read it"},
+
+	{FailureAgent, []string{"executable file not found", "command not found", "code 127"},
+		"", "the claude CLI isn't installed — install Claude Code and put it on PATH"},
+	{FailureAgent, []string{"usage limit", "credit balance", "overloaded"},
+		"", "Claude is out of capacity — retry once your usage limit resets"},
+	{FailureAgent, []string{"prompt template"},
+		"", "the team's prompt template is broken — fix it in the team config"},
+
+	{FailurePush, []string{"protected branch", "gh006"},
+		"", "the branch is protected — push to the agent/issue-N branch instead"},
+	{FailurePush, []string{"non-fast-forward", "fetch first", "[rejected]"},
+		"", "the branch moved on GitHub — pull or rebase in the shell (s), then push again"},
+	{FailurePush, []string{"permission to", "denied to", "error: 403"},
+		"", "you can't push to this repo — ask for write access"},
+
+	{FailurePR, []string{"a pull request already exists"},
+		"", "a PR for this branch already exists — open it on GitHub"},
+	{FailurePR, []string{"no commits between"},
+		"", "the branch has no commits on top of the base — nothing to open a PR for"},
+	{FailurePR, []string{"422 unprocessable"},
+		"", "GitHub rejected the PR — check that the base branch exists"},
+}
+
+// stageSuggestions are offered when no rule matches.
+var stageSuggestions = map[FailureKind]string{
+	FailureClone:  "cloning failed — check the logs, then retry with space",
+	FailureAgent:  "Claude failed — check the logs, then retry with space or take over with t",
+	FailureTests:  "tests failed — reproduce them in the shell (s) or take over with t",
+	FailurePush:   "the push failed — run git push in the shell (s) to see why",
+	FailurePR:     "creating the PR failed — check the base branch and that the branch was pushed",
+	FailureGitHub: "the GitHub API call failed — check gh auth status and retry",
+}
+
+// failureStage infers from an error's wording which step failed.
+func failureStage(text string) FailureKind {
+	switch {
+	case strings.HasPrefix(text, "clone failed"), strings.HasPrefix(text, "bare clone"),
+		strings.HasPrefix(text, "create worktree"), strings.HasPrefix(text, "shallow clone"):
+		return FailureClone
+	case strings.HasPrefix(text, "push:"):
+		return FailurePush
+	case strings.HasPrefix(text, "pr:"):
+		return FailurePR
+	case strings.Contains(text, "claude"), strings.HasPrefix(text, "prompt template"):
+		return FailureAgent
+	case strings.Contains(text, "test"):
+		return FailureTests
+	case strings.HasPrefix(text, "poll failed"), strings.HasPrefix(text, "react failed"),
+		strings.HasPrefix(text, "github:"):
+		return FailureGitHub
+	}
+	return FailureOther
+}
+
+// ClassifyFailure categorizes an error as lurker reports it (e.g. an
+// EventError's Text) and suggests a fix.
+func ClassifyFailure(text string) Failure {
+	lower := strings.ToLower(text)
+	stage := failureStage(lower)
+	for _, r := range failureRules {
+		if r.stage != "" && r.stage != stage {
+			continue
+		}
+		for _, p := range r.patterns {
+			if strings.Contains(lower, p) {
+				kind := r.kind
+				if kind == "" {
+					kind = stage
+				}
+				return Failure{Kind: kind, Suggestion: r.suggestion}
+			}
+		}
+	}
+	return Failure{Kind: stage, Suggestion: stageSuggestions[stage]}
+}
@@ -0,0 +1,40 @@
+package watcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	for _, tc := range []struct {
+		text    string
+		kind    FailureKind
+		suggest string // substring of the suggestion; "" wants none
+	}{
+		{"Poll failed: github: list issues: 401 Unauthorized: Bad credentials", FailureAuth, "gh auth login"},
+		{"React failed: github: add reaction: 403 Forbidden: Resource not accessible by integration", FailureAuth, "gh auth refresh -s repo"},
+		{"pr: github: allow_pr_create is disabled", FailurePermissions, "permissions.json"},
+		{"Poll failed: github: list issues: 403 Forbidden: API rate limit exceeded", FailureGitHub, "rate limit"},
+		{"Clone failed: bare clone: exit code 1", FailureClone, "retry with space"},
+		{"Clone failed: create worktree: fatal: invalid reference: origin/mian", FailureClone, "base branch doesn't exist"},
+		{"Clone failed: dial tcp: lookup github.com: i/o timeout", FailureClone, "network"},
+		{"Claude exited with code 127", FailureAgent, "install Claude Code"},
+		{"Claude exited with code 1", FailureAgent, "take over"},
+		{"Prompt template: template: prompt:3: unexpected EOF", FailureAgent, "prompt template"},
+		{"Claude timed out after 45m0s", FailureAgent, "--run-timeout"},
+		{"Macro check stopped: test exited with code 2", FailureTests, "reproduce"},
+		{"push: ! [rejected] HEAD -> agent/issue-4 (fetch first): exit status 1", FailurePush, "pull or rebase"},
+		{"push: remote: Permission to o/r.git denied to bob.: exit status 128", FailurePush, "write access"},
+		{"pr: github: create PR: 422 Unprocessable Entity: A pull request already exists for o:agent/issue-4", FailurePR, "already exists"},
+		{"Write prompt: open /x: no space left on device", FailureOther, "disk is full"},
+		{"something odd", FailureOther, ""},
+	} {
+		f := ClassifyFailure(tc.text)
+		if f.Kind != tc.kind {
+			t.Errorf("%q: kind = %q, want %q", tc.text, f.Kind, tc.kind)
+		}
+		if tc.suggest == "" && f.Suggestion != "" || !strings.Contains(f.Suggestion, tc.suggest) {
+			t.Errorf("%q: suggestion = %q, want it to mention %q", tc.text, f.Suggestion, tc.suggest)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// FeedbackFile holds, in an issue's IssueDir, where watching its PR for
+// review feedback got to and the feedback Claude hasn't addressed yet.
+const FeedbackFile = "feedback.json"
+
+var (
+	// feedbackPollInterval is how often a PR is checked for new feedback.
+	feedbackPollInterval = 2 * time.Minute
+	// feedbackMaxErrors is how many checks in a row may fail before
+	// watching gives up.
+	feedbackMaxErrors = 5
+)
+
+// feedbacker is implemented by forges that list the reviews and comments
+// on a PR, which lets lurker have Claude address them.
+type feedbacker interface {
+	GetPR(ctx context.Context, repo string, number int) (*github.PullRequest, error)
+	PRFeedback(ctx context.Context, repo string, number int) ([]github.Feedback, error)
+}
+
+// feedbackState is the content of FeedbackFile.
+type feedbackState struct {
+	PR      int               `json:"pr"`
+	Seen    time.Time         `json:"seen"` // feedback left up to here has been picked up
+	Pending []github.Feedback `json:"pending,omitempty"`
+}
+
+func readFeedback(issueDir string) (feedbackState, bool) {
+	data, err := os.ReadFile(filepath.Join(issueDir, FeedbackFile))
+	if err != nil {
+		return feedbackState{}, false
+	}
+	var st feedbackState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return feedbackState{}, false
+	}
+	return st, true
+}
+
+func writeFeedback(issueDir string, st feedbackState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(issueDir, FeedbackFile), data, 0o644)
+}
+
+// PendingFeedback returns the feedback on repo#num's PR that Claude
+// hasn't addressed yet.
+func PendingFeedback(baseDir, repo string, num int) []github.Feedback {
+	st, _ := readFeedback(IssueDir(baseDir, repo, num))
+	return st.Pending
+}
+
+// FeedbackLines describes feedback for the log, a line each, e.g.
+// "bob on main.go:12: Check this error."
+func FeedbackLines(feedback []github.Feedback) []string {
+	lines := make([]string, 0, len(feedback))
+	for _, f := range feedback {
+		where := f.Author
+		switch {
+		case f.Path != "":
+			where += fmt.Sprintf(" on %s:%d", f.Path, f.Line)
+		case f.State == "CHANGES_REQUESTED":
+			where += " requested changes"
+		}
+		body, _, _ := strings.Cut(f.Body, "\n")
+		lines = append(lines, where+": "+truncateRunes(body, 200))
+	}
+	return lines
+}
+
+// feedbackPrompt asks Claude to address feedback left on PR prNum.
+func feedbackPrompt(prNum int, feedback []github.Feedback) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reviewers left this feedback on PR #%d, opened from your work on this issue:\n", prNum)
+	for _, f := range feedback {
+		b.WriteString("\n### " + f.Author)
+		switch {
+		case f.Path != "":
+			fmt.Fprintf(&b, " on %s:%d", f.Path, f.Line)
+		case f.State == "CHANGES_REQUESTED":
+			b.WriteString(" requested changes")
+		}
+		b.WriteString("\n\n" + f.Body + "\n")
+	}
+	b.WriteString(`
+Address each point: change the code where the reviewer is right, and
+commit the changes to this branch with messages that say what they
+address. Where a point needs no change, say why in the follow-ups of your
+result. Don't push or open a PR; lurker pushes your commits to the PR.`)
+	b.WriteString(resultPrompt)
+	return b.String()
+}
+
+// WatchFeedback polls PR prNum, opened for repo#num, for new reviews and
+// comments for as long as it is open, and emits EventFeedback for them.
+// With address_reviews set, Claude addresses them right away; with
+// delete_branches, the branch is deleted once the PR closes. It does
+// nothing if the PR is already watched or the forge can't list feedback,
+// and stops if the repo is removed.
+func (m *Manager) WatchFeedback(repo string, num, prNum int) {
+	forge, ok := m.ghClient.(feedbacker)
+	if !ok {
+		return
+	}
+	key := IssueKey(repo, num)
+	m.mu.Lock()
+	if m.prWatches[key] != nil {
+		m.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	m.prWatches[key] = stop
+	m.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.mu.Lock()
+			if m.prWatches[key] == stop {
+				delete(m.prWatches, key)
+			}
+			m.mu.Unlock()
+		}()
+		ctx := context.Background()
+		failures := 0
+		for {
+			pr, err := forge.GetPR(ctx, repo, prNum)
+			if err == nil && pr.State != "" && pr.State != "open" {
+				m.prClosed(repo, num, pr)
+				return
+			}
+			fresh, err := m.pollFeedback(ctx, forge, repo, num, prNum)
+			if err != nil {
+				if failures++; failures >= feedbackMaxErrors {
+					return
+				}
+			} else {
+				failures = 0
+			}
+			if len(fresh) > 0 {
+				m.eventCh <- Event{Kind: EventFeedback, Repo: repo, IssueNum: num, Text: strings.Join(FeedbackLines(fresh), "\n"), Timestamp: time.Now()}
+				if m.RepoConfig(IssueWorkdir(m.baseDir, repo, num)).AddressReviews && m.ghClient.Permissions().AllowPush {
+					m.AddressFeedback(repo, num)
+				}
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(feedbackPollInterval):
+			}
+		}
+	}()
+}
+
+// pollFeedback adds the feedback left on PR prNum since the last look to
+// the issue's pending feedback, and returns it. The first look only marks
+// where the next starts.
+func (m *Manager) pollFeedback(ctx context.Context, forge feedbacker, repo string, num, prNum int) ([]github.Feedback, error) {
+	dir := IssueDir(m.baseDir, repo, num)
+	m.mu.Lock()
+	st, ok := readFeedback(dir)
+	if !ok || st.PR != prNum {
+		err := writeFeedback(dir, feedbackState{PR: prNum, Seen: time.Now()})
+		m.mu.Unlock()
+		return nil, err
+	}
+	m.mu.Unlock()
+
+	all, err := forge.PRFeedback(ctx, repo, prNum)
+	if err != nil {
+		return nil, err
+	}
+	var fresh []github.Feedback
+	seen := st.Seen
+	for _, f := range all {
+		if f.CreatedAt.After(st.Seen) {
+			fresh = append(fresh, f)
+			if f.CreatedAt.After(seen) {
+				seen = f.CreatedAt
+			}
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Addressing may have taken some pending feedback meanwhile.
+	st, _ = readFeedback(dir)
+	st.PR, st.Seen, st.Pending = prNum, seen, append(st.Pending, fresh...)
+	return fresh, writeFeedback(dir, st)
+}
+
+// dropFeedback removes the first n of repo#num's pending feedback, once
+// Claude has addressed it.
+func (m *Manager) dropFeedback(repo string, num, n int) error {
+	dir := IssueDir(m.baseDir, repo, num)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := readFeedback(dir)
+	if !ok {
+		return nil
+	}
+	st.Pending = st.Pending[min(n, len(st.Pending)):]
+	return writeFeedback(dir, st)
+}
+
+// AddressFeedback resumes the Claude session of repo#num with the
+// pending feedback on its PR, and pushes what it commits to the PR. It
+// does nothing if Claude is already addressing the issue's feedback.
+func (m *Manager) AddressFeedback(repo string, num int) {
+	m.mu.Lock()
+	key := IssueKey(repo, num)
+	issue, ok := m.knownIssues[key]
+	w := m.repoWatchers[repo]
+	if !ok || w == nil || m.fixingPRs[key] {
+		m.mu.Unlock()
+		return
+	}
+	m.fixingPRs[key] = true
+	if cancel, ok := m.issueCtxs[key]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.issueCtxs[key] = cancel
+	done := m.runStarted(key)
+	m.mu.Unlock()
+
+	go func() {
+		defer done()
+		w.addressFeedback(ctx, m.eventCh, issue)
+		m.mu.Lock()
+		delete(m.fixingPRs, key)
+		m.mu.Unlock()
+	}()
+}
+
+// addressFeedback has Claude continue the most recent session in the
+// issue's worktree with its pending feedback, then pushes the branch.
+func (w *Watcher) addressFeedback(ctx context.Context, eventCh chan<- Event, issue Issue) {
+	num := issue.Number
+	run := w.runner(ctx, IssueKey(w.cfg.Repo, num))
+	issueDir := IssueDir(w.cfg.BaseDir, w.cfg.Repo, num)
+	workdir := IssueWorkdir(w.cfg.BaseDir, w.cfg.Repo, num)
+
+	w.manager.mu.Lock()
+	st, _ := readFeedback(issueDir)
+	w.manager.mu.Unlock()
+	if len(st.Pending) == 0 {
+		return
+	}
+
+	repoCfg := w.manager.RepoConfig(workdir)
+	tools := scopedTools(repoCfg.ClaudeTools(), repoCfg.Scope(issue))
+
+	w.backupBeforeRun(ctx, eventCh, num, workdir, w.manager.BaseBranch(w.cfg.Repo, workdir))
+
+	w.emit(eventCh, EventClaudeStart, num, fmt.Sprintf("Resuming Claude Code with the review of PR #%d...", st.PR))
+	promptFile := filepath.Join(issueDir, ".lurker-feedback.txt")
+	if err := os.WriteFile(promptFile, []byte(feedbackPrompt(st.PR, st.Pending)), 0o644); err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Write feedback prompt: %v", err))
+		return
+	}
+	if !w.runClaude(ctx, eventCh, run, num, workdir, promptFile, tools, true) || !w.runStages(ctx, eventCh, num, workdir, repoCfg) {
+		return
+	}
+
+	commits, _ := gitCmd(ctx, workdir, nil, "rev-list", "--count", "@{upstream}..HEAD")
+	remote, _, err := pushTarget(ctx, w.ghClient, w.cfg.Repo, workdir, IssueBranch(num))
+	if err == nil {
+		err = pushBranch(ctx, workdir, remote)
+	}
+	if rejected := (*PushRejectedError)(nil); errors.As(err, &rejected) {
+		hint := `set "force_push": true in .lurker/config.json, then press a to force-push it`
+		if repoCfg.ForcePush {
+			hint = "press a to force-push it"
+		}
+		w.emit(eventCh, EventError, num, fmt.Sprintf("push: %v; %s", err, hint))
+		return
+	}
+	if err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("push: %v", err))
+		return
+	}
+	if err := w.manager.dropFeedback(w.cfg.Repo, num, len(st.Pending)); err != nil {
+		w.emit(eventCh, EventClaudeLog, num, fmt.Sprintf("⚠ Saving %s: %v", FeedbackFile, err))
+	}
+	n, _ := strconv.Atoi(commits)
+	w.emit(eventCh, EventFeedbackDone, num, fmt.Sprintf("Pushed %s addressing %s on PR #%d",
+		plural(n, "commit"), plural(len(st.Pending), "review comment"), st.PR))
+}
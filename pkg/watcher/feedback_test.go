@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// feedbackForge reports each poll's feedback in turn, and the PR closed
+// once they run out.
+type feedbackForge struct {
+	Forge
+	mu    sync.Mutex
+	polls [][]github.Feedback
+}
+
+func (f *feedbackForge) GetPR(ctx context.Context, repo string, number int) (*github.PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.polls) == 0 {
+		return &github.PullRequest{Number: number, State: "closed"}, nil
+	}
+	return &github.PullRequest{Number: number, State: "open"}, nil
+}
+
+func (f *feedbackForge) PRFeedback(ctx context.Context, repo string, number int) ([]github.Feedback, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fb := f.polls[0]
+	f.polls = f.polls[1:]
+	return fb, nil
+}
+
+func TestWatchFeedback(t *testing.T) {
+	defer func(d time.Duration) { feedbackPollInterval = d }(feedbackPollInterval)
+	feedbackPollInterval = time.Millisecond
+
+	later := time.Now().Add(time.Hour)
+	old := github.Feedback{ID: 1, Author: "alice", Body: "Before lurker looked", CreatedAt: time.Now().Add(-time.Hour)}
+	line := github.Feedback{ID: 2, Author: "bob", Path: "main.go", Line: 12, Body: "Check this error.\nIt's dropped.", CreatedAt: later}
+	review := github.Feedback{ID: 3, Author: "bob", State: "CHANGES_REQUESTED", Body: "Needs tests.", CreatedAt: later}
+	forge := &feedbackForge{polls: [][]github.Feedback{
+		{old, line, review},
+		{old, line, review}, // nothing new
+	}}
+	base := t.TempDir()
+	m, err := NewManager(base, 30*time.Second, forge)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+	if err := os.MkdirAll(IssueDir(base, "o/r", 3), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The first look happened when the PR was opened.
+	if err := writeFeedback(IssueDir(base, "o/r", 3), feedbackState{PR: 7, Seen: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	m.WatchFeedback("o/r", 3, 7)
+	m.WatchFeedback("o/r", 3, 7) // already watched
+	ev := <-m.EventCh()
+	want := "bob on main.go:12: Check this error.\nbob requested changes: Needs tests."
+	if ev.Kind != EventFeedback || ev.IssueNum != 3 || ev.Text != want {
+		t.Fatalf("event = %+v", ev)
+	}
+	select {
+	case ev := <-m.EventCh():
+		t.Fatalf("unexpected event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pending := PendingFeedback(base, "o/r", 3)
+	if len(pending) != 2 || pending[0].ID != 2 || pending[1].ID != 3 {
+		t.Fatalf("pending = %+v", pending)
+	}
+	if err := m.dropFeedback("o/r", 3, 1); err != nil {
+		t.Fatal(err)
+	}
+	if pending := PendingFeedback(base, "o/r", 3); len(pending) != 1 || pending[0].ID != 3 {
+		t.Errorf("pending after drop = %+v", pending)
+	}
+}
+
+func TestFeedbackPrompt(t *testing.T) {
+	p := feedbackPrompt(7, []github.Feedback{
+		{Author: "bob", Path: "main.go", Line: 12, Body: "Check this error."},
+		{Author: "carol", Body: "Also update the docs?"},
+	})
+	for _, want := range []string{"PR #7", "### bob on main.go:12\n\nCheck this error.", "### carol\n\nAlso update the docs?", ResultFile} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q:\n%s", want, p)
+		}
+	}
+}
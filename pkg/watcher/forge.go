@@ -0,0 +1,49 @@
+package watcher
+
+import (
+	"context"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// Forge is the code host lurker works against: where it lists and claims
+// issues and opens PRs. *github.Client is one; *gitlab.Client is another,
+// mapping projects, award emoji and merge requests onto the same shapes.
+type Forge interface {
+	Permissions() github.Permissions
+	GetRepo(ctx context.Context, repo string) (github.Repo, error)
+	ListOwnerRepos(ctx context.Context, owner string) ([]github.Repo, error)
+	WebURL(repo string) string
+
+	ListOpenIssues(ctx context.Context, repo string) ([]github.Issue, error)
+	GetIssue(ctx context.Context, repo string, number int) (*github.Issue, error)
+	AddReaction(ctx context.Context, repo string, number int, reaction string) error
+	RemoveReaction(ctx context.Context, repo string, number int, reaction string) error
+
+	CreatePR(ctx context.Context, pr github.CreatePRRequest) (*github.PullRequest, error)
+	RequestReviewers(ctx context.Context, repo string, number int, reviewers []string) error
+	AddLabels(ctx context.Context, repo string, number int, labels []string) error
+	AddAssignees(ctx context.Context, repo string, number int, assignees []string) error
+	CreateComment(ctx context.Context, repo string, number int, body string) error
+	CreateStatus(ctx context.Context, repo, sha string, status github.CommitStatus) error
+	PRChecks(ctx context.Context, repo string, number int) ([]github.CheckRun, error)
+}
+
+// cloneTool is implemented by forges cloned with a CLI other than gh that
+// takes gh's `repo clone REPO DIR -- GIT-FLAGS` arguments.
+type cloneTool interface {
+	CloneTool() string
+}
+
+// cloneCLI returns the CLI that clones from forge.
+func cloneCLI(forge Forge) string {
+	if t, ok := forge.(cloneTool); ok {
+		return t.CloneTool()
+	}
+	return "gh"
+}
+
+// Forge returns the forge the manager works against.
+func (m *Manager) Forge() Forge {
+	return m.ghClient
+}
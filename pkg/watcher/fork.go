@@ -0,0 +1,88 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// forker is implemented by forges that can fork a repo into the user's
+// account, for pushing to repos they can't push to.
+type forker interface {
+	CreateFork(ctx context.Context, repo string) (github.Repo, error)
+}
+
+// forkRemote is the remote an issue's branch is pushed to when it goes to
+// a fork.
+const forkRemote = "fork"
+
+// A new fork takes a moment to become pushable, so pushes to one are
+// retried. Variables so tests needn't wait.
+var (
+	forkPushAttempts = 5
+	forkPushWait     = 3 * time.Second
+)
+
+// pushTarget returns the remote to push workdir's branch to and the PR
+// head naming it. Normally that's origin and branch; when the forge says
+// the user can't push to repo, it forks repo, adds the fork as a remote,
+// and the head becomes "owner:branch".
+func pushTarget(ctx context.Context, gh Forge, repo, workdir, branch string) (remote, head string, err error) {
+	f, ok := gh.(forker)
+	if !ok {
+		return "origin", branch, nil
+	}
+	r, err := gh.GetRepo(ctx, repo)
+	if err != nil || r.Permissions == nil || r.Permissions.Push {
+		// Without an answer, try origin: its push error says more.
+		return "origin", branch, nil
+	}
+	fork, err := f.CreateFork(ctx, repo)
+	if err != nil {
+		return "", "", fmt.Errorf("fork: %w", err)
+	}
+	origin, err := gitCmd(ctx, workdir, nil, "remote", "get-url", "origin")
+	if err != nil {
+		return "", "", fmt.Errorf("fork: %w", err)
+	}
+	if !strings.Contains(origin, repo) {
+		return "", "", fmt.Errorf("fork: origin %s isn't %s", origin, repo)
+	}
+	url := strings.Replace(origin, repo, fork.FullName, 1)
+	if _, err := gitCmd(ctx, workdir, nil, "remote", "get-url", forkRemote); err == nil {
+		_, err = gitCmd(ctx, workdir, nil, "remote", "set-url", forkRemote, url)
+	} else {
+		_, err = gitCmd(ctx, workdir, nil, "remote", "add", forkRemote, url)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("fork remote: %w", err)
+	}
+	owner, _, _ := strings.Cut(fork.FullName, "/")
+	return forkRemote, owner + ":" + branch, nil
+}
+
+// pushBranch pushes workdir's HEAD to remote, retrying a fork that isn't
+// ready yet. A non-fast-forward rejection is a *PushRejectedError.
+func pushBranch(ctx context.Context, workdir, remote string) error {
+	attempts := 1
+	if remote == forkRemote {
+		attempts = forkPushAttempts
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(forkPushWait):
+			}
+		}
+		if _, err = gitCmd(ctx, workdir, nil, "push", "-u", remote, "HEAD"); err == nil {
+			return nil
+		}
+	}
+	return rejectedPush(ctx, workdir, remote, err)
+}
@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// forkForge denies push access to its repo and forks it into "me".
+type forkForge struct {
+	Forge
+	push   bool
+	forked []string
+	pr     github.CreatePRRequest
+}
+
+func (f *forkForge) GetRepo(ctx context.Context, repo string) (github.Repo, error) {
+	return github.Repo{FullName: repo, Permissions: &github.RepoPermissions{Push: f.push}}, nil
+}
+
+func (f *forkForge) CreateFork(ctx context.Context, repo string) (github.Repo, error) {
+	f.forked = append(f.forked, repo)
+	_, name, _ := strings.Cut(repo, "/")
+	return github.Repo{FullName: "me/" + name}, nil
+}
+
+func (f *forkForge) CreatePR(ctx context.Context, pr github.CreatePRRequest) (*github.PullRequest, error) {
+	f.pr = pr
+	return &github.PullRequest{Number: 1}, nil
+}
+
+func TestOpenPR_PushesToFork(t *testing.T) {
+	root := t.TempDir()
+	for _, owner := range []string{"up", "me"} {
+		os.MkdirAll(filepath.Join(root, owner), 0o755)
+		gitIn(t, root, "init", "-q", "--bare", filepath.Join(owner, "repo.git"))
+	}
+	workdir := filepath.Join(root, "work")
+	gitIn(t, root, "clone", "-q", filepath.Join(root, "up", "repo.git"), workdir)
+	gitIn(t, workdir, "checkout", "-q", "-b", "agent/issue-4")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("fix\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "Fix")
+
+	gh := &forkForge{}
+	if _, _, err := OpenPR(context.Background(), gh, "up/repo", 4, workdir, PRSpec{Head: "agent/issue-4", Base: "main"}); err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if len(gh.forked) != 1 || gh.pr.Head != "me:agent/issue-4" || gh.pr.Repo != "up/repo" {
+		t.Errorf("forked %v, PR %+v", gh.forked, gh.pr)
+	}
+	if out := gitIn(t, filepath.Join(root, "me", "repo.git"), "branch", "--list", "agent/issue-4"); !strings.Contains(out, "agent/issue-4") {
+		t.Error("branch not pushed to the fork")
+	}
+	if out := gitIn(t, filepath.Join(root, "up", "repo.git"), "branch", "--list"); out != "" {
+		t.Errorf("upstream has branches %q", out)
+	}
+
+	// With push access the branch goes to origin as before.
+	gh = &forkForge{push: true}
+	if _, _, err := OpenPR(context.Background(), gh, "up/repo", 4, workdir, PRSpec{Head: "agent/issue-4", Base: "main"}); err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if len(gh.forked) != 0 || gh.pr.Head != "agent/issue-4" {
+		t.Errorf("forked %v, PR %+v", gh.forked, gh.pr)
+	}
+}
@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// formatMessage is the message of the commit holding what a repo's
+// format_command changed after a run.
+const formatMessage = "style: apply format_command"
+
+// format is the format stage: it runs cfg's format command in workdir once Claude is done, and
+// commits what it changes, so a branch doesn't bounce on formatting. A
+// worktree with uncommitted changes is left alone, and a failing command's
+// changes are undone; either way the run goes on.
+func (w *Watcher) format(ctx context.Context, eventCh chan<- Event, num int, workdir string, cfg RepoConfig) {
+	if cfg.FormatCommand == "" {
+		return
+	}
+	if status, err := gitCmd(ctx, workdir, nil, "status", "--porcelain"); err != nil || status != "" {
+		w.emit(eventCh, EventVerify, num, "⚠ Not running "+cfg.FormatCommand+": the worktree has uncommitted changes")
+		return
+	}
+
+	w.emit(eventCh, EventVerify, num, "Running "+cfg.FormatCommand+"...")
+	cmd := shellCommand(ctx, workdir, cfg.FormatCommand)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		w.emit(eventCh, EventVerify, num, fmt.Sprintf("⚠ %s failed: %v: %s", cfg.FormatCommand, err, lines[len(lines)-1]))
+		gitCmd(ctx, workdir, nil, "reset", "-q", "--hard")
+		gitCmd(ctx, workdir, nil, "clean", "-fdq")
+		return
+	}
+
+	changed, err := gitCmd(ctx, workdir, nil, "status", "--porcelain")
+	if err != nil || changed == "" {
+		w.emit(eventCh, EventVerify, num, "✓ "+cfg.FormatCommand+" changed nothing")
+		return
+	}
+	n := len(strings.Split(changed, "\n"))
+	_, err = gitCmd(ctx, workdir, nil, "add", "-A")
+	if err == nil {
+		_, err = gitCmd(ctx, workdir, nil, "commit", "-q", "-m", formatMessage)
+	}
+	if err != nil {
+		w.emit(eventCh, EventVerify, num, fmt.Sprintf("⚠ Committing what %s changed: %v", cfg.FormatCommand, err))
+		return
+	}
+	w.emit(eventCh, EventVerify, num, fmt.Sprintf("✓ %s reformatted %s; committed as %q", cfg.FormatCommand, plural(n, "file"), formatMessage))
+}
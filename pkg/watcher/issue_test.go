@@ -47,6 +47,18 @@ func TestIssueKey(t *testing.T) {
 	}
 }
 
+func TestParseIssueKey(t *testing.T) {
+	repo, num, err := ParseIssueKey("owner/repo#42")
+	if err != nil || repo != "owner/repo" || num != 42 {
+		t.Errorf("ParseIssueKey = %q, %d, %v", repo, num, err)
+	}
+	for _, bad := range []string{"", "owner/repo", "owner/repo#", "owner/repo#x", "owner/repo#0", "repo#1", "a/b/c#1", "/repo#1"} {
+		if _, _, err := ParseIssueKey(bad); err == nil {
+			t.Errorf("ParseIssueKey(%q) succeeded", bad)
+		}
+	}
+}
+
 func TestIssueStatusString(t *testing.T) {
 	tests := []struct {
 		status IssueStatus
@@ -0,0 +1,221 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+// JournalFile, in the base directory, keeps the Manager's recent events so
+// a restarted lurker can show the issues it knew about before its first
+// poll finishes.
+const JournalFile = "events.jsonl"
+
+// journalSize is how many events the journal keeps.
+const journalSize = 1000
+
+// journal is an on-disk ring of recent events. Events are appended as
+// JSON lines; the file is rewritten with the newest journalSize once it
+// holds twice that many.
+type journal struct {
+	path string
+
+	mu     sync.Mutex
+	events []Event // newest journalSize, oldest first
+	lines  int     // lines in the file
+	prior  []Event // what earlier runs recorded, for Replay
+}
+
+// openJournal loads the journal at path.
+func openJournal(path string) *journal {
+	j := &journal{path: path}
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			var ev Event
+			if json.Unmarshal(sc.Bytes(), &ev) == nil {
+				j.events = append(j.events, ev)
+			}
+			j.lines++
+		}
+		f.Close()
+	}
+	if len(j.events) > journalSize {
+		j.events = j.events[len(j.events)-journalSize:]
+	}
+	j.prior = append([]Event(nil), j.events...)
+	if j.lines > journalSize {
+		j.compact()
+	}
+	return j
+}
+
+// journaled reports whether events of kind are worth keeping: Claude's
+// output and poll chatter are left out.
+func journaled(kind EventKind) bool {
+	switch kind {
+	case EventClaudeLog, EventPollStart, EventPollDone, EventStatus:
+		return false
+	}
+	return true
+}
+
+// record appends ev to the journal. Write errors are ignored; the journal
+// is a convenience.
+func (j *journal) record(ev Event) {
+	if !journaled(ev.Kind) {
+		return
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, ev)
+	if len(j.events) > journalSize {
+		j.events = j.events[len(j.events)-journalSize:]
+	}
+	if j.lines >= 2*journalSize {
+		j.compact()
+		return
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err == nil {
+		j.lines++
+	}
+}
+
+// compact rewrites the file with just j.events.
+func (j *journal) compact() {
+	var b strings.Builder
+	for _, ev := range j.events {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			continue
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return
+	}
+	j.lines = len(j.events)
+}
+
+// Replay returns what earlier runs of lurker announced about the issues of
+// watched repos, an EventIssueFound per issue still where it was found,
+// oldest first. The issues count as known — they can be started at once —
+// but unconfirmed: the next poll of their repo announces them again if
+// they are still open and forgets them if not. Replay returns nothing
+// after its first call.
+func (m *Manager) Replay() []Event {
+	events := m.replayJournal()
+	for _, ev := range events {
+		m.track(ev)
+	}
+	return events
+}
+
+// replayJournal reads the journal for Replay.
+func (m *Manager) replayJournal() []Event {
+	m.journal.mu.Lock()
+	prior := m.journal.prior
+	m.journal.prior = nil
+	m.journal.mu.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	watched := make(map[string]bool, len(m.state.Repos))
+	for _, repo := range m.state.Repos {
+		watched[repo] = true
+	}
+	found := make(map[string]Event)
+	var order []string
+	for _, ev := range prior {
+		key := IssueKey(ev.Repo, ev.IssueNum)
+		switch ev.Kind {
+		case EventIssueFound:
+			if !watched[ev.Repo] {
+				continue
+			}
+			if _, ok := found[key]; !ok {
+				order = append(order, key)
+			}
+			found[key] = ev
+		case EventIssueMoved, EventIssueGone:
+			delete(found, key)
+		}
+	}
+
+	var events []Event
+	for _, key := range order {
+		ev, ok := found[key]
+		if !ok {
+			continue
+		}
+		if _, known := m.knownIssues[key]; known || m.ignored(ev.Repo, ev.IssueNum) {
+			continue
+		}
+		iss := Issue{
+			Number:    ev.IssueNum,
+			Title:     ev.Text,
+			Body:      ev.IssueBody,
+			URL:       ev.IssueURL,
+			CreatedAt: ev.IssueOpened,
+		}
+		for _, name := range strings.Split(ev.IssueLabels, ", ") {
+			if name != "" {
+				iss.Labels = append(iss.Labels, Label{Name: name})
+			}
+		}
+		m.knownIssues[key] = iss
+		m.replayed[key] = true
+		events = append(events, ev)
+	}
+	return events
+}
+
+// dropUnconfirmed forgets replayed issues of repo that its latest poll
+// didn't find open.
+func (m *Manager) dropUnconfirmed(repo string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := repo + "#"
+	for key := range m.replayed {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.replayed, key)
+			delete(m.knownIssues, key)
+			delete(m.tracked, key)
+		}
+	}
+}
+
+// forwardEvents passes events from the watchers on to EventCh, journaling,
+// tracking and timing them on the way, each followed by the EventStatus it
+// caused, if any. Repeated repo-level errors are held back.
+func (m *Manager) forwardEvents() {
+	for ev := range m.eventCh {
+		if !m.dedupError(&ev) {
+			continue
+		}
+		m.journal.record(ev)
+		status, changed := m.track(ev)
+		m.stats.record(ev, status)
+		m.outCh <- ev
+		if changed {
+			m.outCh <- status
+		}
+	}
+}
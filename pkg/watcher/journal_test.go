@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_Replay(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	for _, ev := range []Event{
+		{Kind: EventIssueFound, Repo: "o/r", IssueNum: 1, Text: "Crash", IssueLabels: "bug, p1"},
+		{Kind: EventPollDone, Repo: "o/r", Text: "Found 1 new issues"},
+		{Kind: EventIssueFound, Repo: "o/r", IssueNum: 2, Text: "Moved away"},
+		{Kind: EventIssueMoved, Repo: "o/r", IssueNum: 2, MovedRepo: "o/other", MovedNum: 9},
+		{Kind: EventIssueFound, Repo: "gone/repo", IssueNum: 3, Text: "Unwatched"},
+		{Kind: EventIssueFound, Repo: "o/r", IssueNum: 4, Text: "Slow"},
+	} {
+		mgr.eventCh <- ev
+		<-mgr.EventCh()
+	}
+	mgr.Stop()
+
+	mgr, err = NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.state.Repos = []string{"o/r"} // watched, without starting a poller
+
+	events := mgr.Replay()
+	if len(events) != 2 || events[0].IssueNum != 1 || events[1].IssueNum != 4 {
+		t.Fatalf("Replay = %+v, want issues 1 and 4", events)
+	}
+	if mgr.Replay() != nil {
+		t.Error("second Replay returned events")
+	}
+	iss := mgr.knownIssues[IssueKey("o/r", 1)]
+	if iss.Title != "Crash" || iss.LabelNames() != "bug, p1" {
+		t.Errorf("replayed issue = %+v", iss)
+	}
+
+	// The next poll announces still-open issues again and forgets the rest
+	if !mgr.claimIssue("o/r", Issue{Number: 1, Title: "Crash (renamed)"}) {
+		t.Error("replayed issue not re-announced by a poll")
+	}
+	if mgr.claimIssue("o/r", Issue{Number: 1}) {
+		t.Error("re-polled issue announced twice")
+	}
+	mgr.dropUnconfirmed("o/r")
+	if !mgr.IsKnown(IssueKey("o/r", 1)) || mgr.IsKnown(IssueKey("o/r", 4)) {
+		t.Error("dropUnconfirmed kept the wrong issues")
+	}
+}
+
+func TestJournal_Compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFile)
+	j := openJournal(path)
+	for i := range 2*journalSize + 1 {
+		j.record(Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: i + 1})
+		j.record(Event{Kind: EventClaudeLog, Repo: "o/r", IssueNum: i + 1, Text: "not kept"})
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != journalSize {
+		t.Errorf("journal has %d lines after compacting, want %d", lines, journalSize)
+	}
+
+	prior := openJournal(path).prior
+	if len(prior) != journalSize || prior[len(prior)-1].IssueNum != 2*journalSize+1 {
+		t.Errorf("reopened journal has %d events ending at %+v", len(prior), prior[len(prior)-1])
+	}
+}
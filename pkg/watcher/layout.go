@@ -0,0 +1,203 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// LayoutFile, in the data directory, records where the rest of a Layout
+// lives, so what is only given the data directory (lurker status, fleet,
+// bundle, migrate) finds it. Without one, everything is in the data
+// directory.
+const LayoutFile = "layout.json"
+
+// Layout is where lurker keeps its files, split by how they are treated:
+// what it can't do without, what it can fetch again, and what users edit.
+type Layout struct {
+	// Data holds each repo's issue directories: worktrees, logs,
+	// checkpoints and transcripts.
+	Data string `json:"-"`
+	// State holds state.json and the event journal.
+	State string `json:"state,omitempty"`
+	// Cache holds the bare clones and the team config checkout, which
+	// lurker fetches again if they are gone.
+	Cache string `json:"cache,omitempty"`
+	// Config holds the files users write: permissions.json, macros.json
+	// and shellrc.
+	Config string `json:"config,omitempty"`
+}
+
+// XDGLayout is the Layout the XDG base directory spec gives lurker:
+// a "lurker" directory in each of $XDG_DATA_HOME, $XDG_STATE_HOME,
+// $XDG_CACHE_HOME and $XDG_CONFIG_HOME, or their defaults under the home
+// directory.
+func XDGLayout() (Layout, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Layout{}, err
+	}
+	dir := func(env string, def ...string) string {
+		// The spec has relative paths ignored.
+		if d := os.Getenv(env); filepath.IsAbs(d) {
+			return filepath.Join(d, "lurker")
+		}
+		return filepath.Join(append([]string{home}, append(def, "lurker")...)...)
+	}
+	return Layout{
+		Data:   dir("XDG_DATA_HOME", ".local", "share"),
+		State:  dir("XDG_STATE_HOME", ".local", "state"),
+		Cache:  dir("XDG_CACHE_HOME", ".cache"),
+		Config: dir("XDG_CONFIG_HOME", ".config"),
+	}, nil
+}
+
+// LoadLayout returns the Layout whose data directory is dataDir, as its
+// LayoutFile records it.
+func LoadLayout(dataDir string) Layout {
+	var l Layout
+	if data, err := os.ReadFile(filepath.Join(dataDir, LayoutFile)); err == nil {
+		json.Unmarshal(data, &l)
+	}
+	l.Data = dataDir
+	return l.resolved()
+}
+
+// resolved returns l with the directories it leaves empty in its data
+// directory, as lurker kept everything before it had a Layout.
+func (l Layout) resolved() Layout {
+	for _, dir := range []*string{&l.State, &l.Cache, &l.Config} {
+		if *dir == "" {
+			*dir = l.Data
+		}
+	}
+	return l
+}
+
+// StatePath returns the path of l's state.json.
+func (l Layout) StatePath() string {
+	return filepath.Join(l.State, "state.json")
+}
+
+// BareDir returns the path of repo's bare clone in l.
+func (l Layout) BareDir(repo string) string {
+	return filepath.Join(l.Cache, repo, "bare.git")
+}
+
+// ConfigPath returns the path of the config file name in l.
+func (l Layout) ConfigPath(name string) string {
+	return filepath.Join(l.Config, name)
+}
+
+// save records l in its data directory, leaving out what is kept there.
+func (l Layout) save() error {
+	path := filepath.Join(l.Data, LayoutFile)
+	rec := l
+	for _, dir := range []*string{&rec.State, &rec.Cache, &rec.Config} {
+		if *dir == l.Data {
+			*dir = ""
+		}
+	}
+	if rec == (Layout{Data: l.Data}) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// The files each part of a Layout holds, which MigrateLayout moves, besides
+// the bare clones it moves by repo.
+var (
+	stateFiles  = []string{"state.json", JournalFile}
+	cacheFiles  = []string{"team-config"}
+	configFiles = []string{"permissions.json", "macros.json", "shellrc"}
+)
+
+// MigrateLayout moves lurker's files into l: the data directory from
+// legacy, if lurker kept it there until now and l's doesn't exist yet
+// (see Migrate), then state, bare clones and config from wherever l's data
+// directory last recorded them. It records l there, so LoadLayout finds it
+// from now on. lurker must not be running.
+//
+// progress, if non-nil, is called with a line per step.
+func MigrateLayout(legacy string, l Layout, progress func(string)) error {
+	if progress == nil {
+		progress = func(string) {}
+	}
+	l = l.resolved()
+	if legacy != "" && legacy != l.Data && existingDir(l.Data) == "" {
+		if _, err := os.Stat(LoadLayout(legacy).StatePath()); err == nil {
+			if err := Migrate(legacy, l.Data, progress); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.MkdirAll(l.Data, 0o755); err != nil {
+		return fmt.Errorf("layout: %w", err)
+	}
+
+	old := LoadLayout(l.Data)
+	move := func(from, to string, names []string) error {
+		if from == to {
+			return nil
+		}
+		for _, name := range names {
+			if err := movePath(filepath.Join(from, name), filepath.Join(to, name), progress); err != nil {
+				return fmt.Errorf("layout: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := move(old.State, l.State, stateFiles); err != nil {
+		return err
+	}
+	if err := move(old.Config, l.Config, configFiles); err != nil {
+		return err
+	}
+	if err := move(old.Cache, l.Cache, cacheFiles); err != nil {
+		return err
+	}
+	if old.Cache != l.Cache {
+		for _, repo := range loadState(l.StatePath()).Repos {
+			if err := movePath(old.BareDir(repo), l.BareDir(repo), progress); err != nil {
+				return fmt.Errorf("layout: %w", err)
+			}
+			if err := repairWorktrees(l.BareDir(repo), filepath.Join(l.Data, repo), filepath.Base(repo)); err != nil {
+				return fmt.Errorf("layout: %s: %w", repo, err)
+			}
+		}
+	}
+	return l.save()
+}
+
+// movePath moves the file or directory src to dst, copying it across
+// filesystems. A missing src is left be; an existing dst is an error.
+func movePath(src, dst string, progress func(string)) error {
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists; not moving %s there", dst, src)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	progress(fmt.Sprintf("Moving %s → %s", src, dst))
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		return fmt.Errorf("copying %s: %w (source left intact)", src, err)
+	}
+	return os.RemoveAll(src)
+}
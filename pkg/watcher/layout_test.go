@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXDGLayout(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "/var/state")
+	t.Setenv("XDG_CACHE_HOME", "relative/cache")
+	t.Setenv("XDG_CONFIG_HOME", "/etc/xdg")
+	l, err := XDGLayout()
+	if err != nil {
+		t.Fatalf("XDGLayout: %v", err)
+	}
+	want := Layout{
+		Data:   "/home/u/.local/share/lurker",
+		State:  "/var/state/lurker",
+		Cache:  "/home/u/.cache/lurker",
+		Config: "/etc/xdg/lurker",
+	}
+	if l != want {
+		t.Errorf("XDGLayout = %+v, want %+v", l, want)
+	}
+}
+
+func TestMigrateLayout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	legacy := filepath.Join(root, "share")
+
+	// Everything in one directory, as lurker used to keep it.
+	mgr, err := NewManager(legacy, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	mgr.AddRepo("owner/repo")
+	mgr.Stop()
+	os.WriteFile(filepath.Join(legacy, "permissions.json"), []byte("{}\n"), 0o644)
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	gitIn(t, src, "commit", "-q", "--allow-empty", "-m", "init")
+	gitIn(t, root, "clone", "-q", "--bare", src, filepath.Join(legacy, "owner/repo/bare.git"))
+	gitIn(t, filepath.Join(legacy, "owner/repo/bare.git"), "worktree", "add", "-q", "-b", "agent/issue-7", filepath.Join(legacy, "owner/repo/7/repo"))
+
+	l := Layout{
+		Data:   filepath.Join(root, "data"),
+		State:  filepath.Join(root, "state"),
+		Cache:  filepath.Join(root, "cache"),
+		Config: filepath.Join(root, "config"),
+	}
+	if err := MigrateLayout(legacy, l, nil); err != nil {
+		t.Fatalf("MigrateLayout: %v", err)
+	}
+	for _, path := range []string{
+		filepath.Join(l.State, "state.json"),
+		filepath.Join(l.Config, "permissions.json"),
+		filepath.Join(l.Data, "owner/repo/7/repo"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("not moved: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(l.Data, "state.json")); !os.IsNotExist(err) {
+		t.Errorf("state.json left in the data dir: %v", err)
+	}
+	wt := filepath.Join(l.Data, "owner/repo/7/repo")
+	if list := gitIn(t, l.BareDir("owner/repo"), "worktree", "list"); !strings.Contains(list, wt) {
+		t.Errorf("moved bare clone doesn't know the worktree:\n%s", list)
+	}
+	if got := strings.TrimSpace(gitIn(t, wt, "rev-parse", "--abbrev-ref", "HEAD")); got != "agent/issue-7" {
+		t.Errorf("worktree HEAD = %q", got)
+	}
+
+	// The data dir records the rest for whoever only knows it.
+	if got := LoadLayout(l.Data); got != l {
+		t.Errorf("LoadLayout = %+v, want %+v", got, l)
+	}
+	mgr, err = NewManager(l.Data, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	if repos := mgr.Repos(); len(repos) != 1 || repos[0] != "owner/repo" {
+		t.Errorf("Repos after migrating = %v", repos)
+	}
+	if snap, err := LoadSnapshot(l.Data); err != nil || len(snap.Repos) != 1 {
+		t.Errorf("LoadSnapshot = %+v, %v", snap, err)
+	}
+}
@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RunLimits bound each Claude run.
+type RunLimits struct {
+	MaxTurns int           // passed to claude as --max-turns; 0 for none
+	Timeout  time.Duration // a run still going after this long is stopped and fails; 0 for none
+}
+
+// SetRunLimits sets the limits of the Claude runs started from now on.
+func (m *Manager) SetRunLimits(l RunLimits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runLimits = l
+}
+
+// RunLimits returns the limits set with SetRunLimits.
+func (m *Manager) RunLimits() RunLimits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runLimits
+}
+
+// SetMaxClaudeRuns limits how many issues may run Claude at once; issues
+// started beyond that wait in StatusQueued for a slot. n <= 0 lifts the
+// limit. Call it before Start.
+func (m *Manager) SetMaxClaudeRuns(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n <= 0 {
+		m.claudeSlots = nil
+		return
+	}
+	m.claudeSlots = make(chan struct{}, n)
+}
+
+// ClaudeRuns reports how many issues are running Claude and the limit
+// (0 if unlimited).
+func (m *Manager) ClaudeRuns() (running, limit int) {
+	m.mu.Lock()
+	slots := m.claudeSlots
+	m.mu.Unlock()
+	return len(slots), cap(slots)
+}
+
+// claudeSlot waits for a free Claude slot for issue num, emitting
+// EventQueued if it has to wait and EventClaudeStart once it gets one. It
+// returns the func that frees the slot, or false if ctx ended first.
+func (w *Watcher) claudeSlot(ctx context.Context, eventCh chan<- Event, num int) (func(), bool) {
+	if w.manager == nil {
+		return func() {}, true
+	}
+	w.manager.mu.Lock()
+	slots := w.manager.claudeSlots
+	w.manager.mu.Unlock()
+	if slots == nil {
+		return func() {}, true
+	}
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	w.emit(eventCh, EventQueued, num, fmt.Sprintf("Queued: all %d Claude slots are busy", cap(slots)))
+	select {
+	case slots <- struct{}{}:
+		w.emit(eventCh, EventClaudeStart, num, "Claude slot free; running Claude Code...")
+		return release, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClaudeSlot(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.SetMaxClaudeRuns(1)
+	w := mgr.newWatcher("o/r")
+	eventCh := make(chan Event, 10)
+
+	release, ok := w.claudeSlot(context.Background(), eventCh, 1)
+	if !ok {
+		t.Fatal("first run didn't get a slot")
+	}
+	if running, limit := mgr.ClaudeRuns(); running != 1 || limit != 1 {
+		t.Errorf("ClaudeRuns = %d, %d; want 1, 1", running, limit)
+	}
+
+	// A second run queues until the first finishes
+	got := make(chan bool)
+	go func() {
+		release, ok := w.claudeSlot(context.Background(), eventCh, 2)
+		if ok {
+			release()
+		}
+		got <- ok
+	}()
+	if ev := <-eventCh; ev.Kind != EventQueued || ev.IssueNum != 2 {
+		t.Fatalf("event = %+v, want EventQueued for #2", ev)
+	}
+	release()
+	if !<-got {
+		t.Fatal("queued run never got a slot")
+	}
+	if ev := <-eventCh; ev.Kind != EventClaudeStart || ev.IssueNum != 2 {
+		t.Errorf("event = %+v, want EventClaudeStart for #2", ev)
+	}
+
+	// A queued run that is stopped gives up
+	release, _ = w.claudeSlot(context.Background(), eventCh, 1)
+	defer release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := w.claudeSlot(ctx, eventCh, 3); ok {
+		t.Error("stopped run got a slot")
+	}
+}
+
+func TestSetMaxClaudeRuns_Unlimited(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.SetMaxClaudeRuns(0)
+	w := mgr.newWatcher("o/r")
+	for i := range 10 {
+		if _, ok := w.claudeSlot(context.Background(), nil, i+1); !ok {
+			t.Fatalf("run %d didn't start without a limit", i+1)
+		}
+	}
+	if running, limit := mgr.ClaudeRuns(); running != 0 || limit != 0 {
+		t.Errorf("ClaudeRuns = %d, %d; want 0, 0", running, limit)
+	}
+}
@@ -0,0 +1,171 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// issueMetaVersion is bumped when IssueMeta changes incompatibly.
+const issueMetaVersion = 1
+
+// IssueMeta is the machine-readable record lurker keeps in each issue
+// directory as issue.json, so scripts (and later lurker versions) can see
+// an issue's state from the filesystem alone.
+type IssueMeta struct {
+	Version   int       `json:"version"`
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url,omitempty"`
+	Status    string    `json:"status"`
+	Branch    string    `json:"branch"`
+	Workdir   string    `json:"workdir,omitempty"`
+	PRNumber  int       `json:"pr_number,omitempty"`
+	PRURL     string    `json:"pr_url,omitempty"`
+	Checks    string    `json:"checks,omitempty"` // "pending", "passed" or "failed"
+	Build     string    `json:"build,omitempty"`  // likewise, for require_build, before Stages
+	Attempts  int       `json:"attempts"`
+	CostUSD   float64   `json:"cost_usd,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Questions string    `json:"questions,omitempty"` // while needs-input
+	CreatedAt time.Time `json:"created_at,omitzero"` // opened on GitHub
+	StartedAt time.Time `json:"started_at,omitzero"` // latest run started
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+
+	// Stages are how far the latest run got with its pipeline.
+	Stages []StageMeta `json:"stages,omitempty"`
+}
+
+// StageMeta is a StageState in an IssueMeta.
+type StageMeta struct {
+	Name  string `json:"name"`
+	State string `json:"state,omitempty"` // as in Checks
+}
+
+// stagesMeta returns stages as IssueMeta.Stages.
+func stagesMeta(stages []StageState) []StageMeta {
+	var meta []StageMeta
+	for _, s := range stages {
+		meta = append(meta, StageMeta{Name: s.Name, State: s.State.String()})
+	}
+	return meta
+}
+
+// Meta returns iss as an IssueMeta, leaving UpdatedAt zero.
+func (iss TrackedIssue) Meta() IssueMeta {
+	return IssueMeta{
+		Version:   issueMetaVersion,
+		Repo:      iss.Repo,
+		Number:    iss.Number,
+		Title:     iss.Title,
+		URL:       iss.URL,
+		Status:    iss.Status.String(),
+		Branch:    IssueBranch(iss.Number),
+		Workdir:   iss.Workdir,
+		PRNumber:  iss.PRNumber,
+		PRURL:     iss.PRURL,
+		Checks:    iss.Checks.String(),
+		Stages:    stagesMeta(iss.Stages),
+		Attempts:  iss.Attempts,
+		CostUSD:   iss.CostUSD,
+		Error:     iss.Error,
+		Questions: iss.Questions,
+		CreatedAt: iss.CreatedAt,
+		StartedAt: iss.StartedAt,
+	}
+}
+
+// IssueDir is where lurker keeps an issue's worktree, logs and metadata.
+func IssueDir(baseDir, repo string, num int) string {
+	return filepath.Join(baseDir, repo, fmt.Sprintf("%d", num))
+}
+
+// IssueWorkdir is the issue's git worktree inside its IssueDir.
+func IssueWorkdir(baseDir, repo string, num int) string {
+	return filepath.Join(IssueDir(baseDir, repo, num), filepath.Base(repo))
+}
+
+// IssueBranch is the branch lurker works on for an issue.
+func IssueBranch(num int) string {
+	return fmt.Sprintf("agent/issue-%d", num)
+}
+
+// WriteIssueMeta records iss in its issue directory. It does nothing for
+// issues lurker hasn't touched yet (no issue directory), so merely open
+// issues don't litter BaseDir.
+func WriteIssueMeta(baseDir string, iss TrackedIssue) error {
+	dir := IssueDir(baseDir, iss.Repo, iss.Number)
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	meta := iss.Meta()
+	meta.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling issue meta: %w", err)
+	}
+	path := filepath.Join(dir, "issue.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing issue meta: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadIssueMeta loads an issue's issue.json.
+func ReadIssueMeta(baseDir, repo string, num int) (IssueMeta, error) {
+	data, err := os.ReadFile(filepath.Join(IssueDir(baseDir, repo, num), "issue.json"))
+	if err != nil {
+		return IssueMeta{}, err
+	}
+	var meta IssueMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return IssueMeta{}, fmt.Errorf("parsing issue.json: %w", err)
+	}
+	return meta, nil
+}
+
+// ParseIssueStatus is the inverse of IssueStatus.String.
+func ParseIssueStatus(s string) (IssueStatus, bool) {
+	for st := StatusPending; st <= StatusWaiting; st++ {
+		if st.String() == s {
+			return st, true
+		}
+	}
+	return StatusPending, false
+}
+
+// RestoreFromMeta fills in what DeriveIssueStatus can't see from git —
+// attempts, PR, cost, and whether the last run failed or was paused —
+// from a previously written issue.json.
+func (iss *TrackedIssue) RestoreFromMeta(meta IssueMeta) {
+	iss.Attempts = meta.Attempts
+	iss.PRNumber = meta.PRNumber
+	iss.PRURL = meta.PRURL
+	iss.Checks = ParseChecksState(meta.Checks)
+	iss.Stages = nil
+	for _, s := range meta.Stages {
+		iss.Stages = append(iss.Stages, StageState{Name: s.Name, State: ParseChecksState(s.State)})
+	}
+	if len(meta.Stages) == 0 && meta.Build != "" {
+		iss.Stages = []StageState{{Name: StageBuild, State: ParseChecksState(meta.Build)}}
+	}
+	iss.CostUSD = meta.CostUSD
+	if !meta.StartedAt.IsZero() {
+		iss.StartedAt = meta.StartedAt
+	}
+	// Only refine a worktree that exists but has no commits yet; a branch
+	// with commits is ready regardless of what the file says, unless it
+	// conflicts with its base or failed its build or tests.
+	st, ok := ParseIssueStatus(meta.Status)
+	switch {
+	case !ok:
+	case iss.Status == StatusCloneReady && (st == StatusFailed || st == StatusPaused),
+		iss.Status == StatusReady && (st == StatusConflict || st == StatusTestsFailed || st == StatusBuildFailed):
+		iss.Status = st
+		iss.Error = meta.Error
+	}
+}
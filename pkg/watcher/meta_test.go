@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIssueMetaRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	iss := TrackedIssue{
+		Repo:      "owner/repo",
+		Number:    7,
+		Title:     "Crash",
+		Status:    StatusFailed,
+		Error:     "exit 1",
+		PRNumber:  12,
+		PRURL:     "https://github.com/owner/repo/pull/12",
+		Attempts:  2,
+		StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	// The cost is the manager's, as the run's session reported it.
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.tracked[IssueKey(iss.Repo, iss.Number)] = iss
+	mgr.track(Event{Kind: EventCost, Repo: iss.Repo, IssueNum: iss.Number, Text: "0.4213"})
+	iss, _ = mgr.Issue(iss.Repo, iss.Number)
+
+	// No issue directory yet: nothing is written.
+	if err := WriteIssueMeta(dir, iss); err != nil {
+		t.Fatalf("WriteIssueMeta: %v", err)
+	}
+	if _, err := ReadIssueMeta(dir, "owner/repo", 7); !os.IsNotExist(err) {
+		t.Fatalf("expected no issue.json before the issue dir exists, got %v", err)
+	}
+
+	os.MkdirAll(IssueDir(dir, "owner/repo", 7), 0o755)
+	if err := WriteIssueMeta(dir, iss); err != nil {
+		t.Fatalf("WriteIssueMeta: %v", err)
+	}
+	meta, err := ReadIssueMeta(dir, "owner/repo", 7)
+	if err != nil {
+		t.Fatalf("ReadIssueMeta: %v", err)
+	}
+	if meta.Status != "failed" || meta.Branch != "agent/issue-7" || meta.Attempts != 2 || meta.PRNumber != 12 || meta.CostUSD != 0.4213 {
+		t.Errorf("meta = %+v", meta)
+	}
+	if meta.UpdatedAt.IsZero() {
+		t.Error("UpdatedAt not set")
+	}
+
+	// Reconstruct: git only knows a worktree exists; issue.json knows the
+	// last run failed.
+	restored := TrackedIssue{Repo: "owner/repo", Number: 7, Status: StatusCloneReady}
+	restored.RestoreFromMeta(meta)
+	if restored.Status != StatusFailed || restored.Error != "exit 1" || restored.Attempts != 2 || restored.PRURL != iss.PRURL || restored.CostUSD != 0.4213 {
+		t.Errorf("restored = %+v", restored)
+	}
+	if !restored.StartedAt.Equal(iss.StartedAt) {
+		t.Errorf("StartedAt = %v", restored.StartedAt)
+	}
+
+	// A branch with commits stays ready whatever the file says.
+	ready := TrackedIssue{Status: StatusReady}
+	ready.RestoreFromMeta(meta)
+	if ready.Status != StatusReady {
+		t.Errorf("status = %v, want ready", ready.Status)
+	}
+}
+
+func TestParseIssueStatus(t *testing.T) {
+	for st := StatusPending; st <= StatusNeedsInput; st++ {
+		if got, ok := ParseIssueStatus(st.String()); !ok || got != st {
+			t.Errorf("ParseIssueStatus(%q) = %v, %v", st.String(), got, ok)
+		}
+	}
+	if _, ok := ParseIssueStatus("bogus"); ok {
+		t.Error("expected bogus status to fail")
+	}
+}
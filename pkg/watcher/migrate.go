@@ -0,0 +1,155 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Migrate moves a lurker base directory — worktrees and logs, and state
+// and bare clones unless its Layout keeps them apart — from one location
+// to another and re-links everything that records absolute paths: git
+// worktrees and Claude Code's per-directory session history, so in-flight
+// issues can be resumed from the new place. lurker must not be running on
+// from.
+//
+// progress, if non-nil, is called with a line per step.
+func Migrate(from, to string, progress func(string)) error {
+	if progress == nil {
+		progress = func(string) {}
+	}
+	from, err := filepath.Abs(from)
+	if err != nil {
+		return err
+	}
+	to, err = filepath.Abs(to)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("migrate: source and destination are the same")
+	}
+	if strings.HasPrefix(to, from+string(filepath.Separator)) {
+		return fmt.Errorf("migrate: %s is inside %s", to, from)
+	}
+	layout := LoadLayout(from)
+	if _, err := os.Stat(layout.StatePath()); err != nil {
+		return fmt.Errorf("migrate: %s is not a lurker directory: %w", from, err)
+	}
+	if entries, err := os.ReadDir(to); err == nil {
+		if len(entries) > 0 {
+			return fmt.Errorf("migrate: %s is not empty", to)
+		}
+		if err := os.Remove(to); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	state := loadState(layout.StatePath())
+
+	// Remember which worktrees exist so their Claude history can follow.
+	var worktrees []string
+	for _, repo := range state.Repos {
+		repoDir := filepath.Join(from, repo)
+		entries, _ := os.ReadDir(repoDir)
+		for _, e := range entries {
+			wt := filepath.Join(repoDir, e.Name(), filepath.Base(repo))
+			if _, err := os.Stat(wt); e.IsDir() && err == nil {
+				worktrees = append(worktrees, strings.TrimPrefix(wt, from))
+			}
+		}
+	}
+
+	progress(fmt.Sprintf("Moving %s → %s", from, to))
+	if err := os.Rename(from, to); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		progress("Different filesystem; copying (this may take a while)")
+		if err := copyTree(from, to); err != nil {
+			return fmt.Errorf("migrate: copying: %w (source left intact)", err)
+		}
+		if err := os.RemoveAll(from); err != nil {
+			return fmt.Errorf("migrate: removing %s after copy: %w", from, err)
+		}
+	}
+
+	for _, repo := range state.Repos {
+		progress("Repairing worktrees for " + repo)
+		if err := repairWorktrees(LoadLayout(to).BareDir(repo), filepath.Join(to, repo), filepath.Base(repo)); err != nil {
+			return fmt.Errorf("migrate: %s: %w", repo, err)
+		}
+	}
+
+	for _, wt := range worktrees {
+		oldDir, err := claudeProjectDir(from + wt)
+		if err != nil {
+			break
+		}
+		newDir, _ := claudeProjectDir(to + wt)
+		if _, err := os.Stat(oldDir); err != nil {
+			continue
+		}
+		if err := os.Rename(oldDir, newDir); err != nil {
+			progress(fmt.Sprintf("Could not move Claude history for %s: %v", to+wt, err))
+		}
+	}
+
+	progress("Done")
+	return nil
+}
+
+// copyTree recursively copies src to dst, preserving file modes and
+// symlinks.
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			// Sockets, FIFOs and the like aren't worth carrying over.
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+		"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestMigrate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := t.TempDir()
+	from := filepath.Join(root, "old")
+	to := filepath.Join(root, "new", "lurker")
+
+	mgr, err := NewManager(from, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	mgr.AddRepo("owner/repo")
+	mgr.Stop()
+
+	// A bare clone with one issue worktree, like cloneAndSetup makes.
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	gitIn(t, src, "commit", "-q", "--allow-empty", "-m", "init")
+	bare := filepath.Join(from, "owner/repo/bare.git")
+	gitIn(t, root, "clone", "-q", "--bare", src, bare)
+	wt := filepath.Join(from, "owner/repo/7/repo")
+	gitIn(t, bare, "worktree", "add", "-q", "-b", "agent/issue-7", wt)
+
+	// Claude history for the worktree.
+	oldHistory, _ := claudeProjectDir(wt)
+	os.MkdirAll(oldHistory, 0o755)
+	os.WriteFile(filepath.Join(oldHistory, "s.jsonl"), []byte("{}\n"), 0o644)
+
+	var steps []string
+	if err := Migrate(from, to, func(s string) { steps = append(steps, s) }); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("source should be gone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(to, "state.json")); err != nil {
+		t.Errorf("state not moved: %v", err)
+	}
+	newWT := filepath.Join(to, "owner/repo/7/repo")
+	if got := strings.TrimSpace(gitIn(t, newWT, "rev-parse", "--abbrev-ref", "HEAD")); got != "agent/issue-7" {
+		t.Errorf("worktree HEAD = %q", got)
+	}
+	if list := gitIn(t, filepath.Join(to, "owner/repo/bare.git"), "worktree", "list"); !strings.Contains(list, newWT) {
+		t.Errorf("bare clone doesn't know the new worktree path:\n%s", list)
+	}
+	newHistory, _ := claudeProjectDir(newWT)
+	if _, err := os.Stat(filepath.Join(newHistory, "s.jsonl")); err != nil {
+		t.Errorf("Claude history not moved: %v", err)
+	}
+	if len(steps) == 0 || steps[len(steps)-1] != "Done" {
+		t.Errorf("progress = %v", steps)
+	}
+}
+
+func TestMigrate_RefusesNonEmptyDest(t *testing.T) {
+	from := t.TempDir()
+	os.WriteFile(filepath.Join(from, "state.json"), []byte(`{}`), 0o644)
+	to := t.TempDir()
+	os.WriteFile(filepath.Join(to, "x"), nil, 0o644)
+
+	if err := Migrate(from, to, nil); err == nil {
+		t.Error("expected error migrating into a non-empty directory")
+	}
+	if err := Migrate(from, filepath.Join(from, "sub"), nil); err == nil {
+		t.Error("expected error migrating into the source")
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "a/b"), 0o755)
+	os.WriteFile(filepath.Join(src, "a/b/f"), []byte("data"), 0o600)
+	os.Symlink("b/f", filepath.Join(src, "a/link"))
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "a/b/f")); string(data) != "data" {
+		t.Errorf("file content = %q", data)
+	}
+	if info, _ := os.Stat(filepath.Join(dst, "a/b/f")); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v", info.Mode().Perm())
+	}
+	if link, _ := os.Readlink(filepath.Join(dst, "a/link")); link != "b/f" {
+		t.Errorf("symlink = %q", link)
+	}
+}
@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// A repo in patch mode gets no bare clone or persistent worktree: each
+// issue is worked on in a shallow checkout of the base branch that is
+// deleted after the run, and what Claude changed is kept in the issue's
+// directory as PatchFile (for git apply) and, if Claude committed,
+// PatchBundle (fetch agent/issue-N from it). It suits triage- or
+// suggestion-only use on repos too big to keep checked out.
+const (
+	PatchFile   = "changes.patch"
+	PatchBundle = "changes.bundle"
+)
+
+// patchPrompt replaces questionsPrompt in patch mode, where there is no
+// session left to resume.
+const patchPrompt = `
+
+## Output
+
+Your changes are saved as a patch for a human to review; the checkout is
+deleted afterwards and you cannot be asked follow-up questions. If
+something is unclear, make the most reasonable choice and explain it in
+your commit message.
+`
+
+// PatchMode reports whether repo is in patch mode.
+func (m *Manager) PatchMode(repo string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.PatchMode[repo]
+}
+
+// SetPatchMode switches repo in or out of patch mode. Issues that already
+// have a worktree keep using it.
+func (m *Manager) SetPatchMode(repo string, on bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if on {
+		if m.state.PatchMode == nil {
+			m.state.PatchMode = make(map[string]bool)
+		}
+		m.state.PatchMode[repo] = true
+	} else {
+		delete(m.state.PatchMode, repo)
+	}
+	return m.saveState()
+}
+
+// PatchPath returns the patch a patch-mode run left for repo#num, or "".
+func PatchPath(baseDir, repo string, num int) string {
+	path := filepath.Join(IssueDir(baseDir, repo, num), PatchFile)
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		return ""
+	}
+	return path
+}
+
+// shallowClone checks out the tip of base alone into workdir, on the
+// issue's branch.
+func (w *Watcher) shallowClone(run runFunc, issueDir, workdir string, num int, base string) error {
+	if err := os.MkdirAll(issueDir, 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	// A checkout left by an interrupted run is stale.
+	if err := os.RemoveAll(workdir); err != nil {
+		return err
+	}
+	steps := []struct{ name, cmd string }{
+		{"shallow clone", fmt.Sprintf("%s repo clone %s %s -- --depth 1 --single-branch --branch %s%s",
+			cloneCLI(w.ghClient), ShellQuote(w.cfg.Repo), ShellQuote(workdir), ShellQuote(base), referenceArgs([]string{w.bareDir()}))},
+		{"create branch", fmt.Sprintf("git -C %s checkout -q -b %s",
+			ShellQuote(workdir), ShellQuote(IssueBranch(num)))},
+	}
+	for _, s := range steps {
+		code, err := run(s.cmd)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		if code != 0 {
+			return fmt.Errorf("%s: exit code %d", s.name, code)
+		}
+	}
+	return nil
+}
+
+// CapturePatch saves what changed in workdir since origin/base into
+// issueDir: every change, committed or not, as PatchFile, and the issue
+// branch's commits as PatchBundle. It returns the patch's path.
+func CapturePatch(ctx context.Context, issueDir, workdir string, num int, base string) (string, error) {
+	_, commit, err := worktreeCommit(ctx, workdir, issueDir, "lurker patch")
+	if err != nil {
+		return "", err
+	}
+	patch, err := gitRaw(ctx, workdir, nil, "diff", "--binary", "origin/"+base, commit)
+	if err != nil {
+		return "", err
+	}
+	if patch == "" {
+		return "", errors.New("no changes to capture")
+	}
+	path := filepath.Join(issueDir, PatchFile)
+	if err := os.WriteFile(path, []byte(patch), 0o644); err != nil {
+		return "", err
+	}
+
+	bundle := filepath.Join(issueDir, PatchBundle)
+	os.Remove(bundle)
+	if n, err := gitCmd(ctx, workdir, nil, "rev-list", "--count", "origin/"+base+"..HEAD"); err == nil && n != "0" {
+		if _, err := gitCmd(ctx, workdir, nil, "bundle", "create", "-q", bundle, IssueBranch(num), "^origin/"+base); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCapturePatch(t *testing.T) {
+	origin := t.TempDir()
+	gitIn(t, origin, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(origin, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, origin, "add", ".")
+	gitIn(t, origin, "commit", "-q", "-m", "init")
+
+	issueDir := t.TempDir()
+	workdir := filepath.Join(issueDir, "repo")
+	gitIn(t, origin, "clone", "-q", "--depth", "1", "--single-branch", "--branch", "main", "file://"+origin, workdir)
+	gitIn(t, workdir, "checkout", "-q", "-b", IssueBranch(3))
+
+	if _, err := CapturePatch(context.Background(), issueDir, workdir, 3, "main"); err == nil {
+		t.Error("expected an error when nothing changed")
+	}
+
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+	gitIn(t, workdir, "commit", "-q", "-am", "two")
+	os.WriteFile(filepath.Join(workdir, "b.txt"), []byte("uncommitted\n"), 0o644)
+
+	path, err := CapturePatch(context.Background(), issueDir, workdir, 3, "main")
+	if err != nil {
+		t.Fatalf("CapturePatch: %v", err)
+	}
+	if path != filepath.Join(issueDir, PatchFile) {
+		t.Errorf("path = %s", path)
+	}
+
+	// The patch applies to the base and carries both kinds of change.
+	check := filepath.Join(t.TempDir(), "check")
+	gitIn(t, origin, "clone", "-q", origin, check)
+	gitIn(t, check, "apply", path)
+	if data, _ := os.ReadFile(filepath.Join(check, "a.txt")); string(data) != "two\n" {
+		t.Errorf("a.txt after apply = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(check, "b.txt")); string(data) != "uncommitted\n" {
+		t.Errorf("b.txt after apply = %q", data)
+	}
+
+	// The bundle holds the committed work on the issue branch.
+	gitIn(t, check, "fetch", "-q", filepath.Join(issueDir, PatchBundle), IssueBranch(3)+":fetched")
+	if log := gitIn(t, check, "log", "--format=%s", "-1", "fetched"); strings.TrimSpace(log) != "two" {
+		t.Errorf("bundle branch tip = %q", log)
+	}
+	if status := gitIn(t, workdir, "status", "--porcelain"); status != "?? b.txt\n" {
+		t.Errorf("capture touched the worktree: %q", status)
+	}
+}
+
+func TestPatchMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.AddRepo("owner/repo")
+	if mgr.PatchMode("owner/repo") {
+		t.Error("patch mode on by default")
+	}
+	if err := mgr.SetPatchMode("owner/repo", true); err != nil {
+		t.Fatal(err)
+	}
+	if ex := mgr.Export(); len(ex.Repos) != 1 || !ex.Repos[0].Patch {
+		t.Errorf("export = %+v", ex)
+	}
+	mgr.Stop()
+
+	reloaded, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Stop()
+	if !reloaded.PatchMode("owner/repo") {
+		t.Error("patch mode not persisted")
+	}
+
+	// A finished patch-mode issue has no worktree but is ready.
+	if status, _ := DeriveIssueStatus(dir, "owner/repo", 5, "main"); status != StatusPending {
+		t.Errorf("status without patch = %v", status)
+	}
+	os.MkdirAll(IssueDir(dir, "owner/repo", 5), 0o755)
+	os.WriteFile(filepath.Join(IssueDir(dir, "owner/repo", 5), PatchFile), []byte("diff --git a/x b/x\n"), 0o644)
+	if status, workdir := DeriveIssueStatus(dir, "owner/repo", 5, "main"); status != StatusReady || workdir != "" {
+		t.Errorf("status with patch = %v, %q", status, workdir)
+	}
+}
@@ -0,0 +1,217 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// PRDraft is the default content of an issue's PR, derived from its
+// worktree.
+type PRDraft struct {
+	Head      string
+	Title     string
+	Body      string
+	Bases     []string // candidate base branches, default first
+	Reviewers []string
+	Labels    []string
+	Assignees []string
+	Warnings  []string // e.g. why Claude's description couldn't be used
+}
+
+// LoadPRDraft computes the default branch, title, body and base candidates
+// for the PR of issue num, titled title, in workdir, and the reviewers,
+// labels and assignees cfg gives it. With claude_prs set, Claude writes
+// the title and body, in box's sandbox if box is non-nil; if that fails
+// the defaults stand, with a warning. The body is cfg's pr_body template, if it has one.
+func LoadPRDraft(box *Bwrap, workdir string, num int, title, base string, cfg RepoConfig) (PRDraft, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = workdir
+	branchOut, err := cmd.Output()
+	if err != nil {
+		return PRDraft{}, fmt.Errorf("branch: %w", err)
+	}
+	head := strings.TrimSpace(string(branchOut))
+
+	// Claude's account of the run, if it left one, else the commits.
+	data := PRBodyData{Number: num, Title: title, Footer: cfg.Templates.footer()}
+	if r, ok := ReadRunResult(filepath.Dir(workdir)); ok {
+		data.Summary = r.Markdown()
+	}
+	cmd = exec.Command("git", "log", "--oneline", base+".."+head)
+	cmd.Dir = workdir
+	logOut, _ := cmd.Output()
+	if log := strings.TrimSpace(string(logOut)); log != "" {
+		data.Commits = strings.Split(log, "\n")
+	}
+
+	// Offer the remote's branches as bases, the repo's base first.
+	bases := []string{base}
+	cmd = exec.Command("git", "for-each-ref", "--format=%(refname:short)", "refs/remotes/origin")
+	cmd.Dir = workdir
+	refsOut, _ := cmd.Output()
+	for _, ref := range strings.Split(strings.TrimSpace(string(refsOut)), "\n") {
+		name := strings.TrimPrefix(ref, "origin/")
+		if name == "" || name == "origin" || name == "HEAD" || name == base || name == head || strings.HasPrefix(name, "agent/") {
+			continue
+		}
+		bases = append(bases, name)
+	}
+
+	reviewers := slices.Clone(cfg.PRReviewers)
+	if cfg.CodeownersReviewers {
+		cmd = exec.Command("git", "diff", "--name-only", base+"..."+head)
+		cmd.Dir = workdir
+		filesOut, _ := cmd.Output()
+		files := strings.Fields(string(filesOut))
+		for _, owner := range CodeOwners(workdir, files) {
+			if !slices.Contains(reviewers, owner) {
+				reviewers = append(reviewers, owner)
+			}
+		}
+	}
+
+	draft := PRDraft{
+		Head:      head,
+		Title:     fmt.Sprintf("Fix #%d: %s", num, title),
+		Bases:     bases,
+		Reviewers: reviewers,
+		Labels:    cfg.PRLabels,
+		Assignees: cfg.PRAssignees,
+	}
+	if cfg.ClaudePRs {
+		if t, desc, err := DescribePR(context.Background(), box, workdir, num, title, base, cfg); err != nil {
+			draft.Warnings = append(draft.Warnings, fmt.Sprintf("Claude's PR description: %v", err))
+		} else {
+			draft.Title, data.Summary = t, desc
+		}
+	}
+	// A broken pr_body template shouldn't stop the PR; lurker's own body
+	// stands in.
+	if draft.Body, err = cfg.Templates.prBody(data); err != nil {
+		draft.Warnings = append(draft.Warnings, err.Error())
+		draft.Body, _ = Templates{}.prBody(data)
+	}
+	return draft, nil
+}
+
+// prEditor is implemented by forges that can edit an open PR.
+type prEditor interface {
+	EditPR(ctx context.Context, repo string, number int, title, body string) error
+}
+
+// UpdatePR replaces the title and body of repo's PR number, e.g. one that
+// OpenPR found already open (see github.PRExistsError).
+func UpdatePR(ctx context.Context, gh Forge, repo string, number int, title, body string) error {
+	e, ok := gh.(prEditor)
+	if !ok {
+		return errors.New("this forge can't edit PRs")
+	}
+	return e.EditPR(ctx, repo, number, title, body)
+}
+
+// PRSpec is everything needed to push and open a PR.
+type PRSpec struct {
+	Head      string
+	Title     string
+	Body      string
+	Base      string
+	Draft     bool
+	Reviewers []string
+	Labels    []string
+	Assignees []string
+	Summary   bool    // comment with the run summary
+	CostUSD   float64 // of the latest run, for the summary
+	Templates Templates
+}
+
+// OpenPR pushes workdir's branch, to a fork if the user can't push to
+// repo, and opens the PR for issue num, then requests reviewers, adds
+// labels, assigns users, posts the verification statuses and the run
+// summary. Failures after the PR exists are returned as warnings
+// alongside it. If the branch has an open PR already, the push stands and
+// the error wraps a *github.PRExistsError.
+func OpenPR(ctx context.Context, gh Forge, repo string, num int, workdir string, spec PRSpec) (*github.PullRequest, []string, error) {
+	remote, head, err := pushTarget(ctx, gh, repo, workdir, spec.Head)
+	if err != nil {
+		return nil, nil, fmt.Errorf("push: %w", err)
+	}
+	if err := pushBranch(ctx, workdir, remote); err != nil {
+		return nil, nil, fmt.Errorf("push: %w", err)
+	}
+
+	pr, err := gh.CreatePR(ctx, github.CreatePRRequest{
+		Repo:  repo,
+		Title: spec.Title,
+		Body:  spec.Body,
+		Head:  head,
+		Base:  spec.Base,
+		Draft: spec.Draft,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("pr: %w", err)
+	}
+
+	var warnings []string
+	if len(spec.Reviewers) > 0 {
+		if err := gh.RequestReviewers(ctx, repo, pr.Number, spec.Reviewers); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	if len(spec.Labels) > 0 {
+		if err := gh.AddLabels(ctx, repo, pr.Number, spec.Labels); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	if len(spec.Assignees) > 0 {
+		if err := gh.AddAssignees(ctx, repo, pr.Number, spec.Assignees); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	if err := PostVerification(ctx, gh, repo, workdir); err != nil && !errors.Is(err, ErrNoVerification) {
+		warnings = append(warnings, fmt.Sprintf("statuses: %v", err))
+	}
+	if spec.Summary {
+		if err := postRunSummary(ctx, gh, repo, num, pr.Number, workdir, spec.CostUSD, spec.Templates); err != nil {
+			warnings = append(warnings, fmt.Sprintf("summary: %v", err))
+		}
+	}
+	return pr, warnings, nil
+}
+
+// postRunSummary comments on PR number, for issue num, with the summary
+// of the run in workdir. Without the run's cost it gives its session's.
+func postRunSummary(ctx context.Context, gh Forge, repo string, num, number int, workdir string, cost float64, tmpl Templates) error {
+	s, err := LoadRunSummary(workdir)
+	if err != nil {
+		return err
+	}
+	s.CostUSD = cost
+	if cost <= 0 {
+		if u, err := ReadSessionUsage(workdir, time.Time{}); err == nil {
+			s.CostUSD = u.CostUSD
+		}
+	}
+	body, err := render("summary_comment", tmpl.SummaryComment, CommentData{
+		Repo:     repo,
+		Number:   num,
+		Branch:   IssueBranch(num),
+		Duration: s.Duration,
+		CostUSD:  s.CostUSD,
+		Summary:  s.Text,
+		Commands: s.Commands,
+		Turns:    s.Turns,
+		PRNumber: number,
+	}, s.Markdown)
+	if err != nil {
+		return err
+	}
+	return gh.CreateComment(ctx, repo, number, body)
+}
@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPRDraft(t *testing.T) {
+	workdir := t.TempDir()
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+	gitIn(t, workdir, "checkout", "-q", "-b", IssueBranch(3))
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+	gitIn(t, workdir, "commit", "-q", "-am", "Handle empty input")
+
+	d, err := LoadPRDraft(nil, workdir, 3, "Crash on empty input", "main", RepoConfig{})
+	if err != nil {
+		t.Fatalf("LoadPRDraft: %v", err)
+	}
+	if d.Head != "agent/issue-3" || d.Title != "Fix #3: Crash on empty input" {
+		t.Errorf("draft = %+v", d)
+	}
+	if !strings.HasPrefix(d.Body, "Fixes #3") || !strings.Contains(d.Body, "Handle empty input") {
+		t.Errorf("body = %q", d.Body)
+	}
+	if len(d.Bases) != 1 || d.Bases[0] != "main" {
+		t.Errorf("bases = %v", d.Bases)
+	}
+
+	os.WriteFile(filepath.Join(workdir, "CODEOWNERS"), []byte("*.txt @alice @erin @org/team\n*.go @bob\n"), 0o644)
+	cfg := RepoConfig{PRReviewers: []string{"carol", "alice"}, PRLabels: []string{"ai-generated"}, PRAssignees: []string{"dave"}, CodeownersReviewers: true}
+	d, err = LoadPRDraft(nil, workdir, 3, "Crash on empty input", "main", cfg)
+	if err != nil {
+		t.Fatalf("LoadPRDraft: %v", err)
+	}
+	if strings.Join(d.Reviewers, " ") != "carol alice erin" || strings.Join(d.Labels, " ") != "ai-generated" || strings.Join(d.Assignees, " ") != "dave" {
+		t.Errorf("reviewers %v, labels %v, assignees %v", d.Reviewers, d.Labels, d.Assignees)
+	}
+
+	// Claude's result replaces the commit list.
+	os.WriteFile(filepath.Join(filepath.Dir(workdir), RunResultFile), []byte(`{"summary": "Return early on empty input."}`), 0o644)
+	d, err = LoadPRDraft(nil, workdir, 3, "Crash on empty input", "main", RepoConfig{})
+	if err != nil {
+		t.Fatalf("LoadPRDraft: %v", err)
+	}
+	if !strings.Contains(d.Body, "## Summary\n\nReturn early on empty input.") || strings.Contains(d.Body, "## Commits") {
+		t.Errorf("body = %q", d.Body)
+	}
+}
+
+func TestPostRunSummary_Cost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workdir := filepath.Join(t.TempDir(), "repo")
+	dir, _ := claudeProjectDir(workdir)
+	os.MkdirAll(dir, 0o755)
+	session := `{"type":"assistant","timestamp":"2026-01-02T10:01:00Z","message":{"id":"m1","model":"claude-sonnet-4-5","usage":{"input_tokens":10000,"output_tokens":20000},"content":[{"type":"text","text":"Fixed it."}]}}
+`
+	os.WriteFile(filepath.Join(dir, "s.jsonl"), []byte(session), 0o644)
+
+	// The run's cost, as the manager recorded it.
+	gh := &commentLog{}
+	if err := postRunSummary(context.Background(), gh, "o/r", 4, 9, workdir, 1.5, Templates{}); err != nil {
+		t.Fatalf("postRunSummary: %v", err)
+	}
+	// Or, unrecorded, the session's: 10k in at $3, 20k out at $15.
+	if err := postRunSummary(context.Background(), gh, "o/r", 4, 9, workdir, 0, Templates{}); err != nil {
+		t.Fatalf("postRunSummary: %v", err)
+	}
+	for i, want := range []string{"| Cost | $1.50 |", "| Cost | $0.33 |"} {
+		if !strings.Contains(gh.bodies[i], want) {
+			t.Errorf("comment %d lacks %q:\n%s", i, want, gh.bodies[i])
+		}
+	}
+}
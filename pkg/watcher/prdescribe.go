@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PR descriptions are written by Claude in prDescribeDir, inside the
+// issue's IssueDir: it reads prDiffFile and writes prDescriptionFile.
+const (
+	prDescribeDir      = "pr-description"
+	prDiffFile         = "pr.diff"
+	prDescriptionFile  = "PR.md"
+	prDescribeTools    = "Read,Write"
+	prDescribeTimeout  = 5 * time.Minute
+	prTemplateFallback = `## Summary
+What the PR does and why, in a few sentences.
+
+## Changes
+The notable changes, a bullet each.
+
+## Testing
+How the change was tested.`
+)
+
+// prTemplate returns the outline for cfg's PR descriptions: its
+// pr_template, else the repo's GitHub PR template, else a default.
+func prTemplate(workdir string, cfg RepoConfig) string {
+	if cfg.PRTemplate != "" {
+		return cfg.PRTemplate
+	}
+	for _, name := range []string{".github/pull_request_template.md", ".github/PULL_REQUEST_TEMPLATE.md", "pull_request_template.md", "docs/pull_request_template.md"} {
+		if data, err := os.ReadFile(filepath.Join(workdir, name)); err == nil && strings.TrimSpace(string(data)) != "" {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return prTemplateFallback
+}
+
+// prDescriptionPrompt asks Claude to describe the change for issue num,
+// whose commits are log, following template. result is Claude's account
+// of the run, if it left one.
+func prDescriptionPrompt(num int, title, base, log, result, template string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `You are writing the pull request for a change made to fix issue #%d,
+%q. Its commits are:
+
+%s
+Its diff against %s is in %s.`, num, title, indent(strings.TrimSpace(log), "    ")+"\n", base, prDiffFile)
+	if result != "" {
+		fmt.Fprintf(&b, " The agent that made the change summed it up as:\n\n%s\n", indent(strings.TrimSpace(result), "    "))
+	}
+	fmt.Fprintf(&b, `
+Read the diff (and nothing outside this directory), then write the pull
+request to %s in exactly this format:
+
+    title: A short title in the imperative, under 72 characters
+
+    The description.
+
+The description follows this outline, filling in each section for a
+reviewer who hasn't seen the issue; drop a section only if it can't
+apply:
+
+%s
+
+Don't mention yourself, lurker or the issue number; lurker adds
+"Fixes #%d".`, prDescriptionFile, indent(template, "    "), num)
+	return b.String()
+}
+
+// parsePRDescription reads what Claude wrote to prDescriptionFile: a
+// "title:" line, then the body.
+func parsePRDescription(text string) (title, body string, err error) {
+	text = strings.TrimSpace(text)
+	first, rest, _ := strings.Cut(text, "\n")
+	title, ok := strings.CutPrefix(strings.TrimSpace(first), "title:")
+	if title = strings.TrimSpace(title); !ok || title == "" {
+		return "", "", errors.New(`no "title:" line`)
+	}
+	if body = strings.TrimSpace(rest); body == "" {
+		return "", "", errors.New("no description")
+	}
+	return title, body, nil
+}
+
+// DescribePR has a short Claude run write the title and body of the PR
+// for issue num, titled title, from the diff of workdir's branch against
+// base, following prTemplate, in box's sandbox if box is non-nil. The
+// body is the description alone; the PR's body wraps it (see
+// Templates.PRBody).
+func DescribePR(ctx context.Context, box *Bwrap, workdir string, num int, title, base string, cfg RepoConfig) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, prDescribeTimeout)
+	defer cancel()
+	issueDir := filepath.Dir(workdir)
+	dir := filepath.Join(issueDir, prDescribeDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+	diff, err := gitRaw(ctx, workdir, nil, "diff", base+"...HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", "", errors.New("no changes to describe")
+	}
+	if err := os.WriteFile(filepath.Join(dir, prDiffFile), []byte(diff), 0o644); err != nil {
+		return "", "", err
+	}
+	log, _ := gitRaw(ctx, workdir, nil, "log", "--format=%h %s", base+"..HEAD")
+	var result string
+	if r, ok := ReadRunResult(issueDir); ok {
+		result = r.Markdown()
+	}
+	path := filepath.Join(dir, prDescriptionFile)
+	os.Remove(path)
+
+	agent, err := LookupAgent(cfg.Agent)
+	if err != nil {
+		return "", "", err
+	}
+	prompt := prDescriptionPrompt(num, title, base, log, result, prTemplate(workdir, cfg))
+	if _, err := agent.Run(ctx, dir, prompt, AgentOptions{Tools: prDescribeTools, Bwrap: box}); err != nil {
+		return "", "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("%s wrote no %s", agent.Name(), prDescriptionFile)
+	}
+	prTitle, body, err := parsePRDescription(string(data))
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", prDescriptionFile, err)
+	}
+	return prTitle, body, nil
+}
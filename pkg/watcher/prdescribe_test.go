@@ -0,0 +1,77 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePRDescription(t *testing.T) {
+	title, body, err := parsePRDescription("\ntitle:  Return early on empty input \n\n## Summary\nSkips parsing.\n")
+	if err != nil || title != "Return early on empty input" || body != "## Summary\nSkips parsing." {
+		t.Errorf("got %q, %q, %v", title, body, err)
+	}
+	for _, bad := range []string{"", "## Summary\nSkips parsing.", "title: \n\nBody", "title: Fix it\n\n"} {
+		if _, _, err := parsePRDescription(bad); err == nil {
+			t.Errorf("parsePRDescription(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestPRTemplate(t *testing.T) {
+	workdir := t.TempDir()
+	if got := prTemplate(workdir, RepoConfig{}); got != prTemplateFallback {
+		t.Errorf("default template = %q", got)
+	}
+	os.MkdirAll(filepath.Join(workdir, ".github"), 0o755)
+	os.WriteFile(filepath.Join(workdir, ".github", "pull_request_template.md"), []byte("## What\n\n## Why\n"), 0o644)
+	if got := prTemplate(workdir, RepoConfig{}); got != "## What\n\n## Why" {
+		t.Errorf("repo template = %q", got)
+	}
+	if got := prTemplate(workdir, RepoConfig{PRTemplate: "## Change"}); got != "## Change" {
+		t.Errorf("configured template = %q", got)
+	}
+}
+
+// fakeClaude puts a claude on PATH that runs script in its working
+// directory.
+func fakeClaude(t *testing.T, script string) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\ncat >/dev/null\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestLoadPRDraftByClaude(t *testing.T) {
+	dir := t.TempDir()
+	workdir := filepath.Join(dir, "repo")
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+	gitIn(t, workdir, "checkout", "-q", "-b", IssueBranch(3))
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+	gitIn(t, workdir, "commit", "-q", "-am", "Handle empty input")
+
+	fakeClaude(t, `grep -q '^+two' pr.diff && printf 'title: Return early on empty input\n\n## Summary\nSkips parsing.\n' > PR.md`)
+	d, err := LoadPRDraft(nil, workdir, 3, "Crash on empty input", "main", RepoConfig{ClaudePRs: true})
+	if err != nil {
+		t.Fatalf("LoadPRDraft: %v", err)
+	}
+	if d.Title != "Return early on empty input" || !strings.HasPrefix(d.Body, "Fixes #3\n\n## Summary\nSkips parsing.") || len(d.Warnings) > 0 {
+		t.Errorf("draft = %+v", d)
+	}
+
+	// Without a description the defaults stand.
+	fakeClaude(t, "exit 0")
+	d, err = LoadPRDraft(nil, workdir, 3, "Crash on empty input", "main", RepoConfig{ClaudePRs: true})
+	if err != nil {
+		t.Fatalf("LoadPRDraft: %v", err)
+	}
+	if d.Title != "Fix #3: Crash on empty input" || len(d.Warnings) != 1 || !strings.Contains(d.Warnings[0], "claude wrote no PR.md") {
+		t.Errorf("draft = %+v", d)
+	}
+}
@@ -0,0 +1,143 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// PreflightCheck is one check made before an issue's branch is pushed.
+type PreflightCheck struct {
+	Name    string
+	OK      bool
+	Skipped bool   // not run, because an earlier check failed
+	Detail  string // what was found, e.g. why it failed
+}
+
+// Preflight is the checks made before pushing, in order.
+type Preflight []PreflightCheck
+
+// OK reports whether every check passed.
+func (p Preflight) OK() bool {
+	for _, c := range p {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Err describes the checks that failed, or is nil if none did.
+func (p Preflight) Err() error {
+	var failed []string
+	for _, c := range p {
+		if !c.OK && !c.Skipped {
+			failed = append(failed, c.Name+": "+c.Detail)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("preflight failed: %s", strings.Join(failed, "; "))
+}
+
+// RunPreflight checks that workdir's branch is fit to push and open a PR
+// for, so a push doesn't fail halfway through: it is ahead of base, has
+// no uncommitted changes, touches none of cfg's protected paths, isn't on
+// origin yet and passes cfg's tests. The tests, being slow, run only if
+// everything else passed, and not at all if a verification of HEAD
+// already ran them.
+func RunPreflight(ctx context.Context, workdir, base string, cfg RepoConfig) Preflight {
+	var p Preflight
+	check := func(name string, ok bool, detail string) {
+		p = append(p, PreflightCheck{Name: name, OK: ok, Detail: detail})
+	}
+
+	ahead, err := gitCmd(ctx, workdir, nil, "rev-list", "--count", "origin/"+base+"..HEAD")
+	if err != nil {
+		check("ahead of "+base, false, err.Error())
+	} else {
+		n, _ := strconv.Atoi(ahead)
+		check("ahead of "+base, n > 0, fmt.Sprintf("%d commits", n))
+	}
+
+	status, err := gitCmd(ctx, workdir, nil, "status", "--porcelain")
+	switch {
+	case err != nil:
+		check("no uncommitted changes", false, err.Error())
+	case status != "":
+		check("no uncommitted changes", false, fmt.Sprintf("%d uncommitted files", len(strings.Split(status, "\n"))))
+	default:
+		check("no uncommitted changes", true, "")
+	}
+
+	files, err := gitCmd(ctx, workdir, nil, "diff", "--name-only", "origin/"+base+"...HEAD")
+	if err != nil {
+		check("no protected paths", false, err.Error())
+	} else if touched := protectedFiles(cfg.ProtectedPaths, files); len(touched) > 0 {
+		check("no protected paths", false, strings.Join(touched, ", "))
+	} else {
+		check("no protected paths", true, "")
+	}
+
+	branch, err := gitCmd(ctx, workdir, nil, "rev-parse", "--abbrev-ref", "HEAD")
+	if err == nil {
+		var remote string
+		remote, err = gitCmd(ctx, workdir, nil, "ls-remote", "--heads", "origin", "refs/heads/"+branch)
+		if err == nil && remote != "" {
+			err = fmt.Errorf("origin already has %s", branch)
+		}
+	}
+	if err != nil {
+		check("branch not on origin", false, err.Error())
+	} else {
+		check("branch not on origin", true, branch)
+	}
+
+	if !p.OK() {
+		return append(p, PreflightCheck{Name: "tests pass", Skipped: true})
+	}
+	ok, detail := preflightTests(ctx, workdir, cfg)
+	check("tests pass", ok, detail)
+	return p
+}
+
+// protectedFiles returns the files matched by patterns: a pattern
+// ending in "/" matches everything under that directory, any other is
+// matched against the whole path with path.Match.
+func protectedFiles(patterns []string, files string) []string {
+	var touched []string
+	for _, f := range strings.Split(files, "\n") {
+		if f == "" {
+			continue
+		}
+		for _, pat := range patterns {
+			if dir, ok := strings.CutSuffix(pat, "/"); ok && strings.HasPrefix(f, dir+"/") {
+				touched = append(touched, f)
+				break
+			}
+			if ok, _ := path.Match(pat, f); ok {
+				touched = append(touched, f)
+				break
+			}
+		}
+	}
+	return touched
+}
+
+// preflightTests runs cfg's test command in workdir, unless a
+// verification of HEAD already did.
+func preflightTests(ctx context.Context, workdir string, cfg RepoConfig) (ok bool, detail string) {
+	if c, ok := verifiedCheck(ctx, workdir, "test"); ok {
+		return c.Passed, "verified " + c.Command
+	}
+	cmd := shellCommand(ctx, workdir, cfg.Test())
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		return false, fmt.Sprintf("%s: %v: %s", cfg.Test(), err, lines[len(lines)-1])
+	}
+	return true, cfg.Test()
+}
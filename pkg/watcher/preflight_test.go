@@ -0,0 +1,57 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunPreflight(t *testing.T) {
+	work, _ := rebaseRepos(t)
+	gitIn(t, work, "checkout", "-q", "-b", IssueBranch(1))
+	writeAndCommit(t, work, "c.txt", "fix\n")
+
+	p := RunPreflight(context.Background(), work, "main", RepoConfig{TestCommand: "test -f c.txt"})
+	if !p.OK() || p.Err() != nil {
+		t.Fatalf("preflight = %+v", p)
+	}
+
+	// Dirty, touching a protected path and failing tests; the tests are
+	// skipped since the rest already failed.
+	os.MkdirAll(filepath.Join(work, ".github", "workflows"), 0o755)
+	writeAndCommit(t, work, ".github/workflows/ci.yml", "on: push\n")
+	os.WriteFile(filepath.Join(work, "c.txt"), []byte("more\n"), 0o644)
+	p = RunPreflight(context.Background(), work, "main", RepoConfig{TestCommand: "false", ProtectedPaths: []string{".github/workflows/"}})
+	var failed, skipped []string
+	for _, c := range p {
+		if c.Skipped {
+			skipped = append(skipped, c.Name)
+		} else if !c.OK {
+			failed = append(failed, c.Name)
+		}
+	}
+	if want := []string{"no uncommitted changes", "no protected paths"}; !slices.Equal(failed, want) || !slices.Equal(skipped, []string{"tests pass"}) {
+		t.Errorf("failed %q, skipped %q", failed, skipped)
+	}
+	if err := p.Err(); err == nil || !strings.Contains(err.Error(), ".github/workflows/ci.yml") {
+		t.Errorf("Err() = %v", err)
+	}
+
+	// A branch already pushed fails, as do tests once they run.
+	gitIn(t, work, "checkout", "-q", "c.txt")
+	gitIn(t, work, "push", "-q", "origin", "HEAD")
+	p = RunPreflight(context.Background(), work, "main", RepoConfig{TestCommand: "false"})
+	if err := p.Err(); err == nil || !strings.Contains(err.Error(), "origin already has") || strings.Contains(err.Error(), "tests pass") {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestProtectedFiles(t *testing.T) {
+	got := protectedFiles([]string{"go.sum", "deploy/", "*.pem"}, "go.sum\nsrc/go.sum\ndeploy/prod.yaml\nkey.pem\nmain.go")
+	if want := []string{"go.sum", "deploy/prod.yaml", "key.pem"}; !slices.Equal(got, want) {
+		t.Errorf("protectedFiles = %q, want %q", got, want)
+	}
+}
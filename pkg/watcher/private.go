@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DefaultUmask is the umask lurker runs with unless told otherwise: what
+// it and the commands it runs create — worktrees, logs, transcripts, the
+// issues' text — is its user's alone, as private repos' issues and code
+// are no one else's business.
+const DefaultUmask = 0o077
+
+// ParseUmask reads an octal umask such as "077" or "0022".
+func ParseUmask(s string) (int, error) {
+	mask, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil || mask > 0o777 {
+		return 0, fmt.Errorf("invalid umask %q (want octal, e.g. 077)", s)
+	}
+	return int(mask), nil
+}
+
+// SetUmask sets the umask of lurker and the commands it starts, returning
+// the one it had.
+func SetUmask(mask int) int {
+	return syscall.Umask(mask)
+}
+
+// Restrict takes from l's directories the permissions mask denies, so
+// what was made there under a laxer umask isn't readable through them
+// either.
+func (l Layout) Restrict(mask int) error {
+	l = l.resolved()
+	for _, dir := range []string{l.Data, l.State, l.Cache, l.Config} {
+		info, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if perm := info.Mode().Perm(); perm&os.FileMode(mask) != 0 {
+			if err := os.Chmod(dir, perm&^os.FileMode(mask)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RequireMount returns an error unless mount is a mount point, such as an
+// unlocked encrypted volume, and l keeps everything in it. It keeps
+// lurker from writing into the empty directory a locked volume leaves.
+func (l Layout) RequireMount(mount string) error {
+	mount, err := filepath.Abs(mount)
+	if err != nil {
+		return err
+	}
+	mounted, err := isMountPoint(mount)
+	if err != nil {
+		return err
+	}
+	if !mounted {
+		return fmt.Errorf("%s is not mounted", mount)
+	}
+	l = l.resolved()
+	for _, dir := range []string{l.Data, l.State, l.Cache, l.Config} {
+		if rel, err := filepath.Rel(mount, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside %s", dir, mount)
+		}
+	}
+	return nil
+}
+
+// isMountPoint reports whether dir is the root of a filesystem: on
+// another device than its parent, or "/".
+func isMountPoint(dir string) (bool, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false, err
+	}
+	parent, err := os.Stat(filepath.Dir(dir))
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(info, parent) {
+		return true, nil
+	}
+	return info.Sys().(*syscall.Stat_t).Dev != parent.Sys().(*syscall.Stat_t).Dev, nil
+}
@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUmask(t *testing.T) {
+	for s, want := range map[string]int{"077": 0o077, "0022": 0o022, "0": 0} {
+		if got, err := ParseUmask(s); err != nil || got != want {
+			t.Errorf("ParseUmask(%q) = %o, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "99", "1777", "-1"} {
+		if _, err := ParseUmask(s); err == nil {
+			t.Errorf("ParseUmask(%q) took it", s)
+		}
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	root := t.TempDir()
+	l := Layout{Data: filepath.Join(root, "data"), State: filepath.Join(root, "state")}
+	os.Mkdir(l.Data, 0o755)
+	os.Mkdir(l.State, 0o750)
+	if err := l.Restrict(0o077); err != nil {
+		t.Fatalf("Restrict: %v", err)
+	}
+	for _, dir := range []string{l.Data, l.State} {
+		if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+			t.Errorf("%s: %v, %v", dir, info.Mode(), err)
+		}
+	}
+}
+
+func TestRequireMount(t *testing.T) {
+	if err := (Layout{Data: t.TempDir()}).RequireMount(t.TempDir()); err == nil || !strings.Contains(err.Error(), "not mounted") {
+		t.Errorf("RequireMount of a plain directory = %v", err)
+	}
+	if err := (Layout{Data: "/data"}).RequireMount("/"); err != nil {
+		t.Errorf("RequireMount(/) = %v", err)
+	}
+	if _, err := os.Stat("/proc/self"); err == nil {
+		if err := (Layout{Data: "/data"}).RequireMount("/proc"); err == nil || !strings.Contains(err.Error(), "outside") {
+			t.Errorf("RequireMount with the data outside = %v", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxProgressItems bounds how many commits and files a progress comment
+// lists.
+const maxProgressItems = 30
+
+// reportProgress comments on issue num that work on it started, and
+// returns a channel to send the run's events to instead of eventCh: they
+// are passed on, and when the run ends lurker comments again with what
+// it produced, or why it failed. Calling done closes the channel and
+// waits for that comment. The comments are tmpl's, if it has them.
+func (w *Watcher) reportProgress(ctx context.Context, eventCh chan<- Event, num int, workdir, base string, started time.Time, tmpl Templates) (ch chan<- Event, done func()) {
+	// post renders the comment from template text, or builtin without
+	// one, and posts it.
+	post := func(what, text string, d CommentData, builtin func(CommentData) string) {
+		body, err := render(what+"_comment", text, d, func() string { return builtin(d) })
+		if err == nil {
+			err = w.ghClient.CreateComment(ctx, w.cfg.Repo, num, body)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				w.emit(eventCh, EventProgress, num, fmt.Sprintf("Posting %s comment failed: %v", what, err))
+			}
+			return
+		}
+		w.emit(eventCh, EventProgress, num, fmt.Sprintf("Posted %s comment", what))
+	}
+	post("start", tmpl.StartComment, CommentData{Repo: w.cfg.Repo, Number: num, Branch: IssueBranch(num)}, func(d CommentData) string {
+		return fmt.Sprintf("🤖 lurker started working on this issue on branch `%s`.", d.Branch)
+	})
+
+	events := make(chan Event)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		reported := false
+		cost := 0.0 // of the run's latest Claude session, once it ends
+		for ev := range events {
+			eventCh <- ev
+			if reported || ev.IssueNum != num {
+				continue
+			}
+			switch ev.Kind {
+			case EventCost:
+				cost, _ = strconv.ParseFloat(ev.Text, 64)
+			case EventReady:
+				reported = true
+				post("ready", tmpl.ReadyComment, w.readyData(ctx, num, workdir, base, time.Since(started), cost), readyComment)
+			case EventError, EventBuildFailed, EventTestsFailed:
+				reported = true
+				d := CommentData{Repo: w.cfg.Repo, Number: num, Branch: IssueBranch(num), Error: ev.Text, Suggestion: ClassifyFailure(ev.Text).Suggestion}
+				post("failure", tmpl.FailureComment, d, failureComment)
+			}
+		}
+	}()
+	return events, func() {
+		close(events)
+		<-finished
+	}
+}
+
+// readyData gathers what the ready comment reports about the finished
+// branch: its commits, the files it touches and what the run cost, which
+// is cost if its EventCost said and its issue.json's otherwise.
+func (w *Watcher) readyData(ctx context.Context, num int, workdir, base string, took time.Duration, cost float64) CommentData {
+	d := CommentData{Repo: w.cfg.Repo, Number: num, Branch: IssueBranch(num), Duration: took.Round(time.Second), CostUSD: cost}
+	if out, err := gitCmd(ctx, workdir, nil, "log", "--reverse", "--format=%h %s", "origin/"+base+"..HEAD"); err == nil && out != "" {
+		d.Commits = strings.Split(out, "\n")
+	}
+	// Against the working tree, so patch mode's uncommitted changes count.
+	if out, err := gitCmd(ctx, workdir, nil, "diff", "--name-only", "origin/"+base); err == nil && out != "" {
+		d.Files = strings.Split(out, "\n")
+	}
+	if meta, err := ReadIssueMeta(w.cfg.BaseDir, w.cfg.Repo, num); err == nil && d.CostUSD <= 0 {
+		d.CostUSD = meta.CostUSD
+	}
+	return d
+}
+
+// readyComment is lurker's own ready comment.
+func readyComment(d CommentData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### ✅ lurker: `%s` is ready for review\n\n", d.Branch)
+	fmt.Fprintf(&b, "%d commits, %d files changed, in %s", len(d.Commits), len(d.Files), d.Duration)
+	if d.CostUSD > 0 {
+		fmt.Fprintf(&b, ", $%.2f", d.CostUSD)
+	}
+	b.WriteString(".\n")
+	list := func(title string, items []string, item func(string) string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n<details><summary>%s (%d)</summary>\n\n", title, len(items))
+		for i, it := range items {
+			if i == maxProgressItems {
+				fmt.Fprintf(&b, "- … %d more\n", len(items)-i)
+				break
+			}
+			b.WriteString("- " + item(it) + "\n")
+		}
+		b.WriteString("\n</details>\n")
+	}
+	list("Commits", d.Commits, func(c string) string {
+		sha, subject, _ := strings.Cut(c, " ")
+		return "`" + sha + "` " + truncateRunes(subject, 200)
+	})
+	list("Files", d.Files, func(f string) string { return "`" + f + "`" })
+	return b.String()
+}
+
+// failureComment is lurker's own failure comment, with ClassifyFailure's
+// suggestion if it has one.
+func failureComment(d CommentData) string {
+	var b strings.Builder
+	b.WriteString("### ❌ lurker: the run failed\n\n")
+	fmt.Fprintf(&b, "```\n%s\n```\n", truncateRunes(d.Error, maxSummaryText))
+	if d.Suggestion != "" {
+		fmt.Fprintf(&b, "\n%s\n", d.Suggestion)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// commentLog records the comments posted.
+type commentLog struct {
+	Forge
+	bodies []string
+}
+
+func (f *commentLog) CreateComment(ctx context.Context, repo string, number int, body string) error {
+	f.bodies = append(f.bodies, body)
+	return nil
+}
+
+func TestReportProgress_Ready(t *testing.T) {
+	workdir := t.TempDir()
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+	gitIn(t, workdir, "update-ref", "refs/remotes/origin/main", "HEAD")
+	os.WriteFile(filepath.Join(workdir, "b.txt"), []byte("new\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "Add b")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+
+	forge := &commentLog{}
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: t.TempDir()}, ghClient: forge}
+	out := make(chan Event, 10)
+	ch, done := w.reportProgress(context.Background(), out, 7, workdir, "main", time.Now(), Templates{})
+	ch <- Event{Kind: EventCost, IssueNum: 7, Text: "0.4213"}
+	ch <- Event{Kind: EventClaudeDone, IssueNum: 7, Text: "Claude finished successfully"}
+	ch <- Event{Kind: EventReady, IssueNum: 7, Text: workdir}
+	ch <- Event{Kind: EventError, IssueNum: 7, Text: "too late to matter"}
+	done()
+
+	if len(forge.bodies) != 2 {
+		t.Fatalf("posted %d comments, want start and summary: %q", len(forge.bodies), forge.bodies)
+	}
+	if !strings.Contains(forge.bodies[0], IssueBranch(7)) {
+		t.Errorf("start comment = %q", forge.bodies[0])
+	}
+	summary := forge.bodies[1]
+	for _, want := range []string{"1 commits, 2 files changed", ", $0.42.", "Add b", "`a.txt`", "`b.txt`"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary lacks %q:\n%s", want, summary)
+		}
+	}
+	var kinds []EventKind
+	for len(out) > 0 {
+		kinds = append(kinds, (<-out).Kind)
+	}
+	if want := []EventKind{EventProgress, EventCost, EventClaudeDone, EventReady, EventProgress, EventError}; !slices.Equal(kinds, want) {
+		t.Errorf("events = %v, want %v", kinds, want)
+	}
+}
+
+func TestReportProgress_Failure(t *testing.T) {
+	forge := &commentLog{}
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: t.TempDir()}, ghClient: forge}
+	out := make(chan Event, 10)
+	ch, done := w.reportProgress(context.Background(), out, 7, t.TempDir(), "main", time.Now(), Templates{})
+	ch <- Event{Kind: EventError, IssueNum: 7, Text: "Claude exited with code 1"}
+	done()
+
+	if len(forge.bodies) != 2 || !strings.Contains(forge.bodies[1], "Claude exited with code 1") {
+		t.Errorf("comments = %q", forge.bodies)
+	}
+}
+
+func TestReportProgress_Templates(t *testing.T) {
+	forge := &commentLog{}
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: t.TempDir()}, ghClient: forge}
+	out := make(chan Event, 10)
+	tmpl := Templates{
+		StartComment:   "Arbeit an {{.Repo}}#{{.Number}} begonnen ({{.Branch}}).",
+		FailureComment: "Fehlgeschlagen: {{.Error}}",
+	}
+	ch, done := w.reportProgress(context.Background(), out, 7, t.TempDir(), "main", time.Now(), tmpl)
+	ch <- Event{Kind: EventError, IssueNum: 7, Text: "Claude exited with code 1"}
+	done()
+
+	want := []string{"Arbeit an o/r#7 begonnen (" + IssueBranch(7) + ").", "Fehlgeschlagen: Claude exited with code 1"}
+	if !slices.Equal(forge.bodies, want) {
+		t.Errorf("comments = %q, want %q", forge.bodies, want)
+	}
+}
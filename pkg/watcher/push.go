@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PushRejectedError is returned when pushing an issue's branch is
+// rejected as non-fast-forward: the branch was rewritten (squashed,
+// rebased, amended by a continued run) since it was pushed, or someone
+// else pushed to it.
+type PushRejectedError struct {
+	Remote string
+	Branch string
+	SHA    string // the branch's commit on Remote when the push was rejected
+	Err    error
+}
+
+func (e *PushRejectedError) Error() string {
+	return fmt.Sprintf("%s on %s has commits this branch doesn't (was it rewritten since it was pushed?): %v", e.Branch, e.Remote, e.Err)
+}
+
+func (e *PushRejectedError) Unwrap() error { return e.Err }
+
+// rejectedPush returns err, from pushing workdir's branch to remote, as a
+// *PushRejectedError if the push was rejected as non-fast-forward.
+func rejectedPush(ctx context.Context, workdir, remote string, err error) error {
+	if !strings.Contains(err.Error(), " ! [rejected] ") {
+		return err
+	}
+	branch, berr := gitCmd(ctx, workdir, nil, "symbolic-ref", "--short", "HEAD")
+	if berr != nil {
+		return err
+	}
+	out, lerr := gitCmd(ctx, workdir, nil, "ls-remote", remote, "refs/heads/"+branch)
+	sha, _, _ := strings.Cut(out, "\t")
+	if lerr != nil || sha == "" {
+		return err
+	}
+	return &PushRejectedError{Remote: remote, Branch: branch, SHA: sha, Err: err}
+}
+
+// ForcePushBranch pushes workdir's branch over sha, the commit a rejected
+// push found on remote. If the branch has moved on from sha since, the
+// push fails rather than drop commits nobody has seen.
+func ForcePushBranch(ctx context.Context, workdir, remote, sha string) error {
+	branch, err := gitCmd(ctx, workdir, nil, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	ref := "refs/heads/" + branch
+	if _, err := gitCmd(ctx, workdir, nil, "push", "-u", "--force-with-lease="+ref+":"+sha, remote, "HEAD:"+ref); err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
+
+// ForcePush pushes repo#num's branch over the commit that rejected, a
+// refused push of it, found there. The repo's config must have
+// force_push.
+func (m *Manager) ForcePush(repo string, num int, rejected *PushRejectedError) error {
+	workdir := existingDir(IssueWorkdir(m.baseDir, repo, num))
+	if workdir == "" {
+		return errors.New("no worktree yet")
+	}
+	if !m.RepoConfig(workdir).ForcePush {
+		return errors.New("force_push is not set in the repo's config")
+	}
+	return ForcePushBranch(context.Background(), workdir, rejected.Remote, rejected.SHA)
+}
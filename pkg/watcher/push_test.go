@@ -0,0 +1,39 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPushBranch_RejectedThenForced(t *testing.T) {
+	work, _ := rebaseRepos(t)
+	ctx := context.Background()
+	pushed := strings.TrimSpace(gitIn(t, work, "rev-parse", "HEAD"))
+
+	// A continued run amends the pushed commit.
+	gitIn(t, work, "commit", "-q", "--amend", "-m", "amended")
+	err := pushBranch(ctx, work, "origin")
+	var rejected *PushRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("pushBranch = %v, want a *PushRejectedError", err)
+	}
+	if rejected.Branch != "work" || rejected.Remote != "origin" || rejected.SHA != pushed {
+		t.Errorf("rejected = %+v, want work on origin at %s", rejected, pushed)
+	}
+
+	if err := ForcePushBranch(ctx, work, rejected.Remote, rejected.SHA); err != nil {
+		t.Fatalf("ForcePushBranch: %v", err)
+	}
+	head := gitIn(t, work, "rev-parse", "HEAD")
+	if remote := gitIn(t, work, "ls-remote", "origin", "refs/heads/work"); !strings.HasPrefix(remote, strings.TrimSpace(head)) {
+		t.Errorf("origin has %q, want %s", remote, head)
+	}
+
+	// The lease refuses to overwrite a commit that wasn't the one seen.
+	gitIn(t, work, "commit", "-q", "--amend", "-m", "again")
+	if err := ForcePushBranch(ctx, work, "origin", pushed); err == nil {
+		t.Error("expected the stale lease to fail")
+	}
+}
@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// QuestionsFile is where Claude writes the questions it needs answered
+// before it can go on. Its presence in the worktree after a run puts the
+// issue in StatusNeedsInput.
+const QuestionsFile = "QUESTIONS.md"
+
+// questionsPrompt is appended to every prompt so Claude knows how to ask.
+const questionsPrompt = `
+
+## Blocked?
+If you cannot continue without a decision from a maintainer (an ambiguous
+requirement, a choice between designs, missing access), write your questions
+to ` + QuestionsFile + ` at the repository root, commit any work so far, and
+stop. You will be resumed with the answers.`
+
+// ReadQuestions returns the questions Claude left in workdir, if any.
+func ReadQuestions(workdir string) (string, bool) {
+	if workdir == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(workdir, QuestionsFile))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// answerPrompt resumes a run that stopped with questions.
+func answerPrompt(questions, answer string) string {
+	return fmt.Sprintf(`You asked:
+
+%s
+
+The maintainer answered:
+
+%s
+
+Delete %s (with git rm if you committed it), then continue with the issue
+using this answer.`, questions, answer, QuestionsFile)
+}
+
+// AnswerIssue resumes the Claude session of an issue waiting in
+// StatusNeedsInput, passing it answer.
+func (m *Manager) AnswerIssue(repo string, num int, answer string) {
+	questions, ok := ReadQuestions(IssueWorkdir(m.baseDir, repo, num))
+	if !ok {
+		m.announce(Event{Kind: EventWarning, Repo: repo, IssueNum: num, Text: "No " + QuestionsFile + " to answer", Timestamp: time.Now()})
+		return
+	}
+	m.mu.Lock()
+	key := IssueKey(repo, num)
+	issue, ok := m.knownIssues[key]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	if cancel, ok := m.issueCtxs[key]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.issueCtxs[key] = cancel
+	done := m.runStarted(key)
+	w := m.repoWatchers[repo]
+	var resumed Event
+	var changed bool
+	if w != nil {
+		resumed, changed = m.setStatus(repo, num, StatusReacted)
+	}
+	m.mu.Unlock()
+
+	if w == nil {
+		cancel()
+		done()
+		return
+	}
+	if changed {
+		m.announce(resumed)
+	}
+	go func() {
+		defer done()
+		w.answerIssue(ctx, m.eventCh, issue, questions, answer)
+	}()
+}
+
+// answerIssue continues the most recent Claude session in the issue's
+// worktree with answer to questions.
+func (w *Watcher) answerIssue(ctx context.Context, eventCh chan<- Event, issue Issue, questions, answer string) {
+	num := issue.Number
+	run := w.runner(ctx, IssueKey(w.cfg.Repo, num))
+	started := time.Now()
+
+	issueDir := IssueDir(w.cfg.BaseDir, w.cfg.Repo, num)
+	workdir := IssueWorkdir(w.cfg.BaseDir, w.cfg.Repo, num)
+
+	repoCfg := w.manager.RepoConfig(workdir)
+	tools := scopedTools(repoCfg.ClaudeTools(), repoCfg.Scope(issue))
+
+	w.backupBeforeRun(ctx, eventCh, num, workdir, w.manager.BaseBranch(w.cfg.Repo, workdir))
+
+	w.emit(eventCh, EventClaudeStart, num, "Resuming Claude Code with your answer...")
+	promptFile := filepath.Join(issueDir, ".lurker-answer.txt")
+	if err := os.WriteFile(promptFile, []byte(answerPrompt(questions, answer)), 0o644); err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Write answer: %v", err))
+		return
+	}
+	if w.runClaude(ctx, eventCh, run, num, workdir, promptFile, tools, true) && w.runStages(ctx, eventCh, num, workdir, repoCfg) {
+		w.finishRun(eventCh, num, workdir, started)
+	}
+}
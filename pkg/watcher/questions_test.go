@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadQuestions(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := ReadQuestions(dir); ok {
+		t.Error("expected no questions in an empty worktree")
+	}
+	if _, ok := ReadQuestions(""); ok {
+		t.Error("expected no questions without a worktree")
+	}
+
+	os.WriteFile(filepath.Join(dir, QuestionsFile), []byte("\n1. Keep the v1 endpoint?\n"), 0o644)
+	q, ok := ReadQuestions(dir)
+	if !ok || q != "1. Keep the v1 endpoint?" {
+		t.Errorf("ReadQuestions = %q, %v", q, ok)
+	}
+}
+
+func TestDeriveIssueStatus_NeedsInput(t *testing.T) {
+	base := t.TempDir()
+	workdir := IssueWorkdir(base, "owner/repo", 7)
+	os.MkdirAll(workdir, 0o755)
+	os.WriteFile(filepath.Join(workdir, QuestionsFile), []byte("Which DB?"), 0o644)
+
+	st, dir := DeriveIssueStatus(base, "owner/repo", 7, "main")
+	if st != StatusNeedsInput || dir != workdir {
+		t.Errorf("DeriveIssueStatus = %v, %q; want needs-input, %q", st, dir, workdir)
+	}
+}
+
+func TestAnswerPrompt(t *testing.T) {
+	p := answerPrompt("Which DB?", "Postgres")
+	for _, want := range []string{"Which DB?", "Postgres", QuestionsFile} {
+		if !strings.Contains(p, want) {
+			t.Errorf("answerPrompt missing %q:\n%s", want, p)
+		}
+	}
+}
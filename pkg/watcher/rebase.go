@@ -0,0 +1,252 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// Update methods, for RepoConfig.UpdateMethod.
+const (
+	UpdateRebase = "rebase" // rebase the branch onto its base locally
+	UpdateMerge  = "merge"  // have the forge merge the base into the PR's branch
+)
+
+var (
+	// updatePollInterval is how often the PR's branch is fetched while
+	// waiting for the forge to merge the base into it.
+	updatePollInterval = 2 * time.Second
+	// updateWait bounds that wait.
+	updateWait = time.Minute
+)
+
+// branchUpdater is implemented by forges that can merge the base branch
+// into a PR's branch themselves.
+type branchUpdater interface {
+	UpdateBranch(ctx context.Context, repo string, number int) error
+}
+
+// BranchUpdate is what bringing an issue's branch up to date with its
+// base did.
+type BranchUpdate struct {
+	Onto      string   // the base, e.g. "origin/main"
+	Behind    int      // commits the branch was behind it
+	Merged    bool     // the forge merged the base in rather than lurker rebasing
+	Conflicts []string // files that conflict; the branch is left as it was
+	Pushed    bool     // the updated branch was pushed
+	Unpushed  bool     // it was pushed before, but allow_push kept it from being pushed again
+}
+
+// String describes u for the log.
+func (u BranchUpdate) String() string {
+	switch {
+	case len(u.Conflicts) > 0:
+		return fmt.Sprintf("Conflicts with %s in %s", u.Onto, strings.Join(u.Conflicts, ", "))
+	case u.Behind == 0 && !u.Pushed:
+		return "Already up to date with " + u.Onto
+	}
+	s := fmt.Sprintf("Rebased onto %s", u.Onto)
+	if u.Merged {
+		s = fmt.Sprintf("Merged %s in", u.Onto)
+	}
+	if u.Behind > 0 {
+		s += fmt.Sprintf(" (%s behind)", plural(u.Behind, "commit"))
+	}
+	if u.Pushed {
+		s += " and pushed"
+	}
+	if u.Unpushed {
+		s += ", not pushed (allow_push off)"
+	}
+	return s
+}
+
+// RebaseBranch fetches base and rebases workdir's branch onto it,
+// stashing uncommitted changes meanwhile. If the rebase conflicts it is
+// aborted, leaving the branch as it was, and the conflicting files are
+// returned in the update. A branch that was pushed is force-pushed once
+// rebased if push is set.
+func RebaseBranch(ctx context.Context, workdir, base string, push bool) (BranchUpdate, error) {
+	onto := "origin/" + base
+	u := BranchUpdate{Onto: onto}
+	if _, err := gitCmd(ctx, workdir, nil, "fetch", "origin", "+refs/heads/"+base+":refs/remotes/"+onto); err != nil {
+		return u, err
+	}
+	behind, err := gitCmd(ctx, workdir, nil, "rev-list", "--count", "HEAD.."+onto)
+	if err != nil {
+		return u, err
+	}
+	u.Behind, _ = strconv.Atoi(behind)
+	if u.Behind > 0 {
+		if _, err := gitCmd(ctx, workdir, nil, "rebase", "--autostash", onto); err != nil {
+			conflicts, _ := gitCmd(ctx, workdir, nil, "diff", "--name-only", "--diff-filter=U")
+			gitCmd(ctx, workdir, nil, "rebase", "--abort")
+			if conflicts == "" {
+				return u, err
+			}
+			u.Conflicts = strings.Split(conflicts, "\n")
+			return u, nil
+		}
+	}
+	u.Pushed, u.Unpushed, err = pushRebased(ctx, workdir, push)
+	return u, err
+}
+
+// pushedTo returns where workdir's branch was pushed and the commit it
+// has there, or "" for a branch that was never pushed.
+func pushedTo(ctx context.Context, workdir string) (remote, ref, sha string, err error) {
+	branch, err := gitCmd(ctx, workdir, nil, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", "", "", err
+	}
+	remote, _ = gitCmd(ctx, workdir, nil, "config", "branch."+branch+".remote")
+	ref, _ = gitCmd(ctx, workdir, nil, "config", "branch."+branch+".merge")
+	if remote == "" || ref == "" {
+		return "", "", "", nil
+	}
+	out, err := gitCmd(ctx, workdir, nil, "ls-remote", remote, ref)
+	if err != nil {
+		return "", "", "", err
+	}
+	sha, _, _ = strings.Cut(out, "\t")
+	return remote, ref, sha, nil
+}
+
+// pushRebased force-pushes workdir's branch where it was pushed before, if
+// it was and differs from there, and reports whether it did. If push is
+// not set it doesn't, and reports unpushed instead.
+func pushRebased(ctx context.Context, workdir string, push bool) (pushed, unpushed bool, err error) {
+	remote, ref, sha, err := pushedTo(ctx, workdir)
+	if err != nil || sha == "" {
+		return false, false, err
+	}
+	if head, _ := gitCmd(ctx, workdir, nil, "rev-parse", "HEAD"); head == sha {
+		return false, false, nil
+	}
+	if !push {
+		return false, true, nil
+	}
+	if _, err := gitCmd(ctx, workdir, nil, "push", "--force-with-lease="+ref+":"+sha, remote, "HEAD:"+ref); err != nil {
+		return false, false, fmt.Errorf("push: %w", err)
+	}
+	return true, false, nil
+}
+
+// allowPush reports whether forge's permissions, which --observer clears,
+// let lurker push.
+func allowPush(forge Forge) bool {
+	return forge != nil && forge.Permissions().AllowPush
+}
+
+// mergeBase has the forge merge base into PR prNum's branch, then fast-
+// forwards workdir to the result.
+func mergeBase(ctx context.Context, forge branchUpdater, repo string, prNum int, workdir, base string) (BranchUpdate, error) {
+	u := BranchUpdate{Onto: "origin/" + base, Merged: true}
+	if _, err := gitCmd(ctx, workdir, nil, "fetch", "origin", "+refs/heads/"+base+":refs/remotes/"+u.Onto); err != nil {
+		return u, err
+	}
+	behind, err := gitCmd(ctx, workdir, nil, "rev-list", "--count", "HEAD.."+u.Onto)
+	if err != nil {
+		return u, err
+	}
+	if u.Behind, _ = strconv.Atoi(behind); u.Behind == 0 {
+		return u, nil
+	}
+	remote, ref, before, err := pushedTo(ctx, workdir)
+	if err != nil {
+		return u, err
+	}
+	if before == "" {
+		return u, errors.New("the branch was never pushed")
+	}
+	if err := forge.UpdateBranch(ctx, repo, prNum); err != nil {
+		if errors.Is(err, github.ErrUpdateConflict) {
+			// The forge doesn't say which files; a trial merge does.
+			u.Conflicts = trialMergeConflicts(ctx, workdir, u.Onto)
+			return u, nil
+		}
+		return u, err
+	}
+	// The forge merges in the background.
+	deadline := time.Now().Add(updateWait)
+	for {
+		if _, _, after, err := pushedTo(ctx, workdir); err != nil {
+			return u, err
+		} else if after != before {
+			break
+		}
+		if time.Now().After(deadline) {
+			return u, fmt.Errorf("%s wasn't merged in within %s", u.Onto, updateWait)
+		}
+		select {
+		case <-ctx.Done():
+			return u, ctx.Err()
+		case <-time.After(updatePollInterval):
+		}
+	}
+	if _, err := gitCmd(ctx, workdir, nil, "fetch", remote, ref); err != nil {
+		return u, err
+	}
+	if _, err := gitCmd(ctx, workdir, nil, "merge", "--ff-only", "--autostash", "FETCH_HEAD"); err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// trialMergeConflicts returns the files merging onto into workdir's HEAD
+// would conflict in, leaving the worktree as it was.
+func trialMergeConflicts(ctx context.Context, workdir, onto string) []string {
+	// On conflicts merge-tree exits 1 and prints the tree, then the files.
+	cmd := exec.CommandContext(ctx, "git", "-C", workdir, "merge-tree", "--write-tree", "--name-only", "--no-messages", "HEAD", onto)
+	out, _ := cmd.Output()
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return []string{"(files unknown)"}
+	}
+	return lines[1:]
+}
+
+// rebase is the rebase stage: it rebases a finished run's branch onto the
+// latest base branch, so it is verified and reviewed as it would merge.
+// It reports false, having emitted EventConflict, if the rebase
+// conflicted; other failures are only logged.
+func (w *Watcher) rebase(ctx context.Context, eventCh chan<- Event, num int, workdir string) bool {
+	u, err := RebaseBranch(ctx, workdir, w.manager.BaseBranch(w.cfg.Repo, workdir), allowPush(w.ghClient))
+	switch {
+	case err != nil:
+		w.emit(eventCh, EventClaudeLog, num, fmt.Sprintf("⚠ Rebasing onto %s: %v", u.Onto, err))
+	case len(u.Conflicts) > 0:
+		w.emit(eventCh, EventConflict, num, u.String())
+		return false
+	case u.Behind > 0:
+		w.emit(eventCh, EventRebased, num, u.String())
+	}
+	return true
+}
+
+// UpdateBranch brings repo#num's branch up to date with its base branch:
+// by rebasing it locally or, with update_method "merge" and an open PR on
+// a forge that supports it, by having the forge merge the base in. Without
+// allow_push it is only rebased locally. The issue must not be running.
+func (m *Manager) UpdateBranch(repo string, num int) (BranchUpdate, error) {
+	workdir := existingDir(IssueWorkdir(m.baseDir, repo, num))
+	if workdir == "" {
+		return BranchUpdate{}, errors.New("no worktree yet")
+	}
+	ctx := context.Background()
+	base := m.BaseBranch(repo, workdir)
+	push := allowPush(m.ghClient)
+	if m.RepoConfig(workdir).UpdateMethod == UpdateMerge && push {
+		meta, _ := ReadIssueMeta(m.baseDir, repo, num)
+		if forge, ok := m.ghClient.(branchUpdater); ok && meta.PRNumber > 0 {
+			return mergeBase(ctx, forge, repo, meta.PRNumber, workdir, base)
+		}
+	}
+	return RebaseBranch(ctx, workdir, base, push)
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// rebaseRepos returns a worktree on branch "work", pushed to origin, and a
+// second clone on main to advance origin with.
+func rebaseRepos(t *testing.T) (work, other string) {
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@example.com")
+	dir := t.TempDir()
+	origin := filepath.Join(dir, "origin.git")
+	work = filepath.Join(dir, "work")
+	other = filepath.Join(dir, "other")
+	gitIn(t, dir, "init", "-q", "--bare", "-b", "main", origin)
+	gitIn(t, dir, "clone", "-q", origin, other)
+	gitIn(t, other, "checkout", "-q", "-b", "main")
+	writeAndCommit(t, other, "a.txt", "one\n")
+	gitIn(t, other, "push", "-q", "origin", "main")
+
+	gitIn(t, dir, "clone", "-q", origin, work)
+	gitIn(t, work, "checkout", "-q", "-b", "work")
+	writeAndCommit(t, work, "b.txt", "work\n")
+	gitIn(t, work, "push", "-q", "-u", "origin", "work")
+	return work, other
+}
+
+func writeAndCommit(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "add", file)
+	gitIn(t, dir, "commit", "-q", "-m", "edit "+file)
+}
+
+func TestRebaseBranch(t *testing.T) {
+	work, other := rebaseRepos(t)
+	ctx := context.Background()
+
+	u, err := RebaseBranch(ctx, work, "main", true)
+	if err != nil {
+		t.Fatalf("RebaseBranch: %v", err)
+	}
+	if u.Behind != 0 || u.Pushed || u.String() != "Already up to date with origin/main" {
+		t.Errorf("up to date: %+v %q", u, u.String())
+	}
+
+	writeAndCommit(t, other, "c.txt", "other\n")
+	gitIn(t, other, "push", "-q", "origin", "main")
+	if err := os.WriteFile(filepath.Join(work, "b.txt"), []byte("uncommitted\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	u, err = RebaseBranch(ctx, work, "main", true)
+	if err != nil {
+		t.Fatalf("RebaseBranch: %v", err)
+	}
+	if want := "Rebased onto origin/main (1 commit behind) and pushed"; u.String() != want {
+		t.Errorf("String = %q, want %q", u.String(), want)
+	}
+	if _, err := os.Stat(filepath.Join(work, "c.txt")); err != nil {
+		t.Errorf("base's commit missing: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(work, "b.txt")); string(data) != "uncommitted\n" {
+		t.Errorf("uncommitted change lost: %q", data)
+	}
+	head := strings.TrimSpace(gitIn(t, work, "rev-parse", "HEAD"))
+	if pushed, _, _ := strings.Cut(gitIn(t, work, "ls-remote", "origin", "refs/heads/work"), "\t"); pushed != head {
+		t.Errorf("pushed %s, HEAD is %s", pushed, head)
+	}
+}
+
+func TestRebaseBranchConflict(t *testing.T) {
+	work, other := rebaseRepos(t)
+	writeAndCommit(t, other, "a.txt", "theirs\n")
+	gitIn(t, other, "push", "-q", "origin", "main")
+	writeAndCommit(t, work, "a.txt", "ours\n")
+	before := gitIn(t, work, "rev-parse", "HEAD")
+
+	u, err := RebaseBranch(context.Background(), work, "main", true)
+	if err != nil {
+		t.Fatalf("RebaseBranch: %v", err)
+	}
+	if want := "Conflicts with origin/main in a.txt"; u.String() != want {
+		t.Errorf("String = %q, want %q", u.String(), want)
+	}
+	if after := gitIn(t, work, "rev-parse", "HEAD"); after != before {
+		t.Errorf("HEAD moved from %s to %s", before, after)
+	}
+	if status := gitIn(t, work, "status", "--porcelain"); status != "" {
+		t.Errorf("worktree left dirty:\n%s", status)
+	}
+}
+
+// permsForge is a forge that only has permissions.
+type permsForge struct {
+	Forge
+	perms github.Permissions
+}
+
+func (f permsForge) Permissions() github.Permissions { return f.perms }
+
+func TestRebaseBranch_NoPush(t *testing.T) {
+	noPush := github.AllowAll()
+	noPush.AllowPush = false
+	for name, forge := range map[string]Forge{"allow_push off": permsForge{perms: noPush}, "observer": permsForge{}, "no forge": nil} {
+		if allowPush(forge) {
+			t.Errorf("%s: allowPush = true", name)
+		}
+	}
+	if !allowPush(permsForge{perms: github.AllowAll()}) {
+		t.Error("allowPush = false with every permission")
+	}
+
+	work, other := rebaseRepos(t)
+	pushed := gitIn(t, work, "rev-parse", "HEAD")
+	writeAndCommit(t, other, "c.txt", "other\n")
+	gitIn(t, other, "push", "-q", "origin", "main")
+
+	u, err := RebaseBranch(context.Background(), work, "main", false)
+	if err != nil {
+		t.Fatalf("RebaseBranch: %v", err)
+	}
+	if want := "Rebased onto origin/main (1 commit behind), not pushed (allow_push off)"; u.String() != want {
+		t.Errorf("String = %q, want %q", u.String(), want)
+	}
+	if _, err := os.Stat(filepath.Join(work, "c.txt")); err != nil {
+		t.Errorf("not rebased locally: %v", err)
+	}
+	if remote, _, _ := strings.Cut(gitIn(t, work, "ls-remote", "origin", "refs/heads/work"), "\t"); remote != strings.TrimSpace(pushed) {
+		t.Errorf("remote branch moved to %s", remote)
+	}
+
+	s, err := SquashBranch(context.Background(), work, "main", "", SquashData{Number: 4, Title: "Fix", Type: "fix"}, false)
+	if err != nil {
+		t.Fatalf("SquashBranch: %v", err)
+	}
+	if s.Pushed || !s.Unpushed || !strings.HasSuffix(s.String(), ", not pushed (allow_push off)") {
+		t.Errorf("squash = %+v %q", s, s.String())
+	}
+	if remote, _, _ := strings.Cut(gitIn(t, work, "ls-remote", "origin", "refs/heads/work"), "\t"); remote != strings.TrimSpace(pushed) {
+		t.Errorf("remote branch moved to %s by the squash", remote)
+	}
+}
@@ -0,0 +1,155 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RecordingsDir is where an issue's terminal recordings live inside its
+// IssueDir, one asciicast v2 file per recording, named by when it began.
+// `asciinema play` replays them.
+const RecordingsDir = "recordings"
+
+// recordingStamp names recordings by their start, in UTC.
+const recordingStamp = "20060102-150405"
+
+// Recording is a saved terminal recording.
+type Recording struct {
+	Path      string
+	StartedAt time.Time
+	Size      int64
+}
+
+// Recorder writes terminal output to a recording as it arrives.
+type Recorder struct {
+	mu      sync.Mutex
+	f       *os.File
+	start   time.Time
+	partial []byte // unfinished UTF-8 sequence
+	err     error
+}
+
+// StartRecording begins a recording in issueDir of a cols×rows terminal.
+func StartRecording(issueDir string, cols, rows int, title string) (*Recorder, error) {
+	dir := filepath.Join(issueDir, RecordingsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	name := now.UTC().Format(recordingStamp)
+	path := filepath.Join(dir, name+".cast")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.cast", name, n))
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": now.Unix(),
+		"title":     title,
+	})
+	if _, err := f.Write(append(header, '\n')); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return &Recorder{f: f, start: now}, nil
+}
+
+// Path is the file being recorded to.
+func (r *Recorder) Path() string { return r.f.Name() }
+
+// Write records p as output at the current time. UTF-8 sequences split
+// across writes are held back until whole, since each event must be valid
+// JSON text. Write errors are kept for Close rather than returned, so a
+// full disk doesn't break the terminal the recording is teed from.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return len(p), nil
+	}
+	data := append(r.partial, p...)
+	r.partial = nil
+	if cut := incompleteTail(data); cut > 0 {
+		r.partial = append([]byte(nil), data[len(data)-cut:]...)
+		data = data[:len(data)-cut]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	r.output(data)
+	return len(p), nil
+}
+
+// output writes one output event. The caller holds r.mu.
+func (r *Recorder) output(data []byte) {
+	text, _ := json.Marshal(strings.ToValidUTF8(string(data), "\uFFFD"))
+	_, r.err = fmt.Fprintf(r.f, "[%.6f, \"o\", %s]\n", time.Since(r.start).Seconds(), text)
+}
+
+// Close finishes the recording and reports any error writing it.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.partial) > 0 && r.err == nil {
+		r.output(r.partial)
+		r.partial = nil
+	}
+	if err := r.f.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// incompleteTail returns how many bytes at the end of p start a UTF-8
+// sequence that isn't finished yet.
+func incompleteTail(p []byte) int {
+	for n := 1; n <= min(len(p), utf8.UTFMax-1); n++ {
+		if utf8.RuneStart(p[len(p)-n]) {
+			if !utf8.FullRune(p[len(p)-n:]) {
+				return n
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// ListRecordings returns issueDir's recordings, newest first.
+func ListRecordings(issueDir string) ([]Recording, error) {
+	files, err := filepath.Glob(filepath.Join(issueDir, RecordingsDir, "*.cast"))
+	if err != nil {
+		return nil, err
+	}
+	var list []Recording
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		rec := Recording{Path: path, StartedAt: info.ModTime(), Size: info.Size()}
+		if name := filepath.Base(path); len(name) >= len(recordingStamp) {
+			if t, err := time.Parse(recordingStamp, name[:len(recordingStamp)]); err == nil {
+				rec.StartedAt = t
+			}
+		}
+		list = append(list, rec)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartedAt.After(list[j].StartedAt) })
+	return list, nil
+}
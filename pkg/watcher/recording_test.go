@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecording_WritesAsciicast(t *testing.T) {
+	issueDir := t.TempDir()
+	rec, err := StartRecording(issueDir, 80, 24, "o/r#1")
+	if err != nil {
+		t.Fatalf("StartRecording: %v", err)
+	}
+	rec.Write([]byte("lurker$ ls\r\n"))
+	// "é" split across two writes.
+	rec.Write([]byte("caf\xc3"))
+	rec.Write([]byte("\xa9\r\n"))
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if filepath.Dir(rec.Path()) != filepath.Join(issueDir, RecordingsDir) {
+		t.Errorf("recorded to %s", rec.Path())
+	}
+
+	f, err := os.Open(rec.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Scan()
+	var header map[string]any
+	if err := json.Unmarshal(sc.Bytes(), &header); err != nil {
+		t.Fatalf("header %q: %v", sc.Text(), err)
+	}
+	if header["version"] != 2.0 || header["width"] != 80.0 || header["height"] != 24.0 || header["title"] != "o/r#1" {
+		t.Errorf("header = %v", header)
+	}
+	var out strings.Builder
+	for sc.Scan() {
+		var ev []any
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil || len(ev) != 3 || ev[1] != "o" {
+			t.Fatalf("event %q: %v", sc.Text(), err)
+		}
+		out.WriteString(ev[2].(string))
+	}
+	if want := "lurker$ ls\r\ncafé\r\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestListRecordings(t *testing.T) {
+	issueDir := t.TempDir()
+	if list, err := ListRecordings(issueDir); err != nil || len(list) != 0 {
+		t.Fatalf("ListRecordings() = %v, %v with none", list, err)
+	}
+	dir := filepath.Join(issueDir, RecordingsDir)
+	os.MkdirAll(dir, 0o755)
+	for _, name := range []string{"20260101-090000.cast", "20260301-090000.cast", "20260201-090000-2.cast", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0o644)
+	}
+	list, err := ListRecordings(issueDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range list {
+		names = append(names, filepath.Base(r.Path))
+	}
+	if got := strings.Join(names, " "); got != "20260301-090000.cast 20260201-090000-2.cast 20260101-090000.cast" {
+		t.Errorf("recordings = %s", got)
+	}
+	if list[0].StartedAt.Month() != 3 || list[0].Size != 3 {
+		t.Errorf("newest = %+v", list[0])
+	}
+}
@@ -0,0 +1,203 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report summarizes what a lurker instance did over a period, per repo.
+type Report struct {
+	Since, Until time.Time
+	Repos        []RepoReport // sorted by name; repos with no activity are left out
+	// MergedKnown is false when PR merges could not be looked up.
+	MergedKnown bool
+}
+
+// RepoReport is one repo's share of a Report.
+type RepoReport struct {
+	Repo       string
+	Processed  int         // issues whose latest run started in the period
+	Ready      int         // of those, ready for review
+	NeedsInput int         // of those, waiting for answers
+	Failures   []IssueMeta // failed issues updated in the period
+	PRsOpened  []IssueMeta // issues that got a PR, updated in the period
+	PRsMerged  []IssueMeta // lurker PRs merged in the period
+	CostUSD    float64     // spend of the runs counted in Processed
+}
+
+// MergedFunc reports when a pull request was merged (zero if it wasn't).
+type MergedFunc func(ctx context.Context, repo string, pr int) (time.Time, error)
+
+// BuildReport summarizes snap between since and until. issue.json only
+// keeps an issue's latest run, so an issue re-run in the period counts
+// once, with the cost of its latest run. merged may be nil to skip merges.
+func BuildReport(ctx context.Context, snap Snapshot, since, until time.Time, merged MergedFunc) Report {
+	in := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	rep := Report{Since: since, Until: until, MergedKnown: merged != nil}
+	byRepo := make(map[string]*RepoReport)
+	repoFor := func(name string) *RepoReport {
+		if r, ok := byRepo[name]; ok {
+			return r
+		}
+		r := &RepoReport{Repo: name}
+		byRepo[name] = r
+		return r
+	}
+
+	for _, iss := range snap.Issues {
+		if in(iss.StartedAt) && iss.Attempts > 0 {
+			r := repoFor(iss.Repo)
+			r.Processed++
+			r.CostUSD += iss.CostUSD
+			switch iss.Status {
+			case StatusReady.String():
+				r.Ready++
+			case StatusNeedsInput.String():
+				r.NeedsInput++
+			}
+		}
+		updated := in(iss.UpdatedAt)
+		if updated && iss.Status == StatusFailed.String() {
+			r := repoFor(iss.Repo)
+			r.Failures = append(r.Failures, iss)
+		}
+		if iss.PRNumber == 0 {
+			continue
+		}
+		if updated {
+			r := repoFor(iss.Repo)
+			r.PRsOpened = append(r.PRsOpened, iss)
+		}
+		if merged != nil {
+			at, err := merged(ctx, iss.Repo, iss.PRNumber)
+			if err != nil {
+				rep.MergedKnown = false
+				continue
+			}
+			if in(at) {
+				r := repoFor(iss.Repo)
+				r.PRsMerged = append(r.PRsMerged, iss)
+			}
+		}
+	}
+
+	for _, r := range byRepo {
+		rep.Repos = append(rep.Repos, *r)
+	}
+	sort.Slice(rep.Repos, func(i, j int) bool { return rep.Repos[i].Repo < rep.Repos[j].Repo })
+	return rep
+}
+
+// Totals adds up every repo.
+func (r Report) Totals() RepoReport {
+	var t RepoReport
+	for _, rr := range r.Repos {
+		t.Processed += rr.Processed
+		t.Ready += rr.Ready
+		t.NeedsInput += rr.NeedsInput
+		t.Failures = append(t.Failures, rr.Failures...)
+		t.PRsOpened = append(t.PRsOpened, rr.PRsOpened...)
+		t.PRsMerged = append(t.PRsMerged, rr.PRsMerged...)
+		t.CostUSD += rr.CostUSD
+	}
+	return t
+}
+
+// Markdown renders the report as a markdown document.
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Lurker report: %s – %s\n\n", r.Since.Format("2006-01-02 15:04"), r.Until.Format("2006-01-02 15:04"))
+	if len(r.Repos) == 0 {
+		b.WriteString("No activity.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Repo | Processed | Ready | Needs input | Failed | PRs opened | PRs merged | Spend |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|---:|---:|\n")
+	row := func(name string, rr RepoReport) {
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d | %d | %s | %s |\n",
+			name, rr.Processed, rr.Ready, rr.NeedsInput, len(rr.Failures), len(rr.PRsOpened), r.merged(rr), spend(rr.CostUSD))
+	}
+	for _, rr := range r.Repos {
+		row(rr.Repo, rr)
+	}
+	if len(r.Repos) > 1 {
+		row("**Total**", r.Totals())
+	}
+
+	t := r.Totals()
+	section := func(title string, issues []IssueMeta, detail func(IssueMeta) string) {
+		if len(issues) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, iss := range issues {
+			fmt.Fprintf(&b, "- %s %s%s\n", mdLink(fmt.Sprintf("%s#%d", iss.Repo, iss.Number), iss.URL), iss.Title, detail(iss))
+		}
+	}
+	prLink := func(iss IssueMeta) string { return " → " + mdLink(fmt.Sprintf("PR #%d", iss.PRNumber), iss.PRURL) }
+	section("PRs opened", t.PRsOpened, prLink)
+	section("PRs merged", t.PRsMerged, prLink)
+	section("Failures", t.Failures, func(iss IssueMeta) string {
+		if iss.Error == "" {
+			return ""
+		}
+		return fmt.Sprintf(" — `%s`", iss.Error)
+	})
+	return b.String()
+}
+
+// Slack renders the report as a Slack message (mrkdwn).
+func (r Report) Slack() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Lurker report* %s – %s\n", r.Since.Format("Jan 2 15:04"), r.Until.Format("Jan 2 15:04"))
+	if len(r.Repos) == 0 {
+		b.WriteString("No activity.")
+		return b.String()
+	}
+	for _, rr := range r.Repos {
+		fmt.Fprintf(&b, "• *%s*: %d processed, %d ready, %d need input, %d failed, %d PRs opened, %s merged, %s\n",
+			rr.Repo, rr.Processed, rr.Ready, rr.NeedsInput, len(rr.Failures), len(rr.PRsOpened), r.merged(rr), spend(rr.CostUSD))
+	}
+	t := r.Totals()
+	for _, iss := range t.Failures {
+		name := fmt.Sprintf("%s#%d", iss.Repo, iss.Number)
+		if iss.URL != "" {
+			name = fmt.Sprintf("<%s|%s>", iss.URL, name)
+		}
+		fmt.Fprintf(&b, ":x: %s %s\n", name, iss.Error)
+	}
+	if len(r.Repos) > 1 && t.CostUSD > 0 {
+		fmt.Fprintf(&b, "Total spend: $%.2f", t.CostUSD)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// spend renders a cost, or "–" if none is known: runs of agents other
+// than Claude, and those of lurkers before costs were recorded, have none.
+func spend(cost float64) string {
+	if cost <= 0 {
+		return "–"
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// mdLink renders a markdown link, or just text without a URL.
+func mdLink(text, url string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
+
+// merged formats a merged-PR count, or "?" if merges couldn't be checked.
+func (r Report) merged(rr RepoReport) string {
+	if !r.MergedKnown {
+		return "?"
+	}
+	return fmt.Sprintf("%d", len(rr.PRsMerged))
+}
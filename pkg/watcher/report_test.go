@@ -0,0 +1,117 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	during, before := since.Add(time.Hour), since.Add(-time.Hour)
+
+	snap := Snapshot{Issues: []IssueMeta{
+		{Repo: "a/one", Number: 1, Status: "ready", Attempts: 1, StartedAt: during, UpdatedAt: during, CostUSD: 0.5, PRNumber: 10, PRURL: "https://github.com/a/one/pull/10"},
+		{Repo: "a/one", Number: 2, Status: "failed", Attempts: 2, StartedAt: during, UpdatedAt: during, CostUSD: 0.25, Error: "Claude exited with code 1"},
+		{Repo: "a/one", Number: 3, Status: "ready", Attempts: 1, StartedAt: before, UpdatedAt: before, PRNumber: 11},
+		{Repo: "b/two", Number: 4, Status: "needs-input", Attempts: 1, StartedAt: during, UpdatedAt: during, CostUSD: 1},
+		{Repo: "b/two", Number: 5, Status: "pending", UpdatedAt: during},
+	}}
+	merged := func(ctx context.Context, repo string, pr int) (time.Time, error) {
+		if pr == 11 {
+			return during, nil
+		}
+		return time.Time{}, nil
+	}
+
+	rep := BuildReport(context.Background(), snap, since, until, merged)
+	if len(rep.Repos) != 2 {
+		t.Fatalf("repos = %+v, want a/one and b/two", rep.Repos)
+	}
+	one, two := rep.Repos[0], rep.Repos[1]
+	if one.Processed != 2 || one.Ready != 1 || len(one.Failures) != 1 || len(one.PRsOpened) != 1 || len(one.PRsMerged) != 1 || one.CostUSD != 0.75 {
+		t.Errorf("a/one = %+v", one)
+	}
+	if two.Processed != 1 || two.NeedsInput != 1 || two.CostUSD != 1 {
+		t.Errorf("b/two = %+v", two)
+	}
+
+	md := rep.Markdown()
+	for _, want := range []string{"| a/one | 2 | 1 | 0 | 1 | 1 | 1 | $0.75 |", "**Total**", "## Failures", "Claude exited with code 1", "[PR #10]"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if s := rep.Slack(); !strings.Contains(s, "*a/one*: 2 processed") || !strings.Contains(s, "Total spend: $1.75") {
+		t.Errorf("slack = %s", s)
+	}
+}
+
+func TestBuildReport_MergesUnknown(t *testing.T) {
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	snap := Snapshot{Issues: []IssueMeta{
+		{Repo: "a/one", Number: 1, Status: "ready", Attempts: 1, StartedAt: since, UpdatedAt: since, PRNumber: 10},
+	}}
+	failing := func(ctx context.Context, repo string, pr int) (time.Time, error) {
+		return time.Time{}, errors.New("rate limited")
+	}
+	for _, merged := range []MergedFunc{nil, failing} {
+		rep := BuildReport(context.Background(), snap, since, since.Add(time.Hour), merged)
+		if rep.MergedKnown || !strings.Contains(rep.Markdown(), "| 1 | ? |") {
+			t.Errorf("merges should be unknown:\n%s", rep.Markdown())
+		}
+	}
+
+	empty := BuildReport(context.Background(), Snapshot{}, since, since.Add(time.Hour), nil)
+	if !strings.Contains(empty.Markdown(), "No activity.") {
+		t.Errorf("empty report = %q", empty.Markdown())
+	}
+}
+
+func TestBuildReport_RecordedCost(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("a/one")
+	started := time.Now()
+	for num, cost := range map[int]string{1: "0.4213", 2: ""} {
+		mgr.tracked[IssueKey("a/one", num)] = TrackedIssue{Repo: "a/one", Number: num, Status: StatusClaudeRunning, Attempts: 1, StartedAt: started}
+		if cost != "" {
+			mgr.track(Event{Kind: EventCost, Repo: "a/one", IssueNum: num, Text: cost})
+		}
+		mgr.track(Event{Kind: EventReady, Repo: "a/one", IssueNum: num})
+		iss, _ := mgr.Issue("a/one", num)
+		os.MkdirAll(IssueDir(dir, "a/one", num), 0o755)
+		WriteIssueMeta(dir, iss)
+	}
+
+	snap, err := LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	rep := BuildReport(context.Background(), snap, started.Add(-time.Hour), started.Add(time.Hour), nil)
+	if len(rep.Repos) != 1 || rep.Repos[0].CostUSD != 0.4213 {
+		t.Fatalf("repos = %+v", rep.Repos)
+	}
+	if md := rep.Markdown(); !strings.Contains(md, "| a/one | 2 | 2 | 0 | 0 | 0 | ? | $0.42 |") {
+		t.Errorf("markdown:\n%s", md)
+	}
+
+	// Runs of unknown cost aren't reported as free.
+	unknown := BuildReport(context.Background(), Snapshot{Issues: []IssueMeta{
+		{Repo: "b/two", Number: 1, Status: "ready", Attempts: 1, StartedAt: started, UpdatedAt: started},
+	}}, started.Add(-time.Hour), started.Add(time.Hour), nil)
+	if md := unknown.Markdown(); !strings.Contains(md, "| b/two | 1 | 1 | 0 | 0 | 0 | ? | – |") {
+		t.Errorf("markdown of unknown spend:\n%s", md)
+	}
+	if s := unknown.Slack(); strings.Contains(s, "$") {
+		t.Errorf("slack of unknown spend = %s", s)
+	}
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResultFile is where Claude writes what a finished run did, at the
+// repository root. lurker moves it into the issue's IssueDir as
+// RunResultFile when the run ends, so it is neither committed nor pushed.
+const ResultFile = "RESULT.json"
+
+// RunResultFile holds the latest run's ResultFile in its IssueDir.
+const RunResultFile = "result.json"
+
+// resultFiles bounds how many changed files RunResult.Lines names.
+const resultFiles = 5
+
+// RunResult is Claude's own account of a run, read from ResultFile.
+type RunResult struct {
+	Summary      string   `json:"summary"`
+	FilesChanged []string `json:"files_changed,omitempty"`
+	TestsRun     []string `json:"tests_run,omitempty"`
+	FollowUps    []string `json:"follow_ups,omitempty"`
+}
+
+// resultPrompt is appended to every prompt so Claude reports its work in
+// a form lurker can show and put in the PR.
+const resultPrompt = `
+
+## Result
+When you are done, write ` + ResultFile + ` at the repository root, without
+committing it, as a JSON object with these fields:
+- "summary": what you changed and why, in a few sentences of Markdown
+- "files_changed": the paths of the files you changed
+- "tests_run": the test commands you ran, each with its outcome
+- "follow_ups": anything left for a maintainer to do or decide`
+
+// ReadRunResult loads the latest run's result from issueDir, if it left
+// one.
+func ReadRunResult(issueDir string) (RunResult, bool) {
+	data, err := os.ReadFile(filepath.Join(issueDir, RunResultFile))
+	if err != nil {
+		return RunResult{}, false
+	}
+	var r RunResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return RunResult{}, false
+	}
+	return r, true
+}
+
+// Lines describes r for the log and the issue dialog: the summary, then
+// a line each for the files changed, the tests run and every follow-up.
+func (r RunResult) Lines() []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(r.Summary), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(r.FilesChanged) > 0 {
+		names := r.FilesChanged
+		more := ""
+		if len(names) > resultFiles {
+			names, more = names[:resultFiles], fmt.Sprintf(", +%d more", len(r.FilesChanged)-resultFiles)
+		}
+		lines = append(lines, fmt.Sprintf("%s changed — %s%s", plural(len(r.FilesChanged), "file"), strings.Join(names, ", "), more))
+	}
+	if len(r.TestsRun) > 0 {
+		lines = append(lines, "Tests: "+strings.Join(r.TestsRun, "; "))
+	}
+	for _, f := range r.FollowUps {
+		lines = append(lines, "Follow-up: "+f)
+	}
+	return lines
+}
+
+// Markdown renders r as the sections of a PR description.
+func (r RunResult) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Summary\n\n")
+	b.WriteString(strings.TrimSpace(r.Summary))
+	b.WriteString("\n")
+	list := func(title string, items []string, item func(string) string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, it := range items {
+			b.WriteString("- " + item(it) + "\n")
+		}
+	}
+	list("Files changed", r.FilesChanged, func(f string) string { return "`" + f + "`" })
+	list("Tests run", r.TestsRun, func(t string) string { return t })
+	list("Follow-ups", r.FollowUps, func(f string) string { return f })
+	return b.String()
+}
+
+// collectResult moves the ResultFile Claude left in workdir into the
+// issue's IssueDir and logs it. The previous run's result is dropped
+// first, so a run that wrote none shows none. A ResultFile Claude
+// committed is read but left in place.
+func (w *Watcher) collectResult(ctx context.Context, eventCh chan<- Event, num int, workdir string) {
+	dest := filepath.Join(IssueDir(w.cfg.BaseDir, w.cfg.Repo, num), RunResultFile)
+	os.Remove(dest)
+	path := filepath.Join(workdir, ResultFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var r RunResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		w.emit(eventCh, EventClaudeLog, num, fmt.Sprintf("⚠ Ignoring %s: %v", ResultFile, err))
+		return
+	}
+	if err := os.WriteFile(dest, data, 0o644); err != nil {
+		w.emit(eventCh, EventClaudeLog, num, fmt.Sprintf("⚠ Saving %s: %v", ResultFile, err))
+		return
+	}
+	if tracked, _ := gitCmd(ctx, workdir, nil, "ls-files", ResultFile); tracked == "" {
+		os.Remove(path)
+	}
+	w.emit(eventCh, EventResult, num, strings.Join(r.Lines(), "\n"))
+}
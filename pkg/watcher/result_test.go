@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectResult(t *testing.T) {
+	baseDir := t.TempDir()
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: baseDir}}
+	workdir := IssueWorkdir(baseDir, "o/r", 4)
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q")
+	os.WriteFile(filepath.Join(workdir, ResultFile), []byte(`{
+		"summary": "Handle empty input.\n\nThe parser now returns early.",
+		"files_changed": ["parse.go", "parse_test.go"],
+		"tests_run": ["go test ./... (pass)"],
+		"follow_ups": ["Document the new error"]
+	}`), 0o644)
+
+	events := make(chan Event, 1)
+	w.collectResult(context.Background(), events, 4, workdir)
+	ev := <-events
+	want := "Handle empty input.\nThe parser now returns early.\n2 files changed — parse.go, parse_test.go\nTests: go test ./... (pass)\nFollow-up: Document the new error"
+	if ev.Kind != EventResult || ev.Text != want {
+		t.Errorf("event = %v %q", ev.Kind, ev.Text)
+	}
+	if _, err := os.Stat(filepath.Join(workdir, ResultFile)); !os.IsNotExist(err) {
+		t.Errorf("%s left in the worktree", ResultFile)
+	}
+	r, ok := ReadRunResult(IssueDir(baseDir, "o/r", 4))
+	if !ok || len(r.FilesChanged) != 2 {
+		t.Fatalf("ReadRunResult = %+v, %v", r, ok)
+	}
+	md := r.Markdown()
+	for _, s := range []string{"## Summary\n\nHandle empty input.", "## Files changed\n\n- `parse.go`", "## Follow-ups\n\n- Document the new error"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("Markdown() = %q, missing %q", md, s)
+		}
+	}
+
+	// A run without one drops the last run's.
+	w.collectResult(context.Background(), events, 4, workdir)
+	if _, ok := ReadRunResult(IssueDir(baseDir, "o/r", 4)); ok {
+		t.Error("stale result kept")
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected event %+v", ev)
+	default:
+	}
+}
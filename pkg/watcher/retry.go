@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RetryNotesFile holds, in an issue's IssueDir, why its last attempt
+// failed, from when it is retried until the retry's prompt is written.
+const RetryNotesFile = "retry-notes.md"
+
+// retryLogLines is how much of the failed attempt's log the notes keep.
+const retryLogLines = 20
+
+// noteRetry writes RetryNotesFile for repo#num if its last attempt
+// failed, and removes any left from before otherwise. It must run before
+// the issue's new status is saved.
+func (m *Manager) noteRetry(repo string, num int) {
+	dir := IssueDir(m.baseDir, repo, num)
+	path := filepath.Join(dir, RetryNotesFile)
+	meta, err := ReadIssueMeta(m.baseDir, repo, num)
+	if err != nil || meta.Status != StatusFailed.String() || meta.Error == "" {
+		os.Remove(path)
+		return
+	}
+	notes := retryNotes(meta.Error, attemptLog(dir, meta.StartedAt, retryLogLines))
+	os.WriteFile(path, []byte(notes), 0o644)
+}
+
+// takeRetryNotes returns and removes the notes noteRetry left in
+// issueDir, or "".
+func takeRetryNotes(issueDir string) string {
+	path := filepath.Join(issueDir, RetryNotesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	os.Remove(path)
+	return string(data)
+}
+
+// attemptLog returns the last n lines of issueDir's log logged since the
+// attempt started at since, or all of the last n if since is zero.
+func attemptLog(issueDir string, since time.Time, n int) []string {
+	f, err := os.Open(filepath.Join(issueDir, LogFile))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	since = since.Truncate(time.Second) // log stamps have whole seconds
+	var lines []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		at, text := ParseLogLine(sc.Text())
+		if strings.TrimSpace(text) == "" || (!since.IsZero() && at.Before(since)) {
+			continue
+		}
+		if lines = append(lines, text); len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
+
+// retryNotes tells Claude that the previous attempt failed with errText,
+// and how its log ended.
+func retryNotes(errText string, log []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The previous attempt at this issue failed: %s\n", errText)
+	if f := ClassifyFailure(errText); f.Suggestion != "" {
+		fmt.Fprintf(&b, "Lurker's diagnosis: %s\n", f.Suggestion)
+	}
+	if len(log) > 0 {
+		b.WriteString("\nIts log ended:\n\n")
+		for _, line := range log {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// retryPrompt is the section of a retry's prompt with the notes on the
+// attempt that failed.
+func retryPrompt(notes string) string {
+	return `
+
+## Previous attempt
+` + strings.TrimRight(notes, "\n") + `
+
+Work out why it failed before changing anything, and don't repeat the
+mistake. Whatever it committed or left uncommitted is still in the
+worktree; check git status and git log, and build on it or undo it.`
+}
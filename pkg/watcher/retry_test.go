@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNoteRetry(t *testing.T) {
+	base := t.TempDir()
+	m, err := NewManager(base, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	dir := IssueDir(base, "o/r", 4)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var log strings.Builder
+	fmt.Fprintf(&log, "%s\t▶ Started\n", started.Add(-time.Hour).Format(time.RFC3339))
+	fmt.Fprintf(&log, "%s\tRunning Claude Code...\n", started.Format(time.RFC3339))
+	for i := range retryLogLines {
+		fmt.Fprintf(&log, "%s\t  line %d\n", started.Add(time.Duration(i)*time.Second).Format(time.RFC3339), i)
+	}
+	fmt.Fprintf(&log, "%s\t❌ Claude timed out after 30m0s\n", started.Add(time.Hour).Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(dir, LogFile), []byte(log.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	iss := TrackedIssue{Repo: "o/r", Number: 4, Status: StatusFailed, Error: "Claude timed out after 30m0s", StartedAt: started}
+	if err := WriteIssueMeta(base, iss); err != nil {
+		t.Fatal(err)
+	}
+
+	m.noteRetry("o/r", 4)
+	notes := takeRetryNotes(dir)
+	for _, want := range []string{
+		"failed: Claude timed out after 30m0s\n",
+		"diagnosis: the run hit --run-timeout",
+		"    line 1\n",
+		"    ❌ Claude timed out",
+	} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("notes missing %q:\n%s", want, notes)
+		}
+	}
+	// Only the last retryLogLines lines of the attempt are kept.
+	if strings.Contains(notes, "line 0\n") || strings.Contains(notes, "Started") {
+		t.Errorf("notes kept too much of the log:\n%s", notes)
+	}
+	if again := takeRetryNotes(dir); again != "" {
+		t.Errorf("notes not removed once taken: %q", again)
+	}
+
+	// Starting an issue that didn't fail drops stale notes.
+	os.WriteFile(filepath.Join(dir, RetryNotesFile), []byte("stale"), 0o644)
+	iss.Status, iss.Error = StatusPaused, ""
+	WriteIssueMeta(base, iss)
+	m.noteRetry("o/r", 4)
+	if notes := takeRetryNotes(dir); notes != "" {
+		t.Errorf("notes for a paused issue = %q", notes)
+	}
+}
@@ -0,0 +1,207 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// Pull requests awaiting the user's review are drafted in
+// ReviewsDir/owner/repo/N: Claude reads the PR's diff and writes
+// ReviewFile, which the user edits before lurker submits it.
+const (
+	ReviewsDir      = "reviews"
+	ReviewFile      = "REVIEW.md"
+	reviewDiffFile  = "pr.diff"
+	reviewLogFile   = "draft.log"
+	submittedSuffix = ".submitted"
+)
+
+// reviewTools lets Claude read the diff and write the draft, nothing more.
+const reviewTools = "Read,Grep,Glob,Write"
+
+// ReviewDir is where the review of repo's PR num is drafted.
+func ReviewDir(baseDir, repo string, num int) string {
+	return filepath.Join(baseDir, ReviewsDir, repo, fmt.Sprintf("%d", num))
+}
+
+// ReviewDraftPath returns the path of the draft review of repo's PR num,
+// or "" if there is none.
+func ReviewDraftPath(baseDir, repo string, num int) string {
+	path := filepath.Join(ReviewDir(baseDir, repo, num), ReviewFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// reviewPrompt asks Claude to draft a review of pr in ReviewFile.
+func reviewPrompt(pr github.ReviewRequest) string {
+	return fmt.Sprintf(`You are pre-drafting a code review of pull request #%d in %s for a human
+reviewer, who will edit your draft before submitting it.
+
+Title: %s
+Author: %s
+
+Description:
+%s
+
+The PR's diff is in %s. Read it (and nothing outside this directory),
+then write your review to %s in exactly this format:
+
+    verdict: comment
+
+    A short summary of what the PR does and your overall assessment.
+
+    --- path/to/file.go:42
+    A comment on line 42 of path/to/file.go as changed by the PR.
+
+The verdict is approve, comment or request_changes. Add one "--- path:line"
+section per suggested comment; the line must be a line number in the new
+version of the file that appears in the diff. Only comment on real
+problems or clear improvements, and say why.`,
+		pr.Number, pr.Repo, pr.Title, pr.Author, pr.Body, reviewDiffFile, ReviewFile)
+}
+
+// DraftReview has Claude draft a review of pr, replacing any earlier
+// draft, logging its output in format, in box's sandbox if box is
+// non-nil. It returns the draft's path.
+func DraftReview(ctx context.Context, box *Bwrap, baseDir string, gh *github.Client, pr github.ReviewRequest, format StreamFormat) (string, error) {
+	dir := ReviewDir(baseDir, pr.Repo, pr.Number)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	diff, err := gh.PRDiff(ctx, pr.Repo, pr.Number)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, reviewDiffFile), []byte(diff), 0o644); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, ReviewFile)
+	os.Remove(path)
+
+	logFile, err := os.Create(filepath.Join(dir, reviewLogFile))
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+	logFn := func(line string) {
+		fmt.Fprintf(logFile, "%s\t%s\n", time.Now().Format(time.RFC3339), line)
+	}
+	if _, err := (claudeAgent{}).Run(ctx, dir, reviewPrompt(pr), AgentOptions{Tools: reviewTools, Format: format, Log: logFn, Bwrap: box}); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("claude wrote no %s", ReviewFile)
+	}
+	if _, err := ParseReview(string(data)); err != nil {
+		return "", fmt.Errorf("draft: %w", err)
+	}
+	return path, nil
+}
+
+// commentHeader starts a line comment in a review draft.
+var commentHeader = regexp.MustCompile(`^--- (\S+):(\d+)\s*$`)
+
+// ParseReview reads a review draft: a "verdict:" line, the review's body,
+// then "--- path:line" sections holding line comments. Lines that are
+// just an HTML comment are ignored.
+func ParseReview(text string) (github.Review, error) {
+	var review github.Review
+	var body strings.Builder
+	var comment *github.ReviewComment
+	flush := func() {
+		if comment == nil {
+			review.Body = strings.TrimSpace(body.String())
+		} else {
+			comment.Body = strings.TrimSpace(body.String())
+			review.Comments = append(review.Comments, *comment)
+		}
+		body.Reset()
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "<!--") && strings.HasSuffix(trimmed, "-->") {
+			continue
+		}
+		if review.Event == "" {
+			if trimmed == "" {
+				continue
+			}
+			verdict, ok := strings.CutPrefix(trimmed, "verdict:")
+			if !ok {
+				return github.Review{}, errors.New(`review must start with "verdict:"`)
+			}
+			switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(verdict)), " ", "_") {
+			case "approve":
+				review.Event = github.ReviewEventApprove
+			case "comment":
+				review.Event = github.ReviewEventComment
+			case "request_changes":
+				review.Event = github.ReviewEventRequestChanges
+			default:
+				return github.Review{}, fmt.Errorf("unknown verdict %q (want approve, comment or request_changes)", strings.TrimSpace(verdict))
+			}
+			continue
+		}
+		if m := commentHeader.FindStringSubmatch(line); m != nil {
+			flush()
+			n, _ := strconv.Atoi(m[2])
+			comment = &github.ReviewComment{Path: m[1], Line: n}
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	if review.Event == "" {
+		return github.Review{}, errors.New("empty review")
+	}
+	flush()
+
+	for _, c := range review.Comments {
+		if c.Body == "" || c.Line <= 0 {
+			return github.Review{}, fmt.Errorf("comment on %s:%d is empty", c.Path, c.Line)
+		}
+	}
+	if review.Event != github.ReviewEventApprove && review.Body == "" && len(review.Comments) == 0 {
+		return github.Review{}, errors.New("review has neither a summary nor comments")
+	}
+	return review, nil
+}
+
+// SubmitReview submits the draft review of repo's PR num, with event
+// overriding its verdict if set, and sets the draft aside so it isn't
+// submitted twice.
+func SubmitReview(ctx context.Context, gh *github.Client, baseDir, repo string, num int, event string) error {
+	path := ReviewDraftPath(baseDir, repo, num)
+	if path == "" {
+		return errors.New("no draft review")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	review, err := ParseReview(string(data))
+	if err != nil {
+		return err
+	}
+	if event != "" {
+		review.Event = event
+	}
+	if err := gh.SubmitReview(ctx, repo, num, review); err != nil {
+		return err
+	}
+	return os.Rename(path, path+submittedSuffix)
+}
@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestParseReview(t *testing.T) {
+	draft := `<!-- edit me, then submit -->
+verdict: request changes
+
+Adds a cache in front of the resolver.
+
+The eviction looks wrong.
+
+--- pkg/cache.go:42
+This evicts the newest entry,
+not the oldest.
+
+--- pkg/cache_test.go:7
+Missing a test for eviction.
+`
+	review, err := ParseReview(draft)
+	if err != nil {
+		t.Fatalf("ParseReview: %v", err)
+	}
+	if review.Event != github.ReviewEventRequestChanges {
+		t.Errorf("event = %q", review.Event)
+	}
+	if review.Body != "Adds a cache in front of the resolver.\n\nThe eviction looks wrong." {
+		t.Errorf("body = %q", review.Body)
+	}
+	want := []github.ReviewComment{
+		{Path: "pkg/cache.go", Line: 42, Body: "This evicts the newest entry,\nnot the oldest."},
+		{Path: "pkg/cache_test.go", Line: 7, Body: "Missing a test for eviction."},
+	}
+	if len(review.Comments) != len(want) {
+		t.Fatalf("comments = %+v", review.Comments)
+	}
+	for i, c := range review.Comments {
+		if c != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+
+	if r, err := ParseReview("verdict: approve\n"); err != nil || r.Event != github.ReviewEventApprove {
+		t.Errorf("bare approval = %+v, %v", r, err)
+	}
+	for _, bad := range []string{
+		"",
+		"Looks good\n",
+		"verdict: maybe\n",
+		"verdict: comment\n",
+		"verdict: comment\n\n--- a.go:1\n",
+	} {
+		if _, err := ParseReview(bad); err == nil {
+			t.Errorf("ParseReview(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestReviewDraftPath(t *testing.T) {
+	base := t.TempDir()
+	if p := ReviewDraftPath(base, "o/r", 12); p != "" {
+		t.Errorf("path without draft = %q", p)
+	}
+	dir := ReviewDir(base, "o/r", 12)
+	if dir != filepath.Join(base, "reviews", "o", "r", "12") {
+		t.Errorf("ReviewDir = %q", dir)
+	}
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, ReviewFile), []byte("verdict: approve\n"), 0o644)
+	if p := ReviewDraftPath(base, "o/r", 12); p != filepath.Join(dir, ReviewFile) {
+		t.Errorf("path = %q", p)
+	}
+}
@@ -0,0 +1,318 @@
+package watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SandboxFile, in an IssueDir, names the Docker image the issue's
+// commands run in (see RepoConfig.SandboxImage). It is written when the
+// worktree is made, and is outside it, so nothing run in the sandbox can
+// turn the sandbox off.
+const SandboxFile = "sandbox"
+
+// sandboxEnv are the variables passed into sandboxes, for Claude to log in
+// with.
+var sandboxEnv = []string{"ANTHROPIC_API_KEY", "CLAUDE_CODE_OAUTH_TOKEN"}
+
+// SandboxGitDir, in an IssueDir, is the git dir of a sandboxed worktree,
+// kept outside it: a container that could write its config or hooks
+// could run code on the host with the next git command lurker runs there.
+const SandboxGitDir = "sandbox.git"
+
+// SandboxHome, in an IssueDir, is the HOME of its sandbox, where Claude's
+// sessions outlive the container.
+const SandboxHome = "sandbox-home"
+
+// A sandbox is the Docker container an issue's clone, Claude and commands
+// run in. It mounts the worktree, at the same path, so paths mean the same
+// in and out of it, and its HOME. Of the worktree's git dir, kept outside
+// it in SandboxGitDir, it may write only what commits and checkouts need:
+// the objects, refs and logs, and the worktree's own HEAD and index. The
+// config and hooks, which git on the host obeys, the worktree's own
+// config, and the links between the worktree and its git dir, are
+// read-only.
+type sandbox struct {
+	image   string
+	workdir string
+}
+
+// sandboxFor returns the sandbox of the worktree workdir, nil if it has
+// none.
+func sandboxFor(workdir string) *sandbox {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(workdir), SandboxFile))
+	image := strings.TrimSpace(string(data))
+	if err != nil || image == "" {
+		return nil
+	}
+	return &sandbox{image: image, workdir: workdir}
+}
+
+// SetSandboxImage has every repo's new issues run in a container of
+// image, whatever their config says; "" leaves it to their config and the
+// team policy.
+func (m *Manager) SetSandboxImage(image string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sandboxImage = image
+}
+
+// sandboxImageFor returns the image the new issues of a repo whose config
+// is repo run in, "" for none. The repo only has a say when neither the
+// user nor the team policy has: it could otherwise run its code on the
+// host by clearing it.
+func (m *Manager) sandboxImageFor(repo RepoConfig) string {
+	m.mu.Lock()
+	image := m.sandboxImage
+	m.mu.Unlock()
+	if image != "" {
+		return image
+	}
+	return m.teamConfig().Policy.apply(repo).SandboxImage
+}
+
+// resetSandbox records that the commands of workdir, a new worktree, run
+// in image, or, if image is "", on the host. The container of the
+// worktree that was there before, if any, is removed: it still mounts
+// that one.
+func resetSandbox(workdir, image string) error {
+	if s := sandboxFor(workdir); s != nil {
+		s.remove()
+	}
+	path := filepath.Join(filepath.Dir(workdir), SandboxFile)
+	if image == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(image+"\n"), 0o644)
+}
+
+// name is s's container's: one per worktree path, as that is what it
+// mounts.
+func (s *sandbox) name() string {
+	sum := sha256.Sum256([]byte(s.workdir))
+	return "lurker-" + hex.EncodeToString(sum[:6])
+}
+
+// home is s's HOME.
+func (s *sandbox) home() string {
+	return filepath.Join(filepath.Dir(s.workdir), SandboxHome)
+}
+
+// gitDir is where s's worktree's git dir is kept.
+func (s *sandbox) gitDir() string {
+	return filepath.Join(filepath.Dir(s.workdir), SandboxGitDir)
+}
+
+// runArgs are the `docker run` flags every container of s's takes: run as
+// the user lurker runs as, so what it writes stays theirs, with the
+// worktree and the given extra volumes mounted.
+func (s *sandbox) runArgs(home string, volumes ...string) []string {
+	args := []string{"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"-v", s.workdir + ":" + s.workdir, "-w", s.workdir, "-e", "HOME=" + home}
+	for _, v := range volumes {
+		args = append(args, "-v", v)
+	}
+	for _, env := range sandboxEnv {
+		args = append(args, "-e", env)
+	}
+	return args
+}
+
+// start starts s's container unless it is running, creating it the first
+// time. It idles until commands are run in it.
+func (s *sandbox) start(ctx context.Context) error {
+	volumes, err := s.volumes()
+	if err != nil {
+		return err
+	}
+	if exec.CommandContext(ctx, "docker", "start", s.name()).Run() == nil {
+		return nil
+	}
+	args := append([]string{"run", "-d", "--name", s.name()}, s.runArgs(s.home(), volumes...)...)
+	args = append(args, s.image, "sleep", "infinity")
+	if out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("starting sandbox: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// volumes are what s's container mounts besides the worktree: its HOME,
+// and the worktree's git dir, read-only but for what git needs to commit
+// (see sandbox). A worktree whose git dir is anywhere but s.gitDir() isn't
+// run in: the container could write that one's config.
+func (s *sandbox) volumes() ([]string, error) {
+	gitDir, common := gitDirs(s.workdir)
+	if common != s.gitDir() || filepath.Dir(gitDir) != filepath.Join(common, "worktrees") {
+		return nil, fmt.Errorf("sandbox: %s's git dir isn't kept outside it; delete the worktree to clone it afresh", s.workdir)
+	}
+	rw := func(path string) string { return path + ":" + path }
+	ro := func(path string) string { return path + ":" + path + ":ro" }
+	return []string{
+		rw(s.home()),
+		ro(common),
+		rw(filepath.Join(common, "objects")),
+		rw(filepath.Join(common, "refs")),
+		rw(filepath.Join(common, "logs")),
+		rw(gitDir),
+		ro(filepath.Join(gitDir, "commondir")),
+		ro(filepath.Join(gitDir, "gitdir")),
+		ro(filepath.Join(gitDir, "config.worktree")),
+		ro(filepath.Join(s.workdir, ".git")),
+	}, nil
+}
+
+// keepGitDirOut moves the git dir of s's worktree, a clone whole in it
+// that nothing has run in yet, out to s.gitDir(), and makes the worktree
+// a linked worktree of it, as volumes requires.
+func (s *sandbox) keepGitDirOut(ctx context.Context) error {
+	dotGit := filepath.Join(s.workdir, ".git")
+	common := s.gitDir()
+	branch, err := gitCmd(ctx, s.workdir, nil, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(common); err != nil {
+		return err
+	}
+	if err := os.Rename(dotGit, common); err != nil {
+		return err
+	}
+	// Unset, not true: with per-worktree config on, the common config's
+	// core.bare holds for every worktree.
+	if _, err := gitCmd(ctx, common, nil, "config", "--unset", "core.bare"); err != nil {
+		return err
+	}
+	// Added beside the checkout, so its admin dir is named for it, and
+	// then attached to it.
+	tmp := filepath.Join(filepath.Dir(s.workdir), ".adding", filepath.Base(s.workdir))
+	defer os.RemoveAll(filepath.Dir(tmp))
+	if _, err := gitCmd(ctx, common, nil, "worktree", "add", "-q", "-f", "--no-checkout", tmp, branch); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(tmp, ".git"), dotGit); err != nil {
+		return err
+	}
+	if _, err := gitCmd(ctx, common, nil, "worktree", "repair", s.workdir); err != nil {
+		return err
+	}
+	gitDir, _ := gitDirs(s.workdir)
+	// A sparse checkout's patterns, and the config git may have kept for
+	// it, are the worktree's own. The config is there even if empty, so
+	// the container can't write one.
+	if patterns, err := os.ReadFile(filepath.Join(common, "info", "sparse-checkout")); err == nil {
+		os.MkdirAll(filepath.Join(gitDir, "info"), 0o755)
+		if err := os.WriteFile(filepath.Join(gitDir, "info", "sparse-checkout"), patterns, 0o644); err != nil {
+			return err
+		}
+	}
+	config, _ := os.ReadFile(filepath.Join(common, "config.worktree"))
+	if err := os.WriteFile(filepath.Join(gitDir, "config.worktree"), config, 0o644); err != nil {
+		return err
+	}
+	if _, err := gitCmd(ctx, s.workdir, nil, "reset", "-q"); err != nil {
+		return err
+	}
+	// What the container mounts has to be there first, or Docker makes it.
+	for _, dir := range []string{filepath.Join(common, "logs"), s.home()} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shell returns the shell command that runs cmd in s's container, reading
+// the caller's stdin.
+func (s *sandbox) shell(cmd string) string {
+	return fmt.Sprintf("docker exec -i %s sh -c %s", s.name(), ShellQuote(cmd))
+}
+
+// command returns cmd, a shell command, to run in s's container.
+func (s *sandbox) command(ctx context.Context, cmd string) *exec.Cmd {
+	return exec.CommandContext(ctx, "docker", "exec", s.name(), "sh", "-c", cmd)
+}
+
+// cloneShell returns the shell command that clones bareDir into the
+// worktree in a container of its own, which sees bareDir read-only:
+// branch off base, with only scope checked out if set. The clone is whole
+// in the worktree until keepGitDirOut moves its git dir out.
+func (s *sandbox) cloneShell(bareDir, branch, base, scope, origin string) string {
+	steps := []string{
+		"git clone -q --no-checkout --branch " + ShellQuote(base) + " " + ShellQuote(bareDir) + " .",
+		"git remote set-url origin " + ShellQuote(origin),
+	}
+	if scope != "" {
+		steps = append(steps, "git sparse-checkout set --cone -- "+ShellQuote(scope)+" .lurker")
+	}
+	steps = append(steps, "git checkout -q -b "+ShellQuote(branch), "git reset -q --hard")
+	args := append([]string{"docker", "run", "--rm"}, s.runArgs("/tmp", bareDir+":"+bareDir+":ro")...)
+	for i, a := range args {
+		args[i] = ShellQuote(a)
+	}
+	return fmt.Sprintf("%s %s sh -c %s", strings.Join(args, " "), ShellQuote(s.image), ShellQuote(strings.Join(steps, " && ")))
+}
+
+// sandboxClone makes s's worktree, for the issue's branch off base: a
+// clone of the repo's bare clone, made in the sandbox.
+func (w *Watcher) sandboxClone(ctx context.Context, run runFunc, s *sandbox, branch, base, scope string) error {
+	bareDir := w.bareDir()
+	origin, err := gitCmd(ctx, bareDir, nil, "remote", "get-url", "origin")
+	if err != nil {
+		return fmt.Errorf("sandboxed clone: %w", err)
+	}
+	if err := os.MkdirAll(s.workdir, 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	code, err := run(s.cloneShell(bareDir, branch, base, scope, origin))
+	if err == nil && code != 0 {
+		err = fmt.Errorf("exit code %d", code)
+	}
+	if err == nil {
+		err = s.keepGitDirOut(ctx)
+	}
+	if err != nil {
+		// Or the next run would take it for a worktree.
+		removeWorktreeDir(s.workdir)
+		return fmt.Errorf("sandboxed clone: %w", err)
+	}
+	return nil
+}
+
+// remove deletes s's container, if it has one.
+func (s *sandbox) remove() {
+	exec.Command("docker", "rm", "-f", s.name()).Run()
+}
+
+// removeWorktreeDir deletes the worktree workdir, and its sandbox's
+// container and git dir if it has them.
+func removeWorktreeDir(workdir string) error {
+	if s := sandboxFor(workdir); s != nil {
+		s.remove()
+		if err := os.RemoveAll(s.gitDir()); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(workdir)
+}
+
+// shellCommand returns cmd, a shell command, to run in the worktree
+// workdir: in its sandbox if it has one, which is started if need be.
+func shellCommand(ctx context.Context, workdir, cmd string) *exec.Cmd {
+	if s := sandboxFor(workdir); s != nil {
+		// If it won't start, the command fails saying why.
+		s.start(ctx)
+		return s.command(ctx, cmd)
+	}
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Dir = workdir
+	return c
+}
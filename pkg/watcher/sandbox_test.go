@@ -0,0 +1,252 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a docker on PATH that logs its arguments and runs the
+// container's `sh -c` command, if any, on the host, in its -w directory.
+// There are no containers to start.
+func fakeDocker(t *testing.T) (log string) {
+	t.Helper()
+	bin := t.TempDir()
+	log = filepath.Join(bin, "log")
+	script := `#!/bin/sh
+echo "$*" >> ` + ShellQuote(log) + `
+[ "$1" = start ] && exit 1
+while [ $# -gt 0 ]; do
+	case $1 in
+	-w) cd "$2"; shift 2 ;;
+	sh) shift; exec sh "$@" ;;
+	*) shift ;;
+	esac
+done
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestSandboxClone(t *testing.T) {
+	log := fakeDocker(t)
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: filepath.Join(root, "base")}}
+	gitIn(t, root, "clone", "-q", "--bare", src, w.bareDir())
+	gitIn(t, w.bareDir(), "remote", "set-url", "origin", "https://github.com/o/r.git")
+
+	workdir := IssueWorkdir(w.cfg.BaseDir, "o/r", 4)
+	os.MkdirAll(filepath.Dir(workdir), 0o755)
+	if err := resetSandbox(workdir, "golang:1"); err != nil {
+		t.Fatalf("resetSandbox: %v", err)
+	}
+	s := sandboxFor(workdir)
+	if s == nil || s.image != "golang:1" {
+		t.Fatalf("sandboxFor = %+v", s)
+	}
+	run := func(cmd string) (int, error) {
+		if err := exec.Command("sh", "-c", cmd).Run(); err != nil {
+			if exit, ok := err.(*exec.ExitError); ok {
+				return exit.ExitCode(), nil
+			}
+			return -1, err
+		}
+		return 0, nil
+	}
+	if err := w.sandboxClone(context.Background(), run, s, IssueBranch(4), "main", ""); err != nil {
+		t.Fatalf("sandboxClone: %v", err)
+	}
+
+	// A worktree whose git dir is kept outside it.
+	if gitDir, common := gitDirs(workdir); common != s.gitDir() || filepath.Dir(gitDir) != filepath.Join(common, "worktrees") {
+		t.Errorf("git dirs = %s, %s", gitDir, common)
+	}
+	if got := strings.TrimSpace(gitIn(t, workdir, "rev-parse", "--abbrev-ref", "HEAD")); got != "agent/issue-4" {
+		t.Errorf("HEAD = %q", got)
+	}
+	if got := strings.TrimSpace(gitIn(t, workdir, "remote", "get-url", "origin")); got != "https://github.com/o/r.git" {
+		t.Errorf("origin = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(workdir, "a.txt")); err != nil {
+		t.Errorf("not checked out: %v", err)
+	}
+	calls, _ := os.ReadFile(log)
+	if !strings.Contains(string(calls), w.bareDir()+":"+w.bareDir()+":ro") || !strings.Contains(string(calls), "golang:1") {
+		t.Errorf("clone container: %s", calls)
+	}
+
+	// Commands then run in the issue's container.
+	os.Remove(log)
+	if out, err := shellCommand(context.Background(), workdir, "echo hi").Output(); err != nil || string(out) != "hi\n" {
+		t.Errorf("shellCommand = %q, %v", out, err)
+	}
+	if calls, _ := os.ReadFile(log); !strings.Contains(string(calls), "exec "+s.name()+" sh -c echo hi") {
+		t.Errorf("docker calls: %s", calls)
+	}
+	if got := strings.TrimSpace(gitIn(t, workdir, "status", "--porcelain")); got != "" {
+		t.Errorf("status = %q", got)
+	}
+
+	// Without a sandbox, on the host.
+	if err := resetSandbox(workdir, ""); err != nil {
+		t.Fatalf("resetSandbox: %v", err)
+	}
+	if sandboxFor(workdir) != nil {
+		t.Error("sandbox kept")
+	}
+	if out, err := shellCommand(context.Background(), workdir, "pwd").Output(); err != nil || strings.TrimSpace(string(out)) != workdir {
+		t.Errorf("shellCommand = %q, %v", out, err)
+	}
+}
+
+// sandboxMounts lists what `docker run -d` mounted in calls, the docker
+// log, as paths that can be written and paths that can't.
+func sandboxMounts(t *testing.T, calls string) (rw, ro []string) {
+	t.Helper()
+	for line := range strings.Lines(calls) {
+		args := strings.Fields(line)
+		if len(args) < 2 || args[0] != "run" || args[1] != "-d" {
+			continue
+		}
+		for i, arg := range args[:len(args)-1] {
+			if arg != "-v" {
+				continue
+			}
+			path, opts, _ := strings.Cut(args[i+1], ":")
+			if strings.HasSuffix(opts, ":ro") {
+				ro = append(ro, path)
+			} else {
+				rw = append(rw, path)
+			}
+		}
+		return rw, ro
+	}
+	t.Fatalf("no docker run -d in %s", calls)
+	return nil, nil
+}
+
+func TestSandbox_HooksDontRunOnHost(t *testing.T) {
+	log := fakeDocker(t)
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	os.MkdirAll(src, 0o755)
+	gitIn(t, src, "init", "-q", "-b", "main")
+	writeAndCommit(t, src, "a.txt", "one\n")
+
+	w := &Watcher{cfg: Config{Repo: "o/r", BaseDir: filepath.Join(root, "base")}}
+	gitIn(t, root, "clone", "-q", "--bare", src, w.bareDir())
+	workdir := IssueWorkdir(w.cfg.BaseDir, "o/r", 4)
+	os.MkdirAll(filepath.Dir(workdir), 0o755)
+	if err := resetSandbox(workdir, "golang:1"); err != nil {
+		t.Fatalf("resetSandbox: %v", err)
+	}
+	s := sandboxFor(workdir)
+	run := func(cmd string) (int, error) { return 0, exec.Command("sh", "-c", cmd).Run() }
+	ctx := context.Background()
+	if err := w.sandboxClone(ctx, run, s, IssueBranch(4), "main", ""); err != nil {
+		t.Fatalf("sandboxClone: %v", err)
+	}
+	// As a sparse checkout may, so the worktree's own config counts.
+	gitIn(t, s.gitDir(), "config", "extensions.worktreeConfig", "true")
+	if err := s.start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	calls, _ := os.ReadFile(log)
+	rw, ro := sandboxMounts(t, string(calls))
+
+	// Whatever the container can write, it fills with hooks and config
+	// pointing git at them.
+	marker := filepath.Join(root, "hooked")
+	hook := "#!/bin/sh\ntouch " + ShellQuote(marker) + "\n"
+	writable := func(path string) bool {
+		if slices.Contains(ro, path) {
+			return false
+		}
+		for _, dir := range rw {
+			if path == dir || strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, dir := range rw {
+		hooks := filepath.Join(dir, "hooks")
+		os.MkdirAll(hooks, 0o755)
+		for _, name := range []string{"pre-commit", "post-commit"} {
+			os.WriteFile(filepath.Join(hooks, name), []byte(hook), 0o755)
+		}
+		config := "[core]\n\thooksPath = " + hooks + "\n"
+		for _, name := range []string{"config", "config.worktree"} {
+			if path := filepath.Join(dir, name); writable(path) {
+				os.WriteFile(path, []byte(config), 0o644)
+			}
+		}
+	}
+	for _, path := range []string{filepath.Join(workdir, ".git"), filepath.Join(s.gitDir(), "config"), filepath.Join(s.gitDir(), "hooks")} {
+		if writable(path) {
+			t.Errorf("%s is writable from the sandbox", path)
+		}
+	}
+
+	// None of it runs when lurker commits on the host...
+	os.WriteFile(filepath.Join(workdir, "b.txt"), []byte("two\n"), 0o644)
+	env := []string{"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com"}
+	if _, err := gitCmd(ctx, workdir, env, "add", "b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gitCmd(ctx, workdir, env, "commit", "-q", "-m", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("a hook written from the sandbox ran on the host")
+	}
+
+	// ...where the repo's own hooks, which the sandbox can't write, would.
+	os.WriteFile(filepath.Join(s.gitDir(), "hooks", "post-commit"), []byte(hook), 0o755)
+	if _, err := gitCmd(ctx, workdir, env, "commit", "-q", "--allow-empty", "-m", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("the repo's hook didn't run: %v", err)
+	}
+}
+
+func TestSandboxImageFor(t *testing.T) {
+	tests := []struct {
+		name               string
+		user, policy, repo string
+		want               string
+	}{
+		{name: "none"},
+		{name: "the repo's", repo: "node:22", want: "node:22"},
+		{name: "the policy's over the repo's", policy: "golang:1", repo: "node:22", want: "golang:1"},
+		{name: "the user's over both", user: "debian", policy: "golang:1", repo: "node:22", want: "debian"},
+		{name: "the user's, though the repo sets none", user: "debian", want: "debian"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewManager(t.TempDir(), 30*time.Second, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.team.Policy.SandboxImage = tt.policy
+			m.SetSandboxImage(tt.user)
+			if got := m.sandboxImageFor(RepoConfig{SandboxImage: tt.repo}); got != tt.want {
+				t.Errorf("sandboxImageFor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
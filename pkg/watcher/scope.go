@@ -0,0 +1,139 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Monorepo issues can be scoped to one directory. A repo maps area names
+// to directories in its config:
+//
+//	{"areas": {"frontend": "apps/web", "api": "services/api"}}
+//
+// and an issue picks an area with an "area:frontend" label or an issue
+// form field headed "### Area". A scoped issue's worktree sparse-checks out
+// only that directory (plus top-level files and .lurker), the prompt tells
+// Claude to stay inside it, and Edit/Write are limited to it.
+
+// areaLabelPrefix marks labels that name an area.
+const areaLabelPrefix = "area:"
+
+// Scope returns the directory issue is confined to, or "" for the whole
+// repo. Area labels win over the issue form field. Unknown areas and
+// unsafe paths are ignored, and so are labels naming more than one area.
+func (c RepoConfig) Scope(issue Issue) string {
+	if len(c.Areas) == 0 {
+		return ""
+	}
+	var scope string
+	for _, l := range issue.Labels {
+		name, ok := strings.CutPrefix(l.Name, areaLabelPrefix)
+		if !ok {
+			continue
+		}
+		dir, ok := c.areaDir(name)
+		if !ok {
+			continue
+		}
+		if scope != "" && scope != dir {
+			return ""
+		}
+		scope = dir
+	}
+	if scope != "" {
+		return scope
+	}
+	if dir, ok := c.areaDir(issueFormField(issue.Body, "Area")); ok {
+		return dir
+	}
+	return ""
+}
+
+// areaDir looks up an area, case-insensitively.
+func (c RepoConfig) areaDir(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", false
+	}
+	for area, dir := range c.Areas {
+		if strings.EqualFold(area, name) {
+			dir, ok := cleanScope(dir)
+			return dir, ok
+		}
+	}
+	return "", false
+}
+
+// cleanScope normalizes a repo-relative directory, rejecting anything that
+// would escape the repo or break the tool list (commas, parentheses, globs).
+func cleanScope(dir string) (string, bool) {
+	dir = path.Clean(strings.TrimSpace(dir))
+	switch {
+	case dir == "." || dir == "..", strings.HasPrefix(dir, "/"), strings.HasPrefix(dir, "../"),
+		strings.HasPrefix(dir, "-"), strings.ContainsAny(dir, ",()*?[]\\ \t\n"):
+		return "", false
+	}
+	return dir, true
+}
+
+// issueFormField returns the answer under "### heading" in an issue
+// created from a GitHub issue form, or "" if there is none.
+func issueFormField(body, heading string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		h, ok := strings.CutPrefix(strings.TrimSpace(line), "### ")
+		if !ok || !strings.EqualFold(strings.TrimSpace(h), heading) {
+			continue
+		}
+		for _, answer := range lines[i+1:] {
+			answer = strings.TrimSpace(answer)
+			switch {
+			case answer == "":
+				continue
+			case strings.HasPrefix(answer, "### "), answer == "_No response_":
+				return ""
+			}
+			return answer
+		}
+	}
+	return ""
+}
+
+// scopedTools limits the file-writing tools in tools to scope. Tools that
+// already carry a pattern are left alone.
+func scopedTools(tools, scope string) string {
+	if scope == "" {
+		return tools
+	}
+	parts := strings.Split(tools, ",")
+	for i, tool := range parts {
+		switch strings.TrimSpace(tool) {
+		case "Edit", "Write", "MultiEdit", "NotebookEdit":
+			parts[i] = fmt.Sprintf("%s(%s/**)", strings.TrimSpace(tool), scope)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// scopePrompt is appended to the prompt of a scoped issue.
+func scopePrompt(scope string) string {
+	return fmt.Sprintf(`
+
+## Scope
+This issue is scoped to %[1]s/. Only that directory, the top-level files and
+.lurker/ are checked out. Make your changes inside %[1]s/ and do not try to
+check out or edit other parts of the repository.`, scope)
+}
+
+// loadRepoConfigAt reads .lurker/config.json as of rev in a git repo, for
+// when the config is needed before a worktree exists.
+func loadRepoConfigAt(ctx context.Context, gitDir, rev string) RepoConfig {
+	data, err := exec.CommandContext(ctx, "git", "-C", gitDir, "show", rev+":.lurker/config.json").Output()
+	if err != nil {
+		return RepoConfig{}
+	}
+	return parseRepoConfig(data)
+}
@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepoConfig_Scope(t *testing.T) {
+	cfg := RepoConfig{Areas: map[string]string{
+		"frontend": "apps/web/",
+		"api":      "services/api",
+		"escape":   "../other",
+		"abs":      "/etc",
+		"globby":   "apps/*",
+	}}
+	label := func(names ...string) []Label {
+		var ls []Label
+		for _, n := range names {
+			ls = append(ls, Label{Name: n})
+		}
+		return ls
+	}
+	form := "### Area\n\napi\n\n### Description\n\nIt breaks"
+
+	tests := []struct {
+		name  string
+		issue Issue
+		want  string
+	}{
+		{"no area", Issue{Labels: label("bug")}, ""},
+		{"label", Issue{Labels: label("bug", "area:frontend")}, "apps/web"},
+		{"label case", Issue{Labels: label("area:Frontend")}, "apps/web"},
+		{"form field", Issue{Body: form}, "services/api"},
+		{"label beats form", Issue{Labels: label("area:frontend"), Body: form}, "apps/web"},
+		{"two areas", Issue{Labels: label("area:frontend", "area:api")}, ""},
+		{"unknown area", Issue{Labels: label("area:mobile")}, ""},
+		{"no response", Issue{Body: "### Area\n\n_No response_\n"}, ""},
+		{"escaping path", Issue{Labels: label("area:escape")}, ""},
+		{"absolute path", Issue{Labels: label("area:abs")}, ""},
+		{"glob path", Issue{Labels: label("area:globby")}, ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.Scope(tt.issue); got != tt.want {
+			t.Errorf("%s: Scope = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := (RepoConfig{}).Scope(Issue{Labels: label("area:frontend")}); got != "" {
+		t.Errorf("Scope without areas = %q", got)
+	}
+}
+
+func TestScopedTools(t *testing.T) {
+	got := scopedTools("Read,Edit,Write,Edit(docs/**),Bash(git add:*)", "apps/web")
+	want := "Read,Edit(apps/web/**),Write(apps/web/**),Edit(docs/**),Bash(git add:*)"
+	if got != want {
+		t.Errorf("scopedTools = %q, want %q", got, want)
+	}
+	if got := scopedTools(claudeTools, ""); got != claudeTools {
+		t.Errorf("unscoped tools changed: %q", got)
+	}
+	if p := scopePrompt("apps/web"); !strings.Contains(p, "apps/web/") {
+		t.Errorf("scopePrompt = %q", p)
+	}
+}
+
+func TestLoadRepoConfigAt(t *testing.T) {
+	src := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", src}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	os.MkdirAll(filepath.Join(src, ".lurker"), 0o755)
+	os.WriteFile(filepath.Join(src, ".lurker", "config.json"), []byte(`{"areas": {"web": "apps/web"}}`), 0o644)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	cfg := loadRepoConfigAt(context.Background(), src, "main")
+	if cfg.Areas["web"] != "apps/web" {
+		t.Errorf("config at main = %+v", cfg)
+	}
+	if cfg := loadRepoConfigAt(context.Background(), src, "nope"); cfg.Areas != nil {
+		t.Errorf("config at missing rev = %+v", cfg)
+	}
+}
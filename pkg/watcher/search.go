@@ -0,0 +1,264 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFile is the name of the log lurker keeps for each issue in its
+// IssueDir, one "<RFC3339 timestamp>\t<text>" entry per line.
+const LogFile = "lurker.log"
+
+// ParseLogLine splits a lurker.log line into its timestamp and text.
+// Lines without a timestamp prefix (older logs) get a zero time. Escape
+// sequences that older logs kept are stripped.
+func ParseLogLine(raw string) (time.Time, string) {
+	if stamp, text, ok := strings.Cut(raw, "\t"); ok {
+		if t, err := time.Parse(time.RFC3339, stamp); err == nil {
+			return t, StripANSI(text)
+		}
+	}
+	return time.Time{}, StripANSI(raw)
+}
+
+// SearchQuery selects log and transcript lines across every issue.
+type SearchQuery struct {
+	Pattern      *regexp.Regexp
+	Repo         string    // "" matches every repo
+	Number       int       // 0 matches every issue
+	Since, Until time.Time // zero leaves that end open; either excludes undated lines
+	Transcripts  bool      // also search Claude session transcripts
+	Limit        int       // newest matches kept; 0 keeps all
+}
+
+// SearchMatch is one matching line.
+type SearchMatch struct {
+	Repo   string
+	Number int
+	Source string // "log" or "transcript"
+	At     time.Time
+	Line   string
+}
+
+// Search greps the lurker.log of every issue under baseDir (including
+// repos no longer watched whose files were kept) and, if asked, their
+// Claude transcripts. Matches are returned newest first.
+func Search(baseDir string, q SearchQuery) ([]SearchMatch, error) {
+	logs, err := issueLogs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []SearchMatch
+	for _, path := range logs {
+		issueDir := filepath.Dir(path)
+		num, err := strconv.Atoi(filepath.Base(issueDir))
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(baseDir, issueDir)
+		if err != nil {
+			continue
+		}
+		repo := filepath.ToSlash(filepath.Dir(rel))
+		if q.Repo != "" && repo != q.Repo || q.Number != 0 && num != q.Number {
+			continue
+		}
+
+		found, err := searchLog(path, q)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if q.Transcripts {
+			t, err := searchTranscripts(IssueWorkdir(baseDir, repo, num), q)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, t...)
+		}
+		for i := range found {
+			found[i].Repo, found[i].Number = repo, num
+		}
+		matches = append(matches, found...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].At.After(matches[j].At) })
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+	}
+	return matches, nil
+}
+
+// issueLogs finds the LogFile of every issue under baseDir, however deep
+// its repo's path is: owner/repo, or group/sub/repo on GitLab. Issue
+// directories, with their worktrees, and bare clones aren't descended
+// into; unreadable directories are passed over, as Glob would.
+func issueLogs(baseDir string) ([]string, error) {
+	var logs []string
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == baseDir {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".git") {
+			return filepath.SkipDir
+		}
+		if num, err := strconv.Atoi(name); err != nil || num <= 0 {
+			return nil
+		}
+		// A numbered directory two or more deep is an issue's if lurker
+		// wrote to it; otherwise it is part of a repo's path.
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil || !strings.Contains(filepath.ToSlash(filepath.Dir(rel)), "/") {
+			return nil
+		}
+		log := filepath.Join(path, LogFile)
+		_, logErr := os.Stat(log)
+		if logErr == nil {
+			logs = append(logs, log)
+		}
+		if _, err := os.Stat(filepath.Join(path, "issue.json")); logErr == nil || err == nil {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return logs, err
+}
+
+// inRange reports whether t is within the query's dates.
+func (q SearchQuery) inRange(t time.Time) bool {
+	if q.Since.IsZero() && q.Until.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	return (q.Since.IsZero() || !t.Before(q.Since)) && (q.Until.IsZero() || t.Before(q.Until))
+}
+
+func searchLog(path string, q SearchQuery) ([]SearchMatch, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var found []SearchMatch
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		at, text := ParseLogLine(scanner.Text())
+		if q.inRange(at) && q.Pattern.MatchString(text) {
+			found = append(found, SearchMatch{Source: "log", At: at, Line: strings.TrimSpace(text)})
+		}
+	}
+	return found, scanner.Err()
+}
+
+// searchTranscripts greps every Claude session run in workdir, rendered
+// the way the transcript tab shows it.
+func searchTranscripts(workdir string, q SearchQuery) ([]SearchMatch, error) {
+	dir, err := claudeProjectDir(workdir)
+	if err != nil {
+		return nil, err
+	}
+	sessions, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	var found []SearchMatch
+	for _, path := range sessions {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var entry struct {
+				Timestamp time.Time `json:"timestamp"`
+			}
+			json.Unmarshal(scanner.Bytes(), &entry)
+			if !q.inRange(entry.Timestamp) {
+				continue
+			}
+			for _, line := range formatStreamEvent(scanner.Text()) {
+				if q.Pattern.MatchString(line) {
+					found = append(found, SearchMatch{Source: "transcript", At: entry.Timestamp, Line: line})
+				}
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return found, nil
+}
+
+// ParseSearchQuery parses the search box's syntax: "repo:owner/name",
+// "#42", "since:30d" (or a date), "until:2026-03-01" and the remaining
+// words, matched literally and case-insensitively. Transcripts are
+// included.
+func ParseSearchQuery(s string, now time.Time) (SearchQuery, error) {
+	q := SearchQuery{Transcripts: true}
+	var words []string
+	for _, tok := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(tok, "repo:"):
+			q.Repo = strings.TrimPrefix(tok, "repo:")
+		case strings.HasPrefix(tok, "#") && len(tok) > 1:
+			n, err := strconv.Atoi(tok[1:])
+			if err != nil {
+				words = append(words, tok)
+				continue
+			}
+			q.Number = n
+		case strings.HasPrefix(tok, "since:"):
+			t, err := ParseSearchTime(strings.TrimPrefix(tok, "since:"), now)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			q.Since = t
+		case strings.HasPrefix(tok, "until:"):
+			t, err := ParseSearchTime(strings.TrimPrefix(tok, "until:"), now)
+			if err != nil {
+				return SearchQuery{}, err
+			}
+			q.Until = t
+		default:
+			words = append(words, tok)
+		}
+	}
+	if len(words) == 0 {
+		return SearchQuery{}, fmt.Errorf("nothing to search for")
+	}
+	q.Pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(strings.Join(words, " ")))
+	return q, nil
+}
+
+// ParseSearchTime reads a date (2026-03-01), an RFC 3339 time, or an age
+// ago from now: a Go duration (36h) or whole days (30d).
+func ParseSearchTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want 2026-03-01, 30d or 36h)", s)
+}
@@ -0,0 +1,129 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	at, text := ParseLogLine("2026-03-01T10:00:00Z\tEdited config.yaml")
+	if !at.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) || text != "Edited config.yaml" {
+		t.Errorf("got %v %q", at, text)
+	}
+	if at, text := ParseLogLine("old line\twith a tab"); !at.IsZero() || text != "old line\twith a tab" {
+		t.Errorf("untimestamped line: got %v %q", at, text)
+	}
+	if _, text := ParseLogLine("2026-03-01T10:00:00Z\t\x1b[31mFAIL\x1b[0m TestFoo"); text != "FAIL TestFoo" {
+		t.Errorf("colored line: got %q", text)
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	q, err := ParseSearchQuery("repo:a/one #42 since:30d until:2026-03-15 Config.yaml edit", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Repo != "a/one" || q.Number != 42 || !q.Transcripts {
+		t.Errorf("query = %+v", q)
+	}
+	if !q.Since.Equal(now.AddDate(0, 0, -30)) {
+		t.Errorf("since = %v", q.Since)
+	}
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local); !q.Until.Equal(want) {
+		t.Errorf("until = %v, want %v", q.Until, want)
+	}
+	if !q.Pattern.MatchString("edited config.yaml edit") || q.Pattern.MatchString("configxyaml edit") {
+		t.Errorf("pattern %q should match the words literally", q.Pattern)
+	}
+
+	for _, bad := range []string{"", "repo:a/one", "since:yesterday foo"} {
+		if _, err := ParseSearchQuery(bad, now); err == nil {
+			t.Errorf("ParseSearchQuery(%q): expected error", bad)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	base := t.TempDir()
+	writeLog := func(repo string, num int, lines string) {
+		dir := IssueDir(base, repo, num)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, LogFile), []byte(lines), 0o644)
+	}
+	writeLog("a/one", 1, "2026-02-10T09:00:00Z\tEdited config.yaml\n2026-02-10T09:05:00Z\tRunning tests\n")
+	writeLog("a/one", 2, "2026-03-20T09:00:00Z\tEdited config.yaml again\nundated config.yaml line\n")
+	writeLog("b/two", 3, "2026-02-15T09:00:00Z\tRead config.yaml\n")
+	writeLog("g/sub/three", 4, "2026-01-05T09:00:00Z\tRead config.yaml in a subgroup\n")
+	// A log in an issue's worktree is the repo's, not lurker's.
+	writeLog("b/two/3/two/logs", 5, "2026-04-01T09:00:00Z\tconfig.yaml\n")
+
+	cfg := regexp.MustCompile(`config\.yaml`)
+	matches, err := Search(base, SearchQuery{Pattern: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 5 {
+		t.Fatalf("matches = %+v, want 5", matches)
+	}
+	if m := matches[0]; m.Repo != "a/one" || m.Number != 2 || m.Source != "log" || m.Line != "Edited config.yaml again" {
+		t.Errorf("newest match = %+v", m)
+	}
+	if m := matches[3]; m.Repo != "g/sub/three" || m.Number != 4 {
+		t.Errorf("subgroup match = %+v", m)
+	}
+	if m := matches[4]; !m.At.IsZero() {
+		t.Errorf("undated match should sort last, got %+v", m)
+	}
+
+	feb := SearchQuery{
+		Pattern: cfg,
+		Since:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if matches, _ := Search(base, feb); len(matches) != 2 || matches[0].Repo != "b/two" || matches[1].Number != 1 {
+		t.Errorf("february = %+v", matches)
+	}
+	feb.Repo = "a/one"
+	if matches, _ := Search(base, feb); len(matches) != 1 || matches[0].Number != 1 {
+		t.Errorf("february in a/one = %+v", matches)
+	}
+	if matches, _ := Search(base, SearchQuery{Pattern: cfg, Number: 3}); len(matches) != 1 || matches[0].Repo != "b/two" {
+		t.Errorf("issue 3 = %+v", matches)
+	}
+	if matches, _ := Search(base, SearchQuery{Pattern: cfg, Limit: 1}); len(matches) != 1 || matches[0].Number != 2 {
+		t.Errorf("limit 1 = %+v", matches)
+	}
+}
+
+func TestSearch_Transcripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	base := t.TempDir()
+	os.MkdirAll(IssueDir(base, "a/one", 1), 0o755)
+	os.WriteFile(filepath.Join(IssueDir(base, "a/one", 1), LogFile), []byte("2026-03-01T09:00:00Z\tStarted\n"), 0o644)
+
+	dir, _ := claudeProjectDir(IssueWorkdir(base, "a/one", 1))
+	os.MkdirAll(dir, 0o755)
+	session := `{"type":"assistant","timestamp":"2026-03-01T09:01:00Z","message":{"content":[{"type":"tool_use","name":"Edit","input":{"file_path":"config.yaml"}}]}}
+{"type":"assistant","timestamp":"2026-03-01T09:02:00Z","message":{"content":[{"type":"text","text":"Done"}]}}
+`
+	os.WriteFile(filepath.Join(dir, "abc.jsonl"), []byte(session), 0o644)
+
+	q := SearchQuery{Pattern: regexp.MustCompile(`config\.yaml`)}
+	if matches, _ := Search(base, q); len(matches) != 0 {
+		t.Errorf("transcripts searched without being asked: %+v", matches)
+	}
+	q.Transcripts = true
+	matches, err := Search(base, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Source != "transcript" || !matches[0].At.Equal(time.Date(2026, 3, 1, 9, 1, 0, 0, time.UTC)) {
+		t.Errorf("matches = %+v", matches)
+	}
+}
@@ -0,0 +1,242 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// SelfTestOptions configures SelfTest.
+type SelfTestOptions struct {
+	Dir  string // scratch directory; everything SelfTest creates lives here
+	Repo string // local git repo to run against; "" uses a built-in fixture
+}
+
+// SelfTestStep is the outcome of one step of SelfTest.
+type SelfTestStep struct {
+	Name   string // fixture, clone, agent, branch, commit, push or pr
+	Detail string
+	Err    error
+}
+
+// selfTestIssue is the issue SelfTest works on.
+var selfTestIssue = Issue{
+	Number: 1,
+	Title:  "Selftest change",
+	Body:   "Add a file saying lurker works.",
+}
+
+// selfTestGH stands in for gh: it clones the source repo instead of the
+// GitHub one and refuses anything else.
+const selfTestGH = `#!/bin/sh
+[ "$1 $2" = "repo clone" ] || { echo "selftest gh: unsupported: $*" >&2; exit 1; }
+dir=$4
+shift 4
+[ "$1" = "--" ] && shift
+exec git clone -q "$@" "$LURKER_SELFTEST_SOURCE" "$dir"
+`
+
+// selfTestClaude stands in for claude: it checks it got a prompt and
+// commits a change, as the agent would.
+const selfTestClaude = `#!/bin/sh
+prompt=$(cat)
+[ -n "$prompt" ] || { echo "selftest claude: empty prompt" >&2; exit 1; }
+echo "lurker selftest: the pipeline works." > LURKER_SELFTEST.md
+git add LURKER_SELFTEST.md && git commit -q -m "Fix #1: selftest change"
+`
+
+// SelfTest runs the issue pipeline end to end, with stand-ins for gh and
+// claude, against opts.Repo or a fixture: it clones, has the mock agent
+// commit on the issue branch, dry-runs the push and builds the PR request.
+// Nothing reaches GitHub. step is called as each step finishes; SelfTest
+// stops at, and returns, the first failure.
+func SelfTest(ctx context.Context, opts SelfTestOptions, step func(SelfTestStep)) error {
+	fail := func(name string, err error) error {
+		step(SelfTestStep{Name: name, Err: err})
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	source, base, err := selfTestSource(ctx, opts)
+	if err != nil {
+		return fail("fixture", err)
+	}
+	step(SelfTestStep{Name: "fixture", Detail: fmt.Sprintf("%s (%s)", source, base)})
+
+	env, err := selfTestEnv(filepath.Join(opts.Dir, "bin"), source)
+	if err != nil {
+		return fail("fixture", err)
+	}
+	mgr, err := NewManager(filepath.Join(opts.Dir, "base"), 0, github.NewOfflineClient())
+	if err != nil {
+		return fail("fixture", err)
+	}
+	repo := "selftest/" + filepath.Base(source)
+	if err := mgr.SetBaseBranch(repo, base); err != nil {
+		return fail("fixture", err)
+	}
+	w := &Watcher{
+		cfg:      Config{Repo: repo, BaseDir: mgr.BaseDir()},
+		manager:  mgr,
+		ghClient: mgr.ghClient,
+		env:      env,
+	}
+
+	// Run the pipeline, keeping its events to tell which step failed
+	eventCh := make(chan Event)
+	done := make(chan []Event)
+	go func() {
+		var events []Event
+		for ev := range eventCh {
+			events = append(events, ev)
+		}
+		done <- events
+	}()
+	w.processIssue(ctx, eventCh, selfTestIssue)
+	close(eventCh)
+	events := <-done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	workdir := IssueWorkdir(mgr.BaseDir(), repo, selfTestIssue.Number)
+	var cloned, ready bool
+	var failure string
+	for _, ev := range events {
+		switch ev.Kind {
+		case EventCloneDone:
+			cloned = true
+		case EventReady:
+			ready = true
+		case EventError, EventNeedsInput, EventBuildFailed, EventTestsFailed:
+			if failure == "" {
+				failure = ev.Text
+			}
+		}
+	}
+	if !cloned {
+		return fail("clone", errors.New(failure))
+	}
+	step(SelfTestStep{Name: "clone", Detail: workdir})
+	if !ready {
+		if failure == "" {
+			failure = "the pipeline stopped before the issue was ready"
+		}
+		return fail("agent", errors.New(failure))
+	}
+	step(SelfTestStep{Name: "agent", Detail: "the mock agent ran and finished"})
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = workdir
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	branch, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err == nil && branch != IssueBranch(selfTestIssue.Number) {
+		err = fmt.Errorf("worktree is on %s, want %s", branch, IssueBranch(selfTestIssue.Number))
+	}
+	if err != nil {
+		return fail("branch", err)
+	}
+	step(SelfTestStep{Name: "branch", Detail: branch})
+
+	commits, err := git("log", "--format=%h %s", base+"..HEAD")
+	if err == nil && commits == "" {
+		err = fmt.Errorf("no commits on %s over %s", branch, base)
+	}
+	if err != nil {
+		return fail("commit", err)
+	}
+	step(SelfTestStep{Name: "commit", Detail: strings.ReplaceAll(commits, "\n", "; ")})
+
+	if _, err := git("push", "--dry-run", "-u", "origin", "HEAD"); err != nil {
+		return fail("push", err)
+	}
+	step(SelfTestStep{Name: "push", Detail: "git push --dry-run origin " + branch})
+
+	payload, err := github.CreatePRRequest{
+		Repo:  repo,
+		Title: fmt.Sprintf("Fix #%d: %s", selfTestIssue.Number, selfTestIssue.Title),
+		Body:  fmt.Sprintf("Fixes #%d\n\n## Commits\n```\n%s\n```", selfTestIssue.Number, commits),
+		Head:  branch,
+		Base:  base,
+	}.Payload()
+	if err == nil {
+		var decoded struct{ Head, Base string }
+		if err = json.Unmarshal(payload, &decoded); err == nil && (decoded.Head != branch || decoded.Base != base) {
+			err = fmt.Errorf("payload asks to merge %s into %s", decoded.Head, decoded.Base)
+		}
+	}
+	if err != nil {
+		return fail("pr", err)
+	}
+	step(SelfTestStep{Name: "pr", Detail: fmt.Sprintf("%d-byte request to merge %s into %s", len(payload), branch, base)})
+	return nil
+}
+
+// selfTestSource returns the repo SelfTest clones and its base branch,
+// creating the fixture if opts names no repo.
+func selfTestSource(ctx context.Context, opts SelfTestOptions) (string, string, error) {
+	if opts.Repo != "" {
+		source, err := filepath.Abs(opts.Repo)
+		if err != nil {
+			return "", "", err
+		}
+		out, err := exec.CommandContext(ctx, "git", "-C", source, "symbolic-ref", "--short", "HEAD").Output()
+		if err != nil {
+			return "", "", fmt.Errorf("%s is not a git repo on a branch", source)
+		}
+		return source, strings.TrimSpace(string(out)), nil
+	}
+
+	source := filepath.Join(opts.Dir, "fixture")
+	if err := os.MkdirAll(source, 0o755); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(filepath.Join(source, "README.md"), []byte("# fixture\n\nA repo for lurker selftest.\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", defaultBaseBranch},
+		{"add", "README.md"},
+		{"-c", "user.name=lurker", "-c", "user.email=lurker@localhost", "commit", "-q", "-m", "Initial commit"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = source
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(string(out)), err)
+		}
+	}
+	return source, defaultBaseBranch, nil
+}
+
+// selfTestEnv writes the gh and claude stand-ins to bin and returns an
+// environment that finds them first and commits as lurker.
+func selfTestEnv(bin, source string) ([]string, error) {
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		return nil, err
+	}
+	for name, script := range map[string]string{"gh": selfTestGH, "claude": selfTestClaude} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return append(os.Environ(),
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"LURKER_SELFTEST_SOURCE="+source,
+		"GIT_AUTHOR_NAME=lurker", "GIT_AUTHOR_EMAIL=lurker@localhost",
+		"GIT_COMMITTER_NAME=lurker", "GIT_COMMITTER_EMAIL=lurker@localhost",
+	), nil
+}
@@ -0,0 +1,40 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	var steps []string
+	err := SelfTest(context.Background(), SelfTestOptions{Dir: t.TempDir()}, func(s SelfTestStep) {
+		if s.Err != nil {
+			t.Logf("%s: %v", s.Name, s.Err)
+		}
+		steps = append(steps, s.Name)
+	})
+	if err != nil {
+		t.Fatalf("SelfTest: %v", err)
+	}
+	want := []string{"fixture", "clone", "agent", "branch", "commit", "push", "pr"}
+	if len(steps) != len(want) {
+		t.Fatalf("steps = %v, want %v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d = %s, want %s", i, steps[i], want[i])
+		}
+	}
+}
+
+func TestSelfTest_NotARepo(t *testing.T) {
+	var failed string
+	err := SelfTest(context.Background(), SelfTestOptions{Dir: t.TempDir(), Repo: t.TempDir()}, func(s SelfTestStep) {
+		if s.Err != nil {
+			failed = s.Name
+		}
+	})
+	if err == nil || failed != "fixture" {
+		t.Errorf("SelfTest on a plain dir = %v, failed step %q", err, failed)
+	}
+}
@@ -0,0 +1,67 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Snapshot is a read-only view of one lurker instance, built from its
+// BaseDir alone (state.json, wherever its Layout keeps it, plus each
+// issue's issue.json). It never takes locks or writes, so it is safe to
+// read while that instance is running.
+type Snapshot struct {
+	BaseDir string
+	Repos   []string
+	Issues  []IssueMeta // sorted by repo, then number
+	Updated time.Time   // latest issue.json update
+}
+
+// LoadSnapshot reads the state of the lurker instance rooted at baseDir.
+func LoadSnapshot(baseDir string) (Snapshot, error) {
+	statePath := LoadLayout(baseDir).StatePath()
+	if _, err := os.Stat(statePath); err != nil {
+		return Snapshot{}, fmt.Errorf("not a lurker directory: %w", err)
+	}
+	state := loadState(statePath)
+
+	snap := Snapshot{BaseDir: baseDir, Repos: state.Repos}
+	for _, repo := range state.Repos {
+		matches, _ := filepath.Glob(filepath.Join(baseDir, repo, "*", "issue.json"))
+		for _, path := range matches {
+			var num int
+			if _, err := fmt.Sscanf(filepath.Base(filepath.Dir(path)), "%d", &num); err != nil {
+				continue
+			}
+			meta, err := ReadIssueMeta(baseDir, repo, num)
+			if err != nil {
+				continue
+			}
+			snap.Issues = append(snap.Issues, meta)
+			if meta.UpdatedAt.After(snap.Updated) {
+				snap.Updated = meta.UpdatedAt
+			}
+		}
+	}
+	sort.Slice(snap.Issues, func(i, j int) bool {
+		a, b := snap.Issues[i], snap.Issues[j]
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	})
+	return snap, nil
+}
+
+// Count returns how many issues in the snapshot have the given status.
+func (s Snapshot) Count(status IssueStatus) int {
+	n := 0
+	for _, iss := range s.Issues {
+		if iss.Status == status.String() {
+			n++
+		}
+	}
+	return n
+}
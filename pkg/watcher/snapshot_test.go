@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	mgr.AddRepo("owner/repo")
+	mgr.Stop()
+
+	for _, iss := range []TrackedIssue{
+		{Repo: "owner/repo", Number: 9, Status: StatusReady},
+		{Repo: "owner/repo", Number: 3, Status: StatusFailed},
+	} {
+		os.MkdirAll(IssueDir(dir, iss.Repo, iss.Number), 0o755)
+		if err := WriteIssueMeta(dir, iss); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An issue dir without metadata (older lurker) is skipped.
+	os.MkdirAll(IssueDir(dir, "owner/repo", 5), 0o755)
+
+	snap, err := LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if len(snap.Repos) != 1 || len(snap.Issues) != 2 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	if snap.Issues[0].Number != 3 || snap.Issues[1].Number != 9 {
+		t.Errorf("issues not sorted: %d, %d", snap.Issues[0].Number, snap.Issues[1].Number)
+	}
+	if snap.Count(StatusReady) != 1 || snap.Count(StatusFailed) != 1 {
+		t.Errorf("counts: ready=%d failed=%d", snap.Count(StatusReady), snap.Count(StatusFailed))
+	}
+	if snap.Updated.IsZero() {
+		t.Error("Updated not set")
+	}
+
+	if _, err := LoadSnapshot(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without state.json")
+	}
+}
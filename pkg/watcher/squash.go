@@ -0,0 +1,152 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// DefaultSquashMessage is the commit message a squashed branch gets when
+// the repo doesn't configure squash_message: a conventional commit
+// listing the commits it replaces.
+const DefaultSquashMessage = `{{.Type}}: {{.Title}}
+{{if gt (len .Commits) 1}}
+{{range .Commits}}- {{.}}
+{{end}}{{end}}
+Fixes #{{.Number}}
+`
+
+// SquashData is passed to RepoConfig.SquashMessage.
+type SquashData struct {
+	Number  int
+	Title   string   // the issue's
+	Type    string   // conventional commit type: "feat" for issues labeled as features, else "fix"
+	Commits []string // subjects of the commits squashed, oldest first
+}
+
+// Squash is what squashing an issue's branch did.
+type Squash struct {
+	Commits  int    // commits squashed into one
+	Subject  string // of the new commit
+	Pushed   bool   // the squashed branch was force-pushed
+	Unpushed bool   // it was pushed before, but allow_push kept it from being pushed again
+}
+
+// String describes s for the log.
+func (s Squash) String() string {
+	what := fmt.Sprintf("Squashed %s into %q", plural(s.Commits, "commit"), s.Subject)
+	if s.Commits == 1 {
+		what = fmt.Sprintf("Reworded the commit as %q", s.Subject)
+	}
+	if s.Pushed {
+		what += " and pushed"
+	}
+	if s.Unpushed {
+		what += ", not pushed (allow_push off)"
+	}
+	return what
+}
+
+// commitType picks the conventional commit type for an issue labeled
+// labels (as Issue.LabelNames joins them).
+func commitType(labels string) string {
+	for _, l := range strings.Split(strings.ToLower(labels), ",") {
+		switch strings.TrimSpace(l) {
+		case "enhancement", "feature", "feature request", "type: feature":
+			return "feat"
+		case "documentation", "docs":
+			return "docs"
+		}
+	}
+	return "fix"
+}
+
+// squashMessage renders message, or DefaultSquashMessage if it is "".
+func squashMessage(message string, d SquashData) (string, error) {
+	if message == "" {
+		message = DefaultSquashMessage
+	}
+	tmpl, err := template.New("squash_message").Parse(message)
+	if err != nil {
+		return "", fmt.Errorf("squash_message: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, d); err != nil {
+		return "", fmt.Errorf("squash_message: %w", err)
+	}
+	msg := strings.TrimSpace(b.String())
+	if msg == "" {
+		return "", errors.New("squash_message: the message is empty")
+	}
+	return msg + "\n", nil
+}
+
+// SquashBranch replaces the commits workdir's branch has on top of base
+// with one commit whose message is message rendered with d, leaving
+// uncommitted changes alone. A single commit is just reworded. A branch
+// that was pushed is force-pushed once squashed if push is set.
+func SquashBranch(ctx context.Context, workdir, base, message string, d SquashData, push bool) (Squash, error) {
+	start, err := gitCmd(ctx, workdir, nil, "merge-base", "origin/"+base, "HEAD")
+	if err != nil {
+		return Squash{}, err
+	}
+	subjects, err := gitCmd(ctx, workdir, nil, "log", "--reverse", "--format=%s", start+"..HEAD")
+	if err != nil {
+		return Squash{}, err
+	}
+	if subjects == "" {
+		return Squash{}, fmt.Errorf("no commits on top of %s", base)
+	}
+	d.Commits = strings.Split(subjects, "\n")
+	msg, err := squashMessage(message, d)
+	if err != nil {
+		return Squash{}, err
+	}
+	s := Squash{Commits: len(d.Commits)}
+	s.Subject, _, _ = strings.Cut(msg, "\n")
+
+	f, err := os.CreateTemp("", "lurker-squash-*")
+	if err != nil {
+		return s, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(msg)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return s, err
+	}
+	// The new commit has HEAD's tree, so moving the branch to it leaves
+	// the index and working tree, and any changes in them, as they are.
+	sha, err := gitCmd(ctx, workdir, nil, "commit-tree", "HEAD^{tree}", "-p", start, "-F", f.Name())
+	if err != nil {
+		return s, err
+	}
+	if _, err := gitCmd(ctx, workdir, nil, "reset", "--soft", sha); err != nil {
+		return s, err
+	}
+	s.Pushed, s.Unpushed, err = pushRebased(ctx, workdir, push)
+	return s, err
+}
+
+// SquashBranch squashes repo#num's branch into one commit, with the
+// repo's squash_message, for the issue titled title and labeled labels.
+// The worktree is checkpointed first so the squash can be undone. The
+// issue must not be running.
+func (m *Manager) SquashBranch(repo string, num int, title, labels string) (Squash, error) {
+	workdir := existingDir(IssueWorkdir(m.baseDir, repo, num))
+	if workdir == "" {
+		return Squash{}, errors.New("no worktree yet")
+	}
+	ctx := context.Background()
+	base := m.BaseBranch(repo, workdir)
+	if _, err := CreateCheckpoint(ctx, IssueDir(m.baseDir, repo, num), workdir, base, "before squash"); err != nil {
+		return Squash{}, fmt.Errorf("checkpoint: %w", err)
+	}
+	d := SquashData{Number: num, Title: title, Type: commitType(labels)}
+	return SquashBranch(ctx, workdir, base, m.RepoConfig(workdir).SquashMessage, d, allowPush(m.ghClient))
+}
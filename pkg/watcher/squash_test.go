@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSquashBranch(t *testing.T) {
+	work, _ := rebaseRepos(t)
+	writeAndCommit(t, work, "c.txt", "fix\n")
+	os.WriteFile(filepath.Join(work, "d.txt"), []byte("uncommitted\n"), 0o644)
+
+	s, err := SquashBranch(context.Background(), work, "main", "", SquashData{Number: 4, Title: "Handle empty input", Type: commitType("bug, Enhancement")}, true)
+	if err != nil {
+		t.Fatalf("SquashBranch: %v", err)
+	}
+	if s.Commits != 2 || s.Subject != "feat: Handle empty input" || !s.Pushed {
+		t.Errorf("squash = %+v", s)
+	}
+	msg := gitIn(t, work, "log", "-1", "--format=%B")
+	if want := "feat: Handle empty input\n\n- edit b.txt\n- edit c.txt\n\nFixes #4"; strings.TrimSpace(msg) != want {
+		t.Errorf("message = %q, want %q", msg, want)
+	}
+	if n := gitIn(t, work, "rev-list", "--count", "origin/main..HEAD"); strings.TrimSpace(n) != "1" {
+		t.Errorf("%s commits on top of main", n)
+	}
+	if files := gitIn(t, work, "show", "--format=", "--name-only", "HEAD"); strings.Fields(files)[0] != "b.txt" || len(strings.Fields(files)) != 2 {
+		t.Errorf("squashed commit has %q", files)
+	}
+	if _, err := os.Stat(filepath.Join(work, "d.txt")); err != nil {
+		t.Errorf("uncommitted file: %v", err)
+	}
+	if remote := gitIn(t, work, "rev-parse", "origin/work"); remote != gitIn(t, work, "rev-parse", "HEAD") {
+		t.Error("the squashed branch wasn't pushed")
+	}
+
+	// A single commit is reworded with the configured message.
+	s, err = SquashBranch(context.Background(), work, "main", "{{.Type}}(#{{.Number}}): {{.Title}}", SquashData{Number: 4, Title: "Empty input", Type: "fix"}, true)
+	if err != nil || s.Commits != 1 || s.Subject != "fix(#4): Empty input" {
+		t.Errorf("reword = %+v, %v", s, err)
+	}
+}
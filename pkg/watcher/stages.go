@@ -0,0 +1,239 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// A Stage is a step of the pipeline a finished run's branch goes through
+// before it is marked ready (see RepoConfig.Pipeline): one of lurker's
+// built-in stages, or a command of the repo's own. The steps every run
+// takes first, StageClone and StageClaude, may be listed too, to gate
+// them.
+type Stage struct {
+	Name string `json:"name"`
+	// Run is a shell command run in the worktree; if it fails, the
+	// branch isn't marked ready and the issue is marked failed. Without
+	// it, Name is a built-in stage.
+	Run string `json:"run,omitempty"`
+	// Gate has the run wait once the stage is done, as StatusWaiting,
+	// until someone lets it go on (see Manager.ContinueIssue).
+	Gate bool `json:"gate,omitempty"`
+}
+
+// The built-in stages, which the settings beside them turn on in the
+// default pipeline, in this order.
+const (
+	StageRebase = "rebase" // auto_rebase
+	StageFormat = "format" // format_command
+	StageLint   = "lint"   // lint_command, unless verify runs it
+	StageVerify = "verify" // verify
+	StageBuild  = "build"  // require_build
+	StageTest   = "test"   // require_tests
+
+	// The steps before the pipeline, which can only be gated.
+	StageClone  = "clone"
+	StageClaude = "claude"
+)
+
+// Pipeline returns the stages of c's finished runs: its "stages" if it
+// lists any besides StageClone and StageClaude, else those its settings
+// turn on.
+func (c RepoConfig) Pipeline() []Stage {
+	var stages []Stage
+	for _, s := range c.Stages {
+		if s.Run != "" || (s.Name != StageClone && s.Name != StageClaude) {
+			stages = append(stages, s)
+		}
+	}
+	if len(stages) > 0 {
+		return stages
+	}
+	add := func(on bool, name string) {
+		if on {
+			stages = append(stages, Stage{Name: name})
+		}
+	}
+	add(c.AutoRebase, StageRebase)
+	add(c.FormatCommand != "", StageFormat)
+	add(c.LintCommand != "" && !c.Verify, StageLint)
+	add(c.Verify, StageVerify)
+	add(c.RequireBuild, StageBuild)
+	add(c.RequireTests, StageTest)
+	return stages
+}
+
+// gated reports whether c's stages gate the step called name.
+func (c RepoConfig) gated(name string) bool {
+	for _, s := range c.Stages {
+		if s.Name == name && s.Run == "" && s.Gate {
+			return true
+		}
+	}
+	return false
+}
+
+// StageState is how far an issue's latest run got with a stage of its
+// pipeline.
+type StageState struct {
+	Name  string
+	State ChecksState // pending while the stage runs
+}
+
+// runStages runs cfg's pipeline on a finished run's branch in workdir,
+// announcing each stage and waiting at gated ones, and reports whether the
+// branch may be marked ready: only once every stage passed.
+func (w *Watcher) runStages(ctx context.Context, eventCh chan<- Event, num int, workdir string, cfg RepoConfig) bool {
+	stages := cfg.Pipeline()
+	if len(stages) > 0 {
+		names := make([]string, len(stages))
+		for i, s := range stages {
+			names[i] = s.Name
+		}
+		w.emit(eventCh, EventPipeline, num, strings.Join(names, "\n"))
+	}
+	if cfg.gated(StageClaude) && !w.awaitApproval(ctx, eventCh, num, StageClaude) {
+		return false
+	}
+	for _, s := range stages {
+		w.emit(eventCh, EventStageStarted, num, s.Name)
+		passed := w.runStage(ctx, eventCh, num, workdir, cfg, s)
+		if ctx.Err() != nil {
+			return false
+		}
+		if !passed {
+			w.emit(eventCh, EventStageFailed, num, s.Name)
+			return false
+		}
+		w.emit(eventCh, EventStagePassed, num, s.Name)
+		if s.Gate && !w.awaitApproval(ctx, eventCh, num, s.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// awaitApproval holds a run that is done with the stage after, which the
+// repo gates, until ContinueIssue lets it go on. It reports false if the
+// run was stopped meanwhile.
+func (w *Watcher) awaitApproval(ctx context.Context, eventCh chan<- Event, num int, after string) bool {
+	m, key := w.manager, IssueKey(w.cfg.Repo, num)
+	approved := make(chan struct{})
+	m.mu.Lock()
+	m.waiting[key] = approved
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		if m.waiting[key] == approved {
+			delete(m.waiting, key)
+		}
+		m.mu.Unlock()
+	}()
+
+	w.emit(eventCh, EventGateWaiting, num, after)
+	select {
+	case <-approved:
+		w.emit(eventCh, EventGateApproved, num, after)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ContinueIssue lets repo#num's run go on past the gated stage it waits
+// at, and reports whether it was waiting.
+func (m *Manager) ContinueIssue(repo string, num int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := IssueKey(repo, num)
+	approved, ok := m.waiting[key]
+	if ok {
+		close(approved)
+		delete(m.waiting, key)
+	}
+	return ok
+}
+
+// runStage runs one stage of a pipeline, and reports whether it passed,
+// having emitted why if not. Formatting, linting and verifying only
+// inform, and always pass.
+func (w *Watcher) runStage(ctx context.Context, eventCh chan<- Event, num int, workdir string, cfg RepoConfig, s Stage) bool {
+	if s.Run != "" {
+		return w.gate(ctx, eventCh, num, workdir, gate{command: s.Run, started: EventVerify, passed: EventVerify, failed: EventError})
+	}
+	switch s.Name {
+	case StageRebase:
+		return w.rebase(ctx, eventCh, num, workdir)
+	case StageFormat:
+		w.format(ctx, eventCh, num, workdir, cfg)
+		return true
+	case StageLint:
+		if cfg.LintCommand != "" {
+			w.gate(ctx, eventCh, num, workdir, lintGate(cfg))
+		}
+		return true
+	case StageVerify:
+		w.verify(ctx, eventCh, num, workdir, cfg)
+		return true
+	case StageBuild:
+		return w.gate(ctx, eventCh, num, workdir, buildGate(cfg))
+	case StageTest:
+		return w.gate(ctx, eventCh, num, workdir, testGate(cfg))
+	}
+	w.emit(eventCh, EventError, num, fmt.Sprintf("Unknown stage %q: give it a run command", s.Name))
+	return false
+}
+
+// trackStage applies a pipeline event to its tracked issue's stages.
+func (m *Manager) trackStage(ev Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := IssueKey(ev.Repo, ev.IssueNum)
+	if iss, ok := m.tracked[key]; ok && iss.Status != StatusPaused {
+		iss.Stages = applyStage(iss.Stages, ev)
+		m.tracked[key] = iss
+	}
+}
+
+// applyStage returns stages as the pipeline event ev leaves them, in a
+// new slice: frontends hold on to the old one.
+func applyStage(stages []StageState, ev Event) []StageState {
+	if ev.Kind == EventPipeline {
+		var next []StageState
+		for _, name := range strings.Split(ev.Text, "\n") {
+			next = append(next, StageState{Name: name})
+		}
+		return next
+	}
+	from, to := ChecksPending, ChecksPassed
+	switch ev.Kind {
+	case EventStageStarted:
+		from, to = ChecksNone, ChecksPending
+	case EventStageFailed:
+		to = ChecksFailed
+	}
+	next := append([]StageState(nil), stages...)
+	for i := range next {
+		if next[i].Name == ev.Text && next[i].State == from {
+			next[i].State = to
+			break
+		}
+	}
+	return next
+}
+
+// StatusText is iss.Status as frontends show it: while a finished run's
+// branch goes through its pipeline, or once a command of the repo's own
+// failed it, the stage says more than the status does.
+func (iss TrackedIssue) StatusText() string {
+	for _, s := range iss.Stages {
+		switch {
+		case s.State == ChecksPending && iss.Status == StatusClaudeRunning:
+			return s.Name
+		case s.State == ChecksFailed && iss.Status == StatusFailed:
+			return s.Name + "-failed"
+		}
+	}
+	return iss.Status.String()
+}
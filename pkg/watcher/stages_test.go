@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRepoConfig_Pipeline(t *testing.T) {
+	names := func(stages []Stage) []string {
+		var names []string
+		for _, s := range stages {
+			names = append(names, s.Name)
+		}
+		return names
+	}
+	cfg := RepoConfig{AutoRebase: true, LintCommand: "golint", Verify: true, RequireTests: true}
+	if got, want := names(cfg.Pipeline()), []string{"rebase", "verify", "test"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default pipeline = %v, want %v", got, want)
+	}
+	cfg.Stages = []Stage{{Name: "docs", Run: "make docs"}, {Name: "build"}}
+	if got, want := names(cfg.Pipeline()), []string{"docs", "build"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("configured pipeline = %v, want %v", got, want)
+	}
+}
+
+func TestRunStages(t *testing.T) {
+	workdir := filepath.Join(t.TempDir(), "repo")
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	writeAndCommit(t, workdir, "a.txt", "one\n")
+
+	w := &Watcher{cfg: Config{Repo: "o/r"}}
+	run := func(stages ...Stage) (bool, []Event) {
+		events := make(chan Event, 20)
+		ok := w.runStages(context.Background(), events, 4, workdir, RepoConfig{BuildCommand: "true", Stages: stages})
+		close(events)
+		var got []Event
+		for ev := range events {
+			got = append(got, ev)
+		}
+		return ok, got
+	}
+
+	ok, events := run(Stage{Name: "build"}, Stage{Name: "docs", Run: "echo docs; exit 3"}, Stage{Name: "test"})
+	if ok {
+		t.Error("failing stage let the run be ready")
+	}
+	var stages []StageState
+	for _, ev := range events {
+		switch ev.Kind {
+		case EventPipeline, EventStageStarted, EventStagePassed, EventStageFailed:
+			stages = applyStage(stages, ev)
+		}
+	}
+	want := []StageState{{"build", ChecksPassed}, {"docs", ChecksFailed}, {"test", ChecksNone}}
+	if !reflect.DeepEqual(stages, want) {
+		t.Errorf("stages = %+v, want %+v", stages, want)
+	}
+	if iss := (TrackedIssue{Status: StatusFailed, Stages: stages}); iss.StatusText() != "docs-failed" {
+		t.Errorf("StatusText = %q", iss.StatusText())
+	}
+
+	if ok, events := run(Stage{Name: "lint"}, Stage{Name: "docs", Run: "true"}); !ok || events[len(events)-1].Kind != EventStagePassed {
+		t.Errorf("passing stages: %v, %+v", ok, events)
+	}
+	if ok, events := run(Stage{Name: "typo"}); ok || events[2].Kind != EventError {
+		t.Errorf("unknown stage: %v, %+v", ok, events)
+	}
+}
+
+func TestManager_TracksStages(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.eventCh <- Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: 1, Text: "Crash"}
+	nextEvent(t, mgr)
+	mgr.eventCh <- Event{Kind: EventClaudeStart, Repo: "o/r", IssueNum: 1}
+	nextEvent(t, mgr)
+	nextEvent(t, mgr)
+	for _, ev := range []Event{
+		{Kind: EventPipeline, Text: "build\ntest"},
+		{Kind: EventStageStarted, Text: "build"},
+		{Kind: EventStagePassed, Text: "build"},
+		{Kind: EventStageStarted, Text: "test"},
+	} {
+		ev.Repo, ev.IssueNum = "o/r", 1
+		mgr.eventCh <- ev
+		nextEvent(t, mgr)
+	}
+	iss, _ := mgr.Issue("o/r", 1)
+	if want := []StageState{{"build", ChecksPassed}, {"test", ChecksPending}}; !reflect.DeepEqual(iss.Stages, want) {
+		t.Errorf("stages = %+v, want %+v", iss.Stages, want)
+	}
+	if iss.StatusText() != "test" {
+		t.Errorf("StatusText = %q", iss.StatusText())
+	}
+
+	// Stages outlive a restart in issue.json.
+	var restored TrackedIssue
+	restored.RestoreFromMeta(iss.Meta())
+	if !reflect.DeepEqual(restored.Stages, iss.Stages) {
+		t.Errorf("restored stages = %+v", restored.Stages)
+	}
+	restored.RestoreFromMeta(IssueMeta{Build: "failed"})
+	if want := []StageState{{"build", ChecksFailed}}; !reflect.DeepEqual(restored.Stages, want) {
+		t.Errorf("stages from an old issue.json = %+v", restored.Stages)
+	}
+}
+
+func TestRunStages_Gate(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	w := &Watcher{cfg: Config{Repo: "o/r"}, manager: mgr}
+	cfg := RepoConfig{Stages: []Stage{{Name: "claude", Gate: true}, {Name: "docs", Run: "true", Gate: true}}}
+	if got := cfg.Pipeline(); len(got) != 1 || got[0].Name != "docs" {
+		t.Errorf("Pipeline = %+v", got)
+	}
+
+	events := make(chan Event, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan bool)
+	go func() { done <- w.runStages(ctx, events, 4, t.TempDir(), cfg) }()
+	waitFor := func(kind EventKind) Event {
+		t.Helper()
+		for {
+			select {
+			case ev := <-events:
+				if ev.Kind == kind {
+					return ev
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for event kind %d", kind)
+			}
+		}
+	}
+
+	// Waits after claude, then after docs, each until let go on.
+	for _, after := range []string{"claude", "docs"} {
+		if ev := waitFor(EventGateWaiting); ev.Text != after {
+			t.Errorf("waiting after %q, want %q", ev.Text, after)
+		}
+		if !mgr.ContinueIssue("o/r", 4) {
+			t.Fatalf("run not waiting after %s", after)
+		}
+		waitFor(EventGateApproved)
+	}
+	if !<-done {
+		t.Error("approved run not ready")
+	}
+	if mgr.ContinueIssue("o/r", 4) {
+		t.Error("finished run still waiting")
+	}
+
+	// Stopping a waiting run ends it.
+	go func() { done <- w.runStages(ctx, events, 4, t.TempDir(), cfg) }()
+	waitFor(EventGateWaiting)
+	cancel()
+	if <-done {
+		t.Error("stopped run ready")
+	}
+}
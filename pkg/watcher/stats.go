@@ -0,0 +1,329 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StatsFile, in the state directory, holds a line per finished run while
+// stats are on (see Manager.SetStats). It is lurker's only record of how
+// it is used; nothing in it is ever sent anywhere.
+const StatsFile = "stats.jsonl"
+
+// RunStat is a finished run of an issue, from its start to the status it
+// stopped in.
+type RunStat struct {
+	Repo    string      `json:"repo"`
+	Issue   int         `json:"issue"`
+	Started time.Time   `json:"started"`
+	Seconds float64     `json:"seconds"`
+	Outcome string      `json:"outcome"` // the IssueStatus it ended in
+	Phases  []PhaseStat `json:"phases,omitempty"`
+}
+
+// PhaseStat is how long a phase of a run took: the clone, Claude's turn,
+// or a stage of the repo's pipeline.
+type PhaseStat struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+// Succeeded reports whether r left its branch ready.
+func (r RunStat) Succeeded() bool {
+	return r.Outcome == StatusReady.String()
+}
+
+// statsRecorder times runs from the events forwardEvents passes on, and
+// appends each to its file once it finishes.
+type statsRecorder struct {
+	path string
+
+	mu   sync.Mutex
+	on   bool
+	live map[string]*liveRun // by issue key
+}
+
+// liveRun is a run in progress and the phase it is in.
+type liveRun struct {
+	stat  RunStat
+	phase string
+	since time.Time // of phase
+}
+
+func newStatsRecorder(path string) *statsRecorder {
+	return &statsRecorder{path: path, live: make(map[string]*liveRun)}
+}
+
+// SetStats turns recording runs to StatsFile on or off. It is off unless
+// turned on.
+func (m *Manager) SetStats(on bool) {
+	m.stats.mu.Lock()
+	defer m.stats.mu.Unlock()
+	m.stats.on = on
+	if !on {
+		m.stats.live = make(map[string]*liveRun)
+	}
+}
+
+// Stats reports whether runs are being recorded.
+func (m *Manager) Stats() bool {
+	m.stats.mu.Lock()
+	defer m.stats.mu.Unlock()
+	return m.stats.on
+}
+
+// record applies ev, and the EventStatus it caused if status is one, to
+// the runs in progress. A run starts when its issue becomes active and
+// ends when it stops in any other status but waiting.
+func (r *statsRecorder) record(ev, status Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.on || ev.IssueNum == 0 {
+		return
+	}
+	key := IssueKey(ev.Repo, ev.IssueNum)
+	at := ev.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if status.Kind != EventStatus && ev.Kind == EventStatus {
+		status = ev
+	}
+
+	run := r.live[key]
+	if run == nil && status.Kind == EventStatus && statusActive(status.Text) {
+		run = &liveRun{stat: RunStat{Repo: ev.Repo, Issue: ev.IssueNum, Started: at}}
+		r.live[key] = run
+	}
+	if run == nil {
+		return
+	}
+	switch ev.Kind {
+	case EventCloneStart:
+		run.begin(StageClone, at)
+	case EventClaudeStart:
+		run.begin(StageClaude, at)
+	case EventStageStarted:
+		run.begin(ev.Text, at)
+	case EventCloneDone, EventClaudeDone, EventStagePassed, EventStageFailed:
+		run.end(at)
+	}
+	if status.Kind == EventStatus && !statusActive(status.Text) && status.Text != StatusWaiting.String() {
+		run.end(at)
+		run.stat.Outcome = status.Text
+		run.stat.Seconds = seconds(at.Sub(run.stat.Started))
+		delete(r.live, key)
+		r.append(run.stat)
+	}
+}
+
+// statusActive reports whether the status named s is a run in progress.
+func statusActive(s string) bool {
+	for _, st := range []IssueStatus{StatusReacted, StatusCloning, StatusCloneReady, StatusQueued, StatusClaudeRunning} {
+		if s == st.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// begin starts phase name, ending the one before it.
+func (run *liveRun) begin(name string, at time.Time) {
+	run.end(at)
+	run.phase, run.since = name, at
+}
+
+// end ends the current phase, if any.
+func (run *liveRun) end(at time.Time) {
+	if run.phase == "" {
+		return
+	}
+	run.stat.Phases = append(run.stat.Phases, PhaseStat{Name: run.phase, Seconds: seconds(at.Sub(run.since))})
+	run.phase = ""
+}
+
+// seconds rounds d to milliseconds, in seconds.
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
+
+// append adds stat to the file. Write errors are ignored, as the
+// journal's are.
+func (r *statsRecorder) append(stat RunStat) {
+	data, err := json.Marshal(stat)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// LoadStats reads the runs recorded in l's StatsFile, oldest first; none
+// if stats were never on.
+func LoadStats(l Layout) ([]RunStat, error) {
+	f, err := os.Open(filepath.Join(l.resolved().State, StatsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var runs []RunStat
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var run RunStat
+		if json.Unmarshal(sc.Bytes(), &run) == nil {
+			runs = append(runs, run)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Started.Before(runs[j].Started) })
+	return runs, sc.Err()
+}
+
+// StatsSummary sums up the runs of a span of days.
+type StatsSummary struct {
+	Runs      int
+	Succeeded int            // ended ready
+	Outcomes  map[string]int // runs by the status they ended in
+	Total     time.Duration  // spent running
+	Median    time.Duration  // of a run
+	Days      []DayStats     // every day of the span, oldest first
+	Phases    []PhaseSummary // in the order runs go through them
+	Repos     []RepoStats    // busiest first
+}
+
+// DayStats counts a day's runs.
+type DayStats struct {
+	Day       time.Time // local midnight
+	Runs      int
+	Succeeded int
+}
+
+// PhaseSummary is how long a phase took in the runs that got to it.
+type PhaseSummary struct {
+	Name   string
+	Runs   int
+	Mean   time.Duration
+	Median time.Duration
+}
+
+// RepoStats counts a repo's runs.
+type RepoStats struct {
+	Repo      string
+	Runs      int
+	Succeeded int
+}
+
+// SuccessRate is the share of runs that ended ready, 0 if there were none.
+func (s StatsSummary) SuccessRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) / float64(s.Runs)
+}
+
+// SummarizeStats sums up the runs started in the days days up to and
+// including now's.
+func SummarizeStats(runs []RunStat, now time.Time, days int) StatsSummary {
+	days = max(days, 1)
+	y, mo, d := now.Date()
+	first := time.Date(y, mo, d-days+1, 0, 0, 0, 0, now.Location())
+	s := StatsSummary{Outcomes: make(map[string]int)}
+	for i := range days {
+		s.Days = append(s.Days, DayStats{Day: time.Date(y, mo, d-days+1+i, 0, 0, 0, 0, now.Location())})
+	}
+
+	var durations []float64
+	phases := make(map[string][]float64)
+	var order []string
+	repos := make(map[string]*RepoStats)
+	for _, run := range runs {
+		started := run.Started.In(now.Location())
+		if started.Before(first) || started.After(now) {
+			continue
+		}
+		s.Runs++
+		s.Outcomes[run.Outcome]++
+		s.Total += time.Duration(run.Seconds * float64(time.Second))
+		durations = append(durations, run.Seconds)
+		rs := repos[run.Repo]
+		if rs == nil {
+			rs = &RepoStats{Repo: run.Repo}
+			repos[run.Repo] = rs
+		}
+		rs.Runs++
+		y, mo, d := started.Date()
+		day := &s.Days[daysBetween(first, time.Date(y, mo, d, 0, 0, 0, 0, now.Location()))]
+		day.Runs++
+		if run.Succeeded() {
+			s.Succeeded++
+			rs.Succeeded++
+			day.Succeeded++
+		}
+		for _, p := range run.Phases {
+			if _, ok := phases[p.Name]; !ok {
+				order = append(order, p.Name)
+			}
+			phases[p.Name] = append(phases[p.Name], p.Seconds)
+		}
+	}
+
+	s.Median = secondsDuration(median(durations))
+	for _, name := range order {
+		ps := phases[name]
+		sum := 0.0
+		for _, sec := range ps {
+			sum += sec
+		}
+		s.Phases = append(s.Phases, PhaseSummary{
+			Name:   name,
+			Runs:   len(ps),
+			Mean:   secondsDuration(sum / float64(len(ps))),
+			Median: secondsDuration(median(ps)),
+		})
+	}
+	for _, rs := range repos {
+		s.Repos = append(s.Repos, *rs)
+	}
+	sort.Slice(s.Repos, func(i, j int) bool {
+		if s.Repos[i].Runs != s.Repos[j].Runs {
+			return s.Repos[i].Runs > s.Repos[j].Runs
+		}
+		return s.Repos[i].Repo < s.Repos[j].Repo
+	})
+	return s
+}
+
+// daysBetween counts the calendar days from a to b, both midnights; it
+// rounds, as a day across a DST change isn't 24 hours.
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
+
+// median returns the middle of xs, 0 if it is empty. It sorts xs.
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sort.Float64s(xs)
+	if n := len(xs); n%2 == 0 {
+		return (xs[n/2-1] + xs[n/2]) / 2
+	}
+	return xs[len(xs)/2]
+}
+
+func secondsDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second)).Round(time.Second / 10)
+}
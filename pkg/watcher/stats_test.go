@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	l := Layout{Data: t.TempDir()}.resolved()
+	os.MkdirAll(l.State, 0o755)
+	r := newStatsRecorder(filepath.Join(l.State, StatsFile))
+	t0 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return t0.Add(time.Duration(sec) * time.Second) }
+	ev := func(kind EventKind, num, sec int, text string) Event {
+		return Event{Kind: kind, Repo: "o/r", IssueNum: num, Text: text, Timestamp: at(sec)}
+	}
+	status := func(num, sec int, s IssueStatus) Event { return ev(EventStatus, num, sec, s.String()) }
+	run := func() {
+		r.record(ev(EventReacted, 1, 0, ""), status(1, 0, StatusReacted))
+		r.record(ev(EventCloneStart, 1, 1, ""), status(1, 1, StatusCloning))
+		r.record(ev(EventCloneDone, 1, 4, "/w"), status(1, 4, StatusCloneReady))
+		r.record(ev(EventClaudeStart, 1, 5, ""), status(1, 5, StatusClaudeRunning))
+		r.record(ev(EventClaudeDone, 1, 65, ""), Event{})
+		r.record(ev(EventStageStarted, 1, 66, "test"), Event{})
+		r.record(ev(EventStagePassed, 1, 76, "test"), Event{})
+		r.record(ev(EventGateWaiting, 1, 76, "test"), status(1, 76, StatusWaiting))
+		r.record(ev(EventReady, 1, 90, ""), status(1, 90, StatusReady))
+	}
+
+	// Off, nothing is recorded.
+	run()
+	if runs, err := LoadStats(l); err != nil || len(runs) != 0 {
+		t.Fatalf("LoadStats while off = %v, %v", runs, err)
+	}
+
+	r.on = true
+	run()
+	// A run stopped by hand, announced as its status alone.
+	r.record(ev(EventQueued, 2, 100, ""), status(2, 100, StatusQueued))
+	r.record(status(2, 130, StatusPaused), Event{})
+	// Events of issues not running are ignored.
+	r.record(ev(EventClaudeDone, 3, 140, ""), Event{})
+
+	runs, err := LoadStats(l)
+	if err != nil {
+		t.Fatalf("LoadStats: %v", err)
+	}
+	want := []RunStat{
+		{Repo: "o/r", Issue: 1, Started: at(0), Seconds: 90, Outcome: "ready", Phases: []PhaseStat{
+			{Name: StageClone, Seconds: 3}, {Name: StageClaude, Seconds: 60}, {Name: "test", Seconds: 10},
+		}},
+		{Repo: "o/r", Issue: 2, Started: at(100), Seconds: 30, Outcome: "paused"},
+	}
+	for i := range runs {
+		runs[i].Started = runs[i].Started.UTC()
+	}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %+v, want %+v", runs, want)
+	}
+}
+
+func TestSummarizeStats(t *testing.T) {
+	now := time.Date(2026, 3, 5, 18, 0, 0, 0, time.UTC)
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	runs := []RunStat{
+		{Repo: "o/old", Started: day(1, 12), Seconds: 10, Outcome: "ready"}, // before the span
+		{Repo: "o/a", Started: day(3, 9), Seconds: 100, Outcome: "ready", Phases: []PhaseStat{{StageClone, 4}, {StageClaude, 80}}},
+		{Repo: "o/a", Started: day(3, 23), Seconds: 200, Outcome: "failed", Phases: []PhaseStat{{StageClone, 2}, {StageClaude, 190}, {"test", 8}}},
+		{Repo: "o/b", Started: day(5, 8), Seconds: 60, Outcome: "ready", Phases: []PhaseStat{{StageClaude, 50}, {"test", 10}}},
+		{Repo: "o/b", Started: day(6, 1), Seconds: 60, Outcome: "ready"}, // after now
+	}
+	s := SummarizeStats(runs, now, 3)
+
+	if s.Runs != 3 || s.Succeeded != 2 || s.Total != 360*time.Second || s.Median != 100*time.Second {
+		t.Errorf("summary = %d runs, %d succeeded, %v total, %v median", s.Runs, s.Succeeded, s.Total, s.Median)
+	}
+	if got := s.SuccessRate(); got < 0.66 || got > 0.67 {
+		t.Errorf("SuccessRate = %v", got)
+	}
+	if want := map[string]int{"ready": 2, "failed": 1}; !reflect.DeepEqual(s.Outcomes, want) {
+		t.Errorf("Outcomes = %v, want %v", s.Outcomes, want)
+	}
+	wantDays := []DayStats{{day(3, 0), 2, 1}, {day(4, 0), 0, 0}, {day(5, 0), 1, 1}}
+	if !reflect.DeepEqual(s.Days, wantDays) {
+		t.Errorf("Days = %v, want %v", s.Days, wantDays)
+	}
+	wantPhases := []PhaseSummary{
+		{StageClone, 2, 3 * time.Second, 3 * time.Second},
+		{StageClaude, 3, 106700 * time.Millisecond, 80 * time.Second},
+		{"test", 2, 9 * time.Second, 9 * time.Second},
+	}
+	if !reflect.DeepEqual(s.Phases, wantPhases) {
+		t.Errorf("Phases = %v, want %v", s.Phases, wantPhases)
+	}
+	wantRepos := []RepoStats{{"o/a", 2, 1}, {"o/b", 1, 1}}
+	if !reflect.DeepEqual(s.Repos, wantRepos) {
+		t.Errorf("Repos = %v, want %v", s.Repos, wantRepos)
+	}
+
+	if empty := SummarizeStats(nil, now, 7); empty.Runs != 0 || len(empty.Days) != 7 || empty.SuccessRate() != 0 {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
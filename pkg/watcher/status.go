@@ -0,0 +1,279 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Active reports whether s is a run in progress, which StopIssue pauses.
+func (s IssueStatus) Active() bool {
+	switch s {
+	case StatusReacted, StatusCloning, StatusCloneReady, StatusQueued, StatusClaudeRunning, StatusWaiting:
+		return true
+	}
+	return false
+}
+
+// failed reports whether s is a status whose TrackedIssue.Error says why.
+func (s IssueStatus) failed() bool {
+	switch s {
+	case StatusFailed, StatusConflict, StatusBuildFailed, StatusTestsFailed:
+		return true
+	}
+	return false
+}
+
+// eventStatus returns the status ev moves its issue to, if any.
+func eventStatus(ev Event) (IssueStatus, bool) {
+	switch ev.Kind {
+	case EventReacted:
+		return StatusReacted, true
+	case EventCloneStart:
+		return StatusCloning, true
+	case EventCloneDone:
+		return StatusCloneReady, true
+	case EventClaudeStart:
+		return StatusClaudeRunning, true
+	case EventQueued:
+		return StatusQueued, true
+	case EventNeedsInput, EventToolRequest:
+		return StatusNeedsInput, true
+	case EventReady, EventFeedbackDone:
+		return StatusReady, true
+	case EventConflict:
+		return StatusConflict, true
+	case EventBuildFailed:
+		return StatusBuildFailed, true
+	case EventTestsFailed:
+		return StatusTestsFailed, true
+	case EventError:
+		return StatusFailed, ev.IssueNum > 0
+	case EventGateWaiting:
+		return StatusWaiting, true
+	case EventGateApproved:
+		if ev.Text == StageClone {
+			return StatusCloneReady, true
+		}
+		return StatusClaudeRunning, true
+	}
+	return 0, false
+}
+
+// Issues returns the issues lurker tracks, by repo and number. Their
+// status is the Manager's: every frontend sees the same one, and is told
+// of changes by EventStatus.
+func (m *Manager) Issues() []TrackedIssue {
+	m.mu.Lock()
+	issues := make([]TrackedIssue, 0, len(m.tracked))
+	for _, iss := range m.tracked {
+		issues = append(issues, iss)
+	}
+	m.mu.Unlock()
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Repo != issues[j].Repo {
+			return issues[i].Repo < issues[j].Repo
+		}
+		return issues[i].Number < issues[j].Number
+	})
+	return issues
+}
+
+// Issue returns the tracked issue repo#num, as Issues does.
+func (m *Manager) Issue(repo string, num int) (TrackedIssue, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	iss, ok := m.tracked[IssueKey(repo, num)]
+	return iss, ok
+}
+
+// SetStatus records a status change a frontend made itself, e.g. marking
+// an issue ready after an interactive session, and announces it as
+// EventStatus.
+func (m *Manager) SetStatus(repo string, num int, status IssueStatus) {
+	m.mu.Lock()
+	ev, changed := m.setStatus(repo, num, status)
+	m.mu.Unlock()
+	if changed {
+		m.announce(ev)
+	}
+}
+
+// setStatus moves the tracked issue repo#num to status, and returns the
+// EventStatus to announce if that changed it. m.mu must be held.
+func (m *Manager) setStatus(repo string, num int, status IssueStatus) (Event, bool) {
+	key := IssueKey(repo, num)
+	iss, ok := m.tracked[key]
+	if !ok || iss.Status == status {
+		return Event{}, false
+	}
+	iss.Status = status
+	if !status.failed() {
+		iss.Error = ""
+	}
+	if status != StatusNeedsInput {
+		iss.Questions = ""
+	}
+	m.tracked[key] = iss
+	return Event{Kind: EventStatus, Repo: repo, IssueNum: num, Text: status.String(), Timestamp: time.Now()}, true
+}
+
+// announce queues ev to be sent after the events announced before it,
+// without waiting, as the caller may be the frontend that drains the
+// events.
+func (m *Manager) announce(ev Event) {
+	m.announceMu.Lock()
+	m.announced = append(m.announced, ev)
+	m.announceMu.Unlock()
+	select {
+	case m.announceWake <- struct{}{}:
+	default:
+	}
+}
+
+// announceLoop sends the events announce queues, in order, until ctx is
+// done.
+func (m *Manager) announceLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.announceWake:
+		}
+		m.announceMu.Lock()
+		evs := m.announced
+		m.announced = nil
+		m.announceMu.Unlock()
+		for _, ev := range evs {
+			select {
+			case m.eventCh <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// track applies ev to the tracked issues, and returns the EventStatus to
+// pass on after it if it changed an issue's status. Events of a paused
+// issue are from the run that was stopped, and change nothing.
+func (m *Manager) track(ev Event) (Event, bool) {
+	key := IssueKey(ev.Repo, ev.IssueNum)
+	if ev.Kind == EventIssueFound {
+		found := m.foundIssue(ev)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if iss, ok := m.tracked[key]; ok {
+			iss.Title, iss.Body, iss.Labels, iss.URL = ev.Text, ev.IssueBody, ev.IssueLabels, ev.IssueURL
+			m.tracked[key] = iss
+		} else if !m.ignored(ev.Repo, ev.IssueNum) {
+			m.tracked[key] = found
+		}
+		return Event{}, false
+	}
+	switch ev.Kind {
+	case EventPipeline, EventStageStarted, EventStagePassed, EventStageFailed:
+		m.trackStage(ev)
+		return Event{}, false
+	case EventIssueGone:
+		m.issueGone(ev.Repo, ev.IssueNum)
+		return Event{}, false
+	case EventCost:
+		m.trackCost(ev)
+		return Event{}, false
+	}
+
+	status, ok := eventStatus(ev)
+	if !ok {
+		return Event{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	iss, ok := m.tracked[key]
+	if !ok || iss.Status == StatusPaused {
+		return Event{}, false
+	}
+	switch ev.Kind {
+	case EventCloneDone:
+		iss.Workdir = ev.Text
+	case EventClaudeStart:
+		iss.Stages = nil // its pipeline hasn't run yet
+	case EventReady:
+		if ev.Text == PatchPath(m.baseDir, ev.Repo, ev.IssueNum) {
+			iss.Workdir = "" // patch mode: the checkout is gone
+		}
+	}
+	m.tracked[key] = iss
+	change, changed := m.setStatus(ev.Repo, ev.IssueNum, status)
+	iss = m.tracked[key]
+	switch {
+	case status.failed():
+		iss.Error = ev.Text
+	case status == StatusNeedsInput:
+		iss.Questions = ev.Text
+	}
+	m.tracked[key] = iss
+	return change, changed
+}
+
+// issueGone forgets repo#num, which left the repo's open issues, unless a
+// run of it is in progress; that one goes once lurker restarts, as the
+// poll then doesn't find it.
+func (m *Manager) issueGone(repo string, num int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := IssueKey(repo, num)
+	if iss, ok := m.tracked[key]; ok && iss.Status.Active() {
+		return
+	}
+	delete(m.knownIssues, key)
+	delete(m.replayed, key)
+	delete(m.tracked, key)
+}
+
+// trackCost records what repo#num's latest run cost, stopped or not.
+func (m *Manager) trackCost(ev Event) {
+	cost, err := strconv.ParseFloat(ev.Text, 64)
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := IssueKey(ev.Repo, ev.IssueNum)
+	if iss, ok := m.tracked[key]; ok {
+		iss.CostUSD = cost
+		m.tracked[key] = iss
+	}
+}
+
+// foundIssue is the TrackedIssue an EventIssueFound announces, in the
+// status its issue dir and issue.json left it in.
+func (m *Manager) foundIssue(ev Event) TrackedIssue {
+	base := m.BaseBranch(ev.Repo, IssueWorkdir(m.baseDir, ev.Repo, ev.IssueNum))
+	status, workdir := DeriveIssueStatus(m.baseDir, ev.Repo, ev.IssueNum, base)
+	iss := TrackedIssue{
+		Repo:      ev.Repo,
+		Number:    ev.IssueNum,
+		Title:     ev.Text,
+		Body:      ev.IssueBody,
+		Labels:    ev.IssueLabels,
+		URL:       ev.IssueURL,
+		Status:    status,
+		Workdir:   workdir,
+		StartedAt: ev.Timestamp,
+		CreatedAt: ev.IssueOpened,
+	}
+	if status == StatusNeedsInput {
+		iss.Questions, _ = ReadQuestions(workdir)
+		if reqs := ReadToolRequests(IssueDir(m.baseDir, ev.Repo, ev.IssueNum)); len(reqs) > 0 {
+			iss.Questions = ToolRequestsText(reqs)
+		}
+	}
+	if status != StatusPending {
+		if meta, err := ReadIssueMeta(m.baseDir, ev.Repo, ev.IssueNum); err == nil {
+			iss.RestoreFromMeta(meta)
+		}
+	}
+	return iss
+}
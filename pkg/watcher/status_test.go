@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+// nextEvent returns the next event from mgr, failing the test if none
+// comes.
+func nextEvent(t *testing.T, mgr *Manager) Event {
+	t.Helper()
+	select {
+	case ev := <-mgr.EventCh():
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return Event{}
+	}
+}
+
+func TestManager_TracksStatus(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.eventCh <- Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: 1, Text: "Crash"}
+	if ev := nextEvent(t, mgr); ev.Kind != EventIssueFound {
+		t.Fatalf("event = %+v", ev)
+	}
+	if iss, ok := mgr.Issue("o/r", 1); !ok || iss.Status != StatusPending || iss.Title != "Crash" {
+		t.Fatalf("found issue = %+v, %v", iss, ok)
+	}
+
+	// Each change is passed on after the event that caused it.
+	mgr.eventCh <- Event{Kind: EventClaudeStart, Repo: "o/r", IssueNum: 1}
+	nextEvent(t, mgr)
+	if ev := nextEvent(t, mgr); ev.Kind != EventStatus || ev.Text != StatusClaudeRunning.String() {
+		t.Errorf("status event = %+v", ev)
+	}
+	mgr.eventCh <- Event{Kind: EventError, Repo: "o/r", IssueNum: 1, Text: "boom"}
+	nextEvent(t, mgr)
+	nextEvent(t, mgr)
+	if iss, _ := mgr.Issue("o/r", 1); iss.Status != StatusFailed || iss.Error != "boom" {
+		t.Errorf("after error = %+v", iss)
+	}
+
+	// A frontend's change is announced to the others.
+	mgr.SetStatus("o/r", 1, StatusReady)
+	if ev := nextEvent(t, mgr); ev.Kind != EventStatus || ev.Text != StatusReady.String() {
+		t.Errorf("status event = %+v", ev)
+	}
+	if iss, _ := mgr.Issue("o/r", 1); iss.Error != "" {
+		t.Errorf("error kept once ready: %q", iss.Error)
+	}
+
+	// Stopping pauses a running issue, and the stopped run's last events
+	// don't move it.
+	mgr.SetStatus("o/r", 1, StatusClaudeRunning)
+	nextEvent(t, mgr)
+	mgr.StopIssue("o/r", 1)
+	if ev := nextEvent(t, mgr); ev.Kind != EventStatus || ev.Text != StatusPaused.String() {
+		t.Errorf("status event = %+v", ev)
+	}
+	mgr.eventCh <- Event{Kind: EventError, Repo: "o/r", IssueNum: 1, Text: "context canceled"}
+	nextEvent(t, mgr)
+	if issues := mgr.Issues(); len(issues) != 1 || issues[0].Status != StatusPaused {
+		t.Errorf("Issues = %+v", issues)
+	}
+	select {
+	case ev := <-mgr.EventCh():
+		t.Errorf("unexpected event %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestManager_WarningKeepsStatus(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.eventCh <- Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: 1, Text: "Crash"}
+	nextEvent(t, mgr)
+	mgr.SetStatus("o/r", 1, StatusNeedsInput)
+	nextEvent(t, mgr)
+
+	// Nothing to answer is said, and the issue still waits for input.
+	mgr.AnswerIssue("o/r", 1, "yes")
+	if ev := nextEvent(t, mgr); ev.Kind != EventWarning || ev.Text != "No "+QuestionsFile+" to answer" {
+		t.Errorf("event = %+v", ev)
+	}
+	mgr.ResolveToolRequests("o/r", 1, ApprovalOnce)
+	if ev := nextEvent(t, mgr); ev.Kind != EventWarning || ev.Text != "No tool requests to answer" {
+		t.Errorf("event = %+v", ev)
+	}
+	if iss, _ := mgr.Issue("o/r", 1); iss.Status != StatusNeedsInput || iss.Error != "" {
+		t.Errorf("after warnings = %+v", iss)
+	}
+}
+
+func TestManager_AnnounceInOrder(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.eventCh <- Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: 1, Text: "Crash"}
+	nextEvent(t, mgr)
+	statuses := []IssueStatus{StatusQueued, StatusClaudeRunning, StatusReady, StatusPaused, StatusFailed, StatusReady}
+	for _, s := range statuses {
+		mgr.SetStatus("o/r", 1, s)
+	}
+	for _, s := range statuses {
+		if ev := nextEvent(t, mgr); ev.Kind != EventStatus || ev.Text != s.String() {
+			t.Fatalf("status event = %+v, want %s", ev, s)
+		}
+	}
+}
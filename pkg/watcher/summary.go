@@ -0,0 +1,287 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits that keep a run summary well inside GitHub's 65536-character
+// comment limit.
+const (
+	maxSummaryText     = 4000
+	maxSummaryCommands = 100
+)
+
+// RunSummary is what a reviewer needs to know about the run behind a
+// branch, read from its Claude session and lurker's verification.
+type RunSummary struct {
+	Text         string        // Claude's closing message
+	Commands     []string      // shell commands Claude ran, in order
+	Tools        ToolStats     // Claude's tool calls and the files they changed
+	Turns        int           // assistant messages in the session
+	Duration     time.Duration // first to last message of the session
+	CostUSD      float64       // 0 when unknown
+	Verification *Verification
+}
+
+// sessionLine is the part of a Claude session line a summary needs.
+type sessionLine struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   json.RawMessage `json:"message,omitempty"` // user messages' content may be a string
+}
+
+// LoadRunSummary summarizes the latest Claude session run in workdir.
+func LoadRunSummary(workdir string) (RunSummary, error) {
+	path, err := latestSession(workdir)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	defer f.Close()
+
+	var s RunSummary
+	var first, last time.Time
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line sessionLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil {
+			continue
+		}
+		if !line.Timestamp.IsZero() {
+			if first.IsZero() {
+				first = line.Timestamp
+			}
+			last = line.Timestamp
+		}
+		var msg assistantMessage
+		if line.Type != "assistant" || json.Unmarshal(line.Message, &msg) != nil {
+			continue
+		}
+		s.Turns++
+		for _, block := range msg.Content {
+			switch {
+			case block.Type == "text" && strings.TrimSpace(block.Text) != "":
+				s.Text = strings.TrimSpace(block.Text)
+			case block.Type == "tool_use":
+				s.Tools.add(block, workdir)
+				if block.Name != "Bash" {
+					continue
+				}
+				var input struct {
+					Command string `json:"command"`
+				}
+				if json.Unmarshal(block.Input, &input) == nil && input.Command != "" {
+					s.Commands = append(s.Commands, input.Command)
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return RunSummary{}, err
+	}
+	s.Duration = last.Sub(first).Round(time.Second)
+	if v, ok := LoadVerification(filepath.Dir(workdir)); ok {
+		s.Verification = &v
+	}
+	return s, nil
+}
+
+// SessionUsage is how far a Claude run has got: its turns and tokens.
+type SessionUsage struct {
+	Turns         int     // assistant messages
+	ContextTokens int     // input of the latest turn, cached or not
+	OutputTokens  int     // summed over the turns
+	CostUSD       float64 // at the models' list prices; see modelPrice
+}
+
+// modelPrices are the list prices of Claude's models in dollars per
+// million input and output tokens, by model ID prefix, longest first.
+// Writing the prompt cache costs 1.25 times input, reading it 0.1 times.
+var modelPrices = []struct {
+	prefix  string
+	in, out float64
+}{
+	{"claude-opus-4-1", 15, 75},
+	{"claude-opus-4-2", 15, 75}, // claude-opus-4-20250514
+	{"claude-3-5-haiku", 0.8, 4},
+	{"claude-3-haiku", 0.25, 1.25},
+	{"claude-3-opus", 15, 75},
+	{"claude-haiku", 1, 5},
+	{"claude-opus", 5, 25},
+	{"claude-", 3, 15}, // Sonnet, and what isn't known yet
+}
+
+// modelPrice returns model's prices per million input and output tokens,
+// and false for what isn't Claude's.
+func modelPrice(model string) (in, out float64, ok bool) {
+	for _, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.in, p.out, true
+		}
+	}
+	return 0, 0, false
+}
+
+// ReadSessionUsage totals the turns, tokens and cost of the latest Claude
+// session in workdir since since, so a resumed session counts only the
+// current run. A message streamed over several lines counts once, with
+// the usage of its last line. Claude Code doesn't record what a turn
+// cost, so it is reckoned from its tokens and model.
+func ReadSessionUsage(workdir string, since time.Time) (SessionUsage, error) {
+	path, err := latestSession(workdir)
+	if err != nil {
+		return SessionUsage{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return SessionUsage{}, err
+	}
+	defer f.Close()
+
+	type usage struct {
+		InputTokens         int `json:"input_tokens"`
+		CacheCreationTokens int `json:"cache_creation_input_tokens"`
+		CacheReadTokens     int `json:"cache_read_input_tokens"`
+		OutputTokens        int `json:"output_tokens"`
+	}
+	var order []string
+	turns := map[string]usage{}
+	models := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line sessionLine
+		if json.Unmarshal(scanner.Bytes(), &line) != nil || line.Type != "assistant" || line.Timestamp.Before(since) {
+			continue
+		}
+		var msg struct {
+			ID    string `json:"id"`
+			Model string `json:"model"`
+			Usage usage  `json:"usage"`
+		}
+		if json.Unmarshal(line.Message, &msg) != nil {
+			continue
+		}
+		if msg.ID == "" {
+			msg.ID = fmt.Sprintf("line %d", len(order))
+		}
+		if _, ok := turns[msg.ID]; !ok {
+			order = append(order, msg.ID)
+		}
+		turns[msg.ID] = msg.Usage
+		models[msg.ID] = msg.Model
+	}
+	if err := scanner.Err(); err != nil {
+		return SessionUsage{}, err
+	}
+
+	u := SessionUsage{Turns: len(order)}
+	for _, id := range order {
+		t := turns[id]
+		u.OutputTokens += t.OutputTokens
+		if in, out, ok := modelPrice(models[id]); ok {
+			u.CostUSD += (in*(float64(t.InputTokens)+1.25*float64(t.CacheCreationTokens)+0.1*float64(t.CacheReadTokens)) +
+				out*float64(t.OutputTokens)) / 1e6
+		}
+	}
+	if len(order) > 0 {
+		last := turns[order[len(order)-1]]
+		u.ContextTokens = last.InputTokens + last.CacheCreationTokens + last.CacheReadTokens
+	}
+	return u, nil
+}
+
+// reportCost announces what the Claude run in workdir since started
+// cost, if its session says.
+func (w *Watcher) reportCost(eventCh chan<- Event, num int, workdir string, started time.Time) {
+	u, err := ReadSessionUsage(workdir, started.Truncate(time.Millisecond))
+	if err != nil || u.CostUSD <= 0 {
+		return
+	}
+	w.emit(eventCh, EventCost, num, strconv.FormatFloat(u.CostUSD, 'f', 4, 64))
+}
+
+// Markdown renders s as a PR comment, with the details in collapsible
+// sections.
+func (s RunSummary) Markdown() string {
+	var b strings.Builder
+	b.WriteString("### lurker run summary\n\n")
+	if s.Text != "" {
+		b.WriteString(truncateRunes(s.Text, maxSummaryText))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("| | |\n|---|---|\n")
+	if s.Duration > 0 {
+		fmt.Fprintf(&b, "| Duration | %s |\n", s.Duration)
+	}
+	fmt.Fprintf(&b, "| Turns | %d |\n", s.Turns)
+	if s.CostUSD > 0 {
+		fmt.Fprintf(&b, "| Cost | $%.2f |\n", s.CostUSD)
+	}
+	if v := s.Verification; v != nil {
+		fmt.Fprintf(&b, "| Confidence | %s (verified %.8s) |\n", v.Confidence(), v.SHA)
+	}
+
+	if len(s.Commands) > 0 {
+		fmt.Fprintf(&b, "\n<details><summary>Commands run (%d)</summary>\n\n```sh\n", len(s.Commands))
+		for i, cmd := range s.Commands {
+			if i == maxSummaryCommands {
+				fmt.Fprintf(&b, "# … %d more\n", len(s.Commands)-i)
+				break
+			}
+			b.WriteString(truncateRunes(cmd, 300))
+			b.WriteString("\n")
+		}
+		b.WriteString("```\n\n</details>\n")
+	}
+
+	if v := s.Verification; v != nil {
+		var names []string
+		for _, c := range v.Checks {
+			mark := "✅"
+			if !c.Passed {
+				mark = "❌"
+			}
+			names = append(names, mark+" "+c.Name)
+		}
+		fmt.Fprintf(&b, "\n<details><summary>Checks: %s</summary>\n\n", strings.Join(names, ", "))
+		for _, c := range v.Checks {
+			result := "passed"
+			if !c.Passed {
+				result = "failed"
+			}
+			fmt.Fprintf(&b, "- `%s` %s in %ds\n", c.Command, result, c.Seconds)
+			if c.Output != "" {
+				fmt.Fprintf(&b, "\n  ```\n%s\n  ```\n", indent(c.Output, "  "))
+			}
+		}
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// truncateRunes cuts s to at most n runes, marking the cut.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
+
+// indent prefixes every line of s.
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadRunSummary(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	issueDir := t.TempDir()
+	workdir := filepath.Join(issueDir, "repo")
+	dir, _ := claudeProjectDir(workdir)
+	os.MkdirAll(dir, 0o755)
+	session := `{"type":"user","timestamp":"2026-01-02T10:00:00Z","message":{"role":"user","content":"fix it"}}
+{"type":"assistant","timestamp":"2026-01-02T10:01:00Z","message":{"content":[{"type":"text","text":"Looking around"},{"type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"assistant","timestamp":"2026-01-02T10:04:30Z","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"main.go"}},{"type":"text","text":"Fixed the nil check in main.go."}]}}
+`
+	os.WriteFile(filepath.Join(dir, "abc.jsonl"), []byte(session), 0o644)
+
+	s, err := LoadRunSummary(workdir)
+	if err != nil {
+		t.Fatalf("LoadRunSummary: %v", err)
+	}
+	if s.Text != "Fixed the nil check in main.go." || s.Turns != 2 || s.Duration != 4*time.Minute+30*time.Second {
+		t.Errorf("summary = %+v", s)
+	}
+	if len(s.Commands) != 1 || s.Commands[0] != "go test ./..." {
+		t.Errorf("commands = %v", s.Commands)
+	}
+	if s.Tools.Total() != 2 || s.Tools.Calls["Read"] != 1 {
+		t.Errorf("tools = %+v", s.Tools)
+	}
+	if s.Verification != nil {
+		t.Errorf("unexpected verification %+v", s.Verification)
+	}
+
+	WriteVerification(issueDir, Verification{SHA: "abcdef0123", Checks: []CheckResult{
+		{Name: "build", Command: "go build ./...", Passed: true},
+		{Name: "test", Command: "go test ./...", Output: "FAIL x"},
+	}})
+	s, _ = LoadRunSummary(workdir)
+	s.CostUSD = 0.42
+	md := s.Markdown()
+	for _, want := range []string{
+		"Fixed the nil check in main.go.",
+		"| Duration | 4m30s |",
+		"| Cost | $0.42 |",
+		"| Confidence | low (verified abcdef01) |",
+		"<details><summary>Commands run (1)</summary>",
+		"<details><summary>Checks: ✅ build, ❌ test</summary>",
+		"FAIL x",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+
+	if _, err := LoadRunSummary("/nowhere"); err == nil {
+		t.Error("expected error when no session exists")
+	}
+}
+
+func TestReadSessionUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workdir := filepath.Join(t.TempDir(), "repo")
+	dir, _ := claudeProjectDir(workdir)
+	os.MkdirAll(dir, 0o755)
+	session := `{"type":"assistant","timestamp":"2026-01-02T09:00:00Z","message":{"id":"m0","usage":{"input_tokens":5,"output_tokens":900}}}
+{"type":"user","timestamp":"2026-01-02T10:00:00Z","message":{"role":"user","content":"go on"}}
+{"type":"assistant","timestamp":"2026-01-02T10:01:00Z","message":{"id":"m1","usage":{"input_tokens":10,"cache_read_input_tokens":1000,"output_tokens":5}}}
+{"type":"assistant","timestamp":"2026-01-02T10:01:01Z","message":{"id":"m1","usage":{"input_tokens":10,"cache_read_input_tokens":1000,"output_tokens":40}}}
+{"type":"assistant","timestamp":"2026-01-02T10:02:00Z","message":{"id":"m2","usage":{"input_tokens":3,"cache_creation_input_tokens":200,"cache_read_input_tokens":1050,"output_tokens":60}}}
+`
+	os.WriteFile(filepath.Join(dir, "abc.jsonl"), []byte(session), 0o644)
+
+	u, err := ReadSessionUsage(workdir, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ReadSessionUsage: %v", err)
+	}
+	if u != (SessionUsage{Turns: 2, ContextTokens: 1253, OutputTokens: 100}) {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestModelPrice(t *testing.T) {
+	for model, want := range map[string][2]float64{
+		"claude-opus-4-1-20250805":   {15, 75},
+		"claude-opus-4-20250514":     {15, 75},
+		"claude-opus-4-5-20251101":   {5, 25},
+		"claude-sonnet-4-5-20250929": {3, 15},
+		"claude-haiku-4-5-20251001":  {1, 5},
+		"claude-3-5-haiku-20241022":  {0.8, 4},
+	} {
+		if in, out, ok := modelPrice(model); !ok || in != want[0] || out != want[1] {
+			t.Errorf("modelPrice(%s) = %v, %v, %v", model, in, out, ok)
+		}
+	}
+	if _, _, ok := modelPrice("gpt-5"); ok {
+		t.Error("modelPrice priced another vendor's model")
+	}
+}
+
+func TestRunClaudeCost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workdir := t.TempDir()
+	dir, _ := claudeProjectDir(workdir)
+	os.MkdirAll(dir, 0o755)
+
+	// A claude that does its work and leaves its session behind.
+	bin := t.TempDir()
+	script := `#!/bin/sh
+cat > /dev/null
+cat > "$SESSION" <<'JSON'
+{"type":"assistant","timestamp":"2099-01-01T00:00:00Z","message":{"id":"m1","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":1000,"cache_read_input_tokens":100000,"output_tokens":2000}}}
+{"type":"assistant","timestamp":"2099-01-01T00:00:05Z","message":{"id":"m2","model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":500,"cache_creation_input_tokens":4000,"output_tokens":1000}}}
+JSON
+`
+	os.WriteFile(filepath.Join(bin, "claude"), []byte(script), 0o755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SESSION", filepath.Join(dir, "s.jsonl"))
+	prompt := filepath.Join(t.TempDir(), "prompt.md")
+	os.WriteFile(prompt, []byte("Fix it."), 0o644)
+
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.tracked[IssueKey("o/r", 1)] = TrackedIssue{Repo: "o/r", Number: 1, Status: StatusClaudeRunning}
+	w := mgr.newWatcher("o/r")
+	eventCh := make(chan Event, 100)
+	ctx := context.Background()
+	if !w.runClaude(ctx, eventCh, w.runner(ctx, IssueKey("o/r", 1)), 1, workdir, prompt, "Read", false) {
+		t.Fatal("runClaude failed")
+	}
+	close(eventCh)
+	for ev := range eventCh {
+		mgr.track(ev)
+	}
+
+	// 1000+100000*0.1+500+4000*1.25 input, 3000 output, at $3 and $15.
+	iss, _ := mgr.Issue("o/r", 1)
+	if want := 0.0945; math.Abs(iss.CostUSD-want) > 1e-9 {
+		t.Errorf("CostUSD = %v, want %v", iss.CostUSD, want)
+	}
+}
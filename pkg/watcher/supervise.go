@@ -0,0 +1,166 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// supervision tunes how a Manager watches over its repo watchers.
+type supervision struct {
+	check       time.Duration // how often a watcher's heartbeat is checked
+	minStall    time.Duration // least silence before a watcher is stuck; covers the stagger before a first poll
+	backoff     time.Duration // before the first restart, doubling with each one after
+	maxBackoff  time.Duration
+	stableAfter time.Duration // a watcher that runs this long resets the backoff
+}
+
+var defaultSupervision = supervision{
+	check:       time.Minute,
+	minStall:    10 * time.Minute,
+	backoff:     time.Second,
+	maxBackoff:  5 * time.Minute,
+	stableAfter: 10 * time.Minute,
+}
+
+// stallAfter is how long a watcher polling every interval may go without a
+// heartbeat before it is considered stuck and replaced. A webhook-fed
+// repo's loop still wakes every interval, so this needn't allow for
+// WebhookPollInterval.
+func (s supervision) stallAfter(interval time.Duration) time.Duration {
+	return max(5*interval, s.minStall)
+}
+
+// supervise runs w until ctx is cancelled. If the watcher panics or stops
+// beating, the incident is reported as a repo-level EventError and a fresh
+// watcher replaces it after a backoff, so one bad poll can't end a repo's
+// polling for good.
+func (m *Manager) supervise(ctx context.Context, w *Watcher) {
+	repo := w.cfg.Repo
+	backoff := m.supervision.backoff
+	for {
+		started := time.Now()
+		err := m.runWatcher(ctx, w)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= m.supervision.stableAfter {
+			backoff = m.supervision.backoff
+		}
+		select {
+		case m.eventCh <- Event{
+			Kind:      EventError,
+			Repo:      repo,
+			Text:      fmt.Sprintf("Watcher %v; restarting in %s", err, backoff),
+			Timestamp: time.Now(),
+		}:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, m.supervision.maxBackoff)
+
+		m.mu.Lock()
+		if ctx.Err() != nil {
+			m.mu.Unlock()
+			return
+		}
+		next := m.newWatcher(repo)
+		w.handOffComments(next)
+		w = next
+		m.repoWatchers[repo] = w
+		m.mu.Unlock()
+	}
+}
+
+// runWatcher runs w.Run, returning nil once ctx is cancelled or an error
+// saying why the watcher died: it panicked or missed its heartbeat.
+func (m *Manager) runWatcher(ctx context.Context, w *Watcher) error {
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel() // a stalled watcher may yet notice and exit
+
+	events := make(chan Event)
+	go m.relay(wctx, events)
+
+	w.beat()
+	done := make(chan error, 1)
+	go func() {
+		defer close(events)
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("crashed: %v (%s)", r, panicSite(debug.Stack()))
+			}
+		}()
+		w.Run(wctx, events)
+		done <- nil
+	}()
+
+	check := time.NewTicker(m.supervision.check)
+	defer check.Stop()
+	stall := m.supervision.stallAfter(m.pollInterval)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-check.C:
+			if since := time.Since(w.lastBeat()); since > stall {
+				return fmt.Errorf("stalled (no progress for %s)", since.Round(time.Second))
+			}
+		}
+	}
+}
+
+// relay passes a watcher's events on until ctx is done, and drops them
+// after, until events is closed: a stalled watcher that wakes once it was
+// given up on can neither block nor interleave its events with its
+// replacement's.
+func (m *Manager) relay(ctx context.Context, events <-chan Event) {
+	for ev := range events {
+		if ctx.Err() != nil {
+			continue
+		}
+		select {
+		case m.eventCh <- ev:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// handOffComments gives next the point w's look for comment commands got
+// to, so its first poll neither acts on a command again nor skips the
+// ones left while w was stuck, and stops w from acting on any more.
+func (w *Watcher) handOffComments(next *Watcher) {
+	w.commentsMu.Lock()
+	defer w.commentsMu.Unlock()
+	w.retired = true
+	next.commentsSince, next.commandsFrom, next.lastCommentID = w.commentsSince, w.commandsFrom, w.lastCommentID
+}
+
+// beat records that the watcher's loop is making progress.
+func (w *Watcher) beat() {
+	w.heartbeat.Store(time.Now().UnixNano())
+}
+
+// lastBeat is when the watcher last made progress.
+func (w *Watcher) lastBeat() time.Time {
+	return time.Unix(0, w.heartbeat.Load())
+}
+
+// panicSite picks the line that panicked out of a goroutine's stack: the
+// first frame after the runtime's own panic machinery.
+func panicSite(stack []byte) string {
+	lines := strings.Split(string(stack), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") && i+3 < len(lines) {
+			site, _, _ := strings.Cut(strings.TrimSpace(lines[i+3]), " +0x")
+			return site
+		}
+	}
+	return "unknown location"
+}
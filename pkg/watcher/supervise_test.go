@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+func TestPanicSite(t *testing.T) {
+	var site string
+	func() {
+		defer func() {
+			recover()
+			site = panicSite(debug.Stack())
+		}()
+		var m map[string]int
+		m["boom"] = 1
+	}()
+	if !strings.Contains(site, "supervise_test.go:") || strings.Contains(site, "+0x") {
+		t.Errorf("panicSite = %q, want this file's line", site)
+	}
+	if got := panicSite([]byte("goroutine 1 [running]:\n")); got != "unknown location" {
+		t.Errorf("panicSite(no panic) = %q", got)
+	}
+}
+
+func TestSupervise_RestartsStalledWatcher(t *testing.T) {
+	m, err := NewManager(t.TempDir(), 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.supervision.check = 5 * time.Millisecond
+	m.supervision.minStall = 20 * time.Millisecond
+	m.supervision.backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	t.Cleanup(func() { cancel(); <-stopped })
+
+	// A watcher still waiting out its start delay never beats
+	w := m.newWatcher("a/b")
+	w.cfg.StartDelay = time.Hour
+	since := time.Now().Add(-time.Minute)
+	w.commentsSince, w.commandsFrom, w.lastCommentID = since, since, 7
+	m.mu.Lock()
+	m.repoWatchers["a/b"] = w
+	m.mu.Unlock()
+	go func() {
+		m.supervise(ctx, w)
+		close(stopped)
+	}()
+
+	select {
+	case ev := <-m.EventCh():
+		if ev.Kind != EventError || ev.Repo != "a/b" || ev.IssueNum != 0 || !strings.Contains(ev.Text, "stalled") {
+			t.Fatalf("event = %+v, want a repo-level stall error", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the stalled watcher")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var next *Watcher
+	for {
+		m.mu.Lock()
+		next = m.repoWatchers["a/b"]
+		m.mu.Unlock()
+		if next != w {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stalled watcher was not replaced")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The replacement picks up comment commands where the stalled one
+	// left off, and the stalled one acts on no more.
+	next.commentsMu.Lock()
+	got, gotID := next.commentsSince, next.lastCommentID
+	next.commentsMu.Unlock()
+	if !got.Equal(since) || gotID != 7 {
+		t.Errorf("replacement's comments cursor = %v, %d; want %v, 7", got, gotID, since)
+	}
+	forge := &commentForge{comments: []github.Comment{comment(8, 1, "MEMBER", "@lurker start", time.Now())}}
+	w.ghClient = forge
+	ch := make(chan Event, 1)
+	w.pollCommands(context.Background(), ch)
+	if len(ch) != 0 || len(forge.reacted) != 0 {
+		t.Errorf("stalled watcher acted on a comment once replaced")
+	}
+}
+
+func TestRelay(t *testing.T) {
+	m, err := NewManager(t.TempDir(), 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	relayed := make(chan struct{})
+	go func() {
+		m.relay(ctx, events)
+		close(relayed)
+	}()
+
+	// A watcher's events pass on while it runs.
+	events <- Event{Kind: EventPollStart, Repo: "a/b"}
+	if ev := nextEvent(t, m); ev.Kind != EventPollStart {
+		t.Errorf("event = %+v", ev)
+	}
+
+	// Once it is given up on, a late one is dropped, not left blocking it.
+	cancel()
+	select {
+	case events <- Event{Kind: EventPollDone, Repo: "a/b"}:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a given-up watcher's send blocked")
+	}
+	close(events)
+	<-relayed
+	select {
+	case ev := <-m.EventCh():
+		t.Errorf("late event passed on: %+v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
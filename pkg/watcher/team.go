@@ -0,0 +1,289 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TeamConfig is a shared lurker policy kept in a git repo (or gist) so a
+// whole team runs the same setup. It lives in lurker.json at the root of
+// that repo.
+type TeamConfig struct {
+	Export
+
+	// Policy overrides the target repo's .lurker/config.json field by
+	// field. Repo-provided config comes from whoever can push to the
+	// watched repo; team policy comes from the operators.
+	Policy RepoConfig `json:"policy"`
+
+	// PromptTemplate replaces the built-in Claude prompt. It is a Go
+	// text/template executed with PromptData.
+	PromptTemplate string `json:"prompt_template,omitempty"`
+}
+
+// PromptData is passed to a TeamConfig.PromptTemplate.
+type PromptData struct {
+	Repo   string
+	Number int
+	Title  string
+	Labels string
+	Body   string
+}
+
+// teamConfigFile is the file read from the root of a team config repo.
+const teamConfigFile = "lurker.json"
+
+// LoadTeamConfig reads and validates lurker.json from a checked-out
+// team config repo.
+func LoadTeamConfig(dir string) (TeamConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, teamConfigFile))
+	if err != nil {
+		return TeamConfig{}, err
+	}
+	var cfg TeamConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return TeamConfig{}, fmt.Errorf("parsing %s: %w", teamConfigFile, err)
+	}
+	if cfg.Version != exportVersion {
+		return TeamConfig{}, fmt.Errorf("%s: unsupported version %d (want %d)", teamConfigFile, cfg.Version, exportVersion)
+	}
+	for _, r := range cfg.Repos {
+		if !validRepoName(r.Name) {
+			return TeamConfig{}, fmt.Errorf("%s: invalid repo name %q", teamConfigFile, r.Name)
+		}
+	}
+	if _, ok := toolchainByName(cfg.Policy.Toolchain); cfg.Policy.Toolchain != "" && !ok {
+		return TeamConfig{}, fmt.Errorf("%s: unknown toolchain %q", teamConfigFile, cfg.Policy.Toolchain)
+	}
+	if cfg.Policy.SquashMessage != "" {
+		if _, err := template.New("squash_message").Parse(cfg.Policy.SquashMessage); err != nil {
+			return TeamConfig{}, fmt.Errorf("%s: squash_message: %w", teamConfigFile, err)
+		}
+	}
+	if err := cfg.Policy.Templates.Validate(); err != nil {
+		return TeamConfig{}, fmt.Errorf("%s: templates.%w", teamConfigFile, err)
+	}
+	if cfg.PromptTemplate != "" {
+		if _, err := template.New("prompt").Parse(cfg.PromptTemplate); err != nil {
+			return TeamConfig{}, fmt.Errorf("%s: prompt_template: %w", teamConfigFile, err)
+		}
+	}
+	return cfg, nil
+}
+
+// apply overlays the team policy on a repo-provided config.
+func (p RepoConfig) apply(repo RepoConfig) RepoConfig {
+	if p.PromptPrefix != "" {
+		repo.PromptPrefix = p.PromptPrefix
+	}
+	if len(p.AllowedTools) > 0 {
+		repo.AllowedTools = p.AllowedTools
+	}
+	if p.BuildCommand != "" {
+		repo.BuildCommand = p.BuildCommand
+	}
+	if p.TestCommand != "" {
+		repo.TestCommand = p.TestCommand
+	}
+	if p.Toolchain != "" {
+		repo.Toolchain = p.Toolchain
+	}
+	if p.BaseBranch != "" {
+		repo.BaseBranch = p.BaseBranch
+	}
+	if len(p.Areas) > 0 {
+		repo.Areas = p.Areas
+	}
+	if p.LintCommand != "" {
+		repo.LintCommand = p.LintCommand
+	}
+	if p.FormatCommand != "" {
+		repo.FormatCommand = p.FormatCommand
+	}
+	if p.Verify {
+		repo.Verify = true
+	}
+	if p.RequireTests {
+		repo.RequireTests = true
+	}
+	if p.RequireBuild {
+		repo.RequireBuild = true
+	}
+	if len(p.Stages) > 0 {
+		repo.Stages = p.Stages
+	}
+	if p.PRSummary {
+		repo.PRSummary = true
+	}
+	if p.DraftPRs {
+		repo.DraftPRs = true
+	}
+	if p.ClaudePRs {
+		repo.ClaudePRs = true
+	}
+	if p.PRTemplate != "" {
+		repo.PRTemplate = p.PRTemplate
+	}
+	if len(p.PRReviewers) > 0 {
+		repo.PRReviewers = p.PRReviewers
+	}
+	if len(p.PRLabels) > 0 {
+		repo.PRLabels = p.PRLabels
+	}
+	if len(p.PRAssignees) > 0 {
+		repo.PRAssignees = p.PRAssignees
+	}
+	if p.CodeownersReviewers {
+		repo.CodeownersReviewers = true
+	}
+	if p.ProgressComments {
+		repo.ProgressComments = true
+	}
+	if p.UpdateMethod != "" {
+		repo.UpdateMethod = p.UpdateMethod
+	}
+	if p.DeleteBranches != "" {
+		repo.DeleteBranches = p.DeleteBranches
+	}
+	if p.AutoRebase {
+		repo.AutoRebase = true
+	}
+	if p.AddressReviews {
+		repo.AddressReviews = true
+	}
+	if len(p.ProtectedPaths) > 0 {
+		repo.ProtectedPaths = p.ProtectedPaths
+	}
+	if p.SquashMessage != "" {
+		repo.SquashMessage = p.SquashMessage
+	}
+	if p.ForcePush {
+		repo.ForcePush = true
+	}
+	if p.SandboxImage != "" {
+		repo.SandboxImage = p.SandboxImage
+	}
+	if p.Agent != "" {
+		repo.Agent = p.Agent
+	}
+	repo.Templates = p.Templates.apply(repo.Templates)
+	return repo
+}
+
+// RenderPrompt builds the Claude prompt for issue, using the team prompt
+// template if one is configured.
+func (c TeamConfig) RenderPrompt(repo string, issue Issue) (string, error) {
+	if c.PromptTemplate == "" {
+		return BuildClaudePrompt(repo, issue), nil
+	}
+	tmpl, err := template.New("prompt").Parse(c.PromptTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, PromptData{
+		Repo:   repo,
+		Number: issue.Number,
+		Title:  issue.Title,
+		Labels: issue.LabelNames(),
+		Body:   issue.Body,
+	})
+	return b.String(), err
+}
+
+// teamConfig returns the current team config (zero if none).
+func (m *Manager) teamConfig() TeamConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.team
+}
+
+// RepoConfig returns the config in effect for a worktree: its
+// .lurker/config.json with the team policy layered on top, and the agent
+// set with SetAgent if neither names one.
+func (m *Manager) RepoConfig(workdir string) RepoConfig {
+	cfg := m.teamConfig().Policy.apply(LoadRepoConfig(workdir))
+	if cfg.Agent == "" {
+		m.mu.Lock()
+		cfg.Agent = m.agent
+		m.mu.Unlock()
+	}
+	return cfg
+}
+
+// StartTeamSync clones url (a git repo or gist) into BaseDir and re-pulls
+// it every interval, applying its repos and policy. Repos it lists are
+// added to the watched set; repos you added yourself are kept.
+func (m *Manager) StartTeamSync(url string, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.teamCancel = cancel
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.syncTeamConfig(ctx, url)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (m *Manager) syncTeamConfig(ctx context.Context, url string) {
+	dir := filepath.Join(m.layout.Cache, "team-config")
+	report := func(text string) {
+		select {
+		case m.eventCh <- Event{Kind: EventTeamConfig, Text: text, Timestamp: time.Now()}:
+		case <-ctx.Done():
+		}
+	}
+
+	// The checkout follows whatever url is now, and whatever its branch
+	// is now, even if it was force-pushed.
+	cmds := [][]string{{"clone", "--depth", "1", url, dir}}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		cmds = [][]string{
+			{"-C", dir, "remote", "set-url", "origin", url},
+			{"-C", dir, "fetch", "--depth", "1", "origin", "HEAD"},
+			{"-C", dir, "reset", "-q", "--hard", "FETCH_HEAD"},
+		}
+	}
+	for _, args := range cmds {
+		if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			report(fmt.Sprintf("team config: git: %s", strings.TrimSpace(string(out))))
+			return
+		}
+	}
+
+	cfg, err := LoadTeamConfig(dir)
+	if err != nil {
+		report(fmt.Sprintf("team config: %v", err))
+		return
+	}
+
+	m.mu.Lock()
+	m.team = cfg
+	m.mu.Unlock()
+
+	added, err := m.Import(cfg.Export, false)
+	if err != nil {
+		report(fmt.Sprintf("team config: %v", err))
+		return
+	}
+	report(fmt.Sprintf("team config: synced (%d repos, %d new)", len(cfg.Repos), added))
+}
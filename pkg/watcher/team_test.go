@@ -0,0 +1,181 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTeamConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, teamConfigFile), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadTeamConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTeamConfig(t, dir, `{
+		"version": 1,
+		"repos": [{"name": "a/one"}],
+		"policy": {"test_command": "make test"},
+		"prompt_template": "Fix {{.Repo}}#{{.Number}}"
+	}`)
+
+	cfg, err := LoadTeamConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadTeamConfig: %v", err)
+	}
+	if len(cfg.Repos) != 1 || cfg.Repos[0].Name != "a/one" {
+		t.Errorf("Repos = %+v", cfg.Repos)
+	}
+	if cfg.Policy.TestCommand != "make test" {
+		t.Errorf("Policy.TestCommand = %q", cfg.Policy.TestCommand)
+	}
+}
+
+func TestLoadTeamConfig_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"bad version":  `{"version": 99}`,
+		"bad repo":     `{"version": 1, "repos": [{"name": "nope"}]}`,
+		"bad template": `{"version": 1, "prompt_template": "{{.Repo"}`,
+		"bad comment":  `{"version": 1, "policy": {"templates": {"start_comment": "{{end}}"}}}`,
+		"bad json":     `{`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTeamConfig(t, dir, content)
+			if _, err := LoadTeamConfig(dir); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPolicyApply(t *testing.T) {
+	repo := RepoConfig{
+		PromptPrefix: "repo prefix",
+		BuildCommand: "go build ./...",
+		TestCommand:  "go test ./...",
+	}
+	policy := RepoConfig{
+		TestCommand:  "make test",
+		AllowedTools: []string{"Read"},
+		DraftPRs:     true,
+	}
+
+	got := policy.apply(repo)
+	if got.PromptPrefix != "repo prefix" || got.BuildCommand != "go build ./..." {
+		t.Errorf("unset policy fields should keep repo values, got %+v", got)
+	}
+	if got.TestCommand != "make test" {
+		t.Errorf("TestCommand = %q, want policy value", got.TestCommand)
+	}
+	if len(got.AllowedTools) != 1 || got.AllowedTools[0] != "Read" {
+		t.Errorf("AllowedTools = %v", got.AllowedTools)
+	}
+	if !got.DraftPRs {
+		t.Error("DraftPRs should be forced on by the policy")
+	}
+	if got.Test() != "make test" || (RepoConfig{}).Build() != "bazel build //..." {
+		t.Errorf("Test() = %q, default Build() = %q", got.Test(), RepoConfig{}.Build())
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	issue := Issue{Number: 7, Title: "Crash", Body: "it crashes"}
+
+	def, err := TeamConfig{}.RenderPrompt("a/one", issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def != BuildClaudePrompt("a/one", issue) {
+		t.Error("empty template should fall back to the built-in prompt")
+	}
+
+	cfg := TeamConfig{PromptTemplate: "{{.Repo}}#{{.Number}}: {{.Title}}\n{{.Body}}"}
+	got, err := cfg.RenderPrompt("a/one", issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a/one#7: Crash\nit crashes"; got != want {
+		t.Errorf("RenderPrompt = %q, want %q", got, want)
+	}
+}
+
+func TestSyncTeamConfig(t *testing.T) {
+	src := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", src}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	writeTeamConfig(t, src, `{"version": 1, "repos": [{"name": "a/one"}]}`)
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	m, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer m.Stop()
+
+	m.syncTeamConfig(context.Background(), src)
+	if ev := <-m.EventCh(); ev.Kind != EventTeamConfig || !strings.Contains(ev.Text, "synced") {
+		t.Fatalf("event = %+v", ev)
+	}
+	if repos := m.Repos(); len(repos) != 1 || repos[0] != "a/one" {
+		t.Fatalf("Repos = %v", repos)
+	}
+
+	// A later pull picks up changes.
+	writeTeamConfig(t, src, `{"version": 1, "repos": [{"name": "a/one"}, {"name": "b/two"}], "policy": {"test_command": "make test"}}`)
+	git("commit", "-q", "-am", "add b/two")
+
+	m.syncTeamConfig(context.Background(), src)
+	<-m.EventCh()
+	if repos := m.Repos(); len(repos) != 2 {
+		t.Errorf("Repos = %v", repos)
+	}
+	if m.teamConfig().Policy.TestCommand != "make test" {
+		t.Errorf("policy not updated: %+v", m.teamConfig().Policy)
+	}
+
+	// Another URL is followed rather than merged with the old one.
+	other := t.TempDir()
+	gitIn(t, other, "init", "-q")
+	writeTeamConfig(t, other, `{"version": 1, "repos": [{"name": "c/three"}]}`)
+	gitIn(t, other, "add", ".")
+	gitIn(t, other, "commit", "-q", "-m", "elsewhere")
+
+	m.syncTeamConfig(context.Background(), other)
+	if ev := <-m.EventCh(); !strings.Contains(ev.Text, "synced") {
+		t.Fatalf("event = %+v", ev)
+	}
+	if m.teamConfig().Policy.TestCommand != "" || !slices.Contains(m.Repos(), "c/three") {
+		t.Errorf("still the old URL's config: %+v, repos %v", m.teamConfig(), m.Repos())
+	}
+
+	// So is a force-push.
+	writeTeamConfig(t, other, `{"version": 1, "repos": [{"name": "c/three"}], "policy": {"test_command": "go test"}}`)
+	gitIn(t, other, "commit", "-q", "--amend", "-am", "rewritten")
+	m.syncTeamConfig(context.Background(), other)
+	if ev := <-m.EventCh(); !strings.Contains(ev.Text, "synced") {
+		t.Fatalf("event after a force-push = %+v", ev)
+	}
+	if m.teamConfig().Policy.TestCommand != "go test" {
+		t.Errorf("policy after a force-push = %+v", m.teamConfig().Policy)
+	}
+}
@@ -0,0 +1,151 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultFooter ends lurker's own PR bodies unless Templates.Footer says
+// otherwise.
+const defaultFooter = "🤖 Generated by lurker"
+
+// Templates replace the text lurker posts to GitHub, e.g. to drop the
+// emoji and bot footer or to write in another language. Each is a Go
+// text/template; "" keeps lurker's own text.
+type Templates struct {
+	// PRBody is executed with PRBodyData.
+	PRBody string `json:"pr_body,omitempty"`
+
+	// StartComment, ReadyComment and FailureComment are the progress
+	// comments (see ProgressComments), SummaryComment the run summary
+	// posted on a new PR (see PRSummary) and AbandonComment the comment
+	// left on an abandoned issue; each is executed with CommentData.
+	StartComment   string `json:"start_comment,omitempty"`
+	ReadyComment   string `json:"ready_comment,omitempty"`
+	FailureComment string `json:"failure_comment,omitempty"`
+	SummaryComment string `json:"summary_comment,omitempty"`
+	AbandonComment string `json:"abandon_comment,omitempty"`
+
+	// Footer ends lurker's own PR bodies, and is PRBodyData.Footer; ""
+	// drops it. Unset, it is "🤖 Generated by lurker".
+	Footer *string `json:"footer,omitempty"`
+}
+
+// PRBodyData is passed to Templates.PRBody.
+type PRBodyData struct {
+	Number  int      // the issue the PR fixes
+	Title   string   // the issue's title
+	Summary string   // Claude's account of the run, or its PR description, as markdown; "" if neither
+	Commits []string // "sha subject", newest first
+	Footer  string
+}
+
+// CommentData is passed to the comment templates. Fields a comment has
+// no use for are left zero.
+type CommentData struct {
+	Repo       string
+	Number     int // the issue's
+	Branch     string
+	Commits    []string      // ready: "sha subject", oldest first
+	Files      []string      // ready: the files changed
+	Duration   time.Duration // ready and summary: how long the run took
+	CostUSD    float64       // ready and summary; 0 when unknown
+	Error      string        // failure
+	Suggestion string        // failure: lurker's suggested fix, if it has one
+	Summary    string        // summary: Claude's closing message
+	Commands   []string      // summary: shell commands Claude ran, in order
+	Turns      int           // summary
+	PRNumber   int           // summary: the PR commented on
+}
+
+// footer is the footer for lurker's own PR bodies.
+func (t Templates) footer() string {
+	if t.Footer != nil {
+		return *t.Footer
+	}
+	return defaultFooter
+}
+
+// Validate reports the first template that doesn't parse.
+func (t Templates) Validate() error {
+	for _, tt := range []struct{ name, text string }{
+		{"pr_body", t.PRBody},
+		{"start_comment", t.StartComment},
+		{"ready_comment", t.ReadyComment},
+		{"failure_comment", t.FailureComment},
+		{"summary_comment", t.SummaryComment},
+		{"abandon_comment", t.AbandonComment},
+	} {
+		if _, err := template.New(tt.name).Parse(tt.text); err != nil {
+			return fmt.Errorf("%s: %w", tt.name, err)
+		}
+	}
+	return nil
+}
+
+// apply overlays the team policy's templates on a repo's, template by
+// template.
+func (p Templates) apply(repo Templates) Templates {
+	if p.PRBody != "" {
+		repo.PRBody = p.PRBody
+	}
+	if p.StartComment != "" {
+		repo.StartComment = p.StartComment
+	}
+	if p.ReadyComment != "" {
+		repo.ReadyComment = p.ReadyComment
+	}
+	if p.FailureComment != "" {
+		repo.FailureComment = p.FailureComment
+	}
+	if p.SummaryComment != "" {
+		repo.SummaryComment = p.SummaryComment
+	}
+	if p.AbandonComment != "" {
+		repo.AbandonComment = p.AbandonComment
+	}
+	if p.Footer != nil {
+		repo.Footer = p.Footer
+	}
+	return repo
+}
+
+// render executes the template text, named name, with data, or returns
+// builtin's text if there is no template.
+func render(name, text string, data any, builtin func() string) (string, error) {
+	if text == "" {
+		return builtin(), nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%s template: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// prBody renders the body of a PR.
+func (t Templates) prBody(d PRBodyData) (string, error) {
+	return render("pr_body", t.PRBody, d, func() string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "Fixes #%d\n\n", d.Number)
+		if d.Summary != "" {
+			b.WriteString(strings.TrimRight(d.Summary, "\n") + "\n")
+		} else {
+			b.WriteString("## Commits\n```\n")
+			for _, c := range d.Commits {
+				b.WriteString(c + "\n")
+			}
+			b.WriteString("```\n")
+		}
+		if d.Footer != "" {
+			b.WriteString("\n" + d.Footer)
+		}
+		return b.String()
+	})
+}
@@ -0,0 +1,38 @@
+package watcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplatesPRBody(t *testing.T) {
+	data := PRBodyData{Number: 3, Title: "Crash", Commits: []string{"abc123 Handle empty input"}, Footer: Templates{}.footer()}
+	body, err := Templates{}.prBody(data)
+	if err != nil || body != "Fixes #3\n\n## Commits\n```\nabc123 Handle empty input\n```\n\n🤖 Generated by lurker" {
+		t.Errorf("default body = %q, %v", body, err)
+	}
+
+	none := ""
+	data.Footer = Templates{Footer: &none}.footer()
+	data.Summary = "## Summary\nSkips parsing.\n"
+	if body, _ := (Templates{}).prBody(data); body != "Fixes #3\n\n## Summary\nSkips parsing.\n" {
+		t.Errorf("body without footer = %q", body)
+	}
+
+	tmpl := Templates{PRBody: "Closes #{{.Number}}\n\n{{range .Commits}}* {{.}}\n{{end}}"}
+	if body, _ := tmpl.prBody(data); body != "Closes #3\n\n* abc123 Handle empty input\n" {
+		t.Errorf("templated body = %q", body)
+	}
+	if _, err := (Templates{PRBody: "{{.Nope}}"}).prBody(data); err == nil || !strings.Contains(err.Error(), "pr_body") {
+		t.Errorf("bad template error = %v", err)
+	}
+}
+
+func TestTemplatesApply(t *testing.T) {
+	none := ""
+	repo := Templates{PRBody: "repo body", StartComment: "repo start"}
+	got := Templates{StartComment: "team start", Footer: &none}.apply(repo)
+	if got.PRBody != "repo body" || got.StartComment != "team start" || got.footer() != "" {
+		t.Errorf("applied = %+v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Toolchain is how a project is built and tested, and which commands
+// Claude may run for it.
+type Toolchain struct {
+	Name    string
+	Build   string
+	Test    string
+	Tools   []string // Bash tool patterns, e.g. "Bash(go test:*)"
+	markers []string // files at the repo root that identify it
+}
+
+// toolchains lists the known toolchains in detection order. Bazel comes
+// first since Bazel repos usually also carry a go.mod or package.json.
+var toolchains = []Toolchain{
+	{
+		Name:    "bazel",
+		Build:   "bazel build //...",
+		Test:    "bazel test //...",
+		Tools:   []string{`Bash(bazel test:*)`, `Bash(bazel build:*)`},
+		markers: []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"},
+	},
+	{
+		Name:    "go",
+		Build:   "go build ./...",
+		Test:    "go test ./...",
+		Tools:   []string{`Bash(go build:*)`, `Bash(go test:*)`, `Bash(go vet:*)`},
+		markers: []string{"go.mod"},
+	},
+	{
+		Name:    "rust",
+		Build:   "cargo build",
+		Test:    "cargo test",
+		Tools:   []string{`Bash(cargo build:*)`, `Bash(cargo test:*)`, `Bash(cargo check:*)`},
+		markers: []string{"Cargo.toml"},
+	},
+	{
+		Name:    "pnpm",
+		Build:   "pnpm run build",
+		Test:    "pnpm test",
+		Tools:   []string{`Bash(pnpm test:*)`, `Bash(pnpm run build:*)`},
+		markers: []string{"pnpm-lock.yaml"},
+	},
+	{
+		Name:    "yarn",
+		Build:   "yarn build",
+		Test:    "yarn test",
+		Tools:   []string{`Bash(yarn test:*)`, `Bash(yarn build:*)`},
+		markers: []string{"yarn.lock"},
+	},
+	{
+		Name:    "node",
+		Build:   "npm run build",
+		Test:    "npm test",
+		Tools:   []string{`Bash(npm test:*)`, `Bash(npm run build:*)`},
+		markers: []string{"package.json"},
+	},
+	{
+		Name:    "python",
+		Build:   "python -m compileall -q .",
+		Test:    "python -m pytest",
+		Tools:   []string{`Bash(python -m pytest:*)`, `Bash(pytest:*)`, `Bash(python -m compileall:*)`},
+		markers: []string{"pyproject.toml", "setup.py", "requirements.txt"},
+	},
+}
+
+// defaultToolchain is used when nothing is detected, as lurker always has.
+var defaultToolchain = toolchains[0]
+
+// DetectToolchain returns the toolchain of the project checked out in
+// workdir, judged by the files at its root.
+func DetectToolchain(workdir string) (Toolchain, bool) {
+	if workdir == "" {
+		return Toolchain{}, false
+	}
+	for _, tc := range toolchains {
+		for _, marker := range tc.markers {
+			if _, err := os.Stat(filepath.Join(workdir, marker)); err == nil {
+				return tc, true
+			}
+		}
+	}
+	return Toolchain{}, false
+}
+
+// toolchainByName looks up a toolchain named in a config.
+func toolchainByName(name string) (Toolchain, bool) {
+	for _, tc := range toolchains {
+		if tc.Name == name {
+			return tc, true
+		}
+	}
+	return Toolchain{}, false
+}
+
+// toolchainPrompt is appended to the prompt so Claude uses the commands
+// it is allowed to run.
+func toolchainPrompt(c RepoConfig) string {
+	tc, ok := c.toolchain()
+	if !ok && c.BuildCommand == "" && c.TestCommand == "" {
+		return ""
+	}
+	what := "The project"
+	if ok {
+		what = fmt.Sprintf("This is a %s project. It", tc.Name)
+	}
+	return fmt.Sprintf("\n\n## Toolchain\n%s builds with `%s` and tests with `%s`.", what, c.Build(), c.Test())
+}
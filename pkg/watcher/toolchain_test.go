@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectToolchain(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"go.mod"}, "go"},
+		{[]string{"go.mod", "MODULE.bazel"}, "bazel"},
+		{[]string{"Cargo.toml"}, "rust"},
+		{[]string{"package.json"}, "node"},
+		{[]string{"package.json", "pnpm-lock.yaml"}, "pnpm"},
+		{[]string{"package.json", "yarn.lock"}, "yarn"},
+		{[]string{"pyproject.toml"}, "python"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for _, f := range tt.files {
+			os.WriteFile(filepath.Join(dir, f), nil, 0o644)
+		}
+		tc, ok := DetectToolchain(dir)
+		if tc.Name != tt.want || ok != (tt.want != "") {
+			t.Errorf("DetectToolchain(%v) = %q, %v; want %q", tt.files, tc.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestRepoConfig_Toolchain(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644)
+
+	cfg := LoadRepoConfig(dir)
+	if cfg.Build() != "go build ./..." || cfg.Test() != "go test ./..." {
+		t.Errorf("go commands = %q, %q", cfg.Build(), cfg.Test())
+	}
+	tools := cfg.ClaudeTools()
+	if !strings.Contains(tools, "Bash(go test:*)") || strings.Contains(tools, "bazel") {
+		t.Errorf("go tools = %q", tools)
+	}
+	if p := toolchainPrompt(cfg); !strings.Contains(p, "go project") || !strings.Contains(p, "`go test ./...`") {
+		t.Errorf("toolchainPrompt = %q", p)
+	}
+
+	// An explicit toolchain and commands win over detection.
+	cfg.Toolchain = "rust"
+	cfg.TestCommand = "make test"
+	if cfg.Build() != "cargo build" || cfg.Test() != "make test" {
+		t.Errorf("overridden commands = %q, %q", cfg.Build(), cfg.Test())
+	}
+
+	// Nothing detected keeps the bazel defaults and adds no prompt section.
+	empty := LoadRepoConfig(t.TempDir())
+	if empty.ClaudeTools() != claudeTools || empty.Build() != "bazel build //..." || toolchainPrompt(empty) != "" {
+		t.Errorf("undetected config = %q, %q, %q", empty.ClaudeTools(), empty.Build(), toolchainPrompt(empty))
+	}
+}
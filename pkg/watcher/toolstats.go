@@ -0,0 +1,124 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Limits on how much of a run's tool use ToolStats.Lines names.
+const (
+	statsTools = 5
+	statsFiles = 3
+)
+
+// ToolStats counts what Claude did in a run: its tool calls by tool and
+// the files it changed.
+type ToolStats struct {
+	Calls map[string]int
+	Files []string // written or edited, in the order first changed
+}
+
+// add counts a tool_use block. Files under workdir are recorded relative
+// to it.
+func (s *ToolStats) add(block contentBlock, workdir string) {
+	if block.Name == "" {
+		return
+	}
+	if s.Calls == nil {
+		s.Calls = map[string]int{}
+	}
+	s.Calls[block.Name]++
+	switch block.Name {
+	case "Edit", "Write", "MultiEdit", "NotebookEdit":
+	default:
+		return
+	}
+	var input struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	json.Unmarshal(block.Input, &input)
+	path := input.FilePath
+	if path == "" {
+		path = input.NotebookPath
+	}
+	if rel, err := filepath.Rel(workdir, path); err == nil && filepath.IsAbs(path) && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	if path != "" && !slices.Contains(s.Files, path) {
+		s.Files = append(s.Files, path)
+	}
+}
+
+// Total is the number of tool calls.
+func (s ToolStats) Total() int {
+	n := 0
+	for _, c := range s.Calls {
+		n += c
+	}
+	return n
+}
+
+// Lines describes s in a few log lines: the tool calls, most used tools
+// first, the files changed and the commands run.
+func (s ToolStats) Lines() []string {
+	if s.Total() == 0 {
+		return nil
+	}
+	tools := make([]string, 0, len(s.Calls))
+	for tool := range s.Calls {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if s.Calls[tools[i]] != s.Calls[tools[j]] {
+			return s.Calls[tools[i]] > s.Calls[tools[j]]
+		}
+		return tools[i] < tools[j]
+	})
+	var counts []string
+	for i, tool := range tools {
+		if i == statsTools {
+			counts = append(counts, fmt.Sprintf("+%d more", len(tools)-i))
+			break
+		}
+		counts = append(counts, fmt.Sprintf("%s %d", tool, s.Calls[tool]))
+	}
+	lines := []string{fmt.Sprintf("%s — %s", plural(s.Total(), "tool call"), strings.Join(counts, ", "))}
+
+	if len(s.Files) > 0 {
+		names := s.Files
+		more := ""
+		if len(names) > statsFiles {
+			names, more = names[:statsFiles], fmt.Sprintf(", +%d more", len(s.Files)-statsFiles)
+		}
+		lines = append(lines, fmt.Sprintf("%s changed — %s%s", plural(len(s.Files), "file"), strings.Join(names, ", "), more))
+	}
+	if n := s.Calls["Bash"]; n > 0 {
+		lines = append(lines, plural(n, "command")+" run")
+	}
+	return lines
+}
+
+// plural formats n of thing, adding an s unless n is 1.
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// reportToolStats logs the tool use of the latest Claude session in
+// workdir.
+func (w *Watcher) reportToolStats(eventCh chan<- Event, num int, workdir string) {
+	s, err := LoadRunSummary(workdir)
+	if err != nil {
+		return
+	}
+	for _, line := range s.Tools.Lines() {
+		w.emit(eventCh, EventToolStats, num, line)
+	}
+}
@@ -0,0 +1,39 @@
+package watcher
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToolStats(t *testing.T) {
+	var s ToolStats
+	if lines := s.Lines(); lines != nil {
+		t.Errorf("empty stats = %v", lines)
+	}
+	workdir := "/work/repo"
+	for _, call := range []struct{ name, input string }{
+		{"Read", `{"file_path":"/work/repo/main.go"}`},
+		{"Edit", `{"file_path":"/work/repo/main.go"}`},
+		{"Edit", `{"file_path":"/work/repo/main.go"}`},
+		{"Write", `{"file_path":"/work/repo/pkg/a.go"}`},
+		{"MultiEdit", `{"file_path":"/elsewhere/b.go"}`},
+		{"NotebookEdit", `{"notebook_path":"nb.ipynb"}`},
+		{"Bash", `{"command":"go test ./..."}`},
+		{"Bash", `{"command":"go vet ./..."}`},
+		{"Grep", `{"pattern":"x"}`},
+	} {
+		s.add(contentBlock{Type: "tool_use", Name: call.name, Input: json.RawMessage(call.input)}, workdir)
+	}
+	if s.Total() != 9 {
+		t.Errorf("Total() = %d", s.Total())
+	}
+	want := []string{
+		"9 tool calls — Bash 2, Edit 2, Grep 1, MultiEdit 1, NotebookEdit 1, +2 more",
+		"4 files changed — main.go, pkg/a.go, /elsewhere/b.go, +1 more",
+		"2 commands run",
+	}
+	if got := s.Lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
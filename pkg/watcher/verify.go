@@ -0,0 +1,293 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// VerificationFile holds lurker's own check of an issue's branch, in its
+// IssueDir. It is written after runs of repos with "verify" configured
+// and posted as commit statuses when the branch is pushed.
+const VerificationFile = "verification.json"
+
+// maxCheckOutput is how many trailing lines of a failed check are kept.
+const maxCheckOutput = 20
+
+// Verification is the result of running a repo's checks on a worktree.
+type Verification struct {
+	SHA    string        `json:"sha"`             // HEAD when verified
+	Dirty  bool          `json:"dirty,omitempty"` // uncommitted changes were checked too
+	At     time.Time     `json:"at"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult is one check of a Verification.
+type CheckResult struct {
+	Name    string `json:"name"` // "build", "test" or "lint"
+	Command string `json:"command"`
+	Passed  bool   `json:"passed"`
+	Seconds int    `json:"seconds"`
+	Output  string `json:"output,omitempty"` // tail of the output of a failed check
+}
+
+// Confidence is lurker's overall assessment: "high" when every check
+// passed, "medium" when only lint failed, else "low".
+func (v Verification) Confidence() string {
+	confidence := "high"
+	for _, c := range v.Checks {
+		if c.Passed {
+			continue
+		}
+		if c.Name != "lint" {
+			return "low"
+		}
+		confidence = "medium"
+	}
+	return confidence
+}
+
+// Statuses renders v as commit statuses: one per check, and a "lurker"
+// summary carrying the confidence.
+func (v Verification) Statuses() []github.CommitStatus {
+	var statuses []github.CommitStatus
+	var passed, failed []string
+	for _, c := range v.Checks {
+		st := github.CommitStatus{State: "success", Context: "lurker/" + c.Name}
+		if c.Passed {
+			passed = append(passed, c.Name)
+			st.Description = fmt.Sprintf("%s passed in %ds", c.Command, c.Seconds)
+		} else {
+			failed = append(failed, c.Name)
+			st.State = "failure"
+			st.Description = fmt.Sprintf("%s failed after %ds", c.Command, c.Seconds)
+		}
+		statuses = append(statuses, st)
+	}
+
+	summary := github.CommitStatus{State: "success", Context: "lurker", Description: "Confidence: " + v.Confidence()}
+	if len(failed) > 0 {
+		summary.State = "failure"
+		summary.Description += "; failed: " + strings.Join(failed, ", ")
+	}
+	if len(passed) > 0 {
+		summary.Description += "; passed: " + strings.Join(passed, ", ")
+	}
+	return append(statuses, summary)
+}
+
+// Verify runs cfg's build, test and (if configured) lint commands in
+// workdir.
+func Verify(ctx context.Context, workdir string, cfg RepoConfig) (Verification, error) {
+	sha, err := gitCmd(ctx, workdir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return Verification{}, err
+	}
+	status, err := gitCmd(ctx, workdir, nil, "status", "--porcelain")
+	if err != nil {
+		return Verification{}, err
+	}
+	v := Verification{SHA: sha, Dirty: status != "", At: time.Now()}
+
+	checks := [][2]string{{"build", cfg.Build()}, {"test", cfg.Test()}}
+	if cfg.LintCommand != "" {
+		checks = append(checks, [2]string{"lint", cfg.LintCommand})
+	}
+	for _, check := range checks {
+		start := time.Now()
+		cmd := shellCommand(ctx, workdir, check[1])
+		out, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			return Verification{}, ctx.Err()
+		}
+		res := CheckResult{Name: check[0], Command: check[1], Passed: err == nil, Seconds: int(time.Since(start).Seconds())}
+		if err != nil {
+			lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+			res.Output = strings.Join(lines[max(len(lines)-maxCheckOutput, 0):], "\n")
+		}
+		v.Checks = append(v.Checks, res)
+	}
+	return v, nil
+}
+
+// WriteVerification saves v in issueDir.
+func WriteVerification(issueDir string, v Verification) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(issueDir, VerificationFile), append(data, '\n'), 0o644)
+}
+
+// LoadVerification reads issueDir's verification, if any.
+func LoadVerification(issueDir string) (Verification, bool) {
+	data, err := os.ReadFile(filepath.Join(issueDir, VerificationFile))
+	if err != nil {
+		return Verification{}, false
+	}
+	var v Verification
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Verification{}, false
+	}
+	return v, true
+}
+
+// ErrNoVerification means a branch was pushed that lurker hasn't verified.
+var ErrNoVerification = errors.New("not verified")
+
+// PostVerification posts the verification of the worktree workdir as
+// commit statuses on its HEAD, once that HEAD has been pushed. A
+// verification of another commit, or of uncommitted changes, is stale and
+// not posted.
+func PostVerification(ctx context.Context, gh Forge, repo, workdir string) error {
+	v, ok := LoadVerification(filepath.Dir(workdir))
+	if !ok {
+		return ErrNoVerification
+	}
+	head, err := gitCmd(ctx, workdir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if v.SHA != head || v.Dirty {
+		return fmt.Errorf("verification of %.8s is stale; not posting statuses", v.SHA)
+	}
+	for _, st := range v.Statuses() {
+		if err := gh.CreateStatus(ctx, repo, head, st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify is the verify stage: it checks a finished run's branch with
+// Verify, saves the result and logs it. It only informs; require_build
+// and require_tests hold a failing branch back.
+func (w *Watcher) verify(ctx context.Context, eventCh chan<- Event, num int, workdir string, cfg RepoConfig) {
+	w.emit(eventCh, EventVerify, num, "Verifying...")
+	v, err := Verify(ctx, workdir, cfg)
+	if err == nil {
+		err = WriteVerification(filepath.Dir(workdir), v)
+	}
+	switch {
+	case ctx.Err() != nil:
+	case err != nil:
+		w.emit(eventCh, EventVerify, num, fmt.Sprintf("Verification failed: %v", err))
+	default:
+		for _, line := range v.Summary() {
+			w.emit(eventCh, EventVerify, num, line)
+		}
+	}
+}
+
+// A gate is a command a finished run's branch must pass to be marked
+// ready.
+type gate struct {
+	check   string // its CheckResult's name in a Verification, if any
+	command string
+	started EventKind // Text says what runs
+	passed  EventKind // Text says how long it took
+	failed  EventKind // Text says how it failed
+}
+
+func buildGate(cfg RepoConfig) gate {
+	return gate{check: "build", command: cfg.Build(), started: EventBuilding, passed: EventBuildPassed, failed: EventBuildFailed}
+}
+
+func testGate(cfg RepoConfig) gate {
+	return gate{check: "test", command: cfg.Test(), started: EventVerify, passed: EventVerify, failed: EventTestsFailed}
+}
+
+func lintGate(cfg RepoConfig) gate {
+	return gate{check: "lint", command: cfg.LintCommand, started: EventVerify, passed: EventVerify, failed: EventVerify}
+}
+
+// gate runs g's command in workdir, streaming its output as
+// EventGateOutput, and reports whether it passed, having emitted g.failed
+// if not. A verification of HEAD that ran the same check stands in for
+// running it again.
+func (w *Watcher) gate(ctx context.Context, eventCh chan<- Event, num int, workdir string, g gate) bool {
+	if c, ok := verifiedCheck(ctx, workdir, g.check); ok {
+		if !c.Passed {
+			w.emit(eventCh, g.failed, num, fmt.Sprintf("%s failed", c.Command))
+			return false
+		}
+		w.emit(eventCh, g.passed, num, fmt.Sprintf("✓ %s passed (verified)", c.Command))
+		return true
+	}
+
+	w.emit(eventCh, g.started, num, "Running "+g.command+"...")
+	start := time.Now()
+	cmd := shellCommand(ctx, workdir, g.command)
+	r, pw := io.Pipe()
+	cmd.Stdout, cmd.Stderr = pw, pw
+	if err := cmd.Start(); err != nil {
+		w.emit(eventCh, g.failed, num, fmt.Sprintf("%s: %v", g.command, err))
+		return false
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+		pw.Close()
+	}()
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		w.emit(eventCh, EventGateOutput, num, sc.Text())
+	}
+	io.Copy(io.Discard, r) // past a line too long to scan
+	err := <-done
+	if ctx.Err() != nil {
+		return false
+	}
+	took := time.Since(start).Round(time.Second)
+	if err != nil {
+		w.emit(eventCh, g.failed, num, fmt.Sprintf("%s failed after %s: %v", g.command, took, err))
+		return false
+	}
+	w.emit(eventCh, g.passed, num, fmt.Sprintf("✓ %s passed (%s)", g.command, took))
+	return true
+}
+
+// verifiedCheck returns the check named name of the verification of
+// workdir's HEAD, if one ran it with nothing uncommitted.
+func verifiedCheck(ctx context.Context, workdir, name string) (CheckResult, bool) {
+	v, found := LoadVerification(filepath.Dir(workdir))
+	if !found || v.Dirty {
+		return CheckResult{}, false
+	}
+	head, err := gitCmd(ctx, workdir, nil, "rev-parse", "HEAD")
+	if err != nil || v.SHA != head {
+		return CheckResult{}, false
+	}
+	for _, c := range v.Checks {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// Summary describes v in a few log lines.
+func (v Verification) Summary() []string {
+	var lines []string
+	for _, c := range v.Checks {
+		if c.Passed {
+			lines = append(lines, fmt.Sprintf("✓ %s (%ds)", c.Name, c.Seconds))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("✗ %s: %s failed (%ds)", c.Name, c.Command, c.Seconds))
+		if c.Output != "" {
+			out := strings.Split(c.Output, "\n")
+			lines = append(lines, "  "+out[len(out)-1])
+		}
+	}
+	return append(lines, "Confidence: "+v.Confidence())
+}
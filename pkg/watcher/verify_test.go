@@ -0,0 +1,188 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	issueDir := t.TempDir()
+	workdir := filepath.Join(issueDir, "repo")
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+
+	cfg := RepoConfig{BuildCommand: "true", TestCommand: "echo boom; exit 1", LintCommand: "true"}
+	v, err := Verify(context.Background(), workdir, cfg)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if v.SHA != strings.TrimSpace(gitIn(t, workdir, "rev-parse", "HEAD")) || v.Dirty {
+		t.Errorf("verified %s (dirty %v)", v.SHA, v.Dirty)
+	}
+	if len(v.Checks) != 3 || !v.Checks[0].Passed || v.Checks[1].Passed || v.Checks[1].Output != "boom" {
+		t.Fatalf("checks = %+v", v.Checks)
+	}
+	if c := v.Confidence(); c != "low" {
+		t.Errorf("confidence = %s, want low", c)
+	}
+
+	statuses := v.Statuses()
+	if len(statuses) != 4 {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if st := statuses[1]; st.Context != "lurker/test" || st.State != "failure" {
+		t.Errorf("test status = %+v", st)
+	}
+	if st := statuses[3]; st.Context != "lurker" || st.State != "failure" || !strings.HasPrefix(st.Description, "Confidence: low") {
+		t.Errorf("summary status = %+v", st)
+	}
+
+	if err := WriteVerification(issueDir, v); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := LoadVerification(issueDir); !ok || got.SHA != v.SHA || len(got.Checks) != 3 {
+		t.Errorf("LoadVerification = %+v, %v", got, ok)
+	}
+
+	// Uncommitted changes make the result unpostable.
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("two\n"), 0o644)
+	if v, _ := Verify(context.Background(), workdir, RepoConfig{BuildCommand: "true", TestCommand: "true"}); !v.Dirty || len(v.Checks) != 2 {
+		t.Errorf("dirty verification = %+v", v)
+	}
+}
+
+func TestVerification_Confidence(t *testing.T) {
+	for _, tc := range []struct {
+		checks []CheckResult
+		want   string
+	}{
+		{[]CheckResult{{Name: "build", Passed: true}, {Name: "test", Passed: true}}, "high"},
+		{[]CheckResult{{Name: "build", Passed: true}, {Name: "lint"}}, "medium"},
+		{[]CheckResult{{Name: "build"}, {Name: "lint"}}, "low"},
+	} {
+		if got := (Verification{Checks: tc.checks}).Confidence(); got != tc.want {
+			t.Errorf("Confidence(%+v) = %s, want %s", tc.checks, got, tc.want)
+		}
+	}
+}
+
+// withoutStages returns the events of events but the pipeline's own.
+func withoutStages(events <-chan Event) []Event {
+	var got []Event
+	for ev := range events {
+		switch ev.Kind {
+		case EventPipeline, EventStageStarted, EventStagePassed, EventStageFailed:
+		default:
+			got = append(got, ev)
+		}
+	}
+	return got
+}
+
+func TestVerify_RequireTests(t *testing.T) {
+	issueDir := t.TempDir()
+	workdir := filepath.Join(issueDir, "repo")
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(workdir, "a.txt"), []byte("one\n"), 0o644)
+	gitIn(t, workdir, "add", ".")
+	gitIn(t, workdir, "commit", "-q", "-m", "init")
+
+	w := &Watcher{cfg: Config{Repo: "o/r"}}
+	run := func(cfg RepoConfig) (bool, []Event) {
+		events := make(chan Event, 20)
+		ok := w.runStages(context.Background(), events, 4, workdir, cfg)
+		close(events)
+		return ok, withoutStages(events)
+	}
+
+	if ok, events := run(RepoConfig{TestCommand: "exit 1"}); !ok || len(events) != 0 {
+		t.Errorf("without require_tests: %v, %+v", ok, events)
+	}
+
+	ok, events := run(RepoConfig{TestCommand: "echo one; echo two >&2; exit 1", RequireTests: true})
+	var output []string
+	for _, ev := range events {
+		if ev.Kind == EventGateOutput {
+			output = append(output, ev.Text)
+		}
+	}
+	if ok || strings.Join(output, ",") != "one,two" || events[len(events)-1].Kind != EventTestsFailed {
+		t.Errorf("failing tests: %v, %+v", ok, events)
+	}
+
+	if ok, _ := run(RepoConfig{TestCommand: "true", RequireTests: true}); !ok {
+		t.Error("passing tests didn't let the run be ready")
+	}
+
+	// Tests a verification just ran aren't run again.
+	ok, events = run(RepoConfig{BuildCommand: "true", TestCommand: "echo ran; exit 1", Verify: true, RequireTests: true})
+	for _, ev := range events {
+		if ev.Kind == EventGateOutput {
+			t.Errorf("tests ran twice: %+v", ev)
+		}
+	}
+	if ok || events[len(events)-1].Kind != EventTestsFailed {
+		t.Errorf("verified failing tests: %v, %+v", ok, events)
+	}
+	os.Remove(filepath.Join(issueDir, VerificationFile))
+
+	// A failing build stops the run before the tests.
+	ok, events = run(RepoConfig{BuildCommand: "exit 2", TestCommand: "echo ran", RequireBuild: true, RequireTests: true})
+	if ok || events[0].Kind != EventBuilding || events[len(events)-1].Kind != EventBuildFailed {
+		t.Errorf("failing build: %v, %+v", ok, events)
+	}
+	ok, events = run(RepoConfig{BuildCommand: "true", TestCommand: "true", RequireBuild: true, RequireTests: true})
+	if !ok || events[1].Kind != EventBuildPassed {
+		t.Errorf("passing build: %v, %+v", ok, events)
+	}
+}
+
+func TestVerify_FormatAndLint(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@example.com")
+	workdir := filepath.Join(t.TempDir(), "repo")
+	os.MkdirAll(workdir, 0o755)
+	gitIn(t, workdir, "init", "-q", "-b", "main")
+	writeAndCommit(t, workdir, "a.txt", "one  \n")
+
+	w := &Watcher{cfg: Config{Repo: "o/r"}}
+	run := func(cfg RepoConfig) (bool, []Event) {
+		events := make(chan Event, 20)
+		ok := w.runStages(context.Background(), events, 4, workdir, cfg)
+		close(events)
+		return ok, withoutStages(events)
+	}
+
+	// What the format command changes is committed.
+	ok, events := run(RepoConfig{FormatCommand: "sed -i 's/ *$//' a.txt"})
+	if !ok || len(events) != 2 || !strings.Contains(events[1].Text, "reformatted 1 file") {
+		t.Errorf("format: %v, %+v", ok, events)
+	}
+	if subject := gitIn(t, workdir, "log", "-1", "--format=%s"); strings.TrimSpace(subject) != formatMessage {
+		t.Errorf("last commit = %q", subject)
+	}
+	if status := gitIn(t, workdir, "status", "--porcelain"); status != "" {
+		t.Errorf("left uncommitted: %q", status)
+	}
+
+	// A failing format command's changes are undone.
+	ok, _ = run(RepoConfig{FormatCommand: "echo two > a.txt; exit 1"})
+	if data, _ := os.ReadFile(filepath.Join(workdir, "a.txt")); !ok || string(data) != "one\n" {
+		t.Errorf("failed format: %v, a.txt = %q", ok, data)
+	}
+
+	// A failing lint is logged but doesn't hold the branch back.
+	ok, events = run(RepoConfig{LintCommand: "echo bad; exit 1"})
+	if !ok || events[len(events)-1].Kind != EventVerify || !strings.Contains(events[len(events)-1].Text, "failed") {
+		t.Errorf("lint: %v, %+v", ok, events)
+	}
+}
@@ -3,12 +3,19 @@ package watcher
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/stefanpenner/lurker/pkg/github"
@@ -28,7 +35,48 @@ const (
 	EventClaudeLog             // line of claude output
 	EventClaudeDone            // claude finished (success/fail)
 	EventReady                 // branch ready for review
-	EventError                 // something failed
+	EventError                 // something failed; an issue's ends its run, unlike EventWarning
+	EventRepoMoved             // repo renamed/transferred; Text is the new "owner/repo"
+	EventIssueMoved            // issue transferred; see MovedRepo/MovedNum
+	EventTeamConfig            // team config synced or failed; Text describes it
+	EventCleanup               // repo files being deleted; Text is progress
+	EventCleanupDone           // repo file deletion finished or failed
+	EventNeedsInput            // claude stopped with questions; Text is QUESTIONS.md
+	EventCheckpoint            // worktree checkpointed or branch backed up; Text describes it
+	EventVerify                // lurker's own checks of a finished run; Text is a result line
+	EventQueued                // waiting for a free Claude slot; Text says why
+	EventDiscovery             // org repo discovery added repos or failed; Text describes it
+	EventCommand               // a teammate commented "@lurker <Text>" on the issue; see By
+	EventProgress              // progress comment posted on the issue, or failed; Text describes it
+	EventToolStats             // what Claude's tool calls did in a run; Text is a stats line
+	EventToolRequest           // claude was refused tools and waits for approval; Text lists them
+
+	// Kinds are journaled by value, so new ones go last.
+	EventChecksPassed // every CI check on the issue's PR passed; Text counts them
+	EventChecksFailed // a CI check on the issue's PR failed; Text names the failures
+	EventChecksNone   // no CI checks were reported, or watching them gave up; Text says why
+	EventResult       // claude left a RESULT.json; Text is RunResult.Lines
+	EventFeedback     // new reviews or comments on the issue's PR; Text is FeedbackLines
+	EventFeedbackDone // claude's fixes for PR feedback were pushed; Text describes them
+	EventRebased      // the branch was rebased onto the latest base; Text is BranchUpdate.String
+	EventConflict     // rebasing the branch conflicted; Text is BranchUpdate.String
+	EventGateOutput   // a line the build or test command printed before the branch was marked ready
+	EventTestsFailed  // the test command failed, so the branch wasn't marked ready; Text says how
+	EventBranchGone   // the issue's branch was deleted from its remote, or deleting it failed; Text says which
+	EventBuilding     // require_build's build command started; Text is the command
+	EventBuildPassed  // the build command succeeded; Text says how long it took
+	EventBuildFailed  // the build command failed, so the branch wasn't marked ready; Text says how
+	EventAbandoned    // the issue was handed back and is ignored from now on; Text says what was cleaned up
+	EventStatus       // the issue's status, as Manager.Issue reports it, changed; Text is the new IssueStatus
+	EventPipeline     // a finished run's branch is going through its pipeline; Text lists its stages, one per line
+	EventStageStarted // a stage of the pipeline started; Text is its name
+	EventStagePassed  // the stage passed; Text is its name
+	EventStageFailed  // the stage failed, so the branch wasn't marked ready; Text is its name
+	EventIssueGone    // the issue left the repo's open issues other than by transfer; Text says how
+	EventGateWaiting  // the run waits for approval to go on, as the repo's stages gate it; Text names the stage done
+	EventGateApproved // the waiting run was let go on; Text names the stage it waited after
+	EventCost         // what the Claude run that just ended cost; Text is the dollars, e.g. "0.4213"
+	EventWarning      // something went wrong that doesn't end the run, unlike EventError; Text says what
 )
 
 // Event is sent from the watcher to the TUI.
@@ -42,6 +90,14 @@ type Event struct {
 	IssueURL    string
 	IssueBody   string
 	IssueLabels string
+	IssueOpened time.Time
+	// Extra fields for EventIssueMoved
+	MovedRepo string
+	MovedNum  int
+	// Extra fields for EventCommand
+	By string // who commented
+	// Extra fields for EventError
+	Repeats int // identical errors held back since the last one; see dedupError
 }
 
 // IssueStatus tracks the lifecycle of an issue being processed.
@@ -55,7 +111,13 @@ const (
 	StatusClaudeRunning
 	StatusReady
 	StatusFailed
-	StatusPaused // user paused processing
+	StatusPaused      // user paused processing
+	StatusNeedsInput  // claude is waiting for answers to QUESTIONS.md, or for tools to be allowed
+	StatusQueued      // cloned, waiting for a free Claude slot
+	StatusConflict    // the branch conflicts with the latest base branch
+	StatusTestsFailed // the run finished but require_tests' tests failed
+	StatusBuildFailed // the run finished but require_build's build failed
+	StatusWaiting     // a gated stage is done; the run goes on once approved
 )
 
 func (s IssueStatus) String() string {
@@ -76,6 +138,18 @@ func (s IssueStatus) String() string {
 		return "failed"
 	case StatusPaused:
 		return "paused"
+	case StatusNeedsInput:
+		return "needs-input"
+	case StatusQueued:
+		return "queued"
+	case StatusConflict:
+		return "conflict"
+	case StatusTestsFailed:
+		return "tests-failed"
+	case StatusBuildFailed:
+		return "build-failed"
+	case StatusWaiting:
+		return "waiting"
 	default:
 		return "unknown"
 	}
@@ -92,6 +166,17 @@ func IssueKey(repo string, num int) string {
 	return fmt.Sprintf("%s#%d", repo, num)
 }
 
+// ParseIssueKey splits an IssueKey ("owner/repo#42") into its repo and
+// number.
+func ParseIssueKey(key string) (string, int, error) {
+	repo, numStr, ok := strings.Cut(key, "#")
+	num, err := strconv.Atoi(numStr)
+	if !ok || err != nil || num <= 0 || strings.Count(repo, "/") != 1 || strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+		return "", 0, fmt.Errorf("%q is not owner/repo#number", key)
+	}
+	return repo, num, nil
+}
+
 // TrackedIssue represents an issue being processed by the watcher.
 type TrackedIssue struct {
 	Repo      string
@@ -104,61 +189,172 @@ type TrackedIssue struct {
 	Workdir   string
 	Error     string
 	StartedAt time.Time
+	CreatedAt time.Time // when the issue was opened on GitHub
+	PRNumber  int       // pull request created from this issue, if any
+	PRURL     string
+	Checks    ChecksState
+	Stages    []StageState // of the latest run's pipeline, once it got there
+	CostUSD   float64      // Claude spend reported for the latest run
+	Attempts  int          // times processing was started
+	Questions string       // what claude asked, while StatusNeedsInput
 }
 
 // State is persisted to disk to remember repos and processed issues.
 type State struct {
 	Repos     []string         `json:"repos"`
 	Processed map[string][]int `json:"processed"`
+	// RunSeconds holds recent successful run durations per repo, used to
+	// estimate progress of in-flight runs.
+	RunSeconds map[string][]int `json:"run_seconds,omitempty"`
+	// BaseBranches holds the base branch chosen for a repo, if any.
+	BaseBranches map[string]string `json:"base_branches,omitempty"`
+	// PatchMode holds the repos whose issues run in patch mode.
+	PatchMode map[string]bool `json:"patch_mode,omitempty"`
+	// Ignored holds the issues abandoned per repo, which lurker no
+	// longer picks up.
+	Ignored map[string][]int `json:"ignored,omitempty"`
+	// Orgs holds the owners whose repos are discovered and watched.
+	Orgs []OrgWatch `json:"orgs,omitempty"`
 }
 
+// maxRunHistory bounds how many run durations are kept per repo.
+const maxRunHistory = 20
+
 // Manager manages multiple repo watchers.
 type Manager struct {
 	baseDir      string
+	layout       Layout
+	bwrap        *Bwrap // confines Claude and the issues' shells; nil for none
+	agent        string // the agent of repos whose config names none; "" for DefaultAgent
+	sandboxImage string // the image every repo's issues run in; "" leaves it to the repos
 	pollInterval time.Duration
-	ghClient     *github.Client
-	eventCh      chan Event
+	ghClient     Forge
+	eventCh      chan Event // from watchers, journaled on the way to outCh
+	outCh        chan Event
+	journal      *journal
+	stats        *statsRecorder
+	claudeSlots  chan struct{} // a token per running Claude; nil is unlimited
+	supervision  supervision
 	mu           sync.Mutex
 	watchers     map[string]context.CancelFunc
 	repoWatchers map[string]*Watcher
 	knownIssues  map[string]Issue
 	issueCtxs    map[string]context.CancelFunc
-	issuePTYs    map[string]IssuePTY // PTY sessions per issue key
+	issuePTYs    map[string]IssuePTY  // PTY sessions per issue key
+	hooked       map[string]time.Time // repo → last webhook delivery
+	replayed     map[string]bool      // known issues replayed from the journal, not yet re-polled
 	state        State
 	statePath    string
+	started      bool // Start has been called
+	team         TeamConfig
+	teamCancel   context.CancelFunc
+	discoverNow  chan struct{} // wakes repo discovery early
+	discoverStop context.CancelFunc
+	// bgCtx bounds the Manager's background work: the deletions of
+	// DeleteRepoFiles, which the next Start finishes if Stop cancels
+	// them, and sending the events announce queues.
+	bgCtx  context.Context
+	bgStop context.CancelFunc
+	// announced holds the events announce queued, oldest first, until
+	// announceLoop sends them; announceWake tells it there are some.
+	announceMu   sync.Mutex
+	announced    []Event
+	announceWake chan struct{}
+	// defaultBranches caches each repo's default branch; see BaseBranch.
+	defaultBranches map[string]string
+	streamFormat    StreamFormat
+	runLimits       RunLimits
+	// checkWatches holds the issues whose PR checks are being polled,
+	// each with a channel closed to stop polling.
+	checkWatches map[string]chan struct{}
+	// prWatches holds the issues whose PRs are watched for review
+	// feedback, likewise, and fixingPRs those whose feedback Claude is
+	// addressing.
+	prWatches map[string]chan struct{}
+	fixingPRs map[string]bool
+	// tracked holds the issues Issues reports, with their authoritative
+	// status; see track.
+	tracked map[string]TrackedIssue
+	// errorsSeen holds the repo-level errors passed on lately, by repo and
+	// text; see dedupError.
+	errorsSeen map[string]seenError
+	// waiting holds the runs waiting for approval at a gated stage; see
+	// ContinueIssue.
+	waiting map[string]chan struct{}
+	// issueRuns holds the runs in flight per issue key, each a channel
+	// closed once it is over; see runStarted.
+	issueRuns map[string][]chan struct{}
 }
 
 // NewManager creates a Manager, loading persisted state from disk.
-func NewManager(baseDir string, pollInterval time.Duration, ghClient *github.Client) (*Manager, error) {
-	if err := os.MkdirAll(baseDir, 0o755); err != nil {
-		return nil, fmt.Errorf("creating base dir: %w", err)
+// baseDir is its Layout's data directory; see LoadLayout.
+func NewManager(baseDir string, pollInterval time.Duration, ghClient Forge) (*Manager, error) {
+	return NewManagerIn(LoadLayout(baseDir), pollInterval, ghClient)
+}
+
+// NewManagerIn creates a Manager keeping its files where l says, and
+// records l in its data directory.
+func NewManagerIn(l Layout, pollInterval time.Duration, ghClient Forge) (*Manager, error) {
+	l = l.resolved()
+	for _, dir := range []string{l.Data, l.State} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating base dir: %w", err)
+		}
+	}
+	if err := l.save(); err != nil {
+		return nil, fmt.Errorf("recording layout: %w", err)
 	}
 
-	statePath := filepath.Join(baseDir, "state.json")
+	statePath := l.StatePath()
 	state := loadState(statePath)
 
-	return &Manager{
-		baseDir:      baseDir,
+	m := &Manager{
+		baseDir:      l.Data,
+		layout:       l,
 		pollInterval: pollInterval,
 		ghClient:     ghClient,
 		eventCh:      make(chan Event, 100),
+		outCh:        make(chan Event, 100),
+		journal:      openJournal(filepath.Join(l.State, JournalFile)),
+		stats:        newStatsRecorder(filepath.Join(l.State, StatsFile)),
+		supervision:  defaultSupervision,
 		watchers:     make(map[string]context.CancelFunc),
 		repoWatchers: make(map[string]*Watcher),
 		knownIssues:  make(map[string]Issue),
 		issueCtxs:    make(map[string]context.CancelFunc),
+		issueRuns:    make(map[string][]chan struct{}),
 		issuePTYs:    make(map[string]IssuePTY),
+		hooked:       make(map[string]time.Time),
+		replayed:     make(map[string]bool),
+		checkWatches: make(map[string]chan struct{}),
+		prWatches:    make(map[string]chan struct{}),
+		fixingPRs:    make(map[string]bool),
+		tracked:      make(map[string]TrackedIssue),
+		errorsSeen:   make(map[string]seenError),
+		waiting:      make(map[string]chan struct{}),
+		discoverNow:  make(chan struct{}, 1),
+		announceWake: make(chan struct{}, 1),
 		state:        state,
 		statePath:    statePath,
-	}, nil
+	}
+	m.bgCtx, m.bgStop = context.WithCancel(context.Background())
+	go m.forwardEvents()
+	go m.announceLoop(m.bgCtx)
+	return m, nil
 }
 
 // Start begins polling for all persisted repos.
 func (m *Manager) Start() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	for _, repo := range m.state.Repos {
-		m.startWatcher(repo)
+	m.started = true
+	for i, repo := range m.state.Repos {
+		m.startWatcher(repo, staggerDelay(i, len(m.state.Repos), m.pollInterval))
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.discoverStop = cancel
+	go m.discoverLoop(ctx)
+	go m.purgeTrash(m.bgCtx)
 }
 
 // AddRepo adds a repo to the watched list and starts polling it.
@@ -176,38 +372,77 @@ func (m *Manager) AddRepo(repo string) error {
 	}
 
 	m.state.Repos = append(m.state.Repos, repo)
+	m.unskip(repo)
 	if err := m.saveState(); err != nil {
 		return err
 	}
 
-	m.startWatcher(repo)
+	m.startWatcher(repo, 0)
 	return nil
 }
 
 // RemoveRepo stops watching a repo and removes it from persisted state.
+// Its issues' runs are cancelled and waited for, so its files can be
+// deleted once it returns.
 func (m *Manager) RemoveRepo(repo string) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	waitRuns := m.forgetRepo(repo)
+	err := m.saveState()
+	m.mu.Unlock()
 
+	waitRuns()
+	return err
+}
+
+// forgetRepo stops watching repo and drops everything kept for it,
+// skipping it in the owner's discovery. The caller holds m.mu and saves
+// the state; it calls the returned wait, once m.mu is released, for the
+// repo's canceled runs to finish.
+func (m *Manager) forgetRepo(repo string) (wait func()) {
 	if cancel, exists := m.watchers[repo]; exists {
 		cancel()
 		delete(m.watchers, repo)
 	}
 	delete(m.repoWatchers, repo)
+	delete(m.hooked, repo)
 
-	// Cancel all issue processing for this repo
+	// Cancel all issue processing for this repo, and forget what its
+	// issues had going, so they start afresh if it is added back.
 	prefix := repo + "#"
-	for key, cancel := range m.issueCtxs {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
-			cancel()
-			delete(m.issueCtxs, key)
+	ofRepo := func(key string) bool { return strings.HasPrefix(key, prefix) }
+	waitRuns := m.cancelRuns(ofRepo)
+	for key, stop := range m.checkWatches {
+		if ofRepo(key) {
+			close(stop)
+			delete(m.checkWatches, key)
+		}
+	}
+	for key, stop := range m.prWatches {
+		if ofRepo(key) {
+			close(stop)
+			delete(m.prWatches, key)
+		}
+	}
+	for key := range m.waiting {
+		if ofRepo(key) {
+			delete(m.waiting, key)
+		}
+	}
+	for key := range m.issuePTYs {
+		if ofRepo(key) {
+			delete(m.issuePTYs, key)
 		}
 	}
 	for key := range m.knownIssues {
-		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+		if ofRepo(key) {
 			delete(m.knownIssues, key)
 		}
 	}
+	for key := range m.tracked {
+		if ofRepo(key) {
+			delete(m.tracked, key)
+		}
+	}
 
 	for i, r := range m.state.Repos {
 		if r == repo {
@@ -216,28 +451,369 @@ func (m *Manager) RemoveRepo(repo string) error {
 		}
 	}
 	delete(m.state.Processed, repo)
+	delete(m.state.BaseBranches, repo)
+	delete(m.state.PatchMode, repo)
+	delete(m.state.Ignored, repo)
+	m.skip(repo)
+	return waitRuns
+}
+
+// RenameRepo re-keys a watched repo after it was renamed or transferred on
+// GitHub. Its directory under BaseDir is moved, worktrees are repaired to
+// point at the new location, and polling restarts under the new name.
+// In-flight issue processing for the old name is cancelled and waited
+// for, so close the issues' shells first: nothing may be using the
+// directory when it moves.
+func (m *Manager) RenameRepo(from, to string) error {
+	m.mu.Lock()
+	if !slices.Contains(m.state.Repos, from) {
+		m.mu.Unlock()
+		return fmt.Errorf("rename: %s is not watched", from)
+	}
+	if _, exists := m.watchers[to]; exists {
+		m.mu.Unlock()
+		return fmt.Errorf("rename: %s is already watched", to)
+	}
+
+	if cancel, exists := m.watchers[from]; exists {
+		cancel()
+		delete(m.watchers, from)
+	}
+	delete(m.repoWatchers, from)
+
+	prefix := from + "#"
+	waitRuns := m.cancelRuns(func(key string) bool { return strings.HasPrefix(key, prefix) })
+	for key, iss := range m.knownIssues {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.knownIssues, key)
+			m.knownIssues[IssueKey(to, iss.Number)] = iss
+		}
+	}
+	for key, iss := range m.tracked {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.tracked, key)
+			iss.Repo = to
+			if iss.Status.Active() {
+				iss.Status = StatusPaused
+			}
+			m.tracked[IssueKey(to, iss.Number)] = iss
+		}
+	}
+	for key := range m.issuePTYs {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.issuePTYs, key)
+		}
+	}
+	m.mu.Unlock()
+
+	waitRuns()
+	if err := moveRepoDir(m.layout, from, to); err != nil {
+		return err
+	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	idx := slices.Index(m.state.Repos, from)
+	if idx < 0 {
+		return fmt.Errorf("rename: %s was removed while moving", from)
+	}
+	m.state.Repos[idx] = to
+	if processed, ok := m.state.Processed[from]; ok {
+		m.state.Processed[to] = processed
+		delete(m.state.Processed, from)
+	}
+	if base, ok := m.state.BaseBranches[from]; ok {
+		m.state.BaseBranches[to] = base
+		delete(m.state.BaseBranches, from)
+	}
+	if m.state.PatchMode[from] {
+		m.state.PatchMode[to] = true
+		delete(m.state.PatchMode, from)
+	}
+	if ignored, ok := m.state.Ignored[from]; ok {
+		m.state.Ignored[to] = ignored
+		delete(m.state.Ignored, from)
+	}
+	if err := m.saveState(); err != nil {
+		return err
+	}
+
+	m.startWatcher(to, 0)
+	return nil
+}
+
+// moveRepoDir moves from's directory in l to to's, and its bare clone if
+// that is kept apart, renaming each issue's worktree to match the new repo
+// name and repairing git's worktree links.
+func moveRepoDir(l Layout, from, to string) error {
+	relink := func() error {
+		if err := l.relinkAlternates(to, objectsDir(l.BareDir(from))); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+		return nil
+	}
+	if l.Cache != l.Data {
+		if err := movePath(filepath.Dir(l.BareDir(from)), filepath.Dir(l.BareDir(to)), func(string) {}); err != nil {
+			return fmt.Errorf("rename: %w", err)
+		}
+		if err := relink(); err != nil {
+			return err
+		}
+	}
+	src := filepath.Join(l.Data, from)
+	dst := filepath.Join(l.Data, to)
+	if _, err := os.Stat(src); err != nil {
+		return os.MkdirAll(dst, 0o755)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("rename: %s already exists", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	if l.Cache == l.Data {
+		if err := relink(); err != nil {
+			return err
+		}
+	}
+
+	oldName, newName := filepath.Base(from), filepath.Base(to)
+	if oldName != newName {
+		entries, _ := os.ReadDir(dst)
+		for _, e := range entries {
+			if !e.IsDir() || e.Name() == "bare.git" {
+				continue
+			}
+			wt := filepath.Join(dst, e.Name(), oldName)
+			if _, err := os.Stat(wt); err != nil {
+				continue
+			}
+			if err := os.Rename(wt, filepath.Join(dst, e.Name(), newName)); err != nil {
+				return fmt.Errorf("rename worktree: %w", err)
+			}
+		}
+	}
+	return repairWorktrees(l.BareDir(to), dst, newName)
+}
+
+// repairWorktrees re-links a repo's bare clone and its issue worktrees
+// (repoDir/<issue>/<name>) after either was moved on disk.
+func repairWorktrees(bareDir, repoDir, name string) error {
+	entries, _ := os.ReadDir(repoDir)
+	var worktrees []string
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "bare.git" {
+			continue
+		}
+		wt := filepath.Join(repoDir, e.Name(), name)
+		if _, err := os.Stat(wt); err == nil {
+			worktrees = append(worktrees, wt)
+		}
+	}
+
+	if _, err := os.Stat(bareDir); err == nil && len(worktrees) > 0 {
+		args := append([]string{"-C", bareDir, "worktree", "repair"}, worktrees...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("worktree repair: %s: %w", strings.TrimSpace(string(out)), err)
+		}
+	}
+	return nil
+}
+
+// MoveIssue re-keys a tracked issue after it was transferred on GitHub.
+// The destination repo must be watched. The issue directory (logs, prompt,
+// worktree) moves with it, and the worktree moves to the destination's
+// bare clone, cloned first if need be, on a branch renamed to match the
+// new issue number so DeriveIssueStatus keeps finding its commits.
+func (m *Manager) MoveIssue(fromRepo string, fromNum int, toRepo string, toNum int) error {
+	m.mu.Lock()
+	if _, ok := m.watchers[toRepo]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("move issue: %s is not watched", toRepo)
+	}
+	w := m.repoWatchers[toRepo]
+
+	oldKey := IssueKey(fromRepo, fromNum)
+	waitRun := m.cancelRuns(func(key string) bool { return key == oldKey })
+	delete(m.issuePTYs, oldKey)
+	if iss, ok := m.knownIssues[oldKey]; ok {
+		delete(m.knownIssues, oldKey)
+		iss.Number = toNum
+		m.knownIssues[IssueKey(toRepo, toNum)] = iss
+	}
+	if iss, ok := m.tracked[oldKey]; ok {
+		delete(m.tracked, oldKey)
+		iss.Repo, iss.Number = toRepo, toNum
+		iss.URL = fmt.Sprintf("https://github.com/%s/issues/%d", toRepo, toNum)
+		if iss.Workdir != "" {
+			iss.Workdir = filepath.Join(m.baseDir, toRepo, fmt.Sprintf("%d", toNum), filepath.Base(toRepo))
+		}
+		if iss.Status.Active() {
+			iss.Status = StatusPaused
+		}
+		m.tracked[IssueKey(toRepo, toNum)] = iss
+	}
+	m.mu.Unlock()
+
+	waitRun()
+	src := filepath.Join(m.baseDir, fromRepo, fmt.Sprintf("%d", fromNum))
+	dst := filepath.Join(m.baseDir, toRepo, fmt.Sprintf("%d", toNum))
+	if _, err := os.Stat(src); err == nil {
+		if _, err := os.Stat(dst); err == nil {
+			return fmt.Errorf("move issue: %s already exists", dst)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("move issue: %w", err)
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("move issue: %w", err)
+		}
+		ctx := context.Background()
+		cloneTo := func() error {
+			if w == nil {
+				return fmt.Errorf("%s has no bare clone", toRepo)
+			}
+			return w.syncBareClone(w.runner(ctx, IssueKey(toRepo, toNum)))
+		}
+		if err := relinkMovedWorktree(ctx, m.layout, fromRepo, fromNum, toRepo, toNum, cloneTo); err != nil {
+			return fmt.Errorf("move issue: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state.Processed == nil {
+		m.state.Processed = make(map[string][]int)
+	}
+	m.state.Processed[toRepo] = append(m.state.Processed[toRepo], toNum)
 	return m.saveState()
 }
 
-// BaseDir returns the base directory for workdirs.
+// relinkMovedWorktree fixes up a worktree whose issue dir was just moved:
+// it renames the checkout to the destination repo's name and moves it
+// from the source repo's bare clone to the destination's, which cloneTo
+// makes if there is none yet, so it fetches from and pushes to the
+// destination. Its branch goes along, renamed for the new issue number.
+// The files in the checkout are kept, but not what was staged.
+func relinkMovedWorktree(ctx context.Context, l Layout, fromRepo string, fromNum int, toRepo string, toNum int, cloneTo func() error) error {
+	issueDir := filepath.Join(l.Data, toRepo, fmt.Sprintf("%d", toNum))
+	wt := filepath.Join(issueDir, filepath.Base(fromRepo))
+	if _, err := os.Stat(wt); err != nil {
+		return nil
+	}
+	if newWt := filepath.Join(issueDir, filepath.Base(toRepo)); newWt != wt {
+		if err := os.Rename(wt, newWt); err != nil {
+			return fmt.Errorf("rename worktree: %w", err)
+		}
+		wt = newWt
+	}
+
+	fromBare, toBare := l.BareDir(fromRepo), l.BareDir(toRepo)
+	if _, err := os.Stat(fromBare); err != nil {
+		return nil
+	}
+	if _, err := gitCmd(ctx, fromBare, nil, "worktree", "repair", wt); err != nil {
+		return err
+	}
+	branch, err := gitCmd(ctx, wt, nil, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	newBranch := branch
+	if branch == IssueBranch(fromNum) {
+		newBranch = IssueBranch(toNum)
+	}
+	if fromBare == toBare {
+		// The same repo under another name: only the branch changes.
+		if newBranch != branch {
+			_, err = gitCmd(ctx, wt, nil, "branch", "-m", branch, newBranch)
+		}
+		return err
+	}
+
+	if _, err := os.Stat(toBare); err != nil {
+		if err := cloneTo(); err != nil {
+			return err
+		}
+	}
+	if _, err := gitCmd(ctx, toBare, nil, "fetch", "-q", fromBare, "refs/heads/"+branch+":refs/heads/"+newBranch); err != nil {
+		return err
+	}
+	// Attach a fresh worktree of toBare's to the checkout, in place of
+	// fromBare's.
+	oldAdmin, err := gitCmd(ctx, wt, nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	tmp := wt + ".moving"
+	if _, err := gitCmd(ctx, toBare, nil, "worktree", "add", "-q", "--no-checkout", tmp, newBranch); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(tmp, ".git"), filepath.Join(wt, ".git")); err != nil {
+		return fmt.Errorf("relink worktree: %w", err)
+	}
+	os.Remove(tmp)
+	if _, err := gitCmd(ctx, toBare, nil, "worktree", "repair", wt); err != nil {
+		return err
+	}
+	if _, err := gitCmd(ctx, wt, nil, "reset", "-q"); err != nil {
+		return err
+	}
+	os.RemoveAll(oldAdmin)
+	// Moved, so the copy left behind is only clutter.
+	gitCmd(ctx, fromBare, nil, "branch", "-D", branch)
+	return nil
+}
+
+// KnownIssueNumbers returns the numbers of issues seen this session for repo.
+func (m *Manager) KnownIssueNumbers(repo string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := repo + "#"
+	var nums []int
+	for key, iss := range m.knownIssues {
+		if strings.HasPrefix(key, prefix) {
+			nums = append(nums, iss.Number)
+		}
+	}
+	return nums
+}
+
+// BaseDir returns the base directory for workdirs: its Layout's data
+// directory.
 func (m *Manager) BaseDir() string {
 	return m.baseDir
 }
 
+// Layout returns where m keeps its files.
+func (m *Manager) Layout() Layout {
+	return m.layout
+}
+
 // DeriveIssueStatus checks the filesystem to determine what status an issue
 // should have on restart. Returns the derived status and workdir path.
-func DeriveIssueStatus(baseDir, repo string, num int) (IssueStatus, string) {
-	repoName := filepath.Base(repo)
-	workdir := filepath.Join(baseDir, repo, fmt.Sprintf("%d", num), repoName)
+func DeriveIssueStatus(baseDir, repo string, num int, base string) (IssueStatus, string) {
+	workdir := IssueWorkdir(baseDir, repo, num)
 
 	if _, err := os.Stat(workdir); err != nil {
+		if PatchPath(baseDir, repo, num) != "" {
+			return StatusReady, ""
+		}
 		return StatusPending, ""
 	}
+	if _, ok := ReadQuestions(workdir); ok {
+		return StatusNeedsInput, workdir
+	}
+	if len(ReadToolRequests(IssueDir(baseDir, repo, num))) > 0 {
+		return StatusNeedsInput, workdir
+	}
 
-	// Workdir exists — check if branch has commits beyond origin/main
-	branch := fmt.Sprintf("agent/issue-%d", num)
-	cmd := exec.Command("git", "log", "--oneline", "origin/main.."+branch)
+	// Workdir exists — check if branch has commits beyond the base
+	branch := IssueBranch(num)
+	cmd := exec.Command("git", "log", "--oneline", "origin/"+base+".."+branch)
 	cmd.Dir = workdir
 	out, err := cmd.Output()
 	if err == nil && len(strings.TrimSpace(string(out))) > 0 {
@@ -249,7 +825,7 @@ func DeriveIssueStatus(baseDir, repo string, num int) (IssueStatus, string) {
 
 // EventCh returns the channel that receives events from all watchers.
 func (m *Manager) EventCh() <-chan Event {
-	return m.eventCh
+	return m.outCh
 }
 
 // Repos returns the current list of watched repos.
@@ -289,6 +865,39 @@ func (m *Manager) MarkProcessed(repo string, num int) {
 	m.saveState()
 }
 
+// RecordRunDuration remembers how long a successful run took for repo.
+func (m *Manager) RecordRunDuration(repo string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state.RunSeconds == nil {
+		m.state.RunSeconds = make(map[string][]int)
+	}
+	runs := append(m.state.RunSeconds[repo], int(d.Seconds()))
+	if len(runs) > maxRunHistory {
+		runs = runs[len(runs)-maxRunHistory:]
+	}
+	m.state.RunSeconds[repo] = runs
+	m.saveState()
+}
+
+// MedianRunDuration returns the median of recent successful run durations
+// for repo, or false if none have been recorded.
+func (m *Manager) MedianRunDuration(repo string) (time.Duration, bool) {
+	m.mu.Lock()
+	runs := append([]int(nil), m.state.RunSeconds[repo]...)
+	m.mu.Unlock()
+	if len(runs) == 0 {
+		return 0, false
+	}
+	sort.Ints(runs)
+	mid := len(runs) / 2
+	secs := runs[mid]
+	if len(runs)%2 == 0 {
+		secs = (runs[mid-1] + runs[mid]) / 2
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
 // IsKnown checks whether an issue has already been seen this session.
 func (m *Manager) IsKnown(key string) bool {
 	m.mu.Lock()
@@ -297,6 +906,16 @@ func (m *Manager) IsKnown(key string) bool {
 	return ok
 }
 
+// ReleaseClaim removes lurker's 👀 claim from an issue so another person
+// or lurker instance can pick it up. Without allow_comments, or a forge,
+// it returns a *github.PermissionError and the claim stays.
+func (m *Manager) ReleaseClaim(ctx context.Context, repo string, num int) error {
+	if m.ghClient == nil || !m.ghClient.Permissions().AllowComments {
+		return &github.PermissionError{Action: "allow_comments"}
+	}
+	return m.ghClient.RemoveReaction(ctx, repo, num, "eyes")
+}
+
 // SetIssuePTY registers a PTY session for an issue's command execution.
 func (m *Manager) SetIssuePTY(key string, pty IssuePTY) {
 	m.mu.Lock()
@@ -304,6 +923,14 @@ func (m *Manager) SetIssuePTY(key string, pty IssuePTY) {
 	m.issuePTYs[key] = pty
 }
 
+// DropIssuePTY forgets an issue's PTY session once it has been closed;
+// commands then run directly until a new one is set.
+func (m *Manager) DropIssuePTY(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.issuePTYs, key)
+}
+
 // GetIssuePTY returns the PTY session for an issue, if set.
 func (m *Manager) GetIssuePTY(key string) IssuePTY {
 	m.mu.Lock()
@@ -318,7 +945,26 @@ func (m *Manager) StoreIssue(repo string, issue Issue) {
 	m.knownIssues[IssueKey(repo, issue.Number)] = issue
 }
 
+// claimIssue stores issue unless it is already known or was abandoned,
+// reporting whether it was new, so an issue found by polling and delivered
+// by a webhook is announced once.
+func (m *Manager) claimIssue(repo string, issue Issue) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := IssueKey(repo, issue.Number)
+	if m.ignored(repo, issue.Number) {
+		return false
+	}
+	if _, ok := m.knownIssues[key]; ok && !m.replayed[key] {
+		return false
+	}
+	delete(m.replayed, key)
+	m.knownIssues[key] = issue
+	return true
+}
+
 // StartIssue begins processing a specific issue (react, clone, claude).
+// Retrying a failed issue tells Claude why the last attempt failed.
 func (m *Manager) StartIssue(repo string, num int) {
 	m.mu.Lock()
 	key := IssueKey(repo, num)
@@ -334,32 +980,154 @@ func (m *Manager) StartIssue(repo string, num int) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	m.issueCtxs[key] = cancel
+	done := m.runStarted(key)
+	delete(m.replayed, key) // started, so kept even if the next poll misses it
 	w := m.repoWatchers[repo]
+	var started Event
+	var changed bool
+	if w != nil {
+		started, changed = m.setStatus(repo, num, StatusReacted)
+		if iss, ok := m.tracked[key]; ok {
+			iss.Stages = nil // the previous run's
+			m.tracked[key] = iss
+		}
+	}
 	m.mu.Unlock()
 
 	if w == nil {
 		cancel()
+		done()
 		return
 	}
+	if changed {
+		m.announce(started)
+	}
 
-	go w.processIssue(ctx, m.eventCh, issue)
+	m.noteRetry(repo, num)
+	go func() {
+		defer done()
+		w.processIssue(ctx, m.eventCh, issue)
+	}()
 }
 
-// StopIssue cancels processing of a specific issue.
+// runStarted records a run of the issue key starting, for cancelRuns to
+// wait on, and returns what to call, without m.mu, once it is over. m.mu
+// must be held.
+func (m *Manager) runStarted(key string) func() {
+	stopped := make(chan struct{})
+	m.issueRuns[key] = append(m.issueRuns[key], stopped)
+	return func() {
+		m.mu.Lock()
+		runs := slices.DeleteFunc(m.issueRuns[key], func(run chan struct{}) bool { return run == stopped })
+		if len(runs) == 0 {
+			delete(m.issueRuns, key)
+		} else {
+			m.issueRuns[key] = runs
+		}
+		m.mu.Unlock()
+		close(stopped)
+	}
+}
+
+// runStopWait bounds how long cancelRuns waits: a cancelled run may be
+// stuck sending events its caller would have read.
+var runStopWait = 10 * time.Second
+
+// cancelRuns cancels the runs of the issues whose keys match and returns
+// a function that waits, up to runStopWait, for them to stop. m.mu must
+// be held, and released before waiting.
+func (m *Manager) cancelRuns(match func(key string) bool) (wait func()) {
+	var runs []chan struct{}
+	for key, cancel := range m.issueCtxs {
+		if match(key) {
+			cancel()
+			delete(m.issueCtxs, key)
+		}
+	}
+	for key, stopped := range m.issueRuns {
+		if match(key) {
+			runs = append(runs, stopped...)
+		}
+	}
+	return func() {
+		timeout := time.NewTimer(runStopWait)
+		defer timeout.Stop()
+		for _, stopped := range runs {
+			select {
+			case <-stopped:
+			case <-timeout.C:
+				return
+			}
+		}
+	}
+}
+
+// RunIssue processes issue of repo in the calling goroutine, sending its
+// events to eventCh, for running a single issue without polling (as
+// `lurker run` does). repo needn't be watched. The issue is tracked as
+// Issue reports it, though no EventStatus is sent.
+func (m *Manager) RunIssue(ctx context.Context, repo string, issue Issue, eventCh chan<- Event) {
+	m.StoreIssue(repo, issue)
+	m.mu.Lock()
+	m.tracked[IssueKey(repo, issue.Number)] = TrackedIssue{
+		Repo:      repo,
+		Number:    issue.Number,
+		Title:     issue.Title,
+		Body:      issue.Body,
+		URL:       issue.URL,
+		Status:    StatusReacted,
+		StartedAt: time.Now(),
+		CreatedAt: issue.CreatedAt,
+	}
+	m.mu.Unlock()
+
+	events := make(chan Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			m.track(ev)
+			eventCh <- ev
+		}
+	}()
+	m.newWatcher(repo).processIssue(ctx, events, issue)
+	close(events)
+	<-done
+}
+
+// StopIssue cancels processing of a specific issue, pausing it if it was
+// running.
 func (m *Manager) StopIssue(repo string, num int) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	key := IssueKey(repo, num)
 	if cancel, ok := m.issueCtxs[key]; ok {
 		cancel()
 		delete(m.issueCtxs, key)
 	}
+	var paused Event
+	var changed bool
+	if m.tracked[key].Status.Active() {
+		paused, changed = m.setStatus(repo, num, StatusPaused)
+	}
+	m.mu.Unlock()
+	if changed {
+		m.announce(paused)
+	}
 }
 
 // Stop stops all watchers and issue processing.
 func (m *Manager) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.teamCancel != nil {
+		m.teamCancel()
+		m.teamCancel = nil
+	}
+	if m.discoverStop != nil {
+		m.discoverStop()
+		m.discoverStop = nil
+	}
+	m.bgStop()
 	for repo, cancel := range m.watchers {
 		cancel()
 		delete(m.watchers, repo)
@@ -370,19 +1138,49 @@ func (m *Manager) Stop() {
 	}
 }
 
-func (m *Manager) startWatcher(repo string) {
+// startWatcher starts polling repo, the first time after delay.
+func (m *Manager) startWatcher(repo string, delay time.Duration) {
 	ctx, cancel := context.WithCancel(context.Background())
 	m.watchers[repo] = cancel
 
+	w := m.newWatcher(repo)
+	w.cfg.StartDelay = delay
+	m.repoWatchers[repo] = w
+	go m.supervise(ctx, w)
+}
+
+// maxStartupSpread bounds how long the last of many repos waits for its
+// first poll.
+const maxStartupSpread = 20 * time.Second
+
+// staggerDelay spreads the first polls of n repos evenly over the poll
+// interval (at most maxStartupSpread), so they don't all hit the API at
+// once; repo i waits this long.
+func staggerDelay(i, n int, interval time.Duration) time.Duration {
+	if n <= 1 || i <= 0 {
+		return 0
+	}
+	return min(interval, maxStartupSpread) * time.Duration(i) / time.Duration(n)
+}
+
+// jitter varies a poll interval by up to ±10%, so watchers started
+// together drift apart instead of polling in lockstep.
+func jitter(d time.Duration) time.Duration {
+	spread := d / 10
+	if spread <= 0 {
+		return d
+	}
+	return d - spread + rand.N(2*spread+1)
+}
+
+func (m *Manager) newWatcher(repo string) *Watcher {
 	cfg := Config{
 		Repo:         repo,
 		PollInterval: m.pollInterval,
 		BaseDir:      m.baseDir,
+		CacheDir:     m.layout.Cache,
 	}
-
-	w := &Watcher{cfg: cfg, manager: m, ghClient: m.ghClient}
-	m.repoWatchers[repo] = w
-	go w.Run(ctx, m.eventCh)
+	return &Watcher{cfg: cfg, manager: m, ghClient: m.ghClient}
 }
 
 func loadState(path string) State {
@@ -416,15 +1214,41 @@ func (m *Manager) saveState() error {
 // Config holds watcher configuration.
 type Config struct {
 	Repo         string
-	PollInterval time.Duration
-	BaseDir      string // e.g. ~/.local/share/lurker/
+	PollInterval time.Duration // varied by jitter each cycle
+	StartDelay   time.Duration // before the first poll
+	BaseDir      string        // e.g. ~/.local/share/lurker/
+	CacheDir     string        // holds the bare clones; BaseDir if empty
 }
 
 // Watcher polls GitHub for new issues and orchestrates processing.
 type Watcher struct {
 	cfg      Config
 	manager  *Manager
-	ghClient *github.Client
+	ghClient Forge
+
+	// env, if set, replaces the environment of commands run without a PTY
+	// (selftest points it at stand-ins for gh and claude).
+	env []string
+
+	// goneChecked records issues that dropped out of the open list and have
+	// already been looked up, so closed issues aren't re-fetched every poll;
+	// an issue is forgotten here once it is listed again.
+	goneChecked map[int]bool
+
+	// commentsSince is where the next look for comment commands starts;
+	// commandsFrom is when the first look was, and lastCommentID the
+	// newest comment already seen. commentsMu guards them, as the
+	// supervisor hands them to a stalled watcher's replacement, and
+	// retired, set then, stops the stalled one acting on comments.
+	commentsMu    sync.Mutex
+	commentsSince time.Time
+	commandsFrom  time.Time
+	lastCommentID int64
+	retired       bool
+
+	// heartbeat is when Run's loop last made progress, in Unix nanoseconds;
+	// the Manager's supervisor replaces watchers that stop beating.
+	heartbeat atomic.Int64
 }
 
 func (w *Watcher) emit(ch chan<- Event, kind EventKind, issueNum int, text string) {
@@ -440,17 +1264,34 @@ func (w *Watcher) emit(ch chan<- Event, kind EventKind, issueNum int, text strin
 // Run starts the poll loop. It sends events to eventCh for the TUI to consume.
 // It blocks until ctx is cancelled.
 func (w *Watcher) Run(ctx context.Context, eventCh chan<- Event) {
-	ticker := time.NewTicker(w.cfg.PollInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(w.cfg.StartDelay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
 
+	// Found issues' status depends on the base branch.
+	if w.manager != nil {
+		w.manager.detectDefaultBranch(ctx, w.cfg.Repo)
+	}
 	w.poll(ctx, eventCh)
+	lastPoll := time.Now()
 
 	for {
+		w.beat()
+		timer.Reset(jitter(w.cfg.PollInterval))
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
+			// Repos fed by webhooks are only polled as a safety net
+			if w.manager != nil && w.manager.webhookFed(w.cfg.Repo) && time.Since(lastPoll) < WebhookPollInterval {
+				continue
+			}
 			w.poll(ctx, eventCh)
+			lastPoll = time.Now()
 		}
 	}
 }
@@ -465,68 +1306,114 @@ func (w *Watcher) poll(ctx context.Context, eventCh chan<- Event) {
 
 	ghIssues, err := w.ghClient.ListOpenIssues(ctx, w.cfg.Repo)
 	if err != nil {
+		var moved *github.RepoMovedError
+		if errors.As(err, &moved) {
+			w.emit(eventCh, EventRepoMoved, 0, moved.NewRepo)
+			return
+		}
 		w.emit(eventCh, EventError, 0, fmt.Sprintf("Poll failed: %v", err))
 		return
 	}
 
 	var newCount int
+	open := make(map[int]bool, len(ghIssues))
+	for _, gi := range ghIssues {
+		open[gi.Number] = true
+	}
+	w.checkGoneIssues(ctx, eventCh, open)
+
 	for _, gi := range ghIssues {
 		iss := IssueFromGitHub(gi)
-		key := IssueKey(w.cfg.Repo, iss.Number)
-		if w.manager != nil && w.manager.IsKnown(key) {
+		if w.manager != nil && !w.manager.claimIssue(w.cfg.Repo, iss) {
 			continue
 		}
-		if w.manager != nil {
-			w.manager.StoreIssue(w.cfg.Repo, iss)
-		}
-		eventCh <- Event{
-			Kind:        EventIssueFound,
-			Repo:        w.cfg.Repo,
-			IssueNum:    iss.Number,
-			Text:        iss.Title,
-			Timestamp:   time.Now(),
-			IssueURL:    iss.URL,
-			IssueBody:   iss.Body,
-			IssueLabels: iss.LabelNames(),
-		}
+		eventCh <- issueFoundEvent(w.cfg.Repo, iss)
 		newCount++
 	}
+	if w.manager != nil {
+		w.manager.dropUnconfirmed(w.cfg.Repo)
+	}
+	w.pollCommands(ctx, eventCh)
 
 	w.emit(eventCh, EventPollDone, 0, fmt.Sprintf("Found %d new issues (of %d open)", newCount, len(ghIssues)))
 }
 
-// processIssue does the actual work: react, clone, run claude.
-// Called by Manager.StartIssue when the user triggers it.
-// All commands run inside the issue's PTY shell via RunCommand.
-func (w *Watcher) processIssue(ctx context.Context, eventCh chan<- Event, issue Issue) {
-	num := issue.Number
-	key := IssueKey(w.cfg.Repo, num)
-	pty := w.manager.GetIssuePTY(key)
+// issueFoundEvent announces a newly discovered issue.
+func issueFoundEvent(repo string, iss Issue) Event {
+	return Event{
+		Kind:        EventIssueFound,
+		Repo:        repo,
+		IssueNum:    iss.Number,
+		Text:        iss.Title,
+		Timestamp:   time.Now(),
+		IssueURL:    iss.URL,
+		IssueBody:   iss.Body,
+		IssueLabels: iss.LabelNames(),
+		IssueOpened: iss.CreatedAt,
+	}
+}
 
-	// Helper: run a command in the PTY shell (or fall back to exec if no PTY)
-	run := func(cmd string) (int, error) {
-		if pty != nil {
-			return pty.RunCommand(ctx, cmd)
+// checkGoneIssues looks up known issues that are no longer open and emits
+// EventIssueMoved for those that were transferred to another repo, and
+// EventIssueGone for the others: closed, or open but no longer listed
+// (e.g. past max_issues).
+func (w *Watcher) checkGoneIssues(ctx context.Context, eventCh chan<- Event, open map[int]bool) {
+	if w.manager == nil {
+		return
+	}
+	if w.goneChecked == nil {
+		w.goneChecked = make(map[int]bool)
+	}
+	for num := range open {
+		delete(w.goneChecked, num)
+	}
+	for _, num := range w.manager.KnownIssueNumbers(w.cfg.Repo) {
+		if open[num] || w.goneChecked[num] {
+			continue
 		}
-		// Fallback: run directly (shouldn't happen in normal flow)
-		c := exec.CommandContext(ctx, "sh", "-c", cmd)
-		if err := c.Run(); err != nil {
-			if exitErr, ok := err.(*exec.ExitError); ok {
-				return exitErr.ExitCode(), nil
+		gi, err := w.ghClient.GetIssue(ctx, w.cfg.Repo, num)
+		var moved *github.IssueMovedError
+		switch {
+		case errors.As(err, &moved):
+			eventCh <- Event{
+				Kind:      EventIssueMoved,
+				Repo:      w.cfg.Repo,
+				IssueNum:  num,
+				Text:      fmt.Sprintf("Transferred to %s#%d", moved.NewRepo, moved.NewNumber),
+				Timestamp: time.Now(),
+				MovedRepo: moved.NewRepo,
+				MovedNum:  moved.NewNumber,
 			}
-			return -1, err
+		case err != nil:
+			// Transient failure — try again next poll.
+			continue
+		case gi.State == "closed":
+			w.emit(eventCh, EventIssueGone, num, "Closed")
+		default:
+			w.emit(eventCh, EventIssueGone, num, "No longer listed as open")
 		}
-		return 0, nil
+		w.goneChecked[num] = true
 	}
+}
 
-	// React with eyes
-	if err := w.ghClient.AddReaction(ctx, w.cfg.Repo, num, "eyes"); err != nil {
-		if ctx.Err() != nil {
-			return
+// processIssue does the actual work: react, clone, run claude.
+// Called by Manager.StartIssue when the user triggers it.
+// All commands run inside the issue's PTY shell via RunCommand.
+func (w *Watcher) processIssue(ctx context.Context, eventCh chan<- Event, issue Issue) {
+	num := issue.Number
+	run := w.runner(ctx, IssueKey(w.cfg.Repo, num))
+	started := time.Now()
+
+	// React with eyes, unless this deployment may not touch issues
+	if w.ghClient.Permissions().AllowComments {
+		if err := w.ghClient.AddReaction(ctx, w.cfg.Repo, num, "eyes"); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			w.emit(eventCh, EventWarning, num, fmt.Sprintf("React failed: %v", err))
+		} else {
+			w.emit(eventCh, EventReacted, num, "Added 👀 reaction")
 		}
-		w.emit(eventCh, EventError, num, fmt.Sprintf("React failed: %v", err))
-	} else {
-		w.emit(eventCh, EventReacted, num, "Added 👀 reaction")
 	}
 
 	if ctx.Err() != nil {
@@ -534,13 +1421,36 @@ func (w *Watcher) processIssue(ctx context.Context, eventCh chan<- Event, issue
 	}
 
 	// Clone
-	issueDir := filepath.Join(w.cfg.BaseDir, w.cfg.Repo, fmt.Sprintf("%d", num))
-	repoName := filepath.Base(w.cfg.Repo)
-	workdir := filepath.Join(issueDir, repoName)
-
-	w.emit(eventCh, EventCloneStart, num, "Cloning repository...")
-
-	if err := w.cloneRepo(ctx, run, issueDir, workdir, num); err != nil {
+	issueDir := IssueDir(w.cfg.BaseDir, w.cfg.Repo, num)
+	workdir := IssueWorkdir(w.cfg.BaseDir, w.cfg.Repo, num)
+	existed := existingDir(workdir)
+	w.manager.detectDefaultBranch(ctx, w.cfg.Repo)
+	patch := existed == "" && w.manager.PatchMode(w.cfg.Repo)
+
+	var err error
+	if !patch {
+		w.emit(eventCh, EventCloneStart, num, "Cloning repository...")
+		// Before picking the base: the repo's committed config may name it
+		err = w.syncBareClone(run)
+	}
+	base := w.manager.BaseBranch(w.cfg.Repo, existed)
+	switch {
+	case err != nil:
+	case patch:
+		w.emit(eventCh, EventCloneStart, num, "Checking out "+base+" (patch mode)...")
+		defer removeWorktreeDir(workdir)
+		err = w.shallowClone(run, issueDir, workdir, num, base)
+		if err == nil {
+			// The checkout, and so its config, is fresh every run.
+			err = resetSandbox(workdir, w.manager.sandboxImageFor(LoadRepoConfig(workdir)))
+		}
+		if s := sandboxFor(workdir); err == nil && s != nil {
+			err = s.keepGitDirOut(ctx)
+		}
+	default:
+		err = w.cloneRepo(ctx, run, issueDir, workdir, issue, base)
+	}
+	if err != nil {
 		if ctx.Err() != nil {
 			return
 		}
@@ -554,16 +1464,60 @@ func (w *Watcher) processIssue(ctx context.Context, eventCh chan<- Event, issue
 		return
 	}
 
-	// Load per-repo config from .lurker/config.json if present
-	repoCfg := LoadRepoConfig(workdir)
+	// A re-run may undo earlier work, so keep it restorable
+	if existed != "" {
+		w.backupBeforeRun(ctx, eventCh, num, workdir, base)
+		if c, err := CreateCheckpoint(ctx, issueDir, workdir, base, "before re-run"); err != nil {
+			w.emit(eventCh, EventCheckpoint, num, fmt.Sprintf("Checkpoint before re-run failed: %v", err))
+		} else {
+			w.emit(eventCh, EventCheckpoint, num, fmt.Sprintf("Checkpoint %s (before re-run)", c.ID))
+		}
+	}
+
+	// Load per-repo config from .lurker/config.json if present, with any
+	// team policy layered on top
+	team := w.manager.teamConfig()
+	repoCfg := team.Policy.apply(LoadRepoConfig(workdir))
+	scope := repoCfg.Scope(issue)
+	if repoCfg.gated(StageClone) && !w.awaitApproval(ctx, eventCh, num, StageClone) {
+		return
+	}
+
+	if repoCfg.ProgressComments && w.ghClient.Permissions().AllowComments {
+		var done func()
+		eventCh, done = w.reportProgress(ctx, eventCh, num, workdir, base, started, repoCfg.Templates)
+		defer done()
+	}
 
 	// Run Claude
-	w.emit(eventCh, EventClaudeStart, num, "Running Claude Code...")
+	if scope != "" {
+		w.emit(eventCh, EventClaudeStart, num, fmt.Sprintf("Running Claude Code in %s/...", scope))
+	} else {
+		w.emit(eventCh, EventClaudeStart, num, "Running Claude Code...")
+	}
 
-	prompt := BuildClaudePrompt(w.cfg.Repo, issue)
+	prompt, err := team.RenderPrompt(w.cfg.Repo, issue)
+	if err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Prompt template: %v", err))
+		return
+	}
 	if repoCfg.PromptPrefix != "" {
 		prompt = repoCfg.PromptPrefix + "\n\n" + prompt
 	}
+	if notes := takeRetryNotes(issueDir); notes != "" {
+		prompt += retryPrompt(notes)
+		w.emit(eventCh, EventClaudeLog, num, "📝 Telling Claude why the last attempt failed")
+	}
+	prompt += toolchainPrompt(repoCfg)
+	if scope != "" {
+		prompt += scopePrompt(scope)
+	}
+	if patch {
+		prompt += patchPrompt
+	} else {
+		prompt += questionsPrompt
+	}
+	prompt += resultPrompt
 
 	// Write prompt to a file so we can pipe it to claude in the shell
 	promptFile := filepath.Join(issueDir, ".lurker-prompt.txt")
@@ -572,58 +1526,204 @@ func (w *Watcher) processIssue(ctx context.Context, eventCh chan<- Event, issue
 		return
 	}
 
-	// Build claude command — strip ANTHROPIC_API_KEY via env -u
-	tools := repoCfg.ClaudeTools()
-	claudeCmd := fmt.Sprintf(
-		"cd %s && env -u ANTHROPIC_API_KEY -u CLAUDECODE claude -p --verbose --allowedTools %s < %s",
-		shellQuote(workdir), shellQuote(tools), shellQuote(promptFile))
+	tools := scopedTools(repoCfg.ClaudeTools(), scope)
+	if !w.runClaude(ctx, eventCh, run, num, workdir, promptFile, tools, false) {
+		return
+	}
+	if !patch {
+		if w.runStages(ctx, eventCh, num, workdir, repoCfg) {
+			w.finishRun(eventCh, num, workdir, started)
+		}
+		return
+	}
+	path, err := CapturePatch(ctx, issueDir, workdir, num, base)
+	if err != nil {
+		w.emit(eventCh, EventError, num, fmt.Sprintf("Capture patch: %v", err))
+		return
+	}
+	w.finishRun(eventCh, num, path, started)
+}
+
+// runner returns a runFunc for the issue's PTY shell, falling back to
+// exec if it has none.
+func (w *Watcher) runner(ctx context.Context, key string) runFunc {
+	pty := w.manager.GetIssuePTY(key)
+	return func(cmd string) (int, error) {
+		if pty != nil {
+			return pty.RunCommand(ctx, cmd)
+		}
+		// Fallback: run directly (shouldn't happen in normal flow)
+		c := exec.CommandContext(ctx, "sh", "-c", cmd)
+		c.Env = w.env
+		if err := c.Run(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				return exitErr.ExitCode(), nil
+			}
+			return -1, err
+		}
+		return 0, nil
+	}
+}
+
+// runClaude runs Claude on promptFile in workdir (continuing its last
+// session if resume is set) and reports whether it finished its work;
+// failures, questions and refused tools have been reported if not.
+func (w *Watcher) runClaude(ctx context.Context, eventCh chan<- Event, run runFunc, num int, workdir, promptFile, tools string, resume bool) bool {
+	var limits RunLimits
+	if w.manager != nil {
+		limits = w.manager.RunLimits()
+	}
+	agent, err := w.agent(workdir)
+	if err != nil {
+		w.emit(eventCh, EventClaudeDone, num, fmt.Sprintf("Claude failed: %v", err))
+		w.emit(eventCh, EventError, num, err.Error())
+		return false
+	}
+	opts := AgentOptions{
+		Tools:    withTools(tools, ReadApprovedTools(w.cfg.BaseDir, w.cfg.Repo)),
+		Resume:   resume,
+		MaxTurns: limits.MaxTurns,
+	}
+	claudeCmd := fmt.Sprintf("cd %s && %s < %s",
+		ShellQuote(workdir), shellJoin(agentCommand(agent, workdir, opts, w.bwrap())), ShellQuote(promptFile))
+
+	release, ok := w.claudeSlot(ctx, eventCh, num)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	parent := ctx
+	if limits.Timeout > 0 {
+		// The command is interrupted when the runner's context ends.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+		run = w.runner(ctx, IssueKey(w.cfg.Repo, num))
+	}
+
+	if s := sandboxFor(workdir); s != nil {
+		if err := s.start(ctx); err != nil {
+			w.emit(eventCh, EventClaudeDone, num, fmt.Sprintf("Claude failed: %v", err))
+			w.emit(eventCh, EventError, num, err.Error())
+			return false
+		}
+		claudeCmd = s.shell(shellJoin(agent.Command(workdir, opts))) + " < " + ShellQuote(promptFile)
+	}
 
+	writeToolRequests(IssueDir(w.cfg.BaseDir, w.cfg.Repo, num), nil)
+	started := time.Now()
 	code, err := run(claudeCmd)
+	if agent.Name() == DefaultAgent {
+		w.reportCost(eventCh, num, workdir, started)
+	}
+	if ctx.Err() != nil && parent.Err() == nil {
+		msg := fmt.Sprintf("Claude timed out after %s", limits.Timeout)
+		w.emit(eventCh, EventClaudeDone, num, msg)
+		w.emit(eventCh, EventError, num, msg)
+		return false
+	}
 	if err != nil {
 		if ctx.Err() != nil {
-			return
+			return false
 		}
 		w.emit(eventCh, EventClaudeDone, num, fmt.Sprintf("Claude failed: %v", err))
 		w.emit(eventCh, EventError, num, err.Error())
-		return
+		return false
+	}
+	if w.checkToolRequests(eventCh, num, workdir) {
+		return false
 	}
 	if code != 0 {
 		w.emit(eventCh, EventClaudeDone, num, fmt.Sprintf("Claude exited with code %d", code))
 		w.emit(eventCh, EventError, num, fmt.Sprintf("Claude exited with code %d", code))
-		return
+		return false
 	}
 
 	w.emit(eventCh, EventClaudeDone, num, "Claude finished successfully")
-	w.emit(eventCh, EventReady, num, workdir)
+	w.reportToolStats(eventCh, num, workdir)
+	w.collectResult(ctx, eventCh, num, workdir)
+	if questions, ok := ReadQuestions(workdir); ok {
+		w.emit(eventCh, EventNeedsInput, num, questions)
+		return false
+	}
+	return true
+}
+
+// finishRun records a successful run and marks the issue ready. result is
+// the worktree, or the patch in patch mode.
+func (w *Watcher) finishRun(eventCh chan<- Event, num int, result string, started time.Time) {
+	w.manager.RecordRunDuration(w.cfg.Repo, time.Since(started))
+	w.emit(eventCh, EventReady, num, result)
 }
 
-// shellQuote wraps a string in single quotes for safe shell interpolation.
-func shellQuote(s string) string {
+// existingDir returns dir if it exists, else "".
+func existingDir(dir string) string {
+	if _, err := os.Stat(dir); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// ShellQuote wraps a string in single quotes for safe shell interpolation.
+func ShellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\"'\"'") + "'"
 }
 
+// shellSafe matches the words shellJoin leaves unquoted.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellJoin returns argv as a shell command line, quoting the words that
+// need it.
+func shellJoin(argv []string) string {
+	words := make([]string, len(argv))
+	for i, a := range argv {
+		if shellSafe.MatchString(a) {
+			words[i] = a
+		} else {
+			words[i] = ShellQuote(a)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
 // runFunc is the signature for running a shell command in the PTY.
 type runFunc func(cmd string) (int, error)
 
-func (w *Watcher) cloneRepo(ctx context.Context, run runFunc, issueDir, workdir string, issueNum int) error {
-	bareDir := filepath.Join(w.cfg.BaseDir, w.cfg.Repo, "bare.git")
+// bareDir returns the path of lurker's bare clone of the repo.
+func (w *Watcher) bareDir() string {
+	return w.layout().BareDir(w.cfg.Repo)
+}
+
+// layout returns the Layout of w's data and cache directories.
+func (w *Watcher) layout() Layout {
+	return Layout{Data: w.cfg.BaseDir, Cache: w.cfg.CacheDir}.resolved()
+}
 
-	// Ensure bare clone exists
+// syncBareClone makes lurker's bare clone of the repo, or fetches into it
+// if it exists.
+func (w *Watcher) syncBareClone(run runFunc) error {
+	bareDir := w.bareDir()
 	if _, err := os.Stat(bareDir); err != nil {
 		if err := os.MkdirAll(filepath.Dir(bareDir), 0o755); err != nil {
 			return fmt.Errorf("mkdir: %w", err)
 		}
-		code, err := run(fmt.Sprintf("gh repo clone %s %s -- --bare",
-			shellQuote(w.cfg.Repo), shellQuote(bareDir)))
+		code, err := run(fmt.Sprintf("%s repo clone %s %s -- --bare%s", cloneCLI(w.ghClient),
+			ShellQuote(w.cfg.Repo), ShellQuote(bareDir), referenceArgs(w.layout().referenceClones(w.cfg.Repo))))
 		if err != nil {
 			return fmt.Errorf("bare clone: %w", err)
 		}
 		if code != 0 {
 			return fmt.Errorf("bare clone: exit code %d", code)
 		}
+		// git wrote them absolute.
+		objects := objectsDir(bareDir)
+		if err := writeAlternates(objects, readAlternates(objects, objects)); err != nil {
+			return fmt.Errorf("bare clone: %w", err)
+		}
 	} else {
 		// Fetch latest
-		code, err := run(fmt.Sprintf("git -C %s fetch origin", shellQuote(bareDir)))
+		code, err := run(fmt.Sprintf("git -C %s fetch origin", ShellQuote(bareDir)))
 		if err != nil {
 			return fmt.Errorf("git fetch: %w", err)
 		}
@@ -631,10 +1731,17 @@ func (w *Watcher) cloneRepo(ctx context.Context, run runFunc, issueDir, workdir
 			return fmt.Errorf("git fetch: exit code %d", code)
 		}
 	}
+	return nil
+}
+
+// cloneRepo adds the issue's worktree to the bare clone, which
+// syncBareClone has brought up to date, branching from base.
+func (w *Watcher) cloneRepo(ctx context.Context, run runFunc, issueDir, workdir string, issue Issue, base string) error {
+	bareDir := w.bareDir()
 
 	// If worktree already exists, just fetch
 	if _, err := os.Stat(workdir); err == nil {
-		code, err := run(fmt.Sprintf("git -C %s fetch origin", shellQuote(workdir)))
+		code, err := run(fmt.Sprintf("git -C %s fetch origin", ShellQuote(workdir)))
 		if err != nil {
 			return fmt.Errorf("worktree fetch: %w", err)
 		}
@@ -649,15 +1756,44 @@ func (w *Watcher) cloneRepo(ctx context.Context, run runFunc, issueDir, workdir
 		return fmt.Errorf("mkdir: %w", err)
 	}
 
-	branch := fmt.Sprintf("agent/issue-%d", issueNum)
-	code, err := run(fmt.Sprintf("git -C %s worktree add -b %s %s",
-		shellQuote(bareDir), shellQuote(branch), shellQuote(workdir)))
-	if err != nil {
-		return fmt.Errorf("worktree add: %w", err)
+	branch := IssueBranch(issue.Number)
+	cfg := w.manager.teamConfig().Policy.apply(loadRepoConfigAt(ctx, bareDir, base))
+	scope := cfg.Scope(issue)
+	if err := resetSandbox(workdir, w.manager.sandboxImageFor(cfg)); err != nil {
+		return fmt.Errorf("sandbox: %w", err)
 	}
-	if code != 0 {
-		return fmt.Errorf("worktree add: exit code %d", code)
+	if s := sandboxFor(workdir); s != nil {
+		return w.sandboxClone(ctx, run, s, branch, base, scope)
+	}
+	if scope == "" {
+		code, err := run(fmt.Sprintf("git -C %s worktree add -b %s %s %s",
+			ShellQuote(bareDir), ShellQuote(branch), ShellQuote(workdir), ShellQuote(base)))
+		if err != nil {
+			return fmt.Errorf("worktree add: %w", err)
+		}
+		if code != 0 {
+			return fmt.Errorf("worktree add: exit code %d", code)
+		}
+		return nil
 	}
 
+	// Scoped issue: check out only its directory (cone mode keeps the
+	// top-level files) and .lurker
+	steps := []struct{ name, cmd string }{
+		{"worktree add", fmt.Sprintf("git -C %s worktree add --no-checkout -b %s %s %s",
+			ShellQuote(bareDir), ShellQuote(branch), ShellQuote(workdir), ShellQuote(base))},
+		{"sparse-checkout", fmt.Sprintf("git -C %s sparse-checkout set --cone -- %s .lurker",
+			ShellQuote(workdir), ShellQuote(scope))},
+		{"checkout", fmt.Sprintf("git -C %s checkout %s", ShellQuote(workdir), ShellQuote(branch))},
+	}
+	for _, step := range steps {
+		code, err := run(step.cmd)
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+		if code != 0 {
+			return fmt.Errorf("%s: exit code %d", step.name, code)
+		}
+	}
 	return nil
 }
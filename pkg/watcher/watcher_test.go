@@ -1,10 +1,15 @@
 package watcher
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/stefanpenner/lurker/pkg/github"
 )
 
 func TestLoadState_NoFile(t *testing.T) {
@@ -76,6 +81,76 @@ func TestManager_AddRemoveRepo(t *testing.T) {
 	}
 }
 
+func TestManager_RemoveRepo_ReAdd(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("o/r")
+	mgr.SetPatchMode("o/r", true)
+	mgr.StoreIssue("o/r", Issue{Number: 1, Title: "one"})
+
+	// A run winding down, and what the issue has going besides.
+	key := IssueKey("o/r", 1)
+	var stopped atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	checks, feedback, approved := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	mgr.mu.Lock()
+	mgr.issueCtxs[key] = cancel
+	done := mgr.runStarted(key)
+	mgr.checkWatches[key] = checks
+	mgr.prWatches[key] = feedback
+	mgr.waiting[key] = approved
+	mgr.issuePTYs[key] = nil
+	mgr.mu.Unlock()
+	go func() {
+		defer done()
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		stopped.Store(true)
+	}()
+
+	if err := mgr.RemoveRepo("o/r"); err != nil {
+		t.Fatalf("RemoveRepo: %v", err)
+	}
+	if !stopped.Load() {
+		t.Error("RemoveRepo returned before the run stopped")
+	}
+	for name, stop := range map[string]chan struct{}{"checks": checks, "feedback": feedback} {
+		select {
+		case <-stop:
+		default:
+			t.Errorf("%s watch not stopped", name)
+		}
+	}
+	select {
+	case <-approved:
+		t.Error("the gated run was approved")
+	default:
+	}
+
+	// Added back, it starts afresh.
+	if err := mgr.AddRepo("o/r"); err != nil {
+		t.Fatalf("AddRepo: %v", err)
+	}
+	mgr.mu.Lock()
+	_, watchingChecks := mgr.checkWatches[key]
+	_, watchingPR := mgr.prWatches[key]
+	_, waiting := mgr.waiting[key]
+	_, pty := mgr.issuePTYs[key]
+	mgr.mu.Unlock()
+	if watchingChecks || watchingPR || waiting || pty {
+		t.Errorf("left over: checks %v, PR %v, waiting %v, PTY %v", watchingChecks, watchingPR, waiting, pty)
+	}
+	if mgr.IsKnown(key) || mgr.PatchMode("o/r") {
+		t.Errorf("known %v, patch mode %v after re-adding", mgr.IsKnown(key), mgr.PatchMode("o/r"))
+	}
+	if mgr.ContinueIssue("o/r", 1) {
+		t.Error("still waiting for approval after re-adding")
+	}
+}
+
 func TestManager_IsProcessed(t *testing.T) {
 	dir := t.TempDir()
 	mgr, err := NewManager(dir, 30*time.Second, nil)
@@ -114,3 +189,339 @@ func TestManager_StoreAndKnow(t *testing.T) {
 		t.Error("issue should be known after storing")
 	}
 }
+
+func TestManager_RenameRepo(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	if err := mgr.AddRepo("old/repo"); err != nil {
+		t.Fatalf("AddRepo: %v", err)
+	}
+	mgr.MarkProcessed("old/repo", 7)
+	mgr.SetBaseBranch("old/repo", "develop")
+	os.MkdirAll(filepath.Join(dir, "old/repo/7/repo"), 0o755)
+	os.WriteFile(filepath.Join(dir, "old/repo/7/lurker.log"), []byte("hi\n"), 0o644)
+
+	if err := mgr.RenameRepo("old/repo", "new/name"); err != nil {
+		t.Fatalf("RenameRepo: %v", err)
+	}
+
+	repos := mgr.Repos()
+	if len(repos) != 1 || repos[0] != "new/name" {
+		t.Errorf("expected [new/name], got %v", repos)
+	}
+	if !mgr.IsProcessed("new/name", 7) {
+		t.Error("processed issues should follow the rename")
+	}
+	if got := mgr.BaseBranch("new/name", ""); got != "develop" {
+		t.Errorf("base branch should follow the rename, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new/name/7/lurker.log")); err != nil {
+		t.Errorf("issue dir not moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new/name/7/name")); err != nil {
+		t.Errorf("worktree not renamed to new repo name: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old/repo")); !os.IsNotExist(err) {
+		t.Errorf("old dir should be gone, got %v", err)
+	}
+
+	if err := mgr.RenameRepo("missing/repo", "x/y"); err == nil {
+		t.Error("expected error renaming unwatched repo")
+	}
+}
+
+func TestManager_RenameRepo_WaitsForRuns(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("old/repo")
+
+	// A run that takes a moment to wind down once cancelled.
+	var stopped atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	mgr.mu.Lock()
+	mgr.issueCtxs[IssueKey("old/repo", 1)] = cancel
+	done := mgr.runStarted(IssueKey("old/repo", 1))
+	mgr.mu.Unlock()
+	go func() {
+		defer done()
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		stopped.Store(true)
+	}()
+
+	if err := mgr.RenameRepo("old/repo", "new/repo"); err != nil {
+		t.Fatalf("RenameRepo: %v", err)
+	}
+	if !stopped.Load() {
+		t.Error("RenameRepo returned before the run stopped")
+	}
+}
+
+func TestManager_CancelRuns(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	key := IssueKey("o/r", 1)
+	ofIssue := func(k string) bool { return k == key }
+
+	// Runs that are over are forgotten.
+	mgr.mu.Lock()
+	first, second := mgr.runStarted(key), mgr.runStarted(key)
+	mgr.mu.Unlock()
+	first()
+	mgr.mu.Lock()
+	left := len(mgr.issueRuns[key])
+	mgr.mu.Unlock()
+	if left != 1 {
+		t.Errorf("%d runs left after one of two ended, want 1", left)
+	}
+	second()
+	mgr.mu.Lock()
+	_, kept := mgr.issueRuns[key]
+	wait := mgr.cancelRuns(ofIssue)
+	mgr.mu.Unlock()
+	if kept {
+		t.Error("runs kept after all of them ended")
+	}
+	wait() // nothing to wait for
+
+	// A run stuck past runStopWait is given up on.
+	defer func(d time.Duration) { runStopWait = d }(runStopWait)
+	runStopWait = 20 * time.Millisecond
+	mgr.mu.Lock()
+	stuck := mgr.runStarted(key)
+	wait = mgr.cancelRuns(ofIssue)
+	mgr.mu.Unlock()
+	start := time.Now()
+	wait()
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("waited %s for a stuck run", waited)
+	}
+	stuck()
+
+	// A run started while another is being waited for doesn't hold it up.
+	runStopWait = 5 * time.Second
+	mgr.mu.Lock()
+	old := mgr.runStarted(key)
+	wait = mgr.cancelRuns(ofIssue)
+	mgr.mu.Unlock()
+	waited := make(chan struct{})
+	go func() { wait(); close(waited) }()
+	mgr.mu.Lock()
+	newer := mgr.runStarted(key)
+	mgr.mu.Unlock()
+	defer newer()
+	old()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Error("waited on a run started after the cancel")
+	}
+}
+
+func TestManager_MoveIssue(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	mgr.AddRepo("a/one")
+	mgr.StoreIssue("a/one", Issue{Number: 3, Title: "moving"})
+	os.MkdirAll(filepath.Join(dir, "a/one/3"), 0o755)
+	os.WriteFile(filepath.Join(dir, "a/one/3/lurker.log"), []byte("hi\n"), 0o644)
+
+	if err := mgr.MoveIssue("a/one", 3, "b/two", 8); err == nil {
+		t.Fatal("expected error moving to an unwatched repo")
+	}
+
+	mgr.AddRepo("b/two")
+	if err := mgr.MoveIssue("a/one", 3, "b/two", 8); err != nil {
+		t.Fatalf("MoveIssue: %v", err)
+	}
+	if mgr.IsKnown(IssueKey("a/one", 3)) || !mgr.IsKnown(IssueKey("b/two", 8)) {
+		t.Error("known issue should be re-keyed")
+	}
+	if !mgr.IsProcessed("b/two", 8) {
+		t.Error("moved issue should be marked processed at its destination")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b/two/8/lurker.log")); err != nil {
+		t.Errorf("issue dir not moved: %v", err)
+	}
+}
+
+func TestManager_MoveIssue_Worktree(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	mgr.AddRepo("a/one")
+	mgr.AddRepo("b/two")
+
+	origins := t.TempDir()
+	for _, repo := range []string{"a/one", "b/two"} {
+		src := filepath.Join(origins, repo)
+		os.MkdirAll(src, 0o755)
+		gitIn(t, src, "init", "-q", "-b", "main")
+		writeAndCommit(t, src, "README", repo+"\n")
+		gitIn(t, dir, "clone", "-q", "--bare", src, mgr.layout.BareDir(repo))
+	}
+	wt := IssueWorkdir(dir, "a/one", 3)
+	gitIn(t, mgr.layout.BareDir("a/one"), "worktree", "add", "-q", "-b", IssueBranch(3), wt, "main")
+	writeAndCommit(t, wt, "fix.txt", "fixed\n")
+	os.WriteFile(filepath.Join(wt, "wip.txt"), []byte("wip\n"), 0o644)
+
+	if err := mgr.MoveIssue("a/one", 3, "b/two", 8); err != nil {
+		t.Fatalf("MoveIssue: %v", err)
+	}
+	wt = IssueWorkdir(dir, "b/two", 8)
+	if got := strings.TrimSpace(gitIn(t, wt, "symbolic-ref", "--short", "HEAD")); got != IssueBranch(8) {
+		t.Errorf("branch = %q, want %s", got, IssueBranch(8))
+	}
+	if got, want := strings.TrimSpace(gitIn(t, wt, "remote", "get-url", "origin")), filepath.Join(origins, "b/two"); got != want {
+		t.Errorf("origin = %q, want %q", got, want)
+	}
+	if got := strings.TrimSpace(gitIn(t, wt, "status", "--porcelain")); got != "?? wip.txt" {
+		t.Errorf("status = %q", got)
+	}
+	gitIn(t, wt, "cat-file", "-e", "HEAD:fix.txt")
+	if got := strings.TrimSpace(gitIn(t, mgr.layout.BareDir("a/one"), "branch", "--list", IssueBranch(3))); got != "" {
+		t.Errorf("source clone kept %s", got)
+	}
+	if got := gitIn(t, mgr.layout.BareDir("a/one"), "worktree", "list"); strings.Contains(got, wt) || strings.Contains(got, "issue-3") {
+		t.Errorf("source clone still lists the worktree:\n%s", got)
+	}
+}
+
+func TestManager_MedianRunDuration(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir, 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	if _, ok := mgr.MedianRunDuration("test/repo"); ok {
+		t.Error("expected no estimate before any runs")
+	}
+
+	for _, secs := range []int{300, 60, 120} {
+		mgr.RecordRunDuration("test/repo", time.Duration(secs)*time.Second)
+	}
+	if d, ok := mgr.MedianRunDuration("test/repo"); !ok || d != 120*time.Second {
+		t.Errorf("median = %v, %v; want 2m0s", d, ok)
+	}
+
+	mgr.RecordRunDuration("test/repo", 200*time.Second)
+	if d, _ := mgr.MedianRunDuration("test/repo"); d != 160*time.Second {
+		t.Errorf("even-count median = %v, want 2m40s", d)
+	}
+
+	// History survives a restart.
+	state := loadState(filepath.Join(dir, "state.json"))
+	if len(state.RunSeconds["test/repo"]) != 4 {
+		t.Errorf("expected 4 persisted runs, got %v", state.RunSeconds)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 30 * time.Second
+	for range 1000 {
+		if got := jitter(d); got < 27*time.Second || got > 33*time.Second {
+			t.Fatalf("jitter(%v) = %v, want within ±10%%", d, got)
+		}
+	}
+	if got := jitter(5); got != 5 {
+		t.Errorf("jitter(5ns) = %v, want unchanged", got)
+	}
+}
+
+func TestStaggerDelay(t *testing.T) {
+	tests := []struct {
+		i, n     int
+		interval time.Duration
+		want     time.Duration
+	}{
+		{0, 4, 30 * time.Second, 0},
+		{1, 1, 30 * time.Second, 0},
+		{1, 4, 8 * time.Second, 2 * time.Second},
+		{3, 4, 8 * time.Second, 6 * time.Second},
+		{3, 4, time.Minute, 15 * time.Second}, // capped at maxStartupSpread
+	}
+	for _, tt := range tests {
+		if got := staggerDelay(tt.i, tt.n, tt.interval); got != tt.want {
+			t.Errorf("staggerDelay(%d, %d, %v) = %v, want %v", tt.i, tt.n, tt.interval, got, tt.want)
+		}
+	}
+}
+
+// issueForge reports the state of each issue in states.
+type issueForge struct {
+	Forge
+	states map[int]string
+}
+
+func (f *issueForge) GetIssue(ctx context.Context, repo string, num int) (*github.Issue, error) {
+	return &github.Issue{Number: num, State: f.states[num]}, nil
+}
+
+func TestCheckGoneIssues(t *testing.T) {
+	mgr, err := NewManager(t.TempDir(), 30*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	defer mgr.Stop()
+	for num := 1; num <= 3; num++ {
+		mgr.claimIssue("o/r", Issue{Number: num})
+		mgr.eventCh <- Event{Kind: EventIssueFound, Repo: "o/r", IssueNum: num}
+		nextEvent(t, mgr)
+	}
+	mgr.SetStatus("o/r", 3, StatusClaudeRunning)
+	nextEvent(t, mgr)
+
+	forge := &issueForge{states: map[int]string{1: "closed", 3: "closed"}}
+	w := &Watcher{cfg: Config{Repo: "o/r"}, ghClient: forge, manager: mgr}
+	events := make(chan Event, 10)
+	w.checkGoneIssues(context.Background(), events, map[int]bool{2: true})
+	close(events)
+	gone := map[int]string{}
+	for ev := range events {
+		if ev.Kind != EventIssueGone {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		gone[ev.IssueNum] = ev.Text
+		mgr.eventCh <- ev
+		nextEvent(t, mgr)
+	}
+	if len(gone) != 2 || gone[1] != "Closed" {
+		t.Errorf("gone = %v", gone)
+	}
+
+	// The closed issue is forgotten; the running one stays until its run
+	// is over.
+	if _, ok := mgr.Issue("o/r", 1); ok || mgr.IsKnown(IssueKey("o/r", 1)) {
+		t.Error("closed issue still tracked")
+	}
+	if _, ok := mgr.Issue("o/r", 3); !ok {
+		t.Error("running issue forgotten")
+	}
+
+	// Reopened, it is found again.
+	if !mgr.claimIssue("o/r", Issue{Number: 1}) {
+		t.Error("reopened issue not claimed")
+	}
+}
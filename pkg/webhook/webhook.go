@@ -0,0 +1,153 @@
+// Package webhook receives GitHub webhook deliveries so lurker learns of
+// new issues as they're opened instead of by polling. Deliveries must be
+// signed with the hook's secret; issues events are handed to a Sink (the
+// watcher.Manager), which deduplicates them against what polling found.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+// Path is where the handler expects deliveries; point the hook's payload
+// URL at it.
+const Path = "/webhook"
+
+// maxPayload is GitHub's cap on a delivery's size.
+const maxPayload = 25 << 20
+
+// maxSeen bounds how many delivery IDs are remembered to drop redeliveries.
+const maxSeen = 1000
+
+// Sink receives what the deliveries say. watcher.Manager implements it.
+type Sink interface {
+	// DeliverIssue hands over an open issue, reporting whether it was new.
+	DeliverIssue(repo string, issue github.Issue) bool
+	// MarkWebhook records that repo is delivering, reporting whether it is
+	// watched.
+	MarkWebhook(repo string) bool
+}
+
+// Handler serves GitHub webhook deliveries.
+type Handler struct {
+	secret []byte
+	sink   Sink
+
+	mu   sync.Mutex
+	seen map[string]bool // delivery IDs handled, to drop redeliveries
+	ids  []string        // seen, oldest first
+}
+
+// NewHandler creates a Handler that accepts deliveries signed with secret.
+func NewHandler(secret string, sink Sink) (*Handler, error) {
+	if secret == "" {
+		return nil, errors.New("webhook: a secret is required")
+	}
+	return &Handler{secret: []byte(secret), sink: sink, seen: make(map[string]bool)}, nil
+}
+
+// payload is the part of an event's payload lurker reads.
+type payload struct {
+	Action     string       `json:"action"`
+	Issue      github.Issue `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Path {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayload))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+	if !h.validSignature(body, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	if !h.firstDelivery(r.Header.Get("X-GitHub-Delivery")) {
+		io.WriteString(w, "duplicate\n")
+		return
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+		return
+	}
+	repo := p.Repository.FullName
+
+	switch event := r.Header.Get("X-GitHub-Event"); {
+	case event == "ping":
+		// Repo hooks ping on creation, so the repo stops being polled
+		// often before its first issue arrives.
+		if repo != "" {
+			h.sink.MarkWebhook(repo)
+		}
+		io.WriteString(w, "pong\n")
+	case event == "issues" && (p.Action == "opened" || p.Action == "reopened"):
+		if h.sink.DeliverIssue(repo, p.Issue) {
+			io.WriteString(w, "new\n")
+		} else {
+			io.WriteString(w, "known\n")
+		}
+	case event == "issues":
+		h.sink.MarkWebhook(repo)
+		io.WriteString(w, "ignored\n")
+	default:
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "ignored\n")
+	}
+}
+
+// validSignature checks sig, the X-Hub-Signature-256 header, against body.
+func (h *Handler) validSignature(body []byte, sig string) bool {
+	hexSum, ok := strings.CutPrefix(sig, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// firstDelivery records id and reports whether it hadn't been seen.
+// Deliveries without an ID are always handled.
+func (h *Handler) firstDelivery(id string) bool {
+	if id == "" {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.seen[id] {
+		return false
+	}
+	h.seen[id] = true
+	h.ids = append(h.ids, id)
+	if len(h.ids) > maxSeen {
+		delete(h.seen, h.ids[0])
+		h.ids = h.ids[1:]
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stefanpenner/lurker/pkg/github"
+)
+
+type fakeSink struct {
+	issues []github.Issue
+	marked []string
+}
+
+func (s *fakeSink) DeliverIssue(repo string, issue github.Issue) bool {
+	if repo != "o/r" {
+		return false
+	}
+	s.issues = append(s.issues, issue)
+	return true
+}
+
+func (s *fakeSink) MarkWebhook(repo string) bool {
+	s.marked = append(s.marked, repo)
+	return true
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliver(h http.Handler, event, id, sig, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", id)
+	req.Header.Set("X-Hub-Signature-256", sig)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	sink := &fakeSink{}
+	h, err := NewHandler("s3cret", sink)
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+	opened := `{"action":"opened","issue":{"number":7,"title":"Crash","state":"open"},"repository":{"full_name":"o/r"}}`
+
+	if rec := deliver(h, "issues", "1", sign("wrong", opened), opened); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", rec.Code)
+	}
+	if rec := deliver(h, "issues", "1", "", opened); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned: status %d", rec.Code)
+	}
+	if len(sink.issues) != 0 {
+		t.Fatalf("unsigned deliveries reached the sink: %+v", sink.issues)
+	}
+
+	if rec := deliver(h, "issues", "1", sign("s3cret", opened), opened); rec.Code != http.StatusOK || rec.Body.String() != "new\n" {
+		t.Errorf("opened: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := deliver(h, "issues", "1", sign("s3cret", opened), opened); rec.Body.String() != "duplicate\n" {
+		t.Errorf("redelivery: %q", rec.Body.String())
+	}
+	if len(sink.issues) != 1 || sink.issues[0].Number != 7 || sink.issues[0].Title != "Crash" {
+		t.Errorf("issues = %+v", sink.issues)
+	}
+
+	closed := `{"action":"closed","issue":{"number":7},"repository":{"full_name":"o/r"}}`
+	if rec := deliver(h, "issues", "2", sign("s3cret", closed), closed); rec.Body.String() != "ignored\n" {
+		t.Errorf("closed: %q", rec.Body.String())
+	}
+	ping := `{"zen":"Keep it simple.","repository":{"full_name":"o/r"}}`
+	if rec := deliver(h, "ping", "3", sign("s3cret", ping), ping); rec.Body.String() != "pong\n" {
+		t.Errorf("ping: %q", rec.Body.String())
+	}
+	if len(sink.issues) != 1 || len(sink.marked) != 2 {
+		t.Errorf("issues = %+v, marked = %v", sink.issues, sink.marked)
+	}
+
+	push := `{"ref":"refs/heads/main"}`
+	if rec := deliver(h, "push", "4", sign("s3cret", push), push); rec.Code != http.StatusAccepted {
+		t.Errorf("push: status %d", rec.Code)
+	}
+}
+
+func TestNewHandler_NeedsSecret(t *testing.T) {
+	if _, err := NewHandler("", &fakeSink{}); err == nil {
+		t.Error("NewHandler without a secret succeeded")
+	}
+}